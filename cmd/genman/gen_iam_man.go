@@ -15,6 +15,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/marmotedu/iam/internal/allinone"
 	"github.com/marmotedu/iam/internal/apiserver"
 	"github.com/marmotedu/iam/internal/authzserver"
 	"github.com/marmotedu/iam/internal/iamctl/cmd"
@@ -74,6 +75,10 @@ func main() {
 		for _, c := range watcher.Commands() {
 			genMarkdown(c, "iam-watcher", outDir)
 		}
+	case "iam":
+		// generate manpage for iam
+		iam := allinone.NewApp("iam").Command()
+		genMarkdown(iam, "", outDir)
 	case "iamctl":
 		// generate manpage for iamctl
 		// TODO os.Stdin should really be something like ioutil.Discard, but a Reader
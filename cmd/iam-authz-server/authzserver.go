@@ -8,7 +8,6 @@ package main
 
 import (
 	"github.com/marmotedu/iam/internal/authzserver"
-	_ "go.uber.org/automaxprocs"
 	"math/rand"
 	"time"
 )
@@ -7,7 +7,6 @@
 package main
 
 import (
-	_ "go.uber.org/automaxprocs"
 	"math/rand"
 	"time"
 
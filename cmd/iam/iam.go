@@ -0,0 +1,20 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// iam runs iam-apiserver, iam-authz-server, iam-pump and iam-watcher in one process, with an embedded
+// redis server and a SQLite database. It is meant for the development and the tests.
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/marmotedu/iam/internal/allinone"
+)
+
+func main() {
+	rand.Seed(time.Now().UTC().UnixNano())
+
+	allinone.NewApp("iam").Run()
+}
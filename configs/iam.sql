@@ -21,6 +21,119 @@ USE `iam`;
 /*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;
 /*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;
 
+--
+-- Table structure for table `audit_event`
+--
+
+DROP TABLE IF EXISTS `audit_event`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `audit_event` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(45) DEFAULT NULL,
+  `username` varchar(255) NOT NULL,
+  `verb` varchar(16) NOT NULL,
+  `resource` varchar(64) NOT NULL,
+  `resourceName` varchar(255) DEFAULT NULL,
+  `method` varchar(16) NOT NULL,
+  `path` varchar(1024) NOT NULL,
+  `statusCode` int(10) NOT NULL,
+  `ip` varchar(64) DEFAULT NULL,
+  `userAgent` varchar(512) DEFAULT NULL,
+  `requestID` varchar(64) DEFAULT NULL,
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  KEY `idx_username_createdAt` (`username`,`createdAt`),
+  KEY `idx_createdAt` (`createdAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `audit_event`
+--
+
+LOCK TABLES `audit_event` WRITE;
+/*!40000 ALTER TABLE `audit_event` DISABLE KEYS */;
+/*!40000 ALTER TABLE `audit_event` ENABLE KEYS */;
+UNLOCK TABLES;
+
+--
+-- Table structure for table `login_record`
+--
+
+DROP TABLE IF EXISTS `login_record`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `login_record` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(45) DEFAULT NULL,
+  `username` varchar(255) NOT NULL,
+  `ip` varchar(64) DEFAULT NULL,
+  `userAgent` varchar(512) DEFAULT NULL,
+  `method` varchar(16) NOT NULL,
+  `success` tinyint(1) unsigned NOT NULL DEFAULT 0 COMMENT '1: success\\\\n0: failure',
+  `reason` varchar(255) DEFAULT NULL,
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  KEY `idx_username_success` (`username`,`success`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `login_record`
+--
+
+LOCK TABLES `login_record` WRITE;
+/*!40000 ALTER TABLE `login_record` DISABLE KEYS */;
+/*!40000 ALTER TABLE `login_record` ENABLE KEYS */;
+UNLOCK TABLES;
+
+--
+-- Table structure for table `operation`
+--
+
+DROP TABLE IF EXISTS `operation`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `operation` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(64) NOT NULL,
+  `kind` varchar(64) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `paramsShadow` longtext DEFAULT NULL,
+  `status` varchar(16) NOT NULL,
+  `progress` int(3) NOT NULL DEFAULT 0,
+  `resultShadow` longtext DEFAULT NULL,
+  `error` varchar(1024) DEFAULT NULL,
+  `finishedAt` timestamp NULL DEFAULT NULL,
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  UNIQUE KEY `name_UNIQUE` (`name`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `operation`
+--
+
+LOCK TABLES `operation` WRITE;
+/*!40000 ALTER TABLE `operation` DISABLE KEYS */;
+/*!40000 ALTER TABLE `operation` ENABLE KEYS */;
+UNLOCK TABLES;
+
 --
 -- Table structure for table `policy`
 --
@@ -40,6 +153,7 @@ CREATE TABLE `policy` (
   PRIMARY KEY (`id`),
   UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
   KEY `fk_policy_user_idx` (`username`),
+  KEY `idx_username_name` (`username`,`name`),
   CONSTRAINT `fk_policy_user` FOREIGN KEY (`username`) REFERENCES `user` (`name`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB AUTO_INCREMENT=47 DEFAULT CHARSET=utf8;
 /*!40101 SET character_set_client = @saved_cs_client */;
@@ -64,6 +178,7 @@ DELIMITER ;;
 /*!50003 CREATE*/ /*!50017 DEFINER=`iam`@`127.0.0.1`*/ /*!50003 TRIGGER `iam`.`policy_BEFORE_DELETE` BEFORE DELETE ON `policy` FOR EACH ROW
 BEGIN
 	insert into policy_audit values(old.id, old.instanceID, old.name, old.username, old.policyShadow, old.extendShadow, old.createdAt, old.updatedAt, curtime());
+	delete from policy_attachment where username = old.username and policyName = old.name;
 END */;;
 DELIMITER ;
 /*!50003 SET sql_mode              = @saved_sql_mode */ ;
@@ -71,6 +186,40 @@ DELIMITER ;
 /*!50003 SET character_set_results = @saved_cs_results */ ;
 /*!50003 SET collation_connection  = @saved_col_connection */ ;
 
+--
+-- Table structure for table `policy_attachment`
+--
+
+DROP TABLE IF EXISTS `policy_attachment`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `policy_attachment` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(45) DEFAULT NULL,
+  `username` varchar(255) NOT NULL,
+  `policyName` varchar(45) NOT NULL,
+  `subject` varchar(255) NOT NULL,
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  UNIQUE KEY `attachment_UNIQUE` (`username`,`policyName`,`subject`),
+  KEY `idx_subject` (`subject`),
+  KEY `idx_username_subject` (`username`,`subject`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `policy_attachment`
+--
+
+LOCK TABLES `policy_attachment` WRITE;
+/*!40000 ALTER TABLE `policy_attachment` DISABLE KEYS */;
+/*!40000 ALTER TABLE `policy_attachment` ENABLE KEYS */;
+UNLOCK TABLES;
+
 --
 -- Table structure for table `policy_audit`
 --
@@ -124,6 +273,7 @@ CREATE TABLE `secret` (
   PRIMARY KEY (`id`),
   UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
   KEY `fk_secret_user_idx` (`username`),
+  KEY `idx_username_name` (`username`,`name`),
   CONSTRAINT `fk_secret_user` FOREIGN KEY (`username`) REFERENCES `user` (`name`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB AUTO_INCREMENT=22 DEFAULT CHARSET=utf8;
 /*!40101 SET character_set_client = @saved_cs_client */;
@@ -137,6 +287,63 @@ LOCK TABLES `secret` WRITE;
 /*!40000 ALTER TABLE `secret` ENABLE KEYS */;
 UNLOCK TABLES;
 
+--
+-- Table structure for table `secret_usage`
+--
+
+DROP TABLE IF EXISTS `secret_usage`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `secret_usage` (
+  `secretID` varchar(36) NOT NULL,
+  `lastUsedAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`secretID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `secret_usage`
+--
+
+LOCK TABLES `secret_usage` WRITE;
+/*!40000 ALTER TABLE `secret_usage` DISABLE KEYS */;
+/*!40000 ALTER TABLE `secret_usage` ENABLE KEYS */;
+UNLOCK TABLES;
+
+--
+-- Table structure for table `tenant_quota`
+--
+
+DROP TABLE IF EXISTS `tenant_quota`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `tenant_quota` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(63) NOT NULL COMMENT 'tenant name',
+  `maxUsers` bigint(20) NOT NULL DEFAULT 0,
+  `maxSecretsPerUser` bigint(20) NOT NULL DEFAULT 0,
+  `maxPolicies` bigint(20) NOT NULL DEFAULT 0,
+  `maxPolicySize` bigint(20) NOT NULL DEFAULT 0,
+  `maxPasswordAge` bigint(20) NOT NULL DEFAULT 0 COMMENT 'days',
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  UNIQUE KEY `name_UNIQUE` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `tenant_quota`
+--
+
+LOCK TABLES `tenant_quota` WRITE;
+/*!40000 ALTER TABLE `tenant_quota` DISABLE KEYS */;
+/*!40000 ALTER TABLE `tenant_quota` ENABLE KEYS */;
+UNLOCK TABLES;
+
 --
 -- Table structure for table `user`
 --
@@ -160,7 +367,8 @@ CREATE TABLE `user` (
   `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_name` (`name`),
-  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`)
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  KEY `idx_status_id` (`status`,`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=38 DEFAULT CHARSET=utf8;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
@@ -186,6 +394,7 @@ DELIMITER ;;
 BEGIN
 	delete from secret where username = old.name;
     delete from policy where username = old.name;
+    delete from user_group where username = old.name;
 END */;;
 DELIMITER ;
 /*!50003 SET sql_mode              = @saved_sql_mode */ ;
@@ -193,6 +402,40 @@ DELIMITER ;
 /*!50003 SET character_set_results = @saved_cs_results */ ;
 /*!50003 SET collation_connection  = @saved_col_connection */ ;
 
+--
+-- Table structure for table `user_group`
+--
+
+DROP TABLE IF EXISTS `user_group`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `user_group` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(45) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `kind` varchar(16) NOT NULL DEFAULT 'groups' COMMENT 'groups or roles',
+  `membersShadow` longtext DEFAULT NULL,
+  `description` varchar(255) DEFAULT NULL,
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  UNIQUE KEY `group_UNIQUE` (`username`,`name`),
+  KEY `idx_username_kind` (`username`,`kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `user_group`
+--
+
+LOCK TABLES `user_group` WRITE;
+/*!40000 ALTER TABLE `user_group` DISABLE KEYS */;
+/*!40000 ALTER TABLE `user_group` ENABLE KEYS */;
+UNLOCK TABLES;
+
 --
 -- Dumping events for database 'iam'
 --
@@ -0,0 +1,49 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package allinone runs all the iam components in one process, for the development and the
+// tests.
+package allinone
+
+import (
+	"github.com/marmotedu/iam/internal/allinone/options"
+	"github.com/marmotedu/iam/pkg/app"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+const commandDesc = `The IAM all-in-one server runs iam-apiserver, iam-authz-server, iam-pump
+and iam-watcher in one process, with an embedded redis server and a SQLite
+database, so that a full IAM stack is started by one command without any
+dependency. It is meant for the development and the tests, not for the production.
+
+The components listen on the loopback address at their default ports, the
+admin user and the example policies are created at the first start.
+
+Find more iam information at:
+    https://github.com/marmotedu/iam/blob/master/docs/guide/en-US/cmd/iam.md`
+
+// NewApp creates an App object with default parameters.
+func NewApp(basename string) *app.App {
+	opts := options.NewOptions()
+	application := app.NewApp("IAM all-in-one server",
+		basename,
+		app.WithOptions(opts),
+		app.WithDescription(commandDesc),
+		app.WithDefaultValidArgs(),
+		app.WithRunFunc(run(opts)),
+	)
+
+	return application
+}
+
+func run(opts *options.Options) app.RunFunc {
+	return func(basename string) error {
+		log.Init(opts.Log)
+		defer log.Flush()
+
+		opts.RuntimeOptions.Apply()
+
+		return Run(opts)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package allinone
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+)
+
+// writeCert writes a self-signed certificate of the loopback addresses, and its key, to the
+// directory. The certificate is both the one served by the components and the authority
+// verifying it, e.g. by iam-authz-server connecting to the grpc server of iam-apiserver.
+func writeCert(dir string) (genericoptions.CertKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return genericoptions.CertKey{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return genericoptions.CertKey{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "iam all-in-one"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return genericoptions.CertKey{}, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return genericoptions.CertKey{}, err
+	}
+
+	certKey := genericoptions.CertKey{
+		CertFile: filepath.Join(dir, "iam.pem"),
+		KeyFile:  filepath.Join(dir, "iam-key.pem"),
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certKey.CertFile, certPEM, 0o600); err != nil {
+		return genericoptions.CertKey{}, err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certKey.KeyFile, keyPEM, 0o600); err != nil {
+		return genericoptions.CertKey{}, err
+	}
+
+	return certKey, nil
+}
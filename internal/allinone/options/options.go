@@ -0,0 +1,61 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package options contains flags and options for running all the iam components in one process.
+package options
+
+import (
+	"os"
+	"path/filepath"
+
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
+
+	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Options runs iam-apiserver, iam-authz-server, iam-pump and iam-watcher in one process.
+type Options struct {
+	// Database is the SQLite database file, the database is kept in memory if it is empty.
+	Database string `json:"database"       mapstructure:"database"`
+	// DataDir keeps the generated TLS certificate and the analytics records.
+	DataDir string `json:"data-dir"       mapstructure:"data-dir"`
+	// AdminPassword is the password of the admin user, a random one is generated if it is empty.
+	AdminPassword  string                         `json:"admin-password" mapstructure:"admin-password"`
+	JwtOptions     *genericoptions.JwtOptions     `json:"jwt"            mapstructure:"jwt"`
+	Log            *log.Options                   `json:"log"            mapstructure:"log"`
+	RuntimeOptions *genericoptions.RuntimeOptions `json:"runtime"        mapstructure:"runtime"`
+}
+
+// NewOptions creates a new Options object with default parameters.
+func NewOptions() *Options {
+	return &Options{
+		DataDir:        filepath.Join(os.TempDir(), "iam"),
+		JwtOptions:     genericoptions.NewJwtOptions(),
+		Log:            log.NewOptions(),
+		RuntimeOptions: genericoptions.NewRuntimeOptions(),
+	}
+}
+
+// Flags returns flags for running all the iam components by section name.
+func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("all-in-one")
+	fs.StringVar(&o.Database, "database", o.Database, ""+
+		"The SQLite database file the api objects are stored in. If empty, they are kept in memory and lost at exit.")
+	fs.StringVar(&o.DataDir, "data-dir", o.DataDir, ""+
+		"The directory the generated TLS certificate and the analytics records are written to.")
+	fs.StringVar(&o.AdminPassword, "admin-password", o.AdminPassword, ""+
+		"The password of the admin user created at the first start. If empty, a random one is generated and printed.")
+
+	o.JwtOptions.AddFlags(fss.FlagSet("jwt"))
+	o.Log.AddFlags(fss.FlagSet("logs"))
+	o.RuntimeOptions.AddFlags(fss.FlagSet("runtime"))
+
+	return fss
+}
+
+// Complete set default Options.
+func (o *Options) Complete() error {
+	return o.JwtOptions.Complete()
+}
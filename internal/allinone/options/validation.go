@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package options
+
+import "fmt"
+
+// Validate checks Options and return a slice of found errs.
+func (o *Options) Validate() []error {
+	var errs []error
+
+	if o.DataDir == "" {
+		errs = append(errs, fmt.Errorf("--data-dir can not be empty"))
+	}
+
+	errs = append(errs, o.JwtOptions.Validate()...)
+	errs = append(errs, o.Log.Validate()...)
+	errs = append(errs, o.RuntimeOptions.Validate()...)
+
+	return errs
+}
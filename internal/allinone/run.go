@@ -0,0 +1,239 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package allinone
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/marmotedu/errors"
+	"github.com/spf13/viper"
+
+	"github.com/marmotedu/iam/internal/allinone/options"
+	"github.com/marmotedu/iam/internal/apiserver"
+	apiserverconfig "github.com/marmotedu/iam/internal/apiserver/config"
+	apiserveroptions "github.com/marmotedu/iam/internal/apiserver/options"
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/authzserver"
+	authzconfig "github.com/marmotedu/iam/internal/authzserver/config"
+	authzoptions "github.com/marmotedu/iam/internal/authzserver/options"
+	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	genericapiserver "github.com/marmotedu/iam/internal/pkg/server"
+	"github.com/marmotedu/iam/internal/pump"
+	pumpconfig "github.com/marmotedu/iam/internal/pump/config"
+	pumpoptions "github.com/marmotedu/iam/internal/pump/options"
+	"github.com/marmotedu/iam/internal/watcher"
+	watcherconfig "github.com/marmotedu/iam/internal/watcher/config"
+	watcheroptions "github.com/marmotedu/iam/internal/watcher/options"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// loopback is the address all the components listen on, they are meant for the local
+// development only.
+const loopback = "127.0.0.1"
+
+// stack holds what the components share: the embedded redis server and the TLS certificate.
+type stack struct {
+	opts    *options.Options
+	redis   *miniredis.Miniredis
+	certKey genericoptions.CertKey
+}
+
+// Run runs iam-apiserver, iam-authz-server, iam-pump and iam-watcher in one process, with an
+// embedded redis server and a SQLite database, until one of them stops.
+func Run(opts *options.Options) error {
+	if err := os.MkdirAll(opts.DataDir, 0o700); err != nil {
+		return errors.Wrap(err, "create data directory failed")
+	}
+
+	certKey, err := writeCert(opts.DataDir)
+	if err != nil {
+		return errors.Wrap(err, "generate TLS certificate failed")
+	}
+
+	redis, err := miniredis.Run()
+	if err != nil {
+		return errors.Wrap(err, "start embedded redis failed")
+	}
+	defer redis.Close()
+
+	// the store is shared by iam-apiserver and iam-watcher, see mysql.GetMySQLFactoryOr
+	storeIns, err := mysql.GetSQLiteFactoryOr(opts.Database, genericoptions.NewMySQLOptions().LogLevel)
+	if err != nil {
+		return err
+	}
+	defer storeIns.Close()
+
+	password, err := apiserver.Seed(context.Background(), storeIns, opts.AdminPassword)
+	if err != nil {
+		return errors.Wrap(err, "seed store failed")
+	}
+
+	// the jwt strategy of iam-apiserver reads its key from viper, which the generated key is
+	// missing from
+	viper.Set("jwt.key", opts.JwtOptions.Key)
+
+	s := &stack{opts: opts, redis: redis, certKey: certKey}
+	errCh := make(chan error, 4)
+
+	apiServerOpts := s.apiServerOptions()
+	go func() {
+		errCh <- runAPIServer(apiServerOpts)
+	}()
+
+	// iam-authz-server loads the secrets and the policies from the grpc server of iam-apiserver
+	grpcAddress := net.JoinHostPort(loopback, strconv.Itoa(apiServerOpts.GRPCOptions.BindPort))
+	if err := waitForListener(grpcAddress, errCh); err != nil {
+		return err
+	}
+
+	go func() {
+		errCh <- runAuthzServer(s.authzServerOptions(grpcAddress))
+	}()
+	go func() {
+		errCh <- runPump(s.pumpOptions())
+	}()
+	go func() {
+		errCh <- runWatcher(s.watcherOptions())
+	}()
+
+	printBanner(apiServerOpts, password)
+
+	return <-errCh
+}
+
+func (s *stack) apiServerOptions() *apiserveroptions.Options {
+	o := apiserveroptions.NewOptions()
+	o.InsecureServing.BindAddress = loopback
+	o.SecureServing.BindAddress = loopback
+	o.SecureServing.ServerCert.CertKey = s.certKey
+	o.GRPCOptions.BindAddress = loopback
+	o.JwtOptions = s.opts.JwtOptions
+	o.Log = s.opts.Log
+	s.setRedis(o.RedisOptions)
+
+	return o
+}
+
+func (s *stack) authzServerOptions(rpcServer string) *authzoptions.Options {
+	o := authzoptions.NewOptions()
+	o.RPCServer = []string{rpcServer}
+	o.ClientCA = s.certKey.CertFile
+	o.InsecureServing.BindAddress = loopback
+	o.InsecureServing.BindPort = 9090
+	o.SecureServing.BindAddress = loopback
+	o.SecureServing.BindPort = 9443
+	o.SecureServing.ServerCert.CertKey = s.certKey
+	// the http metrics are registered once per process, they are served by iam-apiserver
+	o.FeatureOptions.EnableMetrics = false
+	o.Log = s.opts.Log
+	s.setRedis(o.RedisOptions)
+
+	return o
+}
+
+func (s *stack) pumpOptions() *pumpoptions.Options {
+	o := pumpoptions.NewOptions()
+	o.Pumps = map[string]pumpoptions.PumpConfig{
+		"csv": {
+			Type: "csv",
+			Meta: map[string]interface{}{
+				"csv_dir": filepath.Join(s.opts.DataDir, "analytics"),
+			},
+		},
+	}
+	o.HealthCheckAddress = net.JoinHostPort(loopback, "7070")
+	o.Log = s.opts.Log
+	s.setRedis(o.RedisOptions)
+
+	return o
+}
+
+func (s *stack) watcherOptions() *watcheroptions.Options {
+	o := watcheroptions.NewOptions()
+	o.HealthCheckAddress = net.JoinHostPort(loopback, "5050")
+	o.Log = s.opts.Log
+	s.setRedis(o.RedisOptions)
+
+	return o
+}
+
+// setRedis points the redis options to the embedded redis server.
+func (s *stack) setRedis(o *genericoptions.RedisOptions) {
+	o.Host = s.redis.Host()
+	o.Port, _ = strconv.Atoi(s.redis.Port())
+}
+
+func runAPIServer(opts *apiserveroptions.Options) error {
+	cfg, err := apiserverconfig.CreateConfigFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(apiserver.Run(cfg), "iam-apiserver stopped")
+}
+
+func runAuthzServer(opts *authzoptions.Options) error {
+	cfg, err := authzconfig.CreateConfigFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(authzserver.Run(cfg), "iam-authz-server stopped")
+}
+
+func runPump(opts *pumpoptions.Options) error {
+	cfg, err := pumpconfig.CreateConfigFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(pump.Run(cfg, genericapiserver.SetupSignalHandler()), "iam-pump stopped")
+}
+
+func runWatcher(opts *watcheroptions.Options) error {
+	cfg, err := watcherconfig.CreateConfigFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(watcher.Run(cfg), "iam-watcher stopped")
+}
+
+// waitForListener waits until the address accepts the connections, unless the component
+// listening on it stops first.
+func waitForListener(address string, errCh <-chan error) error {
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-errCh:
+			return err
+		default:
+		}
+
+		if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+			_ = conn.Close()
+
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("%s is not listening after 1 minute", address)
+}
+
+func printBanner(opts *apiserveroptions.Options, password string) {
+	log.Infof("IAM is running, iam-apiserver at http://%s, iam-authz-server at http://%s",
+		net.JoinHostPort(loopback, strconv.Itoa(opts.InsecureServing.BindPort)), net.JoinHostPort(loopback, "9090"))
+	if password != "" {
+		fmt.Printf("\nThe password of the admin user is %s\n\n", password)
+	}
+}
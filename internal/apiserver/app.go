@@ -8,7 +8,9 @@ package apiserver
 import (
 	"github.com/marmotedu/iam/internal/apiserver/config"
 	"github.com/marmotedu/iam/internal/apiserver/options"
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
 	"github.com/marmotedu/iam/pkg/app"
+	"github.com/marmotedu/iam/pkg/featuregate"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -28,6 +30,10 @@ func NewApp(basename string) *app.App {
 		app.WithDescription(commandDesc),
 		app.WithDefaultValidArgs(),
 		app.WithRunFunc(run(opts)),
+		app.WithReloaders(reloaders(opts)),
+		app.WithFeatureGate(featuregate.DefaultFeatureGate),
+		app.WithChecks(checks(opts)...),
+		app.WithCommands(newInitCommand()),
 	)
 
 	return application
@@ -38,6 +44,8 @@ func run(opts *options.Options) app.RunFunc {
 		log.Init(opts.Log)
 		defer log.Flush()
 
+		opts.RuntimeOptions.Apply()
+
 		cfg, err := config.CreateConfigFromOptions(opts)
 		if err != nil {
 			return err
@@ -46,3 +54,32 @@ func run(opts *options.Options) app.RunFunc {
 		return Run(cfg)
 	}
 }
+
+// reloaders returns the configuration items applied at runtime when the configuration
+// file changes, besides the log level and the feature gates applied by every app. The running
+// options are updated from the reloaded ones.
+func reloaders(opts *options.Options) map[string]app.Reloader {
+	return map[string]app.Reloader{
+		"rate-limit": func(reloaded app.CliOptions) error {
+			o, _ := reloaded.(*options.Options)
+
+			return opts.RateLimitOptions.Reload(o.RateLimitOptions)
+		},
+		"server.cors-origins": func(reloaded app.CliOptions) error {
+			o, _ := reloaded.(*options.Options)
+
+			return o.GenericServerRunOptions.ReloadCorsOrigins()
+		},
+	}
+}
+
+// checks returns the startup checks run in dry-run mode.
+func checks(opts *options.Options) []app.Check {
+	return []app.Check{
+		{Name: "mysql", Run: func() error { return opts.MySQLOptions.Check() }},
+		{Name: "mysql schema", Run: func() error { return mysql.CheckSchema(opts.MySQLOptions) }},
+		{Name: "redis", Run: func() error { return opts.RedisOptions.Check() }},
+		{Name: "tls", Run: func() error { return opts.SecureServing.Check() }},
+		{Name: "jwt key", Run: func() error { return opts.JwtOptions.Check() }},
+	}
+}
@@ -15,11 +15,17 @@ import (
 	"github.com/gin-gonic/gin"
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
 	"github.com/spf13/viper"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
 	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -31,6 +37,12 @@ const (
 	APIServerIssuer = "iam-apiserver"
 )
 
+// passwordExpiredUser is a user logging in with an expired password, whose token can only
+// change the password.
+type passwordExpiredUser struct {
+	*v1.User
+}
+
 type loginInfo struct {
 	Username string `form:"username" json:"username" binding:"required,username"`
 	Password string `form:"password" json:"password" binding:"required,password"`
@@ -49,6 +61,11 @@ func newBasicAuth() middleware.AuthStrategy {
 			return false
 		}
 
+		// the users which are not active are denied with the reason by middleware.ActiveUser
+		if userstate.Of(user) != userstate.Active {
+			return true
+		}
+
 		user.LoginedAt = time.Now()
 		_ = store.Client().Users().Update(context.TODO(), user, metav1.UpdateOptions{})
 
@@ -65,11 +82,8 @@ func newJWTAuth() middleware.AuthStrategy {
 		MaxRefresh:       viper.GetDuration("jwt.max-refresh"),
 		Authenticator:    authenticator(),
 		LoginResponse:    loginResponse(),
-		LogoutResponse: func(c *gin.Context, code int) {
-			c.JSON(http.StatusOK, nil)
-		},
-		RefreshResponse: refreshResponse(),
-		PayloadFunc:     payloadFunc(),
+		RefreshResponse:  refreshResponse(),
+		PayloadFunc:      payloadFunc(),
 		IdentityHandler: func(c *gin.Context) interface{} {
 			claims := jwt.ExtractClaims(c)
 
@@ -88,6 +102,7 @@ func newJWTAuth() middleware.AuthStrategy {
 		TimeFunc:      time.Now,
 		// TODO: HTTPStatusMessageFunc:
 	})
+	ginjwt.LogoutResponse = logoutResponse(ginjwt)
 
 	return auth.NewJWTStrategy(*ginjwt)
 }
@@ -102,7 +117,9 @@ func authenticator() func(c *gin.Context) (interface{}, error) {
 		var err error
 
 		// support header and body both
+		method := apiv1.LoginMethodPassword
 		if c.Request.Header.Get("Authorization") != "" {
+			method = apiv1.LoginMethodBasic
 			login, err = parseWithHeader(c)
 		} else {
 			login, err = parseWithBody(c)
@@ -115,22 +132,65 @@ func authenticator() func(c *gin.Context) (interface{}, error) {
 		user, err := store.Client().Users().Get(c, login.Username, metav1.GetOptions{})
 		if err != nil {
 			log.Errorf("get user information failed: %s", err.Error())
+			recordLogin(c, login.Username, method, "user not found")
 
 			return "", jwt.ErrFailedAuthentication
 		}
 
 		// Compare the login password with the user password.
 		if err := user.Compare(login.Password); err != nil {
+			recordLogin(c, login.Username, method, "password incorrect")
+
 			return "", jwt.ErrFailedAuthentication
 		}
 
+		// only the active users get a token, the others are told why
+		if err := userstate.Check(user.Name, userstate.Of(user)); err != nil {
+			recordLogin(c, login.Username, method, "user "+userstate.Of(user).String())
+
+			return "", err
+		}
+
+		// an expired password only gets a token changing the password
+		expired := false
+		if err := passwordexpiry.Check(c, store.Client().Quotas(), user); err != nil {
+			if !errors.IsCode(err, code.ErrPasswordExpired) {
+				return "", err
+			}
+			expired = true
+		}
+
 		user.LoginedAt = time.Now()
 		_ = store.Client().Users().Update(c, user, metav1.UpdateOptions{})
+		recordLogin(c, login.Username, method, "")
+
+		if expired {
+			c.Set(middleware.ChangePasswordOnlyKey, true)
+
+			return passwordExpiredUser{user}, nil
+		}
 
 		return user, nil
 	}
 }
 
+// recordLogin saves a login attempt to the login history, an empty reason means the attempt succeeded.
+// Failing to save the record never blocks the login.
+func recordLogin(c *gin.Context, username, method, reason string) {
+	record := &apiv1.LoginRecord{
+		Username:  username,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Method:    method,
+		Success:   reason == "",
+		Reason:    reason,
+	}
+
+	if err := store.Client().LoginRecords().Create(c, record, metav1.CreateOptions{}); err != nil {
+		log.L(c).Warnf("save login record of user %s failed: %s", username, err.Error())
+	}
+}
+
 func parseWithHeader(c *gin.Context) (loginInfo, error) {
 	auth := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
 	if len(auth) != 2 || auth[0] != "Basic" {
@@ -180,7 +240,21 @@ func refreshResponse() func(c *gin.Context, code int, token string, expire time.
 }
 
 func loginResponse() func(c *gin.Context, code int, token string, expire time.Time) {
-	return func(c *gin.Context, code int, token string, expire time.Time) {
+	return func(c *gin.Context, _ int, token string, expire time.Time) {
+		// the token of an expired password is returned along with the error telling why it can
+		// only change the password
+		if c.GetBool(middleware.ChangePasswordOnlyKey) {
+			coder := errors.ParseCoder(errors.WithCode(code.ErrPasswordExpired, ""))
+			c.JSON(coder.HTTPStatus(), gin.H{
+				"code":    coder.Code(),
+				"message": coder.String(),
+				"token":   token,
+				"expire":  expire.Format(time.RFC3339),
+			})
+
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"token":  token,
 			"expire": expire.Format(time.RFC3339),
@@ -193,10 +267,17 @@ func payloadFunc() func(data interface{}) jwt.MapClaims {
 		claims := jwt.MapClaims{
 			"iss": APIServerIssuer,
 			"aud": APIServerAudience,
+			// the id is kept by the refreshed tokens, so that they are revoked along with the token
+			"jti": idutil.GetUUID36(""),
 		}
-		if u, ok := data.(*v1.User); ok {
+		switch u := data.(type) {
+		case *v1.User:
+			claims[jwt.IdentityKey] = u.Name
+			claims["sub"] = u.Name
+		case passwordExpiredUser:
 			claims[jwt.IdentityKey] = u.Name
 			claims["sub"] = u.Name
+			claims[passwordexpiry.ScopeClaim] = passwordexpiry.ChangePasswordScope
 		}
 
 		return claims
@@ -205,9 +286,19 @@ func payloadFunc() func(data interface{}) jwt.MapClaims {
 
 func authorizator() func(data interface{}, c *gin.Context) bool {
 	return func(data interface{}, c *gin.Context) bool {
+		if isTokenRevoked(c, jwt.GetToken(c), jwt.ExtractClaims(c)) {
+			log.L(c).Infof("token of user `%v` is revoked.", data)
+
+			return false
+		}
+
 		if v, ok := data.(string); ok {
 			log.L(c).Infof("user `%s` is authenticated.", v)
 
+			if jwt.ExtractClaims(c)[passwordexpiry.ScopeClaim] == passwordexpiry.ChangePasswordScope {
+				c.Set(middleware.ChangePasswordOnlyKey, true)
+			}
+
 			return true
 		}
 
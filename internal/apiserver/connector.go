@@ -0,0 +1,240 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/connector"
+	authstrategy "github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+const (
+	// connectorStateKeyPrefix is the prefix of the redis keys of the pending connector logins.
+	connectorStateKeyPrefix = "iam-connector-state-"
+
+	// connectorStateTimeout is how long the users have to log in on the identity provider.
+	connectorStateTimeout = 10 * time.Minute
+
+	// connectorExtendPrefix is the prefix of the user extend fields which store the subjects
+	// of the linked identities, e.g. connector.github.
+	connectorExtendPrefix = "connector."
+)
+
+type connectorProvider struct {
+	connector.Connector
+	opts *connector.ProviderOptions
+}
+
+type connectorState struct {
+	Connector string `json:"connector"`
+	Redirect  string `json:"redirect"`
+}
+
+type connectorHandler struct {
+	providers map[string]*connectorProvider
+	names     []gin.H
+	jwt       authstrategy.JWTStrategy
+	states    *storage.RedisCluster
+}
+
+// installConnectors installs the login endpoints of the connectors. A successful connector
+// login returns the same jwt token as the /login endpoint.
+func installConnectors(g *gin.Engine, opts *connector.ConnectorOptions, jwtStrategy authstrategy.JWTStrategy) {
+	h := &connectorHandler{
+		providers: map[string]*connectorProvider{},
+		names:     []gin.H{},
+		jwt:       jwtStrategy,
+		states:    &storage.RedisCluster{},
+	}
+
+	for _, p := range opts.Providers {
+		conn, err := connector.New(p, opts.RedirectURL(p))
+		if err != nil {
+			log.Fatalf("create connector %s failed: %s", p.ID, err.Error())
+		}
+
+		h.providers[p.ID] = &connectorProvider{Connector: conn, opts: p}
+		h.names = append(h.names, gin.H{"id": p.ID, "name": p.DisplayName(), "type": p.Type})
+	}
+
+	connectorv := g.Group("/connectors")
+	{
+		connectorv.GET("", h.list)
+		connectorv.GET("/:id/login", h.login)
+		connectorv.GET("/:id/callback", h.callback)
+	}
+}
+
+func (h *connectorHandler) list(c *gin.Context) {
+	core.WriteResponse(c, nil, gin.H{"totalCount": len(h.names), "items": h.names})
+}
+
+// login redirects the user agent to the identity provider, the redirect query parameter is
+// the local path the user agent is redirected to after the login.
+func (h *connectorHandler) login(c *gin.Context) {
+	p, ok := h.providers[c.Param("id")]
+	if !ok {
+		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "connector %s not found", c.Param("id")), nil)
+
+		return
+	}
+
+	state := idutil.NewSecretKey()
+	data, _ := json.Marshal(connectorState{Connector: p.opts.ID, Redirect: c.Query("redirect")})
+	if err := h.states.SetKey(connectorStateKeyPrefix+state, string(data), connectorStateTimeout); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	redirect, err := p.LoginURL(state)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// callback receives the authorization code from the identity provider.
+func (h *connectorHandler) callback(c *gin.Context) {
+	p, ok := h.providers[c.Param("id")]
+	if !ok {
+		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "connector %s not found", c.Param("id")), nil)
+
+		return
+	}
+
+	state, ok := h.consumeState(c.Query("state"))
+	if !ok || state.Connector != p.opts.ID {
+		core.WriteResponse(c, errors.WithCode(code.ErrSignatureInvalid, "invalid or expired state"), nil)
+
+		return
+	}
+
+	if reason := c.Query("error"); reason != "" {
+		core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, "%s: %s", reason, c.Query("error_description")), nil)
+
+		return
+	}
+
+	identity, err := p.HandleCallback(c, c.Query("code"), c.Query("state"))
+	if err != nil {
+		log.L(c).Warnf("connector %s callback failed: %s", p.opts.ID, err.Error())
+		core.WriteResponse(c, errors.WithCode(code.ErrSignatureInvalid, err.Error()), nil)
+
+		return
+	}
+
+	user, err := h.linkUser(c, p, identity)
+	if err != nil {
+		recordLogin(c, p.opts.Username(identity), apiv1.LoginMethodConnector, errors.ParseCoder(err).String())
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	finishLogin(c, h.jwt, user, apiv1.LoginMethodConnector, state.Redirect)
+}
+
+// consumeState returns the pending login of the state, and deletes it so that a state can
+// only be used once.
+func (h *connectorHandler) consumeState(state string) (*connectorState, bool) {
+	if state == "" {
+		return nil, false
+	}
+
+	data, err := h.states.GetKey(connectorStateKeyPrefix + state)
+	if err != nil || !h.states.DeleteKey(connectorStateKeyPrefix+state) {
+		return nil, false
+	}
+
+	var s connectorState
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, false
+	}
+
+	return &s, true
+}
+
+// linkUser returns the iam user linked to the identity:
+//   - a user linked to the identity is returned, a user linked to another identity is denied;
+//   - a user not linked yet is linked when link-existing is enabled and the verified email of
+//     the identity equals the email of the user;
+//   - a user which does not exist is created when jit-provisioning is enabled.
+func (h *connectorHandler) linkUser(c *gin.Context, p *connectorProvider, identity *connector.Identity) (*v1.User, error) {
+	if err := p.opts.Allow(identity); err != nil {
+		return nil, errors.WithCode(code.ErrPermissionDenied, err.Error())
+	}
+
+	key := connectorExtendPrefix + p.opts.ID
+	username := p.opts.Username(identity)
+
+	user, err := store.Client().Users().Get(c, username, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsCode(err, code.ErrUserNotFound) {
+			return nil, err
+		}
+
+		if !p.opts.JITProvisioning {
+			return nil, errors.WithCode(code.ErrPermissionDenied, "user %s does not exist", username)
+		}
+
+		user = &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: username, Extend: metav1.Extend{key: identity.Subject}},
+			Nickname:   identity.Name,
+		}
+		if identity.EmailVerified {
+			user.Email = identity.Email
+		}
+
+		if err := provisionUser(c, user, apiv1.LoginMethodConnector); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+	linked, _ := user.Extend[key].(string)
+	switch {
+	case linked == identity.Subject:
+		return user, nil
+	case linked != "":
+		return nil, errors.WithCode(code.ErrPermissionDenied, "user %s is linked to another %s account", username, p.opts.ID)
+	case !p.opts.LinkExisting || !identity.EmailVerified || identity.Email == "" ||
+		!strings.EqualFold(user.Email, identity.Email):
+		return nil, errors.WithCode(code.ErrPermissionDenied, "user %s is not linked to the %s account", username, p.opts.ID)
+	}
+
+	if user.Extend == nil {
+		user.Extend = metav1.Extend{}
+	}
+	user.Extend[key] = identity.Subject
+
+	if err := store.Client().Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+		return nil, err
+	}
+
+	log.L(c).Infof("user %s is linked to the %s account %s", username, p.opts.ID, identity.Subject)
+
+	return user, nil
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package scim implements the SCIM 2.0 (RFC 7643, RFC 7644) Users and Groups endpoints, so
+// that identity providers like Okta and Azure AD can provision iam users and groups.
+package scim // import "github.com/marmotedu/iam/internal/apiserver/controller/scim"
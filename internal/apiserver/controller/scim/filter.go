@@ -0,0 +1,49 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+// filter is an equality filter, the only filter the identity providers use to look up
+// the provisioned resources, e.g. `userName eq "colin"`.
+type filter struct {
+	// Attribute is lower-cased, the SCIM attribute names are case-insensitive.
+	Attribute string
+	Value     string
+}
+
+// parseFilter parses a SCIM filter expression. An empty expression returns a nil filter.
+func parseFilter(expr string) (*filter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(expr, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, errors.WithCode(code.ErrValidation, "unsupported filter %q, only `<attribute> eq <value>` is supported", expr)
+	}
+
+	value := strings.TrimSpace(parts[2])
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, errors.WithCode(code.ErrValidation, "invalid filter value %s", value)
+		}
+		value = unquoted
+	}
+
+	return &filter{
+		Attribute: strings.ToLower(parts[0]),
+		Value:     value,
+	}, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    *filter
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			expr: "",
+			want: nil,
+		},
+		{
+			name: "quoted value",
+			expr: `userName eq "colin@example.com"`,
+			want: &filter{Attribute: "username", Value: "colin@example.com"},
+		},
+		{
+			name: "value with spaces",
+			expr: `displayName EQ "iam admins"`,
+			want: &filter{Attribute: "displayname", Value: "iam admins"},
+		},
+		{
+			name:    "unsupported operator",
+			expr:    `userName sw "colin"`,
+			wantErr: true,
+		},
+		{
+			name:    "compound filter",
+			expr:    `userName eq "colin" and active eq true`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseFilter() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    *patchPath
+		wantErr bool
+	}{
+		{
+			name: "attribute",
+			path: "active",
+			want: &patchPath{Attribute: "active"},
+		},
+		{
+			name: "sub attribute",
+			path: "name.formatted",
+			want: &patchPath{Attribute: "name", SubAttribute: "formatted"},
+		},
+		{
+			name: "value filter",
+			path: `members[value eq "colin"]`,
+			want: &patchPath{Attribute: "members", Filter: &filter{Attribute: "value", Value: "colin"}},
+		},
+		{
+			name: "value filter with sub attribute",
+			path: `emails[type eq "work"].value`,
+			want: &patchPath{
+				Attribute:    "emails",
+				Filter:       &filter{Attribute: "type", Value: "work"},
+				SubAttribute: "value",
+			},
+		},
+		{
+			name:    "unterminated filter",
+			path:    `members[value eq "colin"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePath() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
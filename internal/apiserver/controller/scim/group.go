@@ -0,0 +1,376 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// extendDisplayName is the key of the group extend field which stores the SCIM displayName.
+const extendDisplayName = "displayName"
+
+// maxGroupNameLength is the maximum length of the iam group name.
+const maxGroupNameLength = 63
+
+// Group is the SCIM Group resource. The id is the iam group name derived from the displayName,
+// and the members are the iam users.
+type Group struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	ExternalID  string        `json:"externalId,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []MultiValued `json:"members,omitempty"`
+	Meta        *Meta         `json:"meta,omitempty"`
+}
+
+// CreateGroup provisions a new iam group owned by the authenticated user.
+func (s *ScimController) CreateGroup(c *gin.Context) {
+	log.L(c).Info("scim create group function called.")
+
+	var r Group
+	if err := c.ShouldBindJSON(&r); err != nil {
+		writeError(c, errors.WithCode(code.ErrBind, err.Error()), "")
+
+		return
+	}
+
+	group := &v1.Group{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   groupName(r.DisplayName),
+			Extend: metav1.Extend{extendDisplayName: r.DisplayName},
+		},
+		Username: c.GetString(middleware.UsernameKey),
+		Kind:     v1.SubjectKindGroup,
+		Members:  make([]string, 0, len(r.Members)),
+	}
+
+	if r.ExternalID != "" {
+		group.Extend[extendExternalID] = r.ExternalID
+	}
+
+	for _, member := range r.Members {
+		group.Members = append(group.Members, memberSubject(member.Value))
+	}
+
+	if errs := group.Validate(); len(errs) != 0 {
+		writeError(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), scimTypeInvalidValue)
+
+		return
+	}
+
+	if err := s.srv.Groups().Create(c, group, metav1.CreateOptions{}); err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	writeResource(c, http.StatusCreated, newGroup(group))
+}
+
+// GetGroup returns the SCIM Group resource of an iam group.
+func (s *ScimController) GetGroup(c *gin.Context) {
+	log.L(c).Info("scim get group function called.")
+
+	group, err := s.srv.Groups().Get(c, c.GetString(middleware.UsernameKey), c.Param("id"), metav1.GetOptions{})
+	if err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	writeResource(c, http.StatusOK, newGroup(group))
+}
+
+// ListGroups lists the iam groups, the `displayName eq <name>` and `externalId eq <id>`
+// filters are supported.
+func (s *ScimController) ListGroups(c *gin.Context) {
+	log.L(c).Info("scim list groups function called.")
+
+	f, err := parseFilter(c.Query("filter"))
+	if err != nil {
+		writeError(c, err, scimTypeInvalidFilter)
+
+		return
+	}
+
+	if f != nil && f.Attribute != "displayname" && f.Attribute != "externalid" {
+		writeError(c, errors.WithCode(code.ErrValidation, "unsupported filter attribute %s", f.Attribute),
+			scimTypeInvalidFilter)
+
+		return
+	}
+
+	startIndex, offset, limit := listOptions(c)
+
+	opts := metav1.ListOptions{FieldSelector: "kind=" + v1.SubjectKindGroup, Offset: &offset, Limit: &limit}
+	if f != nil {
+		// the filtered attributes are stored in the extend field, filter all the groups in memory
+		all := int64(-1)
+		opts.Offset, opts.Limit = nil, &all
+	}
+
+	groups, err := s.srv.Groups().List(c, c.GetString(middleware.UsernameKey), opts)
+	if err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	items, total := groups.Items, groups.TotalCount
+	if f != nil {
+		items = filterGroups(items, f)
+		total = int64(len(items))
+		items = page(items, offset, limit)
+	}
+
+	resources := make([]interface{}, 0, len(items))
+	for _, group := range items {
+		resources = append(resources, newGroup(group))
+	}
+
+	writeList(c, startIndex, total, resources)
+}
+
+// PatchGroup updates the displayName and the members of an iam group, the group name
+// is not changed when the displayName is replaced.
+func (s *ScimController) PatchGroup(c *gin.Context) {
+	log.L(c).Info("scim patch group function called.")
+
+	var r PatchRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		writeError(c, errors.WithCode(code.ErrBind, err.Error()), "")
+
+		return
+	}
+
+	ops, err := r.operations()
+	if err != nil {
+		writeError(c, err, scimTypeInvalidValue)
+
+		return
+	}
+
+	group, err := s.srv.Groups().Get(c, c.GetString(middleware.UsernameKey), c.Param("id"), metav1.GetOptions{})
+	if err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	if err := patchGroup(group, ops); err != nil {
+		writeError(c, err, scimTypeInvalidPath)
+
+		return
+	}
+
+	if errs := group.Validate(); len(errs) != 0 {
+		writeError(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), scimTypeInvalidValue)
+
+		return
+	}
+
+	if err := s.srv.Groups().Update(c, group, metav1.UpdateOptions{}); err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	writeResource(c, http.StatusOK, newGroup(group))
+}
+
+// patchGroup applies the PATCH operations to the iam group.
+func patchGroup(group *v1.Group, ops []PatchOperation) error {
+	if group.Extend == nil {
+		group.Extend = metav1.Extend{}
+	}
+
+	for _, op := range ops {
+		path, err := parsePath(op.Path)
+		if err != nil {
+			return err
+		}
+
+		switch path.Attribute {
+		case "displayname", "externalid":
+			key := extendDisplayName
+			if path.Attribute == "externalid" {
+				key = extendExternalID
+			}
+
+			if op.Op == opRemove {
+				delete(group.Extend, key)
+
+				continue
+			}
+
+			value, err := stringValue(op.Value)
+			if err != nil {
+				return err
+			}
+			group.Extend[key] = value
+		case "members":
+			if err := patchMembers(group, path, op); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// patchMembers applies a PATCH operation to the members of the group, the members to
+// remove are selected either by the `members[value eq "<id>"]` path or by the value.
+func patchMembers(group *v1.Group, path *patchPath, op PatchOperation) error {
+	var values []MultiValued
+	if len(op.Value) > 0 && string(op.Value) != "null" {
+		var err error
+		if values, err = multiValues(op.Value); err != nil {
+			return err
+		}
+	}
+
+	if path.Filter != nil {
+		if path.Filter.Attribute != "value" {
+			return errors.WithCode(code.ErrValidation, "unsupported members filter attribute %s", path.Filter.Attribute)
+		}
+
+		values = append(values, MultiValued{Value: path.Filter.Value})
+	}
+
+	switch op.Op {
+	case opAdd:
+		for _, v := range values {
+			if member := memberSubject(v.Value); !group.HasMember(member) {
+				group.Members = append(group.Members, member)
+			}
+		}
+	case opReplace:
+		group.Members = make([]string, 0, len(values))
+		for _, v := range values {
+			group.Members = append(group.Members, memberSubject(v.Value))
+		}
+	case opRemove:
+		if len(values) == 0 {
+			group.Members = []string{}
+
+			return nil
+		}
+
+		removed := make(map[string]struct{}, len(values))
+		for _, v := range values {
+			removed[memberSubject(v.Value)] = struct{}{}
+		}
+
+		members := make([]string, 0, len(group.Members))
+		for _, member := range group.Members {
+			if _, ok := removed[member]; !ok {
+				members = append(members, member)
+			}
+		}
+		group.Members = members
+	}
+
+	return nil
+}
+
+// newGroup converts an iam group to a SCIM Group resource.
+func newGroup(group *v1.Group) *Group {
+	r := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          group.Name,
+		DisplayName: group.Name,
+		Members:     make([]MultiValued, 0, len(group.Members)),
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt.Format(time.RFC3339),
+			LastModified: group.UpdatedAt.Format(time.RFC3339),
+			Location:     "/scim/v2/Groups/" + group.Name,
+		},
+	}
+
+	if displayName, ok := group.Extend[extendDisplayName].(string); ok && displayName != "" {
+		r.DisplayName = displayName
+	}
+
+	if externalID, ok := group.Extend[extendExternalID].(string); ok {
+		r.ExternalID = externalID
+	}
+
+	for _, member := range group.Members {
+		if kind, name, ok := v1.SplitSubject(member); ok && kind == v1.SubjectKindUser {
+			r.Members = append(r.Members, MultiValued{Value: name, Type: "User"})
+		}
+	}
+
+	return r
+}
+
+func filterGroups(groups []*v1.Group, f *filter) []*v1.Group {
+	filtered := make([]*v1.Group, 0, len(groups))
+	for _, group := range groups {
+		r := newGroup(group)
+
+		value := r.DisplayName
+		if f.Attribute == "externalid" {
+			value = r.ExternalID
+		}
+
+		if value == f.Value {
+			filtered = append(filtered, group)
+		}
+	}
+
+	return filtered
+}
+
+func page(groups []*v1.Group, offset, limit int64) []*v1.Group {
+	if offset >= int64(len(groups)) {
+		return []*v1.Group{}
+	}
+
+	groups = groups[offset:]
+	if limit < int64(len(groups)) {
+		groups = groups[:limit]
+	}
+
+	return groups
+}
+
+// memberSubject returns the ladon subject of a SCIM member id.
+func memberSubject(id string) string {
+	return v1.SubjectKindUser + ":" + id
+}
+
+// groupName derives a qualified iam group name from the displayName, the characters
+// not allowed in names are replaced with '-'.
+func groupName(displayName string) string {
+	name := []rune(strings.TrimSpace(displayName))
+	for i, r := range name {
+		if !isAlphanumeric(r) && r != '-' && r != '_' && r != '.' {
+			name[i] = '-'
+		}
+	}
+
+	if len(name) > maxGroupNameLength {
+		name = name[:maxGroupNameLength]
+	}
+
+	return strings.TrimFunc(string(name), func(r rune) bool { return !isAlphanumeric(r) })
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
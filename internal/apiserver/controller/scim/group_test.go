@@ -0,0 +1,81 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_patchGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []PatchOperation
+		want []string
+	}{
+		{
+			name: "add members",
+			ops:  []PatchOperation{{Op: opAdd, Path: "members", Value: []byte(`[{"value":"tony"},{"value":"colin"}]`)}},
+			want: []string{"users:colin", "users:lisa", "users:tony"},
+		},
+		{
+			name: "remove member by filter",
+			ops:  []PatchOperation{{Op: opRemove, Path: `members[value eq "colin"]`}},
+			want: []string{"users:lisa"},
+		},
+		{
+			name: "remove members by value",
+			ops:  []PatchOperation{{Op: opRemove, Path: "members", Value: []byte(`[{"value":"lisa"}]`)}},
+			want: []string{"users:colin"},
+		},
+		{
+			name: "remove all members",
+			ops:  []PatchOperation{{Op: opRemove, Path: "members"}},
+			want: []string{},
+		},
+		{
+			name: "replace members",
+			ops:  []PatchOperation{{Op: opReplace, Path: "members", Value: []byte(`[{"value":"tony"}]`)}},
+			want: []string{"users:tony"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &v1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "admins"},
+				Members:    []string{"users:colin", "users:lisa"},
+			}
+			if err := patchGroup(group, tt.ops); err != nil {
+				t.Fatalf("patchGroup() error = %v", err)
+			}
+			if !reflect.DeepEqual(group.Members, tt.want) {
+				t.Errorf("patchGroup() members = %v, want %v", group.Members, tt.want)
+			}
+		})
+	}
+}
+
+func Test_groupName(t *testing.T) {
+	tests := []struct {
+		displayName string
+		want        string
+	}{
+		{displayName: "admins", want: "admins"},
+		{displayName: "IAM Admins", want: "IAM-Admins"},
+		{displayName: " (dev) ", want: "dev"},
+		{displayName: "研发", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.displayName, func(t *testing.T) {
+			if got := groupName(tt.displayName); got != tt.want {
+				t.Errorf("groupName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
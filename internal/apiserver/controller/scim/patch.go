@@ -0,0 +1,147 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+// PATCH operation types, the identity providers do not agree on their case.
+const (
+	opAdd     = "add"
+	opRemove  = "remove"
+	opReplace = "replace"
+)
+
+// PatchRequest is the SCIM PATCH request, see RFC 7644 section 3.5.2.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single operation of a PATCH request.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// patchPath is a parsed PATCH path like `emails[type eq "work"].value`.
+type patchPath struct {
+	// Attribute and SubAttribute are lower-cased.
+	Attribute    string
+	Filter       *filter
+	SubAttribute string
+}
+
+// operations normalizes the operations of the request: the op is lower-cased and the
+// operations without a path are split into one operation per attribute of their value.
+func (r *PatchRequest) operations() ([]PatchOperation, error) {
+	ops := make([]PatchOperation, 0, len(r.Operations))
+	for _, op := range r.Operations {
+		op.Op = strings.ToLower(op.Op)
+		switch op.Op {
+		case opAdd, opRemove, opReplace:
+		default:
+			return nil, errors.WithCode(code.ErrValidation, "unsupported patch operation %q", op.Op)
+		}
+
+		if op.Path != "" {
+			ops = append(ops, op)
+
+			continue
+		}
+
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &values); err != nil {
+			return nil, errors.WithCode(code.ErrValidation, "patch operation without path must have an object value")
+		}
+
+		for attr, value := range values {
+			ops = append(ops, PatchOperation{Op: op.Op, Path: attr, Value: value})
+		}
+	}
+
+	return ops, nil
+}
+
+// parsePath parses the path of a PATCH operation.
+func parsePath(path string) (*patchPath, error) {
+	p := &patchPath{Attribute: path}
+
+	if i := strings.Index(path, "["); i >= 0 {
+		j := strings.LastIndex(path, "]")
+		if j < i {
+			return nil, errors.WithCode(code.ErrValidation, "invalid patch path %q", path)
+		}
+
+		f, err := parseFilter(path[i+1 : j])
+		if err != nil {
+			return nil, err
+		}
+
+		p.Attribute, p.Filter = path[:i], f
+		p.SubAttribute = strings.TrimPrefix(path[j+1:], ".")
+	} else if i := strings.LastIndex(path, "."); i >= 0 && !strings.HasPrefix(path, "urn:") {
+		p.Attribute, p.SubAttribute = path[:i], path[i+1:]
+	}
+
+	p.Attribute = strings.ToLower(p.Attribute)
+	p.SubAttribute = strings.ToLower(p.SubAttribute)
+
+	return p, nil
+}
+
+// stringValue decodes a string value of a PATCH operation.
+func stringValue(value json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", errors.WithCode(code.ErrValidation, "value %s must be a string", string(value))
+	}
+
+	return s, nil
+}
+
+// boolValue decodes a boolean value of a PATCH operation, some identity providers send
+// the booleans as strings like "False".
+func boolValue(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	s, err := stringValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	b, err = strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, errors.WithCode(code.ErrValidation, "value %s must be a boolean", string(value))
+	}
+
+	return b, nil
+}
+
+// multiValues decodes the value of a multi-valued attribute, a single value is also accepted.
+func multiValues(value json.RawMessage) ([]MultiValued, error) {
+	var values []MultiValued
+	if err := json.Unmarshal(value, &values); err == nil {
+		return values, nil
+	}
+
+	var single MultiValued
+	if err := json.Unmarshal(value, &single); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "value %s must be a list of values", string(value))
+	}
+
+	return []MultiValued{single}, nil
+}
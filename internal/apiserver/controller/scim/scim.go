@@ -0,0 +1,133 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/errors"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+// SCIM schema URIs.
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// ContentType is the media type of the SCIM messages.
+const ContentType = "application/scim+json"
+
+// SCIM error types, see RFC 7644 section 3.12.
+const (
+	scimTypeInvalidFilter = "invalidFilter"
+	scimTypeInvalidValue  = "invalidValue"
+	scimTypeInvalidSyntax = "invalidSyntax"
+	scimTypeInvalidPath   = "invalidPath"
+	scimTypeUniqueness    = "uniqueness"
+)
+
+// maxResults is the maximum number of resources returned by a single list request.
+const maxResults = 1000
+
+// ScimController create a SCIM handler used to handle the SCIM Users and Groups requests.
+type ScimController struct {
+	srv srvv1.Service
+}
+
+// NewScimController creates a SCIM handler.
+func NewScimController(store store.Factory) *ScimController {
+	return &ScimController{
+		srv: srvv1.NewService(store),
+	}
+}
+
+// Meta is the meta attribute of SCIM resources.
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// ListResponse is the response of SCIM list and query requests.
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int64         `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// Error is the SCIM error response.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// listOptions returns the offset and the limit of the SCIM startIndex and count query
+// parameters. startIndex is 1-based.
+func listOptions(c *gin.Context) (startIndex, offset, limit int64) {
+	startIndex, _ = strconv.ParseInt(c.Query("startIndex"), 10, 64)
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	limit, err := strconv.ParseInt(c.Query("count"), 10, 64)
+	if err != nil || limit > maxResults {
+		limit = maxResults
+	}
+	if limit < 0 {
+		limit = 0
+	}
+
+	return startIndex, startIndex - 1, limit
+}
+
+func writeResource(c *gin.Context, status int, resource interface{}) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, resource)
+}
+
+func writeList(c *gin.Context, startIndex, total int64, resources []interface{}) {
+	writeResource(c, http.StatusOK, ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// writeError writes a SCIM error, the status is decided by the code of err.
+func writeError(c *gin.Context, err error, scimType string) {
+	coder := errors.ParseCoder(err)
+	status := coder.HTTPStatus()
+
+	switch {
+	case errors.IsCode(err, code.ErrUserAlreadyExist), errors.IsCode(err, code.ErrGroupAlreadyExist):
+		status, scimType = http.StatusConflict, scimTypeUniqueness
+	case errors.IsCode(err, code.ErrBind):
+		scimType = scimTypeInvalidSyntax
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(status, Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   coder.String(),
+	})
+}
@@ -0,0 +1,422 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/auth"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// extendExternalID is the key of the user and group extend field which stores the SCIM externalId.
+const extendExternalID = "externalId"
+
+// maxNicknameLength is the maximum length of the iam user nickname.
+const maxNicknameLength = 30
+
+// User is the SCIM User resource. The id and the userName are both the iam user name.
+type User struct {
+	Schemas      []string      `json:"schemas"`
+	ID           string        `json:"id,omitempty"`
+	ExternalID   string        `json:"externalId,omitempty"`
+	UserName     string        `json:"userName"`
+	Name         *Name         `json:"name,omitempty"`
+	DisplayName  string        `json:"displayName,omitempty"`
+	Emails       []MultiValued `json:"emails,omitempty"`
+	PhoneNumbers []MultiValued `json:"phoneNumbers,omitempty"`
+	Active       *bool         `json:"active,omitempty"`
+	Password     string        `json:"password,omitempty"`
+	Meta         *Meta         `json:"meta,omitempty"`
+}
+
+// Name is the name attribute of the SCIM User resource.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// MultiValued is a value of the SCIM multi-valued attributes, e.g. emails and members.
+type MultiValued struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// CreateUser provisions a new iam user. A random password is set when the identity
+// provider does not send one, the user then logs in through the identity provider only.
+func (s *ScimController) CreateUser(c *gin.Context) {
+	log.L(c).Info("scim create user function called.")
+
+	var r User
+	if err := c.ShouldBindJSON(&r); err != nil {
+		writeError(c, errors.WithCode(code.ErrBind, err.Error()), "")
+
+		return
+	}
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: r.UserName},
+		Password:   r.Password,
+	}
+	r.apply(user)
+
+	if user.Password == "" {
+		user.Password = randomPassword()
+	}
+
+	errs := append(validator.Struct(user), validator.Var(field.NewPath("password"), user.Password, "password")...)
+	if len(errs) != 0 {
+		writeError(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), scimTypeInvalidValue)
+
+		return
+	}
+
+	user.Password, _ = auth.Encrypt(user.Password)
+	user.LoginedAt = time.Now()
+
+	if err := s.srv.Users().Create(c, user, metav1.CreateOptions{}); err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	writeResource(c, http.StatusCreated, newUser(user))
+}
+
+// GetUser returns the SCIM User resource of an active iam user.
+func (s *ScimController) GetUser(c *gin.Context) {
+	log.L(c).Info("scim get user function called.")
+
+	user, err := s.srv.Users().Get(c, c.Param("id"), metav1.GetOptions{})
+	if err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	writeResource(c, http.StatusOK, newUser(user))
+}
+
+// ListUsers lists the active iam users, only the `userName eq <name>` filter is supported.
+func (s *ScimController) ListUsers(c *gin.Context) {
+	log.L(c).Info("scim list users function called.")
+
+	f, err := parseFilter(c.Query("filter"))
+	if err != nil {
+		writeError(c, err, scimTypeInvalidFilter)
+
+		return
+	}
+
+	startIndex, offset, limit := listOptions(c)
+
+	if f != nil {
+		if f.Attribute != "username" {
+			writeError(c, errors.WithCode(code.ErrValidation, "unsupported filter attribute %s", f.Attribute),
+				scimTypeInvalidFilter)
+
+			return
+		}
+
+		user, err := s.srv.Users().Get(c, f.Value, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsCode(err, code.ErrUserNotFound) {
+				writeList(c, startIndex, 0, []interface{}{})
+
+				return
+			}
+
+			writeError(c, err, "")
+
+			return
+		}
+
+		writeList(c, startIndex, 1, []interface{}{newUser(user)})
+
+		return
+	}
+
+	users, err := s.srv.Users().List(c, metav1.ListOptions{Offset: &offset, Limit: &limit})
+	if err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	resources := make([]interface{}, 0, len(users.Items))
+	for _, user := range users.Items {
+		resources = append(resources, newUser(user))
+	}
+
+	writeList(c, startIndex, users.TotalCount, resources)
+}
+
+// PatchUser updates an iam user, the identity providers deprovision the users by setting
+// active to false. The inactive users are no longer visible to the SCIM clients.
+func (s *ScimController) PatchUser(c *gin.Context) {
+	log.L(c).Info("scim patch user function called.")
+
+	var r PatchRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		writeError(c, errors.WithCode(code.ErrBind, err.Error()), "")
+
+		return
+	}
+
+	ops, err := r.operations()
+	if err != nil {
+		writeError(c, err, scimTypeInvalidValue)
+
+		return
+	}
+
+	user, err := s.srv.Users().Get(c, c.Param("id"), metav1.GetOptions{})
+	if err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	if err := patchUser(user, ops); err != nil {
+		writeError(c, err, scimTypeInvalidPath)
+
+		return
+	}
+
+	if errs := validator.Struct(user); len(errs) != 0 {
+		writeError(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), scimTypeInvalidValue)
+
+		return
+	}
+
+	if err := s.srv.Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+		writeError(c, err, "")
+
+		return
+	}
+
+	writeResource(c, http.StatusOK, newUser(user))
+}
+
+// apply sets the fields of the iam user from the SCIM User resource.
+func (r *User) apply(user *v1.User) {
+	user.Nickname = r.nickname()
+	user.Email = primaryValue(r.Emails)
+	user.Phone = primaryValue(r.PhoneNumbers)
+
+	setActive(user, r.Active == nil || *r.Active)
+
+	if r.ExternalID != "" {
+		if user.Extend == nil {
+			user.Extend = metav1.Extend{}
+		}
+		user.Extend[extendExternalID] = r.ExternalID
+	}
+}
+
+// setActive activates or deactivates the iam user. The identity providers do not know about
+// the suspension, so a suspended user stays suspended until an administrator activates it.
+func setActive(user *v1.User, active bool) {
+	switch {
+	case !active:
+		user.Status = int(userstate.Deactivated)
+	case userstate.Of(user) != userstate.Suspended:
+		user.Status = int(userstate.Active)
+	}
+}
+
+// nickname returns the displayName, the formatted name or the userName, whichever is set first.
+func (r *User) nickname() string {
+	nickname := r.UserName
+
+	switch {
+	case r.DisplayName != "":
+		nickname = r.DisplayName
+	case r.Name != nil && r.Name.Formatted != "":
+		nickname = r.Name.Formatted
+	case r.Name != nil && (r.Name.GivenName != "" || r.Name.FamilyName != ""):
+		nickname = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+	}
+
+	return truncate(nickname, maxNicknameLength)
+}
+
+// patchUser applies the PATCH operations to the iam user. The operations on the
+// attributes not stored by iam are ignored.
+func patchUser(user *v1.User, ops []PatchOperation) error {
+	for _, op := range ops {
+		path, err := parsePath(op.Path)
+		if err != nil {
+			return err
+		}
+
+		switch path.Attribute {
+		case "active":
+			if op.Op == opRemove {
+				setActive(user, false)
+
+				continue
+			}
+
+			active, err := boolValue(op.Value)
+			if err != nil {
+				return err
+			}
+
+			setActive(user, active)
+		case "displayname", "name":
+			if op.Op == opRemove {
+				continue
+			}
+
+			if path.Attribute == "name" && path.SubAttribute != "formatted" {
+				continue
+			}
+
+			nickname, err := stringValue(op.Value)
+			if err != nil {
+				return err
+			}
+			user.Nickname = truncate(nickname, maxNicknameLength)
+		case "emails":
+			if op.Op == opRemove {
+				return errors.WithCode(code.ErrValidation, "emails can not be removed")
+			}
+
+			email, err := patchValue(path, op.Value)
+			if err != nil {
+				return err
+			}
+			user.Email = email
+		case "phonenumbers":
+			if op.Op == opRemove {
+				user.Phone = ""
+
+				continue
+			}
+
+			phone, err := patchValue(path, op.Value)
+			if err != nil {
+				return err
+			}
+			user.Phone = phone
+		case "externalid":
+			if user.Extend == nil {
+				user.Extend = metav1.Extend{}
+			}
+
+			if op.Op == opRemove {
+				delete(user.Extend, extendExternalID)
+
+				continue
+			}
+
+			externalID, err := stringValue(op.Value)
+			if err != nil {
+				return err
+			}
+			user.Extend[extendExternalID] = externalID
+		case "username":
+			userName, err := stringValue(op.Value)
+			if err != nil || userName != user.Name {
+				return errors.WithCode(code.ErrValidation, "userName can not be changed")
+			}
+		}
+	}
+
+	return nil
+}
+
+// patchValue returns the value of a PATCH operation on the emails or the phoneNumbers,
+// e.g. `emails[type eq "work"].value` with a string value or emails with a list value.
+func patchValue(path *patchPath, value json.RawMessage) (string, error) {
+	if path.SubAttribute == "value" {
+		return stringValue(value)
+	}
+
+	values, err := multiValues(value)
+	if err != nil {
+		return "", err
+	}
+
+	return primaryValue(values), nil
+}
+
+// newUser converts an iam user to a SCIM User resource.
+func newUser(user *v1.User) *User {
+	active := userstate.Of(user) == userstate.Active
+
+	r := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          user.Name,
+		UserName:    user.Name,
+		DisplayName: user.Nickname,
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt.Format(time.RFC3339),
+			LastModified: user.UpdatedAt.Format(time.RFC3339),
+			Location:     "/scim/v2/Users/" + user.Name,
+		},
+	}
+
+	if externalID, ok := user.Extend[extendExternalID].(string); ok {
+		r.ExternalID = externalID
+	}
+
+	if user.Email != "" {
+		r.Emails = []MultiValued{{Value: user.Email, Primary: true}}
+	}
+
+	if user.Phone != "" {
+		r.PhoneNumbers = []MultiValued{{Value: user.Phone, Primary: true}}
+	}
+
+	return r
+}
+
+// primaryValue returns the primary value or the first value.
+func primaryValue(values []MultiValued) string {
+	for _, v := range values {
+		if v.Primary {
+			return v.Value
+		}
+	}
+
+	if len(values) > 0 {
+		return values[0].Value
+	}
+
+	return ""
+}
+
+// randomPassword returns a password which satisfies the iam password rules.
+func randomPassword() string {
+	return "Aa1!" + idutil.NewSecretKey()[:12]
+}
+
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+
+	return s
+}
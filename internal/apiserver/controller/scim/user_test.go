@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+)
+
+func TestScimController_PatchUser(t *testing.T) {
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin"},
+		Status:     1,
+		Nickname:   "colin",
+		Password:   "Colin@2020",
+		Email:      "colin@foxmail.com",
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+"Operations":[{"op":"Replace","value":{"active":"False"}}]}`)
+	c.Request, _ = http.NewRequest("PATCH", "/scim/v2/Users/colin", body)
+	c.Params = []gin.Param{{Key: "id", Value: "colin"}}
+	c.Request.Header.Set("Content-Type", ContentType)
+
+	user2 := new(v1.User)
+	*user2 = *user
+	user2.Status = 0
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq("colin"), gomock.Any()).Return(user, nil)
+	mockUserSrv.EXPECT().Update(gomock.Any(), gomock.Eq(user2), gomock.Any()).Return(nil)
+	mockService.EXPECT().Users().Return(mockUserSrv).Times(2)
+
+	s := &ScimController{srv: mockService}
+	s.PatchUser(c)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("PatchUser() status = %d, want %d, body %s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func Test_patchUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     []PatchOperation
+		want    v1.User
+		wantErr bool
+	}{
+		{
+			name: "reactivate",
+			ops:  []PatchOperation{{Op: opReplace, Path: "active", Value: []byte(`true`)}},
+			want: v1.User{Status: 1, Nickname: "colin", Email: "colin@foxmail.com"},
+		},
+		{
+			name: "replace primary email and nickname",
+			ops: []PatchOperation{
+				{Op: opReplace, Path: `emails[type eq "work"].value`, Value: []byte(`"colin@example.com"`)},
+				{Op: opReplace, Path: "displayName", Value: []byte(`"Colin Kong"`)},
+			},
+			want: v1.User{Nickname: "Colin Kong", Email: "colin@example.com"},
+		},
+		{
+			name: "ignore unknown attributes",
+			ops:  []PatchOperation{{Op: opAdd, Path: "title", Value: []byte(`"engineer"`)}},
+			want: v1.User{Nickname: "colin", Email: "colin@foxmail.com"},
+		},
+		{
+			name:    "remove email",
+			ops:     []PatchOperation{{Op: opRemove, Path: "emails"}},
+			wantErr: true,
+		},
+		{
+			name:    "change userName",
+			ops:     []PatchOperation{{Op: opReplace, Path: "userName", Value: []byte(`"tony"`)}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &v1.User{Nickname: "colin", Email: "colin@foxmail.com"}
+			err := patchUser(user, tt.ops)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("patchUser() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if user.Status != tt.want.Status || user.Nickname != tt.want.Nickname || user.Email != tt.want.Email {
+				t.Errorf("patchUser() = %v, want %v", user, tt.want)
+			}
+		})
+	}
+}
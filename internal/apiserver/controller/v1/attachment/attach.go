@@ -0,0 +1,47 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Attach attaches the policy identified by name to a subject.
+func (a *AttachmentController) Attach(c *gin.Context) {
+	log.L(c).Info("attach policy function called.")
+
+	var r v1.PolicyAttachment
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	r.Username = c.GetString(middleware.UsernameKey)
+	r.PolicyName = c.Param("name")
+
+	if errs := r.Validate(); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	if err := a.srv.PolicyAttachments().Attach(c, &r, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, r)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// AttachmentController create a policy attachment handler used to handle request for attachment resource.
+type AttachmentController struct {
+	srv srvv1.Service
+}
+
+// NewAttachmentController creates a policy attachment handler.
+func NewAttachmentController(store store.Factory) *AttachmentController {
+	return &AttachmentController{
+		srv: srvv1.NewService(store),
+	}
+}
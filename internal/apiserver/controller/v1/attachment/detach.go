@@ -0,0 +1,28 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Detach detaches the policy identified by name from a subject.
+func (a *AttachmentController) Detach(c *gin.Context) {
+	log.L(c).Info("detach policy function called.")
+
+	if err := a.srv.PolicyAttachments().Detach(c, c.GetString(middleware.UsernameKey), c.Param("name"),
+		c.Param("subject"), metav1.DeleteOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
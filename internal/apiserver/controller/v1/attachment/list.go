@@ -0,0 +1,71 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// List return all policy attachments, optionally filtered by the subject field selector.
+func (a *AttachmentController) List(c *gin.Context) {
+	log.L(c).Info("list attachment function called.")
+
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	pagination.Complete(c, &r)
+
+	attachments, err := a.srv.PolicyAttachments().List(c, c.GetString(middleware.UsernameKey), r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, attachments)
+}
+
+// ListByPolicy return all subjects the policy identified by name is attached to.
+func (a *AttachmentController) ListByPolicy(c *gin.Context) {
+	log.L(c).Info("list policy attachment function called.")
+
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	pagination.Complete(c, &r)
+
+	selector := fmt.Sprintf("policyName=%s", c.Param("name"))
+	if r.FieldSelector != "" {
+		selector = r.FieldSelector + "," + selector
+	}
+	r.FieldSelector = selector
+
+	attachments, err := a.srv.PolicyAttachments().List(c, c.GetString(middleware.UsernameKey), r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, attachments)
+}
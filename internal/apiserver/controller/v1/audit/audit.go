@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// AuditController create a audit event handler used to handle request for audit event resource.
+type AuditController struct {
+	srv srvv1.Service
+}
+
+// NewAuditController creates a audit event handler.
+func NewAuditController(store store.Factory) *AuditController {
+	return &AuditController{
+		srv: srvv1.NewService(store),
+	}
+}
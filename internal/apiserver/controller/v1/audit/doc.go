@@ -0,0 +1,6 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package audit implements the audit event handlers.
+package audit // import "github.com/marmotedu/iam/internal/apiserver/controller/v1/audit"
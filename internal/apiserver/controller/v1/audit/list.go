@@ -0,0 +1,51 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// sortFields are the fields the audit events can be sorted by.
+var sortFields = []string{"username", "verb", "resource", "statusCode", "createdAt"}
+
+// List return the audit events, newest first unless sorted by the `sortBy` and `order`
+// parameters, optionally filtered by the `username`, `verb`, `resource` and `resourceName`
+// field selectors, and by the `since` and `afterID` parameters.
+// Only administrator can call this function.
+func (a *AuditController) List(c *gin.Context) {
+	log.L(c).Info("list audit event function called.")
+
+	var r v1.AuditEventListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	pagination.Complete(c, &r.ListOptions)
+	if errs := pagination.CompleteSort(c, sortFields...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	events, err := a.srv.AuditEvents().List(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, events)
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func TestAuditController_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	since := time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockAuditEventSrv := srvv1.NewMockAuditEventSrv(ctrl)
+	mockAuditEventSrv.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, opts v1.AuditEventListOptions) (*v1.AuditEventList, error) {
+			assert.Equal(t, "username=colin,verb=delete", opts.FieldSelector)
+			assert.True(t, since.Equal(*opts.Since))
+			assert.Equal(t, uint64(10), opts.AfterID)
+
+			return &v1.AuditEventList{}, nil
+		})
+	mockService.EXPECT().AuditEvents().Return(mockAuditEventSrv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET",
+		"/v1/audits?fieldSelector=username%3Dcolin%2Cverb%3Ddelete&since=2020-10-01T08:00:00Z&afterID=10", nil)
+
+	a := &AuditController{srv: mockService}
+	a.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
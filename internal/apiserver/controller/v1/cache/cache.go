@@ -10,12 +10,24 @@ import (
 	"fmt"
 	"sync"
 
+	v1 "github.com/marmotedu/api/apiserver/v1"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
 
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/authzserver/load"
+	"github.com/marmotedu/iam/internal/pkg/cachefilter"
+	"github.com/marmotedu/iam/internal/pkg/cachesync"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/membership"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/internal/pkg/shadow"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -52,14 +64,24 @@ func (c *Cache) ListSecrets(ctx context.Context, r *pb.ListSecretsRequest) (*pb.
 		Limit:  r.Limit,
 	}
 
-	secrets, err := c.store.Secrets().List(ctx, "", opts)
+	// the changes after the revision are not in the listed secrets
+	revision := load.CurrentSequence()
+
+	// an empty username lists the secrets of all users
+	username, _ := cachefilter.Username(r)
+	secrets, err := c.store.Secrets().List(ctx, username, opts)
 	if err != nil {
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
+	owners, err := c.owners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	items := make([]*pb.SecretInfo, 0)
 	for _, secret := range secrets.Items {
-		items = append(items, &pb.SecretInfo{
+		info := &pb.SecretInfo{
 			SecretId:    secret.SecretID,
 			Username:    secret.Username,
 			SecretKey:   secret.SecretKey,
@@ -67,13 +89,43 @@ func (c *Cache) ListSecrets(ctx context.Context, r *pb.ListSecretsRequest) (*pb.
 			Description: secret.Description,
 			CreatedAt:   secret.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:   secret.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		}
+
+		// never hand out a secret whose scope can not be honored
+		sc, err := scope.FromExtend(secret.Extend)
+		if err != nil {
+			log.L(ctx).Warnf("skip secret %s with invalid scope: %s", secret.SecretID, err.Error())
+
+			continue
+		}
+		scope.SetSecretInfo(info, sc)
+		sess, err := session.FromExtend(secret.Extend)
+		if err != nil {
+			log.L(ctx).Warnf("skip secret %s with invalid session: %s", secret.SecretID, err.Error())
+
+			continue
+		}
+		session.SetSecretInfo(info, sess)
+		var ownerTags tags.Tags
+		if owner, ok := owners[secret.Username]; ok {
+			// iam-authz-server denies the secrets of the users which are not active
+			userstate.SetSecretInfo(info, userstate.Of(owner))
+			ownerTags = tags.FromExtend(owner.Extend)
+		}
+		tags.SetSecretInfo(info, tags.FromExtend(secret.Extend), ownerTags)
+
+		items = append(items, info)
 	}
 
-	return &pb.ListSecretsResponse{
+	resp := &pb.ListSecretsResponse{
 		TotalCount: secrets.TotalCount,
 		Items:      items,
-	}, nil
+	}
+	if revision > 0 {
+		cachesync.SetRevision(resp, revision)
+	}
+
+	return resp, nil
 }
 
 // ListPolicies returns all policies.
@@ -84,23 +136,105 @@ func (c *Cache) ListPolicies(ctx context.Context, r *pb.ListPoliciesRequest) (*p
 		Limit:  r.Limit,
 	}
 
-	policies, err := c.store.Policies().List(ctx, "", opts)
+	revision := load.CurrentSequence()
+
+	// an empty username lists the policies of all users
+	username, _ := cachefilter.Username(r)
+	if membership.Only(r) {
+		return c.listMemberships(ctx, username)
+	}
+
+	// the policies are resolved as for the GET /v1/policies?forSubject requests
+	policies, err := srvv1.NewService(c.store).Policies().ListEffective(ctx, username, opts)
 	if err != nil {
-		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+		return nil, err
 	}
 
 	items := make([]*pb.PolicyInfo, 0)
 	for _, pol := range policies.Items {
+		policyShadow := pol.PolicyShadow
+		tenantName := tenant.FromExtend(pol.Extend)
+		policyTags := tags.FromExtend(pol.Extend)
+		if shadow.FromExtend(pol.Extend) || tenantName != "" || len(policyTags) > 0 {
+			// iam-authz-server only receives the ladon policy, so carry the shadow mode, the
+			// tenant and the tags in its metadata
+			authzPolicy := pol.Policy
+			if shadow.FromExtend(pol.Extend) {
+				shadow.Mark(&authzPolicy.DefaultPolicy)
+			}
+			if tenantName != "" {
+				tenant.Mark(&authzPolicy.DefaultPolicy, tenantName)
+			}
+			if len(policyTags) > 0 {
+				tags.Mark(&authzPolicy.DefaultPolicy, policyTags)
+			}
+			policyShadow = authzPolicy.String()
+		}
+
 		items = append(items, &pb.PolicyInfo{
 			Name:         pol.Name,
 			Username:     pol.Username,
-			PolicyShadow: pol.PolicyShadow,
+			PolicyShadow: policyShadow,
 			CreatedAt:    pol.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
 
-	return &pb.ListPoliciesResponse{
+	resp := &pb.ListPoliciesResponse{
 		TotalCount: policies.TotalCount,
 		Items:      items,
-	}, nil
+	}
+	if revision > 0 {
+		cachesync.SetRevision(resp, revision)
+	}
+
+	return resp, nil
+}
+
+// owners returns the users keyed by username, whose state and tags are sent along with
+// their secrets.
+func (c *Cache) owners(ctx context.Context) (map[string]*v1.User, error) {
+	limit := int64(-1)
+	owners := make(map[string]*v1.User)
+	// the users which are not active are only listed when their status is selected
+	for _, state := range []userstate.State{userstate.Active, userstate.Suspended, userstate.Deactivated} {
+		users, err := c.store.Users().List(ctx, metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("status=%d", state),
+			Limit:         &limit,
+		})
+		if err != nil {
+			return nil, errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		for _, user := range users.Items {
+			owners[user.Name] = user
+		}
+	}
+
+	return owners, nil
+}
+
+// listMemberships returns the members of all groups and roles, carried in an otherwise empty
+// policy list response.
+func (c *Cache) listMemberships(ctx context.Context, username string) (*pb.ListPoliciesResponse, error) {
+	limit := int64(-1)
+	groups, err := c.store.Groups().List(ctx, username, metav1.ListOptions{Limit: &limit})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	memberships := make([]membership.Membership, 0)
+	for _, group := range groups.Items {
+		for _, member := range group.Members {
+			memberships = append(memberships, membership.Membership{
+				Username: group.Username,
+				Group:    group.Subject(),
+				Member:   member,
+			})
+		}
+	}
+
+	resp := &pb.ListPoliciesResponse{Items: make([]*pb.PolicyInfo, 0)}
+	membership.Set(resp, memberships)
+
+	return resp, nil
 }
@@ -17,6 +17,9 @@ import (
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
 	"github.com/marmotedu/iam/internal/apiserver/store/fake"
+	"github.com/marmotedu/iam/internal/pkg/cachefilter"
+	"github.com/marmotedu/iam/internal/pkg/membership"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 )
 
 func TestGetCacheInsOr(t *testing.T) {
@@ -65,7 +68,9 @@ func TestCache_ListSecrets(t *testing.T) {
 
 	mockFactory := store.NewMockFactory(ctrl)
 	mockSecretStore := store.NewMockSecretStore(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
 	mockFactory.EXPECT().Secrets().Return(mockSecretStore)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
 	secrets := &v1.SecretList{
 		ListMeta: metav1.ListMeta{
 			TotalCount: 10,
@@ -91,6 +96,7 @@ func TestCache_ListSecrets(t *testing.T) {
 	}
 
 	mockSecretStore.EXPECT().List(gomock.Any(), gomock.Eq(""), gomock.Any()).Return(secrets, nil)
+	mockUserStore.EXPECT().List(gomock.Any(), gomock.Any()).Return(&v1.UserList{}, nil).Times(3)
 
 	type fields struct {
 		store store.Factory
@@ -142,20 +148,34 @@ func TestCache_ListPolicies(t *testing.T) {
 
 	mockFactory := store.NewMockFactory(ctrl)
 	mockPolicyStore := store.NewMockPolicyStore(ctrl)
+	mockPolicyAttachmentStore := store.NewMockPolicyAttachmentStore(ctrl)
 	mockFactory.EXPECT().Policies().Return(mockPolicyStore)
+	mockFactory.EXPECT().PolicyAttachments().Return(mockPolicyAttachmentStore)
 	policies := &v1.PolicyList{
 		ListMeta: metav1.ListMeta{
 			TotalCount: 10,
 		},
 		Items: fake.FakePolicies(3),
 	}
+	attachments := &apiv1.PolicyAttachmentList{
+		Items: []*apiv1.PolicyAttachment{
+			{Username: "user1", PolicyName: "policy1", Subject: "users:alice"},
+		},
+	}
 
 	wantItems := make([]*pb.PolicyInfo, 0)
 	for _, pol := range policies.Items {
+		shadow := pol.PolicyShadow
+		if pol.Name == "policy1" {
+			attached := pol.Policy
+			attached.Subjects = []string{"users:alice"}
+			shadow = attached.String()
+		}
+
 		wantItems = append(wantItems, &pb.PolicyInfo{
 			Name:         pol.Name,
 			Username:     pol.Username,
-			PolicyShadow: pol.PolicyShadow,
+			PolicyShadow: shadow,
 			CreatedAt:    pol.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
@@ -165,6 +185,7 @@ func TestCache_ListPolicies(t *testing.T) {
 		Items:      wantItems,
 	}
 	mockPolicyStore.EXPECT().List(gomock.Any(), gomock.Eq(""), gomock.Any()).Return(policies, nil)
+	mockPolicyAttachmentStore.EXPECT().List(gomock.Any(), gomock.Eq(""), gomock.Any()).Return(attachments, nil)
 
 	type fields struct {
 		store store.Factory
@@ -209,3 +230,52 @@ func TestCache_ListPolicies(t *testing.T) {
 		})
 	}
 }
+
+func TestCache_ListPoliciesMemberships(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockGroupStore := store.NewMockGroupStore(ctrl)
+	mockFactory.EXPECT().Groups().Return(mockGroupStore)
+	groups := &apiv1.GroupList{
+		Items: []*apiv1.Group{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "admins"},
+				Username:   "colin",
+				Kind:       "groups",
+				Members:    []string{"users:peter", "users:maria"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "auditor"},
+				Username:   "colin",
+				Kind:       "roles",
+				Members:    []string{"users:peter"},
+			},
+		},
+	}
+	mockGroupStore.EXPECT().List(gomock.Any(), gomock.Eq("colin"), gomock.Any()).Return(groups, nil)
+
+	req := &pb.ListPoliciesRequest{}
+	cachefilter.SetUsername(req, "colin")
+	membership.SetOnly(req)
+
+	c := &Cache{store: mockFactory}
+	got, err := c.ListPolicies(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("Cache.ListPolicies() error = %v", err)
+	}
+
+	if len(got.Items) != 0 {
+		t.Errorf("Cache.ListPolicies() returned %d policies, want none", len(got.Items))
+	}
+
+	want := []membership.Membership{
+		{Username: "colin", Group: "groups:admins", Member: "users:peter"},
+		{Username: "colin", Group: "groups:admins", Member: "users:maria"},
+		{Username: "colin", Group: "roles:auditor", Member: "users:peter"},
+	}
+	if !reflect.DeepEqual(membership.Get(got), want) {
+		t.Errorf("Cache.ListPolicies() memberships = %v, want %v", membership.Get(got), want)
+	}
+}
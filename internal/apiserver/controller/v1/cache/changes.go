@@ -0,0 +1,107 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"google.golang.org/grpc"
+
+	"github.com/marmotedu/iam/internal/authzserver/load"
+	"github.com/marmotedu/iam/internal/pkg/cachesync"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// ListChangesSince returns the changes of the secrets and policies after the revision.
+func (c *Cache) ListChangesSince(
+	ctx context.Context,
+	r *cachesync.ListChangesSinceRequest,
+) (*cachesync.ListChangesSinceResponse, error) {
+	log.L(ctx).Infof("list changes since %d function called.", r.Revision)
+
+	changes, revision, ok := load.ChangesSince(r.Revision)
+
+	return &cachesync.ListChangesSinceResponse{
+		Revision: revision,
+		Changes:  changes,
+		Reset:    !ok,
+	}, nil
+}
+
+// Register registers the cache service to the grpc server. It serves the methods of the
+// generated proto.Cache service, and ListChangesSince whose messages are decoded by the json
+// codec registered by the rpc package.
+func Register(s *grpc.Server, c *Cache) {
+	s.RegisterService(serviceDesc, c)
+}
+
+// changesServer is the server API of proto.Cache, along with ListChangesSince.
+type changesServer interface {
+	pb.CacheServer
+	ListChangesSince(context.Context, *cachesync.ListChangesSinceRequest) (*cachesync.ListChangesSinceResponse, error)
+}
+
+var serviceDesc = &grpc.ServiceDesc{
+	ServiceName: "proto.Cache",
+	HandlerType: (*changesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSecrets",
+			Handler: methodHandler("ListSecrets", func() interface{} { return new(pb.ListSecretsRequest) },
+				func(srv changesServer, ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.ListSecrets(ctx, req.(*pb.ListSecretsRequest))
+				}),
+		},
+		{
+			MethodName: "ListPolicies",
+			Handler: methodHandler("ListPolicies", func() interface{} { return new(pb.ListPoliciesRequest) },
+				func(srv changesServer, ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.ListPolicies(ctx, req.(*pb.ListPoliciesRequest))
+				}),
+		},
+		{
+			MethodName: "ListChangesSince",
+			Handler: methodHandler("ListChangesSince", func() interface{} { return new(cachesync.ListChangesSinceRequest) },
+				func(srv changesServer, ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.ListChangesSince(ctx, req.(*cachesync.ListChangesSinceRequest))
+				}),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/apiserver/v1/cache.proto",
+}
+
+func methodHandler(
+	name string,
+	newRequest func() interface{},
+	call func(srv changesServer, ctx context.Context, req interface{}) (interface{}, error),
+) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(
+		srv interface{},
+		ctx context.Context,
+		dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor,
+	) (interface{}, error) {
+		in := newRequest()
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		if interceptor == nil {
+			return call(srv.(changesServer), ctx, in)
+		}
+
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: "/proto.Cache/" + name,
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return call(srv.(changesServer), ctx, req)
+		}
+
+		return interceptor(ctx, in, info, handler)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// CompletionController create a completion handler used to complete the names of the resources.
+type CompletionController struct {
+	srv srvv1.Service
+}
+
+// NewCompletionController creates a completion handler.
+func NewCompletionController(store store.Factory) *CompletionController {
+	return &CompletionController{
+		srv: srvv1.NewService(store),
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// maxLimit is the maximum number of names returned by a completion.
+const maxLimit = 1000
+
+// List return the names of the resources of a kind which start with a prefix, e.g.
+// /v1/completions?kind=user&prefix=co. Only the names are read, so that it stays fast with
+// many resources.
+func (cc *CompletionController) List(c *gin.Context) {
+	log.L(c).Info("list completion function called.")
+
+	var r v1.CompletionOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if !isKind(r.Kind) {
+		errs := field.ErrorList{field.NotSupported(field.NewPath("kind"), r.Kind, v1.CompletionKinds)}
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	if r.Limit <= 0 {
+		r.Limit = v1.DefaultCompletionLimit
+	}
+	if r.Limit > maxLimit {
+		r.Limit = maxLimit
+	}
+
+	names, err := cc.srv.Completions().List(c, c.GetString(middleware.UsernameKey), r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, names)
+}
+
+func isKind(kind string) bool {
+	for _, k := range v1.CompletionKinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
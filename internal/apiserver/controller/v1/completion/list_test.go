@@ -0,0 +1,72 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func TestCompletionController_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name      string
+		query     string
+		wantOpts  *v1.CompletionOptions
+		wantCode  int
+		wantNames []string
+	}{
+		{
+			name:      "default limit",
+			query:     "kind=user&prefix=co",
+			wantOpts:  &v1.CompletionOptions{Kind: "user", Prefix: "co", Limit: v1.DefaultCompletionLimit},
+			wantCode:  http.StatusOK,
+			wantNames: []string{"colin"},
+		},
+		{
+			name:      "limit capped",
+			query:     "kind=policy&limit=5000",
+			wantOpts:  &v1.CompletionOptions{Kind: "policy", Limit: maxLimit},
+			wantCode:  http.StatusOK,
+			wantNames: []string{},
+		},
+		{
+			name:     "unknown kind",
+			query:    "kind=pod",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := srvv1.NewMockService(ctrl)
+			if tt.wantOpts != nil {
+				mockCompletionSrv := srvv1.NewMockCompletionSrv(ctrl)
+				mockCompletionSrv.EXPECT().List(gomock.Any(), gomock.Eq("colin"), gomock.Eq(*tt.wantOpts)).
+					Return(&v1.CompletionList{Kind: tt.wantOpts.Kind, Items: tt.wantNames}, nil)
+				mockService.EXPECT().Completions().Return(mockCompletionSrv)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/v1/completions?"+tt.query, nil)
+			c.Set("username", "colin")
+
+			cc := &CompletionController{srv: mockService}
+			cc.List(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
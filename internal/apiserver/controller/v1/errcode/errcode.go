@@ -0,0 +1,37 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package errcode
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+// ErrCodeList is the catalog of the business error codes.
+type ErrCodeList struct {
+	TotalCount int64          `json:"totalCount"`
+	Items      []code.ErrCode `json:"items"`
+}
+
+// ErrCodeController create an error code handler used to publish the error code catalog, so
+// that the clients can map the codes without hard-coding them.
+type ErrCodeController struct{}
+
+// NewErrCodeController creates an error code handler.
+func NewErrCodeController() *ErrCodeController {
+	return &ErrCodeController{}
+}
+
+// List returns every registered error code with its http status, message and reference.
+func (e *ErrCodeController) List(c *gin.Context) {
+	codes := code.List()
+
+	core.WriteResponse(c, nil, &ErrCodeList{
+		TotalCount: int64(len(codes)),
+		Items:      codes,
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package errcode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func TestErrCodeController_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/codes", nil)
+
+	NewErrCodeController().List(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("List() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var list ErrCodeList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("List() returned invalid json: %v", err)
+	}
+
+	if list.TotalCount != int64(len(list.Items)) || list.TotalCount == 0 {
+		t.Fatalf("List() totalCount = %d, items = %d", list.TotalCount, len(list.Items))
+	}
+
+	for i, item := range list.Items {
+		if i > 0 && list.Items[i-1].C >= item.C {
+			t.Errorf("List() is not sorted by code at %d", i)
+		}
+		if item.C == code.ErrUserNotFound && (item.HTTP != http.StatusNotFound || item.Ext != "User not found") {
+			t.Errorf("List() ErrUserNotFound = %+v", item)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package gateway implements the iam.apiserver.v1.API grpc service with the rest handlers of
+// iam-apiserver, so that both apis authenticate, validate and publish the changes the same way.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/grpcerror"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/sdk/rpc"
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// forwardedMetadata are the metadata sent to the rest handlers as the request headers.
+var forwardedMetadata = []string{"authorization", "x-request-id"}
+
+// GatewayController serves the grpc api by the rest handler.
+type GatewayController struct {
+	handler http.Handler
+}
+
+// NewGatewayController creates a grpc api handler, handler is the gin engine of iam-apiserver.
+func NewGatewayController(handler http.Handler) *GatewayController {
+	return &GatewayController{handler: handler}
+}
+
+// Serve calls the rest api of the method with the request.
+func (g *GatewayController) Serve(ctx context.Context, m rpc.Method, req *rpc.Request) (*rpc.Response, error) {
+	log.L(ctx).Infof("grpc %s function called.", m.Name)
+
+	// the body is validated by the rest api, with the same validator
+	if m.Named {
+		if errs := validator.Var(field.NewPath("name"), req.Name, "required"); len(errs) != 0 {
+			return nil, grpcerror.Status(validation.NewError(errs)).Err()
+		}
+	}
+
+	u := url.URL{Path: m.Path(req.Name), RawQuery: req.Query.Encode()}
+	r, err := http.NewRequestWithContext(ctx, m.HTTPMethod, u.RequestURI(), bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, grpcerror.New(code.ErrValidation, http.StatusBadRequest, err.Error(), "").Err()
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range forwardedMetadata {
+		if values := md.Get(key); len(values) > 0 {
+			r.Header.Set(key, values[0])
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	w := newResponseWriter()
+	g.handler.ServeHTTP(w, r)
+
+	// the error responses are translated like the errors of the other grpc services
+	if w.status != http.StatusOK {
+		return nil, grpcerror.FromResponse(w.status, w.body.Bytes()).Err()
+	}
+
+	return &rpc.Response{Body: w.body.Bytes()}, nil
+}
+
+// responseWriter records the response of the rest handler.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: http.Header{}, status: http.StatusOK}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// Register registers the grpc api to the grpc server. The messages are decoded by the json
+// codec registered by the rpc package.
+func Register(s *grpc.Server, g *GatewayController) {
+	s.RegisterService(serviceDesc(), g)
+}
+
+// apiServer is the server API of iam.apiserver.v1.API.
+type apiServer interface {
+	Serve(ctx context.Context, m rpc.Method, req *rpc.Request) (*rpc.Response, error)
+}
+
+// serviceDesc returns the grpc.ServiceDesc of iam.apiserver.v1.API.
+func serviceDesc() *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{
+		ServiceName: rpc.ServiceName,
+		HandlerType: (*apiServer)(nil),
+		Streams:     []grpc.StreamDesc{},
+		Metadata:    "iam/apiserver/v1/api.proto",
+	}
+
+	for _, m := range rpc.Methods() {
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: m.Name,
+			Handler:    methodHandler(m),
+		})
+	}
+
+	return desc
+}
+
+func methodHandler(m rpc.Method) func(interface{}, context.Context, func(interface{}) error,
+	grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(
+		srv interface{},
+		ctx context.Context,
+		dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor,
+	) (interface{}, error) {
+		in := new(rpc.Request)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		if interceptor == nil {
+			return srv.(apiServer).Serve(ctx, m, in)
+		}
+
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: m.FullName(),
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.(apiServer).Serve(ctx, m, req.(*rpc.Request))
+		}
+
+		return interceptor(ctx, in, info, handler)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gateway
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/pkg/sdk/rpc"
+)
+
+func newEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+
+	users := g.Group("/v1/users", func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "Bearer token" {
+			core.WriteResponse(c, errors.WithCode(code.ErrTokenInvalid, "invalid token"), nil)
+			c.Abort()
+		}
+	})
+	users.GET(":name", func(c *gin.Context) {
+		if c.Param("name") != "colin" {
+			core.WriteResponse(c, errors.WithCode(code.ErrUserNotFound, "user not found"), nil)
+
+			return
+		}
+
+		core.WriteResponse(c, nil, &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Nickname: "colin"})
+	})
+	users.GET("", func(c *gin.Context) {
+		list := &v1.UserList{ListMeta: metav1.ListMeta{TotalCount: 42}}
+		list.Items = append(list.Items, &v1.User{ObjectMeta: metav1.ObjectMeta{Name: c.Query("offset") + "-" + c.Query("limit")}})
+		core.WriteResponse(c, nil, list)
+	})
+	users.POST("", func(c *gin.Context) {
+		var user v1.User
+		if err := c.ShouldBindJSON(&user); err != nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+			return
+		}
+
+		user.Status = 1
+		core.WriteResponse(c, nil, &user)
+	})
+
+	return g
+}
+
+func newClient(t *testing.T) *rpc.APIV1Client {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	Register(server, NewGatewayController(newEngine()))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return rpc.NewAPIV1Client(conn)
+}
+
+func TestGatewayController(t *testing.T) {
+	client := newClient(t)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(ctx context.Context) (string, error)
+		want    string
+		wantErr string
+	}{
+		{
+			name: "get",
+			ctx:  ctx,
+			call: func(ctx context.Context) (string, error) {
+				user, err := client.Users().Get(ctx, "colin", metav1.GetOptions{})
+
+				return user.Nickname, err
+			},
+			want: "colin",
+		},
+		{
+			name: "not found",
+			ctx:  ctx,
+			call: func(ctx context.Context) (string, error) {
+				user, err := client.Users().Get(ctx, "alice", metav1.GetOptions{})
+
+				return user.Name, err
+			},
+			wantErr: `"code":110001`,
+		},
+		{
+			name: "unauthenticated",
+			ctx:  context.Background(),
+			call: func(ctx context.Context) (string, error) {
+				user, err := client.Users().Get(ctx, "colin", metav1.GetOptions{})
+
+				return user.Name, err
+			},
+			wantErr: `"code":100005`,
+		},
+		{
+			name: "list with options",
+			ctx:  ctx,
+			call: func(ctx context.Context) (string, error) {
+				list, err := client.Users().List(ctx, metav1.ListOptions{
+					Offset: pointer.ToInt64(20),
+					Limit:  pointer.ToInt64(10),
+				})
+				if err != nil {
+					return "", err
+				}
+
+				return list.Items[0].Name, nil
+			},
+			want: "20-10",
+		},
+		{
+			name: "create",
+			ctx:  ctx,
+			call: func(ctx context.Context) (string, error) {
+				user, err := client.Users().Create(ctx, &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "bob"}}, metav1.CreateOptions{})
+				if err != nil {
+					return "", err
+				}
+
+				return user.Name, nil
+			},
+			want: "bob",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call(tt.ctx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("call error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("call = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayController_NameRequired(t *testing.T) {
+	_, err := NewGatewayController(newEngine()).Serve(context.Background(), rpc.GetUser, &rpc.Request{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Serve() error = %v, want the name required", err)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// GroupController create a group handler used to handle request for group resource.
+type GroupController struct {
+	srv srvv1.Service
+}
+
+// NewGroupController creates a group handler.
+func NewGroupController(store store.Factory) *GroupController {
+	return &GroupController{
+		srv: srvv1.NewService(store),
+	}
+}
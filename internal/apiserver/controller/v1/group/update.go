@@ -0,0 +1,56 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Update updates the members and the description of a group.
+func (g *GroupController) Update(c *gin.Context) {
+	log.L(c).Info("update group function called.")
+
+	var r v1.Group
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	group, err := g.srv.Groups().Get(c, c.GetString(middleware.UsernameKey), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// the kind is part of the group subject, so it can not be changed
+	group.Members = r.Members
+	group.Description = r.Description
+	group.Extend = r.Extend
+
+	if errs := group.Validate(); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	if err := g.srv.Groups().Update(c, group, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, group)
+}
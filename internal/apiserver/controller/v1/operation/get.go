@@ -0,0 +1,28 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package operation
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Get return the status, the progress and the result of an operation, it is polled by the
+// clients until the operation is done.
+func (o *OperationController) Get(c *gin.Context) {
+	log.L(c).Info("get operation function called.")
+
+	op, err := o.srv.Operations().Get(c, c.Param("id"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, op)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package operation
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// OperationController create an operation handler used to handle request for long-running operation resource.
+type OperationController struct {
+	srv srvv1.Service
+}
+
+// NewOperationController creates an operation handler.
+func NewOperationController(store store.Factory) *OperationController {
+	return &OperationController{
+		srv: srvv1.NewService(store),
+	}
+}
@@ -13,7 +13,10 @@ import (
 
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/policylint"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 // Create creates a new ladon policy.
@@ -28,8 +31,8 @@ func (p *PolicyController) Create(c *gin.Context) {
 		return
 	}
 
-	if errs := r.Validate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+	if errs := append(validator.Struct(&r), policylint.LintPolicy(&r).FieldErrors()...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
 
 		return
 	}
@@ -37,7 +40,7 @@ func (p *PolicyController) Create(c *gin.Context) {
 	r.Username = c.GetString(middleware.UsernameKey)
 
 	if err := p.srv.Policies().Create(c, &r, metav1.CreateOptions{}); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
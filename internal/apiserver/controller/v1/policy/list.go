@@ -6,15 +6,23 @@ package policy
 
 import (
 	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
 	"github.com/marmotedu/component-base/pkg/core"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
+// sortFields are the fields the policies can be sorted by.
+var sortFields = []string{"name", "createdAt", "updatedAt"}
+
 // List return all policies.
 func (p *PolicyController) List(c *gin.Context) {
 	log.L(c).Info("list policy function called.")
@@ -26,7 +34,29 @@ func (p *PolicyController) List(c *gin.Context) {
 		return
 	}
 
-	policies, err := p.srv.Policies().List(c, c.GetString(middleware.UsernameKey), r)
+	pagination.Complete(c, &r)
+	if errs := pagination.CompleteSort(c, sortFields...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	username := c.GetString(middleware.UsernameKey)
+
+	var policies *v1.PolicyList
+	var err error
+	if forSubject := c.Query("forSubject"); forSubject != "" {
+		subject, errs := parseSubject(forSubject)
+		if len(errs) != 0 {
+			validation.WriteResponse(c, validation.NewError(errs), nil)
+
+			return
+		}
+
+		policies, err = p.srv.Policies().ListForSubject(c, username, subject, r)
+	} else {
+		policies, err = p.srv.Policies().List(c, username, r)
+	}
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 
@@ -35,3 +65,25 @@ func (p *PolicyController) List(c *gin.Context) {
 
 	core.WriteResponse(c, nil, policies)
 }
+
+// parseSubject returns the ladon subject of the forSubject query parameter. The kinds are also
+// accepted in the singular, e.g. user:colin for users:colin.
+func parseSubject(value string) (string, field.ErrorList) {
+	fldPath := field.NewPath("forSubject")
+
+	kind, name, ok := apiv1.SplitSubject(value)
+	if !ok {
+		return "", field.ErrorList{field.Invalid(fldPath, value, "must be in the format of <kind>:<name>")}
+	}
+
+	switch kind {
+	case "user", "group", "role":
+		kind += "s"
+	case apiv1.SubjectKindUser, apiv1.SubjectKindGroup, apiv1.SubjectKindRole:
+	default:
+		return "", field.ErrorList{field.NotSupported(fldPath, value,
+			[]string{apiv1.SubjectKindUser, apiv1.SubjectKindGroup, apiv1.SubjectKindRole})}
+	}
+
+	return kind + ":" + name, nil
+}
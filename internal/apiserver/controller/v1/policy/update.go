@@ -13,7 +13,11 @@ import (
 
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/policylint"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 // Update updates policy by the policy identifier.
@@ -38,14 +42,20 @@ func (p *PolicyController) Update(c *gin.Context) {
 	pol.Policy = r.Policy
 	pol.Extend = r.Extend
 
-	if errs := pol.Validate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+	if errs := append(validator.Struct(pol), policylint.LintPolicy(pol).FieldErrors()...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	if errs := resourceversion.ValidateExtend(pol.Extend); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
 
 		return
 	}
 
 	if err := p.srv.Policies().Update(c, pol, metav1.UpdateOptions{}); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
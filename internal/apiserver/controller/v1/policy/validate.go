@@ -0,0 +1,30 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/policylint"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Validate lints a policy without storing it, the syntax errors are reported as findings
+// like the other problems, e.g. for the policies managed as code in CI pipelines.
+func (p *PolicyController) Validate(c *gin.Context) {
+	log.L(c).Info("validate policy function called.")
+
+	data, err := c.GetRawData()
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, policylint.Lint(data))
+}
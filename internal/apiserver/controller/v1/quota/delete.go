@@ -0,0 +1,26 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Delete deletes the quota of a tenant, the tenant is unlimited then.
+func (q *QuotaController) Delete(c *gin.Context) {
+	log.L(c).Info("delete quota function called.")
+
+	if err := q.srv.Quotas().Delete(c, c.Param("name"), metav1.DeleteOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Get return the quota of a tenant along with its usage.
+func (q *QuotaController) Get(c *gin.Context) {
+	log.L(c).Info("get quota function called.")
+
+	quota, err := q.srv.Quotas().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, quota)
+}
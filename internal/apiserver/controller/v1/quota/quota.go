@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// QuotaController create a quota handler used to handle request for tenant quota resource.
+type QuotaController struct {
+	srv srvv1.Service
+}
+
+// NewQuotaController creates a quota handler.
+func NewQuotaController(store store.Factory) *QuotaController {
+	return &QuotaController{
+		srv: srvv1.NewService(store),
+	}
+}
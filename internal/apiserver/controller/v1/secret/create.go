@@ -11,11 +11,17 @@ import (
 	"github.com/marmotedu/component-base/pkg/core"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/component-base/pkg/validation/field"
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 const maxSecretCount = 10
@@ -32,17 +38,24 @@ func (s *SecretController) Create(c *gin.Context) {
 		return
 	}
 
-	if errs := r.Validate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+	errs := append(validator.Struct(&r), scope.ValidateExtend(r.Extend)...)
+	errs = append(errs, tags.ValidateExtend(r.Extend)...)
+	// the sessions are issued by assume-role only
+	if _, ok := r.Extend[session.ExtendKey]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("extend", session.ExtendKey), "can only be set by assume-role"))
+	}
+	if len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
 
 		return
 	}
 
 	username := c.GetString(middleware.UsernameKey)
 
+	// the secrets are counted by the database, a single one is loaded
 	secrets, err := s.srv.Secrets().List(c, username, metav1.ListOptions{
 		Offset: pointer.ToInt64(0),
-		Limit:  pointer.ToInt64(-1),
+		Limit:  pointer.ToInt64(1),
 	})
 	if err != nil {
 		core.WriteResponse(c, err, nil)
@@ -64,7 +77,7 @@ func (s *SecretController) Create(c *gin.Context) {
 	r.SecretKey = idutil.NewSecretKey()
 
 	if err := s.srv.Secrets().Create(c, &r, metav1.CreateOptions{}); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
@@ -24,5 +24,12 @@ func (s *SecretController) Get(c *gin.Context) {
 		return
 	}
 
-	core.WriteResponse(c, nil, secret)
+	items, err := s.withLastUsed(c, secret)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, items[0])
 }
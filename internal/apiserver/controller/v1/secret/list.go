@@ -12,9 +12,15 @@ import (
 
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
+// sortFields are the fields the secrets can be sorted by.
+var sortFields = []string{"name", "expires", "createdAt", "updatedAt"}
+
 // List list all the secrets.
 func (s *SecretController) List(c *gin.Context) {
 	log.L(c).Info("list secret function called.")
@@ -25,6 +31,13 @@ func (s *SecretController) List(c *gin.Context) {
 		return
 	}
 
+	pagination.Complete(c, &r)
+	if errs := pagination.CompleteSort(c, sortFields...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
 	secrets, err := s.srv.Secrets().List(c, c.GetString(middleware.UsernameKey), r)
 	if err != nil {
 		core.WriteResponse(c, err, nil)
@@ -32,5 +45,12 @@ func (s *SecretController) List(c *gin.Context) {
 		return
 	}
 
-	core.WriteResponse(c, nil, secrets)
+	items, err := s.withLastUsed(c, secrets.Items...)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, &apiv1.SecretList{ListMeta: secrets.ListMeta, Items: items})
 }
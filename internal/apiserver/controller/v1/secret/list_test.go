@@ -0,0 +1,75 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+)
+
+func TestSecretController_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usedAt := time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC)
+	secrets := &v1.SecretList{
+		ListMeta: metav1.ListMeta{TotalCount: 2},
+		Items: []*v1.Secret{
+			{ObjectMeta: metav1.ObjectMeta{Name: "used"}, Username: "colin", SecretID: "id-used"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "unused"}, Username: "colin", SecretID: "id-unused"},
+		},
+	}
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockSecretSrv := srvv1.NewMockSecretSrv(ctrl)
+	mockSecretSrv.EXPECT().List(gomock.Any(), gomock.Eq("colin"), gomock.Any()).Return(secrets, nil)
+	mockSecretSrv.EXPECT().LastUsed(gomock.Any(), gomock.Eq([]string{"id-used", "id-unused"})).
+		Return(map[string]time.Time{"id-used": usedAt}, nil)
+	mockService.EXPECT().Secrets().Return(mockSecretSrv).Times(2)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/v1/secrets", nil)
+	c.Set(middleware.UsernameKey, "colin")
+
+	s := &SecretController{srv: mockService}
+	s.List(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("SecretController.List() status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	var got struct {
+		TotalCount int64 `json:"totalCount"`
+		Items      []struct {
+			Name       string     `json:"name"`
+			LastUsedAt *time.Time `json:"lastUsedAt"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.TotalCount != 2 || len(got.Items) != 2 {
+		t.Fatalf("SecretController.List() = %s", w.Body.String())
+	}
+	if got.Items[0].LastUsedAt == nil || !got.Items[0].LastUsedAt.Equal(usedAt) {
+		t.Errorf("lastUsedAt of the used secret = %v, want %v", got.Items[0].LastUsedAt, usedAt)
+	}
+	if got.Items[1].LastUsedAt != nil {
+		t.Errorf("lastUsedAt of the unused secret = %v, want unset", got.Items[1].LastUsedAt)
+	}
+}
@@ -5,8 +5,12 @@
 package secret
 
 import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+
 	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 )
 
 // SecretController create a secret handler used to handle request for secret resource.
@@ -20,3 +24,27 @@ func NewSecretController(store store.Factory) *SecretController {
 		srv: srvv1.NewService(store),
 	}
 }
+
+// withLastUsed returns the secrets along with the last time they were used.
+func (s *SecretController) withLastUsed(c *gin.Context, secrets ...*v1.Secret) ([]*apiv1.Secret, error) {
+	secretIDs := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		secretIDs = append(secretIDs, secret.SecretID)
+	}
+
+	lastUsed, err := s.srv.Secrets().LastUsed(c, secretIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*apiv1.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		item := &apiv1.Secret{Secret: secret}
+		if at, ok := lastUsed[secret.SecretID]; ok {
+			item.LastUsedAt = &at
+		}
+		ret = append(ret, item)
+	}
+
+	return ret, nil
+}
@@ -11,9 +11,16 @@ import (
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
 
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 // Update update a key by the secret key identifier.
@@ -37,19 +44,22 @@ func (s *SecretController) Update(c *gin.Context) {
 		return
 	}
 
-	// only update expires and description
-	secret.Expires = r.Expires
+	// only update expires and description, the temporary credentials keep their lifetime
+	if _, ok := secret.Extend[session.ExtendKey]; !ok {
+		secret.Expires = r.Expires
+	}
 	secret.Description = r.Description
-	secret.Extend = r.Extend
+	secret.Extend = adminscope.Keep(r.Extend, secret.Extend, session.ExtendKey)
 
-	if errs := secret.Validate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+	errs := append(validator.Struct(secret), scope.ValidateExtend(secret.Extend)...)
+	if errs = append(append(errs, tags.ValidateExtend(secret.Extend)...), resourceversion.ValidateExtend(secret.Extend)...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
 
 		return
 	}
 
 	if err := s.srv.Secrets().Update(c, secret, metav1.UpdateOptions{}); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
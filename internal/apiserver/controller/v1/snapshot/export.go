@@ -0,0 +1,71 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/snapshot"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Export streams a snapshot of all resources, compressed with gzip when the compress query
+// parameter is true and encrypted with the passphrase of the X-Snapshot-Passphrase header. The
+// personal data of the users are pseudonymized, and the credentials dropped, with the key of the
+// X-Snapshot-Pseudonymization-Key header, if any.
+func (s *SnapshotController) Export(c *gin.Context) {
+	log.L(c).Info("export snapshot function called.")
+
+	var pseudonymizer *snapshot.Pseudonymizer
+	if key := c.GetHeader(PseudonymizationKeyHeader); key != "" {
+		var err error
+		if pseudonymizer, err = snapshot.NewPseudonymizer(key); err != nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrValidation, err.Error()), nil)
+
+			return
+		}
+	}
+
+	compress, _ := strconv.ParseBool(c.Query("compress"))
+	passphrase := c.GetHeader(PassphraseHeader)
+	w, err := snapshot.NewWriter(c.Writer, passphrase, compress)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+	w.Pseudonymize(pseudonymizer)
+
+	contentType := "application/x-ndjson"
+	if compress || passphrase != "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="iam-%s.snapshot"`, time.Now().Format("20060102150405")))
+
+	if err = s.srv.Snapshots().Export(c, w); err == nil {
+		err = w.Close()
+	}
+
+	if err == nil {
+		return
+	}
+
+	// the snapshot streamed so far lacks its end record, it is rejected by the import
+	log.L(c).Errorf("export snapshot failed: %s", err.Error())
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		core.WriteResponse(c, errors.WithCode(code.ErrDatabase, err.Error()), nil)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/snapshot"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Import creates the resources of the snapshot of the request body which do not exist yet, and
+// returns the numbers of the created and of the skipped resources.
+func (s *SnapshotController) Import(c *gin.Context) {
+	log.L(c).Info("import snapshot function called.")
+
+	r, err := snapshot.NewReader(c.Request.Body, c.GetHeader(PassphraseHeader))
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrInvalidSnapshot, err.Error()), nil)
+
+		return
+	}
+
+	result, err := s.srv.Snapshots().Import(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, result)
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// PassphraseHeader is the header of the passphrase encrypting the exported snapshot and
+// decrypting the imported one.
+const PassphraseHeader = "X-Snapshot-Passphrase"
+
+// PseudonymizationKeyHeader is the header of the key pseudonymizing the personal data of the
+// exported snapshot.
+const PseudonymizationKeyHeader = "X-Snapshot-Pseudonymization-Key"
+
+// SnapshotController create a snapshot handler used to export and import all resources.
+type SnapshotController struct {
+	srv srvv1.Service
+}
+
+// NewSnapshotController creates a snapshot handler.
+func NewSnapshotController(store store.Factory) *SnapshotController {
+	return &SnapshotController{
+		srv: srvv1.NewService(store),
+	}
+}
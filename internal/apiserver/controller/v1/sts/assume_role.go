@@ -0,0 +1,67 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sts
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// AssumeRole exchanges the credentials of the user for temporary credentials, which expire
+// after the requested duration and only authorize what the session policies allow as well.
+func (s *STSController) AssumeRole(c *gin.Context) {
+	log.L(c).Info("assume role function called.")
+
+	var r v1.AssumeRoleRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if errs := validator.Struct(&r); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	duration := session.DefaultDuration
+	if r.DurationSeconds != 0 {
+		duration = time.Duration(r.DurationSeconds) * time.Second
+	}
+
+	// the duration is bounded by the service
+	sess := &session.Session{Name: r.SessionName, Policies: r.Policies}
+	if errs := sess.Validate(nil); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	secret, err := s.srv.STS().AssumeRole(c, c.GetString(middleware.UsernameKey), sess, duration)
+	if err != nil {
+		validation.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, v1.Credentials{
+		SecretID:    secret.SecretID,
+		SecretKey:   secret.SecretKey,
+		Expires:     secret.Expires,
+		SessionName: sess.Name,
+	})
+}
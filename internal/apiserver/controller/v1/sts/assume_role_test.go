@@ -0,0 +1,75 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/pkg/session"
+)
+
+func TestSTSController_AssumeRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	policies := `"policies":[{"effect":"allow","actions":["get"],"resources":["resources:articles:<.*>"]}]`
+
+	tests := []struct {
+		name         string
+		body         string
+		wantDuration time.Duration
+		wantCode     int
+	}{
+		{
+			name:         "default duration",
+			body:         `{"sessionName":"ci-build",` + policies + `}`,
+			wantDuration: session.DefaultDuration,
+			wantCode:     http.StatusOK,
+		},
+		{
+			name:         "requested duration",
+			body:         `{"sessionName":"ci-build","durationSeconds":900,` + policies + `}`,
+			wantDuration: 15 * time.Minute,
+			wantCode:     http.StatusOK,
+		},
+		{
+			name:     "no policies",
+			body:     `{"sessionName":"ci-build"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := srvv1.NewMockService(ctrl)
+			if tt.wantDuration != 0 {
+				mockSTSSrv := srvv1.NewMockSTSSrv(ctrl)
+				mockSTSSrv.EXPECT().AssumeRole(gomock.Any(), gomock.Eq("colin"), gomock.Any(), gomock.Eq(tt.wantDuration)).
+					Return(&v1.Secret{SecretID: "id", SecretKey: "key"}, nil)
+				mockService.EXPECT().STS().Return(mockSTSSrv)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/sts/assume-role", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("username", "colin")
+
+			s := &STSController{srv: mockService}
+			s.AssumeRole(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
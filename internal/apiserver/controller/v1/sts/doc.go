@@ -0,0 +1,6 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package sts implements the security token service handlers, which issue temporary credentials.
+package sts // import "github.com/marmotedu/iam/internal/apiserver/controller/v1/sts"
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sts
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// STSController create a security token service handler used to issue temporary credentials.
+type STSController struct {
+	srv srvv1.Service
+}
+
+// NewSTSController creates a security token service handler.
+func NewSTSController(store store.Factory) *STSController {
+	return &STSController{
+		srv: srvv1.NewService(store),
+	}
+}
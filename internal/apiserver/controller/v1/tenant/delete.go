@@ -0,0 +1,31 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tenant
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Delete deletes a tenant in the background, along with its users, their secrets and their
+// policies. It returns the operation to poll with 202 Accepted.
+func (t *TenantController) Delete(c *gin.Context) {
+	log.L(c).Info("delete tenant function called.")
+
+	op, err := t.srv.Tenants().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	c.Header("Location", "/v1/operations/"+op.Name)
+	c.JSON(http.StatusAccepted, op)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tenant
+
+import (
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// TenantController create a tenant handler used to handle request for tenant resource.
+type TenantController struct {
+	srv srvv1.Service
+}
+
+// NewTenantController creates a tenant handler.
+func NewTenantController(store store.Factory) *TenantController {
+	return &TenantController{
+		srv: srvv1.NewService(store),
+	}
+}
@@ -0,0 +1,6 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package token implements the jwt token revocation handler.
+package token // import "github.com/marmotedu/iam/internal/apiserver/controller/v1/token"
@@ -0,0 +1,100 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/revocation"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// Revoke revokes a token, the tokens with an id or the tokens of a user, both iam-apiserver
+// and iam-authz-server reject them at once.
+func (t *TokenController) Revoke(c *gin.Context) {
+	log.L(c).Info("revoke token function called.")
+
+	var r v1.RevokeTokenRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if errs := validator.Struct(&r); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	var err error
+	switch {
+	case r.Token != "" && r.JTI == "" && r.Username == "":
+		// an expired token is rejected anyway
+		if ttl := t.tokenTTL(r.Token); ttl > 0 {
+			err = revocation.RevokeToken(c, r.Token, ttl)
+		}
+	case r.JTI != "" && r.Token == "" && r.Username == "":
+		if !c.GetBool(middleware.PlatformAdminKey) {
+			core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, "only administrators revoke the tokens by id"), nil)
+
+			return
+		}
+		err = revocation.RevokeID(c, r.JTI, t.ttl)
+	case r.Username != "" && r.Token == "" && r.JTI == "":
+		if caller := c.GetString(middleware.UsernameKey); caller != r.Username {
+			if err := t.srv.Delegations().Check(c, caller, r.Username); err != nil {
+				core.WriteResponse(c, err, nil)
+
+				return
+			}
+		}
+		err = revocation.RevokeUser(c, r.Username, t.ttl)
+	default:
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, "exactly one of token, jti and username must be set"), nil)
+
+		return
+	}
+
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, "revoke token failed: %s", err.Error()), nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// tokenTTL returns the remaining lifetime of the token, capped by revocation.MaxTTL. The token
+// is not verified, the tokens which can not be parsed are revoked for the lifetime of the
+// tokens of iam-apiserver.
+func (t *TokenController) tokenTTL(token string) time.Duration {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return t.ttl
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return t.ttl
+	}
+
+	ttl := time.Until(time.Unix(int64(exp), 0))
+	if ttl > revocation.MaxTTL {
+		ttl = revocation.MaxTTL
+	}
+
+	return ttl
+}
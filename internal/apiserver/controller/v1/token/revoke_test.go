@@ -0,0 +1,60 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/marmotedu/errors"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func TestTokenController_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "several fields", body: `{"jti":"id","username":"colin"}`, wantCode: http.StatusBadRequest},
+		{name: "no field", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "id by a user", body: `{"jti":"id"}`, wantCode: http.StatusForbidden},
+		{name: "other user", body: `{"username":"peter"}`, wantCode: http.StatusForbidden},
+		{name: "expired token", body: `{"token":"eyJhbGciOiJIUzI1NiJ9.eyJleHAiOjF9.c2lnbmF0dXJl"}`, wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := srvv1.NewMockService(ctrl)
+			if strings.Contains(tt.body, "peter") {
+				mockDelegationSrv := srvv1.NewMockDelegationSrv(ctrl)
+				mockDelegationSrv.EXPECT().Check(gomock.Any(), gomock.Eq("colin"), gomock.Eq("peter")).
+					Return(errors.WithCode(code.ErrPermissionDenied, "user colin is not a administrator"))
+				mockService.EXPECT().Delegations().Return(mockDelegationSrv)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/tokens/revoke", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("username", "colin")
+
+			tc := &TokenController{srv: mockService, ttl: time.Hour}
+			tc.Revoke(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
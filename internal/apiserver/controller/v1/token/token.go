@@ -0,0 +1,28 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"time"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+)
+
+// TokenController create a token handler used to revoke the jwt tokens.
+type TokenController struct {
+	srv srvv1.Service
+	// ttl is the lifetime of the tokens, including their refreshes, it is how long the
+	// revocations by id and by user are kept.
+	ttl time.Duration
+}
+
+// NewTokenController creates a token handler.
+func NewTokenController(store store.Factory, ttl time.Duration) *TokenController {
+	return &TokenController{
+		srv: srvv1.NewService(store),
+		ttl: ttl,
+	}
+}
@@ -5,6 +5,8 @@
 package user
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/auth"
 	"github.com/marmotedu/component-base/pkg/core"
@@ -12,24 +14,31 @@ import (
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 // ChangePasswordRequest defines the ChangePasswordRequest data format.
 type ChangePasswordRequest struct {
 	// Old password.
 	// Required: true
-	OldPassword string `json:"oldPassword" binding:"omitempty"`
+	OldPassword string `json:"oldPassword" validate:"required"`
 
 	// New password.
 	// Required: true
-	NewPassword string `json:"newPassword" binding:"password"`
+	NewPassword string `json:"newPassword" validate:"password"`
 }
 
 // ChangePassword change the user's password by the user identifier.
 func (u *UserController) ChangePassword(c *gin.Context) {
 	log.L(c).Info("change password function called.")
 
+	if !u.administers(c) {
+		return
+	}
+
 	var r ChangePasswordRequest
 
 	if err := c.ShouldBindJSON(&r); err != nil {
@@ -38,6 +47,12 @@ func (u *UserController) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	if errs := validator.Struct(&r); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
 	user, err := u.srv.Users().Get(c, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
 		core.WriteResponse(c, err, nil)
@@ -51,9 +66,17 @@ func (u *UserController) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// the password is rotated, not renewed
+	if err := user.Compare(r.NewPassword); err == nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, "new password must differ from the old one"), nil)
+
+		return
+	}
+
 	user.Password, _ = auth.Encrypt(r.NewPassword)
+	passwordexpiry.SetChangedAt(user, time.Now())
 	if err := u.srv.Users().ChangePassword(c, user); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
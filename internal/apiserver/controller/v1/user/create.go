@@ -12,10 +12,18 @@ import (
 	"github.com/marmotedu/component-base/pkg/auth"
 	"github.com/marmotedu/component-base/pkg/core"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
 	"github.com/marmotedu/errors"
 
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 // Create add new user to the storage.
@@ -30,19 +38,32 @@ func (u *UserController) Create(c *gin.Context) {
 		return
 	}
 
-	if errs := r.Validate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+	errs := append(validator.Struct(&r), validator.Var(field.NewPath("password"), r.Password, "omitempty,password")...)
+	errs = append(errs, tags.ValidateExtend(r.Extend)...)
+	// the admin scopes are granted by the platform administrators once the user exists
+	if _, ok := r.Extend[adminscope.ExtendKey]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("extend", adminscope.ExtendKey), "can only be set on update"))
+	}
+	// the platform administrators add the users to a tenant, within the quota of the tenant
+	if _, ok := r.Extend[tenant.ExtendKey]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("extend", tenant.ExtendKey), "can only be set on update"))
+	}
+	if _, ok := r.Extend[passwordexpiry.ExtendKey]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("extend", passwordexpiry.ExtendKey), "can only be set by changing the password"))
+	}
+	if len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
 
 		return
 	}
 
 	r.Password, _ = auth.Encrypt(r.Password)
-	r.Status = 1
+	r.Status = int(userstate.Active)
 	r.LoginedAt = time.Now()
 
 	// Insert the user to the storage.
 	if err := u.srv.Users().Create(c, &r, metav1.CreateOptions{}); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
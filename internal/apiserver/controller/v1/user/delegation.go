@@ -0,0 +1,30 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+)
+
+// administers checks that the caller administers the user of the request path. The platform
+// administrators and the users managing themselves are let through by the Validation
+// middleware, which leaves the delegated administrators to be checked against their scope.
+// The response is written when the caller does not administer the user.
+func (u *UserController) administers(c *gin.Context) bool {
+	if !c.GetBool(middleware.DelegatedAdminKey) {
+		return true
+	}
+
+	if err := u.srv.Delegations().Check(c, c.GetString(middleware.UsernameKey), c.Param("name")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+)
+
+func TestUserController_GetDelegated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockDelegationSrv := srvv1.NewMockDelegationSrv(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockDelegationSrv.EXPECT().Check(gomock.Any(), gomock.Eq("orgadmin"), gomock.Eq("colin")).Return(nil)
+	mockDelegationSrv.EXPECT().Check(gomock.Any(), gomock.Eq("orgadmin"), gomock.Eq("colin")).
+		Return(errors.WithCode(code.ErrPermissionDenied, "user colin is out of the scope of administrator orgadmin"))
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq("colin"), gomock.Any()).
+		Return(&v1.User{ObjectMeta: metav1.ObjectMeta{Name: "colin"}}, nil)
+	mockService.EXPECT().Delegations().Return(mockDelegationSrv).Times(2)
+	mockService.EXPECT().Users().Return(mockUserSrv)
+
+	u := &UserController{srv: mockService}
+	for _, want := range []int{http.StatusOK, http.StatusForbidden} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/v1/users/colin", nil)
+		c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+		c.Set(middleware.UsernameKey, "orgadmin")
+		c.Set(middleware.DelegatedAdminKey, true)
+
+		u.Get(c)
+
+		if w.Code != want {
+			t.Errorf("UserController.Get() status = %d, want %d", w.Code, want)
+		}
+	}
+}
+
+func TestUserController_ChangePasswordDelegated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockDelegationSrv := srvv1.NewMockDelegationSrv(ctrl)
+	mockDelegationSrv.EXPECT().Check(gomock.Any(), gomock.Eq("orgadmin"), gomock.Eq("colin")).
+		Return(errors.WithCode(code.ErrPermissionDenied, "user colin is out of the scope of administrator orgadmin"))
+	mockService.EXPECT().Delegations().Return(mockDelegationSrv)
+
+	u := &UserController{srv: mockService}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"oldPassword":"Admin@2020","newPassword":"Colin@2021"}`)
+	c.Request, _ = http.NewRequest("PUT", "/v1/users/colin/change-password", body)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+	c.Set(middleware.UsernameKey, "orgadmin")
+	c.Set(middleware.DelegatedAdminKey, true)
+
+	u.ChangePassword(c)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("UserController.ChangePassword() status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func Test_updatableExtend(t *testing.T) {
+	stored := metav1.Extend{"adminScope": map[string]interface{}{"tenant": true}, "tenant": "marmotedu"}
+	updated := metav1.Extend{"tenant": "other", "nickname": "colin"}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set(middleware.DelegatedAdminKey, true)
+
+	got := updatableExtend(c, updated, stored)
+	if got["tenant"] != "marmotedu" || got["adminScope"] == nil || got["nickname"] != "colin" {
+		t.Errorf("updatableExtend() = %v", got)
+	}
+
+	// the users updating themselves can not move to another tenant either
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+
+	got = updatableExtend(c, metav1.Extend{"tenant": "other", "nickname": "colin"}, stored)
+	if got["tenant"] != "marmotedu" || got["nickname"] != "colin" {
+		t.Errorf("updatableExtend() = %v", got)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Set(middleware.PlatformAdminKey, true)
+
+	got = updatableExtend(c, metav1.Extend{"tenant": "other"}, stored)
+	if got["tenant"] != "other" {
+		t.Errorf("updatableExtend() = %v", got)
+	}
+}
@@ -17,6 +17,10 @@ import (
 func (u *UserController) Delete(c *gin.Context) {
 	log.L(c).Info("delete user function called.")
 
+	if !u.administers(c) {
+		return
+	}
+
 	if err := u.srv.Users().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
 		core.WriteResponse(c, err, nil)
 
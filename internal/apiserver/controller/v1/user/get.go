@@ -16,6 +16,10 @@ import (
 func (u *UserController) Get(c *gin.Context) {
 	log.L(c).Info("get user function called.")
 
+	if !u.administers(c) {
+		return
+	}
+
 	user, err := u.srv.Users().Get(c, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
 		core.WriteResponse(c, err, nil)
@@ -6,14 +6,21 @@ package user
 
 import (
 	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
 	"github.com/marmotedu/component-base/pkg/core"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
+// sortFields are the fields the users can be sorted by.
+var sortFields = []string{"name", "nickname", "email", "loginedAt", "createdAt", "updatedAt"}
+
 // List list the users in the storage.
 // Only administrator can call this function.
 func (u *UserController) List(c *gin.Context) {
@@ -26,7 +33,20 @@ func (u *UserController) List(c *gin.Context) {
 		return
 	}
 
-	users, err := u.srv.Users().List(c, r)
+	pagination.Complete(c, &r)
+	if errs := pagination.CompleteSort(c, sortFields...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
+
+		return
+	}
+
+	var users *v1.UserList
+	var err error
+	if c.GetBool(middleware.DelegatedAdminKey) {
+		users, err = u.srv.Delegations().ListUsers(c, c.GetString(middleware.UsernameKey), r)
+	} else {
+		users, err = u.srv.Users().List(c, r)
+	}
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 
@@ -0,0 +1,60 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+)
+
+func TestUserController_ListLogins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockLoginRecordSrv := srvv1.NewMockLoginRecordSrv(ctrl)
+	mockLoginRecordSrv.EXPECT().List(gomock.Any(), gomock.Eq("colin"), gomock.Any()).Return(nil, nil)
+	mockService.EXPECT().LoginRecords().Return(mockLoginRecordSrv)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/v1/users/colin/logins", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &UserController{
+				srv: tt.fields.srv,
+			}
+			u.ListLogins(tt.args.c)
+		})
+	}
+}
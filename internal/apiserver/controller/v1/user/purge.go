@@ -0,0 +1,36 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Purge erases a user in the background, along with its secrets, its policies, its sessions,
+// its login records, the actor of its audit events and its analytics records. It returns the
+// operation to poll with 202 Accepted, whose result reports what was erased.
+// Only administrator can call this function.
+func (u *UserController) Purge(c *gin.Context) {
+	log.L(c).Info("purge user function called.")
+
+	if !u.administers(c) {
+		return
+	}
+
+	op, err := u.srv.Users().Purge(c, c.Param("name"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	c.Header("Location", "/v1/operations/"+op.Name)
+	c.JSON(http.StatusAccepted, op)
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func TestUserController_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	op := &apiv1.Operation{ObjectMeta: metav1.ObjectMeta{Name: "op-purge"}, Kind: srvv1.OperationPurgeUser}
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Purge(gomock.Any(), gomock.Eq("colin")).Return(op, nil)
+	mockUserSrv.EXPECT().Purge(gomock.Any(), gomock.Eq("colin")).
+		Return(nil, errors.WithCode(code.ErrUserNotFound, "record not found"))
+	mockService.EXPECT().Users().Return(mockUserSrv).Times(2)
+
+	u := &UserController{srv: mockService}
+	for _, want := range []int{http.StatusAccepted, http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/users/colin/purge", nil)
+		c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+
+		u.Purge(c)
+
+		if w.Code != want {
+			t.Errorf("UserController.Purge() status = %d, want %d", w.Code, want)
+		}
+	}
+}
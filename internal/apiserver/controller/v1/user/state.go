@@ -0,0 +1,45 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Suspend suspends an active user, who is denied until activated again.
+func (u *UserController) Suspend(c *gin.Context) {
+	u.transition(c, userstate.Suspend)
+}
+
+// Activate activates a suspended or deactivated user.
+func (u *UserController) Activate(c *gin.Context) {
+	u.transition(c, userstate.Activate)
+}
+
+// Deactivate deactivates an active or suspended user.
+func (u *UserController) Deactivate(c *gin.Context) {
+	u.transition(c, userstate.Deactivate)
+}
+
+func (u *UserController) transition(c *gin.Context, action userstate.Action) {
+	log.L(c).Infof("%s user function called.", action)
+
+	if !u.administers(c) {
+		return
+	}
+
+	user, err := u.srv.Users().Transition(c, c.Param("name"), action)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, user)
+}
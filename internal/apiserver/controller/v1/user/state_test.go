@@ -0,0 +1,49 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+)
+
+func TestUserController_Suspend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Status: int(userstate.Suspended)}
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Transition(gomock.Any(), gomock.Eq("colin"), gomock.Eq(userstate.Suspend)).Return(user, nil)
+	mockUserSrv.EXPECT().Transition(gomock.Any(), gomock.Eq("colin"), gomock.Eq(userstate.Suspend)).
+		Return(nil, errors.WithCode(code.ErrUserStateTransition, "can not suspend a deactivated user"))
+	mockService.EXPECT().Users().Return(mockUserSrv).Times(2)
+
+	u := &UserController{srv: mockService}
+	for _, want := range []int{http.StatusOK, http.StatusConflict} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/users/colin/suspend", nil)
+		c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+
+		u.Suspend(c)
+
+		if w.Code != want {
+			t.Errorf("UserController.Suspend() status = %d, want %d", w.Code, want)
+		}
+	}
+}
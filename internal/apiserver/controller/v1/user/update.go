@@ -11,14 +11,26 @@ import (
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
 
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/validation"
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/validator"
 )
 
 // Update update a user info by the user identifier.
 func (u *UserController) Update(c *gin.Context) {
 	log.L(c).Info("update user function called.")
 
+	if !u.administers(c) {
+		return
+	}
+
 	var r v1.User
 
 	if err := c.ShouldBindJSON(&r); err != nil {
@@ -37,20 +49,34 @@ func (u *UserController) Update(c *gin.Context) {
 	user.Nickname = r.Nickname
 	user.Email = r.Email
 	user.Phone = r.Phone
-	user.Extend = r.Extend
+	user.Extend = updatableExtend(c, r.Extend, user.Extend)
 
-	if errs := user.ValidateUpdate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+	errs := append(validator.Struct(user), tags.ValidateExtend(user.Extend)...)
+	errs = append(errs, adminscope.ValidateExtend(user.Extend)...)
+	if errs = append(errs, resourceversion.ValidateExtend(user.Extend)...); len(errs) != 0 {
+		validation.WriteResponse(c, validation.NewError(errs), nil)
 
 		return
 	}
 
 	// Save changed fields.
 	if err := u.srv.Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
-		core.WriteResponse(c, err, nil)
+		validation.WriteResponse(c, err, nil)
 
 		return
 	}
 
 	core.WriteResponse(c, nil, user)
 }
+
+// updatableExtend returns the updated extend fields, with the fields the caller may not change
+// kept as stored: the time of the last password change is only set by changing the password,
+// and only the platform administrators grant the admin scopes and move the users to another
+// tenant.
+func updatableExtend(c *gin.Context, updated, stored metav1.Extend) metav1.Extend {
+	if c.GetBool(middleware.PlatformAdminKey) {
+		return adminscope.Keep(updated, stored, passwordexpiry.ExtendKey)
+	}
+
+	return adminscope.Keep(updated, stored, adminscope.ExtendKey, passwordexpiry.ExtendKey, tenant.ExtendKey)
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// WhoAmI return the authenticated identity of the request, with the tenant, the groups and the
+// admin status of the user.
+func (u *UserController) WhoAmI(c *gin.Context) {
+	log.L(c).Info("whoami function called.")
+
+	user, err := u.srv.Users().Get(c, c.GetString(middleware.UsernameKey), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// the groups of all the owners are listed, the user may be a member of any of them
+	limit := int64(-1)
+	groups, err := u.srv.Groups().List(c, "", metav1.ListOptions{Limit: &limit})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	identity := &v1.Identity{
+		Username: user.Name,
+		Nickname: user.Nickname,
+		Email:    user.Email,
+		Tenant:   tenant.FromExtend(user.Extend),
+		Groups:   make([]string, 0),
+		IsAdmin:  user.IsAdmin == 1,
+	}
+
+	member := v1.SubjectKindUser + ":" + user.Name
+	for _, group := range groups.Items {
+		if group.HasMember(member) {
+			identity.Groups = append(identity.Groups, group.Subject())
+		}
+	}
+	sort.Strings(identity.Groups)
+
+	identity.AuthMethod = v1.AuthMethodBasic
+	if strings.HasPrefix(c.Request.Header.Get("Authorization"), "Bearer ") {
+		identity.AuthMethod = v1.AuthMethodToken
+		if exp, ok := jwt.ExtractClaims(c)["exp"].(float64); ok {
+			expiresAt := time.Unix(int64(exp), 0)
+			identity.ExpiresAt = &expiresAt
+		}
+	}
+
+	core.WriteResponse(c, nil, identity)
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/marmotedu/iam/internal/apiserver/service/v1"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func TestUserController_WhoAmI(t *testing.T) {
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "colin",
+			Extend: metav1.Extend{"tenant": "marmotedu"},
+		},
+		Nickname: "colin",
+		Email:    "colin@foxmail.com",
+		IsAdmin:  1,
+	}
+	groups := &apiv1.GroupList{
+		Items: []*apiv1.Group{
+			{ObjectMeta: metav1.ObjectMeta{Name: "sre"}, Members: []string{"users:colin"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "auditor"}, Kind: "roles", Members: []string{"users:colin"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "dev"}, Members: []string{"users:tom"}},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq("colin"), gomock.Any()).Return(user, nil)
+	mockGroupSrv := srvv1.NewMockGroupSrv(ctrl)
+	mockGroupSrv.EXPECT().List(gomock.Any(), gomock.Eq(""), gomock.Any()).Return(groups, nil)
+	mockService.EXPECT().Users().Return(mockUserSrv)
+	mockService.EXPECT().Groups().Return(mockGroupSrv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/v1/whoami", nil)
+	c.Request.SetBasicAuth("colin", "Colin@2020")
+	c.Set("username", "colin")
+
+	u := &UserController{srv: mockService}
+	u.WhoAmI(c)
+
+	var identity apiv1.Identity
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &identity))
+	assert.Equal(t, apiv1.Identity{
+		Username:   "colin",
+		Nickname:   "colin",
+		Email:      "colin@foxmail.com",
+		AuthMethod: apiv1.AuthMethodBasic,
+		Tenant:     "marmotedu",
+		Groups:     []string{"groups:sre", "roles:auditor"},
+		IsAdmin:    true,
+	}, identity)
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/auth"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/admission"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	authstrategy "github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// provisionUser creates the user authenticated by an external identity provider. The
+// provisioned users log in through the identity provider, their random password is never used.
+func provisionUser(c *gin.Context, user *v1.User, method string) error {
+	nickname := []rune(user.Nickname)
+	if len(nickname) == 0 {
+		nickname = []rune(user.Name)
+	}
+	if len(nickname) > 30 {
+		nickname = nickname[:30]
+	}
+
+	user.Nickname = string(nickname)
+	user.Status = int(userstate.Active)
+	user.Password = "Aa1!" + idutil.NewSecretKey()[:12]
+
+	if errs := user.Validate(); len(errs) != 0 {
+		return errors.WithCode(code.ErrValidation, errs.ToAggregate().Error())
+	}
+
+	user.Password, _ = auth.Encrypt(user.Password)
+	if err := admission.Admit(c, admission.KindUser, admission.Create, user); err != nil {
+		return err
+	}
+
+	if err := store.Client().Users().Create(c, user, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	log.L(c).Infof("user %s is provisioned by %s login", user.Name, method)
+
+	return nil
+}
+
+// finishLogin records the successful login and issues the jwt token of the user. Browsers
+// are redirected with the jwt cookie when the redirect is a local path, otherwise the token
+// is returned like the /login endpoint.
+func finishLogin(c *gin.Context, jwtStrategy authstrategy.JWTStrategy, user *v1.User, method, redirect string) {
+	if err := userstate.Check(user.Name, userstate.Of(user)); err != nil {
+		recordLogin(c, user.Name, method, "user "+userstate.Of(user).String())
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	user.LoginedAt = time.Now()
+	_ = store.Client().Users().Update(c, user, metav1.UpdateOptions{})
+	recordLogin(c, user.Name, method, "")
+
+	token, expire, err := jwtStrategy.TokenGenerator(user)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	if jwtStrategy.SendCookie && isLocalPath(redirect) {
+		c.SetCookie(jwtStrategy.CookieName, token, int(jwtStrategy.CookieMaxAge.Seconds()), "/",
+			jwtStrategy.CookieDomain, jwtStrategy.SecureCookie, jwtStrategy.CookieHTTPOnly)
+		c.Redirect(http.StatusSeeOther, redirect)
+
+		return
+	}
+
+	loginResponse()(c, http.StatusOK, token, expire)
+}
+
+// isLocalPath reports whether the path is on the same host, which prevents open redirects.
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "/\\")
+}
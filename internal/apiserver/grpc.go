@@ -5,10 +5,9 @@
 package apiserver
 
 import (
-	"net"
-
 	"google.golang.org/grpc"
 
+	"github.com/marmotedu/iam/internal/pkg/upgrade"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -18,7 +17,7 @@ type grpcAPIServer struct {
 }
 
 func (s *grpcAPIServer) Run() {
-	listen, err := net.Listen("tcp", s.address)
+	listen, err := upgrade.Listen(s.address)
 	if err != nil {
 		log.Fatalf("failed to listen: %s", err.Error())
 	}
@@ -0,0 +1,212 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/ghodss/yaml"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/auth"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/apiserver/options"
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	"github.com/marmotedu/iam/pkg/app"
+)
+
+const initDesc = "Create the database schema, the admin user and the example policies, and write a starter " +
+	"configuration file with a generated jwt key, for the first boot."
+
+// adminUsername is the name of the built-in admin user.
+const adminUsername = "admin"
+
+// examplePolicies are created for the admin user by the init command, they show the usage
+// of the subjects, actions, resources and conditions.
+var examplePolicies = []*v1.Policy{
+	{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-read-articles"},
+		Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+			Description: "Every user can read the articles.",
+			Subjects:    []string{"users:<.*>"},
+			Actions:     []string{"get"},
+			Resources:   []string{"resources:articles:<.*>"},
+			Effect:      ladon.AllowAccess,
+		}},
+	},
+	{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-edit-articles"},
+		Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+			Description: "The admins can create, update and delete the articles from the office network.",
+			Subjects:    []string{"users:" + adminUsername, "groups:admins"},
+			Actions:     []string{"<create|update|delete>"},
+			Resources:   []string{"resources:articles:<.*>"},
+			Effect:      ladon.AllowAccess,
+			Conditions: ladon.Conditions{
+				"remoteIPAddress": &ladon.CIDRCondition{CIDR: "192.168.0.1/16"},
+			},
+		}},
+	},
+}
+
+// newInitCommand returns the init command, which prepares the first boot of the api server.
+func newInitCommand() *app.Command {
+	opts := options.NewInitOptions()
+
+	return app.NewCommand("init", initDesc,
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			return runInit(opts)
+		}),
+	)
+}
+
+func runInit(opts *options.InitOptions) error {
+	// generates the jwt key if it is not set
+	if err := opts.APIServer.Complete(); err != nil {
+		return err
+	}
+	if errs := opts.Validate(); len(errs) != 0 {
+		return errors.NewAggregate(errs)
+	}
+	if _, err := os.Stat(opts.Output); err == nil && !opts.Force {
+		return fmt.Errorf("configuration file %s exists, use --force to overwrite it", opts.Output)
+	}
+
+	initStep("Creating the database schema of %s", opts.APIServer.MySQLOptions.Database)
+	if err := mysql.MigrateSchema(opts.APIServer.MySQLOptions); err != nil {
+		return err
+	}
+
+	storeIns, err := mysql.GetMySQLFactoryOr(opts.APIServer.MySQLOptions)
+	if err != nil {
+		return err
+	}
+	defer storeIns.Close()
+
+	ctx := context.Background()
+	initStep("Creating the %s user", adminUsername)
+	password, err := seedAdmin(ctx, storeIns, opts.AdminPassword)
+	if err != nil {
+		return err
+	}
+
+	if opts.ExamplePolicies {
+		initStep("Creating the example policies")
+		if err := seedPolicies(ctx, storeIns); err != nil {
+			return err
+		}
+	}
+
+	initStep("Writing the configuration file %s", opts.Output)
+	if err := writeConfig(opts.APIServer, opts.Output); err != nil {
+		return err
+	}
+
+	if password != "" && opts.AdminPassword == "" {
+		fmt.Printf("\nThe password of the %s user is %s, change it after the first login.\n", adminUsername, password)
+	}
+	fmt.Printf("\nStart the api server with: iam-apiserver -c %s\n", opts.Output)
+
+	return nil
+}
+
+// Seed creates the admin user and the example policies, unless they exist, and returns the
+// password of the admin user if it is created. It seeds the store of the all-in-one iam command.
+func Seed(ctx context.Context, storeIns store.Factory, adminPassword string) (string, error) {
+	password, err := seedAdmin(ctx, storeIns, adminPassword)
+	if err != nil {
+		return "", err
+	}
+
+	return password, seedPolicies(ctx, storeIns)
+}
+
+// seedAdmin creates the admin user, unless it exists, and returns its password.
+func seedAdmin(ctx context.Context, storeIns store.Factory, password string) (string, error) {
+	_, err := storeIns.Users().Get(ctx, adminUsername, metav1.GetOptions{})
+	if err == nil {
+		initSkip("user %s exists", adminUsername)
+
+		return "", nil
+	}
+	if !errors.IsCode(err, code.ErrUserNotFound) {
+		return "", err
+	}
+
+	if password == "" {
+		password = "Aa1!" + idutil.NewSecretKey()[:12]
+	}
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: adminUsername},
+		Status:     int(userstate.Active),
+		Nickname:   adminUsername,
+		Password:   password,
+		Email:      "admin@iam.example.com",
+		IsAdmin:    1,
+	}
+	if errs := user.Validate(); len(errs) != 0 {
+		return "", errors.WithCode(code.ErrValidation, errs.ToAggregate().Error())
+	}
+
+	user.Password, _ = auth.Encrypt(user.Password)
+	if err := storeIns.Users().Create(ctx, user, metav1.CreateOptions{}); err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// seedPolicies creates the example policies of the admin user, unless they exist.
+func seedPolicies(ctx context.Context, storeIns store.Factory) error {
+	for _, example := range examplePolicies {
+		_, err := storeIns.Policies().Get(ctx, adminUsername, example.Name, metav1.GetOptions{})
+		if err == nil {
+			initSkip("policy %s exists", example.Name)
+
+			continue
+		}
+		if !errors.IsCode(err, code.ErrPolicyNotFound) {
+			return err
+		}
+
+		policy := *example
+		policy.Username = adminUsername
+		if err := storeIns.Policies().Create(ctx, &policy, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeConfig writes the options to the configuration file, readable by the owner only as
+// it holds the passwords and the jwt key.
+func writeConfig(opts *options.Options, path string) error {
+	data, err := yaml.Marshal(opts)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+func initStep(format string, args ...interface{}) {
+	fmt.Printf("%s %s ...\n", color.GreenString("==>"), fmt.Sprintf(format, args...))
+}
+
+func initSkip(format string, args ...interface{}) {
+	fmt.Printf("    %s, skipped\n", fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package options
+
+import (
+	"fmt"
+
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
+)
+
+// InitOptions runs the first-boot initialization of an iam api server.
+type InitOptions struct {
+	// APIServer are the options written to the starter configuration file.
+	APIServer       *Options
+	AdminPassword   string
+	Output          string
+	Force           bool
+	ExamplePolicies bool
+}
+
+// NewInitOptions creates a new InitOptions object with default parameters.
+func NewInitOptions() *InitOptions {
+	return &InitOptions{
+		APIServer:       NewOptions(),
+		Output:          "iam-apiserver.yaml",
+		ExamplePolicies: true,
+	}
+}
+
+// Flags returns the flags of the api server, and the flags of the initialization.
+func (o *InitOptions) Flags() (fss cliflag.NamedFlagSets) {
+	fss = o.APIServer.Flags()
+
+	fs := fss.FlagSet("init")
+	fs.StringVar(&o.AdminPassword, "admin-password", o.AdminPassword, ""+
+		"Password of the built-in admin user. A random password is generated and printed if not set.")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "Path of the starter configuration file to write.")
+	fs.BoolVar(&o.Force, "force", o.Force, "Overwrite the configuration file if it exists.")
+	fs.BoolVar(&o.ExamplePolicies, "example-policies", o.ExamplePolicies, ""+
+		"Create the example policies of the admin user.")
+
+	return fss
+}
+
+// Validate checks InitOptions and return a slice of found errs.
+func (o *InitOptions) Validate() []error {
+	errs := o.APIServer.Validate()
+	if o.Output == "" {
+		errs = append(errs, fmt.Errorf("--output can not be empty"))
+	}
+
+	return errs
+}
@@ -8,24 +8,42 @@ package options
 import (
 	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
 	"github.com/marmotedu/component-base/pkg/json"
-	"github.com/marmotedu/component-base/pkg/util/idutil"
 
+	"github.com/marmotedu/iam/internal/pkg/admission"
+	"github.com/marmotedu/iam/internal/pkg/connector"
+	"github.com/marmotedu/iam/internal/pkg/faultinjection"
+	"github.com/marmotedu/iam/internal/pkg/loginthrottle"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/pkg/operation"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/ratelimit"
+	"github.com/marmotedu/iam/internal/pkg/saml"
 	"github.com/marmotedu/iam/internal/pkg/server"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
 // Options runs an iam api server.
 type Options struct {
-	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"   mapstructure:"server"`
-	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"     mapstructure:"grpc"`
-	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure" mapstructure:"insecure"`
-	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"   mapstructure:"secure"`
-	MySQLOptions            *genericoptions.MySQLOptions           `json:"mysql"    mapstructure:"mysql"`
-	RedisOptions            *genericoptions.RedisOptions           `json:"redis"    mapstructure:"redis"`
-	JwtOptions              *genericoptions.JwtOptions             `json:"jwt"      mapstructure:"jwt"`
-	Log                     *log.Options                           `json:"log"      mapstructure:"log"`
-	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"  mapstructure:"feature"`
+	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"     mapstructure:"server"`
+	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"       mapstructure:"grpc"`
+	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"   mapstructure:"insecure"`
+	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"     mapstructure:"secure"`
+	MySQLOptions            *genericoptions.MySQLOptions           `json:"mysql"      mapstructure:"mysql"`
+	RedisOptions            *genericoptions.RedisOptions           `json:"redis"      mapstructure:"redis"`
+	JwtOptions              *genericoptions.JwtOptions             `json:"jwt"        mapstructure:"jwt"`
+	Log                     *log.Options                           `json:"log"        mapstructure:"log"`
+	RuntimeOptions          *genericoptions.RuntimeOptions         `json:"runtime"    mapstructure:"runtime"`
+	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"    mapstructure:"feature"`
+	SAMLOptions             *saml.SAMLOptions                      `json:"saml"       mapstructure:"saml"`
+	ConnectorOptions        *connector.ConnectorOptions            `json:"connectors" mapstructure:"connectors"`
+	AdmissionOptions        *admission.AdmissionOptions            `json:"admission"  mapstructure:"admission"`
+	PaginationOptions       *pagination.PaginationOptions          `json:"pagination" mapstructure:"pagination"`
+	RateLimitOptions        *ratelimit.RateLimitOptions            `json:"rate-limit" mapstructure:"rate-limit"`
+	LoginThrottleOptions    *loginthrottle.LoginThrottleOptions    `json:"login-throttle" mapstructure:"login-throttle"`
+	OperationOptions        *operation.OperationOptions            `json:"operation"  mapstructure:"operation"`
+	NotificationOptions     *notifier.NotificationOptions          `json:"notification" mapstructure:"notification"`
+	FaultInjectionOptions   *faultinjection.FaultInjectionOptions  `json:"fault-injection" mapstructure:"fault-injection"`
 }
 
 // NewOptions creates a new Options object with default parameters.
@@ -39,7 +57,17 @@ func NewOptions() *Options {
 		RedisOptions:            genericoptions.NewRedisOptions(),
 		JwtOptions:              genericoptions.NewJwtOptions(),
 		Log:                     log.NewOptions(),
+		RuntimeOptions:          genericoptions.NewRuntimeOptions(),
 		FeatureOptions:          genericoptions.NewFeatureOptions(),
+		SAMLOptions:             saml.NewSAMLOptions(),
+		ConnectorOptions:        connector.NewConnectorOptions(),
+		AdmissionOptions:        admission.NewAdmissionOptions(),
+		PaginationOptions:       pagination.NewPaginationOptions(),
+		RateLimitOptions:        ratelimit.NewRateLimitOptions(),
+		LoginThrottleOptions:    loginthrottle.NewLoginThrottleOptions(),
+		OperationOptions:        operation.NewOperationOptions(),
+		NotificationOptions:     notifier.NewNotificationOptions(),
+		FaultInjectionOptions:   faultinjection.NewFaultInjectionOptions(),
 	}
 
 	return &o
@@ -58,9 +86,19 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
 	o.FeatureOptions.AddFlags(fss.FlagSet("features"))
+	o.SAMLOptions.AddFlags(fss.FlagSet("saml"))
+	o.ConnectorOptions.AddFlags(fss.FlagSet("connectors"))
+	o.AdmissionOptions.AddFlags(fss.FlagSet("admission"))
+	o.PaginationOptions.AddFlags(fss.FlagSet("pagination"))
+	o.RateLimitOptions.AddFlags(fss.FlagSet("rate limit"))
+	o.LoginThrottleOptions.AddFlags(fss.FlagSet("login throttle"))
+	o.OperationOptions.AddFlags(fss.FlagSet("operation"))
+	o.NotificationOptions.AddFlags(fss.FlagSet("notification"))
+	o.FaultInjectionOptions.AddFlags(fss.FlagSet("fault injection"))
 	o.InsecureServing.AddFlags(fss.FlagSet("insecure serving"))
 	o.SecureServing.AddFlags(fss.FlagSet("secure serving"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
+	o.RuntimeOptions.AddFlags(fss.FlagSet("runtime"))
 
 	return fss
 }
@@ -73,8 +111,8 @@ func (o *Options) String() string {
 
 // Complete set default Options.
 func (o *Options) Complete() error {
-	if o.JwtOptions.Key == "" {
-		o.JwtOptions.Key = idutil.NewSecretKey()
+	if err := o.JwtOptions.Complete(); err != nil {
+		return err
 	}
 
 	return o.SecureServing.Complete()
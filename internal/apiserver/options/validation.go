@@ -16,7 +16,17 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.RedisOptions.Validate()...)
 	errs = append(errs, o.JwtOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
+	errs = append(errs, o.RuntimeOptions.Validate()...)
 	errs = append(errs, o.FeatureOptions.Validate()...)
+	errs = append(errs, o.SAMLOptions.Validate()...)
+	errs = append(errs, o.ConnectorOptions.Validate()...)
+	errs = append(errs, o.AdmissionOptions.Validate()...)
+	errs = append(errs, o.PaginationOptions.Validate()...)
+	errs = append(errs, o.RateLimitOptions.Validate()...)
+	errs = append(errs, o.LoginThrottleOptions.Validate()...)
+	errs = append(errs, o.OperationOptions.Validate()...)
+	errs = append(errs, o.NotificationOptions.Validate()...)
+	errs = append(errs, o.FaultInjectionOptions.Validate()...)
 
 	return errs
 }
@@ -0,0 +1,70 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+
+	"github.com/marmotedu/iam/internal/pkg/revocation"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// isTokenRevoked reports whether the token has been revoked by a logout, by its id or along
+// with the other tokens of its user. The tokens are accepted when the revocation store is
+// unavailable.
+func isTokenRevoked(ctx context.Context, token string, claims jwt.MapClaims) bool {
+	username, _ := claims[jwt.IdentityKey].(string)
+
+	return revocation.IsRevoked(ctx, token, username, claims)
+}
+
+// logoutResponse revokes the token of the logout request, if any, so that it is rejected
+// until it expires.
+func logoutResponse(mw *jwt.GinJWTMiddleware) func(c *gin.Context, code int) {
+	return func(c *gin.Context, code int) {
+		token, err := mw.ParseToken(c)
+		if err != nil || !token.Valid {
+			c.JSON(http.StatusOK, nil)
+
+			return
+		}
+
+		claims := jwt.ExtractClaimsFromToken(token)
+		ttl := mw.Timeout
+		if exp, ok := claims["exp"].(float64); ok {
+			ttl = time.Until(time.Unix(int64(exp), 0))
+		}
+
+		if ttl > 0 {
+			if err := revocation.RevokeToken(c, jwt.GetToken(c), ttl); err != nil {
+				log.L(c).Warnf("revoke token of user %v failed: %s", claims[jwt.IdentityKey], err.Error())
+			}
+		}
+
+		c.JSON(http.StatusOK, nil)
+	}
+}
+
+// refreshHandler refreshes the tokens which are not revoked.
+func refreshHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var claims jwt.MapClaims
+		if token, err := mw.ParseToken(c); err == nil {
+			claims = jwt.ExtractClaimsFromToken(token)
+		}
+		if isTokenRevoked(c, jwt.GetToken(c), claims) {
+			mw.Unauthorized(c, http.StatusUnauthorized, "token is revoked")
+
+			return
+		}
+
+		mw.RefreshHandler(c)
+	}
+}
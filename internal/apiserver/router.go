@@ -9,50 +9,100 @@ import (
 	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
 
+	"github.com/marmotedu/iam/internal/apiserver/controller/scim"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/attachment"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/audit"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/completion"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/errcode"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/group"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/operation"
 	"github.com/marmotedu/iam/internal/apiserver/controller/v1/policy"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/quota"
 	"github.com/marmotedu/iam/internal/apiserver/controller/v1/secret"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/snapshot"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/sts"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/tenant"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/token"
 	"github.com/marmotedu/iam/internal/apiserver/controller/v1/user"
 	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/connector"
+	"github.com/marmotedu/iam/internal/pkg/faultinjection"
+	"github.com/marmotedu/iam/internal/pkg/loginthrottle"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
 	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/ratelimit"
+	"github.com/marmotedu/iam/internal/pkg/saml"
 
 	// custom gin validators.
 	_ "github.com/marmotedu/iam/pkg/validator"
 )
 
-func initRouter(g *gin.Engine) {
-	installMiddleware(g)
-	installController(g)
+func initRouter(
+	g *gin.Engine,
+	samlOptions *saml.SAMLOptions,
+	connectorOptions *connector.ConnectorOptions,
+	rateLimitOptions *ratelimit.RateLimitOptions,
+	loginThrottleOptions *loginthrottle.LoginThrottleOptions,
+	faultOptions *faultinjection.FaultInjectionOptions,
+) {
+	installMiddleware(g, faultOptions)
+	installController(g, samlOptions, connectorOptions, rateLimitOptions, loginThrottleOptions)
 }
 
-func installMiddleware(g *gin.Engine) {
+func installMiddleware(g *gin.Engine, faultOptions *faultinjection.FaultInjectionOptions) {
+	// the faults are injected before the authentication, so that they are scoped by route only
+	if faultOptions.Enable {
+		g.Use(faultinjection.Inject(faultOptions))
+	}
 }
 
-func installController(g *gin.Engine) *gin.Engine {
+func installController(
+	g *gin.Engine,
+	samlOptions *saml.SAMLOptions,
+	connectorOptions *connector.ConnectorOptions,
+	rateLimitOptions *ratelimit.RateLimitOptions,
+	loginThrottleOptions *loginthrottle.LoginThrottleOptions,
+) *gin.Engine {
+	// the clients are limited after the authentication, the login attempts by their ip
+	limit := ratelimit.Limit(rateLimitOptions)
+
 	// Middlewares.
 	jwtStrategy, _ := newJWTAuth().(auth.JWTStrategy)
-	g.POST("/login", jwtStrategy.LoginHandler)
+	g.POST("/login", loginthrottle.Throttle(loginThrottleOptions), limit, jwtStrategy.LoginHandler)
 	g.POST("/logout", jwtStrategy.LogoutHandler)
 	// Refresh time can be longer than token timeout
-	g.POST("/refresh", jwtStrategy.RefreshHandler)
+	g.POST("/refresh", refreshHandler(&jwtStrategy.GinJWTMiddleware))
+
+	if samlOptions.Enabled() {
+		installSAML(g, samlOptions, jwtStrategy)
+	}
+
+	if connectorOptions.Enabled() {
+		installConnectors(g, connectorOptions, jwtStrategy)
+	}
 
 	auto := newAutoAuth()
 	g.NoRoute(auto.AuthFunc(), func(c *gin.Context) {
 		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "Page not found."), nil)
 	})
 
-	// v1 handlers, requiring authentication
+	// v1 handlers, requiring authentication, the changes of the resources are audited and the
+	// unchanged resources are not transferred again to the clients sending their ETag
 	storeIns, _ := mysql.GetMySQLFactoryOr(nil)
-	v1 := g.Group("/v1")
+	v1 := g.Group("/v1", middleware.Audit(), middleware.ETag())
 	{
+		// the error code catalog is public, like the codes in the responses
+		errcodeController := errcode.NewErrCodeController()
+		v1.GET("/codes", errcodeController.List)
+
 		// user RESTful resource
 		userv1 := v1.Group("/users")
 		{
 			userController := user.NewUserController(storeIns)
 
-			userv1.POST("", userController.Create)
-			userv1.Use(auto.AuthFunc(), middleware.Validation())
+			userv1.POST("", limit, userController.Create)
+			userv1.Use(auto.AuthFunc(), middleware.ActiveUser(), limit, middleware.Validation())
 			// v1.PUT("/find_password", userController.FindPassword)
 			userv1.DELETE("", userController.DeleteCollection) // admin api
 			userv1.DELETE(":name", userController.Delete)      // admin api
@@ -60,9 +110,20 @@ func installController(g *gin.Engine) *gin.Engine {
 			userv1.PUT(":name", userController.Update)
 			userv1.GET("", userController.List)
 			userv1.GET(":name", userController.Get) // admin api
+			userv1.GET(":name/logins", userController.ListLogins)
+			userv1.POST(":name/suspend", middleware.Publish(), userController.Suspend)       // admin api
+			userv1.POST(":name/activate", middleware.Publish(), userController.Activate)     // admin api
+			userv1.POST(":name/deactivate", middleware.Publish(), userController.Deactivate) // admin api
+			userv1.POST(":name/purge", userController.Purge)                                 // admin api
 		}
 
-		v1.Use(auto.AuthFunc())
+		v1.Use(auto.AuthFunc(), middleware.ActiveUser(), limit)
+
+		// the authenticated identity of the request
+		v1.GET("/whoami", user.NewUserController(storeIns).WhoAmI)
+
+		// the names of the resources starting with a prefix, used by the shell completion
+		v1.GET("/completions", completion.NewCompletionController(storeIns).List)
 
 		// policy RESTful resource
 		policyv1 := v1.Group("/policies", middleware.Publish())
@@ -75,6 +136,40 @@ func installController(g *gin.Engine) *gin.Engine {
 			policyv1.PUT(":name", policyController.Update)
 			policyv1.GET("", policyController.List)
 			policyv1.GET(":name", policyController.Get)
+
+			attachmentController := attachment.NewAttachmentController(storeIns)
+
+			policyv1.POST(":name/attachments", attachmentController.Attach)
+			policyv1.DELETE(":name/attachments/:subject", attachmentController.Detach)
+			policyv1.GET(":name/attachments", attachmentController.ListByPolicy)
+		}
+
+		// lints a policy without storing it
+		policyValidationv1 := v1.Group("/policy-validations")
+		{
+			policyController := policy.NewPolicyController(storeIns)
+
+			policyValidationv1.POST("", policyController.Validate)
+		}
+
+		// attachment RESTful resource
+		attachmentv1 := v1.Group("/attachments")
+		{
+			attachmentController := attachment.NewAttachmentController(storeIns)
+
+			attachmentv1.GET("", attachmentController.List)
+		}
+
+		// group RESTful resource, the policies of a user are reloaded when their groups change
+		groupv1 := v1.Group("/groups", middleware.Publish())
+		{
+			groupController := group.NewGroupController(storeIns)
+
+			groupv1.POST("", groupController.Create)
+			groupv1.DELETE(":name", groupController.Delete)
+			groupv1.PUT(":name", groupController.Update)
+			groupv1.GET("", groupController.List)
+			groupv1.GET(":name", groupController.Get)
 		}
 
 		// secret RESTful resource
@@ -88,6 +183,76 @@ func installController(g *gin.Engine) *gin.Engine {
 			secretv1.GET("", secretController.List)
 			secretv1.GET(":name", secretController.Get)
 		}
+
+		// the revocations are honored by both iam-apiserver and iam-authz-server
+		tokenController := token.NewTokenController(storeIns, jwtStrategy.Timeout+jwtStrategy.MaxRefresh)
+		v1.POST("/tokens/revoke", middleware.Validation(), tokenController.Revoke)
+
+		// temporary credentials, restricted by the session policies
+		stsv1 := v1.Group("/sts", middleware.Publish())
+		{
+			stsController := sts.NewSTSController(storeIns)
+
+			stsv1.POST("/assume-role", stsController.AssumeRole)
+		}
+
+		// audit event resource, administrators only
+		auditv1 := v1.Group("/audits", middleware.Validation())
+		{
+			auditController := audit.NewAuditController(storeIns)
+
+			auditv1.GET("", auditController.List)
+		}
+
+		// tenant and tenant quota resources, administrators only, the users read the quota of
+		// their own tenant
+		tenantv1 := v1.Group("/tenants", middleware.Validation())
+		{
+			tenantController := tenant.NewTenantController(storeIns)
+
+			tenantv1.DELETE(":name", tenantController.Delete)
+
+			quotaController := quota.NewQuotaController(storeIns)
+
+			tenantv1.GET(":name/quota", quotaController.Get)
+			tenantv1.PUT(":name/quota", quotaController.Update)
+			tenantv1.DELETE(":name/quota", quotaController.Delete)
+		}
+
+		// long-running operation resource, the users read the operations they requested
+		operationv1 := v1.Group("/operations", middleware.Validation())
+		{
+			operationController := operation.NewOperationController(storeIns)
+
+			operationv1.GET(":id", operationController.Get)
+		}
+	}
+
+	// snapshot of all resources, administrators only, the exports are streamed so they are not
+	// served by the v1 group, which buffers the GET responses for their ETag
+	snapshotv1 := g.Group("/v1", middleware.Audit(), auto.AuthFunc(), middleware.ActiveUser(), limit,
+		middleware.Validation())
+	{
+		snapshotController := snapshot.NewSnapshotController(storeIns)
+
+		snapshotv1.GET("/export", snapshotController.Export)
+		snapshotv1.POST("/import", middleware.Publish(), snapshotController.Import)
+	}
+
+	// SCIM 2.0 provisioning endpoints used by the identity providers, administrators only
+	scimv2 := g.Group("/scim/v2", middleware.Audit(), auto.AuthFunc(), middleware.ActiveUser(), limit,
+		middleware.Validation(), middleware.Publish())
+	{
+		scimController := scim.NewScimController(storeIns)
+
+		scimv2.POST("/Users", scimController.CreateUser)
+		scimv2.GET("/Users", scimController.ListUsers)
+		scimv2.GET("/Users/:id", scimController.GetUser)
+		scimv2.PATCH("/Users/:id", scimController.PatchUser)
+		scimv2.POST("/Groups", scimController.CreateGroup)
+		scimv2.GET("/Groups", scimController.ListGroups)
+		scimv2.GET("/Groups/:id", scimController.GetGroup)
+		scimv2.PATCH("/Groups/:id", scimController.PatchGroup)
 	}
 
 	return g
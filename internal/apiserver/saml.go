@@ -0,0 +1,155 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	authstrategy "github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/saml"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+const (
+	// samlRequestKeyPrefix is the prefix of the redis keys of the pending SAML authentication requests.
+	samlRequestKeyPrefix = "iam-saml-request-"
+
+	// samlRequestTimeout is how long the users have to log in on the identity provider.
+	samlRequestTimeout = 10 * time.Minute
+)
+
+type samlHandler struct {
+	sp       *saml.ServiceProvider
+	opts     *saml.SAMLOptions
+	jwt      authstrategy.JWTStrategy
+	requests *storage.RedisCluster
+}
+
+// installSAML installs the SAML 2.0 service provider endpoints. A successful SAML login
+// returns the same jwt token as the /login endpoint.
+func installSAML(g *gin.Engine, opts *saml.SAMLOptions, jwtStrategy authstrategy.JWTStrategy) {
+	sp, err := saml.NewServiceProvider(opts)
+	if err != nil {
+		log.Fatalf("create SAML service provider failed: %s", err.Error())
+	}
+
+	h := &samlHandler{
+		sp:       sp,
+		opts:     opts,
+		jwt:      jwtStrategy,
+		requests: &storage.RedisCluster{},
+	}
+
+	samlv := g.Group("/saml")
+	{
+		samlv.GET("/metadata", h.metadata)
+		samlv.GET("/login", h.login)
+		samlv.POST("/acs", h.acs)
+	}
+}
+
+func (h *samlHandler) metadata(c *gin.Context) {
+	c.Data(http.StatusOK, "application/samlmetadata+xml", h.sp.Metadata())
+}
+
+// login redirects the user agent to the identity provider, the redirect query parameter is
+// passed as the RelayState and the user agent is redirected to it after the login.
+func (h *samlHandler) login(c *gin.Context) {
+	redirect, id, err := h.sp.AuthnRequestURL(c.Query("redirect"))
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	if err := h.requests.SetKey(samlRequestKeyPrefix+id, "1", samlRequestTimeout); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// acs is the assertion consumer service which receives the responses of the identity provider.
+func (h *samlHandler) acs(c *gin.Context) {
+	assertion, err := h.sp.ParseResponse(c.PostForm("SAMLResponse"), h.consumeRequest)
+	if err != nil {
+		log.L(c).Warnf("invalid SAML response: %s", err.Error())
+		core.WriteResponse(c, errors.WithCode(code.ErrSignatureInvalid, err.Error()), nil)
+
+		return
+	}
+
+	username := assertion.NameID
+	if h.opts.UsernameAttribute != "" {
+		username = assertion.Attribute(h.opts.UsernameAttribute)
+	}
+
+	user, err := h.getOrCreateUser(c, username, assertion)
+	if err != nil {
+		recordLogin(c, username, apiv1.LoginMethodSAML, errors.ParseCoder(err).String())
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	finishLogin(c, h.jwt, user, apiv1.LoginMethodSAML, c.PostForm("RelayState"))
+}
+
+// consumeRequest reports whether the authentication request is pending, and deletes it so
+// that a response can only be used once.
+func (h *samlHandler) consumeRequest(id string) bool {
+	if _, err := h.requests.GetKey(samlRequestKeyPrefix + id); err != nil {
+		return false
+	}
+
+	return h.requests.DeleteKey(samlRequestKeyPrefix + id)
+}
+
+// getOrCreateUser returns the iam user of the assertion, the user is created on the first
+// login when the just-in-time provisioning is enabled.
+func (h *samlHandler) getOrCreateUser(c *gin.Context, username string, assertion *saml.Assertion) (*v1.User, error) {
+	user, err := store.Client().Users().Get(c, username, metav1.GetOptions{})
+	if err == nil {
+		return user, nil
+	}
+
+	if !errors.IsCode(err, code.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if !h.opts.JITProvisioning {
+		return nil, errors.WithCode(code.ErrPermissionDenied, "user %s does not exist", username)
+	}
+
+	email := assertion.Attribute(h.opts.EmailAttribute)
+	if email == "" && strings.Contains(assertion.NameID, "@") {
+		email = assertion.NameID
+	}
+
+	user = &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: username},
+		Nickname:   assertion.Attribute(h.opts.NicknameAttribute),
+		Email:      email,
+	}
+	if err := provisionUser(c, user, apiv1.LoginMethodSAML); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
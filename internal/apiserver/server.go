@@ -7,29 +7,53 @@ package apiserver
 import (
 	"context"
 	"fmt"
+	"time"
 
-	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
+	// register the iam specific conditions.
 	"github.com/marmotedu/iam/internal/apiserver/config"
 	cachev1 "github.com/marmotedu/iam/internal/apiserver/controller/v1/cache"
+	"github.com/marmotedu/iam/internal/apiserver/controller/v1/gateway"
 	"github.com/marmotedu/iam/internal/apiserver/store"
 	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/admission"
+	_ "github.com/marmotedu/iam/internal/pkg/condition"
+	"github.com/marmotedu/iam/internal/pkg/connector"
+	"github.com/marmotedu/iam/internal/pkg/grpcerror"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/pkg/operation"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/saml"
 	genericapiserver "github.com/marmotedu/iam/internal/pkg/server"
 	"github.com/marmotedu/iam/pkg/log"
 	"github.com/marmotedu/iam/pkg/shutdown"
 	"github.com/marmotedu/iam/pkg/shutdown/shutdownmanagers/posixsignal"
 	"github.com/marmotedu/iam/pkg/storage"
+
+	"github.com/marmotedu/iam/internal/pkg/faultinjection"
+	"github.com/marmotedu/iam/internal/pkg/loginthrottle"
+	"github.com/marmotedu/iam/internal/pkg/ratelimit"
+	"github.com/marmotedu/iam/internal/pkg/upgrade"
 )
 
 type apiServer struct {
-	gs               *shutdown.GracefulShutdown
-	redisOptions     *genericoptions.RedisOptions
-	gRPCAPIServer    *grpcAPIServer
-	genericAPIServer *genericapiserver.GenericAPIServer
+	gs                   *shutdown.GracefulShutdown
+	redisOptions         *genericoptions.RedisOptions
+	samlOptions          *saml.SAMLOptions
+	rateLimitOptions     *ratelimit.RateLimitOptions
+	loginThrottleOptions *loginthrottle.LoginThrottleOptions
+	operationOptions     *operation.OperationOptions
+	connectorOptions     *connector.ConnectorOptions
+	faultOptions         *faultinjection.FaultInjectionOptions
+	gRPCAPIServer        *grpcAPIServer
+	genericAPIServer     *genericapiserver.GenericAPIServer
 }
 
 type preparedAPIServer struct {
@@ -48,6 +72,7 @@ type ExtraConfig struct {
 func createAPIServer(cfg *config.Config) (*apiServer, error) {
 	gs := shutdown.New()
 	gs.AddShutdownManager(posixsignal.NewPosixSignalManager())
+	gs.AddShutdownManager(upgrade.NewManager())
 
 	genericConfig, err := buildGenericConfig(cfg)
 	if err != nil {
@@ -68,20 +93,49 @@ func createAPIServer(cfg *config.Config) (*apiServer, error) {
 		return nil, err
 	}
 
+	admissionChain, err := admission.NewChain(cfg.AdmissionOptions)
+	if err != nil {
+		return nil, err
+	}
+	admission.SetChain(admissionChain)
+	pagination.SetOptions(cfg.PaginationOptions)
+
+	userNotifier, err := cfg.NotificationOptions.NewNotifier()
+	if err != nil {
+		return nil, err
+	}
+	notifier.SetNotifier(userNotifier)
+
 	server := &apiServer{
-		gs:               gs,
-		redisOptions:     cfg.RedisOptions,
-		genericAPIServer: genericServer,
-		gRPCAPIServer:    extraServer,
+		gs:                   gs,
+		redisOptions:         cfg.RedisOptions,
+		samlOptions:          cfg.SAMLOptions,
+		rateLimitOptions:     cfg.RateLimitOptions,
+		loginThrottleOptions: cfg.LoginThrottleOptions,
+		operationOptions:     cfg.OperationOptions,
+		connectorOptions:     cfg.ConnectorOptions,
+		faultOptions:         cfg.FaultInjectionOptions,
+		genericAPIServer:     genericServer,
+		gRPCAPIServer:        extraServer,
 	}
 
 	return server, nil
 }
 
 func (s *apiServer) PrepareRun() preparedAPIServer {
-	initRouter(s.genericAPIServer.Engine)
+	initRouter(
+		s.genericAPIServer.Engine,
+		s.samlOptions,
+		s.connectorOptions,
+		s.rateLimitOptions,
+		s.loginThrottleOptions,
+		s.faultOptions,
+	)
+	// the grpc api is served by the rest handlers
+	gateway.Register(s.gRPCAPIServer.Server, gateway.NewGatewayController(s.genericAPIServer.Engine))
 
 	s.initRedisStore()
+	s.initOperationPool()
 
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
 		mysqlStore, _ := mysql.GetMySQLFactoryOr(nil)
@@ -99,7 +153,7 @@ func (s *apiServer) PrepareRun() preparedAPIServer {
 }
 
 func (s preparedAPIServer) Run() error {
-	go s.gRPCAPIServer.Run()
+	s.gRPCAPIServer.Run()
 
 	// start shutdown managers
 	if err := s.gs.Start(); err != nil {
@@ -128,7 +182,18 @@ func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 	if err != nil {
 		log.Fatalf("Failed to generate credentials %s", err.Error())
 	}
-	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(c.MaxMsgSize), grpc.Creds(creds)}
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(c.MaxMsgSize),
+		grpc.Creds(creds),
+		// iam-authz-server keeps its idle connections alive with pings
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+		// the grpc clients get the business error codes like the rest clients
+		grpc.ChainUnaryInterceptor(grpcerror.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpcerror.StreamServerInterceptor()),
+	}
 	grpcServer := grpc.NewServer(opts...)
 
 	storeIns, _ := mysql.GetMySQLFactoryOr(c.mysqlOptions)
@@ -139,7 +204,9 @@ func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 		log.Fatalf("Failed to get cache instance: %s", err.Error())
 	}
 
-	pb.RegisterCacheServer(grpcServer, cacheIns)
+	cachev1.Register(grpcServer, cacheIns)
+	// iam-authz-server fails over to another apiserver according to the health status
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
 
 	reflection.Register(grpcServer)
 
@@ -200,8 +267,24 @@ func (s *apiServer) initRedisStore() {
 		EnableCluster:         s.redisOptions.EnableCluster,
 		UseSSL:                s.redisOptions.UseSSL,
 		SSLInsecureSkipVerify: s.redisOptions.SSLInsecureSkipVerify,
+		ClientCacheSize:       s.redisOptions.ClientCacheSize,
+		ClientCacheTTL:        s.redisOptions.ClientCacheTTL,
 	}
 
 	// try to connect to redis
 	go storage.ConnectToRedis(ctx, config)
 }
+
+// initOperationPool starts the workers executing the long-running operations, along with the
+// ones interrupted by the last stop.
+func (s *apiServer) initOperationPool() {
+	pool := operation.NewPool(store.Client(), s.operationOptions)
+	operation.SetPool(pool)
+	pool.Start()
+
+	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		pool.Stop()
+
+		return nil
+	}))
+}
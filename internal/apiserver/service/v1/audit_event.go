@@ -0,0 +1,39 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// AuditEventSrv defines functions used to handle audit event request.
+type AuditEventSrv interface {
+	List(ctx context.Context, opts v1.AuditEventListOptions) (*v1.AuditEventList, error)
+}
+
+type auditEventService struct {
+	store store.Factory
+}
+
+var _ AuditEventSrv = (*auditEventService)(nil)
+
+func newAuditEvents(srv *service) *auditEventService {
+	return &auditEventService{store: srv.store}
+}
+
+func (a *auditEventService) List(ctx context.Context, opts v1.AuditEventListOptions) (*v1.AuditEventList, error) {
+	events, err := a.store.AuditEvents().List(ctx, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return events, nil
+}
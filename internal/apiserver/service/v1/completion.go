@@ -0,0 +1,65 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"strings"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// CompletionSrv defines functions used to handle name completion request.
+type CompletionSrv interface {
+	List(ctx context.Context, username string, opts v1.CompletionOptions) (*v1.CompletionList, error)
+}
+
+type completionService struct {
+	store store.Factory
+}
+
+var _ CompletionSrv = (*completionService)(nil)
+
+func newCompletions(srv *service) *completionService {
+	return &completionService{store: srv.store}
+}
+
+// List returns the names of the resources of the user which start with the prefix. The names
+// of all the users are only completed for the administrators, the others only get their own.
+func (c *completionService) List(
+	ctx context.Context,
+	username string,
+	opts v1.CompletionOptions,
+) (*v1.CompletionList, error) {
+	ret := &v1.CompletionList{Kind: opts.Kind, Items: make([]string, 0)}
+
+	if opts.Kind == v1.CompletionKindUser {
+		user, err := c.store.Users().Get(ctx, username, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		if user.IsAdmin != 1 {
+			if strings.HasPrefix(username, opts.Prefix) {
+				ret.Items = append(ret.Items, username)
+			}
+
+			return ret, nil
+		}
+	}
+
+	names, err := c.store.Completions().Names(ctx, opts.Kind, username, opts.Prefix, opts.Limit)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+	ret.Items = names
+
+	return ret, nil
+}
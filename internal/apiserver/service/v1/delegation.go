@@ -0,0 +1,148 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// DelegationSrv defines functions used to check the rights of the delegated administrators.
+type DelegationSrv interface {
+	// Check returns a ErrPermissionDenied error unless the administrator administers the user.
+	Check(ctx context.Context, admin, username string) error
+	// ListUsers returns the users the administrator administers.
+	ListUsers(ctx context.Context, admin string, opts metav1.ListOptions) (*v1.UserList, error)
+}
+
+type delegationService struct {
+	store store.Factory
+}
+
+var _ DelegationSrv = (*delegationService)(nil)
+
+func newDelegations(srv *service) *delegationService {
+	return &delegationService{store: srv.store}
+}
+
+// Check returns a ErrPermissionDenied error unless the administrator administers the user.
+// The platform administrators administer every user.
+func (d *delegationService) Check(ctx context.Context, admin, username string) error {
+	inScope, err := d.scopeOf(ctx, admin)
+	if err != nil {
+		return err
+	}
+
+	user, err := d.store.Users().Get(ctx, username, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if !inScope(user) {
+		return errors.WithCode(code.ErrPermissionDenied, "user %s is out of the scope of administrator %s", username, admin)
+	}
+
+	return nil
+}
+
+// ListUsers returns the users the administrator administers.
+func (d *delegationService) ListUsers(
+	ctx context.Context,
+	admin string,
+	opts metav1.ListOptions,
+) (*v1.UserList, error) {
+	inScope, err := d.scopeOf(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	all := int64(-1)
+	users, err := d.store.Users().List(ctx, metav1.ListOptions{FieldSelector: opts.FieldSelector, Limit: &all})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	items := make([]*v1.User, 0)
+	for _, user := range users.Items {
+		if inScope(user) {
+			items = append(items, user)
+		}
+	}
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	total := int64(len(items))
+	if ol.Offset > len(items) {
+		ol.Offset = len(items)
+	}
+	items = items[ol.Offset:]
+	if ol.Limit >= 0 && ol.Limit < len(items) {
+		items = items[:ol.Limit]
+	}
+
+	return &v1.UserList{ListMeta: metav1.ListMeta{TotalCount: total}, Items: items}, nil
+}
+
+// scopeOf returns whether a user is in the scope of the administrator. The members of the
+// groups of the scope are resolved once, so that the users can be checked in a loop.
+func (d *delegationService) scopeOf(ctx context.Context, admin string) (func(user *v1.User) bool, error) {
+	owner, err := d.store.Users().Get(ctx, admin, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if owner.IsAdmin == 1 {
+		return func(*v1.User) bool { return true }, nil
+	}
+
+	scope, err := adminscope.FromExtend(owner.Extend)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrValidation, err.Error())
+	}
+	if scope == nil {
+		return nil, errors.WithCode(code.ErrPermissionDenied, "user %s is not a administrator", admin)
+	}
+
+	members := make(map[string]bool)
+	for _, ref := range scope.Groups {
+		group, err := d.store.Groups().Get(ctx, ref.Owner, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			// a deleted group grants nothing
+			if errors.IsCode(err, code.ErrGroupNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		for _, member := range group.Members {
+			members[member] = true
+		}
+	}
+
+	ownTenant := tenant.FromExtend(owner.Extend)
+
+	return func(user *v1.User) bool {
+		// the administrators never administer one another
+		if user.IsAdmin == 1 || (user.Name != owner.Name && user.Extend[adminscope.ExtendKey] != nil) {
+			return false
+		}
+
+		if scope.Tenant && tenant.FromExtend(user.Extend) == ownTenant {
+			return true
+		}
+
+		return members[apiv1.SubjectKindUser+":"+user.Name]
+	}, nil
+}
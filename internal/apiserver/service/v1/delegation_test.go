@@ -0,0 +1,100 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_delegationService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := map[string]*v1.User{
+		"orgadmin": {ObjectMeta: metav1.ObjectMeta{Name: "orgadmin", Extend: metav1.Extend{
+			tenant.ExtendKey: "marmotedu",
+			adminscope.ExtendKey: map[string]interface{}{
+				"tenant": true,
+				"groups": []interface{}{map[string]interface{}{"owner": "admin", "name": "dev"}},
+			},
+		}}},
+		"colin": {ObjectMeta: metav1.ObjectMeta{Name: "colin", Extend: metav1.Extend{tenant.ExtendKey: "marmotedu"}}},
+		"peter": {ObjectMeta: metav1.ObjectMeta{Name: "peter"}},
+		"ken":   {ObjectMeta: metav1.ObjectMeta{Name: "ken"}},
+		"admin": {ObjectMeta: metav1.ObjectMeta{Name: "admin", Extend: metav1.Extend{tenant.ExtendKey: "marmotedu"}}, IsAdmin: 1},
+		"teamlead": {ObjectMeta: metav1.ObjectMeta{Name: "teamlead", Extend: metav1.Extend{
+			tenant.ExtendKey:     "marmotedu",
+			adminscope.ExtendKey: map[string]interface{}{"tenant": true},
+		}}},
+	}
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockGroupStore := store.NewMockGroupStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockFactory.EXPECT().Groups().AnyTimes().Return(mockGroupStore)
+	mockUserStore.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, username string, _ metav1.GetOptions) (*v1.User, error) {
+			if user, ok := users[username]; ok {
+				return user, nil
+			}
+
+			return nil, errors.WithCode(code.ErrUserNotFound, "")
+		})
+	mockGroupStore.EXPECT().Get(gomock.Any(), "admin", "dev", gomock.Any()).AnyTimes().Return(
+		&apiv1.Group{Username: "admin", Members: []string{"users:peter"}}, nil)
+
+	srv := &delegationService{store: mockFactory}
+
+	tests := []struct {
+		name     string
+		admin    string
+		username string
+		wantCode int
+	}{
+		{"platform administrator", "admin", "ken", 0},
+		{"same tenant", "orgadmin", "colin", 0},
+		{"group member", "orgadmin", "peter", 0},
+		{"out of scope", "orgadmin", "ken", code.ErrPermissionDenied},
+		{"platform administrator out of scope", "orgadmin", "admin", code.ErrPermissionDenied},
+		{"delegated administrator out of scope", "orgadmin", "teamlead", code.ErrPermissionDenied},
+		{"not an administrator", "colin", "peter", code.ErrPermissionDenied},
+		{"user not found", "orgadmin", "maria", code.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := srv.Check(context.TODO(), tt.admin, tt.username)
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+			assert.Equal(t, tt.wantCode, errors.ParseCoder(err).Code())
+		})
+	}
+
+	mockUserStore.EXPECT().List(gomock.Any(), gomock.Any()).Return(&v1.UserList{Items: []*v1.User{
+		users["admin"], users["colin"], users["ken"], users["peter"], users["teamlead"],
+	}}, nil)
+
+	limit := int64(1)
+	list, err := srv.ListUsers(context.TODO(), "orgadmin", metav1.ListOptions{Limit: &limit})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), list.TotalCount)
+	assert.Equal(t, []*v1.User{users["colin"]}, list.Items)
+}
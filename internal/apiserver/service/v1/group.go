@@ -0,0 +1,91 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// GroupSrv defines functions used to handle group request.
+type GroupSrv interface {
+	Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error
+	Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Group, error)
+	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.GroupList, error)
+}
+
+type groupService struct {
+	store store.Factory
+}
+
+var _ GroupSrv = (*groupService)(nil)
+
+func newGroups(srv *service) *groupService {
+	return &groupService{store: srv.store}
+}
+
+func (s *groupService) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error {
+	_, err := s.store.Groups().Get(ctx, group.Username, group.Name, metav1.GetOptions{})
+	if err == nil {
+		return errors.WithCode(code.ErrGroupAlreadyExist, "group %s already exist", group.Name)
+	}
+
+	if !errors.IsCode(err, code.ErrGroupNotFound) {
+		return err
+	}
+
+	// store the default kind, so that groups can be filtered by kind
+	if group.Kind == "" {
+		group.Kind = v1.SubjectKindGroup
+	}
+
+	if err := s.store.Groups().Create(ctx, group, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (s *groupService) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error {
+	if err := s.store.Groups().Update(ctx, group, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (s *groupService) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	if err := s.store.Groups().Delete(ctx, username, name, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *groupService) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Group, error) {
+	group, err := s.store.Groups().Get(ctx, username, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *groupService) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.GroupList, error) {
+	groups, err := s.store.Groups().List(ctx, username, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return groups, nil
+}
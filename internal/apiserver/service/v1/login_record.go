@@ -0,0 +1,44 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// LoginRecordSrv defines functions used to handle login history request.
+type LoginRecordSrv interface {
+	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.LoginRecordList, error)
+}
+
+type loginRecordService struct {
+	store store.Factory
+}
+
+var _ LoginRecordSrv = (*loginRecordService)(nil)
+
+func newLoginRecords(srv *service) *loginRecordService {
+	return &loginRecordService{store: srv.store}
+}
+
+func (l *loginRecordService) List(
+	ctx context.Context,
+	username string,
+	opts metav1.ListOptions,
+) (*v1.LoginRecordList, error) {
+	records, err := l.store.LoginRecords().List(ctx, username, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return records, nil
+}
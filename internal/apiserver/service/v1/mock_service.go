@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/marmotedu/iam/internal/apiserver/service/v1 (interfaces: Service,UserSrv,SecretSrv,PolicySrv)
+// Source: github.com/marmotedu/iam/internal/apiserver/service/v1 (interfaces: Service,UserSrv,SecretSrv,PolicySrv,PolicyAttachmentSrv,LoginRecordSrv,GroupSrv,AuditEventSrv,CompletionSrv,QuotaSrv,OperationSrv,TenantSrv,SnapshotSrv)
 
 // Package v1 is a generated GoMock package.
 package v1
@@ -11,10 +11,15 @@ package v1
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	v10 "github.com/marmotedu/component-base/pkg/meta/v1"
+	session "github.com/marmotedu/iam/internal/pkg/session"
+	snapshot "github.com/marmotedu/iam/internal/pkg/snapshot"
+	userstate "github.com/marmotedu/iam/internal/pkg/userstate"
+	v12 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 )
 
 // MockService is a mock of Service interface.
@@ -40,6 +45,90 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AuditEvents mocks base method.
+func (m *MockService) AuditEvents() AuditEventSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditEvents")
+	ret0, _ := ret[0].(AuditEventSrv)
+	return ret0
+}
+
+// AuditEvents indicates an expected call of AuditEvents.
+func (mr *MockServiceMockRecorder) AuditEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditEvents", reflect.TypeOf((*MockService)(nil).AuditEvents))
+}
+
+// Completions mocks base method.
+func (m *MockService) Completions() CompletionSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Completions")
+	ret0, _ := ret[0].(CompletionSrv)
+	return ret0
+}
+
+// Completions indicates an expected call of Completions.
+func (mr *MockServiceMockRecorder) Completions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Completions", reflect.TypeOf((*MockService)(nil).Completions))
+}
+
+// Delegations mocks base method.
+func (m *MockService) Delegations() DelegationSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delegations")
+	ret0, _ := ret[0].(DelegationSrv)
+	return ret0
+}
+
+// Delegations indicates an expected call of Delegations.
+func (mr *MockServiceMockRecorder) Delegations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delegations", reflect.TypeOf((*MockService)(nil).Delegations))
+}
+
+// Groups mocks base method.
+func (m *MockService) Groups() GroupSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Groups")
+	ret0, _ := ret[0].(GroupSrv)
+	return ret0
+}
+
+// Groups indicates an expected call of Groups.
+func (mr *MockServiceMockRecorder) Groups() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Groups", reflect.TypeOf((*MockService)(nil).Groups))
+}
+
+// LoginRecords mocks base method.
+func (m *MockService) LoginRecords() LoginRecordSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginRecords")
+	ret0, _ := ret[0].(LoginRecordSrv)
+	return ret0
+}
+
+// LoginRecords indicates an expected call of LoginRecords.
+func (mr *MockServiceMockRecorder) LoginRecords() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginRecords", reflect.TypeOf((*MockService)(nil).LoginRecords))
+}
+
+// Operations mocks base method.
+func (m *MockService) Operations() OperationSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Operations")
+	ret0, _ := ret[0].(OperationSrv)
+	return ret0
+}
+
+// Operations indicates an expected call of Operations.
+func (mr *MockServiceMockRecorder) Operations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operations", reflect.TypeOf((*MockService)(nil).Operations))
+}
+
 // Policies mocks base method.
 func (m *MockService) Policies() PolicySrv {
 	m.ctrl.T.Helper()
@@ -54,6 +143,48 @@ func (mr *MockServiceMockRecorder) Policies() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Policies", reflect.TypeOf((*MockService)(nil).Policies))
 }
 
+// PolicyAttachments mocks base method.
+func (m *MockService) PolicyAttachments() PolicyAttachmentSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PolicyAttachments")
+	ret0, _ := ret[0].(PolicyAttachmentSrv)
+	return ret0
+}
+
+// PolicyAttachments indicates an expected call of PolicyAttachments.
+func (mr *MockServiceMockRecorder) PolicyAttachments() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PolicyAttachments", reflect.TypeOf((*MockService)(nil).PolicyAttachments))
+}
+
+// Quotas mocks base method.
+func (m *MockService) Quotas() QuotaSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quotas")
+	ret0, _ := ret[0].(QuotaSrv)
+	return ret0
+}
+
+// Quotas indicates an expected call of Quotas.
+func (mr *MockServiceMockRecorder) Quotas() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quotas", reflect.TypeOf((*MockService)(nil).Quotas))
+}
+
+// STS mocks base method.
+func (m *MockService) STS() STSSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "STS")
+	ret0, _ := ret[0].(STSSrv)
+	return ret0
+}
+
+// STS indicates an expected call of STS.
+func (mr *MockServiceMockRecorder) STS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "STS", reflect.TypeOf((*MockService)(nil).STS))
+}
+
 // Secrets mocks base method.
 func (m *MockService) Secrets() SecretSrv {
 	m.ctrl.T.Helper()
@@ -68,6 +199,34 @@ func (mr *MockServiceMockRecorder) Secrets() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Secrets", reflect.TypeOf((*MockService)(nil).Secrets))
 }
 
+// Snapshots mocks base method.
+func (m *MockService) Snapshots() SnapshotSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshots")
+	ret0, _ := ret[0].(SnapshotSrv)
+	return ret0
+}
+
+// Snapshots indicates an expected call of Snapshots.
+func (mr *MockServiceMockRecorder) Snapshots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshots", reflect.TypeOf((*MockService)(nil).Snapshots))
+}
+
+// Tenants mocks base method.
+func (m *MockService) Tenants() TenantSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tenants")
+	ret0, _ := ret[0].(TenantSrv)
+	return ret0
+}
+
+// Tenants indicates an expected call of Tenants.
+func (mr *MockServiceMockRecorder) Tenants() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tenants", reflect.TypeOf((*MockService)(nil).Tenants))
+}
+
 // Users mocks base method.
 func (m *MockService) Users() UserSrv {
 	m.ctrl.T.Helper()
@@ -206,6 +365,36 @@ func (mr *MockUserSrvMockRecorder) ListWithBadPerformance(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithBadPerformance", reflect.TypeOf((*MockUserSrv)(nil).ListWithBadPerformance), arg0, arg1)
 }
 
+// Purge mocks base method.
+func (m *MockUserSrv) Purge(arg0 context.Context, arg1 string) (*v12.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(*v12.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockUserSrvMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockUserSrv)(nil).Purge), arg0, arg1)
+}
+
+// Transition mocks base method.
+func (m *MockUserSrv) Transition(arg0 context.Context, arg1 string, arg2 userstate.Action) (*v1.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transition", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transition indicates an expected call of Transition.
+func (mr *MockUserSrvMockRecorder) Transition(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transition", reflect.TypeOf((*MockUserSrv)(nil).Transition), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *MockUserSrv) Update(arg0 context.Context, arg1 *v1.User, arg2 v10.UpdateOptions) error {
 	m.ctrl.T.Helper()
@@ -300,6 +489,21 @@ func (mr *MockSecretSrvMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSecretSrv)(nil).Get), arg0, arg1, arg2, arg3)
 }
 
+// LastUsed mocks base method.
+func (m *MockSecretSrv) LastUsed(arg0 context.Context, arg1 []string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastUsed", arg0, arg1)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastUsed indicates an expected call of LastUsed.
+func (mr *MockSecretSrvMockRecorder) LastUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastUsed", reflect.TypeOf((*MockSecretSrv)(nil).LastUsed), arg0, arg1)
+}
+
 // List mocks base method.
 func (m *MockSecretSrv) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v1.SecretList, error) {
 	m.ctrl.T.Helper()
@@ -424,6 +628,36 @@ func (mr *MockPolicySrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPolicySrv)(nil).List), arg0, arg1, arg2)
 }
 
+// ListEffective mocks base method.
+func (m *MockPolicySrv) ListEffective(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v1.PolicyList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEffective", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.PolicyList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEffective indicates an expected call of ListEffective.
+func (mr *MockPolicySrvMockRecorder) ListEffective(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEffective", reflect.TypeOf((*MockPolicySrv)(nil).ListEffective), arg0, arg1, arg2)
+}
+
+// ListForSubject mocks base method.
+func (m *MockPolicySrv) ListForSubject(arg0 context.Context, arg1, arg2 string, arg3 v10.ListOptions) (*v1.PolicyList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForSubject", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.PolicyList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListForSubject indicates an expected call of ListForSubject.
+func (mr *MockPolicySrvMockRecorder) ListForSubject(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForSubject", reflect.TypeOf((*MockPolicySrv)(nil).ListForSubject), arg0, arg1, arg2, arg3)
+}
+
 // Update mocks base method.
 func (m *MockPolicySrv) Update(arg0 context.Context, arg1 *v1.Policy, arg2 v10.UpdateOptions) error {
 	m.ctrl.T.Helper()
@@ -437,3 +671,562 @@ func (mr *MockPolicySrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPolicySrv)(nil).Update), arg0, arg1, arg2)
 }
+
+// MockPolicyAttachmentSrv is a mock of PolicyAttachmentSrv interface.
+type MockPolicyAttachmentSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyAttachmentSrvMockRecorder
+}
+
+// MockPolicyAttachmentSrvMockRecorder is the mock recorder for MockPolicyAttachmentSrv.
+type MockPolicyAttachmentSrvMockRecorder struct {
+	mock *MockPolicyAttachmentSrv
+}
+
+// NewMockPolicyAttachmentSrv creates a new mock instance.
+func NewMockPolicyAttachmentSrv(ctrl *gomock.Controller) *MockPolicyAttachmentSrv {
+	mock := &MockPolicyAttachmentSrv{ctrl: ctrl}
+	mock.recorder = &MockPolicyAttachmentSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyAttachmentSrv) EXPECT() *MockPolicyAttachmentSrvMockRecorder {
+	return m.recorder
+}
+
+// Attach mocks base method.
+func (m *MockPolicyAttachmentSrv) Attach(arg0 context.Context, arg1 *v12.PolicyAttachment, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attach", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Attach indicates an expected call of Attach.
+func (mr *MockPolicyAttachmentSrvMockRecorder) Attach(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attach", reflect.TypeOf((*MockPolicyAttachmentSrv)(nil).Attach), arg0, arg1, arg2)
+}
+
+// Detach mocks base method.
+func (m *MockPolicyAttachmentSrv) Detach(arg0 context.Context, arg1, arg2, arg3 string, arg4 v10.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detach", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Detach indicates an expected call of Detach.
+func (mr *MockPolicyAttachmentSrvMockRecorder) Detach(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detach", reflect.TypeOf((*MockPolicyAttachmentSrv)(nil).Detach), arg0, arg1, arg2, arg3, arg4)
+}
+
+// List mocks base method.
+func (m *MockPolicyAttachmentSrv) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v12.PolicyAttachmentList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.PolicyAttachmentList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockPolicyAttachmentSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPolicyAttachmentSrv)(nil).List), arg0, arg1, arg2)
+}
+
+// MockLoginRecordSrv is a mock of LoginRecordSrv interface.
+type MockLoginRecordSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginRecordSrvMockRecorder
+}
+
+// MockLoginRecordSrvMockRecorder is the mock recorder for MockLoginRecordSrv.
+type MockLoginRecordSrvMockRecorder struct {
+	mock *MockLoginRecordSrv
+}
+
+// NewMockLoginRecordSrv creates a new mock instance.
+func NewMockLoginRecordSrv(ctrl *gomock.Controller) *MockLoginRecordSrv {
+	mock := &MockLoginRecordSrv{ctrl: ctrl}
+	mock.recorder = &MockLoginRecordSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginRecordSrv) EXPECT() *MockLoginRecordSrvMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockLoginRecordSrv) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v12.LoginRecordList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.LoginRecordList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockLoginRecordSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLoginRecordSrv)(nil).List), arg0, arg1, arg2)
+}
+
+// MockGroupSrv is a mock of GroupSrv interface.
+type MockGroupSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupSrvMockRecorder
+}
+
+// MockGroupSrvMockRecorder is the mock recorder for MockGroupSrv.
+type MockGroupSrvMockRecorder struct {
+	mock *MockGroupSrv
+}
+
+// NewMockGroupSrv creates a new mock instance.
+func NewMockGroupSrv(ctrl *gomock.Controller) *MockGroupSrv {
+	mock := &MockGroupSrv{ctrl: ctrl}
+	mock.recorder = &MockGroupSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupSrv) EXPECT() *MockGroupSrvMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockGroupSrv) Create(arg0 context.Context, arg1 *v12.Group, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGroupSrvMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroupSrv)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockGroupSrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v10.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGroupSrvMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGroupSrv)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// Get mocks base method.
+func (m *MockGroupSrv) Get(arg0 context.Context, arg1, arg2 string, arg3 v10.GetOptions) (*v12.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v12.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockGroupSrvMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockGroupSrv)(nil).Get), arg0, arg1, arg2, arg3)
+}
+
+// List mocks base method.
+func (m *MockGroupSrv) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v12.GroupList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.GroupList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockGroupSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockGroupSrv)(nil).List), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockGroupSrv) Update(arg0 context.Context, arg1 *v12.Group, arg2 v10.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockGroupSrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockGroupSrv)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockAuditEventSrv is a mock of AuditEventSrv interface.
+type MockAuditEventSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditEventSrvMockRecorder
+}
+
+// MockAuditEventSrvMockRecorder is the mock recorder for MockAuditEventSrv.
+type MockAuditEventSrvMockRecorder struct {
+	mock *MockAuditEventSrv
+}
+
+// NewMockAuditEventSrv creates a new mock instance.
+func NewMockAuditEventSrv(ctrl *gomock.Controller) *MockAuditEventSrv {
+	mock := &MockAuditEventSrv{ctrl: ctrl}
+	mock.recorder = &MockAuditEventSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditEventSrv) EXPECT() *MockAuditEventSrvMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAuditEventSrv) List(arg0 context.Context, arg1 v12.AuditEventListOptions) (*v12.AuditEventList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*v12.AuditEventList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditEventSrvMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditEventSrv)(nil).List), arg0, arg1)
+}
+
+// MockCompletionSrv is a mock of CompletionSrv interface.
+type MockCompletionSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockCompletionSrvMockRecorder
+}
+
+// MockCompletionSrvMockRecorder is the mock recorder for MockCompletionSrv.
+type MockCompletionSrvMockRecorder struct {
+	mock *MockCompletionSrv
+}
+
+// NewMockCompletionSrv creates a new mock instance.
+func NewMockCompletionSrv(ctrl *gomock.Controller) *MockCompletionSrv {
+	mock := &MockCompletionSrv{ctrl: ctrl}
+	mock.recorder = &MockCompletionSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCompletionSrv) EXPECT() *MockCompletionSrvMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockCompletionSrv) List(arg0 context.Context, arg1 string, arg2 v12.CompletionOptions) (*v12.CompletionList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.CompletionList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCompletionSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCompletionSrv)(nil).List), arg0, arg1, arg2)
+}
+
+// MockQuotaSrv is a mock of QuotaSrv interface.
+type MockQuotaSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaSrvMockRecorder
+}
+
+// MockQuotaSrvMockRecorder is the mock recorder for MockQuotaSrv.
+type MockQuotaSrvMockRecorder struct {
+	mock *MockQuotaSrv
+}
+
+// NewMockQuotaSrv creates a new mock instance.
+func NewMockQuotaSrv(ctrl *gomock.Controller) *MockQuotaSrv {
+	mock := &MockQuotaSrv{ctrl: ctrl}
+	mock.recorder = &MockQuotaSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaSrv) EXPECT() *MockQuotaSrvMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockQuotaSrv) Delete(arg0 context.Context, arg1 string, arg2 v10.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockQuotaSrvMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockQuotaSrv)(nil).Delete), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockQuotaSrv) Get(arg0 context.Context, arg1 string, arg2 v10.GetOptions) (*v12.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockQuotaSrvMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockQuotaSrv)(nil).Get), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockQuotaSrv) Update(arg0 context.Context, arg1 *v12.Quota, arg2 v10.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockQuotaSrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockQuotaSrv)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockOperationSrv is a mock of OperationSrv interface.
+type MockOperationSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockOperationSrvMockRecorder
+}
+
+// MockOperationSrvMockRecorder is the mock recorder for MockOperationSrv.
+type MockOperationSrvMockRecorder struct {
+	mock *MockOperationSrv
+}
+
+// NewMockOperationSrv creates a new mock instance.
+func NewMockOperationSrv(ctrl *gomock.Controller) *MockOperationSrv {
+	mock := &MockOperationSrv{ctrl: ctrl}
+	mock.recorder = &MockOperationSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOperationSrv) EXPECT() *MockOperationSrvMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockOperationSrv) Get(arg0 context.Context, arg1 string, arg2 v10.GetOptions) (*v12.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOperationSrvMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOperationSrv)(nil).Get), arg0, arg1, arg2)
+}
+
+// MockTenantSrv is a mock of TenantSrv interface.
+type MockTenantSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantSrvMockRecorder
+}
+
+// MockTenantSrvMockRecorder is the mock recorder for MockTenantSrv.
+type MockTenantSrvMockRecorder struct {
+	mock *MockTenantSrv
+}
+
+// NewMockTenantSrv creates a new mock instance.
+func NewMockTenantSrv(ctrl *gomock.Controller) *MockTenantSrv {
+	mock := &MockTenantSrv{ctrl: ctrl}
+	mock.recorder = &MockTenantSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTenantSrv) EXPECT() *MockTenantSrvMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockTenantSrv) Delete(arg0 context.Context, arg1 string, arg2 v10.DeleteOptions) (*v12.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v12.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTenantSrvMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTenantSrv)(nil).Delete), arg0, arg1, arg2)
+}
+
+// MockSnapshotSrv is a mock of SnapshotSrv interface.
+type MockSnapshotSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotSrvMockRecorder
+}
+
+// MockSnapshotSrvMockRecorder is the mock recorder for MockSnapshotSrv.
+type MockSnapshotSrvMockRecorder struct {
+	mock *MockSnapshotSrv
+}
+
+// NewMockSnapshotSrv creates a new mock instance.
+func NewMockSnapshotSrv(ctrl *gomock.Controller) *MockSnapshotSrv {
+	mock := &MockSnapshotSrv{ctrl: ctrl}
+	mock.recorder = &MockSnapshotSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotSrv) EXPECT() *MockSnapshotSrvMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockSnapshotSrv) Export(arg0 context.Context, arg1 *snapshot.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockSnapshotSrvMockRecorder) Export(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockSnapshotSrv)(nil).Export), arg0, arg1)
+}
+
+// Import mocks base method.
+func (m *MockSnapshotSrv) Import(arg0 context.Context, arg1 *snapshot.Reader) (*v12.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].(*v12.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockSnapshotSrvMockRecorder) Import(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockSnapshotSrv)(nil).Import), arg0, arg1)
+}
+
+// MockDelegationSrv is a mock of DelegationSrv interface.
+type MockDelegationSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockDelegationSrvMockRecorder
+}
+
+// MockDelegationSrvMockRecorder is the mock recorder for MockDelegationSrv.
+type MockDelegationSrvMockRecorder struct {
+	mock *MockDelegationSrv
+}
+
+// NewMockDelegationSrv creates a new mock instance.
+func NewMockDelegationSrv(ctrl *gomock.Controller) *MockDelegationSrv {
+	mock := &MockDelegationSrv{ctrl: ctrl}
+	mock.recorder = &MockDelegationSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDelegationSrv) EXPECT() *MockDelegationSrvMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockDelegationSrv) Check(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockDelegationSrvMockRecorder) Check(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockDelegationSrv)(nil).Check), arg0, arg1, arg2)
+}
+
+// ListUsers mocks base method.
+func (m *MockDelegationSrv) ListUsers(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v1.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockDelegationSrvMockRecorder) ListUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockDelegationSrv)(nil).ListUsers), arg0, arg1, arg2)
+}
+
+// MockSTSSrv is a mock of STSSrv interface.
+type MockSTSSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockSTSSrvMockRecorder
+}
+
+// MockSTSSrvMockRecorder is the mock recorder for MockSTSSrv.
+type MockSTSSrvMockRecorder struct {
+	mock *MockSTSSrv
+}
+
+// NewMockSTSSrv creates a new mock instance.
+func NewMockSTSSrv(ctrl *gomock.Controller) *MockSTSSrv {
+	mock := &MockSTSSrv{ctrl: ctrl}
+	mock.recorder = &MockSTSSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSTSSrv) EXPECT() *MockSTSSrvMockRecorder {
+	return m.recorder
+}
+
+// AssumeRole mocks base method.
+func (m *MockSTSSrv) AssumeRole(arg0 context.Context, arg1 string, arg2 *session.Session, arg3 time.Duration) (*v1.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssumeRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssumeRole indicates an expected call of AssumeRole.
+func (mr *MockSTSSrvMockRecorder) AssumeRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssumeRole", reflect.TypeOf((*MockSTSSrv)(nil).AssumeRole), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// OperationSrv defines functions used to handle long-running operation request.
+type OperationSrv interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiv1.Operation, error)
+}
+
+type operationService struct {
+	store store.Factory
+}
+
+var _ OperationSrv = (*operationService)(nil)
+
+func newOperations(srv *service) *operationService {
+	return &operationService{store: srv.store}
+}
+
+func (s *operationService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiv1.Operation, error) {
+	op, err := s.store.Operations().Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
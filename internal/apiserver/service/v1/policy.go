@@ -10,9 +10,12 @@ import (
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/admission"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 )
 
 // PolicySrv defines functions used to handle policy request.
@@ -23,6 +26,8 @@ type PolicySrv interface {
 	DeleteCollection(ctx context.Context, username string, names []string, opts metav1.DeleteOptions) error
 	Get(ctx context.Context, username string, name string, opts metav1.GetOptions) (*v1.Policy, error)
 	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyList, error)
+	ListEffective(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyList, error)
+	ListForSubject(ctx context.Context, username, subject string, opts metav1.ListOptions) (*v1.PolicyList, error)
 }
 
 type policyService struct {
@@ -36,6 +41,14 @@ func newPolicies(srv *service) *policyService {
 }
 
 func (s *policyService) Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOptions) error {
+	if err := admission.Admit(ctx, admission.KindPolicy, admission.Create, policy); err != nil {
+		return err
+	}
+
+	if err := checkPolicyQuota(ctx, s.store, policy, true); err != nil {
+		return err
+	}
+
 	if err := s.store.Policies().Create(ctx, policy, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -44,8 +57,20 @@ func (s *policyService) Create(ctx context.Context, policy *v1.Policy, opts meta
 }
 
 func (s *policyService) Update(ctx context.Context, policy *v1.Policy, opts metav1.UpdateOptions) error {
+	if err := admission.Admit(ctx, admission.KindPolicy, admission.Update, policy); err != nil {
+		return err
+	}
+
+	if err := checkPolicyQuota(ctx, s.store, policy, false); err != nil {
+		return err
+	}
+
 	// Save changed fields.
 	if err := s.store.Policies().Update(ctx, policy, opts); err != nil {
+		if errors.IsCode(err, code.ErrConflict) {
+			return err
+		}
+
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
@@ -90,3 +115,122 @@ func (s *policyService) List(ctx context.Context, username string, opts metav1.L
 
 	return policies, nil
 }
+
+// ListEffective returns the policies of the user as iam-authz-server evaluates them, the
+// subjects the policies are attached to are added to the subjects written in the policies.
+func (s *policyService) ListEffective(
+	ctx context.Context,
+	username string,
+	opts metav1.ListOptions,
+) (*v1.PolicyList, error) {
+	policies, err := s.List(ctx, username, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.attachedSubjects(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*v1.Policy, 0, len(policies.Items))
+	for _, pol := range policies.Items {
+		extra, attached := subjects[pol.Username+"/"+pol.Name]
+		if !attached {
+			items = append(items, pol)
+
+			continue
+		}
+
+		// the stored policy is left untouched
+		effective := *pol
+		effective.Policy.Subjects = append(append([]string{}, pol.Policy.Subjects...), extra...)
+		effective.PolicyShadow = effective.Policy.String()
+		items = append(items, &effective)
+	}
+
+	return &v1.PolicyList{ListMeta: policies.ListMeta, Items: items}, nil
+}
+
+// ListForSubject returns the effective policies of the user which apply to the subject, e.g.
+// users:colin, either directly or through the groups and roles of the user containing it.
+func (s *policyService) ListForSubject(
+	ctx context.Context,
+	username, subject string,
+	opts metav1.ListOptions,
+) (*v1.PolicyList, error) {
+	subjects, err := s.subjectsOf(ctx, username, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	all := int64(-1)
+	policies, err := s.ListEffective(ctx, username, metav1.ListOptions{FieldSelector: opts.FieldSelector, Limit: &all})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*v1.Policy, 0)
+	for _, pol := range policies.Items {
+		for _, sub := range subjects {
+			matches, err := ladon.DefaultMatcher.Matches(&pol.Policy, pol.Policy.Subjects, sub)
+			if err != nil {
+				return nil, errors.WithCode(code.ErrValidation, "policy %s has an invalid subject: %s", pol.Name, err.Error())
+			}
+
+			if matches {
+				items = append(items, pol)
+
+				break
+			}
+		}
+	}
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	total := int64(len(items))
+	if ol.Offset > len(items) {
+		ol.Offset = len(items)
+	}
+	items = items[ol.Offset:]
+	if ol.Limit >= 0 && ol.Limit < len(items) {
+		items = items[:ol.Limit]
+	}
+
+	return &v1.PolicyList{ListMeta: metav1.ListMeta{TotalCount: total}, Items: items}, nil
+}
+
+// subjectsOf returns the subject along with the subjects of the groups and roles of the user
+// containing it.
+func (s *policyService) subjectsOf(ctx context.Context, username, subject string) ([]string, error) {
+	all := int64(-1)
+	groups, err := s.store.Groups().List(ctx, username, metav1.ListOptions{Limit: &all})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	subjects := []string{subject}
+	for _, group := range groups.Items {
+		if group.HasMember(subject) {
+			subjects = append(subjects, group.Subject())
+		}
+	}
+
+	return subjects, nil
+}
+
+// attachedSubjects returns the subjects of all policy attachments, keyed by username/policyName.
+func (s *policyService) attachedSubjects(ctx context.Context, username string) (map[string][]string, error) {
+	all := int64(-1)
+	attachments, err := s.store.PolicyAttachments().List(ctx, username, metav1.ListOptions{Limit: &all})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	subjects := make(map[string][]string)
+	for _, attachment := range attachments.Items {
+		key := attachment.Username + "/" + attachment.PolicyName
+		subjects[key] = append(subjects[key], attachment.Subject)
+	}
+
+	return subjects, nil
+}
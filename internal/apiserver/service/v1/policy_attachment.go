@@ -0,0 +1,86 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// PolicyAttachmentSrv defines functions used to handle policy attachment request.
+type PolicyAttachmentSrv interface {
+	Attach(ctx context.Context, attachment *v1.PolicyAttachment, opts metav1.CreateOptions) error
+	Detach(ctx context.Context, username, policyName, subject string, opts metav1.DeleteOptions) error
+	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyAttachmentList, error)
+}
+
+type policyAttachmentService struct {
+	store store.Factory
+}
+
+var _ PolicyAttachmentSrv = (*policyAttachmentService)(nil)
+
+func newPolicyAttachments(srv *service) *policyAttachmentService {
+	return &policyAttachmentService{store: srv.store}
+}
+
+func (s *policyAttachmentService) Attach(
+	ctx context.Context,
+	attachment *v1.PolicyAttachment,
+	opts metav1.CreateOptions,
+) error {
+	// only existing policies can be attached
+	if _, err := s.store.Policies().Get(ctx, attachment.Username, attachment.PolicyName, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	_, err := s.store.PolicyAttachments().Get(ctx, attachment.Username, attachment.PolicyName, attachment.Subject,
+		metav1.GetOptions{})
+	if err == nil {
+		return errors.WithCode(code.ErrAttachmentAlreadyExist, "policy %s is already attached to %s",
+			attachment.PolicyName, attachment.Subject)
+	}
+
+	if !errors.IsCode(err, code.ErrAttachmentNotFound) {
+		return err
+	}
+
+	if err := s.store.PolicyAttachments().Create(ctx, attachment, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (s *policyAttachmentService) Detach(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.DeleteOptions,
+) error {
+	if err := s.store.PolicyAttachments().Delete(ctx, username, policyName, subject, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *policyAttachmentService) List(
+	ctx context.Context,
+	username string,
+	opts metav1.ListOptions,
+) (*v1.PolicyAttachmentList, error) {
+	attachments, err := s.store.PolicyAttachments().List(ctx, username, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return attachments, nil
+}
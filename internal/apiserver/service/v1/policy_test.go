@@ -12,10 +12,13 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
 	"github.com/marmotedu/iam/internal/apiserver/store/fake"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 )
 
 type Suite struct {
@@ -55,6 +58,7 @@ func TestPolicy(t *testing.T) {
 }
 
 func (s *Suite) Test_policyService_Create() {
+	s.mockUserStore.EXPECT().Get(gomock.Any(), s.policies[0].Username, gomock.Any()).Return(&v1.User{}, nil)
 	s.mockPolicyStore.EXPECT().Create(gomock.Any(), gomock.Eq(s.policies[0]), gomock.Any()).Return(nil)
 	type fields struct {
 		store store.Factory
@@ -96,6 +100,7 @@ func (s *Suite) Test_policyService_Create() {
 }
 
 func (s *Suite) Test_policyService_Update() {
+	s.mockUserStore.EXPECT().Get(gomock.Any(), s.policies[0].Username, gomock.Any()).Return(&v1.User{}, nil)
 	s.mockPolicyStore.EXPECT().Update(gomock.Any(), gomock.Eq(s.policies[0]), gomock.Any()).Return(nil)
 
 	type fields struct {
@@ -370,3 +375,53 @@ func Test_newPolicies(t *testing.T) {
 		})
 	}
 }
+
+func Test_policyService_ListForSubject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockPolicyStore := store.NewMockPolicyStore(ctrl)
+	mockAttachmentStore := store.NewMockPolicyAttachmentStore(ctrl)
+	mockGroupStore := store.NewMockGroupStore(ctrl)
+	mockFactory.EXPECT().Policies().AnyTimes().Return(mockPolicyStore)
+	mockFactory.EXPECT().PolicyAttachments().AnyTimes().Return(mockAttachmentStore)
+	mockFactory.EXPECT().Groups().AnyTimes().Return(mockGroupStore)
+
+	policy := func(name string, subjects ...string) *v1.Policy {
+		return &v1.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Username:   "admin",
+			Policy:     v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{ID: name, Subjects: subjects}},
+		}
+	}
+	mockPolicyStore.EXPECT().List(gomock.Any(), "admin", gomock.Any()).Return(&v1.PolicyList{Items: []*v1.Policy{
+		policy("direct", "users:colin"),
+		policy("pattern", "users:<col.*>"),
+		policy("group", "groups:admins"),
+		policy("attached", "users:peter"),
+		policy("other", "users:peter", "groups:devs"),
+	}}, nil)
+	mockAttachmentStore.EXPECT().List(gomock.Any(), "admin", gomock.Any()).Return(
+		&apiv1.PolicyAttachmentList{Items: []*apiv1.PolicyAttachment{
+			{Username: "admin", PolicyName: "attached", Subject: "roles:auditor"},
+		}}, nil)
+	mockGroupStore.EXPECT().List(gomock.Any(), "admin", gomock.Any()).Return(&apiv1.GroupList{Items: []*apiv1.Group{
+		{ObjectMeta: metav1.ObjectMeta{Name: "admins"}, Members: []string{"users:colin"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "auditor"}, Kind: apiv1.SubjectKindRole, Members: []string{"users:colin"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "devs"}, Members: []string{"users:peter"}},
+	}}, nil)
+
+	s := &policyService{store: mockFactory}
+	got, err := s.ListForSubject(context.TODO(), "admin", "users:colin", metav1.ListOptions{})
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(got.Items))
+	for _, pol := range got.Items {
+		names = append(names, pol.Name)
+	}
+	assert.Equal(t, []string{"direct", "pattern", "group", "attached"}, names)
+	assert.Equal(t, int64(4), got.TotalCount)
+	// the attachments are resolved into the subjects
+	assert.Equal(t, []string{"users:peter", "roles:auditor"}, got.Items[3].Policy.Subjects)
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/instruction"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/operation"
+	"github.com/marmotedu/iam/internal/pkg/revocation"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// OperationPurgeUser is the kind of the operations purging a user.
+const OperationPurgeUser = "PurgeUser"
+
+// PurgedActor replaces the name of a purged user in the audit events, the events are kept
+// but can no longer be linked to the user.
+const PurgedActor = "purged-user"
+
+// revokeUser and sendInstruction reach redis, they are replaced by the tests.
+var (
+	revokeUser      = revocation.RevokeUser
+	sendInstruction = instruction.Send
+)
+
+func init() {
+	operation.Register(OperationPurgeUser, purgeUser)
+}
+
+// Purge submits an operation erasing the user and the data derived from it. The operation
+// keeps the name of the user as the record of the erasure.
+func (u *userService) Purge(ctx context.Context, username string) (*apiv1.Operation, error) {
+	if _, err := u.store.Users().Get(ctx, username, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	return operation.Submit(ctx, OperationPurgeUser, metav1.Extend{"user": username})
+}
+
+// purgeUser erases the user, its secrets, its policies, its groups, its sessions and its login
+// records, detaches it from the policies and removes it from the groups of the other users,
+// tombstones the actor of its audit events, and instructs iam-pump to erase its analytics
+// records. The report counts what was erased.
+func purgeUser(
+	ctx context.Context,
+	st store.Factory,
+	op *apiv1.Operation,
+	progress func(percent int),
+) (metav1.Extend, error) {
+	username, _ := op.Params["user"].(string)
+	opts := metav1.DeleteOptions{Unscoped: true}
+
+	// the tokens are revoked first, so that the user can not act while being purged
+	if err := revokeUser(ctx, username, revocation.MaxTTL); err != nil {
+		return nil, errors.WithCode(code.ErrUnknown, "revoke the sessions failed: %s", err.Error())
+	}
+	progress(10)
+
+	secrets, err := deleteSecretsOf(ctx, st, username, opts)
+	if err != nil {
+		return nil, err
+	}
+	progress(25)
+
+	// the attachments are deleted before the policies, the database deletes the attachments of
+	// the deleted policies along with them
+	attachments, err := detachUser(ctx, st, username, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := deletePoliciesOf(ctx, st, username, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, memberships, err := deleteGroupsOf(ctx, st, username, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := st.Users().Delete(ctx, username, opts); err != nil {
+		return nil, err
+	}
+	middleware.PublishUserChanged(ctx, username)
+	progress(40)
+
+	// the audit of the policies keeps the deleted ones, along with the name of their owner
+	policyAudits, err := st.PolicyAudits().DeleteByUser(ctx, username)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	logins, err := st.LoginRecords().DeleteByUser(ctx, username)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+	progress(60)
+
+	events, err := st.AuditEvents().Tombstone(ctx, username, PurgedActor)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+	progress(80)
+
+	if err := sendInstruction(ctx, &instruction.Instruction{
+		Kind:      instruction.KindEraseUser,
+		Username:  username,
+		Operation: op.Name,
+	}); err != nil {
+		return nil, errors.WithCode(code.ErrUnknown, "instruct iam-pump failed: %s", err.Error())
+	}
+
+	return metav1.Extend{
+		"secrets":           secrets,
+		"policies":          policies,
+		"policyAttachments": attachments,
+		"groups":            groups,
+		"groupMemberships":  memberships,
+		"policyAudits":      policyAudits,
+		"loginRecords":      logins,
+		"auditEvents":       events,
+		"sessions":          "revoked",
+		"analytics":         "erasure instructed",
+	}, nil
+}
+
+// detachUser deletes the attachments of the policies of the user and the attachments of the
+// policies of the other users to it, and returns their number.
+func detachUser(ctx context.Context, st store.Factory, username string, opts metav1.DeleteOptions) (int, error) {
+	all := int64(-1)
+	attachments, err := st.PolicyAttachments().List(ctx, "", metav1.ListOptions{Limit: &all})
+	if err != nil {
+		return 0, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	subject := apiv1.SubjectKindUser + ":" + username
+	count := 0
+	for _, attachment := range attachments.Items {
+		if attachment.Username != username && attachment.Subject != subject {
+			continue
+		}
+
+		if err := st.PolicyAttachments().Delete(ctx, attachment.Username, attachment.PolicyName,
+			attachment.Subject, opts); err != nil {
+			return 0, errors.WithCode(code.ErrDatabase, err.Error())
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// deleteGroupsOf deletes the groups of the user and removes it from the groups of the other
+// users, and returns the number of the deleted groups and of the removed memberships.
+func deleteGroupsOf(
+	ctx context.Context,
+	st store.Factory,
+	username string,
+	opts metav1.DeleteOptions,
+) (int, int, error) {
+	all := int64(-1)
+	groups, err := st.Groups().List(ctx, "", metav1.ListOptions{Limit: &all})
+	if err != nil {
+		return 0, 0, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	member := apiv1.SubjectKindUser + ":" + username
+	deleted, removed := 0, 0
+	for _, group := range groups.Items {
+		switch {
+		case group.Username == username:
+			if err := st.Groups().Delete(ctx, group.Username, group.Name, opts); err != nil {
+				return 0, 0, errors.WithCode(code.ErrDatabase, err.Error())
+			}
+			deleted++
+		case group.HasMember(member):
+			members := make([]string, 0, len(group.Members)-1)
+			for _, m := range group.Members {
+				if m != member {
+					members = append(members, m)
+				}
+			}
+			group.Members = members
+
+			if err := st.Groups().Update(ctx, group, metav1.UpdateOptions{}); err != nil {
+				return 0, 0, errors.WithCode(code.ErrDatabase, err.Error())
+			}
+			removed++
+		}
+	}
+
+	return deleted, removed, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/instruction"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// policyAudits deletes the policy audits of a user, the store is not mocked.
+type policyAudits struct {
+	deleted []string
+}
+
+func (p *policyAudits) ClearOutdated(context.Context, int) (int64, error) {
+	return 0, nil
+}
+
+func (p *policyAudits) DeleteByUser(_ context.Context, username string) (int64, error) {
+	p.deleted = append(p.deleted, username)
+
+	return 2, nil
+}
+
+func Test_purgeUser(t *testing.T) {
+	var revoked []string
+	var sent []*instruction.Instruction
+	defer func(revoke func(context.Context, string, time.Duration) error,
+		send func(context.Context, *instruction.Instruction) error) {
+		revokeUser, sendInstruction = revoke, send
+	}(revokeUser, sendInstruction)
+	revokeUser = func(_ context.Context, username string, _ time.Duration) error {
+		revoked = append(revoked, username)
+
+		return nil
+	}
+	sendInstruction = func(_ context.Context, ins *instruction.Instruction) error {
+		sent = append(sent, ins)
+
+		return nil
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	audits := &policyAudits{}
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockSecretStore := store.NewMockSecretStore(ctrl)
+	mockPolicyStore := store.NewMockPolicyStore(ctrl)
+	mockLoginRecordStore := store.NewMockLoginRecordStore(ctrl)
+	mockAuditEventStore := store.NewMockAuditEventStore(ctrl)
+	mockGroupStore := store.NewMockGroupStore(ctrl)
+	mockPolicyAttachmentStore := store.NewMockPolicyAttachmentStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockFactory.EXPECT().Secrets().AnyTimes().Return(mockSecretStore)
+	mockFactory.EXPECT().Policies().AnyTimes().Return(mockPolicyStore)
+	mockFactory.EXPECT().PolicyAudits().AnyTimes().Return(audits)
+	mockFactory.EXPECT().LoginRecords().AnyTimes().Return(mockLoginRecordStore)
+	mockFactory.EXPECT().AuditEvents().AnyTimes().Return(mockAuditEventStore)
+	mockFactory.EXPECT().Groups().AnyTimes().Return(mockGroupStore)
+	mockFactory.EXPECT().PolicyAttachments().AnyTimes().Return(mockPolicyAttachmentStore)
+
+	mockSecretStore.EXPECT().List(gomock.Any(), "colin", gomock.Any()).Return(&v1.SecretList{Items: []*v1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "secret0"}, Username: "colin"},
+	}}, nil)
+	mockSecretStore.EXPECT().DeleteCollection(gomock.Any(), "colin", []string{"secret0"}, gomock.Any()).Return(nil)
+	mockPolicyAttachmentStore.EXPECT().List(gomock.Any(), "", gomock.Any()).Return(&apiv1.PolicyAttachmentList{
+		Items: []*apiv1.PolicyAttachment{
+			{Username: "colin", PolicyName: "policy0", Subject: "users:james"},
+			{Username: "james", PolicyName: "policy1", Subject: "users:colin"},
+			{Username: "james", PolicyName: "policy1", Subject: "users:james"},
+		},
+	}, nil)
+	mockPolicyAttachmentStore.EXPECT().Delete(gomock.Any(), "colin", "policy0", "users:james", gomock.Any()).Return(nil)
+	mockPolicyAttachmentStore.EXPECT().Delete(gomock.Any(), "james", "policy1", "users:colin", gomock.Any()).Return(nil)
+	mockPolicyStore.EXPECT().List(gomock.Any(), "colin", gomock.Any()).Return(&v1.PolicyList{}, nil)
+	mockGroupStore.EXPECT().List(gomock.Any(), "", gomock.Any()).Return(&apiv1.GroupList{Items: []*apiv1.Group{
+		{ObjectMeta: metav1.ObjectMeta{Name: "group0"}, Username: "colin", Members: []string{"users:james"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "group1"}, Username: "james", Members: []string{"users:colin", "users:james"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "group2"}, Username: "james", Members: []string{"users:james"}},
+	}}, nil)
+	mockGroupStore.EXPECT().Delete(gomock.Any(), "colin", "group0", gomock.Any()).Return(nil)
+	mockGroupStore.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, group *apiv1.Group, _ metav1.UpdateOptions) error {
+			assert.Equal(t, "group1", group.Name)
+			assert.Equal(t, []string{"users:james"}, group.Members)
+
+			return nil
+		})
+	mockUserStore.EXPECT().Delete(gomock.Any(), "colin", gomock.Any()).Return(nil)
+	mockLoginRecordStore.EXPECT().DeleteByUser(gomock.Any(), "colin").Return(int64(3), nil)
+	mockAuditEventStore.EXPECT().Tombstone(gomock.Any(), "colin", PurgedActor).Return(int64(4), nil)
+
+	var progress []int
+	result, err := purgeUser(context.TODO(), mockFactory, &apiv1.Operation{
+		ObjectMeta: metav1.ObjectMeta{Name: "op-purge"},
+		Kind:       OperationPurgeUser,
+		Params:     metav1.Extend{"user": "colin"},
+	}, func(percent int) { progress = append(progress, percent) })
+
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.Extend{
+		"secrets":           1,
+		"policies":          0,
+		"policyAttachments": 2,
+		"groups":            1,
+		"groupMemberships":  1,
+		"policyAudits":      int64(2),
+		"loginRecords":      int64(3),
+		"auditEvents":       int64(4),
+		"sessions":          "revoked",
+		"analytics":         "erasure instructed",
+	}, result)
+	assert.Equal(t, []string{"colin"}, revoked)
+	assert.Equal(t, []string{"colin"}, audits.deleted)
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, instruction.KindEraseUser, sent[0].Kind)
+		assert.Equal(t, "colin", sent[0].Username)
+		assert.Equal(t, "op-purge", sent[0].Operation)
+	}
+	assert.Equal(t, []int{10, 25, 40, 60, 80}, progress)
+}
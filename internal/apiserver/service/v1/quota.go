@@ -0,0 +1,276 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// QuotaSrv defines functions used to handle tenant quota request.
+type QuotaSrv interface {
+	Get(ctx context.Context, tenant string, opts metav1.GetOptions) (*apiv1.Quota, error)
+	Update(ctx context.Context, quota *apiv1.Quota, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, tenant string, opts metav1.DeleteOptions) error
+}
+
+type quotaService struct {
+	store store.Factory
+}
+
+var _ QuotaSrv = (*quotaService)(nil)
+
+func newQuotas(srv *service) *quotaService {
+	return &quotaService{store: srv.store}
+}
+
+// Get returns the quota of the tenant along with its usage, a tenant without quota is
+// unlimited.
+func (s *quotaService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiv1.Quota, error) {
+	quota, err := getQuota(ctx, s.store, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if quota == nil {
+		quota = &apiv1.Quota{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	users, err := listTenantUsers(ctx, s.store, name)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := listTenantPolicies(ctx, s.store, name)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(-1)
+	secrets, err := s.store.Secrets().List(ctx, "", metav1.ListOptions{Limit: &limit})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	usage := &apiv1.QuotaUsage{Users: int64(len(users)), Policies: int64(len(policies))}
+
+	counts := make(map[string]int64, len(users))
+	for _, secret := range secrets.Items {
+		if !users[secret.Username] {
+			continue
+		}
+
+		counts[secret.Username]++
+		if counts[secret.Username] > usage.SecretsPerUser {
+			usage.SecretsPerUser = counts[secret.Username]
+		}
+	}
+
+	for _, pol := range policies {
+		if size := policySize(pol); size > usage.PolicySize {
+			usage.PolicySize = size
+		}
+	}
+
+	quota.Usage = usage
+
+	return quota, nil
+}
+
+// Update creates or updates the quota of the tenant.
+func (s *quotaService) Update(ctx context.Context, quota *apiv1.Quota, opts metav1.UpdateOptions) error {
+	current, err := getQuota(ctx, s.store, quota.Name)
+	if err != nil {
+		return err
+	}
+
+	quota.Usage = nil
+	if current == nil {
+		if err := s.store.Quotas().Create(ctx, quota, metav1.CreateOptions{}); err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return nil
+	}
+
+	quota.ID = current.ID
+	quota.InstanceID = current.InstanceID
+	quota.CreatedAt = current.CreatedAt
+	if err := s.store.Quotas().Update(ctx, quota, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (s *quotaService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if err := s.store.Quotas().Delete(ctx, name, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getQuota returns the quota of the tenant, nil if the tenant is unlimited.
+func getQuota(ctx context.Context, st store.Factory, name string) (*apiv1.Quota, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	quota, err := st.Quotas().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsCode(err, code.ErrQuotaNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return quota, nil
+}
+
+// listTenantUsers returns the names of the available users of the tenant.
+func listTenantUsers(ctx context.Context, st store.Factory, name string) (map[string]bool, error) {
+	limit := int64(-1)
+	users, err := st.Users().List(ctx, metav1.ListOptions{FieldSelector: "tenant=" + name, Limit: &limit})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	names := make(map[string]bool, len(users.Items))
+	for _, user := range users.Items {
+		names[user.Name] = true
+	}
+
+	return names, nil
+}
+
+// listTenantPolicies returns the policies of the tenant, which are the policies owned by its
+// users.
+func listTenantPolicies(ctx context.Context, st store.Factory, name string) ([]*v1.Policy, error) {
+	limit := int64(-1)
+	policies, err := st.Policies().List(ctx, "", metav1.ListOptions{FieldSelector: "ownerTenant=" + name, Limit: &limit})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return policies.Items, nil
+}
+
+// policySize returns the size of the json format of the ladon policy.
+func policySize(policy *v1.Policy) int64 {
+	return int64(len(policy.Policy.String()))
+}
+
+// checkUserQuota denies adding a user to the tenant once the tenant has reached the quota. The
+// quotas are checked before the writes, concurrent additions may exceed them slightly.
+func checkUserQuota(ctx context.Context, st store.Factory, name string) error {
+	quota, err := getQuota(ctx, st, name)
+	if err != nil || quota == nil || quota.MaxUsers == 0 {
+		return err
+	}
+
+	// the users are counted by the database, a single one is loaded
+	offset, limit := int64(0), int64(1)
+	users, err := st.Users().List(ctx, metav1.ListOptions{
+		FieldSelector: "tenant=" + name,
+		Offset:        &offset,
+		Limit:         &limit,
+	})
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if users.TotalCount >= quota.MaxUsers {
+		return errors.WithCode(code.ErrUserQuotaExceeded, "tenant %s has %d users", name, users.TotalCount)
+	}
+
+	return nil
+}
+
+// ownerTenant returns the tenant of the owner of a secret or a policy, the resources count
+// against the quota of the tenant of their owner whatever their own extend fields say.
+func ownerTenant(ctx context.Context, st store.Factory, username string) (string, error) {
+	owner, err := st.Users().Get(ctx, username, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return tenant.FromExtend(owner.Extend), nil
+}
+
+// checkSecretQuota denies the creation of a secret once its owner has reached the quota of
+// the tenant of the owner.
+func checkSecretQuota(ctx context.Context, st store.Factory, secret *v1.Secret) error {
+	name, err := ownerTenant(ctx, st, secret.Username)
+	if err != nil {
+		return err
+	}
+
+	quota, err := getQuota(ctx, st, name)
+	if err != nil || quota == nil || quota.MaxSecretsPerUser == 0 {
+		return err
+	}
+
+	// the secrets are counted by the database, a single one is loaded
+	offset, limit := int64(0), int64(1)
+	secrets, err := st.Secrets().List(ctx, secret.Username, metav1.ListOptions{Offset: &offset, Limit: &limit})
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if secrets.TotalCount >= quota.MaxSecretsPerUser {
+		return errors.WithCode(code.ErrSecretQuotaExceeded, "user %s has %d secrets", secret.Username, secrets.TotalCount)
+	}
+
+	return nil
+}
+
+// checkPolicyQuota denies the policies larger than the quota of the tenant of their owner, and
+// the creation of a policy once the tenant has reached the quota.
+func checkPolicyQuota(ctx context.Context, st store.Factory, policy *v1.Policy, create bool) error {
+	name, err := ownerTenant(ctx, st, policy.Username)
+	if err != nil {
+		return err
+	}
+
+	quota, err := getQuota(ctx, st, name)
+	if err != nil || quota == nil {
+		return err
+	}
+
+	if size := policySize(policy); quota.MaxPolicySize != 0 && size > quota.MaxPolicySize {
+		return errors.WithCode(code.ErrPolicySizeExceeded, "policy %s has %d bytes", policy.Name, size)
+	}
+
+	if !create || quota.MaxPolicies == 0 {
+		return nil
+	}
+
+	// the policies are counted by the database, a single one is loaded
+	offset, limit := int64(0), int64(1)
+	policies, err := st.Policies().List(ctx, "", metav1.ListOptions{
+		FieldSelector: "ownerTenant=" + name,
+		Offset:        &offset,
+		Limit:         &limit,
+	})
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if policies.TotalCount >= quota.MaxPolicies {
+		return errors.WithCode(code.ErrPolicyQuotaExceeded, "tenant %s has %d policies", name, policies.TotalCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_checkSecretQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockSecretStore := store.NewMockSecretStore(ctrl)
+	mockQuotaStore := store.NewMockQuotaStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockFactory.EXPECT().Secrets().AnyTimes().Return(mockSecretStore)
+	mockFactory.EXPECT().Quotas().AnyTimes().Return(mockQuotaStore)
+
+	mockUserStore.EXPECT().Get(gomock.Any(), "colin", gomock.Any()).AnyTimes().Return(&v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin", Extend: metav1.Extend{tenant.ExtendKey: "marmotedu"}},
+	}, nil)
+	mockQuotaStore.EXPECT().Get(gomock.Any(), "marmotedu", gomock.Any()).AnyTimes().Return(&apiv1.Quota{
+		ObjectMeta:        metav1.ObjectMeta{Name: "marmotedu"},
+		MaxSecretsPerUser: 2,
+	}, nil)
+
+	tests := []struct {
+		name     string
+		existing int64
+		wantCode int
+	}{
+		{name: "below quota", existing: 1},
+		{name: "quota reached", existing: 2, wantCode: code.ErrSecretQuotaExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSecretStore.EXPECT().List(gomock.Any(), "colin", gomock.Any()).Return(&v1.SecretList{
+				ListMeta: metav1.ListMeta{TotalCount: tt.existing},
+			}, nil)
+
+			err := checkSecretQuota(context.TODO(), mockFactory, &v1.Secret{Username: "colin"})
+			if tt.wantCode == 0 && err != nil {
+				t.Errorf("checkSecretQuota() error = %v", err)
+			}
+			if tt.wantCode != 0 && !errors.IsCode(err, tt.wantCode) {
+				t.Errorf("checkSecretQuota() error = %v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func Test_checkPolicyQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockPolicyStore := store.NewMockPolicyStore(ctrl)
+	mockQuotaStore := store.NewMockQuotaStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockFactory.EXPECT().Policies().AnyTimes().Return(mockPolicyStore)
+	mockFactory.EXPECT().Quotas().AnyTimes().Return(mockQuotaStore)
+
+	mockUserStore.EXPECT().Get(gomock.Any(), "colin", gomock.Any()).AnyTimes().Return(&v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin", Extend: metav1.Extend{tenant.ExtendKey: "marmotedu"}},
+	}, nil)
+	mockQuotaStore.EXPECT().Get(gomock.Any(), "marmotedu", gomock.Any()).AnyTimes().Return(&apiv1.Quota{
+		ObjectMeta:  metav1.ObjectMeta{Name: "marmotedu"},
+		MaxPolicies: 2,
+	}, nil)
+
+	tests := []struct {
+		name     string
+		existing int64
+		wantCode int
+	}{
+		{name: "below quota", existing: 1},
+		{name: "quota reached", existing: 2, wantCode: code.ErrPolicyQuotaExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPolicyStore.EXPECT().List(gomock.Any(), "", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, opts metav1.ListOptions) (*v1.PolicyList, error) {
+					if opts.FieldSelector != "ownerTenant=marmotedu" {
+						t.Errorf("checkPolicyQuota() selector = %s", opts.FieldSelector)
+					}
+
+					return &v1.PolicyList{ListMeta: metav1.ListMeta{TotalCount: tt.existing}}, nil
+				})
+
+			// the policy counts against the tenant of its owner, whatever its extend fields say
+			err := checkPolicyQuota(context.TODO(), mockFactory, &v1.Policy{Username: "colin"}, true)
+			if tt.wantCode == 0 && err != nil {
+				t.Errorf("checkPolicyQuota() error = %v", err)
+			}
+			if tt.wantCode != 0 && !errors.IsCode(err, tt.wantCode) {
+				t.Errorf("checkPolicyQuota() error = %v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -6,12 +6,14 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/admission"
 	"github.com/marmotedu/iam/internal/pkg/code"
 )
 
@@ -23,6 +25,7 @@ type SecretSrv interface {
 	DeleteCollection(ctx context.Context, username string, secretIDs []string, opts metav1.DeleteOptions) error
 	Get(ctx context.Context, username, secretID string, opts metav1.GetOptions) (*v1.Secret, error)
 	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.SecretList, error)
+	LastUsed(ctx context.Context, secretIDs []string) (map[string]time.Time, error)
 }
 
 type secretService struct {
@@ -36,6 +39,14 @@ func newSecrets(srv *service) *secretService {
 }
 
 func (s *secretService) Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOptions) error {
+	if err := admission.Admit(ctx, admission.KindSecret, admission.Create, secret); err != nil {
+		return err
+	}
+
+	if err := checkSecretQuota(ctx, s.store, secret); err != nil {
+		return err
+	}
+
 	if err := s.store.Secrets().Create(ctx, secret, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -44,8 +55,16 @@ func (s *secretService) Create(ctx context.Context, secret *v1.Secret, opts meta
 }
 
 func (s *secretService) Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOptions) error {
+	if err := admission.Admit(ctx, admission.KindSecret, admission.Update, secret); err != nil {
+		return err
+	}
+
 	// Save changed fields.
 	if err := s.store.Secrets().Update(ctx, secret, opts); err != nil {
+		if errors.IsCode(err, code.ErrConflict) {
+			return err
+		}
+
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
@@ -94,3 +113,18 @@ func (s *secretService) List(ctx context.Context, username string, opts metav1.L
 
 	return secrets, nil
 }
+
+// LastUsed returns the last time the given secrets were used, the secrets never used are left out.
+func (s *secretService) LastUsed(ctx context.Context, secretIDs []string) (map[string]time.Time, error) {
+	usages, err := s.store.SecretUsages().List(ctx, secretIDs)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	ret := make(map[string]time.Time, len(usages))
+	for _, usage := range usages {
+		ret[usage.SecretID] = usage.LastUsedAt
+	}
+
+	return ret, nil
+}
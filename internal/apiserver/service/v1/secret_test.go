@@ -17,6 +17,7 @@ import (
 )
 
 func (s *Suite) Test_secretService_Create() {
+	s.mockUserStore.EXPECT().Get(gomock.Any(), s.secrets[0].Username, gomock.Any()).Return(s.users[0], nil)
 	s.mockSecretStore.EXPECT().Create(gomock.Any(), gomock.Eq(s.secrets[0]), gomock.Any()).Return(nil)
 	type fields struct {
 		store store.Factory
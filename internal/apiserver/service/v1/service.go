@@ -4,7 +4,7 @@
 
 package v1
 
-//go:generate mockgen -self_package=github.com/marmotedu/iam/internal/apiserver/service/v1 -destination mock_service.go -package v1 github.com/marmotedu/iam/internal/apiserver/service/v1 Service,UserSrv,SecretSrv,PolicySrv
+//go:generate mockgen -self_package=github.com/marmotedu/iam/internal/apiserver/service/v1 -destination mock_service.go -package v1 github.com/marmotedu/iam/internal/apiserver/service/v1 Service,UserSrv,SecretSrv,PolicySrv,PolicyAttachmentSrv,LoginRecordSrv,GroupSrv,AuditEventSrv,CompletionSrv,QuotaSrv,OperationSrv,TenantSrv,SnapshotSrv,DelegationSrv,STSSrv
 
 import "github.com/marmotedu/iam/internal/apiserver/store"
 
@@ -13,6 +13,17 @@ type Service interface {
 	Users() UserSrv
 	Secrets() SecretSrv
 	Policies() PolicySrv
+	PolicyAttachments() PolicyAttachmentSrv
+	LoginRecords() LoginRecordSrv
+	Groups() GroupSrv
+	AuditEvents() AuditEventSrv
+	Completions() CompletionSrv
+	Quotas() QuotaSrv
+	Operations() OperationSrv
+	Tenants() TenantSrv
+	Snapshots() SnapshotSrv
+	Delegations() DelegationSrv
+	STS() STSSrv
 }
 
 type service struct {
@@ -37,3 +48,47 @@ func (s *service) Secrets() SecretSrv {
 func (s *service) Policies() PolicySrv {
 	return newPolicies(s)
 }
+
+func (s *service) PolicyAttachments() PolicyAttachmentSrv {
+	return newPolicyAttachments(s)
+}
+
+func (s *service) LoginRecords() LoginRecordSrv {
+	return newLoginRecords(s)
+}
+
+func (s *service) Groups() GroupSrv {
+	return newGroups(s)
+}
+
+func (s *service) AuditEvents() AuditEventSrv {
+	return newAuditEvents(s)
+}
+
+func (s *service) Completions() CompletionSrv {
+	return newCompletions(s)
+}
+
+func (s *service) Quotas() QuotaSrv {
+	return newQuotas(s)
+}
+
+func (s *service) Operations() OperationSrv {
+	return newOperations(s)
+}
+
+func (s *service) Tenants() TenantSrv {
+	return newTenants(s)
+}
+
+func (s *service) Snapshots() SnapshotSrv {
+	return newSnapshots(s)
+}
+
+func (s *service) Delegations() DelegationSrv {
+	return newDelegations(s)
+}
+
+func (s *service) STS() STSSrv {
+	return newSTS(s)
+}
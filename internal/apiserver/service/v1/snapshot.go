@@ -0,0 +1,298 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/snapshot"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// snapshotPageSize is the number of the resources read at once by an export.
+const snapshotPageSize = 500
+
+// SnapshotSrv defines functions used to export and import the snapshots of all resources.
+type SnapshotSrv interface {
+	Export(ctx context.Context, w *snapshot.Writer) error
+	Import(ctx context.Context, r *snapshot.Reader) (*apiv1.ImportResult, error)
+}
+
+type snapshotService struct {
+	store store.Factory
+}
+
+var _ SnapshotSrv = (*snapshotService)(nil)
+
+func newSnapshots(srv *service) *snapshotService {
+	return &snapshotService{store: srv.store}
+}
+
+// Export writes all resources to w, as they were at a single point in time when the store
+// supports it.
+func (s *snapshotService) Export(ctx context.Context, w *snapshot.Writer) error {
+	export := func(st store.Factory) error {
+		return exportSnapshot(pagination.WithTotalCount(ctx), st, w)
+	}
+
+	if snapshotter, ok := s.store.(store.Snapshotter); ok {
+		return snapshotter.Snapshot(ctx, export)
+	}
+
+	return export(s.store)
+}
+
+func exportSnapshot(ctx context.Context, st store.Factory, w *snapshot.Writer) error {
+	// the users which are not active are only listed when selected
+	for _, selector := range []string{"status=1", "status=2", "status=0"} {
+		selector := selector
+		if err := exportPages(func(opts metav1.ListOptions) (int, int64, error) {
+			opts.FieldSelector = selector
+			users, err := st.Users().List(ctx, opts)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, user := range users.Items {
+				if err := w.Write(snapshot.KindUser, user); err != nil {
+					return 0, 0, err
+				}
+			}
+
+			return len(users.Items), users.TotalCount, nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	pages := []func(opts metav1.ListOptions) (int, int64, error){
+		func(opts metav1.ListOptions) (int, int64, error) {
+			secrets, err := st.Secrets().List(ctx, "", opts)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, secret := range secrets.Items {
+				if err := w.Write(snapshot.KindSecret, secret); err != nil {
+					return 0, 0, err
+				}
+			}
+
+			return len(secrets.Items), secrets.TotalCount, nil
+		},
+		func(opts metav1.ListOptions) (int, int64, error) {
+			policies, err := st.Policies().List(ctx, "", opts)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, policy := range policies.Items {
+				if err := w.Write(snapshot.KindPolicy, policy); err != nil {
+					return 0, 0, err
+				}
+			}
+
+			return len(policies.Items), policies.TotalCount, nil
+		},
+		func(opts metav1.ListOptions) (int, int64, error) {
+			groups, err := st.Groups().List(ctx, "", opts)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, group := range groups.Items {
+				if err := w.Write(snapshot.KindGroup, group); err != nil {
+					return 0, 0, err
+				}
+			}
+
+			return len(groups.Items), groups.TotalCount, nil
+		},
+		func(opts metav1.ListOptions) (int, int64, error) {
+			attachments, err := st.PolicyAttachments().List(ctx, "", opts)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, attachment := range attachments.Items {
+				if err := w.Write(snapshot.KindPolicyAttachment, attachment); err != nil {
+					return 0, 0, err
+				}
+			}
+
+			return len(attachments.Items), attachments.TotalCount, nil
+		},
+		func(opts metav1.ListOptions) (int, int64, error) {
+			quotas, err := st.Quotas().List(ctx, opts)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, quota := range quotas.Items {
+				if err := w.Write(snapshot.KindQuota, quota); err != nil {
+					return 0, 0, err
+				}
+			}
+
+			return len(quotas.Items), quotas.TotalCount, nil
+		},
+	}
+
+	for _, page := range pages {
+		if err := exportPages(page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportPages calls page until all the resources have been read. page returns the number of
+// the resources of the page and the total number of resources, some stores do not page.
+func exportPages(page func(opts metav1.ListOptions) (int, int64, error)) error {
+	offset, limit := int64(0), int64(snapshotPageSize)
+	for {
+		count, total, err := page(metav1.ListOptions{Offset: &offset, Limit: &limit})
+		if err != nil {
+			return err
+		}
+
+		offset += int64(count)
+		if count == 0 || offset >= total {
+			return nil
+		}
+	}
+}
+
+// Import creates the resources of the snapshot which do not exist yet, the existing ones are
+// kept unchanged so that an interrupted import can be run again. The resources are created as
+// they were exported, neither the admission hooks nor the quotas are applied.
+func (s *snapshotService) Import(ctx context.Context, r *snapshot.Reader) (*apiv1.ImportResult, error) {
+	result := &apiv1.ImportResult{Created: map[string]int64{}, Skipped: map[string]int64{}}
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+
+		if err != nil {
+			return nil, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		created, err := s.importRecord(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+
+		if created {
+			result.Created[rec.Kind]++
+		} else {
+			result.Skipped[rec.Kind]++
+		}
+	}
+}
+
+func (s *snapshotService) importRecord(ctx context.Context, rec *snapshot.Record) (bool, error) {
+	switch rec.Kind {
+	case snapshot.KindUser:
+		var user v1.User
+		if err := json.Unmarshal(rec.Object, &user); err != nil {
+			return false, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		_, err := s.store.Users().Get(ctx, user.Name, metav1.GetOptions{})
+
+		return createMissing(err, code.ErrUserNotFound, &user.ObjectMeta, func() error {
+			return s.store.Users().Create(ctx, &user, metav1.CreateOptions{})
+		})
+	case snapshot.KindSecret:
+		var secret v1.Secret
+		if err := json.Unmarshal(rec.Object, &secret); err != nil {
+			return false, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		_, err := s.store.Secrets().Get(ctx, secret.Username, secret.Name, metav1.GetOptions{})
+
+		return createMissing(err, code.ErrSecretNotFound, &secret.ObjectMeta, func() error {
+			return s.store.Secrets().Create(ctx, &secret, metav1.CreateOptions{})
+		})
+	case snapshot.KindPolicy:
+		var policy v1.Policy
+		if err := json.Unmarshal(rec.Object, &policy); err != nil {
+			return false, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		_, err := s.store.Policies().Get(ctx, policy.Username, policy.Name, metav1.GetOptions{})
+
+		return createMissing(err, code.ErrPolicyNotFound, &policy.ObjectMeta, func() error {
+			return s.store.Policies().Create(ctx, &policy, metav1.CreateOptions{})
+		})
+	case snapshot.KindGroup:
+		var group apiv1.Group
+		if err := json.Unmarshal(rec.Object, &group); err != nil {
+			return false, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		_, err := s.store.Groups().Get(ctx, group.Username, group.Name, metav1.GetOptions{})
+
+		return createMissing(err, code.ErrGroupNotFound, &group.ObjectMeta, func() error {
+			return s.store.Groups().Create(ctx, &group, metav1.CreateOptions{})
+		})
+	case snapshot.KindPolicyAttachment:
+		var attachment apiv1.PolicyAttachment
+		if err := json.Unmarshal(rec.Object, &attachment); err != nil {
+			return false, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		_, err := s.store.PolicyAttachments().Get(ctx, attachment.Username, attachment.PolicyName,
+			attachment.Subject, metav1.GetOptions{})
+
+		return createMissing(err, code.ErrAttachmentNotFound, &attachment.ObjectMeta, func() error {
+			return s.store.PolicyAttachments().Create(ctx, &attachment, metav1.CreateOptions{})
+		})
+	case snapshot.KindQuota:
+		var quota apiv1.Quota
+		if err := json.Unmarshal(rec.Object, &quota); err != nil {
+			return false, errors.WithCode(code.ErrInvalidSnapshot, err.Error())
+		}
+
+		_, err := s.store.Quotas().Get(ctx, quota.Name, metav1.GetOptions{})
+
+		return createMissing(err, code.ErrQuotaNotFound, &quota.ObjectMeta, func() error {
+			return s.store.Quotas().Create(ctx, &quota, metav1.CreateOptions{})
+		})
+	default:
+		return false, errors.WithCode(code.ErrInvalidSnapshot, "unknown kind %s", rec.Kind)
+	}
+}
+
+// createMissing creates a resource unless getErr, returned by getting it, reports that it
+// exists. The ids are assigned again by the store.
+func createMissing(getErr error, notFound int, meta *metav1.ObjectMeta, create func() error) (bool, error) {
+	if getErr == nil {
+		return false, nil
+	}
+
+	if !errors.IsCode(getErr, notFound) {
+		return false, errors.WithCode(code.ErrDatabase, getErr.Error())
+	}
+
+	meta.ID = 0
+	meta.InstanceID = ""
+	if err := create(); err != nil {
+		return false, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return true, nil
+}
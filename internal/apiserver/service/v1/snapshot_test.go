@@ -0,0 +1,89 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/snapshot"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_snapshotService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockSecretStore := store.NewMockSecretStore(ctrl)
+	mockPolicyStore := store.NewMockPolicyStore(ctrl)
+	mockGroupStore := store.NewMockGroupStore(ctrl)
+	mockAttachmentStore := store.NewMockPolicyAttachmentStore(ctrl)
+	mockQuotaStore := store.NewMockQuotaStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockFactory.EXPECT().Secrets().AnyTimes().Return(mockSecretStore)
+	mockFactory.EXPECT().Policies().AnyTimes().Return(mockPolicyStore)
+	mockFactory.EXPECT().Groups().AnyTimes().Return(mockGroupStore)
+	mockFactory.EXPECT().PolicyAttachments().AnyTimes().Return(mockAttachmentStore)
+	mockFactory.EXPECT().Quotas().AnyTimes().Return(mockQuotaStore)
+
+	user := &v1.User{ObjectMeta: metav1.ObjectMeta{ID: 1, Name: "colin"}, Password: "hash", Status: 1}
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{ID: 2, InstanceID: "secret-a", Name: "secret0"}, Username: "colin"}
+
+	// export
+	mockUserStore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+			if opts.FieldSelector == "status=1" {
+				return &v1.UserList{ListMeta: metav1.ListMeta{TotalCount: 1}, Items: []*v1.User{user}}, nil
+			}
+
+			return &v1.UserList{}, nil
+		}).Times(3)
+	mockSecretStore.EXPECT().List(gomock.Any(), "", gomock.Any()).Return(
+		&v1.SecretList{ListMeta: metav1.ListMeta{TotalCount: 1}, Items: []*v1.Secret{secret}}, nil)
+	mockPolicyStore.EXPECT().List(gomock.Any(), "", gomock.Any()).Return(&v1.PolicyList{}, nil)
+	mockGroupStore.EXPECT().List(gomock.Any(), "", gomock.Any()).Return(&apiv1.GroupList{}, nil)
+	mockAttachmentStore.EXPECT().List(gomock.Any(), "", gomock.Any()).Return(&apiv1.PolicyAttachmentList{}, nil)
+	mockQuotaStore.EXPECT().List(gomock.Any(), gomock.Any()).Return(&apiv1.QuotaList{}, nil)
+
+	srv := &snapshotService{store: mockFactory}
+
+	var buf bytes.Buffer
+	w, err := snapshot.NewWriter(&buf, "", true)
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Export(context.TODO(), w))
+	assert.NoError(t, w.Close())
+
+	// import, the user exists and the secret is created
+	mockUserStore.EXPECT().Get(gomock.Any(), "colin", gomock.Any()).Return(user, nil)
+	mockSecretStore.EXPECT().Get(gomock.Any(), "colin", "secret0", gomock.Any()).
+		Return(nil, errors.WithCode(code.ErrSecretNotFound, "record not found"))
+	mockSecretStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, created *v1.Secret, opts metav1.CreateOptions) error {
+			assert.Equal(t, "secret0", created.Name)
+			assert.Zero(t, created.ID)
+			assert.Empty(t, created.InstanceID)
+
+			return nil
+		})
+
+	r, err := snapshot.NewReader(&buf, "")
+	assert.NoError(t, err)
+
+	result, err := srv.Import(context.TODO(), r)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{snapshot.KindSecret: 1}, result.Created)
+	assert.Equal(t, map[string]int64{snapshot.KindUser: 1}, result.Skipped)
+}
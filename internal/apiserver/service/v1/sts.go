@@ -0,0 +1,95 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// STSSrv defines functions used to issue temporary credentials.
+type STSSrv interface {
+	// AssumeRole issues a secret of the user which expires after the given duration and only
+	// authorizes what the session policies allow.
+	AssumeRole(ctx context.Context, username string, sess *session.Session, duration time.Duration) (*v1.Secret, error)
+}
+
+type stsService struct {
+	store store.Factory
+}
+
+var _ STSSrv = (*stsService)(nil)
+
+func newSTS(srv *service) *stsService {
+	return &stsService{store: srv.store}
+}
+
+// AssumeRole issues a secret of the user which expires after the given duration and only
+// authorizes what the session policies allow. The expired temporary secrets of the user are
+// deleted first, so that they do not count against the secret quota.
+func (s *stsService) AssumeRole(
+	ctx context.Context,
+	username string,
+	sess *session.Session,
+	duration time.Duration,
+) (*v1.Secret, error) {
+	if duration < session.MinDuration || duration > session.MaxDuration {
+		return nil, errors.WithCode(code.ErrValidation, "duration must be between %s and %s",
+			session.MinDuration, session.MaxDuration)
+	}
+
+	s.purgeExpired(ctx, username)
+
+	secretID := idutil.NewSecretID()
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "sts-" + secretID,
+			Extend: metav1.Extend{session.ExtendKey: sess},
+		},
+		Username:    username,
+		SecretID:    secretID,
+		SecretKey:   idutil.NewSecretKey(),
+		Expires:     time.Now().Add(duration).Unix(),
+		Description: fmt.Sprintf("temporary credentials of session %s", sess.Name),
+	}
+
+	if err := (&secretService{store: s.store}).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// purgeExpired deletes the expired temporary secrets of the user, on a best effort basis.
+func (s *stsService) purgeExpired(ctx context.Context, username string) {
+	secrets, err := s.store.Secrets().List(ctx, username, metav1.ListOptions{})
+	if err != nil {
+		log.L(ctx).Warnf("list secrets of user %s failed: %s", username, err.Error())
+
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, secret := range secrets.Items {
+		if _, ok := secret.Extend[session.ExtendKey]; !ok || secret.Expires == 0 || secret.Expires > now {
+			continue
+		}
+
+		if err := s.store.Secrets().Delete(ctx, username, secret.Name, metav1.DeleteOptions{}); err != nil {
+			log.L(ctx).Warnf("delete expired secret %s failed: %s", secret.Name, err.Error())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/operation"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// OperationDeleteTenant is the kind of the operations deleting a tenant.
+const OperationDeleteTenant = "DeleteTenant"
+
+func init() {
+	operation.Register(OperationDeleteTenant, deleteTenant)
+}
+
+// TenantSrv defines functions used to handle tenant request.
+type TenantSrv interface {
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) (*apiv1.Operation, error)
+}
+
+type tenantService struct {
+	store store.Factory
+}
+
+var _ TenantSrv = (*tenantService)(nil)
+
+func newTenants(srv *service) *tenantService {
+	return &tenantService{store: srv.store}
+}
+
+// Delete submits an operation deleting the users of the tenant along with their secrets and
+// policies, and the quota of the tenant.
+func (s *tenantService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) (*apiv1.Operation, error) {
+	return operation.Submit(ctx, OperationDeleteTenant, metav1.Extend{"tenant": name, "unscoped": opts.Unscoped})
+}
+
+func deleteTenant(
+	ctx context.Context,
+	st store.Factory,
+	op *apiv1.Operation,
+	progress func(percent int),
+) (metav1.Extend, error) {
+	name, _ := op.Params["tenant"].(string)
+	unscoped, _ := op.Params["unscoped"].(bool)
+	opts := metav1.DeleteOptions{Unscoped: unscoped}
+
+	users, err := listTenantUsers(ctx, st, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted struct{ users, secrets, policies int }
+	for username := range users {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		secrets, err := deleteSecretsOf(ctx, st, username, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		policies, err := deletePoliciesOf(ctx, st, username, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := st.Users().Delete(ctx, username, opts); err != nil {
+			return nil, err
+		}
+
+		deleted.users++
+		deleted.secrets += secrets
+		deleted.policies += policies
+		progress(deleted.users * 100 / len(users))
+	}
+
+	if err := st.Quotas().Delete(ctx, name, opts); err != nil {
+		return nil, err
+	}
+
+	return metav1.Extend{"users": deleted.users, "secrets": deleted.secrets, "policies": deleted.policies}, nil
+}
+
+// deleteSecretsOf deletes the secrets of the user and returns their number.
+func deleteSecretsOf(ctx context.Context, st store.Factory, username string, opts metav1.DeleteOptions) (int, error) {
+	limit := int64(-1)
+	secrets, err := st.Secrets().List(ctx, username, metav1.ListOptions{Limit: &limit})
+	if err != nil || len(secrets.Items) == 0 {
+		return 0, err
+	}
+
+	names := make([]string, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		names = append(names, secret.Name)
+	}
+
+	if err := st.Secrets().DeleteCollection(ctx, username, names, opts); err != nil {
+		return 0, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return len(names), nil
+}
+
+// deletePoliciesOf deletes the policies of the user and returns their number.
+func deletePoliciesOf(ctx context.Context, st store.Factory, username string, opts metav1.DeleteOptions) (int, error) {
+	limit := int64(-1)
+	policies, err := st.Policies().List(ctx, username, metav1.ListOptions{Limit: &limit})
+	if err != nil || len(policies.Items) == 0 {
+		return 0, err
+	}
+
+	names := make([]string, 0, len(policies.Items))
+	for _, pol := range policies.Items {
+		names = append(names, pol.Name)
+	}
+
+	if err := st.Policies().DeleteCollection(ctx, username, names, opts); err != nil {
+		return 0, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return len(names), nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_deleteTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockSecretStore := store.NewMockSecretStore(ctrl)
+	mockPolicyStore := store.NewMockPolicyStore(ctrl)
+	mockQuotaStore := store.NewMockQuotaStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockFactory.EXPECT().Secrets().AnyTimes().Return(mockSecretStore)
+	mockFactory.EXPECT().Policies().AnyTimes().Return(mockPolicyStore)
+	mockFactory.EXPECT().Quotas().AnyTimes().Return(mockQuotaStore)
+
+	mockUserStore.EXPECT().List(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+			assert.Equal(t, "tenant=marmotedu", opts.FieldSelector)
+
+			return &v1.UserList{Items: []*v1.User{
+				{ObjectMeta: metav1.ObjectMeta{Name: "colin", Extend: metav1.Extend{tenant.ExtendKey: "marmotedu"}}},
+			}}, nil
+		})
+	mockSecretStore.EXPECT().List(gomock.Any(), "colin", gomock.Any()).Return(&v1.SecretList{Items: []*v1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "secret0"}, Username: "colin"},
+	}}, nil)
+	mockSecretStore.EXPECT().DeleteCollection(gomock.Any(), "colin", []string{"secret0"}, gomock.Any()).Return(nil)
+	mockPolicyStore.EXPECT().List(gomock.Any(), "colin", gomock.Any()).Return(&v1.PolicyList{}, nil)
+	mockUserStore.EXPECT().Delete(gomock.Any(), "colin", gomock.Any()).Return(nil)
+	mockQuotaStore.EXPECT().Delete(gomock.Any(), "marmotedu", gomock.Any()).Return(nil)
+
+	var progress []int
+	result, err := deleteTenant(context.TODO(), mockFactory, &apiv1.Operation{
+		Kind:   OperationDeleteTenant,
+		Params: metav1.Extend{"tenant": "marmotedu"},
+	}, func(percent int) { progress = append(progress, percent) })
+
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.Extend{"users": 1, "secrets": 1, "policies": 0}, result)
+	assert.Equal(t, []int{100}, progress)
+}
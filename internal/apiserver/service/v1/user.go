@@ -14,7 +14,13 @@ import (
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/admission"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -28,6 +34,8 @@ type UserSrv interface {
 	List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
 	ListWithBadPerformance(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
 	ChangePassword(ctx context.Context, user *v1.User) error
+	Transition(ctx context.Context, username string, action userstate.Action) (*v1.User, error)
+	Purge(ctx context.Context, username string) (*apiv1.Operation, error)
 }
 
 type userService struct {
@@ -54,6 +62,7 @@ func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.Us
 	finished := make(chan bool, 1)
 
 	var m sync.Map
+	onePolicy := int64(1)
 
 	// Improve query efficiency in parallel
 	for _, user := range users.Items {
@@ -62,8 +71,8 @@ func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.Us
 		go func(user *v1.User) {
 			defer wg.Done()
 
-			// some cost time process
-			policies, err := u.store.Policies().List(ctx, user.Name, metav1.ListOptions{})
+			// only the total count is used, it is counted by the database along with a single policy
+			policies, err := u.store.Policies().List(pagination.WithTotalCount(ctx), user.Name, metav1.ListOptions{Limit: &onePolicy})
 			if err != nil {
 				errChan <- errors.WithCode(code.ErrDatabase, err.Error())
 
@@ -119,7 +128,7 @@ func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.Li
 
 	infos := make([]*v1.User, 0)
 	for _, user := range users.Items {
-		policies, err := u.store.Policies().List(ctx, user.Name, metav1.ListOptions{})
+		policies, err := u.store.Policies().List(pagination.WithTotalCount(ctx), user.Name, metav1.ListOptions{})
 		if err != nil {
 			return nil, errors.WithCode(code.ErrDatabase, err.Error())
 		}
@@ -142,6 +151,15 @@ func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.Li
 }
 
 func (u *userService) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
+	if err := admission.Admit(ctx, admission.KindUser, admission.Create, user); err != nil {
+		return err
+	}
+
+	// the creators can not set the tenant, only the admission webhooks do
+	if err := checkUserQuota(ctx, u.store, tenant.FromExtend(user.Extend)); err != nil {
+		return err
+	}
+
 	if err := u.store.Users().Create(ctx, user, opts); err != nil {
 		if match, _ := regexp.MatchString("Duplicate entry '.*' for key 'idx_name'", err.Error()); match {
 			return errors.WithCode(code.ErrUserAlreadyExist, err.Error())
@@ -179,7 +197,27 @@ func (u *userService) Get(ctx context.Context, username string, opts metav1.GetO
 }
 
 func (u *userService) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
+	if err := admission.Admit(ctx, admission.KindUser, admission.Update, user); err != nil {
+		return err
+	}
+
+	// the quota of a tenant is checked when a user joins it
+	stored, err := u.store.Users().Get(ctx, user.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if name := tenant.FromExtend(user.Extend); name != tenant.FromExtend(stored.Extend) {
+		if err := checkUserQuota(ctx, u.store, name); err != nil {
+			return err
+		}
+	}
+
 	if err := u.store.Users().Update(ctx, user, opts); err != nil {
+		if errors.IsCode(err, code.ErrConflict) {
+			return err
+		}
+
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
@@ -187,10 +225,47 @@ func (u *userService) Update(ctx context.Context, user *v1.User, opts metav1.Upd
 }
 
 func (u *userService) ChangePassword(ctx context.Context, user *v1.User) error {
+	if err := admission.Admit(ctx, admission.KindUser, admission.Update, user); err != nil {
+		return err
+	}
+
 	// Save changed fields.
 	if err := u.store.Users().Update(ctx, user, metav1.UpdateOptions{}); err != nil {
+		if errors.IsCode(err, code.ErrConflict) {
+			return err
+		}
+
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
 	return nil
 }
+
+// Transition moves the user to the state the action leads to, and returns the updated user.
+func (u *userService) Transition(ctx context.Context, username string, action userstate.Action) (*v1.User, error) {
+	user, err := u.store.Users().Get(ctx, username, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	from := userstate.Of(user)
+	to, err := userstate.Transition(from, action)
+	if err != nil || to == from {
+		return user, err
+	}
+
+	user.Status = int(to)
+	if err := u.Update(ctx, user, metav1.UpdateOptions{}); err != nil {
+		return nil, err
+	}
+
+	log.L(ctx).Infow("user state changed", "username", username, "from", from.String(), "to", to.String())
+
+	if to == userstate.Suspended {
+		notifier.Notify(ctx, notifier.Lockout, notifier.RecipientOf(user), map[string]interface{}{
+			"reason": "the account is suspended by an administrator",
+		})
+	}
+
+	return user, nil
+}
@@ -399,6 +399,7 @@ func (s *Suite) Test_userService_Get() {
 }
 
 func (s *Suite) Test_userService_Update() {
+	s.mockUserStore.EXPECT().Get(gomock.Any(), s.users[0].Name, gomock.Any()).Return(s.users[0], nil)
 	s.mockUserStore.EXPECT().Update(gomock.Any(), s.users[0], gomock.Any()).Return(nil)
 
 	type fields struct {
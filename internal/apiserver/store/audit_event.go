@@ -0,0 +1,20 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// AuditEventStore defines the audit_event storage interface.
+type AuditEventStore interface {
+	Create(ctx context.Context, event *v1.AuditEvent, opts metav1.CreateOptions) error
+	List(ctx context.Context, opts v1.AuditEventListOptions) (*v1.AuditEventList, error)
+	Tombstone(ctx context.Context, username, tombstone string) (int64, error)
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+)
+
+// CompletionStore defines the storage interface used to complete the names of the resources.
+type CompletionStore interface {
+	// Names returns the names of the resources of the kind owned by the user, all the users
+	// when the kind is user, which start with the prefix, in alphabetical order.
+	Names(ctx context.Context, kind, username, prefix string, limit int) ([]string, error)
+}
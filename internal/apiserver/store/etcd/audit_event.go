@@ -0,0 +1,107 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type auditEvents struct {
+	ds *datastore
+}
+
+func newAuditEvents(ds *datastore) *auditEvents {
+	return &auditEvents{ds: ds}
+}
+
+// the keys are ordered by the creation time of the events, which is also their id, so that
+// they are listed newest first.
+var keyAuditEvent = "/audit_events/%020d"
+
+// Create records an audit event.
+func (a *auditEvents) Create(ctx context.Context, event *v1.AuditEvent, opts metav1.CreateOptions) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	event.ID = uint64(event.CreatedAt.UnixNano())
+
+	return a.ds.Put(ctx, fmt.Sprintf(keyAuditEvent, event.ID), jsonutil.ToString(event))
+}
+
+// List return the audit events, newest first, which can be filtered by `username`, `verb`,
+// `resource` and `resourceName` field selectors.
+func (a *auditEvents) List(ctx context.Context, opts v1.AuditEventListOptions) (*v1.AuditEventList, error) {
+	kvs, err := a.ds.List(ctx, "/audit_events/")
+	if err != nil {
+		return nil, err
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+
+	ret := &v1.AuditEventList{}
+	for _, v := range kvs {
+		var event v1.AuditEvent
+		if err := json.Unmarshal(v.Value, &event); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to AuditEvent struct failed")
+		}
+
+		if selector != nil && !selector.Matches(fields.Set{
+			"username":     event.Username,
+			"verb":         event.Verb,
+			"resource":     event.Resource,
+			"resourceName": event.ResourceName,
+		}) {
+			continue
+		}
+
+		if (opts.Since != nil && event.CreatedAt.Before(*opts.Since)) || event.ID <= opts.AfterID {
+			continue
+		}
+
+		ret.Items = append(ret.Items, &event)
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
+
+// Tombstone replaces the actor fields of the events of a user, its name, ip and user agent,
+// by the tombstone, and returns the number of events.
+func (a *auditEvents) Tombstone(ctx context.Context, username, tombstone string) (int64, error) {
+	kvs, err := a.ds.List(ctx, "/audit_events/")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, v := range kvs {
+		var event v1.AuditEvent
+		if err := json.Unmarshal(v.Value, &event); err != nil {
+			return count, errors.Wrap(err, "unmarshal to AuditEvent struct failed")
+		}
+
+		if event.Username != username {
+			continue
+		}
+
+		event.Username, event.IP, event.UserAgent = tombstone, "", ""
+		if err := a.ds.Put(ctx, v.Key, jsonutil.ToString(event)); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
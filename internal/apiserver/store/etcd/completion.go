@@ -0,0 +1,63 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type completions struct {
+	ds *datastore
+}
+
+func newCompletions(ds *datastore) *completions {
+	return &completions{ds: ds}
+}
+
+// Names returns the names of the resources of the kind owned by the user, all the users when
+// the kind is user, which start with the prefix, in alphabetical order. The names are the last
+// segment of the keys, so that the prefix is matched by etcd.
+func (c *completions) Names(ctx context.Context, kind, username, prefix string, limit int) ([]string, error) {
+	var dir string
+	switch kind {
+	case v1.CompletionKindUser:
+		dir = "/users/"
+	case v1.CompletionKindSecret:
+		dir = fmt.Sprintf("/secrets/%v/", username)
+	case v1.CompletionKindPolicy:
+		dir = fmt.Sprintf("/policies/%v/", username)
+	case v1.CompletionKindGroup:
+		dir = fmt.Sprintf("/groups/%v/", username)
+	default:
+		return nil, fmt.Errorf("unsupported completion kind %q", kind)
+	}
+
+	kvs, err := c.ds.List(ctx, dir+prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		name := strings.TrimPrefix(kv.Key, dir)
+		// the keys below the ones of the resources are not resources
+		if name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	if limit >= 0 && limit < len(names) {
+		names = names[:limit]
+	}
+
+	return names, nil
+}
@@ -17,6 +17,8 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
 	"github.com/marmotedu/iam/pkg/log"
 )
@@ -57,6 +59,10 @@ func (ds *datastore) Secrets() store.SecretStore {
 	return newSecrets(ds)
 }
 
+func (ds *datastore) SecretUsages() store.SecretUsageStore {
+	return newSecretUsages(ds)
+}
+
 func (ds *datastore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
@@ -65,6 +71,34 @@ func (ds *datastore) PolicyAudits() store.PolicyAuditStore {
 	return newPolicyAudits(ds)
 }
 
+func (ds *datastore) PolicyAttachments() store.PolicyAttachmentStore {
+	return newPolicyAttachments(ds)
+}
+
+func (ds *datastore) Groups() store.GroupStore {
+	return newGroups(ds)
+}
+
+func (ds *datastore) LoginRecords() store.LoginRecordStore {
+	return newLoginRecords(ds)
+}
+
+func (ds *datastore) AuditEvents() store.AuditEventStore {
+	return newAuditEvents(ds)
+}
+
+func (ds *datastore) Completions() store.CompletionStore {
+	return newCompletions(ds)
+}
+
+func (ds *datastore) Quotas() store.QuotaStore {
+	return newQuotas(ds)
+}
+
+func (ds *datastore) Operations() store.OperationStore {
+	return newOperations(ds)
+}
+
 // Close clsoe the etcdStore clinet.
 func (ds *datastore) Close() error {
 	if ds.cli != nil {
@@ -216,7 +250,7 @@ func (ds *datastore) PutSession(ctx context.Context, key string, val string) err
 }
 
 func (ds *datastore) grantLease(ctx context.Context, ttlSeconds int64) (*clientv3.LeaseGrantResponse, error) {
-	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
 	defer cancel()
 	resp, err := ds.cli.Grant(nctx, ttlSeconds)
 	if err != nil {
@@ -232,7 +266,7 @@ func (ds *datastore) PutWithLease(ctx context.Context, key string, val string, t
 		return fmt.Errorf("put with grant lease: %w", err)
 	}
 
-	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
 	defer cancel()
 
 	key = ds.getKey(key)
@@ -248,7 +282,7 @@ func (ds *datastore) PutWithLease(ctx context.Context, key string, val string, t
 }
 
 func (ds *datastore) put(ctx context.Context, key string, val string, session bool) error {
-	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
 	defer cancel()
 
 	key = ds.getKey(key)
@@ -268,7 +302,7 @@ func (ds *datastore) put(ctx context.Context, key string, val string, session bo
 }
 
 func (ds *datastore) Get(ctx context.Context, key string) ([]byte, error) {
-	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
 	defer cancel()
 
 	key = ds.getKey(key)
@@ -284,6 +318,41 @@ func (ds *datastore) Get(ctx context.Context, key string) ([]byte, error) {
 	return resp.Kvs[0].Value, nil
 }
 
+// Update puts the value returned by fn for the current value of the key, only if the key was not
+// modified in the meantime. The update is rejected with ErrConflict otherwise.
+func (ds *datastore) Update(ctx context.Context, key string, fn func(current []byte) (string, error)) error {
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
+	defer cancel()
+
+	key = ds.getKey(key)
+
+	resp, err := ds.cli.Get(nctx, key)
+	if err != nil {
+		return errors.Wrap(err, "get key from etcd failed")
+	}
+	if len(resp.Kvs) == 0 {
+		return fmt.Errorf("no such key")
+	}
+
+	val, err := fn(resp.Kvs[0].Value)
+	if err != nil {
+		return err
+	}
+
+	tresp, err := ds.cli.Txn(nctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)).
+		Then(clientv3.OpPut(key, val)).
+		Commit()
+	if err != nil {
+		return errors.Wrap(err, "put key-value pair to etcd failed")
+	}
+	if !tresp.Succeeded {
+		return errors.WithCode(code.ErrConflict, "the key was modified by another request")
+	}
+
+	return nil
+}
+
 // EtcdKeyValue defines etcd returned key-value pairs.
 type EtcdKeyValue struct {
 	Key   string
@@ -291,7 +360,7 @@ type EtcdKeyValue struct {
 }
 
 func (ds *datastore) List(ctx context.Context, prefix string) ([]EtcdKeyValue, error) {
-	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
 	defer cancel()
 
 	prefix = ds.getKey(prefix)
@@ -377,7 +446,7 @@ func (ds *datastore) Unwatch(prefix string) {
 }
 
 func (ds *datastore) Delete(ctx context.Context, key string) ([]byte, error) {
-	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	nctx, cancel := context.WithTimeout(middleware.RequestContext(ctx), ds.requestTimeout)
 	defer cancel()
 
 	key = ds.getKey(key)
@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type groups struct {
+	ds *datastore
+}
+
+func newGroups(ds *datastore) *groups {
+	return &groups{ds: ds}
+}
+
+var keyGroup = "/groups/%v/%v"
+
+func (g *groups) getKey(username, name string) string {
+	return fmt.Sprintf(keyGroup, username, name)
+}
+
+// Create creates a new group.
+func (g *groups) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error {
+	return g.ds.Put(ctx, g.getKey(group.Username, group.Name), jsonutil.ToString(group))
+}
+
+// Update updates a group and its members.
+func (g *groups) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error {
+	return g.ds.Put(ctx, g.getKey(group.Username, group.Name), jsonutil.ToString(group))
+}
+
+// Delete deletes the group by the group identifier.
+func (g *groups) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	if _, err := g.ds.Delete(ctx, g.getKey(username, name)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get return group by the group identifier.
+func (g *groups) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Group, error) {
+	resp, err := g.ds.Get(ctx, g.getKey(username, name))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrGroupNotFound, err.Error())
+	}
+
+	var group v1.Group
+	if err := json.Unmarshal(resp, &group); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to Group struct failed")
+	}
+
+	return &group, nil
+}
+
+// List return all groups, which can be filtered by `name` and `kind` field selectors.
+func (g *groups) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.GroupList, error) {
+	prefix := "/groups/"
+	if username != "" {
+		prefix = fmt.Sprintf("/groups/%v/", username)
+	}
+
+	kvs, err := g.ds.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, filterName := selector.RequiresExactMatch("name")
+	kind, filterKind := selector.RequiresExactMatch("kind")
+
+	ret := &v1.GroupList{}
+	for _, v := range kvs {
+		var group v1.Group
+		if err := json.Unmarshal(v.Value, &group); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to Group struct failed")
+		}
+
+		if (filterName && group.Name != name) || (filterKind && group.Kind != kind) {
+			continue
+		}
+
+		ret.Items = append(ret.Items, &group)
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
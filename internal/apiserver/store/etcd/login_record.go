@@ -0,0 +1,95 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type loginRecords struct {
+	ds *datastore
+}
+
+func newLoginRecords(ds *datastore) *loginRecords {
+	return &loginRecords{ds: ds}
+}
+
+var keyLoginRecord = "/login_records/%v/%v"
+
+func (l *loginRecords) getKey(username string, at time.Time) string {
+	return fmt.Sprintf(keyLoginRecord, username, at.UnixNano())
+}
+
+// Create records a login attempt.
+func (l *loginRecords) Create(ctx context.Context, record *v1.LoginRecord, opts metav1.CreateOptions) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	return l.ds.Put(ctx, l.getKey(record.Username, record.CreatedAt), jsonutil.ToString(record))
+}
+
+// List return the login records of a user, which can be filtered by `success`, `method` and `ip` field selectors.
+func (l *loginRecords) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.LoginRecordList, error) {
+	kvs, err := l.ds.List(ctx, fmt.Sprintf("/login_records/%v/", username))
+	if err != nil {
+		return nil, err
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	success, filterSuccess := selector.RequiresExactMatch("success")
+	method, filterMethod := selector.RequiresExactMatch("method")
+	ip, filterIP := selector.RequiresExactMatch("ip")
+
+	ret := &v1.LoginRecordList{}
+	for _, v := range kvs {
+		var record v1.LoginRecord
+		if err := json.Unmarshal(v.Value, &record); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to LoginRecord struct failed")
+		}
+
+		if filterSuccess && strconv.FormatBool(record.Success) != success {
+			continue
+		}
+
+		if (filterMethod && record.Method != method) || (filterIP && record.IP != ip) {
+			continue
+		}
+
+		ret.Items = append(ret.Items, &record)
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
+
+// DeleteByUser deletes the login records of a user and returns their number.
+func (l *loginRecords) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	kvs, err := l.ds.List(ctx, fmt.Sprintf("/login_records/%v/", username))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, v := range kvs {
+		if _, err := l.ds.Delete(ctx, v.Key); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
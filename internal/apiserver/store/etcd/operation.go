@@ -0,0 +1,93 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type operations struct {
+	ds *datastore
+}
+
+func newOperations(ds *datastore) *operations {
+	return &operations{ds: ds}
+}
+
+var keyOperation = "/operations/%v"
+
+func (o *operations) getKey(name string) string {
+	return fmt.Sprintf(keyOperation, name)
+}
+
+// Create creates a new operation.
+func (o *operations) Create(ctx context.Context, op *v1.Operation, opts metav1.CreateOptions) error {
+	return o.ds.Put(ctx, o.getKey(op.Name), jsonutil.ToString(op))
+}
+
+// Update updates the status, the progress and the result of an operation.
+func (o *operations) Update(ctx context.Context, op *v1.Operation, opts metav1.UpdateOptions) error {
+	return o.ds.Put(ctx, o.getKey(op.Name), jsonutil.ToString(op))
+}
+
+// Get return an operation by its name.
+func (o *operations) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Operation, error) {
+	resp, err := o.ds.Get(ctx, o.getKey(name))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrOperationNotFound, err.Error())
+	}
+
+	var op v1.Operation
+	if err := json.Unmarshal(resp, &op); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to Operation struct failed")
+	}
+
+	return &op, nil
+}
+
+// List return all operations, which can be filtered by `kind`, `username` and `status` field
+// selectors.
+func (o *operations) List(ctx context.Context, opts metav1.ListOptions) (*v1.OperationList, error) {
+	kvs, err := o.ds.List(ctx, "/operations/")
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &v1.OperationList{}
+	for _, v := range kvs {
+		var op v1.Operation
+		if err := json.Unmarshal(v.Value, &op); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to Operation struct failed")
+		}
+
+		if !selector.Matches(operationFields(&op)) {
+			continue
+		}
+
+		ret.Items = append(ret.Items, &op)
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
+
+func operationFields(op *v1.Operation) fields.Set {
+	return fields.Set{"kind": op.Kind, "username": op.Username, "status": op.Status}
+}
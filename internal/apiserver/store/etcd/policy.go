@@ -9,10 +9,13 @@ import (
 	"fmt"
 
 	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/fields"
 	"github.com/marmotedu/component-base/pkg/json"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/component-base/pkg/util/jsonutil"
 	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
 )
 
 type policies struct {
@@ -31,12 +34,27 @@ func (p *policies) getKey(username string, name string) string {
 
 // Create creates a new policy.
 func (p *policies) Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOptions) error {
+	policy.Extend = resourceversion.Init(policy.Extend)
+
 	return p.ds.Put(ctx, p.getKey(policy.Username, policy.Name), jsonutil.ToString(policy))
 }
 
 // Update updates an policy information.
 func (p *policies) Update(ctx context.Context, policy *v1.Policy, opts metav1.UpdateOptions) error {
-	return p.ds.Put(ctx, p.getKey(policy.Username, policy.Name), jsonutil.ToString(policy))
+	return p.ds.Update(ctx, p.getKey(policy.Username, policy.Name), func(current []byte) (string, error) {
+		var stored v1.Policy
+		if err := json.Unmarshal(current, &stored); err != nil {
+			return "", errors.Wrap(err, "unmarshal to Policy struct failed")
+		}
+
+		extend, err := resourceversion.Next(stored.Extend, policy.Extend)
+		if err != nil {
+			return "", err
+		}
+		policy.Extend = extend
+
+		return jsonutil.ToString(policy), nil
+	})
 }
 
 // Delete deletes the policy by the policy identifier.
@@ -94,20 +112,35 @@ func (p *policies) List(ctx context.Context, username string, opts metav1.ListOp
 		return nil, err
 	}
 
-	ret := &v1.PolicyList{
-		ListMeta: metav1.ListMeta{
-			TotalCount: int64(len(kvs)),
-		},
+	// the policies of a tenant are the policies owned by its users
+	var owners map[string]bool
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	if ownerTenant, found := selector.RequiresExactMatch("ownerTenant"); found {
+		users, err := newUsers(p.ds).List(ctx, metav1.ListOptions{FieldSelector: "tenant=" + ownerTenant})
+		if err != nil {
+			return nil, err
+		}
+
+		owners = make(map[string]bool, len(users.Items))
+		for _, user := range users.Items {
+			owners[user.Name] = true
+		}
 	}
 
+	ret := &v1.PolicyList{}
 	for _, v := range kvs {
 		var policy v1.Policy
 		if err := json.Unmarshal(v.Value, &policy); err != nil {
 			return nil, errors.Wrap(err, "unmarshal to Policy struct failed")
 		}
 
+		if owners != nil && !owners[policy.Username] {
+			continue
+		}
+
 		ret.Items = append(ret.Items, &policy)
 	}
+	ret.TotalCount = int64(len(ret.Items))
 
 	return ret, nil
 }
@@ -0,0 +1,109 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type policyAttachments struct {
+	ds *datastore
+}
+
+func newPolicyAttachments(ds *datastore) *policyAttachments {
+	return &policyAttachments{ds: ds}
+}
+
+var keyPolicyAttachment = "/policy_attachments/%v/%v/%v"
+
+func (p *policyAttachments) getKey(username, policyName, subject string) string {
+	return fmt.Sprintf(keyPolicyAttachment, username, policyName, subject)
+}
+
+// Create attaches a policy to a subject.
+func (p *policyAttachments) Create(ctx context.Context, attachment *v1.PolicyAttachment, opts metav1.CreateOptions) error {
+	return p.ds.Put(ctx, p.getKey(attachment.Username, attachment.PolicyName, attachment.Subject),
+		jsonutil.ToString(attachment))
+}
+
+// Delete detaches a policy from a subject.
+func (p *policyAttachments) Delete(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.DeleteOptions,
+) error {
+	if _, err := p.ds.Delete(ctx, p.getKey(username, policyName, subject)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get return the attachment of a policy to a subject.
+func (p *policyAttachments) Get(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.GetOptions,
+) (*v1.PolicyAttachment, error) {
+	resp, err := p.ds.Get(ctx, p.getKey(username, policyName, subject))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrAttachmentNotFound, err.Error())
+	}
+
+	var attachment v1.PolicyAttachment
+	if err := json.Unmarshal(resp, &attachment); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to PolicyAttachment struct failed")
+	}
+
+	return &attachment, nil
+}
+
+// List return all attachments, which can be filtered by `policyName` and `subject` field selectors.
+func (p *policyAttachments) List(
+	ctx context.Context,
+	username string,
+	opts metav1.ListOptions,
+) (*v1.PolicyAttachmentList, error) {
+	prefix := "/policy_attachments/"
+	if username != "" {
+		prefix = fmt.Sprintf("/policy_attachments/%v/", username)
+	}
+
+	kvs, err := p.ds.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	policyName, filterPolicy := selector.RequiresExactMatch("policyName")
+	subject, filterSubject := selector.RequiresExactMatch("subject")
+
+	ret := &v1.PolicyAttachmentList{}
+	for _, v := range kvs {
+		var attachment v1.PolicyAttachment
+		if err := json.Unmarshal(v.Value, &attachment); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to PolicyAttachment struct failed")
+		}
+
+		if (filterPolicy && attachment.PolicyName != policyName) || (filterSubject && attachment.Subject != subject) {
+			continue
+		}
+
+		ret.Items = append(ret.Items, &attachment)
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type quotas struct {
+	ds *datastore
+}
+
+func newQuotas(ds *datastore) *quotas {
+	return &quotas{ds: ds}
+}
+
+var keyQuota = "/quotas/%v"
+
+func (q *quotas) getKey(tenant string) string {
+	return fmt.Sprintf(keyQuota, tenant)
+}
+
+// Create creates the quota of a tenant.
+func (q *quotas) Create(ctx context.Context, quota *v1.Quota, opts metav1.CreateOptions) error {
+	return q.ds.Put(ctx, q.getKey(quota.Name), jsonutil.ToString(quota))
+}
+
+// Update updates the quota of a tenant.
+func (q *quotas) Update(ctx context.Context, quota *v1.Quota, opts metav1.UpdateOptions) error {
+	return q.ds.Put(ctx, q.getKey(quota.Name), jsonutil.ToString(quota))
+}
+
+// Delete deletes the quota of a tenant.
+func (q *quotas) Delete(ctx context.Context, tenant string, opts metav1.DeleteOptions) error {
+	if _, err := q.ds.Delete(ctx, q.getKey(tenant)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get return the quota of a tenant.
+func (q *quotas) Get(ctx context.Context, tenant string, opts metav1.GetOptions) (*v1.Quota, error) {
+	resp, err := q.ds.Get(ctx, q.getKey(tenant))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrQuotaNotFound, err.Error())
+	}
+
+	var quota v1.Quota
+	if err := json.Unmarshal(resp, &quota); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to Quota struct failed")
+	}
+
+	return &quota, nil
+}
+
+// List return the quotas of all tenants.
+func (q *quotas) List(ctx context.Context, opts metav1.ListOptions) (*v1.QuotaList, error) {
+	kvs, err := q.ds.List(ctx, "/quotas/")
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &v1.QuotaList{}
+	for _, v := range kvs {
+		var quota v1.Quota
+		if err := json.Unmarshal(v.Value, &quota); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to Quota struct failed")
+		}
+
+		ret.Items = append(ret.Items, &quota)
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
@@ -13,6 +13,8 @@ import (
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/component-base/pkg/util/jsonutil"
 	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
 )
 
 type secrets struct {
@@ -31,12 +33,27 @@ func (s *secrets) getKey(username string, secretID string) string {
 
 // Create creates a new secret.
 func (s *secrets) Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOptions) error {
+	secret.Extend = resourceversion.Init(secret.Extend)
+
 	return s.ds.Put(ctx, s.getKey(secret.Username, secret.SecretID), jsonutil.ToString(secret))
 }
 
 // Update updates an secret information.
 func (s *secrets) Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOptions) error {
-	return s.ds.Put(ctx, s.getKey(secret.Username, secret.SecretID), jsonutil.ToString(secret))
+	return s.ds.Update(ctx, s.getKey(secret.Username, secret.SecretID), func(current []byte) (string, error) {
+		var stored v1.Secret
+		if err := json.Unmarshal(current, &stored); err != nil {
+			return "", errors.Wrap(err, "unmarshal to Secret struct failed")
+		}
+
+		extend, err := resourceversion.Next(stored.Extend, secret.Extend)
+		if err != nil {
+			return "", err
+		}
+		secret.Extend = extend
+
+		return jsonutil.ToString(secret), nil
+	})
 }
 
 // Delete deletes the secret by the secret identifier.
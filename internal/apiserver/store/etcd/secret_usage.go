@@ -0,0 +1,104 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/util/jsonutil"
+	"github.com/marmotedu/errors"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type secretUsages struct {
+	ds *datastore
+}
+
+func newSecretUsages(ds *datastore) *secretUsages {
+	return &secretUsages{ds: ds}
+}
+
+var keySecretUsage = "/secret_usages/%v"
+
+func (s *secretUsages) getKey(secretID string) string {
+	return fmt.Sprintf(keySecretUsage, secretID)
+}
+
+func (s *secretUsages) get(ctx context.Context, secretID string) (*v1.SecretUsage, bool) {
+	data, err := s.ds.Get(ctx, s.getKey(secretID))
+	if err != nil {
+		return nil, false
+	}
+
+	var usage v1.SecretUsage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, false
+	}
+
+	return &usage, true
+}
+
+// Record records the usages, keeping the later of the recorded and the given times.
+func (s *secretUsages) Record(ctx context.Context, usages []*v1.SecretUsage) error {
+	for _, usage := range usages {
+		if recorded, ok := s.get(ctx, usage.SecretID); ok && !recorded.LastUsedAt.Before(usage.LastUsedAt) {
+			continue
+		}
+
+		if err := s.ds.Put(ctx, s.getKey(usage.SecretID), jsonutil.ToString(usage)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns the usages of the given secrets, the secrets never used are left out.
+func (s *secretUsages) List(ctx context.Context, secretIDs []string) ([]*v1.SecretUsage, error) {
+	ret := make([]*v1.SecretUsage, 0, len(secretIDs))
+	for _, secretID := range secretIDs {
+		if usage, ok := s.get(ctx, secretID); ok {
+			ret = append(ret, usage)
+		}
+	}
+
+	return ret, nil
+}
+
+// Prune deletes the usages of the deleted secrets.
+func (s *secretUsages) Prune(ctx context.Context) (int64, error) {
+	secrets, err := s.ds.List(ctx, "/secrets/")
+	if err != nil {
+		return 0, err
+	}
+
+	exists := make(map[string]bool, len(secrets))
+	for _, kv := range secrets {
+		exists[path.Base(kv.Key)] = true
+	}
+
+	kvs, err := s.ds.List(ctx, "/secret_usages/")
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, kv := range kvs {
+		if exists[path.Base(kv.Key)] {
+			continue
+		}
+
+		if _, err := s.ds.Delete(ctx, kv.Key); err != nil {
+			return deleted, errors.Wrap(err, "delete secret usage failed")
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
@@ -9,10 +9,14 @@ import (
 	"fmt"
 
 	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/fields"
 	"github.com/marmotedu/component-base/pkg/json"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/component-base/pkg/util/jsonutil"
 	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
 )
 
 type users struct {
@@ -31,12 +35,27 @@ func (u *users) getKey(name string) string {
 
 // Create creates a new user account.
 func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
+	user.Extend = resourceversion.Init(user.Extend)
+
 	return u.ds.Put(ctx, u.getKey(user.Name), jsonutil.ToString(user))
 }
 
 // Update updates an user account information.
 func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
-	return u.ds.Put(ctx, u.getKey(user.Name), jsonutil.ToString(user))
+	return u.ds.Update(ctx, u.getKey(user.Name), func(current []byte) (string, error) {
+		var stored v1.User
+		if err := json.Unmarshal(current, &stored); err != nil {
+			return "", errors.Wrap(err, "unmarshal to User struct failed")
+		}
+
+		extend, err := resourceversion.Next(stored.Extend, user.Extend)
+		if err != nil {
+			return "", err
+		}
+		user.Extend = extend
+
+		return jsonutil.ToString(user), nil
+	})
 }
 
 // Delete deletes the user by the user identifier.
@@ -84,20 +103,23 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 		return nil, err
 	}
 
-	ret := &v1.UserList{
-		ListMeta: metav1.ListMeta{
-			TotalCount: int64(len(kvs)),
-		},
-	}
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	tenantName, byTenant := selector.RequiresExactMatch("tenant")
 
+	ret := &v1.UserList{}
 	for _, v := range kvs {
 		var user v1.User
 		if err := json.Unmarshal(v.Value, &user); err != nil {
 			return nil, errors.Wrap(err, "unmarshal to User struct failed")
 		}
 
+		if byTenant && tenant.FromExtend(user.Extend) != tenantName {
+			continue
+		}
+
 		ret.Items = append(ret.Items, &user)
 	}
+	ret.TotalCount = int64(len(ret.Items))
 
 	return ret, nil
 }
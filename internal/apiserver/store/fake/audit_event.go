@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type auditEvents struct {
+	ds *datastore
+}
+
+func newAuditEvents(ds *datastore) *auditEvents {
+	return &auditEvents{ds}
+}
+
+// Create records an audit event.
+func (a *auditEvents) Create(ctx context.Context, event *v1.AuditEvent, opts metav1.CreateOptions) error {
+	a.ds.Lock()
+	defer a.ds.Unlock()
+
+	event.ID = 1
+	if len(a.ds.audits) > 0 {
+		event.ID = a.ds.audits[len(a.ds.audits)-1].ID + 1
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	a.ds.audits = append(a.ds.audits, event)
+
+	return nil
+}
+
+// List return the audit events, newest first, which can be filtered by `username`, `verb`,
+// `resource` and `resourceName` field selectors.
+func (a *auditEvents) List(ctx context.Context, opts v1.AuditEventListOptions) (*v1.AuditEventList, error) {
+	a.ds.RLock()
+	defer a.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+
+	events := make([]*v1.AuditEvent, 0)
+	for i := len(a.ds.audits) - 1; i >= 0; i-- {
+		e := a.ds.audits[i]
+		if !matchAuditEvent(e, selector) {
+			continue
+		}
+
+		if (opts.Since != nil && e.CreatedAt.Before(*opts.Since)) || e.ID <= opts.AfterID {
+			continue
+		}
+
+		events = append(events, e)
+	}
+
+	total := int64(len(events))
+	if ol.Offset < len(events) {
+		events = events[ol.Offset:]
+	} else {
+		events = events[:0]
+	}
+
+	if ol.Limit >= 0 && ol.Limit < len(events) {
+		events = events[:ol.Limit]
+	}
+
+	return &v1.AuditEventList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: total,
+		},
+		Items: events,
+	}, nil
+}
+
+func matchAuditEvent(e *v1.AuditEvent, selector fields.Selector) bool {
+	if selector == nil {
+		return true
+	}
+
+	return selector.Matches(fields.Set{
+		"username":     e.Username,
+		"verb":         e.Verb,
+		"resource":     e.Resource,
+		"resourceName": e.ResourceName,
+	})
+}
+
+// Tombstone replaces the actor fields of the events of a user, its name, ip and user agent,
+// by the tombstone, and returns the number of events.
+func (a *auditEvents) Tombstone(ctx context.Context, username, tombstone string) (int64, error) {
+	a.ds.Lock()
+	defer a.ds.Unlock()
+
+	var count int64
+	for _, e := range a.ds.audits {
+		if e.Username == username {
+			e.Username, e.IP, e.UserAgent = tombstone, "", ""
+			count++
+		}
+	}
+
+	return count, nil
+}
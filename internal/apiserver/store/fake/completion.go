@@ -0,0 +1,64 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type completions struct {
+	ds *datastore
+}
+
+func newCompletions(ds *datastore) *completions {
+	return &completions{ds}
+}
+
+// Names returns the names of the resources of the kind owned by the user, all the users when
+// the kind is user, which start with the prefix, in alphabetical order.
+func (c *completions) Names(ctx context.Context, kind, username, prefix string, limit int) ([]string, error) {
+	c.ds.RLock()
+	defer c.ds.RUnlock()
+
+	names := make([]string, 0)
+	add := func(owner, name string) {
+		if (kind == v1.CompletionKindUser || owner == username) && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	switch kind {
+	case v1.CompletionKindUser:
+		for _, u := range c.ds.users {
+			add("", u.Name)
+		}
+	case v1.CompletionKindSecret:
+		for _, s := range c.ds.secrets {
+			add(s.Username, s.Name)
+		}
+	case v1.CompletionKindPolicy:
+		for _, p := range c.ds.policies {
+			add(p.Username, p.Name)
+		}
+	case v1.CompletionKindGroup:
+		for _, g := range c.ds.groups {
+			add(g.Username, g.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported completion kind %q", kind)
+	}
+
+	sort.Strings(names)
+	if limit >= 0 && limit < len(names) {
+		names = names[:limit]
+	}
+
+	return names, nil
+}
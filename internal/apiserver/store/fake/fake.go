@@ -14,6 +14,7 @@ import (
 	"github.com/ory/ladon"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 )
 
 // ResourceCount defines the number of fake resources.
@@ -24,6 +25,14 @@ type datastore struct {
 	users    []*v1.User
 	secrets  []*v1.Secret
 	policies []*v1.Policy
+
+	attachments []*apiv1.PolicyAttachment
+	logins      []*apiv1.LoginRecord
+	audits      []*apiv1.AuditEvent
+	groups      []*apiv1.Group
+	quotas      []*apiv1.Quota
+	operations  []*apiv1.Operation
+	usages      map[string]*apiv1.SecretUsage
 }
 
 func (ds *datastore) Users() store.UserStore {
@@ -34,6 +43,10 @@ func (ds *datastore) Secrets() store.SecretStore {
 	return newSecrets(ds)
 }
 
+func (ds *datastore) SecretUsages() store.SecretUsageStore {
+	return newSecretUsages(ds)
+}
+
 func (ds *datastore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
@@ -42,6 +55,34 @@ func (ds *datastore) PolicyAudits() store.PolicyAuditStore {
 	return newPolicyAudits(ds)
 }
 
+func (ds *datastore) PolicyAttachments() store.PolicyAttachmentStore {
+	return newPolicyAttachments(ds)
+}
+
+func (ds *datastore) Groups() store.GroupStore {
+	return newGroups(ds)
+}
+
+func (ds *datastore) LoginRecords() store.LoginRecordStore {
+	return newLoginRecords(ds)
+}
+
+func (ds *datastore) AuditEvents() store.AuditEventStore {
+	return newAuditEvents(ds)
+}
+
+func (ds *datastore) Completions() store.CompletionStore {
+	return newCompletions(ds)
+}
+
+func (ds *datastore) Quotas() store.QuotaStore {
+	return newQuotas(ds)
+}
+
+func (ds *datastore) Operations() store.OperationStore {
+	return newOperations(ds)
+}
+
 func (ds *datastore) Close() error {
 	return nil
 }
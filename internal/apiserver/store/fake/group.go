@@ -0,0 +1,134 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type groups struct {
+	ds *datastore
+}
+
+func newGroups(ds *datastore) *groups {
+	return &groups{ds}
+}
+
+// Create creates a new group.
+func (g *groups) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error {
+	g.ds.Lock()
+	defer g.ds.Unlock()
+
+	for _, grp := range g.ds.groups {
+		if grp.Username == group.Username && grp.Name == group.Name {
+			return errors.New("record already exist")
+		}
+	}
+
+	if len(g.ds.groups) > 0 {
+		group.ID = g.ds.groups[len(g.ds.groups)-1].ID + 1
+	}
+	g.ds.groups = append(g.ds.groups, group)
+
+	return nil
+}
+
+// Update updates a group and its members.
+func (g *groups) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error {
+	g.ds.Lock()
+	defer g.ds.Unlock()
+
+	for i, grp := range g.ds.groups {
+		if grp.Username == group.Username && grp.Name == group.Name {
+			g.ds.groups[i] = group
+
+			return nil
+		}
+	}
+
+	return errors.WithCode(code.ErrGroupNotFound, "record not found")
+}
+
+// Delete deletes the group by the group identifier.
+func (g *groups) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	g.ds.Lock()
+	defer g.ds.Unlock()
+
+	groups := g.ds.groups
+	g.ds.groups = make([]*v1.Group, 0)
+	for _, grp := range groups {
+		if grp.Username == username && grp.Name == name {
+			continue
+		}
+
+		g.ds.groups = append(g.ds.groups, grp)
+	}
+
+	return nil
+}
+
+// Get return group by the group identifier.
+func (g *groups) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Group, error) {
+	g.ds.RLock()
+	defer g.ds.RUnlock()
+
+	for _, grp := range g.ds.groups {
+		if grp.Username == username && grp.Name == name {
+			return grp, nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrGroupNotFound, "record not found")
+}
+
+// List return all groups, which can be filtered by `name` and `kind` field selectors.
+func (g *groups) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.GroupList, error) {
+	g.ds.RLock()
+	defer g.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, filterName := selector.RequiresExactMatch("name")
+	kind, filterKind := selector.RequiresExactMatch("kind")
+
+	groups := make([]*v1.Group, 0)
+	for _, grp := range g.ds.groups {
+		if username != "" && grp.Username != username {
+			continue
+		}
+
+		if (filterName && grp.Name != name) || (filterKind && grp.Kind != kind) {
+			continue
+		}
+
+		groups = append(groups, grp)
+	}
+
+	total := int64(len(groups))
+	if ol.Offset < len(groups) {
+		groups = groups[ol.Offset:]
+	} else {
+		groups = groups[:0]
+	}
+
+	if ol.Limit >= 0 && ol.Limit < len(groups) {
+		groups = groups[:ol.Limit]
+	}
+
+	return &v1.GroupList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: total,
+		},
+		Items: groups,
+	}, nil
+}
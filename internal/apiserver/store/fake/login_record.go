@@ -0,0 +1,104 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type loginRecords struct {
+	ds *datastore
+}
+
+func newLoginRecords(ds *datastore) *loginRecords {
+	return &loginRecords{ds}
+}
+
+// Create records a login attempt.
+func (l *loginRecords) Create(ctx context.Context, record *v1.LoginRecord, opts metav1.CreateOptions) error {
+	l.ds.Lock()
+	defer l.ds.Unlock()
+
+	if len(l.ds.logins) > 0 {
+		record.ID = l.ds.logins[len(l.ds.logins)-1].ID + 1
+	}
+	l.ds.logins = append(l.ds.logins, record)
+
+	return nil
+}
+
+// List return the login records of a user, which can be filtered by `success`, `method` and `ip` field selectors.
+func (l *loginRecords) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.LoginRecordList, error) {
+	l.ds.RLock()
+	defer l.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	success, filterSuccess := selector.RequiresExactMatch("success")
+	method, filterMethod := selector.RequiresExactMatch("method")
+	ip, filterIP := selector.RequiresExactMatch("ip")
+
+	records := make([]*v1.LoginRecord, 0)
+	// newest first
+	for i := len(l.ds.logins) - 1; i >= 0; i-- {
+		r := l.ds.logins[i]
+		if r.Username != username {
+			continue
+		}
+
+		if filterSuccess && strconv.FormatBool(r.Success) != success {
+			continue
+		}
+
+		if (filterMethod && r.Method != method) || (filterIP && r.IP != ip) {
+			continue
+		}
+
+		records = append(records, r)
+	}
+
+	total := int64(len(records))
+	if ol.Offset < len(records) {
+		records = records[ol.Offset:]
+	} else {
+		records = records[:0]
+	}
+
+	if ol.Limit >= 0 && ol.Limit < len(records) {
+		records = records[:ol.Limit]
+	}
+
+	return &v1.LoginRecordList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: total,
+		},
+		Items: records,
+	}, nil
+}
+
+// DeleteByUser deletes the login records of a user and returns their number.
+func (l *loginRecords) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	l.ds.Lock()
+	defer l.ds.Unlock()
+
+	kept := l.ds.logins[:0]
+	for _, r := range l.ds.logins {
+		if r.Username != username {
+			kept = append(kept, r)
+		}
+	}
+
+	count := int64(len(l.ds.logins) - len(kept))
+	l.ds.logins = kept
+
+	return count, nil
+}
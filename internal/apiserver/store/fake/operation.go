@@ -0,0 +1,103 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type operations struct {
+	ds *datastore
+}
+
+func newOperations(ds *datastore) *operations {
+	return &operations{ds}
+}
+
+// Create creates a new operation.
+func (o *operations) Create(ctx context.Context, op *v1.Operation, opts metav1.CreateOptions) error {
+	o.ds.Lock()
+	defer o.ds.Unlock()
+
+	for _, item := range o.ds.operations {
+		if item.Name == op.Name {
+			return errors.New("record already exist")
+		}
+	}
+
+	if len(o.ds.operations) > 0 {
+		op.ID = o.ds.operations[len(o.ds.operations)-1].ID + 1
+	}
+	o.ds.operations = append(o.ds.operations, copyOperation(op))
+
+	return nil
+}
+
+// Update updates the status, the progress and the result of an operation.
+func (o *operations) Update(ctx context.Context, op *v1.Operation, opts metav1.UpdateOptions) error {
+	o.ds.Lock()
+	defer o.ds.Unlock()
+
+	for i, item := range o.ds.operations {
+		if item.Name == op.Name {
+			o.ds.operations[i] = copyOperation(op)
+
+			return nil
+		}
+	}
+
+	return errors.WithCode(code.ErrOperationNotFound, "record not found")
+}
+
+// Get return an operation by its name.
+func (o *operations) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Operation, error) {
+	o.ds.RLock()
+	defer o.ds.RUnlock()
+
+	for _, item := range o.ds.operations {
+		if item.Name == name {
+			return copyOperation(item), nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrOperationNotFound, "record not found")
+}
+
+// List return the operations, oldest first, which can be filtered by `kind`, `username` and
+// `status` field selectors.
+func (o *operations) List(ctx context.Context, opts metav1.ListOptions) (*v1.OperationList, error) {
+	o.ds.RLock()
+	defer o.ds.RUnlock()
+
+	selector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &v1.OperationList{Items: make([]*v1.Operation, 0)}
+	for _, item := range o.ds.operations {
+		if selector.Matches(fields.Set{"kind": item.Kind, "username": item.Username, "status": item.Status}) {
+			ret.Items = append(ret.Items, copyOperation(item))
+		}
+	}
+	ret.TotalCount = int64(len(ret.Items))
+
+	return ret, nil
+}
+
+// copyOperation copies the operation, so that the workers updating it do not race with the
+// readers, as they would with a database.
+func copyOperation(op *v1.Operation) *v1.Operation {
+	ret := *op
+
+	return &ret
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
 	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 	reflectutil "github.com/marmotedu/iam/internal/pkg/util/reflect"
 )
@@ -42,6 +44,7 @@ func (p *policies) Create(ctx context.Context, policy *v1.Policy, opts metav1.Cr
 	if len(p.ds.policies) > 0 {
 		policy.ID = p.ds.policies[len(p.ds.policies)-1].ID + 1
 	}
+	policy.Extend = resourceversion.Init(policy.Extend)
 	p.ds.policies = append(p.ds.policies, policy)
 
 	return nil
@@ -54,6 +57,12 @@ func (p *policies) Update(ctx context.Context, policy *v1.Policy, opts metav1.Up
 
 	for _, pol := range p.ds.policies {
 		if pol.Username == policy.Username && pol.Name == policy.Name {
+			extend, err := resourceversion.Next(pol.Extend, policy.Extend)
+			if err != nil {
+				return err
+			}
+			policy.Extend = extend
+
 			if _, err := reflectutil.CopyObj(policy, pol, nil); err != nil {
 				return errors.Wrap(err, "copy policy failed")
 			}
@@ -161,24 +170,38 @@ func (p *policies) List(ctx context.Context, username string, opts metav1.ListOp
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	name, _ := selector.RequiresExactMatch("name")
+	ownerTenant, byTenant := selector.RequiresExactMatch("ownerTenant")
 
+	// the policies of a tenant are the policies owned by its users
+	owners := make(map[string]bool)
+	for _, user := range p.ds.users {
+		owners[user.Name] = tenant.FromExtend(user.Extend) == ownerTenant
+	}
+
+	// like the other lists of the fake store, the total counts all the policies unless they are
+	// selected by tenant
 	policies := make([]*v1.Policy, 0)
-	i := 0
+	total, matched := len(p.ds.policies), 0
 	for _, pol := range p.ds.policies {
-		if i == ol.Limit {
-			break
+		if username != "" && pol.Username != username {
+			continue
 		}
 
-		if pol.Username != username {
+		if !strings.Contains(pol.Name, name) {
 			continue
 		}
 
-		if !strings.Contains(pol.Name, name) {
+		if byTenant && !owners[pol.Username] {
 			continue
 		}
 
-		policies = append(policies, pol)
-		i++
+		matched++
+		if len(policies) != ol.Limit {
+			policies = append(policies, pol)
+		}
+	}
+	if byTenant {
+		total = matched
 	}
 
 	// Simulate database query latency, sleep 2 millisecond
@@ -186,7 +209,7 @@ func (p *policies) List(ctx context.Context, username string, opts metav1.ListOp
 
 	return &v1.PolicyList{
 		ListMeta: metav1.ListMeta{
-			TotalCount: int64(len(p.ds.policies)),
+			TotalCount: int64(total),
 		},
 		Items: policies,
 	}, nil
@@ -0,0 +1,131 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type policyAttachments struct {
+	ds *datastore
+}
+
+func newPolicyAttachments(ds *datastore) *policyAttachments {
+	return &policyAttachments{ds}
+}
+
+// Create attaches a policy to a subject.
+func (p *policyAttachments) Create(ctx context.Context, attachment *v1.PolicyAttachment, opts metav1.CreateOptions) error {
+	p.ds.Lock()
+	defer p.ds.Unlock()
+
+	for _, a := range p.ds.attachments {
+		if a.Username == attachment.Username && a.PolicyName == attachment.PolicyName &&
+			a.Subject == attachment.Subject {
+			return errors.New("record already exist")
+		}
+	}
+
+	if len(p.ds.attachments) > 0 {
+		attachment.ID = p.ds.attachments[len(p.ds.attachments)-1].ID + 1
+	}
+	p.ds.attachments = append(p.ds.attachments, attachment)
+
+	return nil
+}
+
+// Delete detaches a policy from a subject.
+func (p *policyAttachments) Delete(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.DeleteOptions,
+) error {
+	p.ds.Lock()
+	defer p.ds.Unlock()
+
+	attachments := p.ds.attachments
+	p.ds.attachments = make([]*v1.PolicyAttachment, 0)
+	for _, a := range attachments {
+		if a.Username == username && a.PolicyName == policyName && a.Subject == subject {
+			continue
+		}
+
+		p.ds.attachments = append(p.ds.attachments, a)
+	}
+
+	return nil
+}
+
+// Get return the attachment of a policy to a subject.
+func (p *policyAttachments) Get(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.GetOptions,
+) (*v1.PolicyAttachment, error) {
+	p.ds.RLock()
+	defer p.ds.RUnlock()
+
+	for _, a := range p.ds.attachments {
+		if a.Username == username && a.PolicyName == policyName && a.Subject == subject {
+			return a, nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrAttachmentNotFound, "record not found")
+}
+
+// List return all attachments, which can be filtered by `policyName` and `subject` field selectors.
+func (p *policyAttachments) List(
+	ctx context.Context,
+	username string,
+	opts metav1.ListOptions,
+) (*v1.PolicyAttachmentList, error) {
+	p.ds.RLock()
+	defer p.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	policyName, filterPolicy := selector.RequiresExactMatch("policyName")
+	subject, filterSubject := selector.RequiresExactMatch("subject")
+
+	attachments := make([]*v1.PolicyAttachment, 0)
+	for _, a := range p.ds.attachments {
+		if username != "" && a.Username != username {
+			continue
+		}
+
+		if (filterPolicy && a.PolicyName != policyName) || (filterSubject && a.Subject != subject) {
+			continue
+		}
+
+		attachments = append(attachments, a)
+	}
+
+	total := int64(len(attachments))
+	if ol.Offset < len(attachments) {
+		attachments = attachments[ol.Offset:]
+	} else {
+		attachments = attachments[:0]
+	}
+
+	if ol.Limit >= 0 && ol.Limit < len(attachments) {
+		attachments = attachments[:ol.Limit]
+	}
+
+	return &v1.PolicyAttachmentList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: total,
+		},
+		Items: attachments,
+	}, nil
+}
@@ -20,3 +20,7 @@ func newPolicyAudits(ds *datastore) *policyAudit {
 func (p *policyAudit) ClearOutdated(ctx context.Context, maxReserveDays int) (int64, error) {
 	return 0, nil
 }
+
+func (p *policyAudit) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	return 0, nil
+}
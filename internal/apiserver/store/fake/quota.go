@@ -0,0 +1,117 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type quotas struct {
+	ds *datastore
+}
+
+func newQuotas(ds *datastore) *quotas {
+	return &quotas{ds}
+}
+
+// Create creates the quota of a tenant.
+func (q *quotas) Create(ctx context.Context, quota *v1.Quota, opts metav1.CreateOptions) error {
+	q.ds.Lock()
+	defer q.ds.Unlock()
+
+	for _, quo := range q.ds.quotas {
+		if quo.Name == quota.Name {
+			return errors.New("record already exist")
+		}
+	}
+
+	if len(q.ds.quotas) > 0 {
+		quota.ID = q.ds.quotas[len(q.ds.quotas)-1].ID + 1
+	}
+	q.ds.quotas = append(q.ds.quotas, quota)
+
+	return nil
+}
+
+// Update updates the quota of a tenant.
+func (q *quotas) Update(ctx context.Context, quota *v1.Quota, opts metav1.UpdateOptions) error {
+	q.ds.Lock()
+	defer q.ds.Unlock()
+
+	for i, quo := range q.ds.quotas {
+		if quo.Name == quota.Name {
+			q.ds.quotas[i] = quota
+
+			return nil
+		}
+	}
+
+	return errors.WithCode(code.ErrQuotaNotFound, "record not found")
+}
+
+// Delete deletes the quota of a tenant.
+func (q *quotas) Delete(ctx context.Context, tenant string, opts metav1.DeleteOptions) error {
+	q.ds.Lock()
+	defer q.ds.Unlock()
+
+	quotas := q.ds.quotas
+	q.ds.quotas = make([]*v1.Quota, 0)
+	for _, quo := range quotas {
+		if quo.Name == tenant {
+			continue
+		}
+
+		q.ds.quotas = append(q.ds.quotas, quo)
+	}
+
+	return nil
+}
+
+// Get return the quota of a tenant.
+func (q *quotas) Get(ctx context.Context, tenant string, opts metav1.GetOptions) (*v1.Quota, error) {
+	q.ds.RLock()
+	defer q.ds.RUnlock()
+
+	for _, quo := range q.ds.quotas {
+		if quo.Name == tenant {
+			return quo, nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrQuotaNotFound, "record not found")
+}
+
+// List return the quotas of all tenants.
+func (q *quotas) List(ctx context.Context, opts metav1.ListOptions) (*v1.QuotaList, error) {
+	q.ds.RLock()
+	defer q.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	quotas := q.ds.quotas
+	total := int64(len(quotas))
+	if ol.Offset < len(quotas) {
+		quotas = quotas[ol.Offset:]
+	} else {
+		quotas = quotas[:0]
+	}
+
+	if ol.Limit >= 0 && ol.Limit < len(quotas) {
+		quotas = quotas[:ol.Limit]
+	}
+
+	return &v1.QuotaList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: total,
+		},
+		Items: append([]*v1.Quota{}, quotas...),
+	}, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
 	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 	reflectutil "github.com/marmotedu/iam/internal/pkg/util/reflect"
 )
@@ -41,6 +42,7 @@ func (s *secrets) Create(ctx context.Context, secret *v1.Secret, opts metav1.Cre
 	if len(s.ds.secrets) > 0 {
 		secret.ID = s.ds.secrets[len(s.ds.secrets)-1].ID + 1
 	}
+	secret.Extend = resourceversion.Init(secret.Extend)
 	s.ds.secrets = append(s.ds.secrets, secret)
 
 	return nil
@@ -53,6 +55,12 @@ func (s *secrets) Update(ctx context.Context, secret *v1.Secret, opts metav1.Upd
 
 	for _, sec := range s.ds.secrets {
 		if sec.Username == secret.Username && sec.Name == secret.Name {
+			extend, err := resourceversion.Next(sec.Extend, secret.Extend)
+			if err != nil {
+				return err
+			}
+			secret.Extend = extend
+
 			if _, err := reflectutil.CopyObj(secret, sec, nil); err != nil {
 				return errors.Wrap(err, "copy secret failed")
 			}
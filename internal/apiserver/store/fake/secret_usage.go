@@ -0,0 +1,77 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type secretUsages struct {
+	ds *datastore
+}
+
+func newSecretUsages(ds *datastore) *secretUsages {
+	return &secretUsages{ds}
+}
+
+// Record records the usages, keeping the later of the recorded and the given times.
+func (s *secretUsages) Record(ctx context.Context, usages []*v1.SecretUsage) error {
+	s.ds.Lock()
+	defer s.ds.Unlock()
+
+	if s.ds.usages == nil {
+		s.ds.usages = make(map[string]*v1.SecretUsage)
+	}
+
+	for _, usage := range usages {
+		if recorded, ok := s.ds.usages[usage.SecretID]; ok && !recorded.LastUsedAt.Before(usage.LastUsedAt) {
+			continue
+		}
+
+		u := *usage
+		s.ds.usages[usage.SecretID] = &u
+	}
+
+	return nil
+}
+
+// List returns the usages of the given secrets, the secrets never used are left out.
+func (s *secretUsages) List(ctx context.Context, secretIDs []string) ([]*v1.SecretUsage, error) {
+	s.ds.RLock()
+	defer s.ds.RUnlock()
+
+	ret := make([]*v1.SecretUsage, 0, len(secretIDs))
+	for _, secretID := range secretIDs {
+		if usage, ok := s.ds.usages[secretID]; ok {
+			u := *usage
+			ret = append(ret, &u)
+		}
+	}
+
+	return ret, nil
+}
+
+// Prune deletes the usages of the deleted secrets.
+func (s *secretUsages) Prune(ctx context.Context) (int64, error) {
+	s.ds.Lock()
+	defer s.ds.Unlock()
+
+	exists := make(map[string]bool, len(s.ds.secrets))
+	for _, secret := range s.ds.secrets {
+		exists[secret.SecretID] = true
+	}
+
+	var deleted int64
+	for secretID := range s.ds.usages {
+		if !exists[secretID] {
+			delete(s.ds.usages, secretID)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
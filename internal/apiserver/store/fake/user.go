@@ -15,6 +15,8 @@ import (
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
 	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 	reflectutil "github.com/marmotedu/iam/internal/pkg/util/reflect"
 )
@@ -41,6 +43,7 @@ func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOpt
 	if len(u.ds.users) > 0 {
 		user.ID = u.ds.users[len(u.ds.users)-1].ID + 1
 	}
+	user.Extend = resourceversion.Init(user.Extend)
 	u.ds.users = append(u.ds.users, user)
 
 	return nil
@@ -53,6 +56,12 @@ func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOpt
 
 	for _, u := range u.ds.users {
 		if u.Name == user.Name {
+			extend, err := resourceversion.Next(u.Extend, user.Extend)
+			if err != nil {
+				return err
+			}
+			user.Extend = extend
+
 			if _, err := reflectutil.CopyObj(user, u, nil); err != nil {
 				return errors.Wrap(err, "copy user failed")
 			}
@@ -132,23 +141,32 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	username, _ := selector.RequiresExactMatch("name")
+	tenantName, byTenant := selector.RequiresExactMatch("tenant")
 
+	// like the other lists of the fake store, the total counts all the users unless they are
+	// selected by tenant
 	users := make([]*v1.User, 0)
-	i := 0
+	total, matched := len(u.ds.users), 0
 	for _, user := range u.ds.users {
-		if i == ol.Limit {
-			break
-		}
 		if !strings.Contains(user.Name, username) {
 			continue
 		}
-		users = append(users, user)
-		i++
+		if byTenant && tenant.FromExtend(user.Extend) != tenantName {
+			continue
+		}
+
+		matched++
+		if len(users) != ol.Limit {
+			users = append(users, user)
+		}
+	}
+	if byTenant {
+		total = matched
 	}
 
 	return &v1.UserList{
 		ListMeta: metav1.ListMeta{
-			TotalCount: int64(len(u.ds.users)),
+			TotalCount: int64(total),
 		},
 		Items: users,
 	}, nil
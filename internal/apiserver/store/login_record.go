@@ -0,0 +1,20 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// LoginRecordStore defines the login_record storage interface.
+type LoginRecordStore interface {
+	Create(ctx context.Context, record *v1.LoginRecord, opts metav1.CreateOptions) error
+	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.LoginRecordList, error)
+	DeleteByUser(ctx context.Context, username string) (int64, error)
+}
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/marmotedu/iam/internal/apiserver/store (interfaces: Factory,UserStore,SecretStore,PolicyStore)
+// Source: github.com/marmotedu/iam/internal/apiserver/store (interfaces: Factory,UserStore,SecretStore,SecretUsageStore,PolicyStore,PolicyAttachmentStore,LoginRecordStore,GroupStore,AuditEventStore,CompletionStore,QuotaStore,OperationStore)
 
 // Package store is a generated GoMock package.
 package store
@@ -15,6 +15,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	v10 "github.com/marmotedu/component-base/pkg/meta/v1"
+	v11 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 )
 
 // MockFactory is a mock of Factory interface.
@@ -40,6 +41,20 @@ func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
 	return m.recorder
 }
 
+// AuditEvents mocks base method.
+func (m *MockFactory) AuditEvents() AuditEventStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditEvents")
+	ret0, _ := ret[0].(AuditEventStore)
+	return ret0
+}
+
+// AuditEvents indicates an expected call of AuditEvents.
+func (mr *MockFactoryMockRecorder) AuditEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditEvents", reflect.TypeOf((*MockFactory)(nil).AuditEvents))
+}
+
 // Close mocks base method.
 func (m *MockFactory) Close() error {
 	m.ctrl.T.Helper()
@@ -54,6 +69,62 @@ func (mr *MockFactoryMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockFactory)(nil).Close))
 }
 
+// Completions mocks base method.
+func (m *MockFactory) Completions() CompletionStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Completions")
+	ret0, _ := ret[0].(CompletionStore)
+	return ret0
+}
+
+// Completions indicates an expected call of Completions.
+func (mr *MockFactoryMockRecorder) Completions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Completions", reflect.TypeOf((*MockFactory)(nil).Completions))
+}
+
+// Groups mocks base method.
+func (m *MockFactory) Groups() GroupStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Groups")
+	ret0, _ := ret[0].(GroupStore)
+	return ret0
+}
+
+// Groups indicates an expected call of Groups.
+func (mr *MockFactoryMockRecorder) Groups() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Groups", reflect.TypeOf((*MockFactory)(nil).Groups))
+}
+
+// LoginRecords mocks base method.
+func (m *MockFactory) LoginRecords() LoginRecordStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginRecords")
+	ret0, _ := ret[0].(LoginRecordStore)
+	return ret0
+}
+
+// LoginRecords indicates an expected call of LoginRecords.
+func (mr *MockFactoryMockRecorder) LoginRecords() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginRecords", reflect.TypeOf((*MockFactory)(nil).LoginRecords))
+}
+
+// Operations mocks base method.
+func (m *MockFactory) Operations() OperationStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Operations")
+	ret0, _ := ret[0].(OperationStore)
+	return ret0
+}
+
+// Operations indicates an expected call of Operations.
+func (mr *MockFactoryMockRecorder) Operations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operations", reflect.TypeOf((*MockFactory)(nil).Operations))
+}
+
 // Policies mocks base method.
 func (m *MockFactory) Policies() PolicyStore {
 	m.ctrl.T.Helper()
@@ -68,6 +139,20 @@ func (mr *MockFactoryMockRecorder) Policies() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Policies", reflect.TypeOf((*MockFactory)(nil).Policies))
 }
 
+// PolicyAttachments mocks base method.
+func (m *MockFactory) PolicyAttachments() PolicyAttachmentStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PolicyAttachments")
+	ret0, _ := ret[0].(PolicyAttachmentStore)
+	return ret0
+}
+
+// PolicyAttachments indicates an expected call of PolicyAttachments.
+func (mr *MockFactoryMockRecorder) PolicyAttachments() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PolicyAttachments", reflect.TypeOf((*MockFactory)(nil).PolicyAttachments))
+}
+
 // PolicyAudits mocks base method.
 func (m *MockFactory) PolicyAudits() PolicyAuditStore {
 	m.ctrl.T.Helper()
@@ -82,6 +167,34 @@ func (mr *MockFactoryMockRecorder) PolicyAudits() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PolicyAudits", reflect.TypeOf((*MockFactory)(nil).PolicyAudits))
 }
 
+// Quotas mocks base method.
+func (m *MockFactory) Quotas() QuotaStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quotas")
+	ret0, _ := ret[0].(QuotaStore)
+	return ret0
+}
+
+// Quotas indicates an expected call of Quotas.
+func (mr *MockFactoryMockRecorder) Quotas() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quotas", reflect.TypeOf((*MockFactory)(nil).Quotas))
+}
+
+// SecretUsages mocks base method.
+func (m *MockFactory) SecretUsages() SecretUsageStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecretUsages")
+	ret0, _ := ret[0].(SecretUsageStore)
+	return ret0
+}
+
+// SecretUsages indicates an expected call of SecretUsages.
+func (mr *MockFactoryMockRecorder) SecretUsages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecretUsages", reflect.TypeOf((*MockFactory)(nil).SecretUsages))
+}
+
 // Secrets mocks base method.
 func (m *MockFactory) Secrets() SecretStore {
 	m.ctrl.T.Helper()
@@ -328,6 +441,73 @@ func (mr *MockSecretStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSecretStore)(nil).Update), arg0, arg1, arg2)
 }
 
+// MockSecretUsageStore is a mock of SecretUsageStore interface.
+type MockSecretUsageStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretUsageStoreMockRecorder
+}
+
+// MockSecretUsageStoreMockRecorder is the mock recorder for MockSecretUsageStore.
+type MockSecretUsageStoreMockRecorder struct {
+	mock *MockSecretUsageStore
+}
+
+// NewMockSecretUsageStore creates a new mock instance.
+func NewMockSecretUsageStore(ctrl *gomock.Controller) *MockSecretUsageStore {
+	mock := &MockSecretUsageStore{ctrl: ctrl}
+	mock.recorder = &MockSecretUsageStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretUsageStore) EXPECT() *MockSecretUsageStoreMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockSecretUsageStore) List(arg0 context.Context, arg1 []string) ([]*v11.SecretUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*v11.SecretUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSecretUsageStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSecretUsageStore)(nil).List), arg0, arg1)
+}
+
+// Prune mocks base method.
+func (m *MockSecretUsageStore) Prune(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockSecretUsageStoreMockRecorder) Prune(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockSecretUsageStore)(nil).Prune), arg0)
+}
+
+// Record mocks base method.
+func (m *MockSecretUsageStore) Record(arg0 context.Context, arg1 []*v11.SecretUsage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockSecretUsageStoreMockRecorder) Record(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockSecretUsageStore)(nil).Record), arg0, arg1)
+}
+
 // MockPolicyStore is a mock of PolicyStore interface.
 type MockPolicyStore struct {
 	ctrl     *gomock.Controller
@@ -436,3 +616,527 @@ func (mr *MockPolicyStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPolicyStore)(nil).Update), arg0, arg1, arg2)
 }
+
+// MockPolicyAttachmentStore is a mock of PolicyAttachmentStore interface.
+type MockPolicyAttachmentStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyAttachmentStoreMockRecorder
+}
+
+// MockPolicyAttachmentStoreMockRecorder is the mock recorder for MockPolicyAttachmentStore.
+type MockPolicyAttachmentStoreMockRecorder struct {
+	mock *MockPolicyAttachmentStore
+}
+
+// NewMockPolicyAttachmentStore creates a new mock instance.
+func NewMockPolicyAttachmentStore(ctrl *gomock.Controller) *MockPolicyAttachmentStore {
+	mock := &MockPolicyAttachmentStore{ctrl: ctrl}
+	mock.recorder = &MockPolicyAttachmentStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyAttachmentStore) EXPECT() *MockPolicyAttachmentStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPolicyAttachmentStore) Create(arg0 context.Context, arg1 *v11.PolicyAttachment, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPolicyAttachmentStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPolicyAttachmentStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockPolicyAttachmentStore) Delete(arg0 context.Context, arg1, arg2, arg3 string, arg4 v10.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPolicyAttachmentStoreMockRecorder) Delete(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPolicyAttachmentStore)(nil).Delete), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Get mocks base method.
+func (m *MockPolicyAttachmentStore) Get(arg0 context.Context, arg1, arg2, arg3 string, arg4 v10.GetOptions) (*v11.PolicyAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v11.PolicyAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPolicyAttachmentStoreMockRecorder) Get(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPolicyAttachmentStore)(nil).Get), arg0, arg1, arg2, arg3, arg4)
+}
+
+// List mocks base method.
+func (m *MockPolicyAttachmentStore) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v11.PolicyAttachmentList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v11.PolicyAttachmentList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockPolicyAttachmentStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPolicyAttachmentStore)(nil).List), arg0, arg1, arg2)
+}
+
+// MockLoginRecordStore is a mock of LoginRecordStore interface.
+type MockLoginRecordStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginRecordStoreMockRecorder
+}
+
+// MockLoginRecordStoreMockRecorder is the mock recorder for MockLoginRecordStore.
+type MockLoginRecordStoreMockRecorder struct {
+	mock *MockLoginRecordStore
+}
+
+// NewMockLoginRecordStore creates a new mock instance.
+func NewMockLoginRecordStore(ctrl *gomock.Controller) *MockLoginRecordStore {
+	mock := &MockLoginRecordStore{ctrl: ctrl}
+	mock.recorder = &MockLoginRecordStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginRecordStore) EXPECT() *MockLoginRecordStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockLoginRecordStore) Create(arg0 context.Context, arg1 *v11.LoginRecord, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockLoginRecordStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLoginRecordStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// DeleteByUser mocks base method.
+func (m *MockLoginRecordStore) DeleteByUser(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUser", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByUser indicates an expected call of DeleteByUser.
+func (mr *MockLoginRecordStoreMockRecorder) DeleteByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUser", reflect.TypeOf((*MockLoginRecordStore)(nil).DeleteByUser), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockLoginRecordStore) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v11.LoginRecordList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v11.LoginRecordList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockLoginRecordStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLoginRecordStore)(nil).List), arg0, arg1, arg2)
+}
+
+// MockGroupStore is a mock of GroupStore interface.
+type MockGroupStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupStoreMockRecorder
+}
+
+// MockGroupStoreMockRecorder is the mock recorder for MockGroupStore.
+type MockGroupStoreMockRecorder struct {
+	mock *MockGroupStore
+}
+
+// NewMockGroupStore creates a new mock instance.
+func NewMockGroupStore(ctrl *gomock.Controller) *MockGroupStore {
+	mock := &MockGroupStore{ctrl: ctrl}
+	mock.recorder = &MockGroupStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupStore) EXPECT() *MockGroupStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockGroupStore) Create(arg0 context.Context, arg1 *v11.Group, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGroupStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroupStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockGroupStore) Delete(arg0 context.Context, arg1, arg2 string, arg3 v10.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGroupStoreMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGroupStore)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// Get mocks base method.
+func (m *MockGroupStore) Get(arg0 context.Context, arg1, arg2 string, arg3 v10.GetOptions) (*v11.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v11.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockGroupStoreMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockGroupStore)(nil).Get), arg0, arg1, arg2, arg3)
+}
+
+// List mocks base method.
+func (m *MockGroupStore) List(arg0 context.Context, arg1 string, arg2 v10.ListOptions) (*v11.GroupList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v11.GroupList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockGroupStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockGroupStore)(nil).List), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockGroupStore) Update(arg0 context.Context, arg1 *v11.Group, arg2 v10.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockGroupStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockGroupStore)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockAuditEventStore is a mock of AuditEventStore interface.
+type MockAuditEventStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditEventStoreMockRecorder
+}
+
+// MockAuditEventStoreMockRecorder is the mock recorder for MockAuditEventStore.
+type MockAuditEventStoreMockRecorder struct {
+	mock *MockAuditEventStore
+}
+
+// NewMockAuditEventStore creates a new mock instance.
+func NewMockAuditEventStore(ctrl *gomock.Controller) *MockAuditEventStore {
+	mock := &MockAuditEventStore{ctrl: ctrl}
+	mock.recorder = &MockAuditEventStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditEventStore) EXPECT() *MockAuditEventStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuditEventStore) Create(arg0 context.Context, arg1 *v11.AuditEvent, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditEventStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditEventStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockAuditEventStore) List(arg0 context.Context, arg1 v11.AuditEventListOptions) (*v11.AuditEventList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*v11.AuditEventList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditEventStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditEventStore)(nil).List), arg0, arg1)
+}
+
+// Tombstone mocks base method.
+func (m *MockAuditEventStore) Tombstone(arg0 context.Context, arg1, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tombstone", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tombstone indicates an expected call of Tombstone.
+func (mr *MockAuditEventStoreMockRecorder) Tombstone(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tombstone", reflect.TypeOf((*MockAuditEventStore)(nil).Tombstone), arg0, arg1, arg2)
+}
+
+// MockCompletionStore is a mock of CompletionStore interface.
+type MockCompletionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCompletionStoreMockRecorder
+}
+
+// MockCompletionStoreMockRecorder is the mock recorder for MockCompletionStore.
+type MockCompletionStoreMockRecorder struct {
+	mock *MockCompletionStore
+}
+
+// NewMockCompletionStore creates a new mock instance.
+func NewMockCompletionStore(ctrl *gomock.Controller) *MockCompletionStore {
+	mock := &MockCompletionStore{ctrl: ctrl}
+	mock.recorder = &MockCompletionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCompletionStore) EXPECT() *MockCompletionStoreMockRecorder {
+	return m.recorder
+}
+
+// Names mocks base method.
+func (m *MockCompletionStore) Names(arg0 context.Context, arg1, arg2, arg3 string, arg4 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Names", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Names indicates an expected call of Names.
+func (mr *MockCompletionStoreMockRecorder) Names(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Names", reflect.TypeOf((*MockCompletionStore)(nil).Names), arg0, arg1, arg2, arg3, arg4)
+}
+
+// MockQuotaStore is a mock of QuotaStore interface.
+type MockQuotaStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaStoreMockRecorder
+}
+
+// MockQuotaStoreMockRecorder is the mock recorder for MockQuotaStore.
+type MockQuotaStoreMockRecorder struct {
+	mock *MockQuotaStore
+}
+
+// NewMockQuotaStore creates a new mock instance.
+func NewMockQuotaStore(ctrl *gomock.Controller) *MockQuotaStore {
+	mock := &MockQuotaStore{ctrl: ctrl}
+	mock.recorder = &MockQuotaStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaStore) EXPECT() *MockQuotaStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockQuotaStore) Create(arg0 context.Context, arg1 *v11.Quota, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockQuotaStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockQuotaStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockQuotaStore) Delete(arg0 context.Context, arg1 string, arg2 v10.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockQuotaStoreMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockQuotaStore)(nil).Delete), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockQuotaStore) Get(arg0 context.Context, arg1 string, arg2 v10.GetOptions) (*v11.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v11.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockQuotaStoreMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockQuotaStore)(nil).Get), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockQuotaStore) List(arg0 context.Context, arg1 v10.ListOptions) (*v11.QuotaList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*v11.QuotaList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockQuotaStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockQuotaStore)(nil).List), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockQuotaStore) Update(arg0 context.Context, arg1 *v11.Quota, arg2 v10.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockQuotaStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockQuotaStore)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockOperationStore is a mock of OperationStore interface.
+type MockOperationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOperationStoreMockRecorder
+}
+
+// MockOperationStoreMockRecorder is the mock recorder for MockOperationStore.
+type MockOperationStoreMockRecorder struct {
+	mock *MockOperationStore
+}
+
+// NewMockOperationStore creates a new mock instance.
+func NewMockOperationStore(ctrl *gomock.Controller) *MockOperationStore {
+	mock := &MockOperationStore{ctrl: ctrl}
+	mock.recorder = &MockOperationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOperationStore) EXPECT() *MockOperationStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOperationStore) Create(arg0 context.Context, arg1 *v11.Operation, arg2 v10.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOperationStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOperationStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockOperationStore) Get(arg0 context.Context, arg1 string, arg2 v10.GetOptions) (*v11.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v11.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOperationStoreMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOperationStore)(nil).Get), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockOperationStore) List(arg0 context.Context, arg1 v10.ListOptions) (*v11.OperationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*v11.OperationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOperationStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOperationStore)(nil).List), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockOperationStore) Update(arg0 context.Context, arg1 *v11.Operation, arg2 v10.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOperationStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOperationStore)(nil).Update), arg0, arg1, arg2)
+}
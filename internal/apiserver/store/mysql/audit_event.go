@@ -0,0 +1,93 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// auditEventColumns maps the fields of the field selector to the columns of the audit events.
+var auditEventColumns = map[string]string{
+	"username":     "username",
+	"verb":         "verb",
+	"resource":     "resource",
+	"resourceName": "resourceName",
+}
+
+// auditEventSortColumns are the columns of the audit event fields which the events can be
+// sorted by.
+var auditEventSortColumns = map[string]string{
+	"username":   "username",
+	"verb":       "verb",
+	"resource":   "resource",
+	"statusCode": "statusCode",
+	"createdAt":  "createdAt",
+}
+
+type auditEvents struct {
+	db *gorm.DB
+}
+
+func newAuditEvents(ds *datastore) *auditEvents {
+	return &auditEvents{ds.db}
+}
+
+// Create records an audit event.
+func (a *auditEvents) Create(ctx context.Context, event *v1.AuditEvent, opts metav1.CreateOptions) error {
+	return withContext(a.db, ctx).Create(&event).Error
+}
+
+// List return the audit events, newest first unless sorted otherwise, which can be filtered
+// by `username`, `verb`, `resource` and `resourceName` field selectors.
+func (a *auditEvents) List(ctx context.Context, opts v1.AuditEventListOptions) (*v1.AuditEventList, error) {
+	db := withContext(a.db, ctx)
+
+	ret := &v1.AuditEventList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	query := gormutil.WhereFields(db.Model(&v1.AuditEvent{}), selector, auditEventColumns)
+
+	if opts.Since != nil {
+		query = query.Where("createdAt >= ?", *opts.Since)
+	}
+
+	if opts.AfterID > 0 {
+		query = query.Where("id > ?", opts.AfterID)
+	}
+
+	sort := pagination.SortFrom(ctx)
+	d := gormutil.OrderBy(query, sort.By, sort.Desc, auditEventSortColumns).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Find(&ret.Items)
+	if d.Error != nil || !pagination.TotalCount(ctx) {
+		return ret, d.Error
+	}
+
+	d = d.Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
+
+// Tombstone replaces the actor fields of the events of a user, its name, ip and user agent,
+// by the tombstone, and returns the number of events.
+func (a *auditEvents) Tombstone(ctx context.Context, username, tombstone string) (int64, error) {
+	d := withContext(a.db, ctx).Model(&v1.AuditEvent{}).
+		Where("username = ?", username).
+		Updates(map[string]interface{}{"username": tombstone, "ip": "", "userAgent": ""})
+
+	return d.RowsAffected, d.Error
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"gorm.io/gorm"
+
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type completions struct {
+	db *gorm.DB
+}
+
+func newCompletions(ds *datastore) *completions {
+	return &completions{ds.db}
+}
+
+// Names returns the names of the resources of the kind owned by the user, all the available
+// users when the kind is user, which start with the prefix, in alphabetical order. Only the
+// names are selected, by the indexes on the names.
+func (c *completions) Names(ctx context.Context, kind, username, prefix string, limit int) ([]string, error) {
+	db := withContext(c.db, ctx)
+
+	var query *gorm.DB
+	switch kind {
+	case iamv1.CompletionKindUser:
+		query = db.Model(&v1.User{}).Where("status = 1")
+	case iamv1.CompletionKindSecret:
+		query = db.Model(&v1.Secret{}).Where("username = ?", username)
+	case iamv1.CompletionKindPolicy:
+		query = db.Model(&v1.Policy{}).Where("username = ?", username)
+	case iamv1.CompletionKindGroup:
+		query = db.Model(&iamv1.Group{}).Where("username = ?", username)
+	default:
+		return nil, fmt.Errorf("unsupported completion kind %q", kind)
+	}
+
+	if prefix != "" {
+		query = query.Where("name like ?", likeEscaper.Replace(prefix)+"%")
+	}
+
+	names := make([]string, 0)
+	err := query.Order("name").Limit(limit).Pluck("name", &names).Error
+
+	return names, err
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type groups struct {
+	db *gorm.DB
+}
+
+// groupRow is a listed group along with the total count, like userRow.
+type groupRow struct {
+	v1.Group
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// groupList converts the listed rows to a group list, like userList.
+func groupList(query *gorm.DB, rows []*groupRow, offset int, count bool) (*v1.GroupList, error) {
+	ret := &v1.GroupList{Items: make([]*v1.Group, 0, len(rows))}
+	for _, row := range rows {
+		ret.Items = append(ret.Items, &row.Group)
+		ret.TotalCount = row.TotalCount
+	}
+
+	if count && len(rows) == 0 && offset > 0 {
+		if err := query.Model(&v1.Group{}).Count(&ret.TotalCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func newGroups(ds *datastore) *groups {
+	return &groups{ds.db}
+}
+
+// Create creates a new group.
+func (g *groups) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error {
+	return withContext(g.db, ctx).Create(&group).Error
+}
+
+// Update updates a group and its members.
+func (g *groups) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error {
+	return withContext(g.db, ctx).Save(group).Error
+}
+
+// Delete deletes the group by the group identifier.
+func (g *groups) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	db := withContext(g.db, ctx)
+
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+
+	err := db.Where("username = ? and name = ?", username, name).Delete(&v1.Group{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get return group by the group identifier.
+func (g *groups) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Group, error) {
+	db := withContext(g.db, ctx)
+
+	group := &v1.Group{}
+	err := db.Where("username = ? and name = ?", username, name).First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrGroupNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return group, nil
+}
+
+// List return all groups, which can be filtered by `name` and `kind` field selectors.
+func (g *groups) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.GroupList, error) {
+	db := withContext(g.db, ctx)
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	if username != "" {
+		db = db.Where("username = ?", username)
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	if name, ok := selector.RequiresExactMatch("name"); ok {
+		db = db.Where("name = ?", name)
+	}
+
+	if kind, ok := selector.RequiresExactMatch("kind"); ok {
+		db = db.Where("kind = ?", kind)
+	}
+
+	query := db.Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	var rows []*groupRow
+	d := gormutil.WithTotalCountIf(query, count).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
+
+	return groupList(query, rows, ol.Offset, count)
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type loginRecords struct {
+	db *gorm.DB
+}
+
+// loginRecordRow is a listed login record along with the total count, like userRow.
+type loginRecordRow struct {
+	v1.LoginRecord
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// loginRecordList converts the listed rows to a login record list, like userList.
+func loginRecordList(query *gorm.DB, rows []*loginRecordRow, offset int, count bool) (*v1.LoginRecordList, error) {
+	ret := &v1.LoginRecordList{Items: make([]*v1.LoginRecord, 0, len(rows))}
+	for _, row := range rows {
+		ret.Items = append(ret.Items, &row.LoginRecord)
+		ret.TotalCount = row.TotalCount
+	}
+
+	if count && len(rows) == 0 && offset > 0 {
+		if err := query.Model(&v1.LoginRecord{}).Count(&ret.TotalCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func newLoginRecords(ds *datastore) *loginRecords {
+	return &loginRecords{ds.db}
+}
+
+// Create records a login attempt.
+func (l *loginRecords) Create(ctx context.Context, record *v1.LoginRecord, opts metav1.CreateOptions) error {
+	return withContext(l.db, ctx).Create(&record).Error
+}
+
+// List return the login records of a user, which can be filtered by `success`, `method` and `ip` field selectors.
+func (l *loginRecords) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.LoginRecordList, error) {
+	db := withContext(l.db, ctx)
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	db = db.Where("username = ?", username)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	if success, ok := selector.RequiresExactMatch("success"); ok {
+		b, _ := strconv.ParseBool(success)
+		db = db.Where("success = ?", b)
+	}
+
+	if method, ok := selector.RequiresExactMatch("method"); ok {
+		db = db.Where("method = ?", method)
+	}
+
+	if ip, ok := selector.RequiresExactMatch("ip"); ok {
+		db = db.Where("ip = ?", ip)
+	}
+
+	query := db.Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	var rows []*loginRecordRow
+	d := gormutil.WithTotalCountIf(query, count).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
+
+	return loginRecordList(query, rows, ol.Offset, count)
+}
+
+// DeleteByUser deletes the login records of a user and returns their number.
+func (l *loginRecords) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	d := withContext(l.db, ctx).Where("username = ?", username).Delete(&v1.LoginRecord{})
+
+	return d.RowsAffected, d.Error
+}
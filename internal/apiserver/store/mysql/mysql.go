@@ -5,17 +5,24 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
 
 	v1 "github.com/marmotedu/api/apiserver/v1"
 	"github.com/marmotedu/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/gorm"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
 	"github.com/marmotedu/iam/internal/pkg/logger"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
 	"github.com/marmotedu/iam/pkg/db"
+	"github.com/marmotedu/iam/pkg/log"
 )
 
 type datastore struct {
@@ -34,6 +41,10 @@ func (ds *datastore) Secrets() store.SecretStore {
 	return newSecrets(ds)
 }
 
+func (ds *datastore) SecretUsages() store.SecretUsageStore {
+	return newSecretUsages(ds)
+}
+
 func (ds *datastore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
@@ -42,13 +53,44 @@ func (ds *datastore) PolicyAudits() store.PolicyAuditStore {
 	return newPolicyAudits(ds)
 }
 
-func (ds *datastore) Close() error {
-	db, err := ds.db.DB()
-	if err != nil {
-		return errors.Wrap(err, "get gorm db instance failed")
-	}
+func (ds *datastore) PolicyAttachments() store.PolicyAttachmentStore {
+	return newPolicyAttachments(ds)
+}
 
-	return db.Close()
+func (ds *datastore) Groups() store.GroupStore {
+	return newGroups(ds)
+}
+
+func (ds *datastore) LoginRecords() store.LoginRecordStore {
+	return newLoginRecords(ds)
+}
+
+func (ds *datastore) AuditEvents() store.AuditEventStore {
+	return newAuditEvents(ds)
+}
+
+func (ds *datastore) Completions() store.CompletionStore {
+	return newCompletions(ds)
+}
+
+func (ds *datastore) Quotas() store.QuotaStore {
+	return newQuotas(ds)
+}
+
+func (ds *datastore) Operations() store.OperationStore {
+	return newOperations(ds)
+}
+
+// Snapshot calls fn with a factory reading a read only transaction, the repeatable read
+// isolation of which reads all the tables at the same point in time.
+func (ds *datastore) Snapshot(ctx context.Context, fn func(store.Factory) error) error {
+	return withContext(ds.db, ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&datastore{tx})
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+func (ds *datastore) Close() error {
+	return errors.Wrap(db.Close(ds.db), "close gorm db instance failed")
 }
 
 var (
@@ -73,10 +115,24 @@ func GetMySQLFactoryOr(opts *genericoptions.MySQLOptions) (store.Factory, error)
 			MaxIdleConnections:    opts.MaxIdleConnections,
 			MaxOpenConnections:    opts.MaxOpenConnections,
 			MaxConnectionLifeTime: opts.MaxConnectionLifeTime,
+			PrepareStatement:      opts.PrepareStatement,
 			LogLevel:              opts.LogLevel,
 			Logger:                logger.New(opts.LogLevel),
+			TLSMode:               opts.TLSMode,
+			TLSCAFile:             opts.TLSCAFile,
+			Charset:               opts.Charset,
+			Loc:                   opts.Loc,
+			ConnectTimeout:        opts.ConnectTimeout,
+			ReadTimeout:           opts.ReadTimeout,
+			WriteTimeout:          opts.WriteTimeout,
+			Params:                opts.Params,
+			Hosts:                 opts.Hosts,
+			ProbeInterval:         opts.ProbeInterval,
 		}
 		dbIns, err = db.New(options)
+		if err == nil {
+			registerDBStats(dbIns, opts.Database)
+		}
 
 		// uncomment the following line if you need auto migration the given models
 		// not suggested in production environment.
@@ -92,6 +148,18 @@ func GetMySQLFactoryOr(opts *genericoptions.MySQLOptions) (store.Factory, error)
 	return mysqlFactory, nil
 }
 
+// registerDBStats exposes the connection pool statistics of the database as prometheus metrics.
+func registerDBStats(dbIns *gorm.DB, name string) {
+	sqlDB, err := dbIns.DB()
+	if err != nil {
+		return
+	}
+
+	if err := prometheus.Register(collectors.NewDBStatsCollector(sqlDB, name)); err != nil {
+		log.Warnf("register the statistics of database %s failed: %s", name, err.Error())
+	}
+}
+
 // cleanDatabase tear downs the database tables.
 // nolint:unused // may be reused in the feature, or just show a migrate usage.
 func cleanDatabase(db *gorm.DB) error {
@@ -110,16 +178,40 @@ func cleanDatabase(db *gorm.DB) error {
 
 // migrateDatabase run auto migration for given models, will only add missing fields,
 // won't delete/change current data.
-// nolint:unused // may be reused in the feature, or just show a migrate usage.
 func migrateDatabase(db *gorm.DB) error {
-	if err := db.AutoMigrate(&v1.User{}); err != nil {
-		return errors.Wrap(err, "migrate user model failed")
+	for _, model := range schemaModels {
+		if err := db.AutoMigrate(model); err != nil {
+			return errors.Wrapf(err, "migrate %T model failed", model)
+		}
 	}
-	if err := db.AutoMigrate(&v1.Policy{}); err != nil {
-		return errors.Wrap(err, "migrate policy model failed")
+
+	for _, index := range nameIndexes {
+		name, stmt := index.name, index.stmt
+		if isSQLite(db) {
+			// the names of the indexes are unique in the whole SQLite database
+			name = index.table + "_" + index.name
+			stmt = strings.Replace(stmt, index.name, name, 1)
+		}
+
+		if db.Migrator().HasIndex(index.model, name) {
+			continue
+		}
+
+		if err := db.Exec(stmt).Error; err != nil {
+			return errors.Wrapf(err, "create index %s failed", name)
+		}
+	}
+
+	// the deleted policies are kept by the trigger for the audit
+	statements := policyAuditStatements
+	if isSQLite(db) {
+		statements = sqlitePolicyAuditStatements
 	}
-	if err := db.AutoMigrate(&v1.Secret{}); err != nil {
-		return errors.Wrap(err, "migrate secret model failed")
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return errors.Wrap(err, "create policy audit failed")
+		}
 	}
 
 	return nil
@@ -137,3 +229,9 @@ func resetDatabase(db *gorm.DB) error {
 
 	return nil
 }
+
+// withContext returns db running the statements with the context of the request, so that
+// they are cancelled along with it.
+func withContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.WithContext(middleware.RequestContext(ctx))
+}
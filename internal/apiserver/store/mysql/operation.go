@@ -0,0 +1,81 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// operationColumns are the columns of the operation fields which can be selected by equality.
+var operationColumns = map[string]string{
+	"kind":     "kind",
+	"username": "username",
+	"status":   "status",
+}
+
+type operations struct {
+	db *gorm.DB
+}
+
+func newOperations(ds *datastore) *operations {
+	return &operations{ds.db}
+}
+
+// Create creates a new operation.
+func (o *operations) Create(ctx context.Context, op *v1.Operation, opts metav1.CreateOptions) error {
+	return withContext(o.db, ctx).Create(&op).Error
+}
+
+// Update updates the status, the progress and the result of an operation.
+func (o *operations) Update(ctx context.Context, op *v1.Operation, opts metav1.UpdateOptions) error {
+	return withContext(o.db, ctx).Save(op).Error
+}
+
+// Get return an operation by its name.
+func (o *operations) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Operation, error) {
+	db := withContext(o.db, ctx)
+
+	op := &v1.Operation{}
+	err := db.Where("name = ?", name).First(&op).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrOperationNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return op, nil
+}
+
+// List return the operations, oldest first, which can be filtered by `kind`, `username` and
+// `status` field selectors.
+func (o *operations) List(ctx context.Context, opts metav1.ListOptions) (*v1.OperationList, error) {
+	db := withContext(o.db, ctx)
+
+	ret := &v1.OperationList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	d := gormutil.WhereFields(db.Model(&v1.Operation{}), selector, operationColumns).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id asc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
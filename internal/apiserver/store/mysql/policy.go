@@ -14,6 +14,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
 	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 )
 
@@ -21,27 +23,56 @@ type policies struct {
 	db *gorm.DB
 }
 
+// policyRow is a listed policy along with the total count, like userRow.
+type policyRow struct {
+	v1.Policy
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// policyList converts the listed rows to a policy list, like userList.
+func policyList(query *gorm.DB, rows []*policyRow, offset int, count bool) (*v1.PolicyList, error) {
+	ret := &v1.PolicyList{Items: make([]*v1.Policy, 0, len(rows))}
+	for _, row := range rows {
+		ret.Items = append(ret.Items, &row.Policy)
+		ret.TotalCount = row.TotalCount
+	}
+
+	if count && len(rows) == 0 && offset > 0 {
+		if err := query.Model(&v1.Policy{}).Count(&ret.TotalCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
 func newPolicies(ds *datastore) *policies {
 	return &policies{ds.db}
 }
 
 // Create creates a new ladon policy.
 func (p *policies) Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOptions) error {
-	return p.db.Create(&policy).Error
+	db := withContext(p.db, ctx)
+
+	policy.Extend = resourceversion.Init(policy.Extend)
+
+	return db.Create(&policy).Error
 }
 
 // Update updates policy by the policy identifier.
 func (p *policies) Update(ctx context.Context, policy *v1.Policy, opts metav1.UpdateOptions) error {
-	return p.db.Save(policy).Error
+	return saveVersion(withContext(p.db, ctx), policy, &policy.ObjectMeta, code.ErrPolicyNotFound)
 }
 
 // Delete deletes the policy by the policy identifier.
 func (p *policies) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	db := withContext(p.db, ctx)
+
 	if opts.Unscoped {
-		p.db = p.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	err := p.db.Where("username = ? and name = ?", username, name).Delete(&v1.Policy{}).Error
+	err := db.Where("username = ? and name = ?", username, name).Delete(&v1.Policy{}).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -51,11 +82,13 @@ func (p *policies) Delete(ctx context.Context, username, name string, opts metav
 
 // DeleteByUser deletes policies by username.
 func (p *policies) DeleteByUser(ctx context.Context, username string, opts metav1.DeleteOptions) error {
+	db := withContext(p.db, ctx)
+
 	if opts.Unscoped {
-		p.db = p.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	return p.db.Where("username = ?", username).Delete(&v1.Policy{}).Error
+	return db.Where("username = ?", username).Delete(&v1.Policy{}).Error
 }
 
 // DeleteCollection batch deletes policies by policies ids.
@@ -74,17 +107,21 @@ func (p *policies) DeleteCollection(
 
 // DeleteCollectionByUser batch deletes policies usernames.
 func (p *policies) DeleteCollectionByUser(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
+	db := withContext(p.db, ctx)
+
 	if opts.Unscoped {
-		p.db = p.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	return p.db.Where("username in (?)", usernames).Delete(&v1.Policy{}).Error
+	return db.Where("username in (?)", usernames).Delete(&v1.Policy{}).Error
 }
 
 // Get return policy by the policy identifier.
 func (p *policies) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Policy, error) {
+	db := withContext(p.db, ctx)
+
 	policy := &v1.Policy{}
-	err := p.db.Where("username = ? and name = ?", username, name).First(&policy).Error
+	err := db.Where("username = ? and name = ?", username, name).First(&policy).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(code.ErrPolicyNotFound, err.Error())
@@ -96,26 +133,53 @@ func (p *policies) Get(ctx context.Context, username, name string, opts metav1.G
 	return policy, nil
 }
 
+// policyColumns are the columns of the policy fields which can be selected by equality.
+var policyColumns = map[string]string{
+	"instanceID": "instanceID",
+}
+
+// policySortColumns are the columns of the policy fields which the policies can be sorted by.
+var policySortColumns = map[string]string{
+	"name":      "name",
+	"createdAt": "createdAt",
+	"updatedAt": "updatedAt",
+}
+
 // List return all policies.
 func (p *policies) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyList, error) {
-	ret := &v1.PolicyList{}
+	db := withContext(p.db, ctx)
+
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 
 	if username != "" {
-		p.db = p.db.Where("username = ?", username)
+		db = db.Where("username = ?", username)
 	}
 
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	name, _ := selector.RequiresExactMatch("name")
 
-	d := p.db.Where("name like ?", "%"+name+"%").
+	if name != "" {
+		db = db.Where("name like ?", "%"+name+"%")
+	}
+	db = gormutil.WhereFields(db, selector, policyColumns)
+	// the policies of a tenant are the policies owned by its users
+	if tenant, found := selector.RequiresExactMatch("ownerTenant"); found {
+		db = db.Where("username IN (?)", withContext(p.db, ctx).Model(&v1.User{}).
+			Select("name").Where("JSON_EXTRACT(extendShadow, '$.tenant') = ?", tenant))
+	}
+
+	query := db.Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	sort := pagination.SortFrom(ctx)
+	var rows []*policyRow
+	d := gormutil.OrderBy(gormutil.WithTotalCountIf(query, count), sort.By, sort.Desc, policySortColumns).
 		Offset(ol.Offset).
 		Limit(ol.Limit).
-		Order("id desc").
-		Find(&ret.Items).
-		Offset(-1).
-		Limit(-1).
-		Count(&ret.TotalCount)
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
 
-	return ret, d.Error
+	return policyList(query, rows, ol.Offset, count)
 }
@@ -0,0 +1,127 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type policyAttachments struct {
+	db *gorm.DB
+}
+
+// policyAttachmentRow is a listed policy attachment along with the total count, like userRow.
+type policyAttachmentRow struct {
+	v1.PolicyAttachment
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// policyAttachmentList converts the listed rows to a policy attachment list, like userList.
+func policyAttachmentList(query *gorm.DB, rows []*policyAttachmentRow, offset int, count bool) (*v1.PolicyAttachmentList, error) {
+	ret := &v1.PolicyAttachmentList{Items: make([]*v1.PolicyAttachment, 0, len(rows))}
+	for _, row := range rows {
+		ret.Items = append(ret.Items, &row.PolicyAttachment)
+		ret.TotalCount = row.TotalCount
+	}
+
+	if count && len(rows) == 0 && offset > 0 {
+		if err := query.Model(&v1.PolicyAttachment{}).Count(&ret.TotalCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func newPolicyAttachments(ds *datastore) *policyAttachments {
+	return &policyAttachments{ds.db}
+}
+
+// Create attaches a policy to a subject.
+func (p *policyAttachments) Create(ctx context.Context, attachment *v1.PolicyAttachment, opts metav1.CreateOptions) error {
+	return withContext(p.db, ctx).Create(&attachment).Error
+}
+
+// Delete detaches a policy from a subject.
+func (p *policyAttachments) Delete(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.DeleteOptions,
+) error {
+	err := p.db.Where("username = ? and policyName = ? and subject = ?", username, policyName, subject).
+		Delete(&v1.PolicyAttachment{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get return the attachment of a policy to a subject.
+func (p *policyAttachments) Get(
+	ctx context.Context,
+	username, policyName, subject string,
+	opts metav1.GetOptions,
+) (*v1.PolicyAttachment, error) {
+	attachment := &v1.PolicyAttachment{}
+	err := p.db.Where("username = ? and policyName = ? and subject = ?", username, policyName, subject).
+		First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrAttachmentNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return attachment, nil
+}
+
+// List return all attachments, which can be filtered by `policyName` and `subject` field selectors.
+func (p *policyAttachments) List(
+	ctx context.Context,
+	username string,
+	opts metav1.ListOptions,
+) (*v1.PolicyAttachmentList, error) {
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	if username != "" {
+		p.db = p.db.Where("username = ?", username)
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	if policyName, ok := selector.RequiresExactMatch("policyName"); ok {
+		p.db = p.db.Where("policyName = ?", policyName)
+	}
+
+	if subject, ok := selector.RequiresExactMatch("subject"); ok {
+		p.db = p.db.Where("subject = ?", subject)
+	}
+
+	query := p.db.Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	var rows []*policyAttachmentRow
+	d := gormutil.WithTotalCountIf(query, count).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
+
+	return policyAttachmentList(query, rows, ol.Offset, count)
+}
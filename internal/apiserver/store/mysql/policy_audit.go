@@ -21,9 +21,18 @@ func newPolicyAudits(ds *datastore) *policyAudit {
 
 // ClearOutdated clear data older than a given days.
 func (p *policyAudit) ClearOutdated(ctx context.Context, maxReserveDays int) (int64, error) {
+	db := withContext(p.db, ctx)
+
 	date := time.Now().AddDate(0, 0, -maxReserveDays).Format("2006-01-02 15:04:05")
 
-	d := p.db.Exec("delete from policy_audit where deletedAt < ?", date)
+	d := db.Exec("delete from policy_audit where deletedAt < ?", date)
+
+	return d.RowsAffected, d.Error
+}
+
+// DeleteByUser deletes the deleted policies of a user kept by the audit.
+func (p *policyAudit) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	d := withContext(p.db, ctx).Exec("delete from policy_audit where username = ?", username)
 
 	return d.RowsAffected, d.Error
 }
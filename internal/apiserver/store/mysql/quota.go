@@ -0,0 +1,87 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type quotas struct {
+	db *gorm.DB
+}
+
+func newQuotas(ds *datastore) *quotas {
+	return &quotas{ds.db}
+}
+
+// Create creates the quota of a tenant.
+func (q *quotas) Create(ctx context.Context, quota *v1.Quota, opts metav1.CreateOptions) error {
+	return withContext(q.db, ctx).Create(&quota).Error
+}
+
+// Update updates the quota of a tenant.
+func (q *quotas) Update(ctx context.Context, quota *v1.Quota, opts metav1.UpdateOptions) error {
+	return withContext(q.db, ctx).Save(quota).Error
+}
+
+// Delete deletes the quota of a tenant.
+func (q *quotas) Delete(ctx context.Context, tenant string, opts metav1.DeleteOptions) error {
+	db := withContext(q.db, ctx)
+
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+
+	err := db.Where("name = ?", tenant).Delete(&v1.Quota{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get return the quota of a tenant.
+func (q *quotas) Get(ctx context.Context, tenant string, opts metav1.GetOptions) (*v1.Quota, error) {
+	db := withContext(q.db, ctx)
+
+	quota := &v1.Quota{}
+	err := db.Where("name = ?", tenant).First(&quota).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrQuotaNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return quota, nil
+}
+
+// List return the quotas of all tenants.
+func (q *quotas) List(ctx context.Context, opts metav1.ListOptions) (*v1.QuotaList, error) {
+	db := withContext(q.db, ctx)
+
+	ret := &v1.QuotaList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	d := db.Model(&v1.Quota{}).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id asc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
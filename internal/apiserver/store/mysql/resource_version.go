@@ -0,0 +1,42 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
+)
+
+// saveVersion saves the model with the version following the stored one, see resourceversion.Next.
+// The row is locked until the model is saved, so that the concurrent updates are applied one after
+// another, each only to the version it carries.
+func saveVersion(db *gorm.DB, model interface{}, meta *metav1.ObjectMeta, notFoundCode int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var shadows []string
+		err := tx.Model(model).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", meta.ID).
+			Pluck("extendShadow", &shadows).Error
+		if err != nil {
+			return err
+		}
+
+		if len(shadows) == 0 {
+			return errors.WithCode(notFoundCode, "record not found")
+		}
+
+		extend, err := resourceversion.Next(metav1.Extend{}.Merge(shadows[0]), meta.Extend)
+		if err != nil {
+			return err
+		}
+		meta.Extend = extend
+
+		return tx.Save(model).Error
+	})
+}
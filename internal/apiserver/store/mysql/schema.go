@@ -0,0 +1,139 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"fmt"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/db"
+)
+
+// schemaModels are the models the store reads and writes with gorm.
+var schemaModels = []interface{}{
+	&v1.User{},
+	&v1.Secret{},
+	&iamv1.SecretUsage{},
+	&v1.Policy{},
+	&iamv1.Group{},
+	&iamv1.LoginRecord{},
+	&iamv1.PolicyAttachment{},
+	&iamv1.AuditEvent{},
+	&iamv1.Quota{},
+	&iamv1.Operation{},
+}
+
+// CheckSchema verifies the database has the tables of the models of the store, with all
+// their columns, e.g. before the startup or after an upgrade.
+func CheckSchema(opts *genericoptions.MySQLOptions) error {
+	dbIns, err := opts.NewClient()
+	if err != nil {
+		return err
+	}
+
+	defer db.Close(dbIns)
+
+	return checkSchema(dbIns)
+}
+
+func checkSchema(db *gorm.DB) error {
+	migrator := db.Migrator()
+
+	var errs []error
+	for _, model := range schemaModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if !migrator.HasTable(model) {
+			errs = append(errs, fmt.Errorf("table %s is missing", stmt.Schema.Table))
+
+			continue
+		}
+
+		for _, column := range stmt.Schema.DBNames {
+			if !migrator.HasColumn(model, column) {
+				errs = append(errs, fmt.Errorf("column %s.%s is missing", stmt.Schema.Table, column))
+			}
+		}
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+// policyAuditStatements create the policy_audit table, which keeps the deleted policies, and
+// the trigger which fills it and deletes the attachments of the deleted policies.
+var policyAuditStatements = []string{
+	"CREATE TABLE IF NOT EXISTS `policy_audit` (" +
+		"`id` bigint(20) unsigned NOT NULL, " +
+		"`instanceID` varchar(32) DEFAULT NULL, " +
+		"`name` varchar(45) NOT NULL, " +
+		"`username` varchar(255) NOT NULL, " +
+		"`policyShadow` longtext DEFAULT NULL, " +
+		"`extendShadow` longtext DEFAULT NULL, " +
+		"`createdAt` timestamp NOT NULL DEFAULT current_timestamp(), " +
+		"`updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(), " +
+		"`deletedAt` timestamp NOT NULL DEFAULT current_timestamp(), " +
+		"PRIMARY KEY (`id`), KEY `fk_policy_user_idx` (`username`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8",
+	"DROP TRIGGER IF EXISTS `policy_BEFORE_DELETE`",
+	"CREATE TRIGGER `policy_BEFORE_DELETE` BEFORE DELETE ON `policy` FOR EACH ROW BEGIN " +
+		"insert into policy_audit values(old.id, old.instanceID, old.name, old.username, old.policyShadow, " +
+		"old.extendShadow, old.createdAt, old.updatedAt, curtime()); " +
+		"delete from policy_attachment where username = old.username and policyName = old.name; " +
+		"END",
+}
+
+// nameIndexes are the indexes of the names of the resources owned by the users, which keep the
+// completion of the names fast, see completions.Names.
+var nameIndexes = []struct {
+	model interface{}
+	table string
+	name  string
+	stmt  string
+}{
+	{&v1.Secret{}, "secret", "idx_username_name", "CREATE INDEX `idx_username_name` ON `secret` (`username`, `name`)"},
+	{&v1.Policy{}, "policy", "idx_username_name", "CREATE INDEX `idx_username_name` ON `policy` (`username`, `name`)"},
+}
+
+// MigrateSchema creates the database if it does not exist, and the tables of the models of
+// the store, adding the missing columns of the existing tables.
+func MigrateSchema(opts *genericoptions.MySQLOptions) error {
+	server := *opts
+	server.Database = ""
+	if err := execute(&server, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` DEFAULT CHARACTER SET utf8mb4",
+		opts.Database)); err != nil {
+		return errors.Wrap(err, "create database failed")
+	}
+
+	dbIns, err := opts.NewClient()
+	if err != nil {
+		return err
+	}
+
+	defer db.Close(dbIns)
+
+	return migrateDatabase(dbIns)
+}
+
+// execute runs the statement on a dedicated connection.
+func execute(opts *genericoptions.MySQLOptions, stmt string) error {
+	dbIns, err := opts.NewClient()
+	if err != nil {
+		return err
+	}
+
+	defer db.Close(dbIns)
+
+	return dbIns.Exec(stmt).Error
+}
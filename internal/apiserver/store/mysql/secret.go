@@ -14,6 +14,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
 	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 )
 
@@ -21,27 +23,56 @@ type secrets struct {
 	db *gorm.DB
 }
 
+// secretRow is a listed secret along with the total count, like userRow.
+type secretRow struct {
+	v1.Secret
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// secretList converts the listed rows to a secret list, like userList.
+func secretList(query *gorm.DB, rows []*secretRow, offset int, count bool) (*v1.SecretList, error) {
+	ret := &v1.SecretList{Items: make([]*v1.Secret, 0, len(rows))}
+	for _, row := range rows {
+		ret.Items = append(ret.Items, &row.Secret)
+		ret.TotalCount = row.TotalCount
+	}
+
+	if count && len(rows) == 0 && offset > 0 {
+		if err := query.Model(&v1.Secret{}).Count(&ret.TotalCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
 func newSecrets(ds *datastore) *secrets {
 	return &secrets{ds.db}
 }
 
 // Create creates a new secret.
 func (s *secrets) Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOptions) error {
-	return s.db.Create(&secret).Error
+	db := withContext(s.db, ctx)
+
+	secret.Extend = resourceversion.Init(secret.Extend)
+
+	return db.Create(&secret).Error
 }
 
 // Update updates an secret information by the secret identifier.
 func (s *secrets) Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOptions) error {
-	return s.db.Save(secret).Error
+	return saveVersion(withContext(s.db, ctx), secret, &secret.ObjectMeta, code.ErrSecretNotFound)
 }
 
 // Delete deletes the secret by the secret identifier.
 func (s *secrets) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	db := withContext(s.db, ctx)
+
 	if opts.Unscoped {
-		s.db = s.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	err := s.db.Where("username = ? and name = ?", username, name).Delete(&v1.Secret{}).Error
+	err := db.Where("username = ? and name = ?", username, name).Delete(&v1.Secret{}).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -65,8 +96,10 @@ func (s *secrets) DeleteCollection(
 
 // Get return an secret by the secret identifier.
 func (s *secrets) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Secret, error) {
+	db := withContext(s.db, ctx)
+
 	secret := &v1.Secret{}
-	err := s.db.Where("username = ? and name= ?", username, name).First(&secret).Error
+	err := db.Where("username = ? and name= ?", username, name).First(&secret).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(code.ErrSecretNotFound, err.Error())
@@ -78,26 +111,49 @@ func (s *secrets) Get(ctx context.Context, username, name string, opts metav1.Ge
 	return secret, nil
 }
 
+// secretColumns are the columns of the secret fields which can be selected by equality.
+var secretColumns = map[string]string{
+	"secretID": "secretID",
+}
+
+// secretSortColumns are the columns of the secret fields which the secrets can be sorted by.
+var secretSortColumns = map[string]string{
+	"name":      "name",
+	"expires":   "expires",
+	"createdAt": "createdAt",
+	"updatedAt": "updatedAt",
+}
+
 // List return all secrets.
 func (s *secrets) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.SecretList, error) {
-	ret := &v1.SecretList{}
+	db := withContext(s.db, ctx)
+
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 
 	if username != "" {
-		s.db = s.db.Where("username = ?", username)
+		db = db.Where("username = ?", username)
 	}
 
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	name, _ := selector.RequiresExactMatch("name")
 
-	d := s.db.Where(" name like ?", "%"+name+"%").
+	if name != "" {
+		db = db.Where("name like ?", "%"+name+"%")
+	}
+	db = gormutil.WhereFields(db, selector, secretColumns)
+
+	query := db.Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	sort := pagination.SortFrom(ctx)
+	var rows []*secretRow
+	d := gormutil.OrderBy(gormutil.WithTotalCountIf(query, count), sort.By, sort.Desc, secretSortColumns).
 		Offset(ol.Offset).
 		Limit(ol.Limit).
-		Order("id desc").
-		Find(&ret.Items).
-		Offset(-1).
-		Limit(-1).
-		Count(&ret.TotalCount)
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
 
-	return ret, d.Error
+	return secretList(query, rows, ol.Offset, count)
 }
@@ -0,0 +1,64 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+type secretUsages struct {
+	db *gorm.DB
+}
+
+func newSecretUsages(ds *datastore) *secretUsages {
+	return &secretUsages{ds.db}
+}
+
+// Record records the usages, keeping the later of the recorded and the given times.
+func (s *secretUsages) Record(ctx context.Context, usages []*v1.SecretUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	later := gorm.Expr("GREATEST(lastUsedAt, VALUES(lastUsedAt))")
+	if isSQLite(s.db) {
+		later = gorm.Expr("MAX(lastUsedAt, excluded.lastUsedAt)")
+	}
+
+	return withContext(s.db, ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "secretID"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"lastUsedAt": later,
+		}),
+	}).CreateInBatches(usages, 500).Error
+}
+
+// List returns the usages of the given secrets, the secrets never used are left out.
+func (s *secretUsages) List(ctx context.Context, secretIDs []string) ([]*v1.SecretUsage, error) {
+	ret := make([]*v1.SecretUsage, 0, len(secretIDs))
+	if len(secretIDs) == 0 {
+		return ret, nil
+	}
+
+	if err := withContext(s.db, ctx).Where("secretID in (?)", secretIDs).Find(&ret).Error; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Prune deletes the usages of the deleted secrets.
+func (s *secretUsages) Prune(ctx context.Context) (int64, error) {
+	d := withContext(s.db, ctx).
+		Where("NOT EXISTS (SELECT 1 FROM secret WHERE secret.secretID = secret_usage.secretID)").
+		Delete(&v1.SecretUsage{})
+
+	return d.RowsAffected, d.Error
+}
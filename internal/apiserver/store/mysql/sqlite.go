@@ -0,0 +1,69 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/logger"
+	"github.com/marmotedu/iam/pkg/db"
+)
+
+// sqlitePolicyAuditStatements are the policyAuditStatements in the SQLite dialect.
+var sqlitePolicyAuditStatements = []string{
+	"CREATE TABLE IF NOT EXISTS `policy_audit` (" +
+		"`id` integer NOT NULL PRIMARY KEY, " +
+		"`instanceID` varchar(32) DEFAULT NULL, " +
+		"`name` varchar(45) NOT NULL, " +
+		"`username` varchar(255) NOT NULL, " +
+		"`policyShadow` text DEFAULT NULL, " +
+		"`extendShadow` text DEFAULT NULL, " +
+		"`createdAt` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+		"`updatedAt` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+		"`deletedAt` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP" +
+		")",
+	"CREATE INDEX IF NOT EXISTS `fk_policy_user_idx` ON `policy_audit` (`username`)",
+	"DROP TRIGGER IF EXISTS `policy_BEFORE_DELETE`",
+	"CREATE TRIGGER `policy_BEFORE_DELETE` BEFORE DELETE ON `policy` FOR EACH ROW BEGIN " +
+		"insert into policy_audit values(old.id, old.instanceID, old.name, old.username, old.policyShadow, " +
+		"old.extendShadow, old.createdAt, old.updatedAt, CURRENT_TIMESTAMP); " +
+		"delete from policy_attachment where username = old.username and policyName = old.name; " +
+		"END",
+}
+
+// GetSQLiteFactoryOr creates the factory with the SQLite database in the given file, or in
+// memory if the file is empty, instead of a MySQL database, and migrates its schema. The
+// factory is returned by GetMySQLFactoryOr afterwards, so it must be called first. It is meant
+// for the development, e.g. by the all-in-one iam command, not for the production.
+func GetSQLiteFactoryOr(file string, logLevel int) (store.Factory, error) {
+	var err error
+	var dbIns *gorm.DB
+	once.Do(func() {
+		dbIns, err = db.NewSQLite(file, logger.New(logLevel))
+		if err != nil {
+			return
+		}
+
+		if err = migrateDatabase(dbIns); err != nil {
+			return
+		}
+
+		mysqlFactory = &datastore{dbIns}
+	})
+
+	if mysqlFactory == nil || err != nil {
+		return nil, fmt.Errorf("failed to get sqlite store fatory, mysqlFactory: %+v, error: %w", mysqlFactory, err)
+	}
+
+	return mysqlFactory, nil
+}
+
+// isSQLite reports whether the database is a SQLite one, see GetSQLiteFactoryOr.
+func isSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
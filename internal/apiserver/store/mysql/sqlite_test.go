@@ -0,0 +1,160 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/db"
+)
+
+func newSQLiteStore(t *testing.T) *datastore {
+	t.Helper()
+
+	dbIns, err := db.NewSQLite("", logger.Discard)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close(dbIns) })
+
+	require.NoError(t, migrateDatabase(dbIns))
+	require.NoError(t, checkSchema(dbIns))
+
+	return &datastore{dbIns}
+}
+
+func TestSQLite_secretUsages(t *testing.T) {
+	ds := newSQLiteStore(t)
+	ctx := context.Background()
+
+	earlier := time.Date(2021, 11, 1, 8, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	require.NoError(t, ds.SecretUsages().Record(ctx, []*iamv1.SecretUsage{{SecretID: "s1", LastUsedAt: later}}))
+	require.NoError(t, ds.SecretUsages().Record(ctx, []*iamv1.SecretUsage{{SecretID: "s1", LastUsedAt: earlier}}))
+
+	usages, err := ds.SecretUsages().List(ctx, []string{"s1"})
+	require.NoError(t, err)
+	if assert.Len(t, usages, 1) {
+		assert.True(t, later.Equal(usages[0].LastUsedAt), "the later usage is kept, got %s", usages[0].LastUsedAt)
+	}
+}
+
+func TestSQLite_policyAudit(t *testing.T) {
+	ds := newSQLiteStore(t)
+	ctx := context.Background()
+
+	policy := &v1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "read-articles"},
+		Username:   "colin",
+		Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+			Subjects:  []string{"users:<.*>"},
+			Actions:   []string{"get"},
+			Resources: []string{"resources:articles:<.*>"},
+			Effect:    ladon.AllowAccess,
+		}},
+	}
+	require.NoError(t, ds.Policies().Create(ctx, policy, metav1.CreateOptions{}))
+	require.NoError(t, ds.PolicyAttachments().Create(ctx, &iamv1.PolicyAttachment{
+		Username:   "colin",
+		PolicyName: "read-articles",
+		Subject:    "users:james",
+	}, metav1.CreateOptions{}))
+
+	require.NoError(t, ds.Policies().Delete(ctx, "colin", "read-articles", metav1.DeleteOptions{}))
+
+	// the trigger keeps the deleted policy and deletes its attachments
+	var audits int64
+	require.NoError(t, ds.db.Table("policy_audit").Where("name = ?", "read-articles").Count(&audits).Error)
+	assert.Equal(t, int64(1), audits)
+
+	attachments, err := ds.PolicyAttachments().List(ctx, "colin", metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, attachments.Items)
+}
+
+func TestSQLite_purgeUser(t *testing.T) {
+	ds := newSQLiteStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, ds.AuditEvents().Create(ctx, &iamv1.AuditEvent{
+		Username: "colin", Verb: iamv1.AuditVerbCreate, Resource: "secrets", IP: "10.0.0.1", UserAgent: "iamctl",
+	}, metav1.CreateOptions{}))
+	require.NoError(t, ds.AuditEvents().Create(ctx, &iamv1.AuditEvent{
+		Username: "james", Verb: iamv1.AuditVerbCreate, Resource: "secrets", IP: "10.0.0.2",
+	}, metav1.CreateOptions{}))
+	require.NoError(t, ds.LoginRecords().Create(ctx, &iamv1.LoginRecord{Username: "colin", Success: true},
+		metav1.CreateOptions{}))
+
+	events, err := ds.AuditEvents().Tombstone(ctx, "colin", "purged-user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), events)
+
+	list, err := ds.AuditEvents().List(ctx, iamv1.AuditEventListOptions{
+		ListOptions: metav1.ListOptions{FieldSelector: "username=purged-user"},
+	})
+	require.NoError(t, err)
+	if assert.Len(t, list.Items, 1) {
+		assert.Empty(t, list.Items[0].IP)
+		assert.Empty(t, list.Items[0].UserAgent)
+	}
+
+	logins, err := ds.LoginRecords().DeleteByUser(ctx, "colin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), logins)
+
+	_, err = ds.PolicyAudits().DeleteByUser(ctx, "colin")
+	require.NoError(t, err)
+}
+
+func TestSQLite_tenantSelectors(t *testing.T) {
+	ds := newSQLiteStore(t)
+	ctx := context.Background()
+
+	for name, tenant := range map[string]string{"colin": "marmotedu", "james": "marmotedu", "admin": ""} {
+		user := &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Nickname:   name,
+			Password:   "Admin@2021",
+			Email:      name + "@foxmail.com",
+			Status:     1,
+		}
+		if tenant != "" {
+			user.Extend = metav1.Extend{"tenant": tenant}
+		}
+		require.NoError(t, ds.Users().Create(ctx, user, metav1.CreateOptions{}))
+	}
+	for i, owner := range []string{"colin", "admin"} {
+		require.NoError(t, ds.Policies().Create(ctx, &v1.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("policy%d", i)},
+			Username:   owner,
+			Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+				Subjects:  []string{"users:<.*>"},
+				Actions:   []string{"get"},
+				Resources: []string{"resources:articles:<.*>"},
+				Effect:    ladon.AllowAccess,
+			}},
+		}, metav1.CreateOptions{}))
+	}
+
+	users, err := ds.Users().List(ctx, metav1.ListOptions{FieldSelector: "tenant=marmotedu"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), users.TotalCount)
+
+	policies, err := ds.Policies().List(ctx, "", metav1.ListOptions{FieldSelector: "ownerTenant=marmotedu"})
+	require.NoError(t, err)
+	if assert.Len(t, policies.Items, 1) {
+		assert.Equal(t, "colin", policies.Items[0].Username)
+	}
+}
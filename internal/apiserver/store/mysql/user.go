@@ -14,6 +14,8 @@ import (
 	gorm "gorm.io/gorm"
 
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/pagination"
+	"github.com/marmotedu/iam/internal/pkg/resourceversion"
 	"github.com/marmotedu/iam/internal/pkg/util/gormutil"
 )
 
@@ -21,33 +23,79 @@ type users struct {
 	db *gorm.DB
 }
 
+// userRow is a listed user along with the number of all the users matching the list.
+type userRow struct {
+	v1.User
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// userList converts the listed rows to a user list, the users are counted by query only when
+// the page is empty but not the first one. Nothing is counted unless count is true.
+func userList(query *gorm.DB, rows []*userRow, offset int, count bool) (*v1.UserList, error) {
+	ret := &v1.UserList{Items: make([]*v1.User, 0, len(rows))}
+	for _, row := range rows {
+		ret.Items = append(ret.Items, &row.User)
+		ret.TotalCount = row.TotalCount
+	}
+
+	if count && len(rows) == 0 && offset > 0 {
+		if err := query.Model(&v1.User{}).Count(&ret.TotalCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// userColumns are the columns of the user fields which can be selected by equality.
+var userColumns = map[string]string{
+	"status":  "status",
+	"isAdmin": "isAdmin",
+}
+
+// userSortColumns are the columns of the user fields which the users can be sorted by.
+var userSortColumns = map[string]string{
+	"name":      "name",
+	"nickname":  "nickname",
+	"email":     "email",
+	"loginedAt": "loginedAt",
+	"createdAt": "createdAt",
+	"updatedAt": "updatedAt",
+}
+
 func newUsers(ds *datastore) *users {
 	return &users{ds.db}
 }
 
 // Create creates a new user account.
 func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
-	return u.db.Create(&user).Error
+	db := withContext(u.db, ctx)
+
+	user.Extend = resourceversion.Init(user.Extend)
+
+	return db.Create(&user).Error
 }
 
 // Update updates an user account information.
 func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
-	return u.db.Save(user).Error
+	return saveVersion(withContext(u.db, ctx), user, &user.ObjectMeta, code.ErrUserNotFound)
 }
 
 // Delete deletes the user by the user identifier.
 func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
+	db := withContext(u.db, ctx)
+
 	// delete related policy first
-	pol := newPolicies(&datastore{u.db})
+	pol := newPolicies(&datastore{db})
 	if err := pol.DeleteByUser(ctx, username, opts); err != nil {
 		return err
 	}
 
 	if opts.Unscoped {
-		u.db = u.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	err := u.db.Where("name = ?", username).Delete(&v1.User{}).Error
+	err := db.Where("name = ?", username).Delete(&v1.User{}).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -57,23 +105,27 @@ func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteO
 
 // DeleteCollection batch deletes the users.
 func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
+	db := withContext(u.db, ctx)
+
 	// delete related policy first
-	pol := newPolicies(&datastore{u.db})
+	pol := newPolicies(&datastore{db})
 	if err := pol.DeleteCollectionByUser(ctx, usernames, opts); err != nil {
 		return err
 	}
 
 	if opts.Unscoped {
-		u.db = u.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	return u.db.Where("name in (?)", usernames).Delete(&v1.User{}).Error
+	return db.Where("name in (?)", usernames).Delete(&v1.User{}).Error
 }
 
 // Get return an user by the user identifier.
 func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
+	db := withContext(u.db, ctx)
+
 	user := &v1.User{}
-	err := u.db.Where("name = ? and status = 1", username).First(&user).Error
+	err := db.Where("name = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(code.ErrUserNotFound, err.Error())
@@ -87,26 +139,46 @@ func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions
 
 // List return all users.
 func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
-	ret := &v1.UserList{}
+	db := withContext(u.db, ctx)
+
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 
+	query := db
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
-	username, _ := selector.RequiresExactMatch("name")
-	d := u.db.Where("name like ? and status = 1", "%"+username+"%").
+	// only the available users are listed unless the status is selected, either way the
+	// status filter and the order use idx_status_id
+	if !gormutil.HasField(selector, "status") {
+		query = query.Where("status = 1")
+	}
+	query = gormutil.WhereFields(query, selector, userColumns)
+	// the tenant is kept in the extend fields
+	if name, found := selector.RequiresExactMatch("tenant"); found {
+		query = query.Where("JSON_EXTRACT(extendShadow, '$.tenant') = ?", name)
+	}
+	// a leading wildcard can not use an index, so only filter when a name is given
+	if username, _ := selector.RequiresExactMatch("name"); username != "" {
+		query = query.Where("name like ?", "%"+username+"%")
+	}
+	query = query.Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	sort := pagination.SortFrom(ctx)
+	var rows []*userRow
+	d := gormutil.OrderBy(gormutil.WithTotalCountIf(query, count), sort.By, sort.Desc, userSortColumns).
 		Offset(ol.Offset).
 		Limit(ol.Limit).
-		Order("id desc").
-		Find(&ret.Items).
-		Offset(-1).
-		Limit(-1).
-		Count(&ret.TotalCount)
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
 
-	return ret, d.Error
+	return userList(query, rows, ol.Offset, count)
 }
 
 // ListOptional show a more graceful query method.
 func (u *users) ListOptional(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
-	ret := &v1.UserList{}
+	db := withContext(u.db, ctx)
+
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 
 	where := v1.User{}
@@ -119,15 +191,20 @@ func (u *users) ListOptional(ctx context.Context, opts metav1.ListOptions) (*v1.
 		where.Name = username
 	}
 
-	d := u.db.Where(where).
+	query := db.Where(where).
 		Not(whereNot).
+		Session(&gorm.Session{})
+
+	count := pagination.TotalCount(ctx)
+	sort := pagination.SortFrom(ctx)
+	var rows []*userRow
+	d := gormutil.OrderBy(gormutil.WithTotalCountIf(query, count), sort.By, sort.Desc, userSortColumns).
 		Offset(ol.Offset).
 		Limit(ol.Limit).
-		Order("id desc").
-		Find(&ret.Items).
-		Offset(-1).
-		Limit(-1).
-		Count(&ret.TotalCount)
+		Find(&rows)
+	if d.Error != nil {
+		return nil, d.Error
+	}
 
-	return ret, d.Error
+	return userList(query, rows, ol.Offset, count)
 }
@@ -0,0 +1,21 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// OperationStore defines the long-running operation storage interface.
+type OperationStore interface {
+	Create(ctx context.Context, op *v1.Operation, opts metav1.CreateOptions) error
+	Update(ctx context.Context, op *v1.Operation, opts metav1.UpdateOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Operation, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.OperationList, error)
+}
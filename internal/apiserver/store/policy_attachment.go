@@ -0,0 +1,21 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// PolicyAttachmentStore defines the policy_attachment storage interface.
+type PolicyAttachmentStore interface {
+	Create(ctx context.Context, attachment *v1.PolicyAttachment, opts metav1.CreateOptions) error
+	Delete(ctx context.Context, username, policyName, subject string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, username, policyName, subject string, opts metav1.GetOptions) (*v1.PolicyAttachment, error)
+	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyAttachmentList, error)
+}
@@ -11,4 +11,5 @@ import (
 // PolicyAuditStore defines the policy_audit storage interface.
 type PolicyAuditStore interface {
 	ClearOutdated(ctx context.Context, maxReserveDays int) (int64, error)
+	DeleteByUser(ctx context.Context, username string) (int64, error)
 }
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// QuotaStore defines the tenant quota storage interface.
+type QuotaStore interface {
+	Create(ctx context.Context, quota *v1.Quota, opts metav1.CreateOptions) error
+	Update(ctx context.Context, quota *v1.Quota, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, tenant string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, tenant string, opts metav1.GetOptions) (*v1.Quota, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.QuotaList, error)
+}
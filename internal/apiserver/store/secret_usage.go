@@ -0,0 +1,21 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// SecretUsageStore defines the secret_usage storage interface.
+type SecretUsageStore interface {
+	// Record records the usages, keeping the later of the recorded and the given times.
+	Record(ctx context.Context, usages []*v1.SecretUsage) error
+	// List returns the usages of the given secrets, the secrets never used are left out.
+	List(ctx context.Context, secretIDs []string) ([]*v1.SecretUsage, error)
+	// Prune deletes the usages of the deleted secrets.
+	Prune(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import "context"
+
+// Snapshotter is implemented by the stores which can read all their resources at a single point
+// in time, the other stores are read while they may change.
+type Snapshotter interface {
+	// Snapshot calls fn with a factory reading the resources as they were when it was called.
+	Snapshot(ctx context.Context, fn func(Factory) error) error
+}
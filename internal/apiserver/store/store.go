@@ -4,7 +4,7 @@
 
 package store
 
-//go:generate mockgen -self_package=github.com/marmotedu/iam/internal/apiserver/store -destination mock_store.go -package store github.com/marmotedu/iam/internal/apiserver/store Factory,UserStore,SecretStore,PolicyStore
+//go:generate mockgen -self_package=github.com/marmotedu/iam/internal/apiserver/store -destination mock_store.go -package store github.com/marmotedu/iam/internal/apiserver/store Factory,UserStore,SecretStore,SecretUsageStore,PolicyStore,PolicyAttachmentStore,LoginRecordStore,GroupStore,AuditEventStore,CompletionStore,QuotaStore,OperationStore
 
 var client Factory
 
@@ -12,8 +12,16 @@ var client Factory
 type Factory interface {
 	Users() UserStore
 	Secrets() SecretStore
+	SecretUsages() SecretUsageStore
 	Policies() PolicyStore
 	PolicyAudits() PolicyAuditStore
+	PolicyAttachments() PolicyAttachmentStore
+	Groups() GroupStore
+	LoginRecords() LoginRecordStore
+	AuditEvents() AuditEventStore
+	Completions() CompletionStore
+	Quotas() QuotaStore
+	Operations() OperationStore
 	Close() error
 }
 
@@ -18,10 +18,6 @@ import (
 
 const analyticsKeyName = "iam-system-analytics"
 
-const (
-	recordsBufferForcedFlushInterval = 1 * time.Second
-)
-
 // AnalyticsRecord encodes the details of a authorization request.
 type AnalyticsRecord struct {
 	TimeStamp  int64     `json:"timestamp"`
@@ -127,14 +123,16 @@ func (r *Analytics) RecordHit(record *AnalyticsRecord) error {
 func (r *Analytics) recordWorker() {
 	defer r.poolWg.Done()
 
-	// this is buffer to send one pipelined command to redis
-	// use r.recordsBufferSize as cap to reduce slice re-allocations
+	// this is buffer to send one command to redis
+	// use r.workerBufferSize as cap to reduce slice re-allocations
 	recordsBuffer := make([][]byte, 0, r.workerBufferSize)
 
-	// read records from channel and process
-	lastSentTS := time.Now()
+	// the buffered records are sent when the buffer is full, or at least once per flush
+	// interval, a single ticker avoids a new timer per record at high decision rates
+	ticker := time.NewTicker(time.Duration(r.recordsBufferFlushInterval) * time.Millisecond)
+	defer ticker.Stop()
+
 	for {
-		var readyToSend bool
 		select {
 		case record, ok := <-r.recordsChan:
 			// check if channel was closed and it is time to exit from worker
@@ -146,27 +144,23 @@ func (r *Analytics) recordWorker() {
 			}
 
 			// we have new record - prepare it and add to buffer
-
 			if encoded, err := msgpack.Marshal(record); err != nil {
 				log.Errorf("Error encoding analytics data: %s", err.Error())
 			} else {
 				recordsBuffer = append(recordsBuffer, encoded)
 			}
 
-			// identify that buffer is ready to be sent
-			readyToSend = uint64(len(recordsBuffer)) == r.workerBufferSize
+			if uint64(len(recordsBuffer)) < r.workerBufferSize {
+				continue
+			}
 
-		case <-time.After(time.Duration(r.recordsBufferFlushInterval) * time.Millisecond):
-			// nothing was received for that period of time
-			// anyways send whatever we have, don't hold data too long in buffer
-			readyToSend = true
+		case <-ticker.C:
 		}
 
 		// send data to Redis and reset buffer
-		if len(recordsBuffer) > 0 && (readyToSend || time.Since(lastSentTS) >= recordsBufferForcedFlushInterval) {
+		if len(recordsBuffer) > 0 {
 			r.store.AppendToSetPipelined(analyticsKeyName, recordsBuffer)
 			recordsBuffer = recordsBuffer[:0]
-			lastSentTS = time.Now()
 		}
 	}
 }
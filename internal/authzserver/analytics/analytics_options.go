@@ -45,6 +45,12 @@ func (o *AnalyticsOptions) Validate() []error {
 		errors = append(errors, fmt.Errorf("--analytics.flush-interval %v must be between 1 and 1000", o.FlushInterval))
 	}
 
+	// every worker buffers its share of the records
+	if o.Enable && (o.PoolSize < 1 || o.RecordsBufferSize < uint64(o.PoolSize)) {
+		errors = append(errors, fmt.Errorf("--analytics.records-buffer-size %v must be at least --analytics.pool-size %v",
+			o.RecordsBufferSize, o.PoolSize))
+	}
+
 	return errors
 }
 
@@ -64,6 +70,10 @@ func (o *AnalyticsOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.Uint64Var(&o.RecordsBufferSize, "analytics.records-buffer-size", o.RecordsBufferSize,
 		"Specifies buffer size for pool workers (size of each pipeline operation).")
 
+	fs.Uint64Var(&o.FlushInterval, "analytics.flush-interval", o.FlushInterval, ""+
+		"Specifies the interval in milliseconds at which the buffered records of a pool worker are sent "+
+		"to redis in a single command, if its buffer is not full before. Must be between 1 and 1000.")
+
 	fs.BoolVar(&o.EnableDetailedRecording, "analytics.enable-detailed-recording", o.EnableDetailedRecording,
 		"Enable detailed analytics at the key level.")
 
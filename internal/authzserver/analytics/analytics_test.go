@@ -0,0 +1,76 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// fakeStore records the batches of the appended records.
+type fakeStore struct {
+	storage.AnalyticsHandler
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (f *fakeStore) Connect() bool {
+	return true
+}
+
+func (f *fakeStore) AppendToSetPipelined(key string, values [][]byte) {
+	if len(values) == 0 {
+		return
+	}
+
+	batch := make([]string, 0, len(values))
+	for _, value := range values {
+		var record AnalyticsRecord
+		_ = msgpack.Unmarshal(value, &record)
+		batch = append(batch, record.Username)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, batch)
+}
+
+func (f *fakeStore) Batches() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.batches
+}
+
+func TestAnalytics_RecordHit(t *testing.T) {
+	store := &fakeStore{}
+	opts := &AnalyticsOptions{PoolSize: 1, RecordsBufferSize: 3, FlushInterval: 50}
+	r := NewAnalytics(opts, store)
+	r.Start()
+
+	for _, username := range []string{"colin", "peter", "maria", "ken"} {
+		_ = r.RecordHit(&AnalyticsRecord{Username: username})
+	}
+
+	// a full buffer is sent at once, the rest once per flush interval
+	deadline := time.Now().Add(time.Second)
+	for len(store.Batches()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_ = r.RecordHit(&AnalyticsRecord{Username: "tom"})
+	r.Stop()
+
+	want := [][]string{{"colin", "peter", "maria"}, {"ken"}, {"tom"}}
+	if got := store.Batches(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+}
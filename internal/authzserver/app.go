@@ -6,9 +6,18 @@
 package authzserver
 
 import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/marmotedu/errors"
+
 	"github.com/marmotedu/iam/internal/authzserver/config"
 	"github.com/marmotedu/iam/internal/authzserver/options"
 	"github.com/marmotedu/iam/pkg/app"
+	"github.com/marmotedu/iam/pkg/featuregate"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -30,6 +39,10 @@ func NewApp(basename string) *app.App {
 		app.WithDescription(commandDesc),
 		app.WithDefaultValidArgs(),
 		app.WithRunFunc(run(opts)),
+		app.WithReloaders(reloaders(opts)),
+		app.WithFeatureGate(featuregate.DefaultFeatureGate),
+		app.WithChecks(checks(opts)...),
+		app.WithCommands(newBenchCommand()),
 	)
 
 	return application
@@ -40,6 +53,8 @@ func run(opts *options.Options) app.RunFunc {
 		log.Init(opts.Log)
 		defer log.Flush()
 
+		opts.RuntimeOptions.Apply()
+
 		cfg, err := config.CreateConfigFromOptions(opts)
 		if err != nil {
 			return err
@@ -48,3 +63,61 @@ func run(opts *options.Options) app.RunFunc {
 		return Run(cfg)
 	}
 }
+
+// reloaders returns the configuration items applied at runtime when the configuration
+// file changes, besides the log level and the feature gates applied by every app. The running
+// options are updated from the reloaded ones.
+func reloaders(opts *options.Options) map[string]app.Reloader {
+	return map[string]app.Reloader{
+		"rate-limit": func(reloaded app.CliOptions) error {
+			o, _ := reloaded.(*options.Options)
+
+			return opts.RateLimitOptions.Reload(o.RateLimitOptions)
+		},
+		"server.cors-origins": func(reloaded app.CliOptions) error {
+			o, _ := reloaded.(*options.Options)
+
+			return o.GenericServerRunOptions.ReloadCorsOrigins()
+		},
+	}
+}
+
+// checks returns the startup checks run in dry-run mode.
+func checks(opts *options.Options) []app.Check {
+	return []app.Check{
+		{Name: "redis", Run: func() error { return opts.RedisOptions.Check() }},
+		{Name: "tls", Run: func() error { return opts.SecureServing.Check() }},
+		{Name: "client-ca-file", Run: func() error { return checkClientCA(opts.ClientCA) }},
+		{Name: "rpcserver", Run: func() error { return checkRPCServers(opts.RPCServer) }},
+	}
+}
+
+// checkClientCA verifies the file holds at least one PEM encoded certificate.
+func checkClientCA(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return fmt.Errorf("no certificate found in %s", file)
+	}
+
+	return nil
+}
+
+// checkRPCServers verifies every apiserver address accepts connections.
+func checkRPCServers(addrs []string) error {
+	var errs []error
+	for _, addr := range addrs {
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		_ = conn.Close()
+	}
+
+	return errors.NewAggregate(errs)
+}
@@ -8,37 +8,220 @@ import (
 	authzv1 "github.com/marmotedu/api/authz/v1"
 	"github.com/ory/ladon"
 
+	// register the iam specific conditions.
+	_ "github.com/marmotedu/iam/internal/pkg/condition"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
 // Authorizer implement the authorize interface that use local repository to
 // authorize the subject access review.
 type Authorizer struct {
-	warden ladon.Warden
+	warden    *ladon.Ladon
+	enrichers []Enricher
+	decisions *DecisionCache
+	// tenantRequired denies the requests which do not carry a tenant.
+	tenantRequired bool
+	memberships    MembershipGetter
+	tags           TagGetter
+	externals      []ExternalAuthorizer
+	recorder       *Recorder
+}
+
+// Option configures an Authorizer.
+type Option func(*Authorizer)
+
+// WithEnrichers sets the enrichers which are run in order on every request before the
+// policies are evaluated.
+func WithEnrichers(enrichers ...Enricher) Option {
+	return func(a *Authorizer) {
+		a.enrichers = enrichers
+	}
+}
+
+// WithDecisionCache sets the cache used to reuse the decisions of identical requests.
+func WithDecisionCache(decisions *DecisionCache) Option {
+	return func(a *Authorizer) {
+		a.decisions = decisions
+	}
+}
+
+// WithTenantRequired denies the requests which do not carry a tenant in their context, so
+// that they can not fall back to the policies of the default tenant.
+func WithTenantRequired(required bool) Option {
+	return func(a *Authorizer) {
+		a.tenantRequired = required
+	}
+}
+
+// WithMemberships matches the request subject against the policies of the groups and roles
+// it belongs to as well.
+func WithMemberships(memberships MembershipGetter) Option {
+	return func(a *Authorizer) {
+		a.memberships = memberships
+	}
+}
+
+// WithTags adds the tags of the request subject and resource to the request context, when
+// they name a user, a secret or a policy known to iam.
+func WithTags(tags TagGetter) Option {
+	return func(a *Authorizer) {
+		a.tags = tags
+	}
+}
+
+// WithExternalAuthorizers sets the external authorizers which are consulted in order after
+// the policies are evaluated.
+func WithExternalAuthorizers(externals ...ExternalAuthorizer) Option {
+	return func(a *Authorizer) {
+		a.externals = externals
+	}
+}
+
+// WithRecorder records the requests along with the decisions of the policies.
+func WithRecorder(recorder *Recorder) Option {
+	return func(a *Authorizer) {
+		a.recorder = recorder
+	}
 }
 
 // NewAuthorizer creates a local repository authorizer and returns it.
-func NewAuthorizer(authorizationClient AuthorizationInterface) *Authorizer {
-	return &Authorizer{
+func NewAuthorizer(authorizationClient AuthorizationInterface, opts ...Option) *Authorizer {
+	a := &Authorizer{
 		warden: &ladon.Ladon{
 			Manager:     NewPolicyManager(authorizationClient),
 			AuditLogger: NewAuditLogger(authorizationClient),
 		},
 	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
 }
 
 // Authorize to determine the subject access.
 func (a *Authorizer) Authorize(request *ladon.Request) *authzv1.Response {
+	rsp := a.authorize(request)
+
+	decision := "deny"
+	if rsp.Allowed {
+		decision = "allow"
+	}
+	decisions.WithLabelValues(decision).Inc()
+
+	return rsp
+}
+
+func (a *Authorizer) authorize(request *ladon.Request) *authzv1.Response {
+	if request.Context == nil {
+		request.Context = ladon.Context{}
+	}
+
+	// conditions may depend on the enriched context, so never evaluate a partially enriched request
+	for _, enricher := range a.enrichers {
+		if err := enricher.Enrich(request); err != nil {
+			log.Errorf("enrich request context failed: %s", err.Error())
+
+			return &authzv1.Response{
+				Denied: true,
+				Reason: "Request context enrichment failed",
+			}
+		}
+	}
+
+	// the tenant may be set by an enricher
+	if name, ok := tenant.FromRequest(request); !ok || (a.tenantRequired && name == "") {
+		return &authzv1.Response{
+			Denied: true,
+			Reason: "Request tenant is missing or invalid",
+		}
+	}
+
+	if a.tags != nil {
+		addTags(request, a.tags)
+	}
+
 	log.Debug("authorize request", log.Any("request", request))
 
-	if err := a.warden.IsAllowed(request); err != nil {
+	epoch := a.decisions.Epoch()
+
+	var groups []string
+	if a.memberships != nil {
+		username, _ := request.Context["username"].(string)
+		groups = a.memberships.GetGroups(username, request.Subject)
+	}
+
+	policies, err := a.findCandidates(request, groups)
+	if err != nil {
 		return &authzv1.Response{
 			Denied: true,
 			Reason: err.Error(),
 		}
 	}
 
-	return &authzv1.Response{
-		Allowed: true,
+	policies = expandGroups(request, policies, groups)
+
+	key := a.decisions.Key(request, policies)
+	if decision, ok := a.decisions.Get(key); ok {
+		// keep the audit trail of the cached decisions
+		if decision.Response.Allowed {
+			a.warden.AuditLogger.LogGrantedAccessRequest(request, policies, decision.Deciders)
+		} else {
+			a.warden.AuditLogger.LogRejectedAccessRequest(request, policies, decision.Deciders)
+		}
+		a.recorder.Record(request, decision)
+
+		return a.consult(request, decision)
+	}
+
+	decision := a.decide(request, policies)
+	a.decisions.Set(key, epoch, decision)
+	a.recorder.Record(request, decision)
+
+	// the external verdicts are not cached, they may depend on facts unknown to iam
+	return a.consult(request, decision)
+}
+
+// candidateFinder is implemented by the managers which find the candidates of the groups of the
+// request subject as well.
+type candidateFinder interface {
+	FindCandidates(r *ladon.Request, groups []string) (ladon.Policies, error)
+}
+
+func (a *Authorizer) findCandidates(request *ladon.Request, groups []string) (ladon.Policies, error) {
+	if finder, ok := a.warden.Manager.(candidateFinder); ok {
+		return finder.FindCandidates(request, groups)
+	}
+
+	return a.warden.Manager.FindRequestCandidates(request)
+}
+
+// decide evaluates the policies against the request.
+func (a *Authorizer) decide(request *ladon.Request, policies ladon.Policies) *Decision {
+	// shadow policies never take part in the actual decision
+	enforced, shadows := splitShadow(policies)
+
+	recorder := &deciderRecorder{next: a.warden.AuditLogger}
+	warden := &ladon.Ladon{AuditLogger: recorder}
+	err := warden.DoPoliciesAllow(request, enforced)
+	evaluateShadow(request, shadows, err)
+
+	if err != nil {
+		return &Decision{
+			Response: authzv1.Response{
+				Denied: true,
+				Reason: err.Error(),
+			},
+			Deciders: recorder.deciders,
+		}
+	}
+
+	return &Decision{
+		Response: authzv1.Response{
+			Allowed: true,
+		},
+		Deciders: recorder.deciders,
 	}
 }
@@ -22,6 +22,12 @@ type PolicyGetter interface {
 	GetPolicy(key string) ([]*ladon.DefaultPolicy, error)
 }
 
+// CandidateGetter is implemented by the policy getters which index the policies, so that
+// only the policies which may match a request are returned.
+type CandidateGetter interface {
+	GetCandidates(key string, subjects []string, resource string) ([]*ladon.DefaultPolicy, error)
+}
+
 // Authorization implements authorization.AuthorizationInterface interface.
 type Authorization struct {
 	getter PolicyGetter
@@ -67,6 +73,16 @@ func (auth *Authorization) List(username string) ([]*ladon.DefaultPolicy, error)
 	return auth.getter.GetPolicy(username)
 }
 
+// ListCandidates returns the policies under the key which may match one of the subjects and
+// the resource, all of them when the getter does not index the policies.
+func (auth *Authorization) ListCandidates(key string, subjects []string, resource string) ([]*ladon.DefaultPolicy, error) {
+	if getter, ok := auth.getter.(CandidateGetter); ok {
+		return getter.GetCandidates(key, subjects, resource)
+	}
+
+	return auth.getter.GetPolicy(key)
+}
+
 // LogRejectedAccessRequest write rejected subject access to redis.
 func (auth *Authorization) LogRejectedAccessRequest(r *ladon.Request, p ladon.Policies, d ladon.Policies) {
 	var conclusion string
@@ -5,12 +5,17 @@
 package authorization
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
 	gomock "github.com/golang/mock/gomock"
 	authzv1 "github.com/marmotedu/api/authz/v1"
 	"github.com/ory/ladon"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/marmotedu/iam/internal/pkg/condition"
+	"github.com/marmotedu/iam/internal/pkg/tags"
 )
 
 func TestNewAuthorizer(t *testing.T) {
@@ -157,3 +162,452 @@ func TestAuthorizer_Authorize(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizer_AuthorizeWithEnrichers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockAuthz.EXPECT().List(gomock.Any()).Return([]*ladon.DefaultPolicy{{
+		ID:         "68819e5a-738b-41ec-b03c-b58a1b19d043",
+		Subjects:   []string{"users:peter"},
+		Resources:  []string{"resources:printer"},
+		Actions:    []string{"print"},
+		Effect:     ladon.AllowAccess,
+		Conditions: ladon.Conditions{"tenant": &ladon.StringEqualCondition{Equals: "marmotedu"}},
+	}}, nil)
+
+	tenant := enricherFunc(func(r *ladon.Request) error {
+		r.Context["tenant"] = "marmotedu"
+
+		return nil
+	})
+	broken := enricherFunc(func(r *ladon.Request) error {
+		return errors.New("redis is down")
+	})
+
+	tests := []struct {
+		name      string
+		enrichers []Enricher
+		want      *authzv1.Response
+	}{
+		{
+			name:      "enriched",
+			enrichers: []Enricher{tenant},
+			want: &authzv1.Response{
+				Allowed: true,
+			},
+		},
+		{
+			name:      "enrich_failed",
+			enrichers: []Enricher{tenant, broken},
+			want: &authzv1.Response{
+				Denied: true,
+				Reason: "Request context enrichment failed",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthorizer(mockAuthz, WithEnrichers(tt.enrichers...))
+			request := &ladon.Request{
+				Subject:  "users:peter",
+				Action:   "print",
+				Resource: "resources:printer",
+				Context: ladon.Context{
+					"username": "colin",
+				},
+			}
+			if got := a.Authorize(request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorizer.Authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type enricherFunc func(r *ladon.Request) error
+
+func (f enricherFunc) Enrich(r *ladon.Request) error {
+	return f(r)
+}
+
+func TestAuthorizer_AuthorizeWithShadowPolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	allow := &ladon.DefaultPolicy{
+		ID:        "allow",
+		Subjects:  []string{"users:peter"},
+		Resources: []string{"resources:printer"},
+		Actions:   []string{"delete"},
+		Effect:    ladon.AllowAccess,
+	}
+	deny := &ladon.DefaultPolicy{
+		ID:        "deny",
+		Subjects:  []string{"users:peter"},
+		Resources: []string{"resources:printer"},
+		Actions:   []string{"delete"},
+		Effect:    ladon.DenyAccess,
+		Meta:      []byte(`{"shadow":true}`),
+	}
+	shadowAllow := &ladon.DefaultPolicy{
+		ID:        "shadow-allow",
+		Subjects:  []string{"users:peter"},
+		Resources: []string{"resources:printer"},
+		Actions:   []string{"delete"},
+		Effect:    ladon.AllowAccess,
+		Meta:      []byte(`{"shadow":true}`),
+	}
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockAuthz.EXPECT().LogRejectedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	gomock.InOrder(
+		mockAuthz.EXPECT().List(gomock.Any()).Return([]*ladon.DefaultPolicy{allow, deny}, nil),
+		mockAuthz.EXPECT().List(gomock.Any()).Return([]*ladon.DefaultPolicy{shadowAllow}, nil),
+	)
+
+	tests := []struct {
+		name     string
+		want     *authzv1.Response
+		decision string
+	}{
+		{
+			name: "shadow_deny",
+			want: &authzv1.Response{
+				Allowed: true,
+			},
+			decision: "deny",
+		},
+		{
+			name: "shadow_allow",
+			want: &authzv1.Response{
+				Denied: true,
+				Reason: ladon.ErrRequestDenied.Error(),
+			},
+			decision: "allow",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(shadowDecisions.WithLabelValues(tt.decision))
+
+			a := NewAuthorizer(mockAuthz)
+			request := &ladon.Request{
+				Subject:  "users:peter",
+				Action:   "delete",
+				Resource: "resources:printer",
+			}
+			if got := a.Authorize(request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorizer.Authorize() = %v, want %v", got, tt.want)
+			}
+
+			if got := testutil.ToFloat64(shadowDecisions.WithLabelValues(tt.decision)) - before; got != 1 {
+				t.Errorf("shadow %s decisions = %v, want 1", tt.decision, got)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_AuthorizeWithTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	// only the partition of the request tenant is evaluated
+	mockAuthz.EXPECT().List(gomock.Eq("colin/marmotedu")).Return([]*ladon.DefaultPolicy{{
+		ID:        "68819e5a-738b-41ec-b03c-b58a1b19d043",
+		Subjects:  []string{"<.*>"},
+		Resources: []string{"<.*>"},
+		Actions:   []string{"<.*>"},
+		Effect:    ladon.AllowAccess,
+		Meta:      []byte(`{"tenant":"marmotedu"}`),
+	}}, nil)
+
+	missing := &authzv1.Response{
+		Denied: true,
+		Reason: "Request tenant is missing or invalid",
+	}
+	tests := []struct {
+		name    string
+		context ladon.Context
+		want    *authzv1.Response
+	}{
+		{
+			name:    "tenant",
+			context: ladon.Context{"username": "colin", "tenant": "marmotedu"},
+			want:    &authzv1.Response{Allowed: true},
+		},
+		{
+			name:    "missing_tenant",
+			context: ladon.Context{"username": "colin"},
+			want:    missing,
+		},
+		{
+			name:    "invalid_tenant",
+			context: ladon.Context{"username": "colin", "tenant": 1},
+			want:    missing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthorizer(mockAuthz, WithTenantRequired(true))
+			request := &ladon.Request{
+				Subject:  "users:peter",
+				Action:   "delete",
+				Resource: "resources:printer",
+				Context:  tt.context,
+			}
+			if got := a.Authorize(request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorizer.Authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type membershipFunc func(username, member string) []string
+
+func (f membershipFunc) GetGroups(username, member string) []string {
+	return f(username, member)
+}
+
+func TestAuthorizer_AuthorizeWithMemberships(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupPolicy := &ladon.DefaultPolicy{
+		ID:        "68819e5a-738b-41ec-b03c-b58a1b19d043",
+		Subjects:  []string{"groups:<admins|dev>"},
+		Resources: []string{"resources:printer"},
+		Actions:   []string{"delete"},
+		Effect:    ladon.AllowAccess,
+	}
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockAuthz.EXPECT().LogRejectedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	mockAuthz.EXPECT().List(gomock.Eq("colin")).AnyTimes().Return([]*ladon.DefaultPolicy{groupPolicy}, nil)
+
+	memberships := membershipFunc(func(username, member string) []string {
+		if username == "colin" && member == "users:peter" {
+			return []string{"groups:admins", "roles:auditor"}
+		}
+
+		return []string{"groups:ops"}
+	})
+
+	denied := &authzv1.Response{
+		Denied: true,
+		Reason: "Request was denied by default",
+	}
+	tests := []struct {
+		name    string
+		subject string
+		want    *authzv1.Response
+	}{
+		{name: "group_member", subject: "users:peter", want: &authzv1.Response{Allowed: true}},
+		{name: "other_group_member", subject: "users:maria", want: denied},
+		// a subject with delimiters must not turn into a pattern matching every member
+		{name: "pattern_subject", subject: "users:<.*>", want: denied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthorizer(mockAuthz, WithMemberships(memberships))
+			request := &ladon.Request{
+				Subject:  tt.subject,
+				Action:   "delete",
+				Resource: "resources:printer",
+				Context:  ladon.Context{"username": "colin"},
+			}
+			if got := a.Authorize(request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorizer.Authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the cached policy is never changed by the expansion
+	if !reflect.DeepEqual(groupPolicy.Subjects, []string{"groups:<admins|dev>"}) {
+		t.Errorf("cached policy subjects changed to %v", groupPolicy.Subjects)
+	}
+}
+
+type tagMap map[string]tags.Tags
+
+func (m tagMap) GetTags(_, name string) (tags.Tags, bool) {
+	t, ok := m[name]
+
+	return t, ok
+}
+
+func TestAuthorizer_AuthorizeWithTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ownerPolicy := &ladon.DefaultPolicy{
+		ID:        "68819e5a-738b-41ec-b03c-b58a1b19d043",
+		Subjects:  []string{"users:<.*>"},
+		Resources: []string{"secrets:<.*>"},
+		Actions:   []string{"delete"},
+		Effect:    ladon.AllowAccess,
+		Conditions: ladon.Conditions{
+			tags.ResourceKey: &condition.TagEqualsCondition{Key: "owner", Subject: true},
+		},
+	}
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	mockAuthz.EXPECT().LogRejectedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	mockAuthz.EXPECT().List(gomock.Eq("colin")).AnyTimes().Return([]*ladon.DefaultPolicy{ownerPolicy}, nil)
+
+	known := tagMap{"secrets:id0": {"owner": "users:peter"}}
+
+	denied := &authzv1.Response{
+		Denied: true,
+		Reason: "Request was denied by default",
+	}
+	tests := []struct {
+		name     string
+		subject  string
+		resource string
+		tags     tags.Tags
+		want     *authzv1.Response
+	}{
+		{name: "owner", subject: "users:peter", resource: "secrets:id0", want: &authzv1.Response{Allowed: true}},
+		{name: "not_owner", subject: "users:maria", resource: "secrets:id0", want: denied},
+		// the tags known to iam can not be overridden by the request
+		{
+			name:     "spoofed",
+			subject:  "users:maria",
+			resource: "secrets:id0",
+			tags:     tags.Tags{"owner": "users:maria"},
+			want:     denied,
+		},
+		{
+			name:     "unknown_resource",
+			subject:  "users:maria",
+			resource: "secrets:external",
+			tags:     tags.Tags{"owner": "users:maria"},
+			want:     &authzv1.Response{Allowed: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthorizer(mockAuthz, WithTags(known))
+			request := &ladon.Request{
+				Subject:  tt.subject,
+				Action:   "delete",
+				Resource: tt.resource,
+				Context:  ladon.Context{"username": "colin"},
+			}
+			if tt.tags != nil {
+				request.Context[tags.ResourceKey] = tt.tags
+			}
+			if got := a.Authorize(request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorizer.Authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type externalFunc func(request *ladon.Request, allowed bool) (Verdict, string, error)
+
+func (f externalFunc) Name() string {
+	return "test"
+}
+
+func (f externalFunc) Authorize(request *ladon.Request, allowed bool) (Verdict, string, error) {
+	return f(request, allowed)
+}
+
+func TestAuthorizer_AuthorizeWithExternalAuthorizers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockAuthz.EXPECT().LogRejectedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockAuthz.EXPECT().List(gomock.Eq("colin")).AnyTimes().Return([]*ladon.DefaultPolicy{
+		{
+			ID:        "68819e5a-738b-41ec-b03c-b58a1b19d043",
+			Subjects:  []string{"users:peter"},
+			Resources: []string{"resources:<printer|secrets>"},
+			Actions:   []string{"delete"},
+			Effect:    ladon.AllowAccess,
+		},
+		{
+			ID:        "a8e1f2b4-1f4b-4c6a-9d2f-0a7a1c2d3e4f",
+			Subjects:  []string{"users:peter"},
+			Resources: []string{"resources:secrets"},
+			Actions:   []string{"delete"},
+			Effect:    ladon.DenyAccess,
+		},
+	}, nil)
+
+	allow := externalFunc(func(*ladon.Request, bool) (Verdict, string, error) {
+		return VerdictAllow, "", nil
+	})
+	deny := externalFunc(func(*ladon.Request, bool) (Verdict, string, error) {
+		return VerdictDeny, "Request was denied by the ticket system", nil
+	})
+	abstain := externalFunc(func(*ladon.Request, bool) (Verdict, string, error) {
+		return VerdictAbstain, "", nil
+	})
+	failed := externalFunc(func(*ladon.Request, bool) (Verdict, string, error) {
+		return "", "", errors.New("connection refused")
+	})
+
+	tests := []struct {
+		name      string
+		resource  string
+		externals []ExternalAuthorizer
+		want      *authzv1.Response
+	}{
+		{
+			name:      "all_abstain",
+			resource:  "resources:printer",
+			externals: []ExternalAuthorizer{abstain, abstain},
+			want:      &authzv1.Response{Allowed: true},
+		},
+		{
+			name:      "first_verdict_wins",
+			resource:  "resources:printer",
+			externals: []ExternalAuthorizer{abstain, deny, allow},
+			want:      &authzv1.Response{Denied: true, Reason: "Request was denied by the ticket system"},
+		},
+		{
+			name:      "allow_denied_by_default",
+			resource:  "resources:articles",
+			externals: []ExternalAuthorizer{allow},
+			want:      &authzv1.Response{Allowed: true},
+		},
+		{
+			name:      "failed",
+			resource:  "resources:printer",
+			externals: []ExternalAuthorizer{failed, allow},
+			want:      &authzv1.Response{Denied: true, Reason: "External authorizer test failed"},
+		},
+		{
+			// an explicit deny policy can not be overridden
+			name:      "forcefully_denied",
+			resource:  "resources:secrets",
+			externals: []ExternalAuthorizer{allow},
+			want:      &authzv1.Response{Denied: true, Reason: ladon.ErrRequestForcefullyDenied.Error()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthorizer(mockAuthz, WithExternalAuthorizers(tt.externals...))
+			request := &ladon.Request{
+				Subject:  "users:peter",
+				Action:   "delete",
+				Resource: tt.resource,
+				Context:  ladon.Context{"username": "colin"},
+			}
+			if got := a.Authorize(request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorizer.Authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/condition"
+	"github.com/marmotedu/iam/internal/pkg/features"
+	"github.com/marmotedu/iam/internal/pkg/shadow"
+)
+
+// Decision is the result of evaluating the policies against a request.
+type Decision struct {
+	Response authzv1.Response
+	// Deciders are the policies which allowed or denied the request.
+	Deciders ladon.Policies
+}
+
+func (d *Decision) response() *authzv1.Response {
+	rsp := d.Response
+
+	return &rsp
+}
+
+// decisionEntry is a cached decision along with the policy epoch it was made in.
+type decisionEntry struct {
+	epoch    uint64
+	decision *Decision
+}
+
+// DecisionCache caches the decisions keyed by the hash of the normalized request. Entries
+// expire after a short ttl, and are invalidated whenever the policy epoch changes.
+// A nil DecisionCache caches nothing.
+type DecisionCache struct {
+	cache *ristretto.Cache
+	ttl   time.Duration
+	epoch func() uint64
+}
+
+// NewDecisionCache creates a DecisionCache. epoch returns the current policy epoch, which
+// changes every time the policies are reloaded. It returns nil when the cache is disabled,
+// the DecisionCache feature gate is checked on every request, as it can change at runtime.
+func NewDecisionCache(opts *DecisionCacheOptions, epoch func() uint64) (*DecisionCache, error) {
+	if opts == nil || !opts.Enable {
+		return nil, nil
+	}
+
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: opts.MaxEntries * 10,
+		MaxCost:     opts.MaxEntries,
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecisionCache{
+		cache: cache,
+		ttl:   opts.TTL,
+		epoch: epoch,
+	}, nil
+}
+
+// Key returns the cache key of the request, or an empty string when the decision must not
+// be cached: the DecisionCache feature gate is disabled, the policies contain shadow policies,
+// which must be evaluated on every request, or conditions whose result does not only depend
+// on the request.
+func (d *DecisionCache) Key(request *ladon.Request, policies ladon.Policies) string {
+	if d == nil || !features.Enabled(features.DecisionCache) {
+		return ""
+	}
+
+	for _, policy := range policies {
+		if shadow.IsShadow(policy) {
+			return ""
+		}
+
+		for _, c := range policy.GetConditions() {
+			if condition.IsStateful(c) {
+				return ""
+			}
+		}
+	}
+
+	h := keyHashes.Get().(*keyHash)
+	defer keyHashes.Put(h)
+
+	if !h.write(request) {
+		return ""
+	}
+
+	return hex.EncodeToString(h.Sum(h.sum[:0]))
+}
+
+// Epoch returns the current policy epoch.
+func (d *DecisionCache) Epoch() uint64 {
+	if d == nil {
+		return 0
+	}
+
+	return d.epoch()
+}
+
+// Get returns the cached decision of the key made in the current policy epoch.
+func (d *DecisionCache) Get(key string) (*Decision, bool) {
+	if d == nil || key == "" {
+		return nil, false
+	}
+
+	value, ok := d.cache.Get(key)
+	if entry, valid := value.(*decisionEntry); ok && valid && entry.epoch == d.epoch() {
+		decisionCacheRequests.WithLabelValues("hit").Inc()
+
+		return entry.decision, true
+	}
+
+	decisionCacheRequests.WithLabelValues("miss").Inc()
+
+	return nil, false
+}
+
+// Set caches the decision of the key made in the given policy epoch, which must be read
+// before the policies are, so that a decision made with outdated policies never hits.
+func (d *DecisionCache) Set(key string, epoch uint64, decision *Decision) {
+	if d == nil || key == "" {
+		return
+	}
+
+	d.cache.SetWithTTL(key, &decisionEntry{epoch: epoch, decision: decision}, 1, d.ttl)
+}
+
+// keyHash hashes the requests into the decision cache keys. The fields and the context
+// values are appended to a reused buffer one by one, instead of marshaling the request on
+// every call.
+type keyHash struct {
+	hash.Hash
+	keys []string
+	buf  []byte
+	sum  [sha256.Size]byte
+}
+
+var keyHashes = sync.Pool{
+	New: func() interface{} {
+		return &keyHash{Hash: sha256.New()}
+	},
+}
+
+// write resets the hash and writes the request, it returns false when a context value can
+// not be marshaled.
+func (h *keyHash) write(request *ladon.Request) bool {
+	h.buf = h.buf[:0]
+	h.appendString('r', request.Resource)
+	h.appendString('a', request.Action)
+	h.appendString('s', request.Subject)
+
+	// the context keys are sorted, so identical requests have the same hash
+	h.keys = h.keys[:0]
+	for k := range request.Context {
+		h.keys = append(h.keys, k)
+	}
+	sort.Strings(h.keys)
+
+	for _, k := range h.keys {
+		h.appendString('k', k)
+
+		switch v := request.Context[k].(type) {
+		case string:
+			h.appendString('s', v)
+		case bool:
+			h.buf = strconv.AppendBool(append(h.buf, 'b'), v)
+		case float64:
+			h.buf = strconv.AppendFloat(append(h.buf, 'f'), v, 'g', -1, 64)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return false
+			}
+			h.appendString('j', string(data))
+		}
+	}
+
+	h.Reset()
+	_, _ = h.Write(h.buf)
+
+	return true
+}
+
+// appendString appends the kind and the length of s before s, so that the values can not
+// run into each other.
+func (h *keyHash) appendString(kind byte, s string) {
+	var n [binary.MaxVarintLen64]byte
+	h.buf = append(append(h.buf, kind), n[:binary.PutUvarint(n[:], uint64(len(s)))]...)
+	h.buf = append(h.buf, s...)
+}
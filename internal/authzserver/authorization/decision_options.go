@@ -0,0 +1,64 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// DecisionCacheOptions contains configuration items related to the decision cache.
+type DecisionCacheOptions struct {
+	Enable     bool          `json:"enable"      mapstructure:"enable"`
+	TTL        time.Duration `json:"ttl"         mapstructure:"ttl"`
+	MaxEntries int64         `json:"max-entries" mapstructure:"max-entries"`
+}
+
+// NewDecisionCacheOptions creates a DecisionCacheOptions object with default parameters.
+func NewDecisionCacheOptions() *DecisionCacheOptions {
+	return &DecisionCacheOptions{
+		Enable:     true,
+		TTL:        5 * time.Second,
+		MaxEntries: 10000,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *DecisionCacheOptions) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	errors := []error{}
+
+	if o.TTL <= 0 {
+		errors = append(errors, fmt.Errorf("--decision-cache.ttl %v must be greater than 0", o.TTL))
+	}
+
+	if o.MaxEntries <= 0 {
+		errors = append(errors, fmt.Errorf("--decision-cache.max-entries %v must be greater than 0", o.MaxEntries))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the decision cache for a specific authz server to the
+// specified FlagSet.
+func (o *DecisionCacheOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "decision-cache.enable", o.Enable, ""+
+		"Cache the decisions of identical authorization requests for a short time.")
+
+	fs.DurationVar(&o.TTL, "decision-cache.ttl", o.TTL, ""+
+		"How long a decision is cached. Decisions are also dropped as soon as the policies are reloaded.")
+
+	fs.Int64Var(&o.MaxEntries, "decision-cache.max-entries", o.MaxEntries, ""+
+		"The maximum number of cached decisions.")
+}
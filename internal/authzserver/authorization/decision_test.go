@@ -0,0 +1,164 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"reflect"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/ory/ladon"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/marmotedu/iam/internal/pkg/condition"
+	"github.com/marmotedu/iam/pkg/featuregate"
+)
+
+func TestDecisionCache_Key(t *testing.T) {
+	decisions, err := NewDecisionCache(NewDecisionCacheOptions(), func() uint64 { return 0 })
+	if err != nil {
+		t.Fatalf("NewDecisionCache() error = %v", err)
+	}
+
+	request := func() *ladon.Request {
+		return &ladon.Request{
+			Subject:  "users:peter",
+			Action:   "delete",
+			Resource: "resources:printer",
+			Context:  ladon.Context{"username": "colin", "remoteIPAddress": "10.0.0.1", "tenant": "marmotedu"},
+		}
+	}
+
+	key := decisions.Key(request(), nil)
+	if key == "" {
+		t.Fatalf("DecisionCache.Key() = empty, want a key")
+	}
+
+	if got := decisions.Key(request(), nil); got != key {
+		t.Errorf("DecisionCache.Key() of an identical request = %s, want %s", got, key)
+	}
+
+	other := request()
+	other.Context["tenant"] = "other"
+	if got := decisions.Key(other, nil); got == key {
+		t.Errorf("DecisionCache.Key() of a different context = %s, want another key", got)
+	}
+
+	// values of different types are hashed apart
+	typed := request()
+	typed.Context["count"], other.Context["count"] = 1.0, "1"
+	if got := decisions.Key(typed, nil); got == decisions.Key(other, nil) {
+		t.Errorf("DecisionCache.Key() of values of different types = %s, want different keys", got)
+	}
+
+	shadowPolicy := &ladon.DefaultPolicy{Meta: []byte(`{"shadow":true}`)}
+	if got := decisions.Key(request(), ladon.Policies{shadowPolicy}); got != "" {
+		t.Errorf("DecisionCache.Key() with shadow policies = %s, want empty", got)
+	}
+
+	limited := &ladon.DefaultPolicy{
+		Conditions: ladon.Conditions{"quota": &condition.RateLimitCondition{Limit: 1, Period: 60}},
+	}
+	if got := decisions.Key(request(), ladon.Policies{limited}); got != "" {
+		t.Errorf("DecisionCache.Key() with stateful conditions = %s, want empty", got)
+	}
+
+	var disabled *DecisionCache
+	if got := disabled.Key(request(), nil); got != "" {
+		t.Errorf("DecisionCache.Key() of a disabled cache = %s, want empty", got)
+	}
+
+	// the feature gate is checked on every request, it can change at runtime
+	if err := featuregate.DefaultFeatureGate.Set("DecisionCache=false"); err != nil {
+		t.Fatalf("FeatureGate.Set() error = %v", err)
+	}
+	defer func() { _ = featuregate.DefaultFeatureGate.Set("DecisionCache=true") }()
+
+	if got := decisions.Key(request(), nil); got != "" {
+		t.Errorf("DecisionCache.Key() with the feature gate disabled = %s, want empty", got)
+	}
+}
+
+func BenchmarkDecisionCache_Key(b *testing.B) {
+	decisions, err := NewDecisionCache(NewDecisionCacheOptions(), func() uint64 { return 0 })
+	if err != nil {
+		b.Fatalf("NewDecisionCache() error = %v", err)
+	}
+
+	request := &ladon.Request{
+		Subject:  "users:peter",
+		Action:   "delete",
+		Resource: "resources:printer",
+		Context:  ladon.Context{"username": "colin", "remoteIPAddress": "10.0.0.1", "tenant": "marmotedu"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		decisions.Key(request, nil)
+	}
+}
+
+func TestDecisionCache_Epoch(t *testing.T) {
+	epoch := uint64(1)
+	decisions, err := NewDecisionCache(NewDecisionCacheOptions(), func() uint64 { return epoch })
+	if err != nil {
+		t.Fatalf("NewDecisionCache() error = %v", err)
+	}
+
+	want := &Decision{Response: authzv1.Response{Allowed: true}}
+	decisions.Set("key", decisions.Epoch(), want)
+	decisions.cache.Wait()
+
+	if got, ok := decisions.Get("key"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecisionCache.Get() = %v, %v, want %v, true", got, ok, want)
+	}
+
+	epoch++
+	if _, ok := decisions.Get("key"); ok {
+		t.Errorf("DecisionCache.Get() after the policy epoch changed, want a miss")
+	}
+}
+
+func TestAuthorizer_AuthorizeWithDecisionCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthz := NewMockAuthorizationInterface(ctrl)
+	// the cached decision is recorded in the audit log as well
+	mockAuthz.EXPECT().LogGrantedAccessRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	mockAuthz.EXPECT().List(gomock.Any()).Times(2).Return([]*ladon.DefaultPolicy{{
+		ID:        "68819e5a-738b-41ec-b03c-b58a1b19d043",
+		Subjects:  []string{"users:peter"},
+		Resources: []string{"resources:printer"},
+		Actions:   []string{"delete"},
+		Effect:    ladon.AllowAccess,
+	}}, nil)
+
+	decisions, err := NewDecisionCache(NewDecisionCacheOptions(), func() uint64 { return 0 })
+	if err != nil {
+		t.Fatalf("NewDecisionCache() error = %v", err)
+	}
+
+	hits := testutil.ToFloat64(decisionCacheRequests.WithLabelValues("hit"))
+	want := &authzv1.Response{Allowed: true}
+	for i := 0; i < 2; i++ {
+		a := NewAuthorizer(mockAuthz, WithDecisionCache(decisions))
+		request := &ladon.Request{
+			Subject:  "users:peter",
+			Action:   "delete",
+			Resource: "resources:printer",
+			Context:  ladon.Context{"username": "colin"},
+		}
+		if got := a.Authorize(request); !reflect.DeepEqual(got, want) {
+			t.Errorf("Authorizer.Authorize() = %v, want %v", got, want)
+		}
+		decisions.cache.Wait()
+	}
+
+	if got := testutil.ToFloat64(decisionCacheRequests.WithLabelValues("hit")) - hits; got != 1 {
+		t.Errorf("decision cache hits = %v, want 1", got)
+	}
+}
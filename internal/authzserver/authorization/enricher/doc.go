@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package enricher defines the context enrichers which add facts about the subject,
+// such as its groups, tenant or risk score, to the ladon request context.
+package enricher // import "github.com/marmotedu/iam/internal/authzserver/authorization/enricher"
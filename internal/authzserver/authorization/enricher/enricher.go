@@ -0,0 +1,47 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package enricher
+
+import (
+	"errors"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+)
+
+// ContextEnricher defines the interface for all context enrichers.
+type ContextEnricher interface {
+	authorization.Enricher
+
+	GetName() string
+	Init(opts *EnricherOptions) error
+}
+
+// GetEnricherByName returns the enricher instance by given name.
+func GetEnricherByName(name string) (ContextEnricher, error) {
+	if enricher, ok := availableEnrichers[name]; ok && enricher != nil {
+		return enricher, nil
+	}
+
+	return nil, errors.New(name + " Not found")
+}
+
+// NewChain creates the ordered enricher chain configured by opts.
+func NewChain(opts *EnricherOptions) ([]authorization.Enricher, error) {
+	chain := make([]authorization.Enricher, 0, len(opts.Chain))
+	for _, name := range opts.Chain {
+		enricher, err := GetEnricherByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := enricher.Init(opts); err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, enricher)
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package enricher
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// EnricherOptions contains configuration items related to context enrichment.
+type EnricherOptions struct {
+	Chain          []string `json:"chain"            mapstructure:"chain"`
+	RedisKeyPrefix string   `json:"redis-key-prefix" mapstructure:"redis-key-prefix"`
+}
+
+// NewEnricherOptions creates a EnricherOptions object with default parameters.
+func NewEnricherOptions() *EnricherOptions {
+	return &EnricherOptions{
+		Chain:          []string{},
+		RedisKeyPrefix: "iam.context.",
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *EnricherOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errors := []error{}
+
+	for _, name := range o.Chain {
+		if _, err := GetEnricherByName(name); err != nil {
+			errors = append(errors, fmt.Errorf("--enricher.chain contains unknown enricher %s", name))
+		}
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to context enrichment for a specific authz server to the
+// specified FlagSet.
+func (o *EnricherOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringSliceVar(&o.Chain, "enricher.chain", o.Chain, ""+
+		"The ordered list of enrichers run on the request context before the policies are evaluated. "+
+		"Available enrichers: redis.")
+
+	fs.StringVar(&o.RedisKeyPrefix, "enricher.redis-key-prefix", o.RedisKeyPrefix, ""+
+		"The prefix of the redis keys which store the context facts of a subject, used by the redis enricher.")
+}
@@ -0,0 +1,15 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package enricher
+
+var availableEnrichers map[string]ContextEnricher
+
+// nolint: gochecknoinits
+func init() {
+	availableEnrichers = make(map[string]ContextEnricher)
+
+	// Register all the context enrichers here
+	availableEnrichers["redis"] = &RedisEnricher{}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package enricher
+
+import (
+	"errors"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// RedisEnricher merges the JSON object stored in redis under the request subject
+// into the request context, e.g. {"groups":["admins"],"tenant":"marmotedu","risk":10}.
+// The values from redis take precedence over those sent by the client.
+type RedisEnricher struct {
+	store *storage.RedisCluster
+}
+
+// GetName returns the name of the enricher.
+func (r *RedisEnricher) GetName() string {
+	return "redis"
+}
+
+// Init initializes the enricher with the given options.
+func (r *RedisEnricher) Init(opts *EnricherOptions) error {
+	r.store = &storage.RedisCluster{KeyPrefix: opts.RedisKeyPrefix, ClientSideCache: true}
+
+	return nil
+}
+
+// Enrich adds the facts stored for the request subject to the context.
+func (r *RedisEnricher) Enrich(request *ladon.Request) error {
+	value, err := r.store.GetKey(request.Subject)
+	if err != nil {
+		// nothing is known about the subject
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	facts := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(value), &facts); err != nil {
+		return err
+	}
+
+	for key, fact := range facts {
+		// the username is set by iam-authz-server from the authenticated secret
+		if key == "username" {
+			continue
+		}
+
+		request.Context[key] = fact
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Verdict is the decision of an external authorizer.
+type Verdict string
+
+// Verdicts of the external authorizers.
+const (
+	// VerdictAllow allows the request and skips the remaining external authorizers.
+	VerdictAllow Verdict = "allow"
+	// VerdictDeny denies the request and skips the remaining external authorizers.
+	VerdictDeny Verdict = "deny"
+	// VerdictAbstain keeps the current decision and consults the next external authorizer.
+	VerdictAbstain Verdict = "abstain"
+)
+
+// ExternalAuthorizer is consulted after the ladon policies are evaluated, allowed is the
+// decision of the policies. It returns the verdict and the reason of the verdict.
+type ExternalAuthorizer interface {
+	Name() string
+	Authorize(request *ladon.Request, allowed bool) (Verdict, string, error)
+}
+
+// consult runs the external authorizers in order on the decision of the policies. A request
+// which is forcefully denied by a policy is never allowed by the external authorizers.
+func (a *Authorizer) consult(request *ladon.Request, decision *Decision) *authzv1.Response {
+	rsp := decision.response()
+	if len(a.externals) == 0 || rsp.Reason == ladon.ErrRequestForcefullyDenied.Error() {
+		return rsp
+	}
+
+	for _, external := range a.externals {
+		verdict, reason, err := external.Authorize(request, rsp.Allowed)
+		if err != nil {
+			log.Errorf("external authorizer %s failed: %s", external.Name(), err.Error())
+
+			return &authzv1.Response{
+				Denied: true,
+				Reason: "External authorizer " + external.Name() + " failed",
+			}
+		}
+
+		switch verdict {
+		case VerdictAllow:
+			return &authzv1.Response{Allowed: true, Reason: reason}
+		case VerdictDeny:
+			if reason == "" {
+				reason = "Request was denied by external authorizer " + external.Name()
+			}
+
+			return &authzv1.Response{Denied: true, Reason: reason}
+		}
+	}
+
+	return rsp
+}
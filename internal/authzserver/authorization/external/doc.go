@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package external implements the external authorizers which are consulted over http or grpc
+// after the ladon policies are evaluated.
+package external // import "github.com/marmotedu/iam/internal/authzserver/authorization/external"
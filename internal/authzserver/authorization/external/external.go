@@ -0,0 +1,119 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package external
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Request is the body sent to an external authorizer.
+type Request struct {
+	Subject  string        `json:"subject"`
+	Action   string        `json:"action"`
+	Resource string        `json:"resource"`
+	Context  ladon.Context `json:"context"`
+	// Allowed is the decision of the ladon policies.
+	Allowed bool `json:"allowed"`
+}
+
+// Response is the body returned by an external authorizer.
+type Response struct {
+	Verdict authorization.Verdict `json:"verdict"`
+	Reason  string                `json:"reason,omitempty"`
+}
+
+// client calls an external authorizer.
+type client interface {
+	call(ctx context.Context, req *Request) (*Response, error)
+}
+
+// authorizer applies the timeout and the failure policy to the calls of a client.
+type authorizer struct {
+	name          string
+	timeout       time.Duration
+	failurePolicy string
+	client        client
+}
+
+var _ authorization.ExternalAuthorizer = &authorizer{}
+
+// Name returns the name of the external authorizer.
+func (a *authorizer) Name() string {
+	return a.name
+}
+
+// Authorize consults the external authorizer. An authorizer which can not be consulted
+// abstains when its failure policy is abstain.
+func (a *authorizer) Authorize(r *ladon.Request, allowed bool) (authorization.Verdict, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	rsp, err := a.client.call(ctx, &Request{
+		Subject:  r.Subject,
+		Action:   r.Action,
+		Resource: r.Resource,
+		Context:  r.Context,
+		Allowed:  allowed,
+	})
+	if err == nil {
+		switch rsp.Verdict {
+		case authorization.VerdictAllow, authorization.VerdictDeny, authorization.VerdictAbstain:
+			return rsp.Verdict, rsp.Reason, nil
+		default:
+			err = fmt.Errorf("unknown verdict %q", rsp.Verdict)
+		}
+	}
+
+	if a.failurePolicy == FailurePolicyAbstain {
+		log.Warnf("external authorizer %s abstains: %s", a.name, err.Error())
+
+		return authorization.VerdictAbstain, "", nil
+	}
+
+	return "", "", err
+}
+
+// NewChain creates the external authorizers in the configured order.
+func NewChain(opts *ExternalOptions) ([]authorization.ExternalAuthorizer, error) {
+	chain := make([]authorization.ExternalAuthorizer, 0, len(opts.Authorizers))
+
+	for _, o := range opts.Authorizers {
+		a := &authorizer{
+			name:          o.Name,
+			timeout:       o.Timeout,
+			failurePolicy: o.FailurePolicy,
+		}
+		if a.timeout == 0 {
+			a.timeout = opts.Timeout
+		}
+		if a.failurePolicy == "" {
+			a.failurePolicy = opts.FailurePolicy
+		}
+
+		var err error
+		switch o.Type {
+		case TypeHTTP:
+			a.client, err = newHTTPClient(o.Address, o.CAFile)
+		case TypeGRPC:
+			a.client, err = newGRPCClient(o.Address, o.CAFile)
+		default:
+			err = fmt.Errorf("unknown type %s", o.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("create external authorizer %s failed: %w", o.Name, err)
+		}
+
+		chain = append(chain, a)
+	}
+
+	return chain, nil
+}
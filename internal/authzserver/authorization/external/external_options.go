@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package external
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Types of the external authorizers.
+const (
+	TypeHTTP = "http"
+	TypeGRPC = "grpc"
+)
+
+// Failure policies applied when an external authorizer can not be consulted.
+const (
+	FailurePolicyDeny    = "deny"
+	FailurePolicyAbstain = "abstain"
+)
+
+// ExternalOptions contains configuration items related to the external authorizers.
+type ExternalOptions struct {
+	Timeout       time.Duration        `json:"timeout"        mapstructure:"timeout"`
+	FailurePolicy string               `json:"failure-policy" mapstructure:"failure-policy"`
+	Authorizers   []*AuthorizerOptions `json:"authorizers"    mapstructure:"authorizers"`
+}
+
+// AuthorizerOptions contains configuration items of an external authorizer. The timeout and
+// the failure policy default to the ones of ExternalOptions.
+type AuthorizerOptions struct {
+	Name          string        `json:"name"           mapstructure:"name"`
+	Type          string        `json:"type"           mapstructure:"type"`
+	Address       string        `json:"address"        mapstructure:"address"`
+	CAFile        string        `json:"ca-file"        mapstructure:"ca-file"`
+	Timeout       time.Duration `json:"timeout"        mapstructure:"timeout"`
+	FailurePolicy string        `json:"failure-policy" mapstructure:"failure-policy"`
+}
+
+// NewExternalOptions creates a ExternalOptions object with default parameters.
+func NewExternalOptions() *ExternalOptions {
+	return &ExternalOptions{
+		Timeout:       time.Second,
+		FailurePolicy: FailurePolicyDeny,
+		Authorizers:   []*AuthorizerOptions{},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *ExternalOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errors := []error{}
+
+	if o.Timeout <= 0 {
+		errors = append(errors, fmt.Errorf("--external.timeout must be greater than 0"))
+	}
+
+	if !validFailurePolicy(o.FailurePolicy) {
+		errors = append(errors, fmt.Errorf("--external.failure-policy must be deny or abstain"))
+	}
+
+	names := map[string]struct{}{}
+	for i, a := range o.Authorizers {
+		if a.Name == "" {
+			errors = append(errors, fmt.Errorf("external.authorizers[%d].name can not be empty", i))
+		}
+		if _, ok := names[a.Name]; ok {
+			errors = append(errors, fmt.Errorf("external.authorizers[%d].name %s is duplicated", i, a.Name))
+		}
+		names[a.Name] = struct{}{}
+
+		if a.Type != TypeHTTP && a.Type != TypeGRPC {
+			errors = append(errors, fmt.Errorf("external.authorizers[%d].type must be http or grpc", i))
+		}
+		if a.Address == "" {
+			errors = append(errors, fmt.Errorf("external.authorizers[%d].address can not be empty", i))
+		}
+		if a.Timeout < 0 {
+			errors = append(errors, fmt.Errorf("external.authorizers[%d].timeout can not be negative", i))
+		}
+		if a.FailurePolicy != "" && !validFailurePolicy(a.FailurePolicy) {
+			errors = append(errors, fmt.Errorf("external.authorizers[%d].failure-policy must be deny or abstain", i))
+		}
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the external authorizers for a specific authz server to the
+// specified FlagSet. The authorizers themselves can only be set in the config file.
+func (o *ExternalOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.DurationVar(&o.Timeout, "external.timeout", o.Timeout, ""+
+		"The default timeout of a call to an external authorizer.")
+
+	fs.StringVar(&o.FailurePolicy, "external.failure-policy", o.FailurePolicy, ""+
+		"The default failure policy of the external authorizers, deny denies the request when an "+
+		"external authorizer can not be consulted, abstain consults the next one. Available: deny, abstain.")
+}
+
+func validFailurePolicy(policy string) bool {
+	return policy == FailurePolicyDeny || policy == FailurePolicyAbstain
+}
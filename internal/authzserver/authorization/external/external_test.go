@@ -0,0 +1,102 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package external
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+)
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		switch req.Resource {
+		case "resources:printer":
+			_ = json.NewEncoder(w).Encode(Response{Verdict: authorization.VerdictAllow})
+		case "resources:articles":
+			_ = json.NewEncoder(w).Encode(Response{Verdict: authorization.VerdictDeny, Reason: "no ticket"})
+		case "resources:slow":
+			time.Sleep(100 * time.Millisecond)
+		case "resources:unknown":
+			_ = json.NewEncoder(w).Encode(Response{Verdict: "maybe"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		resource      string
+		failurePolicy string
+		wantVerdict   authorization.Verdict
+		wantReason    string
+		wantErr       bool
+	}{
+		{name: "allow", resource: "resources:printer", wantVerdict: authorization.VerdictAllow},
+		{name: "deny", resource: "resources:articles", wantVerdict: authorization.VerdictDeny, wantReason: "no ticket"},
+		{name: "timeout", resource: "resources:slow", wantErr: true},
+		{name: "unknown_verdict", resource: "resources:unknown", wantErr: true},
+		{name: "server_error", resource: "resources:other", wantErr: true},
+		{
+			name:          "server_error_abstain",
+			resource:      "resources:other",
+			failurePolicy: FailurePolicyAbstain,
+			wantVerdict:   authorization.VerdictAbstain,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewExternalOptions()
+			opts.Timeout = 50 * time.Millisecond
+			opts.Authorizers = []*AuthorizerOptions{
+				{Name: "test", Type: TypeHTTP, Address: srv.URL, FailurePolicy: tt.failurePolicy},
+			}
+
+			chain, err := NewChain(opts)
+			if err != nil {
+				t.Fatalf("NewChain() error = %v", err)
+			}
+
+			verdict, reason, err := chain[0].Authorize(&ladon.Request{
+				Subject:  "users:peter",
+				Action:   "delete",
+				Resource: tt.resource,
+			}, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if verdict != tt.wantVerdict || reason != tt.wantReason {
+				t.Errorf("Authorize() = %s, %s, want %s, %s", verdict, reason, tt.wantVerdict, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestExternalOptions_Validate(t *testing.T) {
+	opts := NewExternalOptions()
+	opts.Authorizers = []*AuthorizerOptions{
+		{Name: "ticket", Type: TypeHTTP, Address: "http://127.0.0.1:8080/authorize"},
+		{Name: "ticket", Type: "amqp", FailurePolicy: "allow"},
+	}
+
+	// duplicated name, unknown type, empty address and unknown failure policy
+	if errs := opts.Validate(); len(errs) != 4 {
+		t.Errorf("ExternalOptions.Validate() = %v, want 4 errors", errs)
+	}
+}
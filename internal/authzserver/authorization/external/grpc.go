@@ -0,0 +1,67 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package external
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// authorizeMethod is the grpc method implemented by the grpc external authorizers.
+const authorizeMethod = "/iam.authz.v1.ExternalAuthorizer/Authorize"
+
+// codec encodes the messages as json, the content subtype is application/grpc+json.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return "json"
+}
+
+// grpcClient calls the Authorize method of a grpc external authorizer.
+type grpcClient struct {
+	conn *grpc.ClientConn
+}
+
+func newGRPCClient(address, caFile string) (*grpcClient, error) {
+	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{}))}
+
+	if caFile != "" {
+		creds, err := credentials.NewClientTLSFromFile(caFile, "")
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	// the connection is established lazily and re-established by grpc when it is lost
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcClient{conn: conn}, nil
+}
+
+func (c *grpcClient) call(ctx context.Context, req *Request) (*Response, error) {
+	var rsp Response
+	if err := c.conn.Invoke(ctx, authorizeMethod, req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"strings"
+
+	"github.com/ory/ladon"
+)
+
+// expandGroups grants the request subject the policies of its groups and roles. Every
+// policy with a subject matching one of the groups is replaced by a copy which has the
+// request subject as an extra subject, the cached policies are never changed.
+func expandGroups(request *ladon.Request, policies ladon.Policies, groups []string) ladon.Policies {
+	if len(groups) == 0 {
+		return policies
+	}
+
+	ret := make(ladon.Policies, 0, len(policies))
+	for _, policy := range policies {
+		p, ok := policy.(*ladon.DefaultPolicy)
+		// a subject with delimiters would be matched as a pattern instead of literally
+		if !ok || strings.IndexByte(request.Subject, p.GetStartDelimiter()) >= 0 || !matchesAny(p, groups) {
+			ret = append(ret, policy)
+
+			continue
+		}
+
+		expanded := *p
+		expanded.Subjects = append(append([]string{}, p.Subjects...), request.Subject)
+		ret = append(ret, &expanded)
+	}
+
+	return ret
+}
+
+// matchesAny reports whether one of the subjects of the policy matches one of the groups.
+func matchesAny(policy ladon.Policy, groups []string) bool {
+	for _, group := range groups {
+		if ok, err := ladon.DefaultMatcher.Matches(policy, policy.GetSubjects(), group); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
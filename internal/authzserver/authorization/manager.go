@@ -7,6 +7,8 @@ package authorization
 import (
 	"github.com/marmotedu/errors"
 	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/tenant"
 )
 
 // PolicyManager is a mysql implementation for Manager to store
@@ -52,13 +54,30 @@ func (*PolicyManager) GetAll(limit, offset int64) (ladon.Policies, error) {
 // a set that exactly matches the request, or a superset of it. If an error occurs, it returns nil and
 // the error.
 func (m *PolicyManager) FindRequestCandidates(r *ladon.Request) (ladon.Policies, error) {
+	return m.FindCandidates(r, nil)
+}
+
+// FindCandidates returns the candidates of the request for the request subject and the given
+// groups it belongs to.
+func (m *PolicyManager) FindCandidates(r *ladon.Request, groups []string) (ladon.Policies, error) {
 	username := ""
 
 	if user, ok := r.Context["username"].(string); ok {
 		username = user
 	}
 
-	policies, err := m.client.List(username)
+	// only the policies of the request tenant are candidates
+	name, _ := tenant.FromRequest(r)
+
+	var (
+		policies []*ladon.DefaultPolicy
+		err      error
+	)
+	if lister, ok := m.client.(CandidateLister); ok {
+		policies, err = lister.ListCandidates(tenant.Key(username, name), append([]string{r.Subject}, groups...), r.Resource)
+	} else {
+		policies, err = m.client.List(tenant.Key(username, name))
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "list policies failed")
 	}
@@ -0,0 +1,51 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// decisions counts the authorization decisions, allow or deny.
+	decisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_authz_decisions_total",
+			Help: "Number of authorization decisions, partitioned by decision.",
+		},
+		[]string{"decision"},
+	)
+
+	// shadowDecisions counts the requests whose decision would be changed by the shadow policies.
+	shadowDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_authz_shadow_decisions_total",
+			Help: "Number of authorization requests whose decision would be changed by shadow policies.",
+		},
+		[]string{"decision"},
+	)
+
+	// decisionCacheRequests counts the lookups of the decision cache by result, hit or miss.
+	decisionCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_authz_decision_cache_requests_total",
+			Help: "Number of authorization decision cache lookups, partitioned by result.",
+		},
+		[]string{"result"},
+	)
+
+	// droppedRecords counts the authorization requests not recorded because the buffer was full.
+	droppedRecords = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "iam_authz_dropped_records_total",
+			Help: "Number of authorization requests not recorded because the record buffer was full.",
+		},
+	)
+)
+
+// nolint: gochecknoinits
+func init() {
+	prometheus.MustRegister(decisions, shadowDecisions, decisionCacheRequests, droppedRecords)
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Record is an authorization request recorded along with the decision of the policies, the
+// external authorizers and the sessions are not part of it.
+type Record struct {
+	Time    time.Time      `json:"time"`
+	Request *ladon.Request `json:"request"`
+	Allowed bool           `json:"allowed"`
+	// Deciders are the ids of the policies which allowed or denied the request.
+	Deciders []string `json:"deciders,omitempty"`
+}
+
+// Recorder appends the records of the authorization requests to a file, one JSON record per
+// line. The records are written in the background, they are dropped rather than slowing down
+// the requests when the buffer is full. A nil Recorder records nothing.
+type Recorder struct {
+	file       *os.File
+	salt       []byte
+	redactKeys map[string]bool
+
+	mu      sync.RWMutex
+	closed  bool
+	records chan *Record
+	done    chan struct{}
+}
+
+// NewRecorder creates a Recorder and starts writing the records. It returns nil when the
+// recording is disabled.
+func NewRecorder(opts *RecordOptions) (*Recorder, error) {
+	if opts == nil || !opts.Enable {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open record file %s failed", opts.File)
+	}
+
+	// the hashes can not be reversed by hashing the likely values
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	r := &Recorder{
+		file:       file,
+		salt:       salt,
+		redactKeys: make(map[string]bool, len(opts.RedactKeys)),
+		records:    make(chan *Record, opts.BufferSize),
+		done:       make(chan struct{}),
+	}
+	for _, key := range opts.RedactKeys {
+		r.redactKeys[key] = true
+	}
+
+	go r.run()
+
+	return r, nil
+}
+
+// Record records the request and the decision of the policies. The request is copied, so
+// that it can be reused once Record returns.
+func (r *Recorder) Record(request *ladon.Request, decision *Decision) {
+	if r == nil {
+		return
+	}
+
+	record := &Record{
+		Time: time.Now(),
+		Request: &ladon.Request{
+			Resource: request.Resource,
+			Action:   request.Action,
+			Subject:  request.Subject,
+			Context:  make(ladon.Context, len(request.Context)),
+		},
+		Allowed: decision.Response.Allowed,
+	}
+	for key, value := range request.Context {
+		if r.redactKeys[key] {
+			value = r.redact(value)
+		}
+		record.Request.Context[key] = value
+	}
+	for _, policy := range decision.Deciders {
+		record.Deciders = append(record.Deciders, policy.GetID())
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.records <- record:
+	default:
+		droppedRecords.Inc()
+	}
+}
+
+// Close writes the pending records and closes the file.
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.records)
+	}
+	r.mu.Unlock()
+
+	<-r.done
+
+	return r.file.Close()
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	w := bufio.NewWriter(r.file)
+	encoder := json.NewEncoder(w)
+	for record := range r.records {
+		if err := encoder.Encode(record); err != nil {
+			log.Warnf("write authorization record failed: %s", err.Error())
+		}
+
+		// flush whenever the buffer is drained, so that the file is readable while recording
+		if len(r.records) == 0 {
+			if err := w.Flush(); err != nil {
+				log.Warnf("flush authorization records failed: %s", err.Error())
+			}
+		}
+	}
+
+	_ = w.Flush()
+}
+
+// redact returns the salted hash of the value, equal values have equal hashes.
+func (r *Recorder) redact(value interface{}) string {
+	data, _ := json.Marshal(value)
+	sum := sha256.Sum256(append(append([]byte{}, r.salt...), data...))
+
+	return "redacted:" + hex.EncodeToString(sum[:16])
+}
+
+// ReadRecords reads the records written by a Recorder.
+func ReadRecords(reader io.Reader) ([]*Record, error) {
+	var records []*Record
+
+	decoder := json.NewDecoder(reader)
+	for {
+		record := &Record{}
+		if err := decoder.Decode(record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+
+			return nil, errors.Wrapf(err, "decode record %d failed", len(records)+1)
+		}
+
+		if record.Request == nil {
+			return nil, errors.Errorf("record %d has no request", len(records)+1)
+		}
+		if record.Request.Context == nil {
+			record.Request.Context = ladon.Context{}
+		}
+
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// RecordOptions contains configuration items related to the recording of the authorization
+// requests, which are replayed by iamctl authz replay against candidate policies.
+type RecordOptions struct {
+	Enable bool   `json:"enable"      mapstructure:"enable"`
+	File   string `json:"file"        mapstructure:"file"`
+	// RedactKeys are the context keys whose values are replaced by a salted hash.
+	RedactKeys []string `json:"redact-keys" mapstructure:"redact-keys"`
+	BufferSize int      `json:"buffer-size" mapstructure:"buffer-size"`
+}
+
+// NewRecordOptions creates a RecordOptions object with default parameters.
+func NewRecordOptions() *RecordOptions {
+	return &RecordOptions{
+		Enable:     false,
+		File:       "",
+		RedactKeys: []string{"remoteIPAddress"},
+		BufferSize: 1000,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *RecordOptions) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	errors := []error{}
+
+	if o.File == "" {
+		errors = append(errors, fmt.Errorf("--record.file can not be empty when the recording is enabled"))
+	}
+
+	if o.BufferSize <= 0 {
+		errors = append(errors, fmt.Errorf("--record.buffer-size %v must be greater than 0", o.BufferSize))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the recording of the authorization requests for a specific
+// authz server to the specified FlagSet.
+func (o *RecordOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "record.enable", o.Enable, ""+
+		"Record the authorization requests, along with the decisions of the policies, to --record.file. "+
+		"The records are replayed against candidate policies by iamctl authz replay.")
+
+	fs.StringVar(&o.File, "record.file", o.File, ""+
+		"The file the records are appended to, one JSON record per line.")
+
+	fs.StringSliceVar(&o.RedactKeys, "record.redact-keys", o.RedactKeys, ""+
+		"The context keys whose values are replaced by a salted hash in the records. The hashes are "+
+		"only equal within a run, so the conditions on these keys can not be replayed.")
+
+	fs.IntVar(&o.BufferSize, "record.buffer-size", o.BufferSize, ""+
+		"The maximum number of records waiting to be written, the requests are not recorded when it is full.")
+}
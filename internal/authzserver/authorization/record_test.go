@@ -0,0 +1,94 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_replay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "records.jsonl")
+	recorder, err := NewRecorder(&RecordOptions{
+		Enable:     true,
+		File:       file,
+		RedactKeys: []string{"remoteIPAddress"},
+		BufferSize: 10,
+	})
+	require.NoError(t, err)
+
+	current := map[string][]*ladon.DefaultPolicy{
+		"colin": {{
+			ID:        "read-articles",
+			Subjects:  []string{"users:<peter|ken>"},
+			Resources: []string{"resources:articles:<.*>"},
+			Actions:   []string{"<get|delete>"},
+			Effect:    ladon.AllowAccess,
+		}},
+	}
+	a := NewAuthorizer(staticPolicies(current), WithRecorder(recorder))
+
+	requests := []*ladon.Request{
+		{Subject: "users:peter", Action: "delete", Resource: "resources:articles:ladon"},
+		{Subject: "users:ken", Action: "get", Resource: "resources:articles:ladon"},
+		{Subject: "users:maria", Action: "get", Resource: "resources:articles:ladon"},
+	}
+	for _, request := range requests {
+		request.Context = ladon.Context{"username": "colin", "remoteIPAddress": "192.168.0.5"}
+		a.Authorize(request)
+	}
+	require.NoError(t, recorder.Close())
+
+	reader, err := os.Open(file)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	records, err := ReadRecords(reader)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.True(t, records[0].Allowed)
+	assert.Equal(t, []string{"read-articles"}, records[0].Deciders)
+	assert.False(t, records[2].Allowed)
+
+	ip, _ := records[0].Request.Context["remoteIPAddress"].(string)
+	assert.True(t, strings.HasPrefix(ip, "redacted:"), "the redacted key is hashed, got %s", ip)
+	assert.Equal(t, ip, records[1].Request.Context["remoteIPAddress"], "equal values have equal hashes")
+
+	// the candidate policies no longer allow deleting the articles
+	candidate := map[string][]*ladon.DefaultPolicy{
+		"colin": {{
+			ID:        "read-articles",
+			Subjects:  []string{"users:<peter|ken>"},
+			Resources: []string{"resources:articles:<.*>"},
+			Actions:   []string{"get"},
+			Effect:    ladon.AllowAccess,
+		}},
+	}
+
+	var changed []string
+	for _, result := range Replay(records, candidate) {
+		if result.Changed() {
+			changed = append(changed, result.Record.Request.Subject+" "+result.Record.Request.Action)
+		}
+	}
+	assert.Equal(t, []string{"users:peter delete"}, changed)
+}
+
+func TestNewRecorder_disabled(t *testing.T) {
+	recorder, err := NewRecorder(NewRecordOptions())
+	require.NoError(t, err)
+	assert.Nil(t, recorder)
+
+	// a nil recorder records nothing
+	recorder.Record(&ladon.Request{}, &Decision{})
+	assert.NoError(t, recorder.Close())
+}
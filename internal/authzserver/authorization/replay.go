@@ -0,0 +1,77 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/ory/ladon"
+)
+
+// ReplayResult is the decision of the candidate policies for a recorded request.
+type ReplayResult struct {
+	Record   *Record           `json:"record"`
+	Response *authzv1.Response `json:"response"`
+}
+
+// Changed reports whether the candidate policies changed the recorded decision.
+func (r *ReplayResult) Changed() bool {
+	return r.Record.Allowed != r.Response.Allowed
+}
+
+// Replay evaluates the recorded requests against the candidate policies, keyed by their policy
+// partition key, i.e. the username or username/tenant. The recorded contexts are already
+// enriched and tagged, the groups of the subjects are not resolved.
+func Replay(records []*Record, policies map[string][]*ladon.DefaultPolicy) []*ReplayResult {
+	a := NewAuthorizer(staticPolicies(policies))
+
+	results := make([]*ReplayResult, 0, len(records))
+	for _, record := range records {
+		// the authorizer may add to the context, keep the record as it was read
+		request := &ladon.Request{
+			Resource: record.Request.Resource,
+			Action:   record.Request.Action,
+			Subject:  record.Request.Subject,
+			Context:  make(ladon.Context, len(record.Request.Context)),
+		}
+		for key, value := range record.Request.Context {
+			request.Context[key] = value
+		}
+
+		results = append(results, &ReplayResult{Record: record, Response: a.authorize(request)})
+	}
+
+	return results
+}
+
+// staticPolicies serves the candidate policies of a replay, the decisions are not audited.
+type staticPolicies map[string][]*ladon.DefaultPolicy
+
+func (staticPolicies) Create(*ladon.DefaultPolicy) error {
+	return nil
+}
+
+func (staticPolicies) Update(*ladon.DefaultPolicy) error {
+	return nil
+}
+
+func (staticPolicies) Delete(id string) error {
+	return nil
+}
+
+func (staticPolicies) DeleteCollection(idList []string) error {
+	return nil
+}
+
+func (staticPolicies) Get(id string) (*ladon.DefaultPolicy, error) {
+	return &ladon.DefaultPolicy{}, nil
+}
+
+func (p staticPolicies) List(key string) ([]*ladon.DefaultPolicy, error) {
+	return p[key], nil
+}
+
+func (staticPolicies) LogRejectedAccessRequest(*ladon.Request, ladon.Policies, ladon.Policies) {}
+
+func (staticPolicies) LogGrantedAccessRequest(*ladon.Request, ladon.Policies, ladon.Policies) {}
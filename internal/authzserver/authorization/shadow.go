@@ -0,0 +1,100 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/shadow"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// deciderRecorder records the policies which decided a single evaluation, and passes the
+// audit records on to the next logger, if any.
+type deciderRecorder struct {
+	next     ladon.AuditLogger
+	deciders ladon.Policies
+}
+
+// LogRejectedAccessRequest records the policies which rejected the request.
+func (d *deciderRecorder) LogRejectedAccessRequest(r *ladon.Request, pool ladon.Policies, deciders ladon.Policies) {
+	d.deciders = deciders
+	if d.next != nil {
+		d.next.LogRejectedAccessRequest(r, pool, deciders)
+	}
+}
+
+// LogGrantedAccessRequest records the policies which granted the request.
+func (d *deciderRecorder) LogGrantedAccessRequest(r *ladon.Request, pool ladon.Policies, deciders ladon.Policies) {
+	d.deciders = deciders
+	if d.next != nil {
+		d.next.LogGrantedAccessRequest(r, pool, deciders)
+	}
+}
+
+// splitShadow splits the policies into the enforced and the shadow ones.
+func splitShadow(policies ladon.Policies) (enforced, shadows ladon.Policies) {
+	for _, policy := range policies {
+		if shadow.IsShadow(policy) {
+			shadows = append(shadows, policy)
+
+			continue
+		}
+
+		enforced = append(enforced, policy)
+	}
+
+	return enforced, shadows
+}
+
+// evaluateShadow evaluates the shadow policies and reports when they would have changed
+// the enforced decision. It never changes the decision itself.
+func evaluateShadow(request *ladon.Request, policies ladon.Policies, enforced error) {
+	if len(policies) == 0 {
+		return
+	}
+
+	recorder := &deciderRecorder{}
+	warden := &ladon.Ladon{AuditLogger: recorder}
+	result := warden.DoPoliciesAllow(request, policies)
+
+	// ladon denies explicitly when any policy denies, and allows when no policy denies and
+	// at least one allows, so shadow policies can only turn:
+	// - an allowed request into a denied one, with a matching deny policy;
+	// - a request denied for lack of an allow policy into an allowed one.
+	var allowed bool
+	switch {
+	case enforced == nil:
+		allowed = !errors.Is(result, ladon.ErrRequestForcefullyDenied)
+	case errors.Is(enforced, ladon.ErrRequestDenied):
+		allowed = result == nil
+	default:
+		return
+	}
+
+	if allowed == (enforced == nil) {
+		return
+	}
+
+	decision := "deny"
+	if allowed {
+		decision = "allow"
+	}
+
+	ids := make([]string, 0, len(recorder.deciders))
+	for _, policy := range recorder.deciders {
+		ids = append(ids, policy.GetID())
+	}
+
+	shadowDecisions.WithLabelValues(decision).Inc()
+	log.Warn("shadow policies would change the decision",
+		log.String("subject", request.Subject),
+		log.String("action", request.Action),
+		log.String("resource", request.Resource),
+		log.String("decision", decision),
+		log.Any("policies", ids),
+	)
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+)
+
+// addTags sets the tags of the request subject and resource in the request context. The tags
+// known to iam replace the ones sent along with the request, which are kept for the subjects
+// and the resources iam does not know about.
+func addTags(request *ladon.Request, getter TagGetter) {
+	username, _ := request.Context["username"].(string)
+	name, _ := tenant.FromRequest(request)
+	key := tenant.Key(username, name)
+
+	if t, ok := getter.GetTags(key, request.Subject); ok {
+		request.Context[tags.CallerKey] = nonNil(t)
+	}
+	if t, ok := getter.GetTags(key, request.Resource); ok {
+		request.Context[tags.ResourceKey] = nonNil(t)
+	}
+}
+
+// nonNil returns empty tags instead of nil, so that an untagged object still replaces the
+// tags sent along with the request.
+func nonNil(t tags.Tags) tags.Tags {
+	if t == nil {
+		return tags.Tags{}
+	}
+
+	return t
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// TenantOptions contains configuration items related to the tenant isolation.
+type TenantOptions struct {
+	Required bool `json:"required" mapstructure:"required"`
+}
+
+// NewTenantOptions creates a TenantOptions object with default parameters.
+func NewTenantOptions() *TenantOptions {
+	return &TenantOptions{
+		Required: false,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *TenantOptions) Validate() []error {
+	return []error{}
+}
+
+// AddFlags adds flags related to the tenant isolation for a specific authz server to the
+// specified FlagSet.
+func (o *TenantOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Required, "tenant.required", o.Required, ""+
+		"Deny the authorization requests which do not carry a tenant in the tenant context key. "+
+		"Otherwise such requests are only matched against the policies without a tenant.")
+}
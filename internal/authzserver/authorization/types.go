@@ -8,6 +8,8 @@ package authorization
 
 import (
 	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/tags"
 )
 
 // AuthorizationInterface defiens the CURD method for lady policy.
@@ -23,3 +25,28 @@ type AuthorizationInterface interface {
 	LogRejectedAccessRequest(request *ladon.Request, pool ladon.Policies, deciders ladon.Policies)
 	LogGrantedAccessRequest(request *ladon.Request, pool ladon.Policies, deciders ladon.Policies)
 }
+
+// CandidateLister may be implemented by an AuthorizationInterface to list a superset of the
+// policies matching one of the subjects and the resource, rather than all the policies.
+type CandidateLister interface {
+	ListCandidates(key string, subjects []string, resource string) ([]*ladon.DefaultPolicy, error)
+}
+
+// Enricher enriches the context of a ladon request before the policy conditions are evaluated.
+type Enricher interface {
+	Enrich(request *ladon.Request) error
+}
+
+// TagGetter returns the tags of the users, secrets and policies known to iam.
+type TagGetter interface {
+	// GetTags returns the tags of the object named like a subject or a resource, e.g.
+	// users:colin, among the policies of the given policy partition key. The second return
+	// value reports whether the object is known.
+	GetTags(key, name string) (tags.Tags, bool)
+}
+
+// MembershipGetter returns the groups and roles a member belongs to.
+type MembershipGetter interface {
+	// GetGroups returns the subjects of the groups and roles of the user which contain member.
+	GetGroups(username, member string) []string
+}
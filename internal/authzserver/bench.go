@@ -0,0 +1,315 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authzserver
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/authorizer"
+	"github.com/marmotedu/iam/internal/authzserver/load/cache"
+	"github.com/marmotedu/iam/internal/authzserver/options"
+	"github.com/marmotedu/iam/internal/authzserver/store"
+	"github.com/marmotedu/iam/internal/pkg/membership"
+	"github.com/marmotedu/iam/pkg/app"
+)
+
+const benchDesc = "Load synthetic policies into the authorizer and report the decisions per second, the latency " +
+	"percentiles and the allocations per decision, to plan the capacity and catch the regressions. The same " +
+	"options and seed reproduce the same workload."
+
+const (
+	// benchRequests is the number of the distinct requests sent in turn by the benchmark.
+	benchRequests = 10000
+	// benchAllocRuns is the number of the sequential decisions the allocations are measured on.
+	benchAllocRuns = 1000
+)
+
+// newBenchCommand returns the bench command, which measures the performance of the authorizer.
+func newBenchCommand() *app.Command {
+	opts := options.NewBenchOptions()
+
+	return app.NewCommand("bench", benchDesc,
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			return runBench(opts)
+		}),
+	)
+}
+
+// benchResult is the outcome of a benchmark.
+type benchResult struct {
+	decisions int64
+	allowed   int64
+	elapsed   time.Duration
+	latencies []time.Duration
+	allocs    float64
+	bytes     float64
+}
+
+func runBench(opts *options.BenchOptions) error {
+	if errs := opts.Validate(); len(errs) != 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	bs := newBenchStore(rnd, opts.Policies, opts.Users)
+
+	fmt.Printf("Loading %d policies of %d users\n", opts.Policies, opts.Users)
+	start := time.Now()
+
+	cacheIns, err := cache.GetCacheInsOr(bs)
+	if err != nil {
+		return err
+	}
+	if err := cacheIns.Reload(); err != nil {
+		return err
+	}
+	fmt.Printf("Loaded in %v\n", time.Since(start).Round(time.Millisecond))
+
+	decisions, err := authorization.NewDecisionCache(opts.DecisionCache, cacheIns.PolicyEpoch)
+	if err != nil {
+		return err
+	}
+
+	auth := authorization.NewAuthorizer(
+		&benchAuthorization{authorizer.NewAuthorization(cacheIns).(*authorizer.Authorization)},
+		authorization.WithDecisionCache(decisions),
+		authorization.WithMemberships(cacheIns),
+	)
+	requests := bs.requests(rnd, benchRequests)
+
+	// the sequential run warms up the caches as well
+	allocs, bytes := measureAllocs(auth, requests)
+
+	fmt.Printf("Running %d workers for %v\n", opts.Concurrency, opts.Duration)
+	result := runWorkers(auth, requests, opts.Concurrency, opts.Duration)
+	result.allocs, result.bytes = allocs, bytes
+
+	result.print()
+
+	return nil
+}
+
+// measureAllocs returns the allocations and the allocated bytes per decision.
+func measureAllocs(auth *authorization.Authorizer, requests []*ladon.Request) (float64, float64) {
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < benchAllocRuns; i++ {
+		r := *requests[i%len(requests)]
+		auth.Authorize(&r)
+	}
+	runtime.ReadMemStats(&after)
+
+	return float64(after.Mallocs-before.Mallocs) / benchAllocRuns,
+		float64(after.TotalAlloc-before.TotalAlloc) / benchAllocRuns
+}
+
+// runWorkers sends the requests from concurrency goroutines during duration.
+func runWorkers(
+	auth *authorization.Authorizer,
+	requests []*ladon.Request,
+	concurrency int,
+	duration time.Duration,
+) *benchResult {
+	var (
+		wg        sync.WaitGroup
+		stop      int32
+		allowed   int64
+		latencies = make([][]time.Duration, concurrency)
+	)
+
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := w; atomic.LoadInt32(&stop) == 0; i += concurrency {
+				r := *requests[i%len(requests)]
+				begin := time.Now()
+				rsp := auth.Authorize(&r)
+				latencies[w] = append(latencies[w], time.Since(begin))
+
+				if rsp.Allowed {
+					atomic.AddInt64(&allowed, 1)
+				}
+			}
+		}(w)
+	}
+
+	time.Sleep(duration)
+	atomic.StoreInt32(&stop, 1)
+	wg.Wait()
+
+	result := &benchResult{allowed: allowed, elapsed: time.Since(start)}
+	for _, l := range latencies {
+		result.latencies = append(result.latencies, l...)
+	}
+	result.decisions = int64(len(result.latencies))
+	sort.Slice(result.latencies, func(i, j int) bool { return result.latencies[i] < result.latencies[j] })
+
+	return result
+}
+
+func (r *benchResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+
+	return r.latencies[int(float64(len(r.latencies)-1)*p)]
+}
+
+func (r *benchResult) print() {
+	if r.decisions == 0 {
+		fmt.Println("No decision completed")
+
+		return
+	}
+
+	fmt.Printf("\n%-16s %d\n", "decisions", r.decisions)
+	fmt.Printf("%-16s %.1f%%\n", "allowed", float64(r.allowed)*100/float64(r.decisions))
+	fmt.Printf("%-16s %.0f\n", "decisions/sec", float64(r.decisions)/r.elapsed.Seconds())
+	for _, p := range []float64{0.5, 0.9, 0.99, 0.999} {
+		fmt.Printf("%-16s %v\n", fmt.Sprintf("latency p%g", p*100), r.percentile(p))
+	}
+	fmt.Printf("%-16s %v\n", "latency max", r.latencies[len(r.latencies)-1])
+	fmt.Printf("%-16s %.1f\n", "allocs/decision", r.allocs)
+	fmt.Printf("%-16s %.0f\n", "bytes/decision", r.bytes)
+}
+
+// benchAuthorization does not record the decisions, the analytics are not started by the
+// benchmark.
+type benchAuthorization struct {
+	*authorizer.Authorization
+}
+
+func (*benchAuthorization) LogRejectedAccessRequest(r *ladon.Request, p ladon.Policies, d ladon.Policies) {
+}
+
+func (*benchAuthorization) LogGrantedAccessRequest(r *ladon.Request, p ladon.Policies, d ladon.Policies) {
+}
+
+// benchStore is a store of synthetic policies. Every user owns the same share of the
+// policies, most of them apply to a single subject and resource, the others use regular
+// expressions and conditions.
+type benchStore struct {
+	policies map[string][]*ladon.DefaultPolicy
+	users    []string
+}
+
+func newBenchStore(rnd *rand.Rand, policies, users int) *benchStore {
+	bs := &benchStore{policies: make(map[string][]*ladon.DefaultPolicy, users)}
+	for u := 0; u < users; u++ {
+		bs.users = append(bs.users, fmt.Sprintf("bench-user-%d", u))
+	}
+
+	for i := 0; i < policies; i++ {
+		username := bs.users[i%users]
+		policy := &ladon.DefaultPolicy{
+			ID:        fmt.Sprintf("bench-policy-%d", i),
+			Subjects:  []string{fmt.Sprintf("users:bench-subject-%d", rnd.Intn(policies))},
+			Actions:   []string{"<get|list>"},
+			Resources: []string{fmt.Sprintf("resources:bench:%d", i)},
+			Effect:    ladon.AllowAccess,
+		}
+
+		switch i % 10 {
+		case 0:
+			policy.Subjects = []string{"users:<bench-subject-[0-9]*>"}
+			policy.Resources = []string{fmt.Sprintf("resources:bench:%d<.*>", i)}
+		case 1:
+			policy.Conditions = ladon.Conditions{
+				"remoteIPAddress": &ladon.CIDRCondition{CIDR: "192.168.0.0/16"},
+			}
+		case 2:
+			policy.Effect = ladon.DenyAccess
+			policy.Actions = []string{"delete"}
+		}
+
+		bs.policies[username] = append(bs.policies[username], policy)
+	}
+
+	return bs
+}
+
+// requests returns n requests of the users, on the resources of their policies.
+func (bs *benchStore) requests(rnd *rand.Rand, n int) []*ladon.Request {
+	ret := make([]*ladon.Request, 0, n)
+	for i := 0; i < n; i++ {
+		username := bs.users[rnd.Intn(len(bs.users))]
+		policies := bs.policies[username]
+		policy := policies[rnd.Intn(len(policies))]
+
+		ret = append(ret, &ladon.Request{
+			Subject:  policy.Subjects[0],
+			Action:   []string{"get", "list", "delete"}[rnd.Intn(3)],
+			Resource: fmt.Sprintf("resources:bench:%s", policy.ID[len("bench-policy-"):]),
+			Context: ladon.Context{
+				"username":        username,
+				"remoteIPAddress": "192.168.1.1",
+			},
+		})
+	}
+
+	return ret
+}
+
+func (bs *benchStore) Secrets() store.SecretStore {
+	return benchSecrets{}
+}
+
+func (bs *benchStore) Policies() store.PolicyStore {
+	return benchPolicies{bs}
+}
+
+func (bs *benchStore) Memberships() store.MembershipStore {
+	return benchMemberships{}
+}
+
+type benchPolicies struct {
+	*benchStore
+}
+
+func (bp benchPolicies) List() (map[string][]*ladon.DefaultPolicy, error) {
+	return bp.policies, nil
+}
+
+func (bp benchPolicies) ListByUser(username string) ([]*ladon.DefaultPolicy, error) {
+	return bp.policies[username], nil
+}
+
+// benchSecrets has no secret, the requests are not authenticated by the benchmark.
+type benchSecrets struct{}
+
+func (benchSecrets) List() (map[string]*pb.SecretInfo, error) {
+	return map[string]*pb.SecretInfo{}, nil
+}
+
+func (benchSecrets) ListByUser(username string) (map[string]*pb.SecretInfo, error) {
+	return map[string]*pb.SecretInfo{}, nil
+}
+
+type benchMemberships struct{}
+
+func (benchMemberships) List() (map[string]membership.Index, error) {
+	return map[string]membership.Index{}, nil
+}
+
+func (benchMemberships) ListByUser(username string) (membership.Index, error) {
+	return nil, nil
+}
@@ -6,6 +6,8 @@
 package authorize
 
 import (
+	"sync"
+
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
@@ -14,37 +16,77 @@ import (
 	"github.com/marmotedu/iam/internal/authzserver/authorization"
 	"github.com/marmotedu/iam/internal/authzserver/authorization/authorizer"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
 )
 
 // AuthzController create a authorize handler used to handle authorize request.
 type AuthzController struct {
-	store authorizer.PolicyGetter
+	// auth holds no state of the requests, so it is shared by all of them.
+	auth *authorization.Authorizer
 }
 
 // NewAuthzController creates a authorize handler.
-func NewAuthzController(store authorizer.PolicyGetter) *AuthzController {
+func NewAuthzController(store authorizer.PolicyGetter, opts ...authorization.Option) *AuthzController {
 	return &AuthzController{
-		store: store,
+		auth: authorization.NewAuthorizer(authorizer.NewAuthorization(store), opts...),
+	}
+}
+
+// requests reuses the ladon requests along with their context maps. A request is only used
+// while it is authorized, the audit records and the decisions do not keep it.
+var requests = sync.Pool{
+	New: func() interface{} {
+		return &ladon.Request{Context: ladon.Context{}}
+	},
+}
+
+func getRequest() *ladon.Request {
+	return requests.Get().(*ladon.Request)
+}
+
+func putRequest(r *ladon.Request) {
+	r.Resource, r.Action, r.Subject = "", "", ""
+	for k := range r.Context {
+		delete(r.Context, k)
 	}
+
+	requests.Put(r)
 }
 
 // Authorize returns whether a request is allow or deny to access a resource and do some action
 // under specified condition.
 func (a *AuthzController) Authorize(c *gin.Context) {
-	var r ladon.Request
-	if err := c.ShouldBind(&r); err != nil {
+	r := getRequest()
+	defer putRequest(r)
+
+	if err := bindRequest(c, r); err != nil {
 		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
 
 		return
 	}
 
-	auth := authorization.NewAuthorizer(authorizer.NewAuthorization(a.store))
+	// reject requests which are signed by a secret not allowed to authorize them
+	if sc, ok := c.Value(middleware.ScopeKey).(*scope.Scope); ok && !sc.Allow(r.Action, r.Resource) {
+		core.WriteResponse(c, errors.WithCode(code.ErrOutOfScope, "action `%s` on resource `%s` is not allowed by the secret",
+			r.Action, r.Resource), nil)
+
+		return
+	}
+
+	// a null context in the body resets the map
 	if r.Context == nil {
 		r.Context = ladon.Context{}
 	}
 
 	r.Context["username"] = c.GetString("username")
-	rsp := auth.Authorize(&r)
+	rsp := a.auth.Authorize(r)
+
+	// the temporary credentials authorize what both their owner and their session allow
+	if sess, ok := c.Value(middleware.SessionKey).(*session.Session); ok {
+		rsp = sess.Restrict(r, rsp)
+	}
 
 	core.WriteResponse(c, nil, rsp)
 }
@@ -0,0 +1,205 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorize
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/analytics"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/session"
+)
+
+// policyGetter returns the same policies for every user.
+type policyGetter []*ladon.DefaultPolicy
+
+func (p policyGetter) GetPolicy(key string) ([]*ladon.DefaultPolicy, error) {
+	return p, nil
+}
+
+const policy = `{
+	"id": "68819e5a-738b-41ec-b03c-b58a1b19d043",
+	"subjects": ["users:<peter|ken>", "users:maria"],
+	"actions": ["delete", "<create|update>"],
+	"effect": "allow",
+	"resources": ["resources:articles:<.*>", "resources:printer"],
+	"conditions": {
+		"remoteIPAddress": {"type": "CIDRCondition", "options": {"cidr": "192.168.0.1/16"}},
+		"owner": {"type": "StringMatchCondition", "options": {"matches": "^colin$"}}
+	}
+}`
+
+func decodePolicies(tb testing.TB) policyGetter {
+	tb.Helper()
+
+	var p ladon.DefaultPolicy
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		tb.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	return policyGetter{&p}
+}
+
+// ladonPolicies returns the policy with the conditions of ladon, which are compiled on
+// every evaluation.
+func ladonPolicies(tb testing.TB) policyGetter {
+	tb.Helper()
+
+	p := *decodePolicies(tb)[0]
+	p.Conditions = ladon.Conditions{
+		"remoteIPAddress": &ladon.CIDRCondition{CIDR: "192.168.0.1/16"},
+		"owner":           &ladon.StringMatchCondition{Matches: "^colin$"},
+	}
+
+	return policyGetter{&p}
+}
+
+func newEngine(getter policyGetter) *gin.Engine {
+	// the audit records are built, but never sent
+	analytics.NewAnalytics(analytics.NewAnalyticsOptions(), nil).Stop()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.POST("/v1/authz", func(c *gin.Context) { c.Set("username", "colin") }, NewAuthzController(getter).Authorize)
+
+	return g
+}
+
+func authorize(g *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodPost, "/v1/authz", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	g.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestAuthzController_Authorize(t *testing.T) {
+	g := newEngine(decodePolicies(t))
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "allowed",
+			body: `{"subject":"users:peter","action":"delete","resource":"resources:printer",` +
+				`"context":{"remoteIPAddress":"192.168.0.5","owner":"colin"}}`,
+			want: `{"allowed":true}`,
+		},
+		{
+			// the context of the previous request is not reused
+			name: "no context",
+			body: `{"subject":"users:peter","action":"delete","resource":"resources:printer"}`,
+			want: `{"allowed":false,"denied":true,"reason":"Request was denied by default"}`,
+		},
+		{
+			name: "null context",
+			body: `{"subject":"users:peter","action":"delete","resource":"resources:printer","context":null}`,
+			want: `{"allowed":false,"denied":true,"reason":"Request was denied by default"}`,
+		},
+		{
+			name: "out of cidr",
+			body: `{"subject":"users:peter","action":"delete","resource":"resources:printer",` +
+				`"context":{"remoteIPAddress":"10.0.0.1","owner":"colin"}}`,
+			want: `{"allowed":false,"denied":true,"reason":"Request was denied by default"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := authorize(g, tt.body)
+			if got := w.Body.String(); w.Code != http.StatusOK || got != tt.want {
+				t.Errorf("Authorize() = %d %s, want 200 %s", w.Code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthzController_AuthorizeWithSession(t *testing.T) {
+	sess, err := session.Parse([]byte(`{"name":"ci-build","policies":[` +
+		`{"effect":"allow","actions":["delete"],"resources":["resources:articles:<.*>"]}]}`))
+	if err != nil {
+		t.Fatalf("session.Parse() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.POST("/v1/authz", func(c *gin.Context) {
+		c.Set("username", "colin")
+		c.Set(middleware.SessionKey, sess)
+	}, NewAuthzController(decodePolicies(t)).Authorize)
+
+	context := `"context":{"remoteIPAddress":"192.168.0.5","owner":"colin"}`
+	tests := []struct {
+		name    string
+		body    string
+		allowed bool
+	}{
+		{
+			name:    "allowed by the session",
+			body:    `{"subject":"users:peter","action":"delete","resource":"resources:articles:ladon",` + context + `}`,
+			allowed: true,
+		},
+		{
+			name: "not allowed by the session",
+			body: `{"subject":"users:peter","action":"delete","resource":"resources:printer",` + context + `}`,
+		},
+		{
+			name: "not allowed by the policies",
+			body: `{"subject":"users:colin","action":"delete","resource":"resources:articles:ladon",` + context + `}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := authorize(g, tt.body)
+			if got := strings.HasPrefix(w.Body.String(), `{"allowed":true`); w.Code != http.StatusOK || got != tt.allowed {
+				t.Errorf("Authorize() = %d %s, want allowed %v", w.Code, w.Body.String(), tt.allowed)
+			}
+		})
+	}
+}
+
+// BenchmarkAuthzController_Authorize reports the p99 latency along with the mean one, the
+// ladon conditions are compiled on every request, the decoded ones once.
+func BenchmarkAuthzController_Authorize(b *testing.B) {
+	body := `{"subject":"users:peter","action":"delete","resource":"resources:printer",` +
+		`"context":{"remoteIPAddress":"192.168.0.5","owner":"colin"}}`
+
+	benchmarks := []struct {
+		name     string
+		policies func(testing.TB) policyGetter
+	}{
+		{name: "ladon conditions", policies: ladonPolicies},
+		{name: "compiled conditions", policies: decodePolicies},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			g := newEngine(bm.policies(b))
+			durations := make([]time.Duration, b.N)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				authorize(g, body)
+				durations[i] = time.Since(start)
+			}
+			b.StopTimer()
+
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			b.ReportMetric(float64(durations[b.N*99/100].Nanoseconds()), "p99-ns/op")
+		})
+	}
+}
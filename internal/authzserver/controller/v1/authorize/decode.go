@@ -0,0 +1,134 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/buger/jsonparser"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+)
+
+// buffers reuses the buffers the request bodies are read into.
+var buffers = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// bindRequest decodes the json body of the request into r without going through reflection,
+// the context values are stored right into r.Context. The other content types are bound by gin.
+func bindRequest(c *gin.Context, r *ladon.Request) error {
+	if c.ContentType() != binding.MIMEJSON {
+		return c.ShouldBind(r)
+	}
+
+	buf := buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		buffers.Put(buf)
+	}()
+
+	if _, err := buf.ReadFrom(c.Request.Body); err != nil {
+		return err
+	}
+
+	return decodeRequest(buf.Bytes(), r)
+}
+
+// decodeRequest decodes data into r the way encoding/json does, nothing of r refers to data
+// once it returns. Unlike encoding/json, a malformed value which is skipped may go unnoticed.
+func decodeRequest(data []byte, r *ladon.Request) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+
+	return jsonparser.ObjectEach(data, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		var field *string
+		switch {
+		case bytes.EqualFold(key, []byte("resource")):
+			field = &r.Resource
+		case bytes.EqualFold(key, []byte("action")):
+			field = &r.Action
+		case bytes.EqualFold(key, []byte("subject")):
+			field = &r.Subject
+		case bytes.EqualFold(key, []byte("context")):
+			return decodeContext(value, dataType, r)
+		default:
+			return nil
+		}
+
+		switch dataType {
+		case jsonparser.Null:
+			return nil
+		case jsonparser.String:
+			s, err := jsonparser.ParseString(value)
+			if err != nil {
+				return err
+			}
+			*field = s
+
+			return nil
+		default:
+			return fmt.Errorf("json: cannot unmarshal %s into Go struct field Request.%s of type string", dataType, key)
+		}
+	})
+}
+
+func decodeContext(value []byte, dataType jsonparser.ValueType, r *ladon.Request) error {
+	switch dataType {
+	case jsonparser.Null:
+		r.Context = nil
+
+		return nil
+	case jsonparser.Object:
+	default:
+		return fmt.Errorf("json: cannot unmarshal %s into Go struct field Request.context of type ladon.Context", dataType)
+	}
+
+	if r.Context == nil {
+		r.Context = ladon.Context{}
+	}
+
+	return jsonparser.ObjectEach(value, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		v, err := decodeValue(value, dataType)
+		if err != nil {
+			return err
+		}
+		r.Context[string(key)] = v
+
+		return nil
+	})
+}
+
+// decodeValue returns the value as encoding/json decodes it into an interface{}.
+func decodeValue(value []byte, dataType jsonparser.ValueType) (interface{}, error) {
+	switch dataType {
+	case jsonparser.String:
+		return jsonparser.ParseString(value)
+	case jsonparser.Number:
+		return jsonparser.ParseFloat(value)
+	case jsonparser.Boolean:
+		return jsonparser.ParseBoolean(value)
+	case jsonparser.Null:
+		return nil, nil
+	case jsonparser.NotExist, jsonparser.Unknown:
+		return nil, fmt.Errorf("json: invalid value %q", value)
+	}
+
+	// objects and arrays are rare in the context, they are decoded the usual way
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
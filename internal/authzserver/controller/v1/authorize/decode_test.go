@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorize
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "all fields",
+			body: `{"subject":"users:peter","action":"delete","resource":"resources:printer",` +
+				`"context":{"remoteIPAddress":"192.168.0.5","owner":"colin"}}`,
+		},
+		{
+			name: "context values",
+			body: `{"subject":"users:pe\"teré","context":{"n":1.5e3,"b":true,"z":null,` +
+				`"o":{"a":[1,"x"]},"a":["x"]}}`,
+		},
+		{name: "case insensitive and unknown fields", body: `{"Subject":"users:peter","other":{"a":1}}`},
+		{name: "null fields", body: `{"subject":null,"context":null}`},
+		{name: "empty", body: ` `, wantErr: true},
+		{name: "not an object", body: `["users:peter"]`, wantErr: true},
+		{name: "subject of the wrong type", body: `{"subject":1}`, wantErr: true},
+		{name: "context of the wrong type", body: `{"context":"colin"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &ladon.Request{Context: ladon.Context{}}
+			err := decodeRequest([]byte(tt.body), got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := &ladon.Request{Context: ladon.Context{}}
+			if err := json.Unmarshal([]byte(tt.body), want); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeRequest() = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeRequest(b *testing.B) {
+	body := []byte(`{"subject":"users:peter","action":"delete","resource":"resources:printer",` +
+		`"context":{"remoteIPAddress":"192.168.0.5","owner":"colin"}}`)
+
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var r ladon.Request
+			_ = json.Unmarshal(body, &r)
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		r := &ladon.Request{Context: ladon.Context{}}
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = decodeRequest(body, r)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package debug implements the cache debugging handlers.
+package debug
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/authzserver/load"
+	"github.com/marmotedu/iam/internal/authzserver/load/cache"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Counter counts the cached secrets and policies.
+type Counter interface {
+	Count() (secrets int, policies int)
+}
+
+// ProgressReporter reports the progress of the latest full reload of the cache.
+type ProgressReporter interface {
+	Progress() cache.Progress
+}
+
+// CacheStatus describes the state of the secrets and policies cache.
+type CacheStatus struct {
+	load.Status `json:",inline"`
+
+	Secrets  int             `json:"secrets"`
+	Policies int             `json:"policies"`
+	Progress *cache.Progress `json:"progress,omitempty"`
+}
+
+// DebugController create a debug handler used to inspect and reload the cache.
+type DebugController struct {
+	loader *load.Load
+	cache  Counter
+}
+
+// NewDebugController creates a debug handler.
+func NewDebugController(loader *load.Load, cache Counter) *DebugController {
+	return &DebugController{
+		loader: loader,
+		cache:  cache,
+	}
+}
+
+// ReloadCache resyncs all secrets and policies from iam-apiserver immediately.
+func (d *DebugController) ReloadCache(c *gin.Context) {
+	log.L(c).Info("reload cache function called.")
+
+	if err := d.loader.Reload(); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, "reload cache failed: %s", err.Error()), nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, d.status())
+}
+
+// CacheStatus returns the counts and the last sync time of the cache.
+func (d *DebugController) CacheStatus(c *gin.Context) {
+	core.WriteResponse(c, nil, d.status())
+}
+
+// Readyz reports whether the cache is loaded, along with the progress of the warm-up while
+// it is not. The probes only look at the status code.
+func (d *DebugController) Readyz(c *gin.Context) {
+	status := d.status()
+	if !status.Ready {
+		c.JSON(http.StatusServiceUnavailable, status)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (d *DebugController) status() CacheStatus {
+	secrets, policies := d.cache.Count()
+
+	status := CacheStatus{
+		Status:   d.loader.Status(),
+		Secrets:  secrets,
+		Policies: policies,
+	}
+	if reporter, ok := d.cache.(ProgressReporter); ok {
+		progress := reporter.Progress()
+		status.Progress = &progress
+	}
+
+	return status
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package extauthz
+
+import (
+	"google.golang.org/grpc/encoding"
+	// register the proto codec.
+	_ "google.golang.org/grpc/encoding/proto"
+)
+
+var protoCodec = encoding.GetCodec("proto")
+
+// Codec encodes the ext_authz messages, which are not generated protobuf messages, and
+// falls back to the proto codec for the other services of the grpc server.
+type Codec struct{}
+
+// Marshal returns the wire format of v.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	if rsp, ok := v.(*CheckResponse); ok {
+		return rsp.Marshal(), nil
+	}
+
+	return protoCodec.Marshal(v)
+}
+
+// Unmarshal parses the wire format into v.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	if req, ok := v.(*CheckRequest); ok {
+		return req.Unmarshal(data)
+	}
+
+	return protoCodec.Unmarshal(data, v)
+}
+
+// Name returns the name of the codec.
+func (Codec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package extauthz implements the envoy.service.auth.v3.Authorization grpc service, so that
+// envoy and istio sidecars can use iam-authz-server as their external authorizer.
+package extauthz
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+	"google.golang.org/grpc"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/authorizer"
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Keys of the envoy context extensions which override the fields of the ladon request.
+const (
+	SubjectKey  = "subject"
+	ActionKey   = "action"
+	ResourceKey = "resource"
+)
+
+// SubjectHeader is the request header which carries the subject when no context extension sets it.
+const SubjectHeader = "x-iam-subject"
+
+// ExtAuthzController implements the envoy ext_authz Check rpc.
+type ExtAuthzController struct {
+	auth  auth.CacheStrategy
+	store authorizer.PolicyGetter
+	opts  []authorization.Option
+}
+
+// NewExtAuthzController creates an ext_authz handler. Requests are authenticated by the
+// bearer token of the original http request, signed by one of the secrets in strategy.
+func NewExtAuthzController(
+	strategy auth.CacheStrategy,
+	store authorizer.PolicyGetter,
+	opts ...authorization.Option,
+) *ExtAuthzController {
+	return &ExtAuthzController{
+		auth:  strategy,
+		store: store,
+		opts:  opts,
+	}
+}
+
+// Check maps the envoy CheckRequest onto a ladon request and returns the authorization decision:
+//   - subject: the `subject` context extension, the x-iam-subject header or the source principal;
+//   - action: the `action` context extension or the lower-cased http method;
+//   - resource: the `resource` context extension or the http path without the query string;
+//   - context: the other context extensions, and remoteIPAddress set to the source address.
+func (e *ExtAuthzController) Check(ctx context.Context, req *CheckRequest) (*CheckResponse, error) {
+	log.L(ctx).Info("check function called.")
+
+	attrs := req.Attributes
+	secret, err := e.auth.Verify(attrs.Request.Headers["authorization"])
+	if err != nil {
+		return deny(codeUnauthenticated, http.StatusUnauthorized, errors.ParseCoder(err).String()), nil
+	}
+
+	r := newRequest(attrs)
+	// reject requests which are signed by a secret not allowed to authorize them
+	if !secret.Scope.Allow(r.Action, r.Resource) {
+		return deny(codePermissionDenied, http.StatusForbidden, "Request is not allowed by the secret"), nil
+	}
+
+	r.Context["username"] = secret.Username
+
+	rsp := authorization.NewAuthorizer(authorizer.NewAuthorization(e.store), e.opts...).Authorize(r)
+
+	// the temporary credentials authorize what both their owner and their session allow
+	if secret.Session != nil {
+		rsp = secret.Session.Restrict(r, rsp)
+	}
+	if !rsp.Allowed {
+		return deny(codePermissionDenied, http.StatusForbidden, rsp.Reason), nil
+	}
+
+	return &CheckResponse{Code: codeOK}, nil
+}
+
+func newRequest(attrs AttributeContext) *ladon.Request {
+	r := &ladon.Request{
+		Subject:  attrs.Source.Principal,
+		Action:   strings.ToLower(attrs.Request.Method),
+		Resource: strings.SplitN(attrs.Request.Path, "?", 2)[0],
+		Context:  ladon.Context{},
+	}
+
+	if subject, ok := attrs.Request.Headers[SubjectHeader]; ok {
+		r.Subject = subject
+	}
+
+	for key, value := range attrs.ContextExtensions {
+		switch key {
+		case SubjectKey:
+			r.Subject = value
+		case ActionKey:
+			r.Action = value
+		case ResourceKey:
+			r.Resource = value
+		default:
+			r.Context[key] = value
+		}
+	}
+
+	if attrs.Source.Address != "" {
+		r.Context["remoteIPAddress"] = attrs.Source.Address
+	}
+
+	return r
+}
+
+func deny(code, httpStatus int32, reason string) *CheckResponse {
+	return &CheckResponse{
+		Code:       code,
+		Message:    reason,
+		HTTPStatus: httpStatus,
+		Body:       reason,
+	}
+}
+
+// Register registers the ext_authz service to the grpc server, which must be created with
+// grpc.ForceServerCodec(Codec{}).
+func Register(s *grpc.Server, e *ExtAuthzController) {
+	s.RegisterService(&serviceDesc, e)
+}
+
+// authorizationServer is the server API of envoy.service.auth.v3.Authorization.
+type authorizationServer interface {
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+}
+
+// serviceDesc is the grpc.ServiceDesc of envoy.service.auth.v3.Authorization.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "envoy.service.auth.v3.Authorization",
+	HandlerType: (*authorizationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    checkHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "envoy/service/auth/v3/external_auth.proto",
+}
+
+func checkHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	if interceptor == nil {
+		return srv.(authorizationServer).Check(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/envoy.service.auth.v3.Authorization/Check",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(authorizationServer).Check(ctx, req.(*CheckRequest))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package extauthz
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/ory/ladon"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/marmotedu/iam/internal/authzserver/analytics"
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/session"
+)
+
+func field(num protowire.Number, value []byte) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+
+	return protowire.AppendBytes(b, value)
+}
+
+func entry(key, value string) []byte {
+	return append(field(1, []byte(key)), field(2, []byte(value))...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+
+	return b
+}
+
+func TestCheckRequest_Unmarshal(t *testing.T) {
+	port := protowire.AppendTag(nil, 3, protowire.VarintType)
+	port = protowire.AppendVarint(port, 52312)
+
+	socketAddress := concat(field(2, []byte("10.0.0.1")), port)
+	source := concat(field(1, field(1, socketAddress)), field(4, []byte("spiffe://cluster.local/ns/default/sa/peter")))
+	httpRequest := concat(
+		field(1, []byte("request-id")),
+		field(2, []byte("DELETE")),
+		field(3, entry("authorization", "Bearer token")),
+		field(3, entry(SubjectHeader, "users:peter")),
+		field(4, []byte("/v1/printers/p1?force=true")),
+		field(5, []byte("iam.marmotedu.com")),
+	)
+	attributes := concat(
+		field(1, source),
+		field(2, field(4, []byte("destination"))),
+		field(4, field(2, httpRequest)),
+		field(10, entry(ResourceKey, "resources:printer")),
+		field(10, entry("tenant", "marmotedu")),
+	)
+
+	var got CheckRequest
+	if err := got.Unmarshal(field(1, attributes)); err != nil {
+		t.Fatalf("CheckRequest.Unmarshal() error = %v", err)
+	}
+
+	want := CheckRequest{
+		Attributes: AttributeContext{
+			Source: Peer{
+				Address:   "10.0.0.1",
+				Principal: "spiffe://cluster.local/ns/default/sa/peter",
+			},
+			Request: HTTPRequest{
+				Method:  "DELETE",
+				Headers: map[string]string{"authorization": "Bearer token", SubjectHeader: "users:peter"},
+				Path:    "/v1/printers/p1?force=true",
+				Host:    "iam.marmotedu.com",
+			},
+			ContextExtensions: map[string]string{ResourceKey: "resources:printer", "tenant": "marmotedu"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckRequest.Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	if err := new(CheckRequest).Unmarshal([]byte{0x0a, 0x05}); err == nil {
+		t.Errorf("CheckRequest.Unmarshal() of a truncated message, want error")
+	}
+}
+
+func Test_newRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs AttributeContext
+		want  *ladon.Request
+	}{
+		{
+			name: "default",
+			attrs: AttributeContext{
+				Source:  Peer{Address: "10.0.0.1", Principal: "users:peter"},
+				Request: HTTPRequest{Method: "GET", Path: "/v1/printers?limit=10"},
+			},
+			want: &ladon.Request{
+				Subject:  "users:peter",
+				Action:   "get",
+				Resource: "/v1/printers",
+				Context:  ladon.Context{"remoteIPAddress": "10.0.0.1"},
+			},
+		},
+		{
+			name: "header_and_extensions",
+			attrs: AttributeContext{
+				Source: Peer{Principal: "spiffe://cluster.local/ns/default/sa/peter"},
+				Request: HTTPRequest{
+					Method:  "DELETE",
+					Path:    "/v1/printers/p1",
+					Headers: map[string]string{SubjectHeader: "users:peter"},
+				},
+				ContextExtensions: map[string]string{
+					ActionKey:   "delete",
+					ResourceKey: "resources:printer",
+					"tenant":    "marmotedu",
+				},
+			},
+			want: &ladon.Request{
+				Subject:  "users:peter",
+				Action:   "delete",
+				Resource: "resources:printer",
+				Context:  ladon.Context{"tenant": "marmotedu"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newRequest(tt.attrs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckResponse_Marshal(t *testing.T) {
+	okStatus := concat(protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 0), field(2, nil))
+	deniedStatus := concat(
+		protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), uint64(codePermissionDenied)),
+		field(2, []byte("denied")),
+	)
+	httpStatus := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 403)
+
+	tests := []struct {
+		name string
+		rsp  *CheckResponse
+		want []byte
+	}{
+		{
+			name: "ok",
+			rsp:  &CheckResponse{Code: codeOK},
+			want: concat(field(1, okStatus), field(3, nil)),
+		},
+		{
+			name: "denied",
+			rsp:  deny(codePermissionDenied, 403, "denied"),
+			want: concat(field(1, deniedStatus), field(2, concat(field(1, httpStatus), field(3, []byte("denied"))))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rsp.Marshal(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckResponse.Marshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// policies returns the policies of the users.
+type policies map[string][]*ladon.DefaultPolicy
+
+func (p policies) GetPolicy(key string) ([]*ladon.DefaultPolicy, error) {
+	return p[key], nil
+}
+
+func TestExtAuthzController_Check_session(t *testing.T) {
+	// the decisions are recorded to the analytics, which are not started
+	analytics.NewAnalytics(&analytics.AnalyticsOptions{PoolSize: 1, RecordsBufferSize: 10}, nil)
+
+	secrets := map[string]auth.Secret{
+		"owner":   {Username: "colin", ID: "owner", Key: "owner-key"},
+		"unkeyed": {Username: "colin", ID: "unkeyed"},
+		"temporary": {Username: "colin", ID: "temporary", Key: "temporary-key", Session: &session.Session{
+			Name: "ci",
+			Policies: []*ladon.DefaultPolicy{{
+				Subjects:  []string{"<.*>"},
+				Actions:   []string{"get"},
+				Resources: []string{"<.*>"},
+				Effect:    ladon.AllowAccess,
+			}},
+		}},
+	}
+	strategy := auth.NewCacheStrategy(func(kid string) (auth.Secret, error) {
+		secret, ok := secrets[kid]
+		if !ok {
+			return auth.Secret{}, auth.ErrMissingSecret
+		}
+
+		return secret, nil
+	})
+	e := NewExtAuthzController(strategy, policies{"colin": {{
+		ID:        "all",
+		Subjects:  []string{"<.*>"},
+		Actions:   []string{"<.*>"},
+		Resources: []string{"<.*>"},
+		Effect:    ladon.AllowAccess,
+	}}})
+
+	tests := []struct {
+		name   string
+		kid    string
+		method string
+		want   int32
+	}{
+		{name: "owner delete", kid: "owner", method: "DELETE", want: codeOK},
+		{name: "session get", kid: "temporary", method: "GET", want: codeOK},
+		{name: "session delete", kid: "temporary", method: "DELETE", want: codePermissionDenied},
+		{name: "empty key", kid: "unkeyed", method: "GET", want: codeUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+			token.Header["kid"] = tt.kid
+			signed, err := token.SignedString([]byte(secrets[tt.kid].Key))
+			if err != nil {
+				t.Fatalf("sign token failed: %v", err)
+			}
+
+			rsp, err := e.Check(context.Background(), &CheckRequest{Attributes: AttributeContext{
+				Source: Peer{Principal: "users:colin"},
+				Request: HTTPRequest{
+					Method:  tt.method,
+					Path:    "/v1/printers/p1",
+					Headers: map[string]string{"authorization": "Bearer " + signed},
+				},
+			}})
+			if err != nil {
+				t.Fatalf("ExtAuthzController.Check() error = %v", err)
+			}
+			if rsp.Code != tt.want {
+				t.Errorf("ExtAuthzController.Check() code = %d, want %d: %s", rsp.Code, tt.want, rsp.Message)
+			}
+		})
+	}
+}
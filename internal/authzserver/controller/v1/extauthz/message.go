@@ -0,0 +1,237 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package extauthz
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// errInvalidMessage is returned when a message can not be decoded.
+var errInvalidMessage = errors.New("invalid protobuf message")
+
+// CheckRequest is the subset of envoy.service.auth.v3.CheckRequest used by iam-authz-server.
+// The fields are decoded from the protobuf wire format directly, all the other fields are skipped.
+type CheckRequest struct {
+	Attributes AttributeContext
+}
+
+// AttributeContext is the subset of envoy.service.auth.v3.AttributeContext used by iam-authz-server.
+type AttributeContext struct {
+	// Source is the peer which sent the original request.
+	Source Peer
+	// Request is the original http request.
+	Request HTTPRequest
+	// ContextExtensions are set per route in the envoy ext_authz filter.
+	ContextExtensions map[string]string
+}
+
+// Peer is the subset of envoy.service.auth.v3.AttributeContext.Peer used by iam-authz-server.
+type Peer struct {
+	// Address is the ip address of the peer socket.
+	Address string
+	// Principal is the identity of the peer, e.g. the SPIFFE ID of an istio workload.
+	Principal string
+}
+
+// HTTPRequest is the subset of envoy.service.auth.v3.AttributeContext.HttpRequest used by iam-authz-server.
+type HTTPRequest struct {
+	Method string
+	// Headers keys are lower-cased by envoy.
+	Headers map[string]string
+	Path    string
+	Host    string
+}
+
+// CheckResponse is the subset of envoy.service.auth.v3.CheckResponse returned by iam-authz-server.
+type CheckResponse struct {
+	// Code is the google.rpc.Code of the response, OK allows the request.
+	Code    int32
+	Message string
+	// HTTPStatus and Body are returned to the downstream client when the request is not allowed.
+	HTTPStatus int32
+	Body       string
+}
+
+// google.rpc.Code values used by the responses.
+const (
+	codeOK               int32 = 0
+	codePermissionDenied int32 = 7
+	codeUnauthenticated  int32 = 16
+)
+
+// Unmarshal decodes the request from the protobuf wire format.
+func (r *CheckRequest) Unmarshal(b []byte) error {
+	return walk(b, func(num protowire.Number, value []byte) error {
+		if num == 1 {
+			return r.Attributes.unmarshal(value)
+		}
+
+		return nil
+	})
+}
+
+func (a *AttributeContext) unmarshal(b []byte) error {
+	return walk(b, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 1:
+			return a.Source.unmarshal(value)
+		case 4:
+			// Request.http
+			return walk(value, func(num protowire.Number, value []byte) error {
+				if num == 2 {
+					return a.Request.unmarshal(value)
+				}
+
+				return nil
+			})
+		case 10:
+			if a.ContextExtensions == nil {
+				a.ContextExtensions = map[string]string{}
+			}
+
+			return unmarshalEntry(value, a.ContextExtensions)
+		}
+
+		return nil
+	})
+}
+
+func (p *Peer) unmarshal(b []byte) error {
+	return walk(b, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 1:
+			// Address.socket_address.address
+			return walk(value, func(num protowire.Number, value []byte) error {
+				if num != 1 {
+					return nil
+				}
+
+				return walk(value, func(num protowire.Number, value []byte) error {
+					if num == 2 {
+						p.Address = string(value)
+					}
+
+					return nil
+				})
+			})
+		case 4:
+			p.Principal = string(value)
+		}
+
+		return nil
+	})
+}
+
+func (h *HTTPRequest) unmarshal(b []byte) error {
+	return walk(b, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 2:
+			h.Method = string(value)
+		case 3:
+			if h.Headers == nil {
+				h.Headers = map[string]string{}
+			}
+
+			return unmarshalEntry(value, h.Headers)
+		case 4:
+			h.Path = string(value)
+		case 5:
+			h.Host = string(value)
+		}
+
+		return nil
+	})
+}
+
+// Marshal encodes the response to the protobuf wire format.
+func (r *CheckResponse) Marshal() []byte {
+	var status []byte
+	status = protowire.AppendTag(status, 1, protowire.VarintType)
+	status = protowire.AppendVarint(status, uint64(r.Code))
+	status = protowire.AppendTag(status, 2, protowire.BytesType)
+	status = protowire.AppendString(status, r.Message)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, status)
+
+	if r.Code == codeOK {
+		// an empty ok_response
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+
+		return protowire.AppendBytes(b, nil)
+	}
+
+	var httpStatus []byte
+	httpStatus = protowire.AppendTag(httpStatus, 1, protowire.VarintType)
+	httpStatus = protowire.AppendVarint(httpStatus, uint64(r.HTTPStatus))
+
+	var denied []byte
+	denied = protowire.AppendTag(denied, 1, protowire.BytesType)
+	denied = protowire.AppendBytes(denied, httpStatus)
+	denied = protowire.AppendTag(denied, 3, protowire.BytesType)
+	denied = protowire.AppendString(denied, r.Body)
+
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+
+	return protowire.AppendBytes(b, denied)
+}
+
+// unmarshalEntry decodes a map<string, string> entry into m.
+func unmarshalEntry(b []byte, m map[string]string) error {
+	var key, value string
+	if err := walk(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1:
+			key = string(v)
+		case 2:
+			value = string(v)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	m[key] = value
+
+	return nil
+}
+
+// walk calls fn with the content of every length-delimited field of the message,
+// the fields of other wire types are skipped.
+func walk(b []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errInvalidMessage
+		}
+		b = b[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return errInvalidMessage
+			}
+			b = b[n:]
+
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return errInvalidMessage
+		}
+		b = b[n:]
+
+		if err := fn(num, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
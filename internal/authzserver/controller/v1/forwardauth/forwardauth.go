@@ -0,0 +1,127 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package forwardauth implements the nginx auth_request and traefik ForwardAuth compatible
+// handler, so that reverse proxies can enforce iam policies without changing the applications.
+package forwardauth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/authorizer"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
+)
+
+// Headers set by the reverse proxies to describe the original request. Traefik sets the
+// X-Forwarded-* headers, nginx must be configured to set them, or the X-Original-* headers
+// set by ingress-nginx are used.
+const (
+	ForwardedURIHeader    = "X-Forwarded-Uri"
+	ForwardedMethodHeader = "X-Forwarded-Method"
+	OriginalURLHeader     = "X-Original-Url"
+	OriginalMethodHeader  = "X-Original-Method"
+)
+
+// UserHeader is the response header which carries the authenticated user of an allowed
+// request, the proxies can pass it to the upstream applications.
+const UserHeader = "X-Iam-User"
+
+// PrefixQuery is the query parameter of the forward auth url which is prepended to the
+// resources, e.g. /v1/authz/forward?prefix=shop: authorizes the resource shop:/orders.
+const PrefixQuery = "prefix"
+
+// ForwardAuthController create a forward auth handler used to handle the auth subrequests
+// of reverse proxies.
+type ForwardAuthController struct {
+	store authorizer.PolicyGetter
+	opts  []authorization.Option
+}
+
+// NewForwardAuthController creates a forward auth handler.
+func NewForwardAuthController(store authorizer.PolicyGetter, opts ...authorization.Option) *ForwardAuthController {
+	return &ForwardAuthController{
+		store: store,
+		opts:  opts,
+	}
+}
+
+// Forward authorizes the original request of the reverse proxy against the policies of the
+// user of the Authorization header. It returns 200 if the request is allowed, 403 otherwise.
+func (f *ForwardAuthController) Forward(c *gin.Context) {
+	r, err := newRequest(c.Request.Header, c.Query(PrefixQuery))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// reject requests which are signed by a secret not allowed to authorize them
+	if sc, ok := c.Value(middleware.ScopeKey).(*scope.Scope); ok && !sc.Allow(r.Action, r.Resource) {
+		core.WriteResponse(c, errors.WithCode(code.ErrOutOfScope, "action `%s` on resource `%s` is not allowed by the secret",
+			r.Action, r.Resource), nil)
+
+		return
+	}
+
+	username := c.GetString(middleware.UsernameKey)
+	r.Subject = "users:" + username
+	r.Context["username"] = username
+
+	rsp := authorization.NewAuthorizer(authorizer.NewAuthorization(f.store), f.opts...).Authorize(r)
+
+	// the temporary credentials authorize what both their owner and their session allow
+	if sess, ok := c.Value(middleware.SessionKey).(*session.Session); ok {
+		rsp = sess.Restrict(r, rsp)
+	}
+	if !rsp.Allowed {
+		core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, rsp.Reason), nil)
+
+		return
+	}
+
+	c.Header(UserHeader, username)
+	c.Status(http.StatusOK)
+}
+
+// newRequest maps the forwarded headers onto a ladon request:
+//   - action: the lower-cased method of the original request;
+//   - resource: the prefix and the path of the original request without the query string.
+func newRequest(header http.Header, prefix string) (*ladon.Request, error) {
+	method := header.Get(ForwardedMethodHeader)
+	if method == "" {
+		method = header.Get(OriginalMethodHeader)
+	}
+
+	uri := header.Get(ForwardedURIHeader)
+	if uri == "" {
+		uri = header.Get(OriginalURLHeader)
+	}
+
+	if method == "" || uri == "" {
+		return nil, errors.WithCode(code.ErrValidation, "%s and %s headers must be specified",
+			ForwardedMethodHeader, ForwardedURIHeader)
+	}
+
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "invalid %s header: %s", ForwardedURIHeader, err.Error())
+	}
+
+	return &ladon.Request{
+		Action:   strings.ToLower(method),
+		Resource: prefix + u.Path,
+		Context:  ladon.Context{},
+	}, nil
+}
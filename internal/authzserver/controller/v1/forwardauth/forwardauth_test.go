@@ -0,0 +1,63 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package forwardauth
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ory/ladon"
+)
+
+func Test_newRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  map[string]string
+		prefix  string
+		want    *ladon.Request
+		wantErr bool
+	}{
+		{
+			name:   "forwarded headers",
+			header: map[string]string{ForwardedMethodHeader: "GET", ForwardedURIHeader: "/v1/orders/1?expand=items"},
+			prefix: "shop:",
+			want:   &ladon.Request{Action: "get", Resource: "shop:/v1/orders/1", Context: ladon.Context{}},
+		},
+		{
+			name:   "ingress-nginx headers",
+			header: map[string]string{OriginalMethodHeader: "POST", OriginalURLHeader: "https://shop.example.com/v1/orders"},
+			want:   &ladon.Request{Action: "post", Resource: "/v1/orders", Context: ladon.Context{}},
+		},
+		{
+			name:    "missing method",
+			header:  map[string]string{ForwardedURIHeader: "/v1/orders"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid uri",
+			header:  map[string]string{ForwardedMethodHeader: "GET", ForwardedURIHeader: "orders"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+
+			got, err := newRequest(header, tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newRequest() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
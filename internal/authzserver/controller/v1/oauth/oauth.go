@@ -0,0 +1,184 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package oauth implements the OAuth 2.0 token endpoint, so that the standard OAuth 2.0
+// client libraries can exchange a secretID/secretKey pair for an access token with the
+// client_credentials grant instead of signing the tokens themselves.
+package oauth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// GrantTypeClientCredentials is the only supported grant type.
+const GrantTypeClientCredentials = "client_credentials"
+
+// Issuer is the issuer of the access tokens.
+const Issuer = "iam-authz-server"
+
+// TokenTTL is the lifetime of the access tokens, it is shortened to the expiration time of the secret.
+const TokenTTL = 2 * time.Hour
+
+// OAuth 2.0 error codes, see RFC 6749 section 5.2.
+const (
+	errInvalidRequest       = "invalid_request"
+	errInvalidClient        = "invalid_client"
+	errInvalidScope         = "invalid_scope"
+	errUnsupportedGrantType = "unsupported_grant_type"
+	errServerError          = "server_error"
+)
+
+// TokenResponse is the successful response of the token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// ErrorResponse is the error response of the token endpoint.
+type ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthController create a OAuth 2.0 handler used to issue access tokens.
+type OAuthController struct {
+	get func(kid string) (auth.Secret, error)
+}
+
+// NewOAuthController creates a OAuth 2.0 handler, the clients are the secrets returned by get.
+func NewOAuthController(get func(kid string) (auth.Secret, error)) *OAuthController {
+	return &OAuthController{get: get}
+}
+
+// Token issues an access token signed by the secret of the client. The client is authenticated
+// by the secretID and the secretKey, sent either with HTTP Basic authentication or as the
+// client_id and client_secret form parameters. The space-delimited scope parameter sets the
+// audiences of the token, they must be allowed by the secret and default to the audience of
+// iam-authz-server.
+func (o *OAuthController) Token(c *gin.Context) {
+	log.L(c).Info("oauth token function called.")
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if grantType := c.PostForm("grant_type"); grantType != GrantTypeClientCredentials {
+		writeError(c, http.StatusBadRequest, errUnsupportedGrantType, "grant_type must be client_credentials")
+
+		return
+	}
+
+	clientID, clientSecret, basic := clientCredentials(c)
+	if clientID == "" || clientSecret == "" {
+		writeError(c, http.StatusBadRequest, errInvalidRequest, "missing client credentials")
+
+		return
+	}
+
+	secret, err := o.get(clientID)
+	if err != nil || subtle.ConstantTimeCompare([]byte(secret.Key), []byte(clientSecret)) != 1 ||
+		auth.KeyExpired(secret.Expires) {
+		if basic {
+			c.Header("WWW-Authenticate", `Basic realm="iam"`)
+		}
+		writeError(c, http.StatusUnauthorized, errInvalidClient, "client authentication failed")
+
+		return
+	}
+
+	if secret.Denied != nil {
+		writeError(c, http.StatusUnauthorized, errInvalidClient, secret.Denied.Error())
+
+		return
+	}
+
+	audiences := strings.Fields(c.PostForm("scope"))
+	if len(audiences) == 0 {
+		audiences = []string{auth.AuthzAudience}
+	}
+
+	for _, aud := range audiences {
+		if !secret.Scope.AllowAudience([]string{aud}) {
+			writeError(c, http.StatusBadRequest, errInvalidScope, "scope "+aud+" is not allowed by the secret")
+
+			return
+		}
+	}
+
+	token, expiresIn, err := sign(secret, audiences, time.Now())
+	if err != nil {
+		log.L(c).Errorf("sign access token failed: %s", err.Error())
+		writeError(c, http.StatusInternalServerError, errServerError, "")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   expiresIn,
+		Scope:       strings.Join(audiences, " "),
+	})
+}
+
+// clientCredentials returns the client credentials and whether they are sent with HTTP Basic
+// authentication, whose user name and password are form-urlencoded by the clients.
+func clientCredentials(c *gin.Context) (id, secret string, basic bool) {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		if unescaped, err := url.QueryUnescape(id); err == nil {
+			id = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(secret); err == nil {
+			secret = unescaped
+		}
+
+		return id, secret, true
+	}
+
+	return c.PostForm("client_id"), c.PostForm("client_secret"), false
+}
+
+// sign returns an access token which can be verified by auth.CacheStrategy, and the number
+// of seconds before it expires.
+func sign(secret auth.Secret, audiences []string, now time.Time) (string, int64, error) {
+	expires := now.Add(TokenTTL)
+	if secret.Expires > 0 && time.Unix(secret.Expires, 0).Before(expires) {
+		expires = time.Unix(secret.Expires, 0)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": expires.Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"aud": audiences,
+		"iss": Issuer,
+		"sub": secret.Username,
+	})
+	token.Header["kid"] = secret.ID
+
+	signed, err := token.SignedString([]byte(secret.Key))
+	if err != nil {
+		return "", 0, err
+	}
+
+	return signed, expires.Unix() - now.Unix(), nil
+}
+
+func writeError(c *gin.Context, status int, code, description string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/json"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+)
+
+func TestOAuthController_Token(t *testing.T) {
+	get := func(kid string) (auth.Secret, error) {
+		if kid != "kid1" {
+			return auth.Secret{}, errors.New("secret not found")
+		}
+
+		return auth.Secret{
+			Username: "colin",
+			ID:       "kid1",
+			Key:      "key1",
+			Scope:    &scope.Scope{Audiences: []string{auth.AuthzAudience, "kubernetes"}},
+		}, nil
+	}
+	o := NewOAuthController(get)
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		basic      []string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "form credentials",
+			form:       url.Values{"grant_type": {"client_credentials"}, "client_id": {"kid1"}, "client_secret": {"key1"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "basic credentials with scope",
+			form:       url.Values{"grant_type": {"client_credentials"}, "scope": {"kubernetes"}},
+			basic:      []string{"kid1", "key1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsupported grant type",
+			form:       url.Values{"grant_type": {"password"}, "client_id": {"kid1"}, "client_secret": {"key1"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  errUnsupportedGrantType,
+		},
+		{
+			name:       "wrong secret key",
+			form:       url.Values{"grant_type": {"client_credentials"}, "client_id": {"kid1"}, "client_secret": {"key2"}},
+			wantStatus: http.StatusUnauthorized,
+			wantError:  errInvalidClient,
+		},
+		{
+			name: "scope not allowed",
+			form: url.Values{
+				"grant_type": {"client_credentials"}, "client_id": {"kid1"}, "client_secret": {"key1"},
+				"scope": {"vault"},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  errInvalidScope,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/oauth/token", strings.NewReader(tt.form.Encode()))
+			c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.basic != nil {
+				c.Request.SetBasicAuth(tt.basic[0], tt.basic[1])
+			}
+
+			o.Token(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Token() status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantError != "" {
+				var rsp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil || rsp.Error != tt.wantError {
+					t.Errorf("Token() error = %s, want %s", w.Body.String(), tt.wantError)
+				}
+
+				return
+			}
+
+			var rsp TokenResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+				t.Fatalf("unmarshal token response: %v", err)
+			}
+
+			// the access token must be accepted by the authzserver
+			secret, err := auth.NewCacheStrategy(get).Verify("Bearer " + rsp.AccessToken)
+			if err != nil || secret.Username != "colin" {
+				t.Errorf("Verify() = %v, %v, want colin", secret, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package subjectaccessreview implements the kubernetes webhook authorization handler, so that
+// the access to the kubernetes api can be managed by iam policies.
+package subjectaccessreview
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/authorizer"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Kind is the kind of the kubernetes SubjectAccessReview object.
+const Kind = "SubjectAccessReview"
+
+// ResourcePrefix is the prefix of the ladon resources of the kubernetes api.
+const ResourcePrefix = "kubernetes:"
+
+// defaultAPIVersion is used in the response when the request does not set the apiVersion.
+const defaultAPIVersion = "authorization.k8s.io/v1"
+
+// SubjectAccessReview is the authorization.k8s.io SubjectAccessReview object, both v1 and
+// v1beta1 have the same fields except that v1beta1 names the groups `group`.
+type SubjectAccessReview struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Spec       SubjectAccessReviewSpec   `json:"spec"`
+	Status     SubjectAccessReviewStatus `json:"status"`
+}
+
+// SubjectAccessReviewSpec is the request to authorize, one of ResourceAttributes and
+// NonResourceAttributes must be set.
+type SubjectAccessReviewSpec struct {
+	ResourceAttributes    *ResourceAttributes    `json:"resourceAttributes,omitempty"`
+	NonResourceAttributes *NonResourceAttributes `json:"nonResourceAttributes,omitempty"`
+	User                  string                 `json:"user,omitempty"`
+	Groups                []string               `json:"groups,omitempty"`
+	// GroupsV1beta1 is the groups of v1beta1 objects.
+	GroupsV1beta1 []string            `json:"group,omitempty"`
+	Extra         map[string][]string `json:"extra,omitempty"`
+	UID           string              `json:"uid,omitempty"`
+}
+
+// ResourceAttributes are the attributes of a request to a kubernetes api resource.
+type ResourceAttributes struct {
+	Namespace   string `json:"namespace,omitempty"`
+	Verb        string `json:"verb,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// NonResourceAttributes are the attributes of a request to a kubernetes non-resource path, e.g. /healthz.
+type NonResourceAttributes struct {
+	Path string `json:"path,omitempty"`
+	Verb string `json:"verb,omitempty"`
+}
+
+// SubjectAccessReviewStatus is the authorization decision.
+type SubjectAccessReviewStatus struct {
+	Allowed         bool   `json:"allowed"`
+	Denied          bool   `json:"denied,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// SubjectAccessReviewController create a subject access review handler used to handle
+// kubernetes SubjectAccessReview request.
+type SubjectAccessReviewController struct {
+	store authorizer.PolicyGetter
+	opts  []authorization.Option
+}
+
+// NewSubjectAccessReviewController creates a subject access review handler.
+func NewSubjectAccessReviewController(
+	store authorizer.PolicyGetter,
+	opts ...authorization.Option,
+) *SubjectAccessReviewController {
+	return &SubjectAccessReviewController{
+		store: store,
+		opts:  opts,
+	}
+}
+
+// Review authorizes the SubjectAccessReview against the policies of the authenticated user.
+// A request which is not allowed is never denied explicitly, so that the other kubernetes
+// authorizers, e.g. RBAC, can still allow it.
+func (s *SubjectAccessReviewController) Review(c *gin.Context) {
+	log.L(c).Info("subject access review function called.")
+
+	var r SubjectAccessReview
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	request, err := newRequest(r.Spec)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// reject requests which are signed by a secret not allowed to authorize them
+	if sc, ok := c.Value(middleware.ScopeKey).(*scope.Scope); ok && !sc.Allow(request.Action, request.Resource) {
+		core.WriteResponse(c, errors.WithCode(code.ErrOutOfScope, "action `%s` on resource `%s` is not allowed by the secret",
+			request.Action, request.Resource), nil)
+
+		return
+	}
+
+	request.Context["username"] = c.GetString(middleware.UsernameKey)
+	rsp := authorization.NewAuthorizer(authorizer.NewAuthorization(s.store), s.opts...).Authorize(request)
+
+	// the temporary credentials authorize what both their owner and their session allow
+	if sess, ok := c.Value(middleware.SessionKey).(*session.Session); ok {
+		rsp = sess.Restrict(request, rsp)
+	}
+
+	if r.APIVersion == "" {
+		r.APIVersion = defaultAPIVersion
+	}
+	r.Kind = Kind
+	r.Status = SubjectAccessReviewStatus{Allowed: rsp.Allowed, Reason: rsp.Reason}
+
+	c.JSON(http.StatusOK, r)
+}
+
+// newRequest maps the SubjectAccessReview onto a ladon request:
+//   - subject: users:<user>;
+//   - action: the kubernetes verb, e.g. get, list, watch, create;
+//   - resource: kubernetes:<namespace>:<group>:<resource>[/<subresource>]:<name> for the api
+//     resources, the core group is empty, or kubernetes:<path> for the non-resource paths;
+//   - context: groups set to the kubernetes groups of the user.
+func newRequest(spec SubjectAccessReviewSpec) (*ladon.Request, error) {
+	if spec.User == "" {
+		return nil, errors.WithCode(code.ErrValidation, "spec.user must be specified")
+	}
+
+	groups := spec.Groups
+	if len(groups) == 0 {
+		groups = spec.GroupsV1beta1
+	}
+
+	r := &ladon.Request{
+		Subject: "users:" + spec.User,
+		Context: ladon.Context{"groups": groups},
+	}
+
+	switch {
+	case spec.ResourceAttributes != nil:
+		attrs := spec.ResourceAttributes
+
+		resource := attrs.Resource
+		if attrs.Subresource != "" {
+			resource += "/" + attrs.Subresource
+		}
+
+		r.Action = attrs.Verb
+		r.Resource = ResourcePrefix + strings.Join([]string{attrs.Namespace, attrs.Group, resource, attrs.Name}, ":")
+	case spec.NonResourceAttributes != nil:
+		r.Action = spec.NonResourceAttributes.Verb
+		r.Resource = ResourcePrefix + spec.NonResourceAttributes.Path
+	default:
+		return nil, errors.WithCode(code.ErrValidation, "one of spec.resourceAttributes and spec.nonResourceAttributes must be specified")
+	}
+
+	return r, nil
+}
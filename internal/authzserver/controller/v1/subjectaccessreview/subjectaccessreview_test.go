@@ -0,0 +1,92 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package subjectaccessreview
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ory/ladon"
+)
+
+func Test_newRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    SubjectAccessReviewSpec
+		want    *ladon.Request
+		wantErr bool
+	}{
+		{
+			name: "resource",
+			spec: SubjectAccessReviewSpec{
+				User:   "colin",
+				Groups: []string{"groups:admins", "system:authenticated"},
+				ResourceAttributes: &ResourceAttributes{
+					Namespace:   "default",
+					Verb:        "get",
+					Resource:    "pods",
+					Subresource: "log",
+					Name:        "nginx",
+				},
+			},
+			want: &ladon.Request{
+				Subject:  "users:colin",
+				Action:   "get",
+				Resource: "kubernetes:default::pods/log:nginx",
+				Context:  ladon.Context{"groups": []string{"groups:admins", "system:authenticated"}},
+			},
+		},
+		{
+			name: "cluster scoped resource of v1beta1",
+			spec: SubjectAccessReviewSpec{
+				User:               "colin",
+				GroupsV1beta1:      []string{"system:authenticated"},
+				ResourceAttributes: &ResourceAttributes{Verb: "list", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+			},
+			want: &ladon.Request{
+				Subject:  "users:colin",
+				Action:   "list",
+				Resource: "kubernetes::rbac.authorization.k8s.io:clusterroles:",
+				Context:  ladon.Context{"groups": []string{"system:authenticated"}},
+			},
+		},
+		{
+			name: "non-resource",
+			spec: SubjectAccessReviewSpec{
+				User:                  "colin",
+				NonResourceAttributes: &NonResourceAttributes{Path: "/healthz", Verb: "get"},
+			},
+			want: &ladon.Request{
+				Subject:  "users:colin",
+				Action:   "get",
+				Resource: "kubernetes:/healthz",
+				Context:  ladon.Context{"groups": []string(nil)},
+			},
+		},
+		{
+			name:    "missing attributes",
+			spec:    SubjectAccessReviewSpec{User: "colin"},
+			wantErr: true,
+		},
+		{
+			name:    "missing user",
+			spec:    SubjectAccessReviewSpec{NonResourceAttributes: &NonResourceAttributes{Path: "/healthz", Verb: "get"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRequest(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newRequest() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package tokenreview implements the kubernetes webhook token authentication handler, so that
+// kubernetes clusters can authenticate the users by the bearer tokens signed by iam secrets.
+package tokenreview
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/secretusage"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Kind is the kind of the kubernetes TokenReview object.
+const Kind = "TokenReview"
+
+// SecretIDKey is the key of the user extra which carries the id of the secret signing the token.
+const SecretIDKey = "iam.marmotedu.com/secret-id"
+
+// defaultAPIVersion is used in the response when the request does not set the apiVersion.
+const defaultAPIVersion = "authentication.k8s.io/v1"
+
+// TokenReview is the authentication.k8s.io TokenReview object, both v1 and v1beta1 have
+// the same fields.
+type TokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       TokenReviewSpec   `json:"spec"`
+	Status     TokenReviewStatus `json:"status"`
+}
+
+// TokenReviewSpec is the spec of the TokenReview object.
+type TokenReviewSpec struct {
+	Token     string   `json:"token"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// TokenReviewStatus is the result of the token authentication.
+type TokenReviewStatus struct {
+	Authenticated bool     `json:"authenticated"`
+	User          UserInfo `json:"user,omitempty"`
+	Audiences     []string `json:"audiences,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// UserInfo is the user authenticated by the token.
+type UserInfo struct {
+	Username string              `json:"username,omitempty"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// TokenReviewController create a token review handler used to handle kubernetes TokenReview request.
+type TokenReviewController struct {
+	auth        auth.CacheStrategy
+	memberships authorization.MembershipGetter
+}
+
+// NewTokenReviewController creates a token review handler. The tokens are verified by the
+// secrets returned by get, and the groups of the users are returned by memberships.
+func NewTokenReviewController(
+	get func(kid string) (auth.Secret, error),
+	memberships authorization.MembershipGetter,
+) *TokenReviewController {
+	return &TokenReviewController{
+		auth:        auth.NewCacheStrategy(get).WithUsage(secretusage.Record),
+		memberships: memberships,
+	}
+}
+
+// Review authenticates the token of the TokenReview request. The user is the owner of the
+// secret signing the token, and the groups are the subjects of the groups and roles of the
+// user, e.g. groups:admins. A token which can not be verified is not authenticated, and an
+// error is only returned when the request is malformed.
+func (t *TokenReviewController) Review(c *gin.Context) {
+	log.L(c).Info("token review function called.")
+
+	var r TokenReview
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if r.APIVersion == "" {
+		r.APIVersion = defaultAPIVersion
+	}
+	r.Kind = Kind
+	r.Status = t.review(r.Spec)
+
+	c.JSON(http.StatusOK, r)
+}
+
+func (t *TokenReviewController) review(spec TokenReviewSpec) TokenReviewStatus {
+	secret, tokenAudiences, err := t.auth.VerifyAudiences("Bearer " + spec.Token)
+	if err != nil {
+		return TokenReviewStatus{Error: errors.ParseCoder(err).String()}
+	}
+
+	// the token is valid for the requested audiences it is issued for and allowed by the secret
+	audiences := make([]string, 0, len(spec.Audiences))
+	for _, aud := range spec.Audiences {
+		if contains(tokenAudiences, aud) && secret.Scope.AllowAudience([]string{aud}) {
+			audiences = append(audiences, aud)
+		}
+	}
+
+	if len(spec.Audiences) > 0 && len(audiences) == 0 {
+		return TokenReviewStatus{Error: "token is not issued for the requested audiences"}
+	}
+
+	return TokenReviewStatus{
+		Authenticated: true,
+		User: UserInfo{
+			Username: secret.Username,
+			Groups:   t.memberships.GetGroups(secret.Username, "users:"+secret.Username),
+			Extra:    map[string][]string{SecretIDKey: {secret.ID}},
+		},
+		Audiences: audiences,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tokenreview
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+)
+
+type membershipFunc func(username, member string) []string
+
+func (f membershipFunc) GetGroups(username, member string) []string {
+	return f(username, member)
+}
+
+func sign(t *testing.T, kid, key, aud string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"aud": aud,
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString([]byte(key))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	return signed
+}
+
+func TestTokenReviewController_review(t *testing.T) {
+	secrets := map[string]auth.Secret{
+		"kid1": {Username: "colin", ID: "kid1", Key: "key1"},
+		"kid2": {Username: "colin", ID: "kid2", Key: "key2", Scope: &scope.Scope{Audiences: []string{"kubernetes"}}},
+	}
+	get := func(kid string) (auth.Secret, error) {
+		secret, ok := secrets[kid]
+		if !ok {
+			return auth.Secret{}, errors.New("secret not found")
+		}
+
+		return secret, nil
+	}
+	memberships := membershipFunc(func(username, member string) []string {
+		if username == "colin" && member == "users:colin" {
+			return []string{"groups:admins"}
+		}
+
+		return nil
+	})
+
+	tests := []struct {
+		name string
+		spec TokenReviewSpec
+		want TokenReviewStatus
+	}{
+		{
+			name: "authenticated",
+			spec: TokenReviewSpec{Token: sign(t, "kid1", "key1", "iam")},
+			want: TokenReviewStatus{
+				Authenticated: true,
+				User: UserInfo{
+					Username: "colin",
+					Groups:   []string{"groups:admins"},
+					Extra:    map[string][]string{SecretIDKey: {"kid1"}},
+				},
+				Audiences: []string{},
+			},
+		},
+		{
+			name: "allowed audiences",
+			spec: TokenReviewSpec{Token: sign(t, "kid2", "key2", "kubernetes"), Audiences: []string{"kubernetes", "vault"}},
+			want: TokenReviewStatus{
+				Authenticated: true,
+				User: UserInfo{
+					Username: "colin",
+					Groups:   []string{"groups:admins"},
+					Extra:    map[string][]string{SecretIDKey: {"kid2"}},
+				},
+				Audiences: []string{"kubernetes"},
+			},
+		},
+		{
+			name: "audiences not allowed",
+			spec: TokenReviewSpec{Token: sign(t, "kid2", "key2", "kubernetes"), Audiences: []string{"vault"}},
+			want: TokenReviewStatus{Error: "token is not issued for the requested audiences"},
+		},
+		{
+			name: "audiences not issued",
+			spec: TokenReviewSpec{Token: sign(t, "kid1", "key1", "iam"), Audiences: []string{"kubernetes"}},
+			want: TokenReviewStatus{Error: "token is not issued for the requested audiences"},
+		},
+		{
+			name: "wrong key",
+			spec: TokenReviewSpec{Token: sign(t, "kid1", "key2", "iam")},
+			want: TokenReviewStatus{Error: "Signature is invalid"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTokenReviewController(get, memberships)
+			if got := tr.review(tt.spec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("review() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
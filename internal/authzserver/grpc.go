@@ -0,0 +1,67 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authzserver
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/extauthz"
+	"github.com/marmotedu/iam/internal/authzserver/load/cache"
+	"github.com/marmotedu/iam/internal/pkg/grpcerror"
+	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/secretusage"
+	"github.com/marmotedu/iam/internal/pkg/upgrade"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+type grpcAuthzServer struct {
+	*grpc.Server
+	address string
+}
+
+// newGRPCAuthzServer creates the grpc server which serves the envoy ext_authz api.
+func newGRPCAuthzServer(address string, maxMsgSize int, authzOptions []authorization.Option) (*grpcAuthzServer, error) {
+	cacheIns, err := cache.GetCacheInsOr(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxMsgSize),
+		grpc.ForceServerCodec(extauthz.Codec{}),
+		grpc.ChainUnaryInterceptor(grpcerror.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpcerror.StreamServerInterceptor()),
+	}
+	grpcServer := grpc.NewServer(opts...)
+
+	strategy := auth.NewCacheStrategy(getSecretFunc()).WithUsage(secretusage.Record)
+	extauthz.Register(grpcServer, extauthz.NewExtAuthzController(strategy, cacheIns, authzOptions...))
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+
+	return &grpcAuthzServer{grpcServer, address}, nil
+}
+
+func (s *grpcAuthzServer) Run() {
+	listen, err := upgrade.Listen(s.address)
+	if err != nil {
+		log.Fatalf("failed to listen: %s", err.Error())
+	}
+
+	go func() {
+		if err := s.Serve(listen); err != nil {
+			log.Fatalf("failed to start grpc server: %s", err.Error())
+		}
+	}()
+
+	log.Infof("start grpc server at %s", s.address)
+}
+
+func (s *grpcAuthzServer) Close() {
+	s.GracefulStop()
+	log.Infof("GRPC server on %s stopped", s.address)
+}
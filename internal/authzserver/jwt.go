@@ -10,10 +10,18 @@ import (
 	"github.com/marmotedu/iam/internal/authzserver/load/cache"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
 	"github.com/marmotedu/iam/internal/pkg/middleware/auth"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/secretusage"
+	"github.com/marmotedu/iam/internal/pkg/session"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
 )
 
+// newCacheAuth authenticates the requests by the bearer tokens or the signatures made with the secrets.
 func newCacheAuth() middleware.AuthStrategy {
-	return auth.NewCacheStrategy(getSecretFunc())
+	return auth.NewSecretStrategy(
+		auth.NewCacheStrategy(getSecretFunc()).WithUsage(secretusage.Record),
+		auth.NewHMACStrategy(getSecretFunc()).WithUsage(secretusage.Record),
+	)
 }
 
 func getSecretFunc() func(string) (auth.Secret, error) {
@@ -28,11 +36,24 @@ func getSecretFunc() func(string) (auth.Secret, error) {
 			return auth.Secret{}, err
 		}
 
+		sc, err := scope.FromSecretInfo(secret)
+		if err != nil {
+			return auth.Secret{}, errors.Wrap(err, "get secret scope failed")
+		}
+
+		sess, err := session.FromSecretInfo(secret)
+		if err != nil {
+			return auth.Secret{}, errors.Wrap(err, "get secret session failed")
+		}
+
 		return auth.Secret{
 			Username: secret.Username,
 			ID:       secret.SecretId,
 			Key:      secret.SecretKey,
 			Expires:  secret.Expires,
+			Scope:    sc,
+			Session:  sess,
+			Denied:   userstate.Check(secret.Username, userstate.FromSecretInfo(secret)),
 		}, nil
 	}
 }
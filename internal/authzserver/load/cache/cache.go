@@ -5,22 +5,44 @@
 package cache
 
 import (
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/dgraph-io/ristretto"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"github.com/marmotedu/errors"
 	"github.com/ory/ladon"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/marmotedu/iam/internal/authzserver/load"
 	"github.com/marmotedu/iam/internal/authzserver/store"
+	"github.com/marmotedu/iam/internal/pkg/membership"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/pkg/log"
 )
 
 // Cache is used to store secrets and policies.
 type Cache struct {
-	lock     *sync.RWMutex
-	cli      store.Factory
-	secrets  *ristretto.Cache
-	policies *ristretto.Cache
+	lock    *sync.RWMutex
+	cli     store.Factory
+	secrets *ristretto.Cache
+	// policies holds the *policyShards of the policy partitions, a reload of all the
+	// policies swaps them at once.
+	policies atomic.Value
+	// userSecrets indexes the cached secret ids by username, so the secrets of a
+	// single user can be replaced.
+	userSecrets map[string]map[string]struct{}
+	// userPolicies counts the cached policies by username and tenant. The policies are
+	// cached in one partition per tenant, keyed by tenant.Key(username, tenant).
+	userPolicies map[string]map[string]int
+	// memberships indexes the members of the groups and roles by username of the group owner.
+	memberships map[string]membership.Index
+	// policyEpoch changes every time the cached policies change.
+	policyEpoch uint64
+	// progress tracks the latest full reload.
+	progress progress
 }
 
 var (
@@ -28,6 +50,8 @@ var (
 	ErrSecretNotFound = errors.New("secret not found")
 	// ErrPolicyNotFound defines policy not found error.
 	ErrPolicyNotFound = errors.New("policy not found")
+	// ErrChangesUnavailable defines the changes can not be listed, a full reload is required.
+	ErrChangesUnavailable = errors.New("changes unavailable")
 )
 
 var (
@@ -39,10 +63,7 @@ var (
 func GetCacheInsOr(cli store.Factory) (*Cache, error) {
 	var err error
 	if cli != nil {
-		var (
-			secretCache *ristretto.Cache
-			policyCache *ristretto.Cache
-		)
+		var secretCache *ristretto.Cache
 
 		onceCache.Do(func() {
 			c := &ristretto.Config{
@@ -56,17 +77,16 @@ func GetCacheInsOr(cli store.Factory) (*Cache, error) {
 			if err != nil {
 				return
 			}
-			policyCache, err = ristretto.NewCache(c)
-			if err != nil {
-				return
-			}
 
 			cacheIns = &Cache{
-				cli:      cli,
-				lock:     new(sync.RWMutex),
-				secrets:  secretCache,
-				policies: policyCache,
+				cli:          cli,
+				lock:         new(sync.RWMutex),
+				secrets:      secretCache,
+				userSecrets:  make(map[string]map[string]struct{}),
+				userPolicies: make(map[string]map[string]int),
+				memberships:  make(map[string]membership.Index),
 			}
+			cacheIns.policies.Store(newPolicyShards())
 		})
 	}
 
@@ -86,45 +106,314 @@ func (c *Cache) GetSecret(key string) (*pb.SecretInfo, error) {
 	return value.(*pb.SecretInfo), nil
 }
 
-// GetPolicy return user's ladon policies for the given user.
+// GetPolicy return user's ladon policies for the given policy partition key, see tenant.Key.
 func (c *Cache) GetPolicy(key string) ([]*ladon.DefaultPolicy, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	idx, ok := c.policyShards().get(key)
+	if !ok {
+		return nil, ErrPolicyNotFound
+	}
+
+	return idx.policies, nil
+}
 
-	value, ok := c.policies.Get(key)
+// GetCandidates returns the policies of the given policy partition key which may match one of
+// the subjects and the resource, in a time which does not grow with the size of the partition.
+func (c *Cache) GetCandidates(key string, subjects []string, resource string) ([]*ladon.DefaultPolicy, error) {
+	idx, ok := c.policyShards().get(key)
 	if !ok {
 		return nil, ErrPolicyNotFound
 	}
 
-	return value.([]*ladon.DefaultPolicy), nil
+	return idx.candidates(subjects, resource), nil
+}
+
+func (c *Cache) policyShards() *policyShards {
+	return c.policies.Load().(*policyShards)
+}
+
+// GetGroups returns the subjects of the groups and roles of the user which contain member.
+func (c *Cache) GetGroups(username, member string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.memberships[username].Groups(member)
+}
+
+// GetTags returns the tags of the user, the secret or the policy of the given policy partition
+// key named like a subject or a resource: users:<name>, secrets:<id> or policies:<name>. The
+// tags of a user are known from the secrets of the user.
+func (c *Cache) GetTags(key, name string) (tags.Tags, bool) {
+	kind, id, ok := strings.Cut(name, ":")
+	if !ok {
+		return nil, false
+	}
+
+	switch kind {
+	case "users":
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+
+		for secretID := range c.userSecrets[id] {
+			if value, ok := c.secrets.Get(secretID); ok {
+				_, owner := tags.FromSecretInfo(value.(*pb.SecretInfo))
+
+				return owner, true
+			}
+		}
+	case "secrets":
+		if value, ok := c.secrets.Get(id); ok {
+			secret, _ := tags.FromSecretInfo(value.(*pb.SecretInfo))
+
+			return secret, true
+		}
+	case "policies":
+		policies, _ := c.GetPolicy(key)
+		for _, policy := range policies {
+			if policy.ID == id {
+				return tags.FromPolicy(policy), true
+			}
+		}
+	}
+
+	return nil, false
 }
 
-// Reload reload secrets and policies.
+// Reload reload secrets and policies. The secrets, the policies and the memberships are
+// pulled concurrently, and only installed once all of them are, so that the requests never
+// see a partial snapshot.
 func (c *Cache) Reload() error {
+	c.progress.start()
+
+	var (
+		g           errgroup.Group
+		secrets     map[string]*pb.SecretInfo
+		policies    map[string][]*ladon.DefaultPolicy
+		memberships map[string]membership.Index
+	)
+
+	g.Go(func() (err error) {
+		if secrets, err = c.cli.Secrets().List(); err != nil {
+			return errors.Wrap(err, "list secrets failed")
+		}
+		c.progress.loaded(&c.progress.state.Secrets, len(secrets))
+
+		return nil
+	})
+	g.Go(func() (err error) {
+		if policies, err = c.cli.Policies().List(); err != nil {
+			return errors.Wrap(err, "list policies failed")
+		}
+		n := 0
+		for _, pols := range policies {
+			n += len(pols)
+		}
+		c.progress.loaded(&c.progress.state.Policies, n)
+
+		return nil
+	})
+	// the memberships decide the subjects the policies apply to, so reload them together
+	g.Go(func() (err error) {
+		if memberships, err = c.cli.Memberships().List(); err != nil {
+			return errors.Wrap(err, "list memberships failed")
+		}
+		c.progress.loaded(&c.progress.state.Memberships, len(memberships))
+
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		c.progress.fail(err)
+
+		return err
+	}
+
+	// the requests keep using the previous policies until all of them are indexed
+	shards := newPolicyShards()
+	userPolicies := make(map[string]map[string]int)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.secrets.Clear()
+	c.userSecrets = make(map[string]map[string]struct{})
+	for key, val := range secrets {
+		c.setSecret(key, val)
+	}
+	c.secrets.Wait()
+
+	c.memberships = memberships
+	c.userPolicies = userPolicies
+	for key, val := range policies {
+		c.setPolicies(shards, key, val)
+	}
+	c.policies.Store(shards)
+	c.bumpPolicyEpoch()
+	c.progress.done()
+
+	return nil
+}
+
+// ReloadSecrets reload the secrets of the given user.
+func (c *Cache) ReloadSecrets(username string) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	// reload secrets
-	secrets, err := c.cli.Secrets().List()
+	secrets, err := c.cli.Secrets().ListByUser(username)
 	if err != nil {
-		return errors.Wrap(err, "list secrets failed")
+		return errors.Wrapf(err, "list secrets of user %s failed", username)
 	}
 
-	c.secrets.Clear()
+	for key := range c.userSecrets[username] {
+		c.secrets.Del(key)
+	}
+	delete(c.userSecrets, username)
+
 	for key, val := range secrets {
-		c.secrets.Set(key, val, 1)
+		c.setSecret(key, val)
+	}
+
+	return nil
+}
+
+// ReloadChangesSince reloads the secrets and policies of the users changed after the
+// revision, and returns the revision of the latest change reloaded.
+func (c *Cache) ReloadChangesSince(revision int64) (int64, error) {
+	lister, ok := c.cli.(store.ChangeLister)
+	if !ok {
+		return revision, ErrChangesUnavailable
 	}
 
-	// reload policies
-	policies, err := c.cli.Policies().List()
+	resp, err := lister.ListChangesSince(revision)
 	if err != nil {
-		return errors.Wrap(err, "list policies failed")
+		return revision, errors.Wrapf(err, "list changes since %d failed", revision)
+	}
+	if resp.Reset {
+		return revision, ErrChangesUnavailable
 	}
 
-	c.policies.Clear()
-	for key, val := range policies {
-		c.policies.Set(key, val, 1)
+	// a user changed several times is reloaded once
+	type reload struct {
+		command  load.NotificationCommand
+		username string
+	}
+	reloaded := make(map[reload]bool, len(resp.Changes))
+	for _, change := range resp.Changes {
+		key := reload{command: change.Command, username: change.Username}
+		if reloaded[key] {
+			continue
+		}
+		reloaded[key] = true
+
+		if change.Command == load.NoticePolicyChanged {
+			err = c.ReloadPolicies(change.Username)
+		} else {
+			err = c.ReloadSecrets(change.Username)
+		}
+		if err != nil {
+			return revision, err
+		}
+	}
+
+	return resp.Revision, nil
+}
+
+// ReloadPolicies reload the policies of the given user.
+func (c *Cache) ReloadPolicies(username string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	policies, err := c.cli.Policies().ListByUser(username)
+	if err != nil {
+		return errors.Wrapf(err, "list policies of user %s failed", username)
+	}
+
+	memberships, err := c.cli.Memberships().ListByUser(username)
+	if err != nil {
+		return errors.Wrapf(err, "list memberships of user %s failed", username)
+	}
+
+	if len(memberships) == 0 {
+		delete(c.memberships, username)
+	} else {
+		c.memberships[username] = memberships
+	}
+
+	// replace the partitions in place, and only then remove the ones which are gone
+	shards := c.policyShards()
+	previous := c.userPolicies[username]
+	delete(c.userPolicies, username)
+
+	c.setPolicies(shards, username, policies)
+	for name := range previous {
+		if _, ok := c.userPolicies[username][name]; !ok {
+			shards.del(tenant.Key(username, name))
+		}
 	}
+	c.bumpPolicyEpoch()
 
 	return nil
 }
+
+// PolicyEpoch returns the policy epoch, which changes every time the cached policies change.
+func (c *Cache) PolicyEpoch() uint64 {
+	return atomic.LoadUint64(&c.policyEpoch)
+}
+
+// bumpPolicyEpoch moves to a new policy epoch, the policy changes must be visible.
+func (c *Cache) bumpPolicyEpoch() {
+	atomic.AddUint64(&c.policyEpoch, 1)
+}
+
+// Count returns the number of cached secrets and policies.
+func (c *Cache) Count() (secrets int, policies int) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, keys := range c.userSecrets {
+		secrets += len(keys)
+	}
+
+	for _, tenants := range c.userPolicies {
+		for _, n := range tenants {
+			policies += n
+		}
+	}
+
+	return secrets, policies
+}
+
+// setPolicies caches the policies of the user partitioned by tenant, so that the policies
+// of one tenant are never evaluated for the requests of another.
+func (c *Cache) setPolicies(shards *policyShards, username string, policies []*ladon.DefaultPolicy) {
+	partitions := make(map[string][]*ladon.DefaultPolicy)
+	for _, policy := range policies {
+		name := tenant.FromPolicy(policy)
+		partitions[name] = append(partitions[name], policy)
+	}
+
+	if len(partitions) == 0 {
+		return
+	}
+
+	c.userPolicies[username] = make(map[string]int)
+	for name, partition := range partitions {
+		shards.set(tenant.Key(username, name), partition)
+		c.userPolicies[username][name] = len(partition)
+	}
+}
+
+func (c *Cache) setSecret(key string, secret *pb.SecretInfo) {
+	// a secret without key can not verify a token
+	if secret.SecretKey == "" {
+		log.Warnf("Skip secret %s of user %s without key", key, secret.Username)
+
+		return
+	}
+
+	c.secrets.Set(key, secret, 1)
+
+	if c.userSecrets[secret.Username] == nil {
+		c.userSecrets[secret.Username] = make(map[string]struct{})
+	}
+	c.userSecrets[secret.Username][key] = struct{}{}
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ory/ladon"
+)
+
+// policyIndex indexes the policies of a partition, so that a request is only evaluated
+// against the policies which may match it instead of all of them. The candidates are a
+// superset of the matching policies, in the order of the partition.
+type policyIndex struct {
+	policies []*ladon.DefaultPolicy
+	// prefixes are the literal prefixes of the resources of every policy, a resource can
+	// only match a pattern which starts with the literal part of the pattern.
+	prefixes [][]string
+	// bySubject indexes the policies whose subjects are all literal by subject.
+	bySubject map[string][]int
+	// byResource indexes the policies with a pattern subject by resource prefix.
+	byResource map[string][]int
+	// prefixLens are the distinct lengths of the byResource prefixes.
+	prefixLens []int
+}
+
+func newPolicyIndex(policies []*ladon.DefaultPolicy) *policyIndex {
+	idx := &policyIndex{
+		policies:   policies,
+		prefixes:   make([][]string, len(policies)),
+		bySubject:  make(map[string][]int),
+		byResource: make(map[string][]int),
+	}
+
+	lens := make(map[int]struct{})
+	for i, policy := range policies {
+		for _, resource := range policy.Resources {
+			idx.prefixes[i] = append(idx.prefixes[i], literalPrefix(resource, policy.GetStartDelimiter()))
+		}
+
+		if !hasPattern(policy.Subjects, policy.GetStartDelimiter()) {
+			for _, subject := range policy.Subjects {
+				idx.bySubject[subject] = appendOnce(idx.bySubject[subject], i)
+			}
+
+			continue
+		}
+
+		for _, prefix := range idx.prefixes[i] {
+			idx.byResource[prefix] = appendOnce(idx.byResource[prefix], i)
+			lens[len(prefix)] = struct{}{}
+		}
+	}
+
+	for n := range lens {
+		idx.prefixLens = append(idx.prefixLens, n)
+	}
+	sort.Ints(idx.prefixLens)
+
+	return idx
+}
+
+// candidates returns the policies which may match one of the subjects and the resource.
+func (idx *policyIndex) candidates(subjects []string, resource string) []*ladon.DefaultPolicy {
+	var hits []int
+	for _, subject := range subjects {
+		for _, i := range idx.bySubject[subject] {
+			if idx.matchesPrefix(i, resource) {
+				hits = append(hits, i)
+			}
+		}
+	}
+
+	for _, n := range idx.prefixLens {
+		if n > len(resource) {
+			break
+		}
+		hits = append(hits, idx.byResource[resource[:n]]...)
+	}
+
+	sort.Ints(hits)
+
+	ret := make([]*ladon.DefaultPolicy, 0, len(hits))
+	for k, i := range hits {
+		if k > 0 && hits[k-1] == i {
+			continue
+		}
+		ret = append(ret, idx.policies[i])
+	}
+
+	return ret
+}
+
+func (idx *policyIndex) matchesPrefix(i int, resource string) bool {
+	for _, prefix := range idx.prefixes[i] {
+		if strings.HasPrefix(resource, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// literalPrefix returns the part of the ladon pattern before its first delimiter.
+func literalPrefix(pattern string, delimiter byte) string {
+	if i := strings.IndexByte(pattern, delimiter); i >= 0 {
+		return pattern[:i]
+	}
+
+	return pattern
+}
+
+func hasPattern(values []string, delimiter byte) bool {
+	for _, value := range values {
+		if strings.IndexByte(value, delimiter) >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendOnce appends i unless it is already the last one, a policy may repeat a subject
+// or a resource prefix.
+func appendOnce(ids []int, i int) []int {
+	if len(ids) > 0 && ids[len(ids)-1] == i {
+		return ids
+	}
+
+	return append(ids, i)
+}
+
+// policyShardCount is the number of shards of the policy partitions.
+const policyShardCount = 64
+
+// policyShards holds the indexed policy partitions in shards, each with its own lock, so
+// that the requests of different users do not wait for each other.
+type policyShards [policyShardCount]struct {
+	lock       sync.RWMutex
+	partitions map[string]*policyIndex
+}
+
+func newPolicyShards() *policyShards {
+	s := new(policyShards)
+	for i := range s {
+		s[i].partitions = make(map[string]*policyIndex)
+	}
+
+	return s
+}
+
+func (s *policyShards) shard(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % policyShardCount)
+}
+
+func (s *policyShards) get(key string) (*policyIndex, bool) {
+	shard := &s[s.shard(key)]
+	shard.lock.RLock()
+	defer shard.lock.RUnlock()
+
+	idx, ok := shard.partitions[key]
+
+	return idx, ok
+}
+
+func (s *policyShards) set(key string, policies []*ladon.DefaultPolicy) {
+	idx := newPolicyIndex(policies)
+
+	shard := &s[s.shard(key)]
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	shard.partitions[key] = idx
+}
+
+func (s *policyShards) del(key string) {
+	shard := &s[s.shard(key)]
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	delete(shard.partitions, key)
+}
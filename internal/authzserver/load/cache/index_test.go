@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ory/ladon"
+)
+
+func testPolicy(id string, subjects, resources []string) *ladon.DefaultPolicy {
+	return &ladon.DefaultPolicy{
+		ID:        id,
+		Subjects:  subjects,
+		Resources: resources,
+		Actions:   []string{"<.*>"},
+		Effect:    ladon.AllowAccess,
+	}
+}
+
+func TestPolicyIndex_candidates(t *testing.T) {
+	policies := []*ladon.DefaultPolicy{
+		testPolicy("peter", []string{"users:peter", "users:peter"}, []string{"resources:articles:<.*>"}),
+		testPolicy("pattern", []string{"users:<peter|ken>"}, []string{"resources:articles:<.*>", "resources:articles:1"}),
+		testPolicy("admins", []string{"groups:admins"}, []string{"<.*>"}),
+		testPolicy("printer", []string{"users:peter", "users:maria"}, []string{"resources:printer"}),
+		testPolicy("everyone", []string{"<.*>"}, []string{"resources:<.*>"}),
+	}
+	idx := newPolicyIndex(policies)
+
+	tests := []struct {
+		name     string
+		subjects []string
+		resource string
+		want     []string
+	}{
+		{
+			name:     "literal and pattern subjects in partition order",
+			subjects: []string{"users:peter"},
+			resource: "resources:articles:1",
+			want:     []string{"peter", "pattern", "everyone"},
+		},
+		{
+			name:     "resource prefix of the literal subjects",
+			subjects: []string{"users:maria"},
+			resource: "resources:printer",
+			want:     []string{"printer", "everyone"},
+		},
+		{
+			name:     "groups",
+			subjects: []string{"users:maria", "groups:admins"},
+			resource: "devices:1",
+			want:     []string{"admins"},
+		},
+		{
+			name:     "no candidates",
+			subjects: []string{"users:tom"},
+			resource: "devices:1",
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, policy := range idx.candidates(tt.subjects, tt.resource) {
+				got = append(got, policy.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("candidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyShards(t *testing.T) {
+	s := newPolicyShards()
+	policies := []*ladon.DefaultPolicy{testPolicy("peter", []string{"users:peter"}, []string{"<.*>"})}
+
+	s.set("colin", policies)
+	if idx, ok := s.get("colin"); !ok || !reflect.DeepEqual(idx.policies, policies) {
+		t.Errorf("get() = %v, %v, want %v", idx, ok, policies)
+	}
+
+	s.del("colin")
+	if _, ok := s.get("colin"); ok {
+		t.Error("get() after del() = true, want false")
+	}
+}
+
+func BenchmarkPolicyIndex_candidates(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		policies := make([]*ladon.DefaultPolicy, 0, n)
+		for i := 0; i < n; i++ {
+			policies = append(policies, testPolicy(
+				fmt.Sprintf("policy-%d", i),
+				[]string{fmt.Sprintf("users:user-%d", i%(n/10))},
+				[]string{fmt.Sprintf("resources:articles:%d:<.*>", i)},
+			))
+		}
+		idx := newPolicyIndex(policies)
+
+		b.Run(fmt.Sprintf("%d policies", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				idx.candidates([]string{"users:user-7", "groups:admins"}, "resources:articles:7:comments")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Stage is the progress of the reload of one kind of cached objects.
+type Stage struct {
+	Loaded bool `json:"loaded"`
+	Count  int  `json:"count"`
+}
+
+// Progress describes the latest full reload of the cache, in flight or not.
+type Progress struct {
+	Reloading   bool      `json:"reloading"`
+	StartedAt   time.Time `json:"startedAt"`
+	Secrets     Stage     `json:"secrets"`
+	Policies    Stage     `json:"policies"`
+	Memberships Stage     `json:"memberships"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type progress struct {
+	lock  sync.Mutex
+	state Progress
+}
+
+func (p *progress) start() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state = Progress{Reloading: true, StartedAt: time.Now()}
+}
+
+// loaded marks the stage, one of the stages of p.state, as loaded.
+func (p *progress) loaded(stage *Stage, count int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	*stage = Stage{Loaded: true, Count: count}
+}
+
+func (p *progress) fail(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state.Reloading = false
+	p.state.Error = err.Error()
+}
+
+func (p *progress) done() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state.Reloading = false
+}
+
+// Progress returns the progress of the latest full reload.
+func (c *Cache) Progress() Progress {
+	c.progress.lock.Lock()
+	defer c.progress.lock.Unlock()
+
+	return c.progress.state
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package load
+
+import (
+	"strconv"
+
+	"github.com/marmotedu/component-base/pkg/json"
+
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// EventType defines the type of a resource change event.
+type EventType string
+
+// Define the resource change event types.
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// SequenceKey is the redis key of the global event sequence shared by all iam-apiserver instances.
+const SequenceKey = "iam.cluster.notifications.sequence"
+
+// ChangesKey is the redis sorted set of the latest events scored by their sequence, so that
+// the servers which missed some events catch up with them.
+const ChangesKey = "iam.cluster.notifications.changes"
+
+// changesRetained is the number of the latest events kept in ChangesKey.
+const changesRetained = 10000
+
+// Event describes a change of the secrets or policies of a single user.
+// It is carried as the payload of a Notification.
+type Event struct {
+	// Sequence is a global, monotonically increasing number. A missing number tells
+	// the receiver it lost events and has to resync in full. 0 means unknown.
+	Sequence int64     `json:"sequence"`
+	Type     EventType `json:"type"`
+	Username string    `json:"username"`
+	// Names are the names of the changed resources, if known.
+	Names []string `json:"names,omitempty"`
+}
+
+// Change is an event along with the kind of the changed resources.
+type Change struct {
+	Command NotificationCommand `json:"command"`
+	Event
+}
+
+// IncrementalLoader is implemented by the loaders which can reload the secrets and
+// policies of a single user, instead of reloading everything.
+type IncrementalLoader interface {
+	Loader
+	ReloadSecrets(username string) error
+	ReloadPolicies(username string) error
+}
+
+// DeltaLoader is implemented by the loaders which can catch up with the changes since a
+// sequence, instead of reloading everything.
+type DeltaLoader interface {
+	Loader
+	// ReloadChangesSince reloads the resources changed after the sequence, and returns the
+	// sequence of the latest change reloaded.
+	ReloadChangesSince(sequence int64) (int64, error)
+}
+
+// NewEventNotification returns a notification carrying the given event.
+func NewEventNotification(command NotificationCommand, event Event) Notification {
+	payload, _ := json.Marshal(event)
+
+	return Notification{Command: command, Payload: string(payload)}
+}
+
+// NextSequence allocates the sequence number of a new event.
+// It returns 0 if redis is not available.
+func NextSequence() int64 {
+	redisStore := &storage.RedisCluster{}
+
+	return redisStore.IncrememntWithExpire(SequenceKey, 0)
+}
+
+// CurrentSequence returns the sequence number of the latest published event.
+func CurrentSequence() int64 {
+	redisStore := &storage.RedisCluster{}
+
+	value, err := redisStore.GetRawKey(SequenceKey)
+	if err != nil {
+		return 0
+	}
+
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Warnf("invalid event sequence %q: %s", value, err.Error())
+
+		return 0
+	}
+
+	return seq
+}
+
+// RecordChange adds the event to the latest changes, and drops the oldest ones.
+func RecordChange(command NotificationCommand, event Event) {
+	if event.Sequence == 0 || !storage.Connected() {
+		return
+	}
+
+	data, _ := json.Marshal(Change{Command: command, Event: event})
+
+	redisStore := &storage.RedisCluster{}
+	redisStore.AddToSortedSet(ChangesKey, string(data), float64(event.Sequence))
+	_ = redisStore.RemoveSortedSetRange(ChangesKey, "-inf", strconv.FormatInt(event.Sequence-changesRetained, 10))
+}
+
+// ChangesSince returns the changes after the sequence in order, along with the sequence of
+// the last one. The changes stop at the first missing one, as its event may still be in flight.
+// The last return value is false when the changes right after the sequence are no longer
+// retained, so that only a full reload catches up.
+func ChangesSince(sequence int64) ([]Change, int64, bool) {
+	current := CurrentSequence()
+	if current <= sequence {
+		return nil, sequence, true
+	}
+
+	redisStore := &storage.RedisCluster{}
+	values, scores, err := redisStore.GetSortedSetRange(
+		ChangesKey, strconv.FormatInt(sequence+1, 10), strconv.FormatInt(current, 10),
+	)
+	if err != nil {
+		return nil, sequence, false
+	}
+
+	var changes []Change
+	for i, value := range values {
+		if int64(scores[i]) != sequence+1 {
+			break
+		}
+
+		var change Change
+		if err := json.Unmarshal([]byte(value), &change); err != nil {
+			log.Warnf("invalid change %q: %s", value, err.Error())
+
+			break
+		}
+
+		changes = append(changes, change)
+		sequence++
+	}
+
+	return changes, sequence, len(changes) > 0
+}
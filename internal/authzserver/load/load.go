@@ -10,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/marmotedu/component-base/pkg/json"
+
 	"github.com/marmotedu/iam/pkg/log"
 	"github.com/marmotedu/iam/pkg/storage"
 )
@@ -24,6 +26,21 @@ type Load struct {
 	ctx    context.Context
 	lock   *sync.RWMutex
 	loader Loader
+	// lastSeq is the sequence of the latest event reflected in the loaded storage.
+	lastSeq int64
+	// lastReload and lastSync are the time of the latest full reload and of the
+	// latest full reload or applied event.
+	lastReload time.Time
+	lastSync   time.Time
+}
+
+// Status describes the synchronization state of the loaded storage.
+type Status struct {
+	// Ready is true once the storage is loaded in full for the first time.
+	Ready          bool      `json:"ready"`
+	LastReloadTime time.Time `json:"lastReloadTime"`
+	LastSyncTime   time.Time `json:"lastSyncTime"`
+	LastSequence   int64     `json:"lastSequence"`
 }
 
 // NewLoader return a loader with a loader implement.
@@ -37,21 +54,60 @@ func NewLoader(ctx context.Context, loader Loader) *Load {
 
 // Start start a loop service.
 func (l *Load) Start() {
-	go startPubSubLoop()
+	go l.startPubSubLoop()
 	go l.reloadQueueLoop()
 	// 1s is the minimum amount of time between hot reloads. The
 	// interval counts from the start of one reload to the next.
 	go l.reloadLoop()
-	l.DoReload()
+	// serve while the storage warms up, the readiness is reported by Status
+	go l.warmUp()
+}
+
+const (
+	// warmUpMinBackoff and warmUpMaxBackoff bound the wait between the attempts of the
+	// first reload.
+	warmUpMinBackoff = time.Second
+	warmUpMaxBackoff = 30 * time.Second
+)
+
+// warmUp reloads the storage until it succeeds once, the later reloads are triggered by the
+// events.
+func (l *Load) warmUp() {
+	backoff := warmUpMinBackoff
+	for {
+		start := time.Now()
+		err := l.Reload()
+		if err == nil {
+			log.Infof("warm-up: storage loaded in %v", time.Since(start))
+
+			return
+		}
+
+		log.Errorf("warm-up: failed to load target storage, retry in %v: %s", backoff, err.Error())
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > warmUpMaxBackoff {
+			backoff = warmUpMaxBackoff
+		}
+	}
 }
 
-func startPubSubLoop() {
+func (l *Load) startPubSubLoop() {
 	cacheStore := storage.RedisCluster{}
 	cacheStore.Connect()
 	// On message, synchronize
-	for {
+	for retry := false; ; retry = true {
+		if retry {
+			l.resync()
+		}
+
 		err := cacheStore.StartPubSubHandler(RedisPubSubChannel, func(v interface{}) {
-			handleRedisEvent(v, nil, nil)
+			l.handleRedisEvent(v, nil, nil)
 		})
 		if err != nil {
 			if !errors.Is(err, storage.ErrRedisIsDown) {
@@ -138,12 +194,141 @@ func (l *Load) reloadQueueLoop(cb ...func()) {
 
 // DoReload reload secrets and policies.
 func (l *Load) DoReload() {
+	if err := l.Reload(); err != nil {
+		log.Errorf("faild to refresh target storage: %s", err.Error())
+
+		return
+	}
+
+	log.Debug("refresh target storage succ")
+}
+
+// Reload reload secrets and policies immediately.
+func (l *Load) Reload() error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	// events published from now on may not be in the reloaded storage yet, applying
+	// them again later is harmless.
+	seq := CurrentSequence()
 	if err := l.loader.Reload(); err != nil {
-		log.Errorf("faild to refresh target storage: %s", err.Error())
+		return err
 	}
 
-	log.Debug("refresh target storage succ")
+	l.lastSeq = seq
+	l.lastReload = time.Now()
+	l.lastSync = l.lastReload
+
+	return nil
+}
+
+// Status returns the synchronization state of the loaded storage.
+func (l *Load) Status() Status {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return Status{
+		Ready:          !l.lastReload.IsZero(),
+		LastReloadTime: l.lastReload,
+		LastSyncTime:   l.lastSync,
+		LastSequence:   l.lastSeq,
+	}
+}
+
+// applyEvent applies the event carried by the notification to the loaded storage.
+// It returns false if the event can not be applied and a full reload is required.
+func (l *Load) applyEvent(notif Notification) bool {
+	loader, ok := l.loader.(IncrementalLoader)
+	if !ok || notif.Payload == "" {
+		return false
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(notif.Payload), &event); err != nil {
+		log.Warnf("Unmarshalling event failed, malformed: %s", err.Error())
+
+		return false
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	switch {
+	case event.Sequence == 0 || event.Username == "":
+		return false
+	case event.Sequence <= l.lastSeq:
+		log.Debugf("event %d is already loaded, skip it", event.Sequence)
+
+		return true
+	case event.Sequence != l.lastSeq+1:
+		if l.catchUp(event.Sequence) {
+			return true
+		}
+
+		log.Warnf("events between %d and %d are lost, resync in full", l.lastSeq, event.Sequence)
+
+		return false
+	}
+
+	var err error
+	if notif.Command == NoticePolicyChanged {
+		err = loader.ReloadPolicies(event.Username)
+	} else {
+		err = loader.ReloadSecrets(event.Username)
+	}
+
+	if err != nil {
+		log.Errorf("failed to apply event %d: %s", event.Sequence, err.Error())
+
+		return false
+	}
+
+	l.lastSeq = event.Sequence
+	l.lastSync = time.Now()
+	log.Infow("event applied", "sequence", event.Sequence, "type", event.Type, "username", event.Username)
+
+	return true
+}
+
+// catchUp reloads the changes after the latest loaded event, up to the target sequence at
+// least. It returns false if the changes can not be reloaded and a full reload is required.
+// The caller must hold the lock.
+func (l *Load) catchUp(target int64) bool {
+	loader, ok := l.loader.(DeltaLoader)
+	if !ok {
+		return false
+	}
+
+	seq, err := loader.ReloadChangesSince(l.lastSeq)
+	if err != nil {
+		log.Warnf("failed to reload the changes since %d: %s", l.lastSeq, err.Error())
+
+		return false
+	}
+	if seq < target {
+		log.Warnf("changes between %d and %d are not available yet", seq, target)
+
+		return false
+	}
+
+	log.Infow("changes reloaded", "from", l.lastSeq, "to", seq)
+	l.lastSeq = seq
+	l.lastSync = time.Now()
+
+	return true
+}
+
+// resync catches up with the events published while the notifications were not received,
+// such as during a reconnection to redis.
+func (l *Load) resync() {
+	target := CurrentSequence()
+
+	l.lock.Lock()
+	synced := l.lastReload.IsZero() || target <= l.lastSeq || l.catchUp(target)
+	l.lock.Unlock()
+
+	if !synced {
+		log.Warnf("events before %d may be lost, resync in full", target)
+		reloadQueue <- nil
+	}
 }
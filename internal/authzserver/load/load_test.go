@@ -0,0 +1,133 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package load
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeLoader struct {
+	secrets  []string
+	policies []string
+}
+
+func (f *fakeLoader) Reload() error { return nil }
+
+func (f *fakeLoader) ReloadSecrets(username string) error {
+	f.secrets = append(f.secrets, username)
+
+	return nil
+}
+
+func (f *fakeLoader) ReloadPolicies(username string) error {
+	f.policies = append(f.policies, username)
+
+	return nil
+}
+
+func TestLoad_applyEvent(t *testing.T) {
+	loader := &fakeLoader{}
+	l := NewLoader(context.TODO(), loader)
+	l.lastSeq = 10
+
+	tests := []struct {
+		name    string
+		command NotificationCommand
+		event   Event
+		want    bool
+		wantSeq int64
+	}{
+		{
+			name:    "next event",
+			command: NoticePolicyChanged,
+			event:   Event{Sequence: 11, Type: EventCreated, Username: "colin"},
+			want:    true,
+			wantSeq: 11,
+		},
+		{
+			name:    "already loaded",
+			command: NoticeSecretChanged,
+			event:   Event{Sequence: 9, Type: EventDeleted, Username: "colin"},
+			want:    true,
+			wantSeq: 11,
+		},
+		{
+			name:    "gap",
+			command: NoticeSecretChanged,
+			event:   Event{Sequence: 13, Type: EventUpdated, Username: "colin"},
+			want:    false,
+			wantSeq: 11,
+		},
+		{
+			name:    "unknown sequence",
+			command: NoticePolicyChanged,
+			event:   Event{Type: EventUpdated, Username: "colin"},
+			want:    false,
+			wantSeq: 11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.applyEvent(NewEventNotification(tt.command, tt.event)); got != tt.want {
+				t.Errorf("Load.applyEvent() = %v, want %v", got, tt.want)
+			}
+
+			if l.lastSeq != tt.wantSeq {
+				t.Errorf("Load.lastSeq = %v, want %v", l.lastSeq, tt.wantSeq)
+			}
+		})
+	}
+
+	if len(loader.policies) != 1 || len(loader.secrets) != 0 {
+		t.Errorf("applied policies = %v, secrets = %v", loader.policies, loader.secrets)
+	}
+
+	// a notification without event, e.g. from an older apiserver, always triggers a full reload
+	if l.applyEvent(Notification{Command: NoticePolicyChanged}) {
+		t.Errorf("Load.applyEvent() without payload should return false")
+	}
+}
+
+type fakeDeltaLoader struct {
+	fakeLoader
+	latest int64
+}
+
+func (f *fakeDeltaLoader) ReloadChangesSince(sequence int64) (int64, error) {
+	return f.latest, nil
+}
+
+func TestLoad_applyEvent_catchUp(t *testing.T) {
+	loader := &fakeDeltaLoader{latest: 13}
+	l := NewLoader(context.TODO(), loader)
+	l.lastSeq = 10
+
+	if !l.applyEvent(NewEventNotification(NoticeSecretChanged, Event{Sequence: 13, Username: "colin"})) {
+		t.Errorf("Load.applyEvent() after a gap should catch up with the changes")
+	}
+	if l.lastSeq != 13 {
+		t.Errorf("Load.lastSeq = %v, want %v", l.lastSeq, 13)
+	}
+
+	// the changes up to the event are not listed yet
+	if l.applyEvent(NewEventNotification(NoticeSecretChanged, Event{Sequence: 15, Username: "colin"})) {
+		t.Errorf("Load.applyEvent() should return false when the changes are behind the event")
+	}
+}
+
+func TestLoad_Status(t *testing.T) {
+	l := NewLoader(context.TODO(), &fakeLoader{})
+
+	if l.Status().Ready {
+		t.Errorf("Load.Status().Ready should be false before the first reload")
+	}
+
+	l.lastReload = time.Now()
+	if !l.Status().Ready {
+		t.Errorf("Load.Status().Ready should be true after the first reload")
+	}
+}
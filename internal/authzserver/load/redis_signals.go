@@ -42,7 +42,7 @@ func (n *Notification) Sign() {
 	n.Signature = hex.EncodeToString(hash[:])
 }
 
-func handleRedisEvent(v interface{}, handled func(NotificationCommand), reloaded func()) {
+func (l *Load) handleRedisEvent(v interface{}, handled func(NotificationCommand), reloaded func()) {
 	message, ok := v.(*redis.Message)
 	if !ok {
 		return
@@ -58,8 +58,12 @@ func handleRedisEvent(v interface{}, handled func(NotificationCommand), reloaded
 
 	switch notif.Command {
 	case NoticePolicyChanged, NoticeSecretChanged:
-		log.Info("Reloading secrets and policies")
-		reloadQueue <- reloaded
+		if !l.applyEvent(notif) {
+			log.Info("Reloading secrets and policies")
+			reloadQueue <- reloaded
+		} else if reloaded != nil {
+			reloaded()
+		}
 	default:
 		log.Warnf("Unknown notification command: %q", notif.Command)
 
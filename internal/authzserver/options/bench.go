@@ -0,0 +1,76 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+)
+
+// BenchOptions runs a benchmark of the authorizer against synthetic policies.
+type BenchOptions struct {
+	Policies    int
+	Users       int
+	Concurrency int
+	Duration    time.Duration
+	Seed        int64
+	// DecisionCache is applied when --decision-cache.enable is set, the policies are evaluated
+	// on every request otherwise.
+	DecisionCache *authorization.DecisionCacheOptions
+}
+
+// NewBenchOptions creates a new BenchOptions object with default parameters.
+func NewBenchOptions() *BenchOptions {
+	o := &BenchOptions{
+		Policies:      100000,
+		Users:         100,
+		Concurrency:   16,
+		Duration:      10 * time.Second,
+		Seed:          1,
+		DecisionCache: authorization.NewDecisionCacheOptions(),
+	}
+	o.DecisionCache.Enable = false
+
+	return o
+}
+
+// Flags returns the flags of the benchmark.
+func (o *BenchOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.DecisionCache.AddFlags(fss.FlagSet("decision cache"))
+
+	fs := fss.FlagSet("bench")
+	fs.IntVar(&o.Policies, "policies", o.Policies, "Number of the synthetic policies, spread over the users.")
+	fs.IntVar(&o.Users, "users", o.Users, "Number of the users owning the policies.")
+	fs.IntVar(&o.Concurrency, "concurrency", o.Concurrency, "Number of the goroutines sending the requests.")
+	fs.DurationVar(&o.Duration, "duration", o.Duration, "Duration of the measurement.")
+	fs.Int64Var(&o.Seed, "seed", o.Seed, ""+
+		"Seed of the generated policies and requests, the same seed reproduces the same workload.")
+
+	return fss
+}
+
+// Validate checks BenchOptions and return a slice of found errs.
+func (o *BenchOptions) Validate() []error {
+	var errs []error
+
+	if o.Users < 1 {
+		errs = append(errs, fmt.Errorf("--users must be greater than 0"))
+	}
+	if o.Policies < o.Users {
+		errs = append(errs, fmt.Errorf("--policies must be at least --users %d", o.Users))
+	}
+	if o.Concurrency < 1 {
+		errs = append(errs, fmt.Errorf("--concurrency must be greater than 0"))
+	}
+	if o.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("--duration must be greater than 0"))
+	}
+
+	return append(errs, o.DecisionCache.Validate()...)
+}
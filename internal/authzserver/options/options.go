@@ -10,28 +10,48 @@ import (
 	"github.com/marmotedu/component-base/pkg/json"
 
 	"github.com/marmotedu/iam/internal/authzserver/analytics"
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/enricher"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/external"
+	"github.com/marmotedu/iam/internal/authzserver/store/file"
+	"github.com/marmotedu/iam/internal/pkg/faultinjection"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	"github.com/marmotedu/iam/internal/pkg/ratelimit"
 	"github.com/marmotedu/iam/internal/pkg/server"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
 // Options runs a authzserver.
 type Options struct {
-	RPCServer               string                                 `json:"rpcserver"      mapstructure:"rpcserver"`
-	ClientCA                string                                 `json:"client-ca-file" mapstructure:"client-ca-file"`
-	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"         mapstructure:"server"`
-	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"       mapstructure:"insecure"`
-	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"         mapstructure:"secure"`
-	RedisOptions            *genericoptions.RedisOptions           `json:"redis"          mapstructure:"redis"`
-	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"        mapstructure:"feature"`
-	Log                     *log.Options                           `json:"log"            mapstructure:"log"`
-	AnalyticsOptions        *analytics.AnalyticsOptions            `json:"analytics"      mapstructure:"analytics"`
+	RPCServer               []string                               `json:"rpcserver"             mapstructure:"rpcserver"`
+	RPCPageSize             int                                    `json:"rpcserver-page-size"   mapstructure:"rpcserver-page-size"`
+	RPCConnections          int                                    `json:"rpcserver-connections" mapstructure:"rpcserver-connections"`
+	ClientCA                string                                 `json:"client-ca-file"        mapstructure:"client-ca-file"`
+	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"                mapstructure:"server"`
+	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"              mapstructure:"insecure"`
+	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"                mapstructure:"secure"`
+	RedisOptions            *genericoptions.RedisOptions           `json:"redis"                 mapstructure:"redis"`
+	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"               mapstructure:"feature"`
+	Log                     *log.Options                           `json:"log"                   mapstructure:"log"`
+	RuntimeOptions          *genericoptions.RuntimeOptions         `json:"runtime"               mapstructure:"runtime"`
+	AnalyticsOptions        *analytics.AnalyticsOptions            `json:"analytics"             mapstructure:"analytics"`
+	EnricherOptions         *enricher.EnricherOptions              `json:"enricher"              mapstructure:"enricher"`
+	DecisionCacheOptions    *authorization.DecisionCacheOptions    `json:"decision-cache"        mapstructure:"decision-cache"`
+	TenantOptions           *authorization.TenantOptions           `json:"tenant"                mapstructure:"tenant"`
+	RecordOptions           *authorization.RecordOptions           `json:"record"                mapstructure:"record"`
+	ExternalOptions         *external.ExternalOptions              `json:"external"              mapstructure:"external"`
+	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"                  mapstructure:"grpc"`
+	RateLimitOptions        *ratelimit.RateLimitOptions            `json:"rate-limit"            mapstructure:"rate-limit"`
+	StandaloneOptions       *file.StandaloneOptions                `json:"standalone"            mapstructure:"standalone"`
+	FaultInjectionOptions   *faultinjection.FaultInjectionOptions  `json:"fault-injection"       mapstructure:"fault-injection"`
 }
 
 // NewOptions creates a new Options object with default parameters.
 func NewOptions() *Options {
 	o := Options{
-		RPCServer:               "127.0.0.1:8081",
+		RPCServer:               []string{"127.0.0.1:8081"},
+		RPCPageSize:             500,
+		RPCConnections:          2,
 		ClientCA:                "",
 		GenericServerRunOptions: genericoptions.NewServerRunOptions(),
 		InsecureServing:         genericoptions.NewInsecureServingOptions(),
@@ -39,9 +59,22 @@ func NewOptions() *Options {
 		RedisOptions:            genericoptions.NewRedisOptions(),
 		FeatureOptions:          genericoptions.NewFeatureOptions(),
 		Log:                     log.NewOptions(),
+		RuntimeOptions:          genericoptions.NewRuntimeOptions(),
 		AnalyticsOptions:        analytics.NewAnalyticsOptions(),
+		EnricherOptions:         enricher.NewEnricherOptions(),
+		DecisionCacheOptions:    authorization.NewDecisionCacheOptions(),
+		TenantOptions:           authorization.NewTenantOptions(),
+		RecordOptions:           authorization.NewRecordOptions(),
+		ExternalOptions:         external.NewExternalOptions(),
+		GRPCOptions:             genericoptions.NewGRPCOptions(),
+		RateLimitOptions:        ratelimit.NewRateLimitOptions(),
+		StandaloneOptions:       file.NewStandaloneOptions(),
+		FaultInjectionOptions:   faultinjection.NewFaultInjectionOptions(),
 	}
 
+	// the envoy ext_authz grpc server is disabled by default
+	o.GRPCOptions.BindPort = 0
+
 	return &o
 }
 
@@ -54,17 +87,35 @@ func (o *Options) ApplyTo(c *server.Config) error {
 func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.GenericServerRunOptions.AddFlags(fss.FlagSet("generic"))
 	o.AnalyticsOptions.AddFlags(fss.FlagSet("analytics"))
+	o.EnricherOptions.AddFlags(fss.FlagSet("enricher"))
+	o.DecisionCacheOptions.AddFlags(fss.FlagSet("decision cache"))
+	o.TenantOptions.AddFlags(fss.FlagSet("tenant"))
+	o.RecordOptions.AddFlags(fss.FlagSet("record"))
+	o.ExternalOptions.AddFlags(fss.FlagSet("external"))
+	o.RateLimitOptions.AddFlags(fss.FlagSet("rate limit"))
+	o.StandaloneOptions.AddFlags(fss.FlagSet("standalone"))
+	o.FaultInjectionOptions.AddFlags(fss.FlagSet("fault injection"))
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
 	o.FeatureOptions.AddFlags(fss.FlagSet("features"))
 	o.InsecureServing.AddFlags(fss.FlagSet("insecure serving"))
 	o.SecureServing.AddFlags(fss.FlagSet("secure serving"))
+	o.GRPCOptions.AddFlags(fss.FlagSet("grpc"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
+	o.RuntimeOptions.AddFlags(fss.FlagSet("runtime"))
 
 	// Note: the weird ""+ in below lines seems to be the only way to get gofmt to
 	// arrange these text blocks sensibly. Grrr.
 	fs := fss.FlagSet("misc")
-	fs.StringVar(&o.RPCServer, "rpcserver", o.RPCServer, "The address of iam rpc server. "+
-		"The rpc server can provide all the secrets and policies to use.")
+	fs.StringSliceVar(&o.RPCServer, "rpcserver", o.RPCServer, "The addresses of iam rpc servers. "+
+		"The rpc server can provide all the secrets and policies to use. Multiple addresses are "+
+		"health checked and failed over, a single address may use the dns:/// scheme to "+
+		"re-resolve the servers when one is lost.")
+	fs.IntVar(&o.RPCPageSize, "rpcserver-page-size", o.RPCPageSize, ""+
+		"The number of policies or secrets pulled from the rpc server per request when the cache is loaded. "+
+		"A negative value pulls them all in a single response.")
+	fs.IntVar(&o.RPCConnections, "rpcserver-connections", o.RPCConnections, ""+
+		"The number of long-lived connections to the rpc servers the requests are multiplexed over. "+
+		"A lost connection is re-established with a backoff.")
 	fs.StringVar(&o.ClientCA, "client-ca-file", o.ClientCA, ""+
 		"If set, any request presenting a client certificate signed by one of "+
 		"the authorities in the client-ca-file is authenticated with an identity "+
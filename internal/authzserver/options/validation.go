@@ -4,17 +4,64 @@
 
 package options
 
+import (
+	"fmt"
+	"net"
+)
+
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() []error {
 	var errs []error
 
+	errs = append(errs, o.validateRPCServer()...)
 	errs = append(errs, o.GenericServerRunOptions.Validate()...)
 	errs = append(errs, o.InsecureServing.Validate()...)
 	errs = append(errs, o.SecureServing.Validate()...)
+	errs = append(errs, o.GRPCOptions.Validate()...)
 	errs = append(errs, o.RedisOptions.Validate()...)
 	errs = append(errs, o.FeatureOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
+	errs = append(errs, o.RuntimeOptions.Validate()...)
 	errs = append(errs, o.AnalyticsOptions.Validate()...)
+	errs = append(errs, o.EnricherOptions.Validate()...)
+	errs = append(errs, o.DecisionCacheOptions.Validate()...)
+	errs = append(errs, o.TenantOptions.Validate()...)
+	errs = append(errs, o.RecordOptions.Validate()...)
+	errs = append(errs, o.ExternalOptions.Validate()...)
+	errs = append(errs, o.RateLimitOptions.Validate()...)
+	errs = append(errs, o.StandaloneOptions.Validate()...)
+	errs = append(errs, o.FaultInjectionOptions.Validate()...)
+
+	return errs
+}
+
+func (o *Options) validateRPCServer() []error {
+	// the rpc servers are not used in standalone mode
+	if o.StandaloneOptions.Enable {
+		return nil
+	}
+
+	if len(o.RPCServer) == 0 {
+		return []error{fmt.Errorf("--rpcserver can not be empty")}
+	}
+
+	var errs []error
+	if o.RPCPageSize == 0 {
+		errs = append(errs, fmt.Errorf("--rpcserver-page-size can not be 0"))
+	}
+	if o.RPCConnections < 1 {
+		errs = append(errs, fmt.Errorf("--rpcserver-connections must be greater than 0"))
+	}
+
+	if len(o.RPCServer) == 1 {
+		return errs
+	}
+
+	for _, address := range o.RPCServer {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			errs = append(errs, fmt.Errorf("--rpcserver %q must be host:port when multiple servers are given", address))
+		}
+	}
 
 	return errs
 }
@@ -9,22 +9,48 @@ import (
 	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
 
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
 	"github.com/marmotedu/iam/internal/authzserver/controller/v1/authorize"
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/debug"
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/forwardauth"
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/oauth"
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/subjectaccessreview"
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/tokenreview"
+	"github.com/marmotedu/iam/internal/authzserver/load"
 	"github.com/marmotedu/iam/internal/authzserver/load/cache"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/faultinjection"
+	"github.com/marmotedu/iam/internal/pkg/ratelimit"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
-func initRouter(g *gin.Engine) {
-	installMiddleware(g)
-	installController(g)
+func initRouter(
+	g *gin.Engine,
+	loader *load.Load,
+	opts []authorization.Option,
+	rateLimitOptions *ratelimit.RateLimitOptions,
+	faultOptions *faultinjection.FaultInjectionOptions,
+) {
+	installMiddleware(g, faultOptions)
+	installController(g, loader, opts, rateLimitOptions)
 }
 
-func installMiddleware(g *gin.Engine) {
+func installMiddleware(g *gin.Engine, faultOptions *faultinjection.FaultInjectionOptions) {
+	if faultOptions.Enable {
+		g.Use(faultinjection.Inject(faultOptions))
+	}
 }
 
-func installController(g *gin.Engine) *gin.Engine {
+func installController(
+	g *gin.Engine,
+	loader *load.Load,
+	opts []authorization.Option,
+	rateLimitOptions *ratelimit.RateLimitOptions,
+) *gin.Engine {
 	auth := newCacheAuth()
+	// the clients are limited by the secrets which signed their requests
+	limit := ratelimit.Limit(rateLimitOptions)
+
 	g.NoRoute(auth.AuthFunc(), func(c *gin.Context) {
 		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "page not found."), nil)
 	})
@@ -34,12 +60,38 @@ func installController(g *gin.Engine) *gin.Engine {
 		log.Panicf("get nil cache instance")
 	}
 
-	apiv1 := g.Group("/v1", auth.AuthFunc())
+	apiv1 := g.Group("/v1", auth.AuthFunc(), limit)
 	{
-		authzController := authorize.NewAuthzController(cacheIns)
+		authzController := authorize.NewAuthzController(cacheIns, opts...)
 
 		// Router for authorization
 		apiv1.POST("/authz", authzController.Authorize)
+
+		// Router for the nginx auth_request and traefik ForwardAuth subrequests
+		forwardAuthController := forwardauth.NewForwardAuthController(cacheIns, opts...)
+		apiv1.GET("/authz/forward", forwardAuthController.Forward)
+
+		// Router for the kubernetes webhook authorizer
+		sarController := subjectaccessreview.NewSubjectAccessReviewController(cacheIns, opts...)
+		apiv1.POST("/subjectaccessreviews", sarController.Review)
+	}
+
+	// Router for the kubernetes webhook token authenticator, the token to review is in the request body
+	tokenReviewController := tokenreview.NewTokenReviewController(getSecretFunc(), cacheIns)
+	g.POST("/v1/tokenreviews", limit, tokenReviewController.Review)
+
+	// Router for the OAuth 2.0 client_credentials grant, the clients authenticate with their secrets
+	oauthController := oauth.NewOAuthController(getSecretFunc())
+	g.POST("/oauth/token", limit, oauthController.Token)
+
+	debugController := debug.NewDebugController(loader, cacheIns)
+	// the readiness probes are not authenticated, like /healthz
+	g.GET("/readyz", debugController.Readyz)
+
+	debugv1 := g.Group("/debug/cache", auth.AuthFunc())
+	{
+		debugv1.POST("/reload", debugController.ReloadCache)
+		debugv1.GET("/status", debugController.CacheStatus)
 	}
 
 	return g
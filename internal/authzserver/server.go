@@ -6,16 +6,27 @@ package authzserver
 
 import (
 	"context"
+	"net"
+	"strconv"
 
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/authzserver/analytics"
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/enricher"
+	"github.com/marmotedu/iam/internal/authzserver/authorization/external"
 	"github.com/marmotedu/iam/internal/authzserver/config"
 	"github.com/marmotedu/iam/internal/authzserver/load"
 	"github.com/marmotedu/iam/internal/authzserver/load/cache"
+	"github.com/marmotedu/iam/internal/authzserver/store"
 	"github.com/marmotedu/iam/internal/authzserver/store/apiserver"
+	"github.com/marmotedu/iam/internal/authzserver/store/file"
+	"github.com/marmotedu/iam/internal/pkg/faultinjection"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
+	"github.com/marmotedu/iam/internal/pkg/ratelimit"
+	"github.com/marmotedu/iam/internal/pkg/secretusage"
 	genericapiserver "github.com/marmotedu/iam/internal/pkg/server"
+	"github.com/marmotedu/iam/internal/pkg/upgrade"
 	"github.com/marmotedu/iam/pkg/log"
 	"github.com/marmotedu/iam/pkg/shutdown"
 	"github.com/marmotedu/iam/pkg/shutdown/shutdownmanagers/posixsignal"
@@ -27,12 +38,27 @@ const RedisKeyPrefix = "analytics-"
 
 type authzServer struct {
 	gs               *shutdown.GracefulShutdown
-	rpcServer        string
+	rpcServer        []string
+	rpcPageSize      int
+	rpcConnections   int
 	clientCA         string
 	redisOptions     *genericoptions.RedisOptions
 	genericAPIServer *genericapiserver.GenericAPIServer
 	analyticsOptions *analytics.AnalyticsOptions
+	enricherOptions  *enricher.EnricherOptions
+	decisionOptions  *authorization.DecisionCacheOptions
+	tenantOptions    *authorization.TenantOptions
+	recordOptions    *authorization.RecordOptions
+	externalOptions  *external.ExternalOptions
+	grpcOptions      *genericoptions.GRPCOptions
+	rateLimitOptions *ratelimit.RateLimitOptions
+	faultOptions     *faultinjection.FaultInjectionOptions
+	standalone       *file.StandaloneOptions
+	gRPCAuthzServer  *grpcAuthzServer
 	redisCancelFunc  context.CancelFunc
+	loader           *load.Load
+	authzOptions     []authorization.Option
+	recorder         *authorization.Recorder
 }
 
 type preparedAuthzServer struct {
@@ -43,6 +69,7 @@ type preparedAuthzServer struct {
 func createAuthzServer(cfg *config.Config) (*authzServer, error) {
 	gs := shutdown.New()
 	gs.AddShutdownManager(posixsignal.NewPosixSignalManager())
+	gs.AddShutdownManager(upgrade.NewManager())
 
 	genericConfig, err := buildGenericConfig(cfg)
 	if err != nil {
@@ -58,7 +85,18 @@ func createAuthzServer(cfg *config.Config) (*authzServer, error) {
 		gs:               gs,
 		redisOptions:     cfg.RedisOptions,
 		analyticsOptions: cfg.AnalyticsOptions,
+		enricherOptions:  cfg.EnricherOptions,
+		decisionOptions:  cfg.DecisionCacheOptions,
+		tenantOptions:    cfg.TenantOptions,
+		recordOptions:    cfg.RecordOptions,
+		rateLimitOptions: cfg.RateLimitOptions,
+		faultOptions:     cfg.FaultInjectionOptions,
+		externalOptions:  cfg.ExternalOptions,
+		standalone:       cfg.StandaloneOptions,
+		grpcOptions:      cfg.GRPCOptions,
 		rpcServer:        cfg.RPCServer,
+		rpcPageSize:      cfg.RPCPageSize,
+		rpcConnections:   cfg.RPCConnections,
 		clientCA:         cfg.ClientCA,
 		genericAPIServer: genericServer,
 	}
@@ -69,7 +107,17 @@ func createAuthzServer(cfg *config.Config) (*authzServer, error) {
 func (s *authzServer) PrepareRun() preparedAuthzServer {
 	_ = s.initialize()
 
-	initRouter(s.genericAPIServer.Engine)
+	initRouter(s.genericAPIServer.Engine, s.loader, s.authzOptions, s.rateLimitOptions, s.faultOptions)
+
+	// the envoy ext_authz grpc server is disabled with a zero port
+	if s.grpcOptions.BindPort != 0 {
+		address := net.JoinHostPort(s.grpcOptions.BindAddress, strconv.Itoa(s.grpcOptions.BindPort))
+		gRPCAuthzServer, err := newGRPCAuthzServer(address, s.grpcOptions.MaxMsgSize, s.authzOptions)
+		if err != nil {
+			log.Fatalf("create grpc server failed: %s", err.Error())
+		}
+		s.gRPCAuthzServer = gRPCAuthzServer
+	}
 
 	return preparedAuthzServer{s}
 }
@@ -79,7 +127,13 @@ func (s preparedAuthzServer) Run() error {
 	// in order to ensure that the reported data is not lost,
 	// please ensure the following graceful shutdown sequence
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		if s.gRPCAuthzServer != nil {
+			s.gRPCAuthzServer.Close()
+		}
 		s.genericAPIServer.Close()
+		if err := s.recorder.Close(); err != nil {
+			log.Errorf("close authorization recorder failed: %s", err.Error())
+		}
 		if s.analyticsOptions.Enable {
 			analytics.GetAnalytics().Stop()
 		}
@@ -88,6 +142,10 @@ func (s preparedAuthzServer) Run() error {
 		return nil
 	}))
 
+	if s.gRPCAuthzServer != nil {
+		s.gRPCAuthzServer.Run()
+	}
+
 	// start shutdown managers
 	if err := s.gs.Start(); err != nil {
 		log.Fatalf("start shutdown manager failed: %s", err.Error())
@@ -132,6 +190,8 @@ func (s *authzServer) buildStorageConfig() *storage.Config {
 		EnableCluster:         s.redisOptions.EnableCluster,
 		UseSSL:                s.redisOptions.UseSSL,
 		SSLInsecureSkipVerify: s.redisOptions.SSLInsecureSkipVerify,
+		ClientCacheSize:       s.redisOptions.ClientCacheSize,
+		ClientCacheTTL:        s.redisOptions.ClientCacheTTL,
 	}
 }
 
@@ -142,13 +202,61 @@ func (s *authzServer) initialize() error {
 	// keep redis connected
 	go storage.ConnectToRedis(ctx, s.buildStorageConfig())
 
-	// cron to reload all secrets and policies from iam-apiserver
-	cacheIns, err := cache.GetCacheInsOr(apiserver.GetAPIServerFactoryOrDie(s.rpcServer, s.clientCA))
+	// flush the usages of the secrets to redis, from where iam-watcher moves them to mysql
+	secretusage.Start(ctx)
+
+	// cron to reload all secrets and policies from iam-apiserver, or from the local files in
+	// standalone mode
+	var factory store.Factory
+	if s.standalone.Enable {
+		factory = file.NewFactory(s.standalone)
+	} else {
+		factory = apiserver.GetAPIServerFactoryOrDie(s.rpcServer, s.clientCA, s.rpcPageSize, s.rpcConnections)
+	}
+
+	cacheIns, err := cache.GetCacheInsOr(factory)
 	if err != nil {
 		return errors.Wrap(err, "get cache instance failed")
 	}
 
-	load.NewLoader(ctx, cacheIns).Start()
+	s.loader = load.NewLoader(ctx, cacheIns)
+	s.loader.Start()
+
+	if s.standalone.Enable {
+		if err := file.Watch(ctx, s.standalone, s.loader.DoReload); err != nil {
+			return errors.Wrap(err, "watch standalone files failed")
+		}
+	}
+
+	enrichers, err := enricher.NewChain(s.enricherOptions)
+	if err != nil {
+		return errors.Wrap(err, "create context enricher chain failed")
+	}
+
+	decisions, err := authorization.NewDecisionCache(s.decisionOptions, cacheIns.PolicyEpoch)
+	if err != nil {
+		return errors.Wrap(err, "create decision cache failed")
+	}
+
+	externals, err := external.NewChain(s.externalOptions)
+	if err != nil {
+		return errors.Wrap(err, "create external authorizer chain failed")
+	}
+
+	s.recorder, err = authorization.NewRecorder(s.recordOptions)
+	if err != nil {
+		return errors.Wrap(err, "create authorization recorder failed")
+	}
+
+	s.authzOptions = []authorization.Option{
+		authorization.WithEnrichers(enrichers...),
+		authorization.WithDecisionCache(decisions),
+		authorization.WithTenantRequired(s.tenantOptions.Required),
+		authorization.WithMemberships(cacheIns),
+		authorization.WithTags(cacheIns),
+		authorization.WithExternalAuthorizers(externals...),
+		authorization.WithRecorder(s.recorder),
+	}
 
 	// start analytics service
 	if s.analyticsOptions.Enable {
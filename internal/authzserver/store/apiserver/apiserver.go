@@ -5,18 +5,27 @@
 package apiserver
 
 import (
+	"context"
+	"net"
 	"sync"
 
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/health" // enable client side health checking
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 
 	"github.com/marmotedu/iam/internal/authzserver/store"
+	"github.com/marmotedu/iam/internal/pkg/cachesync"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
 type datastore struct {
-	cli pb.CacheClient
+	conn grpc.ClientConnInterface
+	cli  pb.CacheClient
+	// pageSize is the limit of a list request, a negative page size lists all at once.
+	pageSize int64
 }
 
 func (ds *datastore) Secrets() store.SecretStore {
@@ -27,17 +36,33 @@ func (ds *datastore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
 
+func (ds *datastore) Memberships() store.MembershipStore {
+	return newMemberships(ds)
+}
+
+// ListChangesSince returns the changes of the secrets and policies after the revision.
+func (ds *datastore) ListChangesSince(revision int64) (*cachesync.ListChangesSinceResponse, error) {
+	log.Infof("Loading changes since %d", revision)
+
+	return cachesync.ListChangesSince(context.Background(), ds.conn, &cachesync.ListChangesSinceRequest{
+		Revision: revision,
+	})
+}
+
 var (
 	apiServerFactory store.Factory
 	once             sync.Once
 )
 
 // GetAPIServerFactoryOrDie return cache instance and panics on any error.
-func GetAPIServerFactoryOrDie(address string, clientCA string) store.Factory {
+// Requests are balanced over the healthy servers among addresses, so losing one
+// iam-apiserver does not stall the synchronization. The connections are kept for the
+// lifetime of the process, they are kept alive and re-established with a backoff.
+func GetAPIServerFactoryOrDie(addresses []string, clientCA string, pageSize, connections int) store.Factory {
 	once.Do(func() {
 		var (
 			err   error
-			conn  *grpc.ClientConn
+			pool  *connPool
 			creds credentials.TransportCredentials
 		)
 
@@ -46,13 +71,24 @@ func GetAPIServerFactoryOrDie(address string, clientCA string) store.Factory {
 			log.Panicf("credentials.NewClientTLSFromFile err: %v", err)
 		}
 
-		conn, err = grpc.Dial(address, grpc.WithBlock(), grpc.WithTransportCredentials(creds))
+		pool, err = dialPool(connections, func() (*grpc.ClientConn, error) {
+			target, opts := dialTarget(addresses)
+			opts = append(opts,
+				grpc.WithBlock(),
+				grpc.WithTransportCredentials(creds),
+				grpc.WithDefaultServiceConfig(serviceConfig),
+				grpc.WithConnectParams(connectParams),
+				grpc.WithKeepaliveParams(keepaliveParams),
+			)
+
+			return grpc.Dial(target, opts...)
+		})
 		if err != nil {
 			log.Panicf("Connect to grpc server failed, error: %s", err.Error())
 		}
 
-		apiServerFactory = &datastore{pb.NewCacheClient(conn)}
-		log.Infof("Connected to grpc server, address: %s", address)
+		apiServerFactory = &datastore{conn: pool, cli: pb.NewCacheClient(pool), pageSize: int64(pageSize)}
+		log.Infof("Connected to grpc server with %d connections, addresses: %v", connections, addresses)
 	})
 
 	if apiServerFactory == nil {
@@ -61,3 +97,54 @@ func GetAPIServerFactoryOrDie(address string, clientCA string) store.Factory {
 
 	return apiServerFactory
 }
+
+// listPages calls list with the offset and the limit of every page, until a page is shorter
+// than the page size. The pages are pulled one by one, so that the loaders only hold the
+// items they keep instead of a single response of all the items.
+func listPages(pageSize int64, list func(offset, limit int64) (int, error)) error {
+	if pageSize <= 0 {
+		_, err := list(0, -1)
+
+		return err
+	}
+
+	for offset := int64(0); ; offset += pageSize {
+		n, err := list(offset, pageSize)
+		if err != nil {
+			return err
+		}
+
+		if int64(n) < pageSize {
+			return nil
+		}
+	}
+}
+
+// serviceConfig balances the requests over the servers which pass the standard
+// grpc health check. Servers without the health service are considered healthy.
+const serviceConfig = `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""}}`
+
+// dialTarget returns the grpc target of the given addresses.
+// A single address is dialed as is, so it can use a resolver scheme such as
+// dns:///iam.api.marmotedu.com:8081 to re-resolve the servers on failure.
+func dialTarget(addresses []string) (string, []grpc.DialOption) {
+	if len(addresses) == 1 {
+		return addresses[0], nil
+	}
+
+	addrs := make([]resolver.Address, 0, len(addresses))
+	for _, address := range addresses {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+
+		// verify every server certificate against its own host
+		addrs = append(addrs, resolver.Address{Addr: address, ServerName: host})
+	}
+
+	r := manual.NewBuilderWithScheme("iam-apiserver")
+	r.InitialState(resolver.State{Addresses: addrs})
+
+	return r.Scheme() + ":///cache", []grpc.DialOption{grpc.WithResolvers(r)}
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"context"
+
+	"github.com/AlekSi/pointer"
+	"github.com/avast/retry-go"
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/cachefilter"
+	"github.com/marmotedu/iam/internal/pkg/membership"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+type memberships struct {
+	cli pb.CacheClient
+}
+
+func newMemberships(ds *datastore) *memberships {
+	return &memberships{ds.cli}
+}
+
+// List returns the membership indexes of all users.
+func (m *memberships) List() (map[string]membership.Index, error) {
+	log.Info("Loading memberships")
+
+	return m.list(&pb.ListPoliciesRequest{
+		Offset: pointer.ToInt64(0),
+		Limit:  pointer.ToInt64(-1),
+	})
+}
+
+// ListByUser returns the membership index of the groups owned by the given user.
+func (m *memberships) ListByUser(username string) (membership.Index, error) {
+	log.Infof("Loading memberships of user %s", username)
+
+	req := &pb.ListPoliciesRequest{
+		Offset: pointer.ToInt64(0),
+		Limit:  pointer.ToInt64(-1),
+	}
+	cachefilter.SetUsername(req, username)
+
+	indexes, err := m.list(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return indexes[username], nil
+}
+
+func (m *memberships) list(req *pb.ListPoliciesRequest) (map[string]membership.Index, error) {
+	membership.SetOnly(req)
+
+	var resp *pb.ListPoliciesResponse
+	err := retry.Do(
+		func() error {
+			var listErr error
+			resp, listErr = m.cli.ListPolicies(context.Background(), req)
+			if listErr != nil {
+				return listErr
+			}
+
+			return nil
+		}, retry.Attempts(3),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "list memberships failed")
+	}
+
+	memberships := membership.Get(resp)
+	log.Infof("Memberships found (%d total)", len(memberships))
+
+	return membership.NewIndexes(memberships), nil
+}
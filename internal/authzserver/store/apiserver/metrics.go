@@ -0,0 +1,34 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// connectionState is 1 for the current state of every connection to the iam-apiserver.
+	connectionState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iam_authz_apiserver_connection_state",
+			Help: "State of the grpc connections to iam-apiserver, 1 for the current state of a connection.",
+		},
+		[]string{"connection", "state"},
+	)
+
+	// connectionTransitions counts the state changes of the connections to the iam-apiserver.
+	connectionTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_authz_apiserver_connection_transitions_total",
+			Help: "Number of state transitions of the grpc connections to iam-apiserver.",
+		},
+		[]string{"from", "to"},
+	)
+)
+
+// nolint: gochecknoinits
+func init() {
+	prometheus.MustRegister(connectionState, connectionTransitions)
+}
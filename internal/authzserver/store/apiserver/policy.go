@@ -14,59 +14,95 @@ import (
 	"github.com/marmotedu/errors"
 	"github.com/ory/ladon"
 
+	"github.com/marmotedu/iam/internal/pkg/cachefilter"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
 type policies struct {
-	cli pb.CacheClient
+	cli      pb.CacheClient
+	pageSize int64
 }
 
 func newPolicies(ds *datastore) *policies {
-	return &policies{ds.cli}
+	return &policies{cli: ds.cli, pageSize: ds.pageSize}
 }
 
 // List returns all the authorization policies.
 func (p *policies) List() (map[string][]*ladon.DefaultPolicy, error) {
-	pols := make(map[string][]*ladon.DefaultPolicy)
-
 	log.Info("Loading policies")
 
-	req := &pb.ListPoliciesRequest{
-		Offset: pointer.ToInt64(0),
-		Limit:  pointer.ToInt64(-1),
-	}
+	return p.list(&pb.ListPoliciesRequest{})
+}
 
-	var resp *pb.ListPoliciesResponse
-	err := retry.Do(
-		func() error {
-			var listErr error
-			resp, listErr = p.cli.ListPolicies(context.Background(), req)
-			if listErr != nil {
-				return listErr
-			}
+// ListByUser returns the authorization policies of the given user.
+func (p *policies) ListByUser(username string) ([]*ladon.DefaultPolicy, error) {
+	log.Infof("Loading policies of user %s", username)
 
-			return nil
-		}, retry.Attempts(3),
-	)
+	req := &pb.ListPoliciesRequest{}
+	cachefilter.SetUsername(req, username)
+
+	pols, err := p.list(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "list policies failed")
+		return nil, err
 	}
 
-	log.Infof("Policies found (%d total)[username:name]:", len(resp.Items))
+	return pols[username], nil
+}
+
+func (p *policies) list(req *pb.ListPoliciesRequest) (map[string][]*ladon.DefaultPolicy, error) {
+	pols := make(map[string][]*ladon.DefaultPolicy)
+	// the pages shift when the policies change while they are pulled, a policy moved to the
+	// next page is seen twice and kept once, the reload on change catches up the others
+	seen := make(map[string]struct{})
+	total := 0
+
+	err := listPages(p.pageSize, func(offset, limit int64) (int, error) {
+		req.Offset, req.Limit = pointer.ToInt64(offset), pointer.ToInt64(limit)
+
+		var resp *pb.ListPoliciesResponse
+		err := retry.Do(
+			func() error {
+				var listErr error
+				resp, listErr = p.cli.ListPolicies(context.Background(), req)
+				if listErr != nil {
+					return listErr
+				}
+
+				return nil
+			}, retry.Attempts(3),
+		)
+		if err != nil {
+			return 0, err
+		}
 
-	for _, v := range resp.Items {
-		log.Infof(" - %s:%s", v.Username, v.Name)
+		for _, v := range resp.Items {
+			key := v.Username + ":" + v.Name
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
 
-		var policy ladon.DefaultPolicy
+			log.Infof(" - %s", key)
 
-		if err := json.Unmarshal([]byte(v.PolicyShadow), &policy); err != nil {
-			log.Warnf("failed to load policy for %s, error: %s", v.Name, err.Error())
+			var policy ladon.DefaultPolicy
 
-			continue
+			if err := json.Unmarshal([]byte(v.PolicyShadow), &policy); err != nil {
+				log.Warnf("failed to load policy for %s, error: %s", v.Name, err.Error())
+
+				continue
+			}
+
+			pols[v.Username] = append(pols[v.Username], &policy)
+			total++
 		}
 
-		pols[v.Username] = append(pols[v.Username], &policy)
+		return len(resp.Items), nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list policies failed")
 	}
 
+	log.Infof("Policies found (%d total)", total)
+
 	return pols, nil
 }
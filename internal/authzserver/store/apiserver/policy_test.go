@@ -0,0 +1,81 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"google.golang.org/grpc"
+)
+
+// fakeCache serves the pages of items, the shifted items are served again on the next page.
+type fakeCache struct {
+	pb.CacheClient
+	items    []*pb.PolicyInfo
+	shift    int64
+	requests []string
+}
+
+func (f *fakeCache) ListPolicies(ctx context.Context, in *pb.ListPoliciesRequest,
+	opts ...grpc.CallOption,
+) (*pb.ListPoliciesResponse, error) {
+	f.requests = append(f.requests, fmt.Sprintf("%d/%d", *in.Offset, *in.Limit))
+
+	start, end := *in.Offset, int64(len(f.items))
+	if start > 0 {
+		start -= f.shift
+	}
+	if *in.Limit >= 0 && start+*in.Limit < end {
+		end = start + *in.Limit
+	}
+	if start > end {
+		start = end
+	}
+
+	return &pb.ListPoliciesResponse{TotalCount: int64(len(f.items)), Items: f.items[start:end]}, nil
+}
+
+func TestPoliciesList(t *testing.T) {
+	items := make([]*pb.PolicyInfo, 0)
+	for i := 0; i < 5; i++ {
+		items = append(items, &pb.PolicyInfo{
+			Name:         fmt.Sprintf("policy-%d", i),
+			Username:     "colin",
+			PolicyShadow: fmt.Sprintf(`{"id":"policy-%d","effect":"allow"}`, i),
+		})
+	}
+
+	tests := []struct {
+		name         string
+		pageSize     int64
+		shift        int64
+		wantRequests int
+	}{
+		{name: "pages", pageSize: 2, wantRequests: 3},
+		{name: "last page is full", pageSize: 5, wantRequests: 2},
+		{name: "single request", pageSize: -1, wantRequests: 1},
+		{name: "shifted items are kept once", pageSize: 2, shift: 1, wantRequests: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &fakeCache{items: items, shift: tt.shift}
+			p := &policies{cli: cli, pageSize: tt.pageSize}
+
+			pols, err := p.List()
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if got := len(pols["colin"]); got != len(items) {
+				t.Errorf("List() returns %d policies, want %d", got, len(items))
+			}
+			if len(cli.requests) != tt.wantRequests {
+				t.Errorf("requests = %v, want %d requests", cli.requests, tt.wantRequests)
+			}
+		})
+	}
+}
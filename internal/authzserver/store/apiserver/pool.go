@@ -0,0 +1,129 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+var (
+	// connectParams retries a lost connection with an exponential backoff up to 30 seconds.
+	connectParams = grpc.ConnectParams{
+		Backoff: backoff.Config{
+			BaseDelay:  time.Second,
+			Multiplier: 1.6,
+			Jitter:     0.2,
+			MaxDelay:   30 * time.Second,
+		},
+		MinConnectTimeout: 5 * time.Second,
+	}
+
+	// keepaliveParams detects the dead connections between two synchronizations, the
+	// iam-apiserver permits pings every 10 seconds.
+	keepaliveParams = keepalive.ClientParameters{
+		Time:                30 * time.Second,
+		Timeout:             10 * time.Second,
+		PermitWithoutStream: true,
+	}
+)
+
+// connPool multiplexes the calls over long-lived connections in turn, skipping the ones
+// which are not ready.
+type connPool struct {
+	conns []*grpc.ClientConn
+	next  uint32
+}
+
+var _ grpc.ClientConnInterface = &connPool{}
+
+// dialPool dials size connections and watches their state.
+func dialPool(size int, dial func() (*grpc.ClientConn, error)) (*connPool, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	p := &connPool{conns: make([]*grpc.ClientConn, 0, size)}
+	for i := 0; i < size; i++ {
+		conn, err := dial()
+		if err != nil {
+			p.Close()
+
+			return nil, err
+		}
+
+		p.conns = append(p.conns, conn)
+		go watchState(strconv.Itoa(i), conn)
+	}
+
+	return p, nil
+}
+
+// Invoke performs a unary RPC on one of the connections.
+func (p *connPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream begins a streaming RPC on one of the connections.
+func (p *connPool) NewStream(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	method string,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+// Close closes all the connections.
+func (p *connPool) Close() {
+	for _, conn := range p.conns {
+		_ = conn.Close()
+	}
+}
+
+// pick returns the next ready connection, or the next one when none is ready so that the
+// call waits for it to reconnect or fails.
+func (p *connPool) pick() *grpc.ClientConn {
+	n := uint32(len(p.conns))
+	start := atomic.AddUint32(&p.next, 1)
+	for i := uint32(0); i < n; i++ {
+		if conn := p.conns[(start+i)%n]; conn.GetState() == connectivity.Ready {
+			return conn
+		}
+	}
+
+	return p.conns[start%n]
+}
+
+// watchState records the state transitions of the connection until it is closed.
+func watchState(name string, conn *grpc.ClientConn) {
+	state := conn.GetState()
+	connectionState.WithLabelValues(name, state.String()).Set(1)
+
+	for state != connectivity.Shutdown {
+		conn.WaitForStateChange(context.Background(), state)
+
+		next := conn.GetState()
+		connectionState.WithLabelValues(name, state.String()).Set(0)
+		connectionState.WithLabelValues(name, next.String()).Set(1)
+		// counted last, once the states are up to date
+		connectionTransitions.WithLabelValues(state.String(), next.String()).Inc()
+
+		if next == connectivity.TransientFailure {
+			log.Warnf("Connection %s to grpc server failed, reconnecting", name)
+		}
+
+		state = next
+	}
+}
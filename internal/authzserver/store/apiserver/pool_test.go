@@ -0,0 +1,76 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apiserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestConnPool(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	shutdowns := testutil.ToFloat64(connectionTransitions.WithLabelValues("READY", "SHUTDOWN"))
+	pool, err := dialPool(2, func() (*grpc.ClientConn, error) {
+		return grpc.Dial("bufnet",
+			grpc.WithBlock(),
+			grpc.WithInsecure(),
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+			grpc.WithConnectParams(connectParams),
+		)
+	})
+	if err != nil {
+		t.Fatalf("dialPool() error = %v", err)
+	}
+
+	// the calls are spread over the ready connections
+	picked := map[*grpc.ClientConn]int{}
+	for i := 0; i < 4; i++ {
+		picked[pool.pick()]++
+	}
+	if len(picked) != 2 {
+		t.Errorf("pick() returns %d connections, want 2", len(picked))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := healthpb.NewHealthClient(pool).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	// a connection which is not ready is skipped
+	_ = pool.conns[0].Close()
+	for i := 0; i < 4; i++ {
+		if conn := pool.pick(); conn != pool.conns[1] {
+			t.Errorf("pick() = %p, want the ready connection %p", conn, pool.conns[1])
+		}
+	}
+
+	pool.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(connectionTransitions.WithLabelValues("READY", "SHUTDOWN"))-shutdowns != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the shutdown of the connections is not recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := testutil.ToFloat64(connectionState.WithLabelValues("1", connectivity.Ready.String())); got != 0 {
+		t.Errorf("READY state of a closed connection = %v, want 0", got)
+	}
+}
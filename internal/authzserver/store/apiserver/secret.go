@@ -12,50 +12,82 @@ import (
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"github.com/marmotedu/errors"
 
+	"github.com/marmotedu/iam/internal/pkg/cachefilter"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
 type secrets struct {
-	cli pb.CacheClient
+	cli      pb.CacheClient
+	pageSize int64
 }
 
 func newSecrets(ds *datastore) *secrets {
-	return &secrets{ds.cli}
+	return &secrets{cli: ds.cli, pageSize: ds.pageSize}
 }
 
 // List returns all the authorization secrets.
 func (s *secrets) List() (map[string]*pb.SecretInfo, error) {
-	secrets := make(map[string]*pb.SecretInfo)
-
 	log.Info("Loading secrets")
 
-	req := &pb.ListSecretsRequest{
-		Offset: pointer.ToInt64(0),
-		Limit:  pointer.ToInt64(-1),
-	}
+	return s.list(&pb.ListSecretsRequest{})
+}
+
+// ListByUser returns the authorization secrets of the given user.
+func (s *secrets) ListByUser(username string) (map[string]*pb.SecretInfo, error) {
+	log.Infof("Loading secrets of user %s", username)
 
-	var resp *pb.ListSecretsResponse
-	err := retry.Do(
-		func() error {
-			var listErr error
-			resp, listErr = s.cli.ListSecrets(context.Background(), req)
-			if listErr != nil {
-				return listErr
-			}
-
-			return nil
-		}, retry.Attempts(3),
-	)
+	req := &pb.ListSecretsRequest{}
+	cachefilter.SetUsername(req, username)
+
+	secrets, err := s.list(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "list secrets failed")
+		return nil, err
 	}
 
-	log.Infof("Secrets found (%d total):", len(resp.Items))
+	// an apiserver without the filter support returns the secrets of all users
+	for key, secret := range secrets {
+		if secret.Username != username {
+			delete(secrets, key)
+		}
+	}
+
+	return secrets, nil
+}
+
+func (s *secrets) list(req *pb.ListSecretsRequest) (map[string]*pb.SecretInfo, error) {
+	secrets := make(map[string]*pb.SecretInfo)
+
+	err := listPages(s.pageSize, func(offset, limit int64) (int, error) {
+		req.Offset, req.Limit = pointer.ToInt64(offset), pointer.ToInt64(limit)
+
+		var resp *pb.ListSecretsResponse
+		err := retry.Do(
+			func() error {
+				var listErr error
+				resp, listErr = s.cli.ListSecrets(context.Background(), req)
+				if listErr != nil {
+					return listErr
+				}
 
-	for _, v := range resp.Items {
-		log.Infof(" - %s:%s", v.Username, v.SecretId)
-		secrets[v.SecretId] = v
+				return nil
+			}, retry.Attempts(3),
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, v := range resp.Items {
+			log.Infof(" - %s:%s", v.Username, v.SecretId)
+			secrets[v.SecretId] = v
+		}
+
+		return len(resp.Items), nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list secrets failed")
 	}
 
+	log.Infof("Secrets found (%d total)", len(secrets))
+
 	return secrets, nil
 }
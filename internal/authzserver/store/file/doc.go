@@ -0,0 +1,26 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package file implements the authzserver storage with local JSON or YAML files, so that the
+// authzserver runs standalone, without iam-apiserver.
+//
+// The secrets file lists the secrets:
+//
+//	secrets:
+//	- username: colin
+//	  secretID: ZuxvXNfG08BdEMqkTaP41L2DLArlE6Jpqoox
+//	  secretKey: 7Sfa5EfAPIwcTLGCfSvqLf0zZGCjF3l8
+//	  expires: 0
+//
+// The policies file lists the policies, in the format of ladon:
+//
+//	policies:
+//	- username: colin
+//	  policy:
+//	    id: policy0
+//	    subjects: ["users:<peter|ken>"]
+//	    actions: ["<delete|get>"]
+//	    resources: ["resources:articles:<.*>"]
+//	    effect: allow
+package file // import "github.com/marmotedu/iam/internal/authzserver/store/file"
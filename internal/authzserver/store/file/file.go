@@ -0,0 +1,190 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"os"
+
+	"github.com/ghodss/yaml"
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/authzserver/store"
+	"github.com/marmotedu/iam/internal/pkg/membership"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+type datastore struct {
+	secretsFile  string
+	policiesFile string
+}
+
+// NewFactory returns the storage reading the secrets and the policies from the files of opts.
+// The files are read on every load, so that their changes are picked up by the next reload.
+func NewFactory(opts *StandaloneOptions) store.Factory {
+	return &datastore{secretsFile: opts.SecretsFile, policiesFile: opts.PoliciesFile}
+}
+
+func (ds *datastore) Secrets() store.SecretStore {
+	return &secrets{file: ds.secretsFile}
+}
+
+func (ds *datastore) Policies() store.PolicyStore {
+	return &policies{file: ds.policiesFile}
+}
+
+// Memberships returns no membership, the groups are not supported in standalone mode.
+func (ds *datastore) Memberships() store.MembershipStore {
+	return memberships{}
+}
+
+// secretRecord is a secret of the secrets file.
+type secretRecord struct {
+	Username    string `json:"username"`
+	SecretID    string `json:"secretID"`
+	SecretKey   string `json:"secretKey"`
+	Expires     int64  `json:"expires"`
+	Description string `json:"description,omitempty"`
+}
+
+// policyRecord is a policy of the policies file.
+type policyRecord struct {
+	Username string              `json:"username"`
+	Policy   ladon.DefaultPolicy `json:"policy"`
+}
+
+type secrets struct {
+	file string
+}
+
+// List returns all the authorization secrets.
+func (s *secrets) List() (map[string]*pb.SecretInfo, error) {
+	log.Infof("Loading secrets from %s", s.file)
+
+	var content struct {
+		Secrets []secretRecord `json:"secrets"`
+	}
+	if err := readFile(s.file, &content); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]*pb.SecretInfo, len(content.Secrets))
+	for i, v := range content.Secrets {
+		if v.Username == "" || v.SecretID == "" || v.SecretKey == "" {
+			return nil, errors.Errorf("secret %d of %s requires a username, a secretID and a secretKey", i, s.file)
+		}
+
+		if _, ok := ret[v.SecretID]; ok {
+			return nil, errors.Errorf("secret %s is duplicated in %s", v.SecretID, s.file)
+		}
+
+		ret[v.SecretID] = &pb.SecretInfo{
+			Username:    v.Username,
+			SecretId:    v.SecretID,
+			SecretKey:   v.SecretKey,
+			Expires:     v.Expires,
+			Description: v.Description,
+		}
+	}
+
+	log.Infof("Secrets found (%d total)", len(ret))
+
+	return ret, nil
+}
+
+// ListByUser returns the authorization secrets of the given user.
+func (s *secrets) ListByUser(username string) (map[string]*pb.SecretInfo, error) {
+	secrets, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for key, secret := range secrets {
+		if secret.Username != username {
+			delete(secrets, key)
+		}
+	}
+
+	return secrets, nil
+}
+
+type policies struct {
+	file string
+}
+
+// List returns all the authorization policies.
+func (p *policies) List() (map[string][]*ladon.DefaultPolicy, error) {
+	log.Infof("Loading policies from %s", p.file)
+
+	ret, err := ReadPolicies(p.file)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, pols := range ret {
+		total += len(pols)
+	}
+	log.Infof("Policies found (%d total)", total)
+
+	return ret, nil
+}
+
+// ReadPolicies reads the policies file, the policies are keyed by their username.
+func ReadPolicies(name string) (map[string][]*ladon.DefaultPolicy, error) {
+	var content struct {
+		Policies []policyRecord `json:"policies"`
+	}
+	if err := readFile(name, &content); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string][]*ladon.DefaultPolicy)
+	for i := range content.Policies {
+		v := &content.Policies[i]
+		if v.Username == "" || v.Policy.ID == "" {
+			return nil, errors.Errorf("policy %d of %s requires a username and an id", i, name)
+		}
+
+		ret[v.Username] = append(ret[v.Username], &v.Policy)
+	}
+
+	return ret, nil
+}
+
+// ListByUser returns the authorization policies of the given user.
+func (p *policies) ListByUser(username string) ([]*ladon.DefaultPolicy, error) {
+	pols, err := p.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return pols[username], nil
+}
+
+type memberships struct{}
+
+func (memberships) List() (map[string]membership.Index, error) {
+	return map[string]membership.Index{}, nil
+}
+
+func (memberships) ListByUser(username string) (membership.Index, error) {
+	return nil, nil
+}
+
+// readFile decodes the JSON or YAML file into v.
+func readFile(name string, v interface{}) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return errors.Wrapf(err, "read %s failed", name)
+	}
+
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return errors.Wrapf(err, "decode %s failed", name)
+	}
+
+	return nil
+}
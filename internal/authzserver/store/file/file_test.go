@@ -0,0 +1,103 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secretsYAML = `
+secrets:
+- username: colin
+  secretID: id0
+  secretKey: key0
+- username: peter
+  secretID: id1
+  secretKey: key1
+  expires: 1700000000
+`
+
+const policiesJSON = `{"policies": [
+  {"username": "colin", "policy": {"id": "policy0", "subjects": ["users:<peter|ken>"], "actions": ["get"],
+    "resources": ["resources:<.*>"], "effect": "allow", "conditions": {"remoteIPAddress": {
+    "type": "CIDRCondition", "options": {"cidr": "192.168.0.1/16"}}}}}
+]}`
+
+func writeFiles(t *testing.T, secrets, policies string) *StandaloneOptions {
+	t.Helper()
+
+	dir := t.TempDir()
+	opts := &StandaloneOptions{
+		Enable:       true,
+		SecretsFile:  filepath.Join(dir, "secrets.yaml"),
+		PoliciesFile: filepath.Join(dir, "policies.json"),
+	}
+	assert.NoError(t, os.WriteFile(opts.SecretsFile, []byte(secrets), 0o600))
+	assert.NoError(t, os.WriteFile(opts.PoliciesFile, []byte(policies), 0o600))
+
+	return opts
+}
+
+func TestFactory(t *testing.T) {
+	factory := NewFactory(writeFiles(t, secretsYAML, policiesJSON))
+
+	secrets, err := factory.Secrets().List()
+	assert.NoError(t, err)
+	assert.Len(t, secrets, 2)
+	assert.Equal(t, "key1", secrets["id1"].SecretKey)
+	assert.Equal(t, int64(1700000000), secrets["id1"].Expires)
+
+	secrets, err = factory.Secrets().ListByUser("colin")
+	assert.NoError(t, err)
+	assert.Len(t, secrets, 1)
+	assert.Contains(t, secrets, "id0")
+
+	policies, err := factory.Policies().ListByUser("colin")
+	assert.NoError(t, err)
+	assert.Len(t, policies, 1)
+	assert.Equal(t, "policy0", policies[0].ID)
+	assert.Contains(t, policies[0].Conditions, "remoteIPAddress")
+
+	memberships, err := factory.Memberships().List()
+	assert.NoError(t, err)
+	assert.Empty(t, memberships)
+}
+
+func TestFactory_Invalid(t *testing.T) {
+	factory := NewFactory(writeFiles(t, "secrets:\n- username: colin\n  secretID: id0\n", "policies: [}"))
+
+	_, err := factory.Secrets().List()
+	assert.Error(t, err)
+
+	_, err = factory.Policies().List()
+	assert.Error(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	opts := writeFiles(t, secretsYAML, policiesJSON)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan struct{}, 1)
+	assert.NoError(t, Watch(ctx, opts, func() { reloaded <- struct{}{} }))
+
+	// the file is replaced by a rename, as most editors do
+	tmp := opts.PoliciesFile + ".tmp"
+	assert.NoError(t, os.WriteFile(tmp, []byte(`{"policies": []}`), 0o600))
+	assert.NoError(t, os.Rename(tmp, opts.PoliciesFile))
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after the policies file changed")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// StandaloneOptions contains configuration items related to the standalone mode, which loads
+// the secrets and the policies from local files instead of iam-apiserver.
+type StandaloneOptions struct {
+	Enable       bool   `json:"enable"        mapstructure:"enable"`
+	SecretsFile  string `json:"secrets-file"  mapstructure:"secrets-file"`
+	PoliciesFile string `json:"policies-file" mapstructure:"policies-file"`
+}
+
+// NewStandaloneOptions creates a StandaloneOptions object with default parameters.
+func NewStandaloneOptions() *StandaloneOptions {
+	return &StandaloneOptions{}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *StandaloneOptions) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+
+	errors := []error{}
+
+	if o.SecretsFile == "" {
+		errors = append(errors, fmt.Errorf("--standalone.secrets-file can not be empty in standalone mode"))
+	}
+
+	if o.PoliciesFile == "" {
+		errors = append(errors, fmt.Errorf("--standalone.policies-file can not be empty in standalone mode"))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the standalone mode for a specific api server to the
+// specified FlagSet.
+func (o *StandaloneOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "standalone.enable", o.Enable, ""+
+		"Load the secrets and the policies from local files instead of the rpc servers. "+
+		"The files are reloaded whenever they change.")
+
+	fs.StringVar(&o.SecretsFile, "standalone.secrets-file", o.SecretsFile, ""+
+		"JSON or YAML file containing the secrets in standalone mode.")
+
+	fs.StringVar(&o.PoliciesFile, "standalone.policies-file", o.PoliciesFile, ""+
+		"JSON or YAML file containing the policies in standalone mode.")
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// watchDelay gathers the events of a single change of the files, such as an editor writing a
+// file in several steps, into a single reload.
+const watchDelay = 500 * time.Millisecond
+
+// Watch calls reload whenever one of the files of opts changes, until ctx is done. The
+// directories of the files are watched rather than the files, so that the files replaced by
+// a rename, including the files of a kubernetes ConfigMap, are followed.
+func Watch(ctx context.Context, opts *StandaloneOptions, reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	files := map[string]bool{}
+	dirs := map[string]bool{}
+	for _, name := range []string{opts.SecretsFile, opts.PoliciesFile} {
+		name = filepath.Clean(name)
+		files[name] = true
+		dirs[filepath.Dir(name)] = true
+	}
+
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var timer <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// a ConfigMap is updated by swapping the ..data link of its directory
+				name := filepath.Clean(event.Name)
+				if files[name] || filepath.Base(name) == "..data" {
+					timer = time.After(watchDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				log.Warnf("Watch standalone files failed: %s", err.Error())
+			case <-timer:
+				timer = nil
+				log.Info("Standalone files changed, reloading secrets and policies")
+				reload()
+			}
+		}
+	}()
+
+	return nil
+}
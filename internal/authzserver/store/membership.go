@@ -0,0 +1,13 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package store
+
+import "github.com/marmotedu/iam/internal/pkg/membership"
+
+// MembershipStore defines the group membership storage interface.
+type MembershipStore interface {
+	List() (map[string]membership.Index, error)
+	ListByUser(username string) (membership.Index, error)
+}
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/marmotedu/iam/internal/authzserver/store (interfaces: Factory,SecretStore,PolicyStore)
+// Source: github.com/marmotedu/iam/internal/authzserver/store (interfaces: Factory,SecretStore,PolicyStore,MembershipStore)
 
 // Package store is a generated GoMock package.
 package store
@@ -13,6 +13,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1 "github.com/marmotedu/api/proto/apiserver/v1"
+	membership "github.com/marmotedu/iam/internal/pkg/membership"
 	ladon "github.com/ory/ladon"
 )
 
@@ -39,6 +40,20 @@ func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
 	return m.recorder
 }
 
+// Memberships mocks base method.
+func (m *MockFactory) Memberships() MembershipStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Memberships")
+	ret0, _ := ret[0].(MembershipStore)
+	return ret0
+}
+
+// Memberships indicates an expected call of Memberships.
+func (mr *MockFactoryMockRecorder) Memberships() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Memberships", reflect.TypeOf((*MockFactory)(nil).Memberships))
+}
+
 // Policies mocks base method.
 func (m *MockFactory) Policies() PolicyStore {
 	m.ctrl.T.Helper()
@@ -105,6 +120,21 @@ func (mr *MockSecretStoreMockRecorder) List() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSecretStore)(nil).List))
 }
 
+// ListByUser mocks base method.
+func (m *MockSecretStore) ListByUser(arg0 string) (map[string]*v1.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", arg0)
+	ret0, _ := ret[0].(map[string]*v1.SecretInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockSecretStoreMockRecorder) ListByUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockSecretStore)(nil).ListByUser), arg0)
+}
+
 // MockPolicyStore is a mock of PolicyStore interface.
 type MockPolicyStore struct {
 	ctrl     *gomock.Controller
@@ -142,3 +172,71 @@ func (mr *MockPolicyStoreMockRecorder) List() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPolicyStore)(nil).List))
 }
+
+// ListByUser mocks base method.
+func (m *MockPolicyStore) ListByUser(arg0 string) ([]*ladon.DefaultPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", arg0)
+	ret0, _ := ret[0].([]*ladon.DefaultPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockPolicyStoreMockRecorder) ListByUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockPolicyStore)(nil).ListByUser), arg0)
+}
+
+// MockMembershipStore is a mock of MembershipStore interface.
+type MockMembershipStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipStoreMockRecorder
+}
+
+// MockMembershipStoreMockRecorder is the mock recorder for MockMembershipStore.
+type MockMembershipStoreMockRecorder struct {
+	mock *MockMembershipStore
+}
+
+// NewMockMembershipStore creates a new mock instance.
+func NewMockMembershipStore(ctrl *gomock.Controller) *MockMembershipStore {
+	mock := &MockMembershipStore{ctrl: ctrl}
+	mock.recorder = &MockMembershipStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipStore) EXPECT() *MockMembershipStoreMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMembershipStore) List() (map[string]membership.Index, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].(map[string]membership.Index)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMembershipStoreMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMembershipStore)(nil).List))
+}
+
+// ListByUser mocks base method.
+func (m *MockMembershipStore) ListByUser(arg0 string) (membership.Index, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", arg0)
+	ret0, _ := ret[0].(membership.Index)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockMembershipStoreMockRecorder) ListByUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockMembershipStore)(nil).ListByUser), arg0)
+}
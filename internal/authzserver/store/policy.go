@@ -9,4 +9,5 @@ import "github.com/ory/ladon"
 // PolicyStore defines the policy storage interface.
 type PolicyStore interface {
 	List() (map[string][]*ladon.DefaultPolicy, error)
+	ListByUser(username string) ([]*ladon.DefaultPolicy, error)
 }
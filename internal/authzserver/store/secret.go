@@ -10,4 +10,5 @@ import pb "github.com/marmotedu/api/proto/apiserver/v1"
 type SecretStore interface {
 	// List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.SecretList, error)
 	List() (map[string]*pb.SecretInfo, error)
+	ListByUser(username string) (map[string]*pb.SecretInfo, error)
 }
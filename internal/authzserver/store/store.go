@@ -4,7 +4,9 @@
 
 package store
 
-//go:generate mockgen -self_package=github.com/marmotedu/iam/internal/authzserver/store -destination mock_store.go -package store github.com/marmotedu/iam/internal/authzserver/store Factory,SecretStore,PolicyStore
+import "github.com/marmotedu/iam/internal/pkg/cachesync"
+
+//go:generate mockgen -self_package=github.com/marmotedu/iam/internal/authzserver/store -destination mock_store.go -package store github.com/marmotedu/iam/internal/authzserver/store Factory,SecretStore,PolicyStore,MembershipStore
 
 var client Factory
 
@@ -12,6 +14,13 @@ var client Factory
 type Factory interface {
 	Policies() PolicyStore
 	Secrets() SecretStore
+	Memberships() MembershipStore
+}
+
+// ChangeLister is implemented by the factories which list the changes after a revision, so
+// that the cache catches up with them instead of reloading everything.
+type ChangeLister interface {
+	ListChangesSince(revision int64) (*cachesync.ListChangesSinceResponse, error)
 }
 
 // Client return the store client instance.
@@ -0,0 +1,70 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package audit provides functions to query the audit events of iam platform.
+package audit
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+var auditLong = templates.LongDesc(`
+	Audit event query commands.
+
+	The audit events record who created, updated or deleted which resource, and when.
+	This commands allow administrators to query them on iam platform.`)
+
+// NewCmdAudit returns new initialized instance of 'audit' sub command.
+func NewCmdAudit(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "audit SUBCOMMAND",
+		DisableFlagsInUseLine: true,
+		Short:                 "Query the audit events of iam platform",
+		Long:                  auditLong,
+		Run:                   cmdutil.DefaultSubCommandRun(ioStreams.ErrOut),
+	}
+
+	cmd.AddCommand(NewCmdList(f, ioStreams))
+
+	return cmd
+}
+
+// auditTable returns the table of the audit events.
+func auditTable(events ...*v1.AuditEvent) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Time"},
+		printers.Column{Name: "User"},
+		printers.Column{Name: "Verb"},
+		printers.Column{Name: "Resource"},
+		printers.Column{Name: "Name"},
+		printers.Column{Name: "Status"},
+		printers.Column{Name: "IP", Wide: true},
+		printers.Column{Name: "Path", Wide: true},
+		printers.Column{Name: "RequestID", Wide: true},
+	)
+
+	for _, event := range events {
+		table.AddRow(
+			event.CreatedAt.Format("2006-01-02 15:04:05"),
+			event.Username,
+			event.Verb,
+			event.Resource,
+			event.ResourceName,
+			strconv.Itoa(event.StatusCode),
+			event.IP,
+			event.Path,
+			event.RequestID,
+		)
+	}
+
+	return table
+}
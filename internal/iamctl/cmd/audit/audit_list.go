@@ -0,0 +1,333 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/interrupt"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+const (
+	defaultLimit = 100
+
+	// followLimit is the page size used to get the new events in follow mode.
+	followLimit = 1000
+)
+
+// ListOptions is an options struct to support audit list subcommands.
+type ListOptions struct {
+	User     string
+	Verb     string
+	Resource string
+	Name     string
+	Since    time.Duration
+	Limit    int64
+	Follow   bool
+	Interval time.Duration
+
+	PrintFlags *printers.PrintFlags
+
+	printer printers.ResourcePrinter
+	client  rest.Interface
+	genericclioptions.IOStreams
+}
+
+var (
+	listLong = templates.LongDesc(`Display the audit events, oldest first.
+
+An audit event is recorded for every request of an authenticated user which creates, updates
+or deletes a resource, whether it succeeded or not. Only administrators can list them.
+
+With --follow, the new events are printed as they are recorded, until interrupted.`)
+
+	listExample = templates.Examples(`
+		# Display the most recent audit events
+		iamctl audit list
+
+		# Display the deletions made by colin in the last 24 hours
+		iamctl audit list --user colin --since 24h --verb delete
+
+		# Display the changes of the policy foo, with the ip and path of the requests
+		iamctl audit list --resource policies --name foo -o wide
+
+		# Follow the new audit events of the secrets, as json
+		iamctl audit list --resource secrets --follow -o json`)
+)
+
+// NewListOptions returns an initialized ListOptions instance.
+func NewListOptions(ioStreams genericclioptions.IOStreams) *ListOptions {
+	return &ListOptions{
+		Limit:      defaultLimit,
+		Interval:   2 * time.Second,
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdList returns new initialized instance of list sub command.
+func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewListOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "list",
+		DisableFlagsInUseLine: true,
+		Short:                 "Display the audit events",
+		Long:                  listLong,
+		Example:               listExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.User, "user", o.User, "Only display the events of the user.")
+	cmd.Flags().StringVar(&o.Verb, "verb", o.Verb, "Only display the events of the verb, one of: create|update|delete.")
+	cmd.Flags().StringVar(&o.Resource, "resource", o.Resource, "Only display the events of the resource type, e.g. policies.")
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Only display the events of the resource with the name.")
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only display the events newer than a relative duration like 30m or 24h.")
+	cmd.Flags().Int64VarP(&o.Limit, "limit", "l", o.Limit, "Specify the amount of the most recent events to be displayed.")
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", o.Follow, "Keep printing the new events as they are recorded.")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "The interval between the polls of the new events in follow mode.")
+	o.PrintFlags.AddFlags(cmd)
+
+	_ = cmd.RegisterFlagCompletionFunc("user", cmdutil.ResourceNameFlagCompletionFunc(f, v1.CompletionKindUser))
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *ListOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = f.RESTClient()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *ListOptions) Validate(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected args: %v", args)
+	}
+
+	switch o.Verb {
+	case "", v1.AuditVerbCreate, v1.AuditVerbUpdate, v1.AuditVerbDelete:
+	default:
+		return cmdutil.UsageErrorf(cmd, "--verb must be one of: create|update|delete, got %q", o.Verb)
+	}
+
+	if o.Since < 0 {
+		return cmdutil.UsageErrorf(cmd, "--since must be a positive duration, got %s", o.Since)
+	}
+
+	if o.Limit <= 0 {
+		return cmdutil.UsageErrorf(cmd, "--limit must be greater than 0, got %d", o.Limit)
+	}
+
+	if o.Follow && o.Interval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s, got %s", o.Interval)
+	}
+
+	return nil
+}
+
+// Run executes a list subcommand using the specified options.
+func (o *ListOptions) Run(args []string) error {
+	var since *time.Time
+	if o.Since > 0 {
+		t := time.Now().Add(-o.Since)
+		since = &t
+	}
+
+	events, err := o.list(context.TODO(), since, 0, 0, o.Limit)
+	if err != nil {
+		return err
+	}
+
+	if !o.Follow {
+		reverse(events.Items)
+
+		return o.printer.PrintObj(events, auditTable(events.Items...), o.Out)
+	}
+
+	return o.follow(events.Items)
+}
+
+// follow prints the events, then polls and prints the new ones until interrupted.
+func (o *ListOptions) follow(events []*v1.AuditEvent) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lastID uint64
+	if len(events) > 0 {
+		lastID = events[0].ID
+	}
+	reverse(events)
+	if err := o.print(events, true); err != nil {
+		return err
+	}
+	// the headers are printed with the first events
+	headers := len(events) == 0
+
+	// an interrupt stops the polls, instead of exiting with an error
+	return interrupt.New(func(os.Signal) {}, cancel).Run(func() error {
+		ticker := time.NewTicker(o.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			events, err := o.listAfter(ctx, lastID)
+			if ctx.Err() != nil {
+				return nil
+			}
+			// a failed poll is retried at the next interval, the server may be restarting
+			if err != nil {
+				fmt.Fprintf(o.ErrOut, "WARNING: failed to get the new audit events: %s\n",
+					strings.TrimSpace(strings.SplitN(err.Error(), "\n", 2)[0]))
+
+				continue
+			}
+
+			if len(events) == 0 {
+				continue
+			}
+			lastID = events[0].ID
+			reverse(events)
+
+			if err := o.print(events, headers); err != nil {
+				return err
+			}
+			headers = false
+		}
+	})
+}
+
+// print prints the events in follow mode. The events are printed one by one in the json and
+// yaml output formats, so that the output is a stream of objects.
+func (o *ListOptions) print(events []*v1.AuditEvent, headers bool) error {
+	if p, ok := o.printer.(*printers.TablePrinter); ok {
+		if len(events) == 0 {
+			return nil
+		}
+
+		table := *p
+		table.NoHeaders = p.NoHeaders || !headers
+
+		return table.PrintObj(nil, auditTable(events...), o.Out)
+	}
+
+	for _, event := range events {
+		if _, ok := o.printer.(*printers.YAMLPrinter); ok {
+			fmt.Fprintln(o.Out, "---")
+		}
+
+		if err := o.printer.PrintObj(event, nil, o.Out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listAfter returns all the events whose id is greater than afterID, newest first.
+func (o *ListOptions) listAfter(ctx context.Context, afterID uint64) ([]*v1.AuditEvent, error) {
+	var events []*v1.AuditEvent
+	for {
+		page, err := o.list(ctx, nil, afterID, int64(len(events)), followLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page.Items...)
+		if len(page.Items) == 0 || int64(len(events)) >= page.TotalCount {
+			return events, nil
+		}
+	}
+}
+
+// list returns a page of the events matching the filters, newest first.
+func (o *ListOptions) list(
+	ctx context.Context,
+	since *time.Time,
+	afterID uint64,
+	offset, limit int64,
+) (*v1.AuditEventList, error) {
+	// the follow mode pages through the new events until their total count
+	req := o.client.Get().AbsPath("/v1/audits").
+		Param("offset", strconv.FormatInt(offset, 10)).
+		Param("limit", strconv.FormatInt(limit, 10)).
+		Param("count", "true")
+
+	if selector := o.fieldSelector(); selector != "" {
+		req = req.Param("fieldSelector", selector)
+	}
+
+	if since != nil {
+		req = req.Param("since", since.UTC().Format(time.RFC3339))
+	}
+
+	if afterID > 0 {
+		req = req.Param("afterID", strconv.FormatUint(afterID, 10))
+	}
+
+	events := &v1.AuditEventList{}
+	if err := req.Do(ctx).Into(events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// fieldSelector returns the field selector of the filters of the events.
+func (o *ListOptions) fieldSelector() string {
+	var requirements []string
+	for _, r := range []struct{ field, value string }{
+		{"username", o.User},
+		{"verb", o.Verb},
+		{"resource", o.Resource},
+		{"resourceName", o.Name},
+	} {
+		if r.value != "" {
+			requirements = append(requirements, r.field+"="+r.value)
+		}
+	}
+
+	return strings.Join(requirements, ",")
+}
+
+// reverse reverses the events, which are listed newest first, to print them oldest first.
+func reverse(events []*v1.AuditEvent) {
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+}
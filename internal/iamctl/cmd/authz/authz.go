@@ -0,0 +1,157 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package authz provides functions to operate iam-authz-server.
+package authz
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	authzv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/authz/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/debug"
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+	"github.com/marmotedu/iam/pkg/sdk"
+	"github.com/marmotedu/iam/pkg/sdk/signer"
+	"github.com/marmotedu/iam/pkg/sdk/token"
+)
+
+var authzLong = templates.LongDesc(`
+	Authorization server commands.
+
+	This commands allow you to inspect and resync the secrets and policies cached by iam-authz-server,
+	and to replay the authorization requests it recorded against candidate policies.
+	The commands sign the requests with the configured secret-id and secret-key, either as bearer
+	tokens or, with --sign-requests, as HMAC signatures which can not be replayed. With
+	--refresh-token, the commands authenticate with the cached access tokens of the secret.`)
+
+// NewCmdAuthz returns new initialized instance of 'authz' sub command.
+func NewCmdAuthz(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "authz SUBCOMMAND",
+		DisableFlagsInUseLine: true,
+		Short:                 "Operate the iam-authz-server cache",
+		Long:                  authzLong,
+		Run:                   cmdutil.DefaultSubCommandRun(ioStreams.ErrOut),
+	}
+
+	cmd.AddCommand(NewCmdReload(f, ioStreams))
+	cmd.AddCommand(NewCmdStatus(f, ioStreams))
+	cmd.AddCommand(NewCmdReplay(f, ioStreams))
+
+	return cmd
+}
+
+// ClientOptions are the options of the client which talks to iam-authz-server.
+type ClientOptions struct {
+	Server       string
+	SignRequests bool
+	RefreshToken bool
+}
+
+// AddClientFlags adds the flags used to specify the iam-authz-server address and how the
+// requests are authenticated.
+func AddClientFlags(cmd *cobra.Command, o *ClientOptions) {
+	cmd.Flags().StringVar(&o.Server, "authz-server", o.Server,
+		"The address of iam-authz-server, defaults to the configured server address.")
+	cmd.Flags().BoolVar(&o.SignRequests, "sign-requests", o.SignRequests, ""+
+		"Sign the requests with the secret-id and secret-key instead of sending bearer tokens, "+
+		"so that the requests can not be replayed.")
+	cmd.Flags().BoolVar(&o.RefreshToken, "refresh-token", o.RefreshToken, ""+
+		"Authenticate with an access token obtained from the /oauth/token endpoint with the secret-id and "+
+		"secret-key, cached in $HOME/.iam/cache/tokens and refreshed before it expires or when it is rejected.")
+}
+
+// NewRESTClient returns a rest client which talks to the iam-authz-server at the given address.
+func NewRESTClient(f cmdutil.Factory, o ClientOptions) (rest.Interface, error) {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	authzConfig := *config
+	if o.Server != "" {
+		authzConfig.Host = o.Server
+	}
+
+	if !o.SignRequests && !o.RefreshToken {
+		client, err := authzv1.NewForConfig(&authzConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.RESTClient(), nil
+	}
+
+	if o.SignRequests && o.RefreshToken {
+		return nil, fmt.Errorf("--sign-requests and --refresh-token can not be used together")
+	}
+
+	if authzConfig.SecretID == "" || authzConfig.SecretKey == "" {
+		return nil, fmt.Errorf("--sign-requests and --refresh-token require the secret-id and secret-key to be set")
+	}
+
+	secretID, secretKey := authzConfig.SecretID, authzConfig.SecretKey
+	authzConfig.SecretID, authzConfig.SecretKey, authzConfig.BearerToken = "", "", ""
+	authzConfig.Username, authzConfig.Password = "", ""
+
+	client, err := authzv1.NewForConfig(&authzConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	restClient, ok := client.RESTClient().(*rest.RESTClient)
+	if !ok {
+		return nil, fmt.Errorf("unexpected rest client type %T", client.RESTClient())
+	}
+
+	if o.SignRequests {
+		sdk.Wrap(restClient, signer.Middleware(signer.New(secretID, secretKey)))
+
+		return restClient, nil
+	}
+
+	// the token endpoint is called with the tls configuration of the rest client
+	source := token.NewSource(restClient.Post().AbsPath("/oauth/token").URL().String(), secretID, secretKey)
+	source.HTTPClient = &http.Client{Transport: restClient.Client.Transport, Timeout: authzConfig.Timeout}
+	sdk.Wrap(restClient, token.Middleware(source))
+
+	return restClient, nil
+}
+
+// statusTable returns the table of the cache status.
+func statusTable(status *debug.CacheStatus) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Secrets"},
+		printers.Column{Name: "Policies"},
+		printers.Column{Name: "LastReload"},
+		printers.Column{Name: "LastSync"},
+		printers.Column{Name: "LastSequence"},
+	)
+	table.AddRow(
+		strconv.Itoa(status.Secrets),
+		strconv.Itoa(status.Policies),
+		formatTime(status.LastReloadTime),
+		formatTime(status.LastSyncTime),
+		strconv.FormatInt(status.LastSequence, 10),
+	)
+
+	return table
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return t.Format(time.RFC3339)
+}
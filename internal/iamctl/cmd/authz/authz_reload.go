@@ -0,0 +1,86 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authz
+
+import (
+	"context"
+
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/debug"
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+// ReloadOptions is an options struct to support reload subcommands.
+type ReloadOptions struct {
+	ClientOptions
+
+	client rest.Interface
+	genericclioptions.IOStreams
+}
+
+var reloadExample = templates.Examples(`
+		# Resync the cache of the iam-authz-server at 127.0.0.1:9090
+		iamctl authz reload --authz-server=http://127.0.0.1:9090`)
+
+// NewReloadOptions returns an initialized ReloadOptions instance.
+func NewReloadOptions(ioStreams genericclioptions.IOStreams) *ReloadOptions {
+	return &ReloadOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdReload returns new initialized instance of reload sub command.
+func NewCmdReload(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewReloadOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "reload",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{},
+		Short:                 "Resync the secrets and policies of iam-authz-server immediately",
+		TraverseChildren:      true,
+		Long:                  "Resync the secrets and policies of iam-authz-server immediately.",
+		Example:               reloadExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+		SuggestFor: []string{},
+	}
+
+	AddClientFlags(cmd, &o.ClientOptions)
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *ReloadOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	o.client, err = NewRESTClient(f, o.ClientOptions)
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *ReloadOptions) Validate(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Run executes a reload subcommand using the specified options.
+func (o *ReloadOptions) Run(args []string) error {
+	status := &debug.CacheStatus{}
+	if err := o.client.Post().AbsPath("/debug/cache/reload").Do(context.TODO()).Into(status); err != nil {
+		return err
+	}
+
+	return (&printers.TablePrinter{}).PrintObj(status, statusTable(status), o.Out)
+}
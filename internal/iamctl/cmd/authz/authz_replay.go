@@ -0,0 +1,204 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authz
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marmotedu/iam/internal/authzserver/authorization"
+	"github.com/marmotedu/iam/internal/authzserver/store/file"
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+const (
+	replayUsageStr = "replay -f FILENAME --policies FILENAME"
+)
+
+// ReplayOptions is an options struct to support replay subcommands.
+type ReplayOptions struct {
+	Filename   string
+	Policies   string
+	All        bool
+	PrintFlags *printers.PrintFlags
+
+	printer printers.ResourcePrinter
+	genericclioptions.IOStreams
+}
+
+var (
+	replayLong = templates.LongDesc(`Replay the recorded authorization requests against candidate policies.
+
+The requests are recorded by iam-authz-server with --record.enable, along with the decisions
+of the policies. The command evaluates them against the candidate policies and lists the
+requests whose decision changed, failing when any did, so that the regressions are caught
+before the policies are rolled out. The candidate policies file has the format of the policies
+file of the standalone mode.
+
+The recorded contexts are replayed as they were enriched and tagged. The groups of the subjects
+are not resolved, and the conditions on the redacted context keys, or on the time, may not
+decide as they did.`)
+
+	replayExample = templates.Examples(`
+		# List the recorded requests whose decision is changed by the candidate policies
+		iamctl authz replay -f authz-requests.jsonl --policies policies.yaml
+
+		# List the decisions of all the recorded requests
+		iamctl authz replay -f authz-requests.jsonl --policies policies.yaml --all`)
+)
+
+// NewReplayOptions returns an initialized ReplayOptions instance.
+func NewReplayOptions(ioStreams genericclioptions.IOStreams) *ReplayOptions {
+	return &ReplayOptions{
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdReplay returns new initialized instance of replay sub command.
+func NewCmdReplay(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewReplayOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   replayUsageStr,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{},
+		Short:                 "Replay the recorded authorization requests against candidate policies",
+		TraverseChildren:      true,
+		Long:                  replayLong,
+		Example:               replayExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+		SuggestFor: []string{},
+	}
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "The file of the recorded requests, - for the standard input.")
+	cmd.Flags().StringVar(&o.Policies, "policies", o.Policies, "The file of the candidate policies, in JSON or YAML.")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "List all the requests, not only those whose decision changed.")
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *ReplayOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if o.Filename == "" || o.Policies == "" {
+		return cmdutil.UsageErrorf(cmd, "expected '%s'.\n--filename and --policies are required for the replay command",
+			replayUsageStr)
+	}
+
+	var err error
+	o.printer, err = o.PrintFlags.ToPrinter()
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *ReplayOptions) Validate(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Run executes a replay subcommand using the specified options.
+func (o *ReplayOptions) Run(args []string) error {
+	records, err := o.readRecords()
+	if err != nil {
+		return err
+	}
+
+	policies, err := file.ReadPolicies(o.Policies)
+	if err != nil {
+		return err
+	}
+
+	results := authorization.Replay(records, policies)
+
+	listed := make([]*authorization.ReplayResult, 0, len(results))
+	changed := 0
+	for _, result := range results {
+		if result.Changed() {
+			changed++
+		}
+
+		if o.All || result.Changed() {
+			listed = append(listed, result)
+		}
+	}
+
+	if err := o.printer.PrintObj(listed, replayTable(listed), o.Out); err != nil {
+		return err
+	}
+
+	if changed > 0 {
+		return fmt.Errorf("%d of %d decisions changed", changed, len(results))
+	}
+
+	fmt.Fprintf(o.ErrOut, "%d decisions unchanged\n", len(results))
+
+	return nil
+}
+
+func (o *ReplayOptions) readRecords() ([]*authorization.Record, error) {
+	if o.Filename == "-" {
+		return authorization.ReadRecords(o.In)
+	}
+
+	reader, err := os.Open(o.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return authorization.ReadRecords(reader)
+}
+
+// replayTable returns the table of the replayed decisions.
+func replayTable(results []*authorization.ReplayResult) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Subject"},
+		printers.Column{Name: "Action"},
+		printers.Column{Name: "Resource"},
+		printers.Column{Name: "Recorded"},
+		printers.Column{Name: "Replayed"},
+		printers.Column{Name: "Username", Wide: true},
+		printers.Column{Name: "Deciders", Wide: true},
+		printers.Column{Name: "Reason", Wide: true},
+		printers.Column{Name: "Time", Wide: true},
+	)
+
+	for _, result := range results {
+		request := result.Record.Request
+		username, _ := request.Context["username"].(string)
+		table.AddRow(
+			request.Subject,
+			request.Action,
+			request.Resource,
+			decision(result.Record.Allowed),
+			decision(result.Response.Allowed),
+			username,
+			strings.Join(result.Record.Deciders, ","),
+			result.Response.Reason,
+			formatTime(result.Record.Time),
+		)
+	}
+
+	return table
+}
+
+func decision(allowed bool) string {
+	if allowed {
+		return "allow"
+	}
+
+	return "deny"
+}
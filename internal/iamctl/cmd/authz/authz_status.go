@@ -0,0 +1,95 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authz
+
+import (
+	"context"
+
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/debug"
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+// StatusOptions is an options struct to support status subcommands.
+type StatusOptions struct {
+	ClientOptions
+	PrintFlags *printers.PrintFlags
+
+	printer printers.ResourcePrinter
+	client  rest.Interface
+	genericclioptions.IOStreams
+}
+
+var statusExample = templates.Examples(`
+		# Display the cache status of the iam-authz-server at 127.0.0.1:9090
+		iamctl authz status --authz-server=http://127.0.0.1:9090`)
+
+// NewStatusOptions returns an initialized StatusOptions instance.
+func NewStatusOptions(ioStreams genericclioptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdStatus returns new initialized instance of status sub command.
+func NewCmdStatus(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewStatusOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "status",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{},
+		Short:                 "Display the cache status of iam-authz-server",
+		TraverseChildren:      true,
+		Long:                  "Display the cache status of iam-authz-server.",
+		Example:               statusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+		SuggestFor: []string{},
+	}
+
+	AddClientFlags(cmd, &o.ClientOptions)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *StatusOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = NewRESTClient(f, o.ClientOptions)
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *StatusOptions) Validate(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Run executes a status subcommand using the specified options.
+func (o *StatusOptions) Run(args []string) error {
+	status := &debug.CacheStatus{}
+	if err := o.client.Get().AbsPath("/debug/cache/status").Do(context.TODO()).Into(status); err != nil {
+		return err
+	}
+
+	return o.printer.PrintObj(status, statusTable(status), o.Out)
+}
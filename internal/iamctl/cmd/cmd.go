@@ -14,19 +14,25 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/marmotedu/iam/internal/iamctl/cmd/audit"
+	"github.com/marmotedu/iam/internal/iamctl/cmd/authz"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/color"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/completion"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/info"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/jwt"
+	"github.com/marmotedu/iam/internal/iamctl/cmd/login"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/new"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/options"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/policy"
+	"github.com/marmotedu/iam/internal/iamctl/cmd/proxy"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/secret"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/set"
+	"github.com/marmotedu/iam/internal/iamctl/cmd/top"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/user"
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/validate"
 	"github.com/marmotedu/iam/internal/iamctl/cmd/version"
+	"github.com/marmotedu/iam/internal/iamctl/cmd/whoami"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
 	genericapiserver "github.com/marmotedu/iam/internal/pkg/server"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
@@ -76,6 +82,7 @@ func NewIAMCtlCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	_ = viper.BindPFlags(cmds.PersistentFlags())
 	cobra.OnInitialize(func() {
 		genericapiserver.LoadConfig(viper.GetString(genericclioptions.FlagIAMConfig), "iamctl")
+		login.ClearExpiredToken(err)
 	})
 	cmds.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
@@ -102,17 +109,24 @@ func NewIAMCtlCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 				user.NewCmdUser(f, ioStreams),
 				secret.NewCmdSecret(f, ioStreams),
 				policy.NewCmdPolicy(f, ioStreams),
+				audit.NewCmdAudit(f, ioStreams),
 			},
 		},
 		{
 			Message: "Troubleshooting and Debugging Commands:",
 			Commands: []*cobra.Command{
 				validate.NewCmdValidate(f, ioStreams),
+				authz.NewCmdAuthz(f, ioStreams),
+				top.NewCmdTop(f, ioStreams),
+				proxy.NewCmdProxy(f, ioStreams),
 			},
 		},
 		{
 			Message: "Settings Commands:",
 			Commands: []*cobra.Command{
+				login.NewCmdLogin(f, ioStreams),
+				login.NewCmdLogout(f, ioStreams),
+				whoami.NewCmdWhoAmI(f, ioStreams),
 				set.NewCmdSet(f, ioStreams),
 				completion.NewCmdCompletion(ioStreams.Out, ""),
 			},
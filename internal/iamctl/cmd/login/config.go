@@ -0,0 +1,160 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/util/homedir"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	genericapiserver "github.com/marmotedu/iam/internal/pkg/server"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+// FlagTokenExpiry is the configuration item of the expiry time of the token, in RFC3339 format.
+const FlagTokenExpiry = "user.token-expiry"
+
+// ClearExpiredToken drops the token of the configuration when it has expired, so that the
+// commands fail with a hint to log in again instead of an authentication error.
+func ClearExpiredToken(w io.Writer) {
+	token, expiry := viper.GetString(genericclioptions.FlagBearerToken), viper.GetString(FlagTokenExpiry)
+	if token == "" || expiry == "" {
+		return
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, expiry)
+	if err != nil || time.Now().Before(expiredAt) {
+		return
+	}
+
+	viper.Set(genericclioptions.FlagBearerToken, "")
+	fmt.Fprintf(w, "WARNING: the token expired at %s, run 'iamctl login' to get a new one\n", expiry)
+}
+
+// configFile returns the configuration file the login is stored in, that is the loaded
+// configuration file, or $HOME/.iam/iamctl.yaml.
+func configFile() string {
+	if file := viper.ConfigFileUsed(); file != "" {
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+
+	return filepath.Join(homedir.HomeDir(), genericapiserver.RecommendedHomeDir, "iamctl.yaml")
+}
+
+// updateConfig sets and removes the configuration items of the file, preserving its
+// comments. The file is readable by the owner only as it holds the credentials.
+func updateConfig(file string, set map[string]string, unset ...string) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(file)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+	}
+
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse %s: the configuration is not a mapping", file)
+	}
+
+	for key, value := range set {
+		setNode(root, strings.Split(key, "."), value)
+	}
+	for _, key := range unset {
+		unsetNode(root, strings.Split(key, "."))
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(file, 0o600)
+}
+
+// lookupNode returns the value of the key of a mapping node, or nil.
+func lookupNode(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func setNode(mapping *yaml.Node, path []string, value string) {
+	node := lookupNode(mapping, path[0])
+	if node == nil {
+		node = &yaml.Node{Kind: yaml.ScalarNode}
+		if len(path) > 1 {
+			node.Kind = yaml.MappingNode
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}, node)
+	}
+
+	if len(path) > 1 {
+		if node.Kind != yaml.MappingNode {
+			*node = yaml.Node{Kind: yaml.MappingNode}
+		}
+		setNode(node, path[1:], value)
+
+		return
+	}
+
+	*node = yaml.Node{Kind: yaml.ScalarNode, Value: value, LineComment: node.LineComment}
+}
+
+func unsetNode(mapping *yaml.Node, path []string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != path[0] {
+			continue
+		}
+
+		if len(path) > 1 {
+			if mapping.Content[i+1].Kind == yaml.MappingNode {
+				unsetNode(mapping.Content[i+1], path[1:])
+			}
+
+			return
+		}
+
+		// the comments above the key are kept above the next one
+		if comment := mapping.Content[i].HeadComment; comment != "" && i+2 < len(mapping.Content) {
+			next := mapping.Content[i+2]
+			next.HeadComment = strings.TrimSpace(comment + "\n" + next.HeadComment)
+		}
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+
+		return
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package login logs in and out of the iam platform, the token is stored in the iamctl
+// configuration file.
+package login
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	apiclientv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+	restclient "github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+const (
+	loginUsageStr = "login [USERNAME]"
+)
+
+// LoginOptions is an options struct to support login command.
+type LoginOptions struct {
+	Username      string
+	PasswordStdin bool
+	Connector     string
+
+	File   string
+	client restclient.Interface
+	genericclioptions.IOStreams
+}
+
+// loginResponse is the response of the /login endpoint.
+type loginResponse struct {
+	Token  string `json:"token"`
+	Expire string `json:"expire"`
+}
+
+var (
+	loginLong = templates.LongDesc(`Log in to the iam platform.
+
+The password is prompted for, or read from the standard input with --password-stdin, so that
+it is never written to the shell history or the configuration file. The token returned by the
+iam-apiserver is stored with its expiry time in the iamctl configuration file, which is made
+readable by the owner only, and is used by the next commands until it expires.
+
+With --connector, the login is done in the browser on the identity provider of the connector,
+e.g. an OpenID Connect provider, and the token shown at the end of the login is pasted.`)
+
+	loginExample = templates.Examples(`
+		# Log in as the user in the configuration file, prompting for the password
+		iamctl login
+
+		# Log in as admin, reading the password from a file
+		cat password.txt | iamctl login admin --password-stdin
+
+		# Log in with the github connector
+		iamctl login --connector github`)
+)
+
+// NewLoginOptions returns an initialized LoginOptions instance.
+func NewLoginOptions(ioStreams genericclioptions.IOStreams) *LoginOptions {
+	return &LoginOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdLogin returns new initialized instance of login sub command.
+func NewCmdLogin(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewLoginOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   loginUsageStr,
+		DisableFlagsInUseLine: true,
+		Short:                 "Log in to the iam platform",
+		Long:                  loginLong,
+		Example:               loginExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.PasswordStdin, "password-stdin", o.PasswordStdin, "Read the password from the standard input.")
+	cmd.Flags().StringVar(&o.Connector, "connector", o.Connector, "Log in with the identity provider of the connector.")
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *LoginOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmdutil.UsageErrorf(cmd, "expected '%s'", loginUsageStr)
+	}
+
+	o.Username = viper.GetString(genericclioptions.FlagUsername)
+	if len(args) == 1 {
+		o.Username = args[0]
+	}
+	o.File = configFile()
+
+	var err error
+	o.client, err = anonymousClient(f)
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *LoginOptions) Validate(cmd *cobra.Command, args []string) error {
+	if o.Connector != "" && (o.PasswordStdin || len(args) != 0) {
+		return cmdutil.UsageErrorf(cmd, "--connector can not be used with a username or --password-stdin")
+	}
+
+	if viper.GetString(genericclioptions.FlagSecretID) != "" || viper.GetString(genericclioptions.FlagSecretKey) != "" {
+		return fmt.Errorf("the secret-id and secret-key are set in %s, remove them to log in with a token", o.File)
+	}
+
+	return nil
+}
+
+// Run executes a login command using the specified options.
+func (o *LoginOptions) Run(args []string) error {
+	var (
+		resp *loginResponse
+		err  error
+	)
+
+	if o.Connector != "" {
+		resp, err = o.connectorLogin()
+	} else {
+		resp, err = o.passwordLogin()
+	}
+	if err != nil {
+		return err
+	}
+
+	set := map[string]string{genericclioptions.FlagBearerToken: resp.Token}
+	if resp.Expire != "" {
+		set[FlagTokenExpiry] = resp.Expire
+	}
+
+	// the token replaces the username and password, they can not be used together
+	if err := updateConfig(o.File, set, genericclioptions.FlagUsername, genericclioptions.FlagPassword); err != nil {
+		return err
+	}
+
+	if resp.Expire != "" {
+		fmt.Fprintf(o.Out, "Login succeeded, the token is stored in %s and expires at %s\n", o.File, resp.Expire)
+	} else {
+		fmt.Fprintf(o.Out, "Login succeeded, the token is stored in %s\n", o.File)
+	}
+
+	return nil
+}
+
+// passwordLogin logs in with the username and password.
+func (o *LoginOptions) passwordLogin() (*loginResponse, error) {
+	reader := bufio.NewReader(o.In)
+
+	username := o.Username
+	if username == "" {
+		if o.PasswordStdin {
+			return nil, fmt.Errorf("the username is required with --password-stdin")
+		}
+
+		fmt.Fprint(o.ErrOut, "Username: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("failed to read the username: %w", err)
+		}
+		username = strings.TrimSpace(line)
+	}
+
+	var (
+		password string
+		err      error
+	)
+	if o.PasswordStdin {
+		password, err = reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read the password: %w", err)
+		}
+		password = strings.TrimRight(password, "\r\n")
+	} else {
+		password, err = o.readSecret("Password: ")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("the username and password are required")
+	}
+
+	var resp loginResponse
+	err = o.client.Post().
+		AbsPath("/login").
+		SetHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password))).
+		Do(context.TODO()).
+		Into(&resp)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// connectorLogin logs in on the identity provider of the connector in the browser.
+func (o *LoginOptions) connectorLogin() (*loginResponse, error) {
+	url := o.client.Get().AbsPath("/connectors", o.Connector, "login").URL().String()
+	fmt.Fprintf(o.ErrOut, "Open the following url in a browser and log in:\n\n    %s\n\n", url)
+
+	token, err := o.readSecret("Paste the token shown after the login: ")
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("the token is required")
+	}
+
+	resp := &loginResponse{Token: token}
+	if exp, err := tokenExpiry(token); err == nil {
+		resp.Expire = exp.Format(time.RFC3339)
+	}
+
+	return resp, nil
+}
+
+// readSecret prompts for a secret, which is not echoed when the input is a terminal.
+func (o *LoginOptions) readSecret(prompt string) (string, error) {
+	fmt.Fprint(o.ErrOut, prompt)
+
+	file, ok := o.In.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return "", fmt.Errorf("the standard input is not a terminal, use --password-stdin")
+	}
+
+	secret, err := term.ReadPassword(int(file.Fd()))
+	fmt.Fprintln(o.ErrOut)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// anonymousClient returns a rest client which talks to the iam-apiserver without the
+// credentials of the configuration.
+func anonymousClient(f cmdutil.Factory) (restclient.Interface, error) {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	anonymousConfig := *config
+	anonymousConfig.SecretID, anonymousConfig.SecretKey, anonymousConfig.BearerToken = "", "", ""
+	anonymousConfig.Username, anonymousConfig.Password = "", ""
+
+	client, err := apiclientv1.NewForConfig(&anonymousConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.RESTClient(), nil
+}
+
+// tokenExpiry returns the expiry time of the exp claim of the token, the token is not
+// verified as it is only used to tell when to log in again.
+func tokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("malformed token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, fmt.Errorf("token has no expiry")
+	}
+
+	return time.Unix(claims.Exp, 0), nil
+}
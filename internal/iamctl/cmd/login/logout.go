@@ -0,0 +1,109 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	restclient "github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+// LogoutOptions is an options struct to support logout command.
+type LogoutOptions struct {
+	Token string
+
+	File   string
+	client restclient.Interface
+	genericclioptions.IOStreams
+}
+
+var (
+	logoutLong = templates.LongDesc(`Log out of the iam platform.
+
+The token of the iamctl configuration file is revoked by the iam-apiserver, and removed from
+the configuration file. The token is removed even if the iam-apiserver can not be reached.`)
+
+	logoutExample = templates.Examples(`
+		# Log out, revoking the token of the configuration file
+		iamctl logout`)
+)
+
+// NewLogoutOptions returns an initialized LogoutOptions instance.
+func NewLogoutOptions(ioStreams genericclioptions.IOStreams) *LogoutOptions {
+	return &LogoutOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdLogout returns new initialized instance of logout sub command.
+func NewCmdLogout(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewLogoutOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "logout",
+		DisableFlagsInUseLine: true,
+		Short:                 "Log out of the iam platform",
+		Long:                  logoutLong,
+		Example:               logoutExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+	}
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *LogoutOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected args: %v", args)
+	}
+
+	o.Token = viper.GetString(genericclioptions.FlagBearerToken)
+	o.File = configFile()
+
+	var err error
+	o.client, err = anonymousClient(f)
+
+	return err
+}
+
+// Run executes a logout command using the specified options.
+func (o *LogoutOptions) Run(args []string) error {
+	// an expired token has been dropped when the configuration was loaded, and needs no revocation
+	if o.Token != "" {
+		err := o.client.Post().
+			AbsPath("/logout").
+			SetHeader("Authorization", "Bearer "+o.Token).
+			Do(context.TODO()).
+			Error()
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "WARNING: failed to revoke the token: %s\n", err.Error())
+		}
+	}
+
+	if _, err := os.Stat(o.File); os.IsNotExist(err) {
+		fmt.Fprintln(o.Out, "Not logged in")
+
+		return nil
+	}
+
+	if err := updateConfig(o.File, nil, genericclioptions.FlagBearerToken, FlagTokenExpiry); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Logout succeeded, the token is removed from %s\n", o.File)
+
+	return nil
+}
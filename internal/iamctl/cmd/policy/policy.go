@@ -6,10 +6,17 @@
 package policy
 
 import (
+	"sort"
+	"strings"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	// register the iam specific conditions.
+	_ "github.com/marmotedu/iam/internal/pkg/condition"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
 
@@ -33,6 +40,44 @@ func NewCmdPolicy(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cob
 	cmd.AddCommand(NewCmdList(f, ioStreams))
 	cmd.AddCommand(NewCmdDelete(f, ioStreams))
 	cmd.AddCommand(NewCmdUpdate(f, ioStreams))
+	cmd.AddCommand(NewCmdValidate(f, ioStreams))
 
 	return cmd
 }
+
+// policyTable returns the table of the policies.
+func policyTable(policies ...*v1.Policy) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Name"},
+		printers.Column{Name: "Effect"},
+		printers.Column{Name: "Subjects"},
+		printers.Column{Name: "Actions"},
+		printers.Column{Name: "Resources"},
+		printers.Column{Name: "Created"},
+		printers.Column{Name: "Username", Wide: true},
+		printers.Column{Name: "Conditions", Wide: true},
+		printers.Column{Name: "Description", Wide: true},
+	)
+
+	for _, policy := range policies {
+		conditions := make([]string, 0, len(policy.Policy.Conditions))
+		for key := range policy.Policy.Conditions {
+			conditions = append(conditions, key)
+		}
+		sort.Strings(conditions)
+
+		table.AddRow(
+			policy.Name,
+			policy.Policy.Effect,
+			strings.Join(policy.Policy.Subjects, ","),
+			strings.Join(policy.Policy.Actions, ","),
+			strings.Join(policy.Policy.Resources, ","),
+			policy.CreatedAt.Format("2006-01-02 15:04:05"),
+			policy.Username,
+			strings.Join(conditions, ","),
+			policy.Policy.Description,
+		)
+	}
+
+	return table
+}
@@ -14,6 +14,7 @@ import (
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
 
@@ -59,6 +60,7 @@ func NewCmdDelete(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cob
 		TraverseChildren:      true,
 		Long:                  "Delete a authorization policy resource.",
 		Example:               deleteExample,
+		ValidArgsFunction:     cmdutil.ResourceNameCompletionFunc(f, iamv1.CompletionKindPolicy),
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
 			cmdutil.CheckErr(o.Validate(cmd, args))
@@ -5,18 +5,17 @@
 package policy
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 
-	"github.com/fatih/color"
-	"github.com/marmotedu/component-base/pkg/json"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
 
@@ -28,6 +27,9 @@ const (
 type GetOptions struct {
 	Name string
 
+	PrintFlags *printers.PrintFlags
+
+	printer   printers.ResourcePrinter
 	iamclient iam.IamInterface
 	genericclioptions.IOStreams
 }
@@ -35,7 +37,10 @@ type GetOptions struct {
 var (
 	getExample = templates.Examples(`
 		# Display a policy resource
-		iamctl policy get foo`)
+		iamctl policy get foo
+
+		# Display the whole policy foo in json format
+		iamctl policy get foo -o json`)
 
 	getUsageErrStr = fmt.Sprintf("expected '%s'.\nPOLICY_NAME is required arguments for the get command", getUsageStr)
 )
@@ -43,7 +48,8 @@ var (
 // NewGetOptions returns an initialized GetOptions instance.
 func NewGetOptions(ioStreams genericclioptions.IOStreams) *GetOptions {
 	return &GetOptions{
-		IOStreams: ioStreams,
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
 	}
 }
 
@@ -59,6 +65,7 @@ func NewCmdGet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.
 		TraverseChildren:      true,
 		Long:                  "Display a authorization policy resource.",
 		Example:               getExample,
+		ValidArgsFunction:     cmdutil.ResourceNameCompletionFunc(f, iamv1.CompletionKindPolicy),
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
 			cmdutil.CheckErr(o.Validate(cmd, args))
@@ -67,6 +74,8 @@ func NewCmdGet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.
 		SuggestFor: []string{},
 	}
 
+	o.PrintFlags.AddFlags(cmd)
+
 	return cmd
 }
 
@@ -80,6 +89,11 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 
 	o.Name = args[0]
 
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
 	o.iamclient, err = f.IAMClient()
 	if err != nil {
 		return err
@@ -100,14 +114,5 @@ func (o *GetOptions) Run(args []string) error {
 		return err
 	}
 
-	bf := bytes.NewBuffer([]byte{})
-	jsonEncoder := json.NewEncoder(bf)
-	jsonEncoder.SetEscapeHTML(false)
-	if err := jsonEncoder.Encode(policy.Policy); err != nil {
-		return err
-	}
-
-	fmt.Fprintf(o.Out, "%12s %s\n", color.RedString(policy.Name+":"), bf.String())
-
-	return nil
+	return o.printer.PrintObj(policy, policyTable(policy), o.Out)
 }
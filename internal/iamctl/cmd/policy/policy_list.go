@@ -5,17 +5,14 @@
 package policy
 
 import (
-	"bytes"
 	"context"
-	"fmt"
 
-	"github.com/fatih/color"
-	"github.com/marmotedu/component-base/pkg/json"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
@@ -29,6 +26,9 @@ type ListOptions struct {
 	Offset int64
 	Limit  int64
 
+	PrintFlags *printers.PrintFlags
+
+	printer   printers.ResourcePrinter
 	iamclient iam.IamInterface
 	genericclioptions.IOStreams
 }
@@ -38,14 +38,18 @@ var listExample = templates.Examples(`
 		iamctl poicy list
 
 		# Display all policy resources with offset and limit
-		iamctl policy list --offset=0 --limit=10`)
+		iamctl policy list --offset=0 --limit=10
+
+		# Display all policy resources with their conditions and description
+		iamctl policy list -o wide`)
 
 // NewListOptions returns an initialized ListOptions instance.
 func NewListOptions(ioStreams genericclioptions.IOStreams) *ListOptions {
 	return &ListOptions{
-		Offset:    0,
-		Limit:     defaultLimit,
-		IOStreams: ioStreams,
+		Offset:     0,
+		Limit:      defaultLimit,
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
 	}
 }
 
@@ -69,8 +73,9 @@ func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 		SuggestFor: []string{},
 	}
 
-	cmd.Flags().Int64VarP(&o.Offset, "offset", "o", o.Offset, "Specify the offset of the first row to be returned.")
+	cmd.Flags().Int64Var(&o.Offset, "offset", o.Offset, "Specify the offset of the first row to be returned.")
 	cmd.Flags().Int64VarP(&o.Limit, "limit", "l", o.Limit, "Specify the amount records to be returned.")
+	o.PrintFlags.AddFlags(cmd)
 
 	return cmd
 }
@@ -79,6 +84,11 @@ func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 func (o *ListOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
 	var err error
 
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
 	o.iamclient, err = f.IAMClient()
 	if err != nil {
 		return err
@@ -102,16 +112,5 @@ func (o *ListOptions) Run(args []string) error {
 		return err
 	}
 
-	for _, pol := range policies.Items {
-		bf := bytes.NewBuffer([]byte{})
-		jsonEncoder := json.NewEncoder(bf)
-		jsonEncoder.SetEscapeHTML(false)
-		if err := jsonEncoder.Encode(pol.Policy); err != nil {
-			return err
-		}
-
-		fmt.Fprintf(o.Out, "%12s %s\n", color.RedString(pol.Name+":"), bf.String())
-	}
-
-	return nil
+	return o.printer.PrintObj(policies, policyTable(policies.Items...), o.Out)
 }
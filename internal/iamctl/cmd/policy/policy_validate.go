@@ -0,0 +1,164 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	restclient "github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/internal/pkg/policylint"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+const (
+	validateUsageStr = "validate -f FILENAME"
+)
+
+// ValidateOptions is an options struct to support validate subcommands.
+type ValidateOptions struct {
+	Filenames []string
+	Remote    bool
+	Strict    bool
+
+	client *restclient.RESTClient
+	genericclioptions.IOStreams
+}
+
+var (
+	validateLong = templates.LongDesc(`Validate authorization policies without creating them.
+
+The policy files have the format of the policy create API, e.g. {"metadata":{"name":"foo"},"policy":{...}}.
+The policies are checked for syntax errors, unknown conditions, invalid condition options and
+templates, with the linting of iam-apiserver, and for the subjects, actions and resources wildcards
+matching everything. The errors make the command fail, and the warnings too with --strict, which
+suits the CI pipelines of the policies managed as code.`)
+
+	validateExample = templates.Examples(`
+		# Validate a policy locally
+		iamctl policy validate -f policy.json
+
+		# Validate policies with the linting of the server, failing on the warnings too
+		iamctl policy validate -f foo.json -f bar.json --remote --strict
+
+		# Validate a policy read from the standard input
+		cat policy.json | iamctl policy validate -f -`)
+)
+
+// NewValidateOptions returns an initialized ValidateOptions instance.
+func NewValidateOptions(ioStreams genericclioptions.IOStreams) *ValidateOptions {
+	return &ValidateOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdValidate returns new initialized instance of validate sub command.
+func NewCmdValidate(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewValidateOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   validateUsageStr,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"lint"},
+		Short:                 "Validate authorization policies without creating them",
+		TraverseChildren:      true,
+		Long:                  validateLong,
+		Example:               validateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+		SuggestFor: []string{},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "The policy files to validate, - for the standard input.")
+	cmd.Flags().BoolVar(&o.Remote, "remote", o.Remote, "Validate the policies with the validate endpoint of iam-apiserver.")
+	cmd.Flags().BoolVar(&o.Strict, "strict", o.Strict, "Fail on the warnings too.")
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *ValidateOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(o.Filenames) == 0 {
+		return cmdutil.UsageErrorf(cmd, "expected '%s'.\n--filename is required for the validate command", validateUsageStr)
+	}
+
+	if !o.Remote {
+		return nil
+	}
+
+	var err error
+	o.client, err = f.RESTClient()
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *ValidateOptions) Validate(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Run executes a validate subcommand using the specified options.
+func (o *ValidateOptions) Run(args []string) error {
+	var failed int
+	for _, filename := range o.Filenames {
+		result, err := o.lint(filename)
+		if err != nil {
+			return err
+		}
+
+		for _, finding := range result.Findings {
+			fmt.Fprintf(o.Out, "%s: %s\n", filename, finding)
+		}
+
+		if !result.Valid || (o.Strict && len(result.Findings) > 0) {
+			failed++
+			fmt.Fprintf(o.Out, "%s: invalid\n", filename)
+
+			continue
+		}
+
+		fmt.Fprintf(o.Out, "%s: valid\n", filename)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d policies are invalid", failed, len(o.Filenames))
+	}
+
+	return nil
+}
+
+// lint lints the policy of the file, locally or with iam-apiserver.
+func (o *ValidateOptions) lint(filename string) (policylint.Result, error) {
+	var (
+		data   []byte
+		err    error
+		result policylint.Result
+	)
+	if filename == "-" {
+		data, err = io.ReadAll(o.In)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if !o.Remote {
+		return policylint.Lint(data), nil
+	}
+
+	err = o.client.Post().AbsPath("/v1/policy-validations").Body(string(data)).Do(context.TODO()).Into(&result)
+
+	return result, err
+}
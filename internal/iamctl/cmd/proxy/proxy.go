@@ -0,0 +1,225 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package proxy runs a local proxy to iam-apiserver which authenticates the requests.
+package proxy
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/auth"
+	"github.com/marmotedu/component-base/pkg/scheme"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/interrupt"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+const (
+	defaultPort = 8001
+
+	// shutdownTimeout is how long the in-flight requests are waited for when interrupted.
+	shutdownTimeout = 5 * time.Second
+)
+
+// ProxyOptions is an options struct to support proxy command.
+type ProxyOptions struct {
+	Port    int
+	Address string
+
+	target    *url.URL
+	transport http.RoundTripper
+	authorize func(req *http.Request)
+	genericclioptions.IOStreams
+}
+
+var (
+	proxyLong = templates.LongDesc(`Run a proxy to iam-apiserver on a local address.
+
+The requests sent to the proxy are forwarded to the configured iam-apiserver, with the
+Authorization header of the configured credentials and over the configured TLS settings, so
+that the apis can be explored with curl or a browser without handling the tokens manually.
+
+The proxy serves anyone who can connect to it with the identity of the credentials, keep it
+on the loopback address unless you know what you are doing.`)
+
+	proxyExample = templates.Examples(`
+		# Run a proxy to iam-apiserver on port 8001
+		iamctl proxy
+
+		# Run a proxy on port 8011, then list the users through it
+		iamctl proxy --port=8011
+		curl http://127.0.0.1:8011/v1/users
+
+		# Run a proxy on an arbitrary local port, the chosen port is printed
+		iamctl proxy --port=0`)
+)
+
+// NewProxyOptions returns an initialized ProxyOptions instance.
+func NewProxyOptions(ioStreams genericclioptions.IOStreams) *ProxyOptions {
+	return &ProxyOptions{
+		Port:      defaultPort,
+		Address:   "127.0.0.1",
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdProxy returns new initialized instance of proxy sub command.
+func NewCmdProxy(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewProxyOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "proxy [--port=PORT] [--address=ADDRESS]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Run a proxy to iam-apiserver",
+		Long:                  proxyLong,
+		Example:               proxyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.Port, "port", "p", o.Port, "The port on which to run the proxy. Set to 0 to pick a random port.")
+	cmd.Flags().StringVar(&o.Address, "address", o.Address, "The IP address on which to serve on.")
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *ProxyOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	// the scheme and the port of the server default as the ones of the rest clients
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return err
+	}
+
+	o.target, _, err = rest.DefaultServerURL(config.Host, "", scheme.GroupVersion{Group: "iam.api"}, tlsConfig != nil)
+	if err != nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	o.transport = transport
+
+	o.authorize, err = authorizer(config)
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *ProxyOptions) Validate(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected args: %v", args)
+	}
+
+	if o.Port < 0 || o.Port > 65535 {
+		return cmdutil.UsageErrorf(cmd, "--port must be between 0 and 65535, got %d", o.Port)
+	}
+
+	return nil
+}
+
+// Run executes a proxy command using the specified options.
+func (o *ProxyOptions) Run(args []string) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(o.Address, strconv.Itoa(o.Port)))
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Handler: o.newHandler()}
+
+	fmt.Fprintf(o.Out, "Starting to serve on %s, forwarding to %s\n", listener.Addr().String(), o.target.String())
+
+	// an interrupt stops the proxy after the in-flight requests are done, instead of exiting
+	// with an error
+	shutdown := func(os.Signal) {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		_ = server.Shutdown(ctx)
+	}
+
+	return interrupt.New(shutdown).Run(func() error {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// newHandler returns the handler which forwards the requests to the server, replacing their
+// credentials with the configured ones.
+func (o *ProxyOptions) newHandler() http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(o.target)
+	proxy.Transport = o.transport
+
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = o.target.Host
+		req.Header.Del("Cookie")
+		req.Header.Del("Authorization")
+		o.authorize(req)
+	}
+
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		fmt.Fprintf(o.ErrOut, "WARNING: failed to proxy %s %s: %s\n", req.Method, req.URL.Path, err.Error())
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	return proxy
+}
+
+// authorizer returns the function which sets the Authorization header of the credentials in
+// the config to a request, the same way as the rest clients do.
+func authorizer(config *rest.Config) (func(req *http.Request), error) {
+	hasBasicAuth := config.Username != ""
+	hasTokenAuth := config.BearerToken != ""
+	hasKeyAuth := config.SecretID != "" && config.SecretKey != ""
+
+	switch {
+	case hasBasicAuth && (hasTokenAuth || hasKeyAuth), hasTokenAuth && hasKeyAuth:
+		return nil, fmt.Errorf("username/password or bearer token or secretID/secretKey may be set, " +
+			"but should use only one of them")
+	case hasTokenAuth:
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+config.BearerToken)
+		}, nil
+	case hasKeyAuth:
+		// the signed tokens expire in a minute, a new one is signed for every request
+		return func(req *http.Request) {
+			token := auth.Sign(config.SecretID, config.SecretKey, "marmotedu-sdk-go", "iam.api.marmotedu.com")
+			req.Header.Set("Authorization", "Bearer "+token)
+		}, nil
+	case hasBasicAuth:
+		credentials := base64.StdEncoding.EncodeToString([]byte(config.Username + ":" + config.Password))
+
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Basic "+credentials)
+		}, nil
+	default:
+		return nil, fmt.Errorf("no credentials are configured, run `iamctl login` or set them in the iamconfig file")
+	}
+}
@@ -6,10 +6,13 @@
 package secret
 
 import (
-	"github.com/olekukonko/tablewriter"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
@@ -38,14 +41,29 @@ func NewCmdSecret(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cob
 	return cmd
 }
 
-// setHeader set headers for secret commands.
-func setHeader(table *tablewriter.Table) *tablewriter.Table {
-	table.SetHeader([]string{"Name", "SecretID", "SecretKey", "Expires", "Created"})
-	table.SetHeaderColor(tablewriter.Colors{tablewriter.FgGreenColor},
-		tablewriter.Colors{tablewriter.FgRedColor},
-		tablewriter.Colors{tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.FgMagentaColor},
-		tablewriter.Colors{tablewriter.FgGreenColor})
+// secretTable returns the table of the secrets.
+func secretTable(secrets ...*v1.Secret) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Name"},
+		printers.Column{Name: "SecretID"},
+		printers.Column{Name: "SecretKey"},
+		printers.Column{Name: "Expires"},
+		printers.Column{Name: "Created"},
+		printers.Column{Name: "Username", Wide: true},
+		printers.Column{Name: "Description", Wide: true},
+	)
+
+	for _, secret := range secrets {
+		table.AddRow(
+			secret.Name,
+			secret.SecretID,
+			secret.SecretKey,
+			time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05"),
+			secret.CreatedAt.Format("2006-01-02 15:04:05"),
+			secret.Username,
+			secret.Description,
+		)
+	}
 
 	return table
 }
@@ -7,15 +7,15 @@ package secret
 import (
 	"context"
 	"fmt"
-	"time"
 
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
 
@@ -27,6 +27,9 @@ const (
 type GetOptions struct {
 	Name string
 
+	PrintFlags *printers.PrintFlags
+
+	printer   printers.ResourcePrinter
 	iamclient iam.IamInterface
 
 	genericclioptions.IOStreams
@@ -43,7 +46,8 @@ var (
 // NewGetOptions returns an initialized GetOptions instance.
 func NewGetOptions(ioStreams genericclioptions.IOStreams) *GetOptions {
 	return &GetOptions{
-		IOStreams: ioStreams,
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
 	}
 }
 
@@ -59,6 +63,7 @@ func NewCmdGet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.
 		TraverseChildren:      true,
 		Long:                  "Display a secret resource.",
 		Example:               getExample,
+		ValidArgsFunction:     cmdutil.ResourceNameCompletionFunc(f, iamv1.CompletionKindSecret),
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
 			cmdutil.CheckErr(o.Validate(cmd, args))
@@ -67,6 +72,8 @@ func NewCmdGet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.
 		SuggestFor: []string{},
 	}
 
+	o.PrintFlags.AddFlags(cmd)
+
 	return cmd
 }
 
@@ -79,6 +86,11 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 
 	o.Name = args[0]
 
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
 	o.iamclient, err = f.IAMClient()
 	if err != nil {
 		return err
@@ -99,22 +111,5 @@ func (o *GetOptions) Run(args []string) error {
 		return err
 	}
 
-	table := tablewriter.NewWriter(o.Out)
-
-	data := [][]string{
-		{
-			secret.Name,
-			secret.SecretID,
-			secret.SecretKey,
-			time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05"),
-			secret.CreatedAt.Format("2006-01-02 15:04:05"),
-		},
-	}
-
-	table = setHeader(table)
-	table = cmdutil.TableWriterDefaultConfig(table)
-	table.AppendBulk(data)
-	table.Render()
-
-	return nil
+	return o.printer.PrintObj(secret, secretTable(secret), o.Out)
 }
@@ -6,14 +6,13 @@ package secret
 
 import (
 	"context"
-	"time"
 
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
@@ -27,6 +26,9 @@ type ListOptions struct {
 	Offset int64
 	Limit  int64
 
+	PrintFlags *printers.PrintFlags
+
+	printer   printers.ResourcePrinter
 	iamclient iam.IamInterface
 	genericclioptions.IOStreams
 }
@@ -36,14 +38,18 @@ var listExample = templates.Examples(`
 		iamctl secret list
 
 		# List secrets with limit and offset 
-		iamctl secret list --offset=0 --limit=5`)
+		iamctl secret list --offset=0 --limit=5
+
+		# List all secrets in yaml format
+		iamctl secret list -o yaml`)
 
 // NewListOptions returns an initialized ListOptions instance.
 func NewListOptions(ioStreams genericclioptions.IOStreams) *ListOptions {
 	return &ListOptions{
-		IOStreams: ioStreams,
-		Offset:    0,
-		Limit:     defaltLimit,
+		IOStreams:  ioStreams,
+		Offset:     0,
+		Limit:      defaltLimit,
+		PrintFlags: printers.NewPrintFlags(),
 	}
 }
 
@@ -67,8 +73,9 @@ func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 		SuggestFor: []string{},
 	}
 
-	cmd.Flags().Int64VarP(&o.Offset, "offset", "o", o.Offset, "Specify the offset of the first row to be returned.")
+	cmd.Flags().Int64Var(&o.Offset, "offset", o.Offset, "Specify the offset of the first row to be returned.")
 	cmd.Flags().Int64VarP(&o.Limit, "limit", "l", o.Limit, "Specify the amount records to be returned.")
+	o.PrintFlags.AddFlags(cmd)
 
 	return cmd
 }
@@ -76,6 +83,11 @@ func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 // Complete completes all the required options.
 func (o *ListOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
 	var err error
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
 	o.iamclient, err = f.IAMClient()
 	if err != nil {
 		return err
@@ -99,23 +111,5 @@ func (o *ListOptions) Run(args []string) error {
 		return err
 	}
 
-	data := make([][]string, 0, len(secrets.Items))
-	table := tablewriter.NewWriter(o.Out)
-
-	for _, secret := range secrets.Items {
-		data = append(data, []string{
-			secret.Name,
-			secret.SecretID,
-			secret.SecretKey,
-			time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05"),
-			secret.CreatedAt.Format("2006-01-02 15:04:05"),
-		})
-	}
-
-	table = setHeader(table)
-	table = cmdutil.TableWriterDefaultConfig(table)
-	table.AppendBulk(data)
-	table.Render()
-
-	return nil
+	return o.printer.PrintObj(secrets, secretTable(secrets.Items...), o.Out)
 }
@@ -0,0 +1,129 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package top
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/version"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/marmotedu/iam/internal/authzserver/controller/v1/debug"
+)
+
+// The metrics shown by the top command.
+const (
+	metricRequests       = "gin_requests_total"
+	metricDecisions      = "iam_authz_decisions_total"
+	metricDBOpen         = "go_sql_open_connections"
+	metricDBInUse        = "go_sql_in_use_connections"
+	metricDBIdle         = "go_sql_idle_connections"
+	metricDBMaxOpen      = "go_sql_max_open_connections"
+	metricDBWaitDuration = "go_sql_wait_duration_seconds_total"
+)
+
+// sample is the state of a component at a point in time.
+type sample struct {
+	time    time.Time
+	version *version.Info
+	metrics map[string]*dto.MetricFamily
+	cache   *debug.CacheStatus
+
+	// the errors of the endpoints, the others are shown anyway
+	versionErr error
+	metricsErr error
+	cacheErr   error
+}
+
+// scrape samples the version and the metrics of a component, and its cache status if
+// withCache is true.
+func scrape(ctx context.Context, client rest.Interface, withCache bool) *sample {
+	s := &sample{time: time.Now()}
+
+	s.versionErr = client.Get().AbsPath("/version").Do(ctx).Into(&s.version)
+
+	var data []byte
+	if data, s.metricsErr = client.Get().AbsPath("/metrics").Do(ctx).Raw(); s.metricsErr == nil {
+		var parser expfmt.TextParser
+		s.metrics, s.metricsErr = parser.TextToMetricFamilies(bytes.NewReader(data))
+	}
+
+	if withCache {
+		s.cache = &debug.CacheStatus{}
+		s.cacheErr = client.Get().AbsPath("/debug/cache/status").Do(ctx).Into(s.cache)
+	}
+
+	return s
+}
+
+// value returns the sum of the counters or gauges of the metric, whose labels match the given
+// label pairs, and whether the metric exists.
+func (s *sample) value(name string, labels ...string) (float64, bool) {
+	family, ok := s.metrics[name]
+	if !ok {
+		return 0, false
+	}
+
+	var sum float64
+	for _, metric := range family.GetMetric() {
+		if !matchLabels(metric, labels) {
+			continue
+		}
+
+		switch {
+		case metric.Counter != nil:
+			sum += metric.GetCounter().GetValue()
+		case metric.Gauge != nil:
+			sum += metric.GetGauge().GetValue()
+		case metric.Untyped != nil:
+			sum += metric.GetUntyped().GetValue()
+		}
+	}
+
+	return sum, true
+}
+
+// rate returns the per second increase of the metric since the previous sample.
+func (s *sample) rate(prev *sample, name string, labels ...string) (float64, bool) {
+	if prev == nil {
+		return 0, false
+	}
+
+	current, ok := s.value(name, labels...)
+	if !ok {
+		return 0, false
+	}
+	previous, _ := prev.value(name, labels...)
+
+	elapsed := s.time.Sub(prev.time).Seconds()
+	// counters are reset by a restart of the component
+	if elapsed <= 0 || current < previous {
+		return 0, false
+	}
+
+	return (current - previous) / elapsed, true
+}
+
+func matchLabels(metric *dto.Metric, labels []string) bool {
+	for i := 0; i+1 < len(labels); i += 2 {
+		matched := false
+		for _, pair := range metric.GetLabel() {
+			if pair.GetName() == labels[i] && pair.GetValue() == labels[i+1] {
+				matched = true
+
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
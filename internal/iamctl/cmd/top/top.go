@@ -0,0 +1,295 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package top displays the live statistics of the iam servers.
+package top
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/marmotedu/iam/internal/iamctl/cmd/authz"
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/interrupt"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// TopOptions is an options struct to support top command.
+type TopOptions struct {
+	Interval time.Duration
+	Once     bool
+	authz.ClientOptions
+
+	apiClient   rest.Interface
+	authzClient rest.Interface
+	terminal    bool
+	genericclioptions.IOStreams
+}
+
+var (
+	topLong = templates.LongDesc(`Display the live statistics of iam-apiserver and iam-authz-server.
+
+The statistics are computed from the /metrics, /version and /debug/cache/status endpoints of
+the servers, so the metrics of the servers must be enabled. The view is refreshed every
+interval until interrupted, the rates are computed over the last interval.
+
+iam-authz-server is only shown when --authz-server is set.`)
+
+	topExample = templates.Examples(`
+		# Display the statistics of iam-apiserver, refreshed every 2 seconds
+		iamctl top
+
+		# Display the statistics of iam-apiserver and iam-authz-server, refreshed every 5 seconds
+		iamctl top --authz-server=http://127.0.0.1:9090 --interval=5s
+
+		# Print the statistics once, e.g. in a script
+		iamctl top --once`)
+)
+
+// NewTopOptions returns an initialized TopOptions instance.
+func NewTopOptions(ioStreams genericclioptions.IOStreams) *TopOptions {
+	return &TopOptions{
+		Interval:  2 * time.Second,
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdTop returns new initialized instance of top sub command.
+func NewCmdTop(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTopOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "top",
+		DisableFlagsInUseLine: true,
+		Short:                 "Display the live statistics of the iam servers",
+		Long:                  topLong,
+		Example:               topExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "The interval between the refreshes of the statistics.")
+	cmd.Flags().BoolVar(&o.Once, "once", o.Once, "Print the statistics once, over one interval, and exit.")
+	authz.AddClientFlags(cmd, &o.ClientOptions)
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *TopOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	if o.apiClient, err = f.RESTClient(); err != nil {
+		return err
+	}
+
+	if o.Server != "" {
+		if o.authzClient, err = authz.NewRESTClient(f, o.ClientOptions); err != nil {
+			return err
+		}
+	}
+
+	if file, ok := o.Out.(*os.File); ok {
+		o.terminal = term.IsTerminal(int(file.Fd()))
+	}
+
+	return nil
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *TopOptions) Validate(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected args: %v", args)
+	}
+
+	if o.Interval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s, got %s", o.Interval)
+	}
+
+	return nil
+}
+
+// Run executes a top command using the specified options.
+func (o *TopOptions) Run(args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// an interrupt stops the refreshes, instead of exiting with an error
+	return interrupt.New(func(os.Signal) {}, cancel).Run(func() error {
+		prevAPI, prevAuthz := o.scrape(ctx)
+
+		ticker := time.NewTicker(o.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			api, authz := o.scrape(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if o.terminal && !o.Once {
+				fmt.Fprint(o.Out, clearScreen)
+			}
+			o.print(o.Out, api, prevAPI, authz, prevAuthz)
+
+			if o.Once {
+				return nil
+			}
+			prevAPI, prevAuthz = api, authz
+		}
+	})
+}
+
+func (o *TopOptions) scrape(ctx context.Context) (api, authz *sample) {
+	api = scrape(ctx, o.apiClient, false)
+	if o.authzClient != nil {
+		authz = scrape(ctx, o.authzClient, true)
+	}
+
+	return api, authz
+}
+
+// print prints the statistics of the servers.
+func (o *TopOptions) print(out io.Writer, api, prevAPI, authz, prevAuthz *sample) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "%s %s\n", color.CyanString("iamctl top"), api.time.Format("2006-01-02 15:04:05"))
+
+	printHeader(w, "iam-apiserver", api)
+	printRequests(w, api, prevAPI)
+	printDB(w, api)
+
+	if authz == nil {
+		return
+	}
+
+	printHeader(w, "iam-authz-server", authz)
+	printRequests(w, authz, prevAuthz)
+	printDecisions(w, authz, prevAuthz)
+	printCache(w, authz)
+}
+
+func printHeader(w io.Writer, name string, s *sample) {
+	fmt.Fprintln(w)
+
+	// the header lines have no tab, so that the colors do not break the alignment
+	if s.versionErr != nil {
+		fmt.Fprintf(w, "%s %s\n", color.GreenString(name), unavailable(s.versionErr))
+
+		return
+	}
+	fmt.Fprintf(w, "%s %s\n", color.GreenString(name), s.version.GitVersion)
+}
+
+func printRequests(w io.Writer, s, prev *sample) {
+	if s.metricsErr != nil {
+		fmt.Fprintf(w, "  Metrics:\t%s\n", unavailable(s.metricsErr))
+
+		return
+	}
+
+	qps, ok := s.rate(prev, metricRequests)
+	if !ok {
+		fmt.Fprintf(w, "  QPS:\t-\n")
+
+		return
+	}
+	fmt.Fprintf(w, "  QPS:\t%.1f\n", qps)
+}
+
+func printDecisions(w io.Writer, s, prev *sample) {
+	if s.metricsErr != nil {
+		return
+	}
+
+	allowed, ok := s.rate(prev, metricDecisions, "decision", "allow")
+	denied, _ := s.rate(prev, metricDecisions, "decision", "deny")
+	if !ok {
+		fmt.Fprintf(w, "  Decisions:\t-\n")
+
+		return
+	}
+
+	ratio := "-"
+	if total := allowed + denied; total > 0 {
+		ratio = fmt.Sprintf("%.1f%% allowed", allowed/total*100)
+	}
+	fmt.Fprintf(w, "  Decisions:\t%.1f/s allowed, %.1f/s denied (%s)\n", allowed, denied, ratio)
+}
+
+func printDB(w io.Writer, s *sample) {
+	if s.metricsErr != nil {
+		return
+	}
+
+	open, ok := s.value(metricDBOpen)
+	if !ok {
+		fmt.Fprintf(w, "  DB pool:\t-\n")
+
+		return
+	}
+
+	inUse, _ := s.value(metricDBInUse)
+	idle, _ := s.value(metricDBIdle)
+	maxOpen, _ := s.value(metricDBMaxOpen)
+	limit := "unlimited"
+	if maxOpen > 0 {
+		limit = fmt.Sprintf("%.0f max (%.0f%% used)", maxOpen, inUse/maxOpen*100)
+	}
+	fmt.Fprintf(w, "  DB pool:\t%.0f in use, %.0f idle, %.0f open, %s\n", inUse, idle, open, limit)
+
+	if wait, ok := s.value(metricDBWaitDuration); ok && wait > 0 {
+		fmt.Fprintf(w, "  DB wait:\t%s total\n", time.Duration(wait*float64(time.Second)).Round(time.Millisecond))
+	}
+}
+
+func printCache(w io.Writer, s *sample) {
+	if s.cacheErr != nil {
+		fmt.Fprintf(w, "  Cache:\t%s\n", unavailable(s.cacheErr))
+
+		return
+	}
+
+	fmt.Fprintf(w, "  Cache:\t%d secrets, %d policies\n", s.cache.Secrets, s.cache.Policies)
+	fmt.Fprintf(w, "  Last sync:\t%s (sequence %d)\n", formatTime(s.cache.LastSyncTime), s.cache.LastSequence)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return fmt.Sprintf("%s ago", time.Since(t).Round(time.Second))
+}
+
+// unavailable formats the error of an endpoint on a single line.
+func unavailable(err error) string {
+	message := strings.TrimSpace(strings.SplitN(err.Error(), "\n", 2)[0])
+
+	return color.RedString("unavailable: %s", message)
+}
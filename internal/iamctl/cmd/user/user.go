@@ -6,11 +6,15 @@
 package user
 
 import (
-	"github.com/olekukonko/tablewriter"
+	"strconv"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
 
@@ -34,19 +38,45 @@ func NewCmdUser(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 	cmd.AddCommand(NewCmdList(f, ioStreams))
 	cmd.AddCommand(NewCmdDelete(f, ioStreams))
 	cmd.AddCommand(NewCmdUpdate(f, ioStreams))
+	cmd.AddCommand(NewCmdImport(f, ioStreams))
 
 	return cmd
 }
 
-// setHeader set headers for user commands.
-func setHeader(table *tablewriter.Table) *tablewriter.Table {
-	table.SetHeader([]string{"Name", "Nickname", "Email", "Phone", "Created", "Updated"})
-	table.SetHeaderColor(tablewriter.Colors{tablewriter.FgGreenColor},
-		tablewriter.Colors{tablewriter.FgRedColor},
-		tablewriter.Colors{tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.FgMagentaColor},
-		tablewriter.Colors{tablewriter.FgGreenColor},
-		tablewriter.Colors{tablewriter.FgWhiteColor})
+// userTable returns the table of the users.
+func userTable(users ...*v1.User) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Name"},
+		printers.Column{Name: "Nickname"},
+		printers.Column{Name: "Email"},
+		printers.Column{Name: "Phone"},
+		printers.Column{Name: "Created"},
+		printers.Column{Name: "Updated"},
+		printers.Column{Name: "Status", Wide: true},
+		printers.Column{Name: "Admin", Wide: true},
+		printers.Column{Name: "Policies", Wide: true},
+		printers.Column{Name: "LoginedAt", Wide: true},
+	)
+
+	for _, user := range users {
+		logined := ""
+		if !user.LoginedAt.IsZero() {
+			logined = user.LoginedAt.Format("2006-01-02 15:04:05")
+		}
+
+		table.AddRow(
+			user.Name,
+			user.Nickname,
+			user.Email,
+			user.Phone,
+			user.CreatedAt.Format("2006-01-02 15:04:05"),
+			user.UpdatedAt.Format("2006-01-02 15:04:05"),
+			userstate.State(user.Status).String(),
+			strconv.FormatBool(user.IsAdmin == 1),
+			strconv.FormatInt(user.TotalPolicy, 10),
+			logined,
+		)
+	}
 
 	return table
 }
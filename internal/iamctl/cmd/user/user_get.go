@@ -10,11 +10,12 @@ import (
 
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	iamv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
 
@@ -26,6 +27,9 @@ const (
 type GetOptions struct {
 	Name string
 
+	PrintFlags *printers.PrintFlags
+
+	printer   printers.ResourcePrinter
 	iamclient iam.IamInterface
 	genericclioptions.IOStreams
 }
@@ -33,7 +37,10 @@ type GetOptions struct {
 var (
 	getExample = templates.Examples(`
 		# Get user foo detail information
-		iamctl user get foo`)
+		iamctl user get foo
+
+		# Get user foo detail information, with the wide columns
+		iamctl user get foo -o wide`)
 
 	getUsageErrStr = fmt.Sprintf("expected '%s'.\nUSERNAME is required arguments for the get command", getUsageStr)
 )
@@ -41,7 +48,8 @@ var (
 // NewGetOptions returns an initialized GetOptions instance.
 func NewGetOptions(ioStreams genericclioptions.IOStreams) *GetOptions {
 	return &GetOptions{
-		IOStreams: ioStreams,
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
 	}
 }
 
@@ -57,6 +65,7 @@ func NewCmdGet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.
 		TraverseChildren:      true,
 		Long:                  `Display a user resource.`,
 		Example:               getExample,
+		ValidArgsFunction:     cmdutil.ResourceNameCompletionFunc(f, iamv1.CompletionKindUser),
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
 			cmdutil.CheckErr(o.Validate(cmd, args))
@@ -65,6 +74,8 @@ func NewCmdGet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.
 		SuggestFor: []string{},
 	}
 
+	o.PrintFlags.AddFlags(cmd)
+
 	return cmd
 }
 
@@ -77,6 +88,11 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 
 	o.Name = args[0]
 
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
 	o.iamclient, err = f.IAMClient()
 	if err != nil {
 		return err
@@ -97,23 +113,5 @@ func (o *GetOptions) Run(args []string) error {
 		return err
 	}
 
-	table := tablewriter.NewWriter(o.Out)
-
-	data := [][]string{
-		{
-			user.Name,
-			user.Nickname,
-			user.Email,
-			user.Phone,
-			user.CreatedAt.Format("2006-01-02 15:04:05"),
-			user.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
-	}
-
-	table = setHeader(table)
-	table = cmdutil.TableWriterDefaultConfig(table)
-	table.AppendBulk(data)
-	table.Render()
-
-	return nil
+	return o.printer.PrintObj(user, userTable(user), o.Out)
 }
@@ -0,0 +1,325 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	apiclientv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+	"github.com/spf13/cobra"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+const (
+	importUsageStr = "import --file FILE"
+)
+
+// importColumns are the columns of the csv file, the name, password and email are required.
+var importColumns = []string{"name", "password", "email", "nickname", "phone"}
+
+// ImportOptions is an options struct to support import subcommands.
+type ImportOptions struct {
+	File          string
+	Concurrency   int
+	DryRun        bool
+	FailureReport string
+
+	Client apiclientv1.APIV1Interface
+	genericclioptions.IOStreams
+}
+
+// importRecord is a user read from a line of the csv file.
+type importRecord struct {
+	line   int
+	fields []string
+	user   *v1.User
+	err    error
+}
+
+var (
+	importLong = templates.LongDesc(`Import users from a csv file, e.g. to migrate them from a legacy identity system.
+
+The first line of the file is the header, with the columns name, password, email, and optionally
+nickname and phone, in any order. The users are created concurrently, the lines which fail are
+written to the failure report file, with the error in the last column, so that they can be fixed
+and imported again.`)
+
+	importExample = templates.Examples(`
+		# Validate the users of users.csv without creating them
+		iamctl user import --file users.csv --dry-run
+
+		# Import the users of users.csv, 10 at a time
+		iamctl user import --file users.csv --concurrency=10`)
+)
+
+// NewImportOptions returns an initialized ImportOptions instance.
+func NewImportOptions(ioStreams genericclioptions.IOStreams) *ImportOptions {
+	return &ImportOptions{
+		Concurrency: 4,
+		IOStreams:   ioStreams,
+	}
+}
+
+// NewCmdImport returns new initialized instance of import sub command.
+func NewCmdImport(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewImportOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   importUsageStr,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{},
+		Short:                 "Import users from a csv file (Administrator rights required)",
+		TraverseChildren:      true,
+		Long:                  importLong,
+		Example:               importExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+		SuggestFor: []string{},
+	}
+
+	cmd.Flags().StringVarP(&o.File, "file", "f", o.File, "The csv file of the users to import.")
+	cmd.Flags().IntVar(&o.Concurrency, "concurrency", o.Concurrency, "The number of users created at the same time.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Only validate the users, without creating them.")
+	cmd.Flags().StringVar(&o.FailureReport, "failure-report", o.FailureReport, ""+
+		"The csv file the failed lines are written to, defaults to the file name with a .failed.csv suffix.")
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *ImportOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if o.File == "" {
+		return cmdutil.UsageErrorf(cmd, "expected '%s'.\n--file is required for the import command", importUsageStr)
+	}
+
+	if o.FailureReport == "" {
+		o.FailureReport = strings.TrimSuffix(o.File, ".csv") + ".failed.csv"
+	}
+
+	if o.DryRun {
+		return nil
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = apiclientv1.NewForConfig(clientConfig)
+
+	return err
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *ImportOptions) Validate(cmd *cobra.Command, args []string) error {
+	if o.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be greater than 0, got %d", o.Concurrency)
+	}
+
+	return nil
+}
+
+// Run executes an import subcommand using the specified options.
+func (o *ImportOptions) Run(args []string) error {
+	header, records, err := readImportFile(o.File)
+	if err != nil {
+		return err
+	}
+
+	var valid []*importRecord
+	for _, record := range records {
+		if record.err == nil {
+			valid = append(valid, record)
+		}
+	}
+
+	if !o.DryRun {
+		o.createUsers(valid)
+	}
+
+	var failed []*importRecord
+	for _, record := range records {
+		if record.err != nil {
+			failed = append(failed, record)
+		}
+	}
+
+	if o.DryRun {
+		fmt.Fprintf(o.Out, "%d users are valid, %d are invalid (dry run)\n", len(records)-len(failed), len(failed))
+	} else {
+		fmt.Fprintf(o.Out, "%d users imported, %d failed\n", len(records)-len(failed), len(failed))
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if err := writeFailureReport(o.FailureReport, header, failed); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%d of %d users failed, the failed lines are written to %s", len(failed), len(records), o.FailureReport)
+}
+
+// createUsers creates the users with at most o.Concurrency requests in flight, and prints
+// the progress.
+func (o *ImportOptions) createUsers(records []*importRecord) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		failures int
+	)
+
+	tokens := make(chan struct{}, o.Concurrency)
+	for _, record := range records {
+		tokens <- struct{}{}
+		wg.Add(1)
+
+		go func(record *importRecord) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+
+			_, record.err = o.Client.Users().Create(context.TODO(), record.user, metav1.CreateOptions{})
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if record.err != nil {
+				failures++
+			}
+			fmt.Fprintf(o.ErrOut, "\rimporting users: %d/%d, %d failed", done, len(records), failures)
+		}(record)
+	}
+	wg.Wait()
+
+	if len(records) > 0 {
+		fmt.Fprintln(o.ErrOut)
+	}
+}
+
+// readImportFile reads the header and the users of the csv file. The users which are
+// invalid, or duplicated in the file, have their error set.
+func readImportFile(file string) ([]string, []*importRecord, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the header of %s: %w", file, err)
+	}
+
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range importColumns[:3] {
+		if _, ok := index[column]; !ok {
+			return nil, nil, fmt.Errorf("column %s is missing in the header of %s", column, file)
+		}
+	}
+
+	var records []*importRecord
+	names := map[string]int{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			records = append(records, &importRecord{line: parseErr.StartLine, fields: fields, err: err})
+
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := &importRecord{line: line, fields: fields, user: newImportUser(index, fields)}
+		if first, ok := names[record.user.Name]; ok {
+			record.err = fmt.Errorf("user %s is duplicated, first defined at line %d", record.user.Name, first)
+		} else if errs := record.user.Validate(); len(errs) != 0 {
+			record.err = errs.ToAggregate()
+		}
+		names[record.user.Name] = line
+		records = append(records, record)
+	}
+
+	return header, records, nil
+}
+
+// newImportUser creates the user from the fields of a line.
+func newImportUser(index map[string]int, fields []string) *v1.User {
+	field := func(column string) string {
+		if i, ok := index[column]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+
+		return ""
+	}
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: field("name"),
+		},
+		Nickname: field("nickname"),
+		Password: field("password"),
+		Email:    field("email"),
+		Phone:    field("phone"),
+	}
+	if user.Nickname == "" {
+		user.Nickname = user.Name
+	}
+
+	return user
+}
+
+// writeFailureReport writes the failed lines to the report file, with their error.
+func writeFailureReport(file string, header []string, records []*importRecord) error {
+	// the report holds the passwords of the failed users
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := csv.NewWriter(f)
+	if err := writer.Write(append(append([]string{}, header...), "error")); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := writer.Write(append(append([]string{}, record.fields...), record.err.Error())); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -9,10 +9,10 @@ import (
 
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
 	"github.com/marmotedu/iam/internal/iamctl/util/templates"
 	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
 )
@@ -26,6 +26,9 @@ type ListOptions struct {
 	Offset int64
 	Limit  int64
 
+	PrintFlags *printers.PrintFlags
+
+	printer   printers.ResourcePrinter
 	iamclient iam.IamInterface
 	genericclioptions.IOStreams
 }
@@ -35,14 +38,21 @@ var listExample = templates.Examples(`
 		iamctl user list
 
 		# List users with limit and offset
-		iamctl user list --offset=0 --limit=10`)
+		iamctl user list --offset=0 --limit=10
+
+		# List the name and email of the users, without headers
+		iamctl user list --columns=name,email --no-headers
+
+		# List all users in json format
+		iamctl user list -o json`)
 
 // NewListOptions returns an initialized ListOptions instance.
 func NewListOptions(ioStreams genericclioptions.IOStreams) *ListOptions {
 	return &ListOptions{
-		IOStreams: ioStreams,
-		Offset:    0,
-		Limit:     defaultLimit,
+		IOStreams:  ioStreams,
+		Offset:     0,
+		Limit:      defaultLimit,
+		PrintFlags: printers.NewPrintFlags(),
 	}
 }
 
@@ -66,8 +76,9 @@ func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 		SuggestFor: []string{},
 	}
 
-	cmd.Flags().Int64VarP(&o.Offset, "offset", "o", o.Offset, "Specify the offset of the first row to be returned.")
+	cmd.Flags().Int64Var(&o.Offset, "offset", o.Offset, "Specify the offset of the first row to be returned.")
 	cmd.Flags().Int64VarP(&o.Limit, "limit", "l", o.Limit, "Specify the amount records to be returned.")
+	o.PrintFlags.AddFlags(cmd)
 
 	return cmd
 }
@@ -76,6 +87,11 @@ func NewCmdList(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra
 func (o *ListOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
 	var err error
 
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
 	o.iamclient, err = f.IAMClient()
 	if err != nil {
 		return err
@@ -99,20 +115,5 @@ func (o *ListOptions) Run(args []string) error {
 		return err
 	}
 
-	data := make([][]string, 0, 1)
-	table := tablewriter.NewWriter(o.Out)
-
-	for _, user := range users.Items {
-		data = append(data, []string{
-			user.Name, user.Nickname, user.Email,
-			user.Phone, user.CreatedAt.Format("2006-01-02 15:04:05"), user.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
-	}
-
-	table = setHeader(table)
-	table = cmdutil.TableWriterDefaultConfig(table)
-	table.AppendBulk(data)
-	table.Render()
-
-	return nil
+	return o.printer.PrintObj(users, userTable(users.Items...), o.Out)
 }
@@ -0,0 +1,73 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// completionTimeout bounds the time the shell waits for the names.
+const completionTimeout = 5 * time.Second
+
+// ResourceNameCompletionFunc returns a cobra ValidArgsFunction, which completes the first
+// argument with the names of the resources of the kind, e.g. v1.CompletionKindUser.
+func ResourceNameCompletionFunc(
+	f Factory,
+	kind string,
+) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return CompleteResourceNames(f, kind, toComplete)
+	}
+}
+
+// ResourceNameFlagCompletionFunc returns a cobra flag completion function, which completes the
+// value of a flag with the names of the resources of the kind.
+func ResourceNameFlagCompletionFunc(
+	f Factory,
+	kind string,
+) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return CompleteResourceNames(f, kind, toComplete)
+	}
+}
+
+// CompleteResourceNames returns the names of the resources of the kind which start with the
+// prefix. The names are filtered by iam-apiserver, instead of listing all the resources.
+func CompleteResourceNames(f Factory, kind, prefix string) ([]string, cobra.ShellCompDirective) {
+	client, err := f.RESTClient()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	names := &v1.CompletionList{}
+	err = client.Get().AbsPath("/v1/completions").
+		Param("kind", kind).
+		Param("prefix", prefix).
+		Param("limit", strconv.Itoa(v1.DefaultCompletionLimit)).
+		Do(ctx).
+		Into(names)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return names.Items, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package whoami prints the identity iamctl is authenticated as.
+package whoami
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+	"github.com/marmotedu/iam/internal/iamctl/util/printers"
+	"github.com/marmotedu/iam/internal/iamctl/util/templates"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/cli/genericclioptions"
+)
+
+// WhoAmIOptions is an options struct to support whoami command.
+type WhoAmIOptions struct {
+	PrintFlags *printers.PrintFlags
+
+	printer printers.ResourcePrinter
+	client  rest.Interface
+	genericclioptions.IOStreams
+}
+
+// whoAmI is the identity along with the server and the configuration file it was got with.
+type whoAmI struct {
+	Server string `json:"server"`
+	Config string `json:"config,omitempty"`
+	*v1.Identity
+}
+
+var (
+	whoamiLong = templates.LongDesc(`Display the identity iamctl is authenticated as.
+
+The identity is returned by iam-apiserver for the current credentials, so that it shows who
+the commands run as, with the server and the configuration file in use, the expiry time of
+the token, the tenant, the groups and roles, and whether the user is an administrator.`)
+
+	whoamiExample = templates.Examples(`
+		# Display the identity of the current credentials
+		iamctl whoami
+
+		# Display the identity of the credentials of another configuration file
+		iamctl whoami --iamconfig=$HOME/.iam/prod.yaml
+
+		# Display the identity as json
+		iamctl whoami -o json`)
+)
+
+// NewWhoAmIOptions returns an initialized WhoAmIOptions instance.
+func NewWhoAmIOptions(ioStreams genericclioptions.IOStreams) *WhoAmIOptions {
+	return &WhoAmIOptions{
+		PrintFlags: printers.NewPrintFlags(),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdWhoAmI returns new initialized instance of whoami sub command.
+func NewCmdWhoAmI(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewWhoAmIOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "whoami",
+		DisableFlagsInUseLine: true,
+		Short:                 "Display the identity iamctl is authenticated as",
+		Long:                  whoamiLong,
+		Example:               whoamiExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate(cmd, args))
+			cmdutil.CheckErr(o.Run(args))
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *WhoAmIOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	o.printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = f.RESTClient()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate makes sure there is no discrepency in command options.
+func (o *WhoAmIOptions) Validate(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected args: %v", args)
+	}
+
+	return nil
+}
+
+// Run executes a whoami command using the specified options.
+func (o *WhoAmIOptions) Run(args []string) error {
+	identity := &v1.Identity{}
+	if err := o.client.Get().AbsPath("/v1/whoami").Do(context.TODO()).Into(identity); err != nil {
+		return err
+	}
+
+	obj := &whoAmI{
+		Server:   viper.GetString(genericclioptions.FlagAPIServer),
+		Config:   viper.ConfigFileUsed(),
+		Identity: identity,
+	}
+
+	return o.printer.PrintObj(obj, whoAmITable(obj), o.Out)
+}
+
+// whoAmITable returns the table of the attributes of the identity.
+func whoAmITable(obj *whoAmI) *printers.Table {
+	table := printers.NewTable(
+		printers.Column{Name: "Attribute"},
+		printers.Column{Name: "Value"},
+	)
+
+	expiry := "-"
+	if obj.ExpiresAt != nil {
+		expiry = fmt.Sprintf("%s (in %s)", obj.ExpiresAt.Local().Format("2006-01-02 15:04:05"),
+			time.Until(*obj.ExpiresAt).Round(time.Second))
+	}
+
+	groups := "-"
+	if len(obj.Groups) > 0 {
+		groups = strings.Join(obj.Groups, ",")
+	}
+
+	tenant := "-"
+	if obj.Tenant != "" {
+		tenant = obj.Tenant
+	}
+
+	table.AddRow("Server", obj.Server)
+	table.AddRow("Config", obj.Config)
+	table.AddRow("Username", obj.Username)
+	table.AddRow("Email", obj.Email)
+	table.AddRow("Auth method", obj.AuthMethod)
+	table.AddRow("Token expiry", expiry)
+	table.AddRow("Tenant", tenant)
+	table.AddRow("Groups", groups)
+	table.AddRow("Admin", fmt.Sprintf("%t", obj.IsAdmin))
+
+	return table
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package printers prints the resources of the iamctl commands as a table, or as json or yaml.
+package printers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/spf13/cobra"
+)
+
+// The supported output formats.
+const (
+	OutputTable = ""
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// ResourcePrinter prints a resource. The table is used by the table printers, the object
+// by the json and yaml printers.
+type ResourcePrinter interface {
+	PrintObj(obj interface{}, table *Table, w io.Writer) error
+}
+
+// PrintFlags composes the flags which control how the resources are printed.
+type PrintFlags struct {
+	OutputFormat string
+	NoHeaders    bool
+	Columns      []string
+}
+
+// NewPrintFlags returns PrintFlags with default values set.
+func NewPrintFlags() *PrintFlags {
+	return &PrintFlags{}
+}
+
+// AddFlags binds the print flags to the command.
+func (f *PrintFlags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.OutputFormat, "output", "o", f.OutputFormat, ""+
+		"Output format. One of: json|yaml|wide, the default is a table.")
+	cmd.Flags().BoolVar(&f.NoHeaders, "no-headers", f.NoHeaders, ""+
+		"When using the default or wide output format, don't print headers.")
+	cmd.Flags().StringSliceVar(&f.Columns, "columns", f.Columns, ""+
+		"Comma separated list of the columns to print in the default or wide output format, e.g. NAME,EMAIL.")
+}
+
+// ToPrinter returns the printer of the output format.
+func (f *PrintFlags) ToPrinter() (ResourcePrinter, error) {
+	switch format := strings.ToLower(f.OutputFormat); format {
+	case OutputTable, OutputWide:
+		return &TablePrinter{Wide: format == OutputWide, NoHeaders: f.NoHeaders, Columns: f.Columns}, nil
+	case OutputJSON:
+		return &JSONPrinter{}, nil
+	case OutputYAML:
+		return &YAMLPrinter{}, nil
+	default:
+		return nil, fmt.Errorf("unable to match a printer suitable for the output format %q, "+
+			"allowed formats are: json, yaml, wide", f.OutputFormat)
+	}
+}
+
+// JSONPrinter prints the object as indented json.
+type JSONPrinter struct{}
+
+// PrintObj prints the object as json.
+func (p *JSONPrinter) PrintObj(obj interface{}, _ *Table, w io.Writer) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(obj); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// YAMLPrinter prints the object as yaml.
+type YAMLPrinter struct{}
+
+// PrintObj prints the object as yaml.
+func (p *YAMLPrinter) PrintObj(obj interface{}, _ *Table, w io.Writer) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
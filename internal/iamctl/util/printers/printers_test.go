@@ -0,0 +1,97 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package printers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTable() *Table {
+	table := NewTable(Column{Name: "Name"}, Column{Name: "Email"}, Column{Name: "Phone", Wide: true})
+	table.AddRow("colin", "colin@foxmail.com", "1812884xxxx")
+	table.AddRow("tom", "tom@foxmail.com", "")
+
+	return table
+}
+
+func TestTablePrinter(t *testing.T) {
+	color.NoColor = true
+
+	tests := []struct {
+		name    string
+		printer *TablePrinter
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			printer: &TablePrinter{},
+			want:    []string{"NAME   EMAIL", "colin  colin@foxmail.com", "tom    tom@foxmail.com"},
+		},
+		{
+			name:    "wide",
+			printer: &TablePrinter{Wide: true},
+			want:    []string{"NAME   EMAIL              PHONE", "colin  colin@foxmail.com  1812884xxxx", "tom    tom@foxmail.com"},
+		},
+		{
+			name:    "no headers",
+			printer: &TablePrinter{NoHeaders: true},
+			want:    []string{"colin  colin@foxmail.com", "tom    tom@foxmail.com"},
+		},
+		{
+			name:    "columns",
+			printer: &TablePrinter{Columns: []string{"phone", "NAME"}},
+			want:    []string{"PHONE        NAME", "1812884xxxx  colin", "             tom"},
+		},
+		{
+			name:    "unknown column",
+			printer: &TablePrinter{Columns: []string{"age"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tt.printer.PrintObj(nil, testTable(), &buf)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+			for i := range lines {
+				lines[i] = strings.TrimRight(lines[i], " ")
+			}
+			assert.Equal(t, tt.want, lines)
+		})
+	}
+}
+
+func TestPrintFlagsToPrinter(t *testing.T) {
+	obj := map[string]string{"name": "colin"}
+
+	var buf bytes.Buffer
+	printer, err := (&PrintFlags{OutputFormat: "json"}).ToPrinter()
+	assert.NoError(t, err)
+	assert.NoError(t, printer.PrintObj(obj, nil, &buf))
+	assert.Equal(t, "{\n    \"name\": \"colin\"\n}\n", buf.String())
+
+	buf.Reset()
+	printer, err = (&PrintFlags{OutputFormat: "YAML"}).ToPrinter()
+	assert.NoError(t, err)
+	assert.NoError(t, printer.PrintObj(obj, nil, &buf))
+	assert.Equal(t, "name: colin\n", buf.String())
+
+	_, err = (&PrintFlags{OutputFormat: "xml"}).ToPrinter()
+	assert.Error(t, err)
+}
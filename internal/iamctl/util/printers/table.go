@@ -0,0 +1,137 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package printers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+
+	cmdutil "github.com/marmotedu/iam/internal/iamctl/cmd/util"
+)
+
+// headerColors are the colors of the headers, in the order of the columns.
+var headerColors = []int{
+	tablewriter.FgGreenColor,
+	tablewriter.FgRedColor,
+	tablewriter.FgCyanColor,
+	tablewriter.FgMagentaColor,
+	tablewriter.FgGreenColor,
+	tablewriter.FgWhiteColor,
+}
+
+// Column is a column of a table.
+type Column struct {
+	Name string
+	// Wide columns are only printed by the wide output format, or when they are selected.
+	Wide bool
+}
+
+// Table is the tabular form of a resource, or of a list of resources.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// NewTable returns a table with the given columns.
+func NewTable(columns ...Column) *Table {
+	return &Table{Columns: columns}
+}
+
+// AddRow appends a row, with a cell per column.
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// TablePrinter prints the table of the resources.
+type TablePrinter struct {
+	Wide      bool
+	NoHeaders bool
+	// Columns are the names of the columns to print, in order, all the columns are printed
+	// if empty.
+	Columns []string
+}
+
+// PrintObj prints the table.
+func (p *TablePrinter) PrintObj(_ interface{}, table *Table, w io.Writer) error {
+	if table == nil {
+		return fmt.Errorf("the resource can not be printed as a table")
+	}
+
+	indexes, err := p.selectColumns(table)
+	if err != nil {
+		return err
+	}
+
+	writer := cmdutil.TableWriterDefaultConfig(tablewriter.NewWriter(w))
+
+	if !p.NoHeaders {
+		headers := make([]string, 0, len(indexes))
+		colors := make([]tablewriter.Colors, 0, len(indexes))
+		for i, index := range indexes {
+			headers = append(headers, strings.ToUpper(table.Columns[index].Name))
+			colors = append(colors, tablewriter.Colors{headerColors[i%len(headerColors)]})
+		}
+		writer.SetHeader(headers)
+		// the headers are not colored when the output is not a terminal, e.g. piped to grep
+		if !color.NoColor {
+			writer.SetHeaderColor(colors...)
+		}
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(indexes))
+		for _, index := range indexes {
+			cell := ""
+			if index < len(row) {
+				cell = row[index]
+			}
+			cells = append(cells, cell)
+		}
+		writer.Append(cells)
+	}
+	writer.Render()
+
+	return nil
+}
+
+// selectColumns returns the indexes of the columns to print.
+func (p *TablePrinter) selectColumns(table *Table) ([]int, error) {
+	var indexes []int
+	if len(p.Columns) == 0 {
+		for i, column := range table.Columns {
+			if p.Wide || !column.Wide {
+				indexes = append(indexes, i)
+			}
+		}
+
+		return indexes, nil
+	}
+
+	names := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		names = append(names, strings.ToUpper(column.Name))
+	}
+
+	for _, name := range p.Columns {
+		index := -1
+		for i, column := range names {
+			if strings.EqualFold(column, strings.TrimSpace(name)) {
+				index = i
+
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("unknown column %q, the columns are: %s", name, strings.Join(names, ","))
+		}
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package adminscope
+
+import (
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+)
+
+// ExtendKey is the key under which the scope is stored in the extend fields of the
+// delegated administrator.
+const ExtendKey = "adminScope"
+
+// GroupRef refers to a group or a role by its owner and name.
+type GroupRef struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+// Scope lists the users a delegated administrator administers, a user matching either
+// dimension is in scope. The platform administrators and the other delegated administrators
+// are never in scope.
+type Scope struct {
+	// Tenant grants the users of the administrator's own tenant.
+	Tenant bool `json:"tenant,omitempty"`
+
+	// Groups grants the members of the groups and roles. Referring to the groups of another
+	// owner keeps their members out of reach of the delegated administrator.
+	Groups []GroupRef `json:"groups,omitempty"`
+}
+
+// FromExtend returns the scope stored in the given extend fields.
+// Nil is returned if no scope is set.
+func FromExtend(ext metav1.Extend) (*Scope, error) {
+	value, ok := ext[ExtendKey]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal admin scope failed")
+	}
+
+	var s Scope
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, errors.Wrap(err, "unmarshal admin scope failed")
+	}
+
+	return &s, nil
+}
+
+// Validate validates that a scope is valid.
+func (s *Scope) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !s.Tenant && len(s.Groups) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "must grant the tenant or some groups"))
+	}
+
+	for i, group := range s.Groups {
+		for _, msg := range validation.IsQualifiedName(group.Owner) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("groups").Index(i).Child("owner"), group.Owner, msg))
+		}
+		for _, msg := range validation.IsQualifiedName(group.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("groups").Index(i).Child("name"), group.Name, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateExtend validates the scope stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	fldPath := field.NewPath("extend", ExtendKey)
+
+	s, err := FromExtend(ext)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, ext[ExtendKey], err.Error())}
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	return s.Validate(fldPath)
+}
+
+// Keep copies the given keys of the stored extend fields into the updated ones, so that the
+// callers not allowed to change them can not add, change or remove them.
+func Keep(updated, stored metav1.Extend, keys ...string) metav1.Extend {
+	for _, key := range keys {
+		if value, ok := stored[key]; ok {
+			if updated == nil {
+				updated = metav1.Extend{}
+			}
+			updated[key] = value
+		} else {
+			delete(updated, key)
+		}
+	}
+
+	return updated
+}
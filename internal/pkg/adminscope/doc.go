@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package adminscope defines the scope of the delegated administrators, who administer the
+// users of their tenant or of some groups without being platform administrators.
+package adminscope // import "github.com/marmotedu/iam/internal/pkg/adminscope"
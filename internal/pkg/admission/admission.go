@@ -0,0 +1,261 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+)
+
+// Operation is the operation of an admitted write.
+type Operation string
+
+// The operations of the admitted writes.
+const (
+	Create Operation = "CREATE"
+	Update Operation = "UPDATE"
+)
+
+// Kind is the kind of an admitted object.
+type Kind string
+
+// The kinds of the admitted objects.
+const (
+	KindUser   Kind = "user"
+	KindSecret Kind = "secret"
+	KindPolicy Kind = "policy"
+)
+
+// Attributes describes an admitted write.
+type Attributes struct {
+	Kind      Kind
+	Operation Operation
+
+	// Username is the name of the user issuing the request, empty for the writes which are not
+	// issued by an authenticated user, e.g. the users provisioned on their first login.
+	Username string
+
+	// Object is the *v1.User, *v1.Secret or *v1.Policy to write, the mutating hooks change it in
+	// place.
+	Object interface{}
+}
+
+// MutatingHook mutates the objects before they are validated and written.
+type MutatingHook interface {
+	Admit(ctx context.Context, a *Attributes) error
+}
+
+// ValidatingHook validates the objects once they are mutated, a write is denied if any
+// validating hook returns an error.
+type ValidatingHook interface {
+	Validate(ctx context.Context, a *Attributes) error
+}
+
+// MutatingHookFunc is an adapter to use an ordinary function as a MutatingHook.
+type MutatingHookFunc func(ctx context.Context, a *Attributes) error
+
+// Admit calls f(ctx, a).
+func (f MutatingHookFunc) Admit(ctx context.Context, a *Attributes) error {
+	return f(ctx, a)
+}
+
+// ValidatingHookFunc is an adapter to use an ordinary function as a ValidatingHook.
+type ValidatingHookFunc func(ctx context.Context, a *Attributes) error
+
+// Validate calls f(ctx, a).
+func (f ValidatingHookFunc) Validate(ctx context.Context, a *Attributes) error {
+	return f(ctx, a)
+}
+
+// Deny returns the error of a write denied by a hook, the field errors are returned to the
+// client along with ErrAdmissionDenied. The other errors returned by the hooks are returned as
+// a single field error.
+func Deny(errs field.ErrorList) error {
+	return validation.NewErrorWithCode(code.ErrAdmissionDenied, errs)
+}
+
+type plugin struct {
+	mutating   MutatingHook
+	validating ValidatingHook
+}
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   = map[string]*plugin{}
+)
+
+// RegisterMutating registers an in-process mutating hook, which is invoked once its name is
+// enabled by --admission.plugins. It is usually called in the init function of the package
+// implementing the hook.
+func RegisterMutating(name string, hook MutatingHook) {
+	register(name, func(p *plugin) bool {
+		registered := p.mutating != nil
+		p.mutating = hook
+
+		return registered
+	})
+}
+
+// RegisterValidating registers an in-process validating hook, which is invoked once its name
+// is enabled by --admission.plugins. A plugin may register both a mutating and a validating
+// hook under the same name.
+func RegisterValidating(name string, hook ValidatingHook) {
+	register(name, func(p *plugin) bool {
+		registered := p.validating != nil
+		p.validating = hook
+
+		return registered
+	})
+}
+
+func register(name string, set func(p *plugin) bool) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	p, ok := plugins[name]
+	if !ok {
+		p = &plugin{}
+		plugins[name] = p
+	}
+
+	if set(p) {
+		panic(fmt.Sprintf("admission plugin %s registered twice", name))
+	}
+}
+
+// Plugins returns the sorted names of the registered in-process hooks.
+func Plugins() []string {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type namedMutatingHook struct {
+	name string
+	MutatingHook
+}
+
+type namedValidatingHook struct {
+	name string
+	ValidatingHook
+}
+
+// Chain invokes the mutating hooks in order, then the validating hooks in order.
+type Chain struct {
+	mutating   []namedMutatingHook
+	validating []namedValidatingHook
+}
+
+// NewChain returns the chain of the enabled in-process hooks, followed by the webhooks.
+func NewChain(opts *AdmissionOptions) (*Chain, error) {
+	c := &Chain{}
+
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	for _, name := range opts.Plugins {
+		p, ok := plugins[name]
+		if !ok {
+			return nil, fmt.Errorf("admission plugin %s is not registered", name)
+		}
+
+		if p.mutating != nil {
+			c.mutating = append(c.mutating, namedMutatingHook{name, p.mutating})
+		}
+		if p.validating != nil {
+			c.validating = append(c.validating, namedValidatingHook{name, p.validating})
+		}
+	}
+
+	for _, w := range opts.Webhooks {
+		hook, err := newWebhook(w)
+		if err != nil {
+			return nil, err
+		}
+
+		if w.Type == WebhookTypeMutating {
+			c.mutating = append(c.mutating, namedMutatingHook{w.Name, hook})
+		} else {
+			c.validating = append(c.validating, namedValidatingHook{w.Name, hook})
+		}
+	}
+
+	return c, nil
+}
+
+// Admit invokes the hooks of the chain, the write is denied once a hook returns an error.
+func (c *Chain) Admit(ctx context.Context, a *Attributes) error {
+	if c == nil {
+		return nil
+	}
+
+	for _, h := range c.mutating {
+		if err := h.Admit(ctx, a); err != nil {
+			return denied(h.name, err)
+		}
+	}
+
+	for _, h := range c.validating {
+		if err := h.Validate(ctx, a); err != nil {
+			return denied(h.name, err)
+		}
+	}
+
+	return nil
+}
+
+func denied(name string, err error) error {
+	if errors.IsCode(err, code.ErrAdmissionDenied) {
+		return err
+	}
+
+	return Deny(field.ErrorList{
+		&field.Error{
+			Type:   field.ErrorTypeForbidden,
+			Detail: fmt.Sprintf("denied by %s: %s", name, err.Error()),
+		},
+	})
+}
+
+var (
+	chainMu sync.RWMutex
+	chain   *Chain
+)
+
+// SetChain sets the chain invoked by Admit.
+func SetChain(c *Chain) {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
+	chain = c
+}
+
+// Admit invokes the chain set by SetChain before the object is written, the writes are admitted
+// if no chain is set. The requesting user is taken from the request context.
+func Admit(ctx context.Context, kind Kind, op Operation, obj interface{}) error {
+	chainMu.RLock()
+	c := chain
+	chainMu.RUnlock()
+
+	username, _ := ctx.Value(middleware.UsernameKey).(string)
+
+	return c.Admit(ctx, &Attributes{Kind: kind, Operation: op, Username: username, Object: obj})
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admission
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/spf13/pflag"
+)
+
+// The types of the webhooks.
+const (
+	WebhookTypeMutating   = "mutating"
+	WebhookTypeValidating = "validating"
+)
+
+// The failure policies of the webhooks.
+const (
+	// FailurePolicyFail denies the writes when the webhook can not be called.
+	FailurePolicyFail = "fail"
+	// FailurePolicyIgnore admits the writes when the webhook can not be called.
+	FailurePolicyIgnore = "ignore"
+)
+
+// AdmissionOptions contains configuration items related to the admission hooks.
+type AdmissionOptions struct {
+	// Plugins are the names of the enabled in-process hooks, invoked in order.
+	Plugins  []string          `json:"plugins"  mapstructure:"plugins"`
+	Webhooks []*WebhookOptions `json:"webhooks" mapstructure:"webhooks"`
+}
+
+// WebhookOptions contains configuration items of an external admission webhook.
+type WebhookOptions struct {
+	Name string `json:"name" mapstructure:"name"`
+	URL  string `json:"url"  mapstructure:"url"`
+	Type string `json:"type" mapstructure:"type"`
+	// Kinds and Operations restrict the writes sent to the webhook, all the writes are sent
+	// when they are empty.
+	Kinds         []string      `json:"kinds"          mapstructure:"kinds"`
+	Operations    []string      `json:"operations"     mapstructure:"operations"`
+	Timeout       time.Duration `json:"timeout"        mapstructure:"timeout"`
+	FailurePolicy string        `json:"failure-policy" mapstructure:"failure-policy"`
+	// CAFile verifies the certificate of a https webhook, the system roots are used if empty.
+	CAFile string `json:"ca-file" mapstructure:"ca-file"`
+}
+
+// NewAdmissionOptions creates an AdmissionOptions object with default parameters.
+func NewAdmissionOptions() *AdmissionOptions {
+	return &AdmissionOptions{
+		Plugins:  []string{},
+		Webhooks: []*WebhookOptions{},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *AdmissionOptions) Validate() []error {
+	errs := []error{}
+
+	registered := map[string]bool{}
+	for _, name := range Plugins() {
+		registered[name] = true
+	}
+
+	for _, name := range o.Plugins {
+		if !registered[name] {
+			errs = append(errs, fmt.Errorf("--admission.plugins %s is not registered, must be one of %s",
+				name, strings.Join(Plugins(), ", ")))
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, w := range o.Webhooks {
+		for _, msg := range validation.IsQualifiedName(w.Name) {
+			errs = append(errs, fmt.Errorf("admission.webhooks[%d].name %s is invalid: %s", i, w.Name, msg))
+		}
+
+		if seen[w.Name] {
+			errs = append(errs, fmt.Errorf("admission.webhooks[%d].name %s is duplicated", i, w.Name))
+		}
+		seen[w.Name] = true
+
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("admission.webhooks[%d].url must be a http or https url", i))
+		}
+
+		if w.Type != WebhookTypeMutating && w.Type != WebhookTypeValidating {
+			errs = append(errs, fmt.Errorf("admission.webhooks[%d].type %s is not supported, must be %s or %s",
+				i, w.Type, WebhookTypeMutating, WebhookTypeValidating))
+		}
+
+		for _, kind := range w.Kinds {
+			if k := Kind(kind); k != KindUser && k != KindSecret && k != KindPolicy {
+				errs = append(errs, fmt.Errorf("admission.webhooks[%d].kinds %s is not supported, must be %s, %s or %s",
+					i, kind, KindUser, KindSecret, KindPolicy))
+			}
+		}
+
+		for _, op := range w.Operations {
+			if operation := Operation(op); operation != Create && operation != Update {
+				errs = append(errs, fmt.Errorf("admission.webhooks[%d].operations %s is not supported, must be %s or %s",
+					i, op, Create, Update))
+			}
+		}
+
+		if w.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("admission.webhooks[%d].timeout can not be negative", i))
+		}
+
+		switch w.FailurePolicy {
+		case "", FailurePolicyFail, FailurePolicyIgnore:
+		default:
+			errs = append(errs, fmt.Errorf("admission.webhooks[%d].failure-policy %s is not supported, must be %s or %s",
+				i, w.FailurePolicy, FailurePolicyFail, FailurePolicyIgnore))
+		}
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the admission hooks for a specific api server to the
+// specified FlagSet. The webhooks can only be configured by the config file.
+func (o *AdmissionOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringSliceVar(&o.Plugins, "admission.plugins", o.Plugins, ""+
+		"In-process admission hooks invoked before the users, secrets and policies are written, in order. "+
+		"Registered hooks: "+strings.Join(Plugins(), ", ")+".")
+}
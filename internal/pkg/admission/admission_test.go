@@ -0,0 +1,153 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+)
+
+func newPolicy(actions ...string) *v1.Policy {
+	return &v1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Username:   "colin",
+		Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+			Subjects:  []string{"users:colin"},
+			Effect:    ladon.AllowAccess,
+			Actions:   actions,
+			Resources: []string{"resources:articles:<.*>"},
+		}},
+	}
+}
+
+func TestChain_Admit(t *testing.T) {
+	var calls []string
+	RegisterMutating("TestChainMutating", MutatingHookFunc(func(ctx context.Context, a *Attributes) error {
+		calls = append(calls, "mutating")
+		a.Object.(*v1.Policy).Extend = metav1.Extend{"team": "iam"}
+
+		return nil
+	}))
+	RegisterValidating("TestChainValidating", ValidatingHookFunc(func(ctx context.Context, a *Attributes) error {
+		calls = append(calls, "validating")
+		if a.Object.(*v1.Policy).Extend["team"] != "iam" {
+			return fmt.Errorf("team is required")
+		}
+
+		return nil
+	}))
+
+	chain, err := NewChain(&AdmissionOptions{
+		Plugins: []string{"TestChainValidating", "TestChainMutating", DenyWildcardPolicies},
+	})
+	assert.NoError(t, err)
+
+	pol := newPolicy("get")
+	assert.NoError(t, chain.Admit(context.Background(), &Attributes{Kind: KindPolicy, Operation: Create, Object: pol}))
+	assert.Equal(t, []string{"mutating", "validating"}, calls)
+	assert.Equal(t, "iam", pol.Extend["team"])
+
+	err = chain.Admit(context.Background(), &Attributes{Kind: KindPolicy, Operation: Create, Object: newPolicy("<.*>")})
+	assert.True(t, errors.IsCode(err, code.ErrAdmissionDenied))
+
+	_, err = NewChain(&AdmissionOptions{Plugins: []string{"NotRegistered"}})
+	assert.Error(t, err)
+}
+
+func TestChain_Admit_plainError(t *testing.T) {
+	chain := &Chain{validating: []namedValidatingHook{{"naming", ValidatingHookFunc(
+		func(ctx context.Context, a *Attributes) error {
+			return fmt.Errorf("names must start with team-")
+		})}}}
+
+	err := chain.Admit(context.Background(), &Attributes{Kind: KindPolicy, Operation: Create, Object: newPolicy("get")})
+	assert.True(t, errors.IsCode(err, code.ErrAdmissionDenied))
+
+	var verr *validation.Error
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "denied by naming: names must start with team-", verr.Fields[0].Message)
+}
+
+func TestWebhook(t *testing.T) {
+	var review Review
+	rsp := ReviewResponse{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		_ = json.NewEncoder(w).Encode(rsp)
+	}))
+	defer srv.Close()
+
+	hook, err := newWebhook(&WebhookOptions{Name: "tags", URL: srv.URL, Type: WebhookTypeMutating})
+	assert.NoError(t, err)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{ID: 1, Name: "secret"},
+		Username:   "colin",
+		SecretID:   "id",
+		SecretKey:  "key",
+	}
+	a := &Attributes{Kind: KindSecret, Operation: Update, Username: "admin", Object: secret}
+
+	rsp = ReviewResponse{Allowed: true, Patch: json.RawMessage(`{"metadata":{"extend":{"team":"iam"}},"expires":0}`)}
+	assert.NoError(t, hook.Admit(context.Background(), a))
+	assert.Equal(t, "admin", review.Username)
+	assert.NotContains(t, review.Object, "secretKey")
+	assert.Equal(t, "iam", secret.Extend["team"])
+	assert.Equal(t, uint64(1), secret.ID)
+	assert.Equal(t, "key", secret.SecretKey)
+
+	rsp = ReviewResponse{Allowed: true, Patch: json.RawMessage(`{"metadata":{"name":"renamed"}}`)}
+	assert.Error(t, hook.Admit(context.Background(), a))
+	assert.Equal(t, "secret", secret.Name)
+
+	rsp = ReviewResponse{Allowed: false, Reason: "names must start with team-"}
+	err = hook.Admit(context.Background(), a)
+	assert.True(t, errors.IsCode(err, code.ErrAdmissionDenied))
+}
+
+func TestWebhook_failurePolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	a := &Attributes{Kind: KindPolicy, Operation: Create, Object: newPolicy("get")}
+
+	fail, _ := newWebhook(&WebhookOptions{Name: "fail", URL: srv.URL, Type: WebhookTypeValidating})
+	assert.Error(t, fail.Validate(context.Background(), a))
+
+	ignore, _ := newWebhook(&WebhookOptions{
+		Name: "ignore", URL: srv.URL, Type: WebhookTypeValidating, FailurePolicy: FailurePolicyIgnore,
+	})
+	assert.NoError(t, ignore.Validate(context.Background(), a))
+
+	users, _ := newWebhook(&WebhookOptions{Name: "users", URL: srv.URL, Type: WebhookTypeValidating, Kinds: []string{"user"}})
+	assert.NoError(t, users.Validate(context.Background(), a))
+}
+
+func TestAdmissionOptions_Validate(t *testing.T) {
+	o := &AdmissionOptions{
+		Plugins: []string{DenyWildcardPolicies, "NotRegistered"},
+		Webhooks: []*WebhookOptions{
+			{Name: "naming", URL: "https://admission.marmotedu.com/review", Type: WebhookTypeValidating},
+			{Name: "naming", URL: "ftp://admission", Type: "audit", Kinds: []string{"group"}, FailurePolicy: "retry"},
+		},
+	}
+
+	assert.Len(t, o.Validate(), 6)
+}
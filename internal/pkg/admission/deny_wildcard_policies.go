@@ -0,0 +1,49 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/policylint"
+)
+
+// DenyWildcardPolicies is the name of the plugin denying the allow policies which grant every
+// action or every resource, e.g. actions: ["<.*>"].
+const DenyWildcardPolicies = "DenyWildcardPolicies"
+
+func init() {
+	RegisterValidating(DenyWildcardPolicies, ValidatingHookFunc(denyWildcardPolicies))
+}
+
+func denyWildcardPolicies(ctx context.Context, a *Attributes) error {
+	pol, ok := a.Object.(*v1.Policy)
+	if !ok || pol.Policy.Effect != ladon.AllowAccess {
+		return nil
+	}
+
+	var errs field.ErrorList
+	check := func(path *field.Path, templates []string, kind string) {
+		for i, template := range templates {
+			if policylint.MatchesAll(template) {
+				errs = append(errs, field.Forbidden(path.Index(i), fmt.Sprintf("%q matches every %s", template, kind)))
+			}
+		}
+	}
+
+	check(field.NewPath("policy", "actions"), pol.Policy.Actions, "action")
+	check(field.NewPath("policy", "resources"), pol.Policy.Resources, "resource")
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return Deny(errs)
+}
@@ -0,0 +1,10 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package admission implements the admission hooks invoked by iam-apiserver before a user,
+// secret or policy is written. The mutating hooks may change the object, e.g. inject default
+// extend fields, then the validating hooks may deny the write, e.g. to enforce naming
+// conventions. The hooks are either registered in process and enabled by name, or external
+// webhooks called over HTTP.
+package admission // import "github.com/marmotedu/iam/internal/pkg/admission"
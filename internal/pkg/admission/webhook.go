@@ -0,0 +1,282 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// Review is the body of the requests posted to the admission webhooks. The password of the
+// users and the secret key of the secrets are left out of the object.
+type Review struct {
+	Kind      Kind                   `json:"kind"`
+	Operation Operation              `json:"operation"`
+	Username  string                 `json:"username"`
+	Object    map[string]interface{} `json:"object"`
+}
+
+// ReviewResponse is the body of the responses of the admission webhooks.
+type ReviewResponse struct {
+	Allowed bool `json:"allowed"`
+
+	// Reason tells the client why the write is denied.
+	Reason string `json:"reason,omitempty"`
+
+	// Patch is a JSON merge patch (RFC 7386) applied to the object, only the patches returned
+	// by the mutating webhooks are applied.
+	Patch json.RawMessage `json:"patch,omitempty"`
+}
+
+// hiddenFields are the fields left out of the objects posted to the webhooks.
+var hiddenFields = map[Kind][]string{
+	KindUser:   {"password"},
+	KindSecret: {"secretKey"},
+}
+
+// immutableFields are the fields the patches of the webhooks can not change.
+var immutableFields = map[Kind][][]string{
+	KindUser:   {{"password"}},
+	KindSecret: {{"username"}, {"secretID"}, {"secretKey"}},
+	KindPolicy: {{"username"}},
+}
+
+var immutableMetadataFields = [][]string{
+	{"metadata", "id"},
+	{"metadata", "instanceID"},
+	{"metadata", "name"},
+	{"metadata", "createdAt"},
+	{"metadata", "updatedAt"},
+	{"metadata", "extend", "resourceVersion"},
+}
+
+// webhook posts the writes as json Review to the url of an external webhook, which must answer
+// with 200 and a json ReviewResponse.
+type webhook struct {
+	opts   *WebhookOptions
+	client *http.Client
+}
+
+func newWebhook(opts *WebhookOptions) (*webhook, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	w := &webhook{opts: opts, client: &http.Client{Timeout: timeout}}
+	if opts.CAFile == "" {
+		return w, nil
+	}
+
+	ca, err := ioutil.ReadFile(opts.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate found in %s", opts.CAFile)
+	}
+
+	w.client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+	}
+
+	return w, nil
+}
+
+// Admit posts the write to a mutating webhook and applies the returned patch.
+func (w *webhook) Admit(ctx context.Context, a *Attributes) error {
+	return w.admit(ctx, a)
+}
+
+// Validate posts the write to a validating webhook.
+func (w *webhook) Validate(ctx context.Context, a *Attributes) error {
+	return w.admit(ctx, a)
+}
+
+func (w *webhook) admit(ctx context.Context, a *Attributes) error {
+	if !w.handles(a) {
+		return nil
+	}
+
+	data, err := json.Marshal(a.Object)
+	if err != nil {
+		return err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	review := &Review{Kind: a.Kind, Operation: a.Operation, Username: a.Username, Object: map[string]interface{}{}}
+	for k, v := range object {
+		review.Object[k] = v
+	}
+	for _, k := range hiddenFields[a.Kind] {
+		delete(review.Object, k)
+	}
+
+	rsp, err := w.call(ctx, review)
+	if err != nil {
+		if w.opts.FailurePolicy == FailurePolicyIgnore {
+			log.L(ctx).Warnf("Admission webhook %s failed, the %s is admitted: %s", w.opts.Name, a.Kind, err.Error())
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to call the webhook: %w", err)
+	}
+
+	if !rsp.Allowed {
+		reason := rsp.Reason
+		if reason == "" {
+			reason = "the request is not allowed"
+		}
+
+		return Deny(field.ErrorList{
+			&field.Error{Type: field.ErrorTypeForbidden, Detail: fmt.Sprintf("denied by %s: %s", w.opts.Name, reason)},
+		})
+	}
+
+	if w.opts.Type != WebhookTypeMutating || len(rsp.Patch) == 0 {
+		return nil
+	}
+
+	return applyPatch(a, object, rsp.Patch)
+}
+
+func (w *webhook) handles(a *Attributes) bool {
+	return matches(w.opts.Kinds, string(a.Kind)) && matches(w.opts.Operations, string(a.Operation))
+}
+
+func (w *webhook) call(ctx context.Context, review *Review) (*ReviewResponse, error) {
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var rsp ReviewResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rsp); err != nil {
+		return nil, fmt.Errorf("decode response failed: %w", err)
+	}
+
+	return &rsp, nil
+}
+
+// applyPatch merges the patch into the json object of the attributes and decodes the result
+// back into the object.
+func applyPatch(a *Attributes, object map[string]interface{}, data json.RawMessage) error {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return fmt.Errorf("the patch must be a json object: %w", err)
+	}
+
+	for _, path := range append(immutableMetadataFields, immutableFields[a.Kind]...) {
+		if touches(patch, path) {
+			return fmt.Errorf("the patch can not change %s", strings.Join(path, "."))
+		}
+	}
+
+	merged, err := json.Marshal(mergePatch(object, patch))
+	if err != nil {
+		return err
+	}
+
+	patched := reflect.New(reflect.TypeOf(a.Object).Elem())
+	if err := json.Unmarshal(merged, patched.Interface()); err != nil {
+		return fmt.Errorf("the patched %s is invalid: %w", a.Kind, err)
+	}
+	reflect.ValueOf(a.Object).Elem().Set(patched.Elem())
+
+	return nil
+}
+
+// mergePatch applies a JSON merge patch (RFC 7386) to the target.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+
+			continue
+		}
+
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
+
+// touches reports whether the patch sets the field or replaces one of its parents.
+func touches(patch map[string]interface{}, path []string) bool {
+	v, ok := patch[path[0]]
+	if !ok {
+		return false
+	}
+
+	next, isObject := v.(map[string]interface{})
+	if len(path) == 1 || !isObject {
+		return true
+	}
+
+	return touches(next, path[1:])
+}
+
+func matches(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cachefilter
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// usernameField is the field number used to carry the username filter in
+// pb.ListSecretsRequest and pb.ListPoliciesRequest. The requests have no such
+// field yet, so the filter travels as an unknown field. An apiserver which does not
+// know the filter returns the full list, which is still a correct superset.
+const usernameField protowire.Number = 100
+
+// SetUsername limits the list request to the resources owned by username.
+func SetUsername(req proto.Message, username string) {
+	m := req.ProtoReflect()
+	raw := protowire.AppendTag(m.GetUnknown(), usernameField, protowire.BytesType)
+	raw = protowire.AppendString(raw, username)
+	m.SetUnknown(raw)
+}
+
+// Username returns the username filter of the list request.
+// The second return value reports whether a filter is set.
+func Username(req proto.Message) (string, bool) {
+	raw := req.ProtoReflect().GetUnknown()
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return "", false
+		}
+		raw = raw[n:]
+
+		if num == usernameField && typ == protowire.BytesType {
+			value, n := protowire.ConsumeString(raw)
+			if n < 0 {
+				return "", false
+			}
+
+			return value, true
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return "", false
+		}
+		raw = raw[n:]
+	}
+
+	return "", false
+}
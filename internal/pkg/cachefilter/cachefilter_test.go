@@ -0,0 +1,36 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cachefilter
+
+import (
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUsername(t *testing.T) {
+	req := &pb.ListPoliciesRequest{}
+	if _, ok := Username(req); ok {
+		t.Fatalf("Username() on empty request should not be set")
+	}
+
+	SetUsername(req, "colin")
+
+	// the filter must survive the wire
+	raw, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	got := &pb.ListPoliciesRequest{}
+	if err := proto.Unmarshal(raw, got); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	if username, ok := Username(got); !ok || username != "colin" {
+		t.Errorf("Username() = %v, %v, want colin, true", username, ok)
+	}
+}
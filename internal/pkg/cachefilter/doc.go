@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package cachefilter defines the filters iam-authz-server passes to the apiserver
+// cache service to list the secrets and policies of a single user.
+package cachefilter // import "github.com/marmotedu/iam/internal/pkg/cachefilter"
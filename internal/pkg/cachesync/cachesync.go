@@ -0,0 +1,91 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cachesync
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/marmotedu/iam/internal/authzserver/load"
+	"github.com/marmotedu/iam/pkg/sdk/rpc"
+)
+
+// ListChangesSinceMethod is the full name of the method listing the changes, it is served by
+// the proto.Cache service along with the generated methods. Its messages are encoded as json.
+const ListChangesSinceMethod = "/proto.Cache/ListChangesSince"
+
+// revisionField is the field number used to carry the revision in pb.ListSecretsResponse and
+// pb.ListPoliciesResponse, as an unknown field like the filters of the cachefilter package.
+const revisionField protowire.Number = 101
+
+// ListChangesSinceRequest lists the changes after the revision.
+type ListChangesSinceRequest struct {
+	Revision int64 `json:"revision"`
+}
+
+// ListChangesSinceResponse holds the changes after the requested revision, in order.
+type ListChangesSinceResponse struct {
+	// Revision is the revision of the last change.
+	Revision int64         `json:"revision"`
+	Changes  []load.Change `json:"changes"`
+	// Reset is true when the changes after the requested revision are no longer retained,
+	// the resources have to be reloaded in full.
+	Reset bool `json:"reset"`
+}
+
+// ListChangesSince calls the ListChangesSince method of the cache service.
+func ListChangesSince(
+	ctx context.Context,
+	cc grpc.ClientConnInterface,
+	req *ListChangesSinceRequest,
+) (*ListChangesSinceResponse, error) {
+	rsp := &ListChangesSinceResponse{}
+	if err := cc.Invoke(ctx, ListChangesSinceMethod, req, rsp, grpc.CallContentSubtype(rpc.Codec{}.Name())); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+// SetRevision sets the revision of the resources of the list response.
+func SetRevision(resp proto.Message, revision int64) {
+	m := resp.ProtoReflect()
+	raw := protowire.AppendTag(m.GetUnknown(), revisionField, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, uint64(revision))
+	m.SetUnknown(raw)
+}
+
+// Revision returns the revision of the resources of the list response.
+// The second return value reports whether the revision is set.
+func Revision(resp proto.Message) (int64, bool) {
+	raw := resp.ProtoReflect().GetUnknown()
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return 0, false
+		}
+		raw = raw[n:]
+
+		if num == revisionField && typ == protowire.VarintType {
+			value, n := protowire.ConsumeVarint(raw)
+			if n < 0 {
+				return 0, false
+			}
+
+			return int64(value), true
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return 0, false
+		}
+		raw = raw[n:]
+	}
+
+	return 0, false
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cachesync
+
+import (
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRevision(t *testing.T) {
+	resp := &pb.ListSecretsResponse{TotalCount: 1}
+	if _, ok := Revision(resp); ok {
+		t.Fatalf("Revision() on empty response should not be set")
+	}
+
+	SetRevision(resp, 42)
+
+	// the revision must survive the wire
+	raw, err := proto.Marshal(resp)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	got := &pb.ListSecretsResponse{}
+	if err := proto.Unmarshal(raw, got); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	if revision, ok := Revision(got); !ok || revision != 42 {
+		t.Errorf("Revision() = %v, %v, want 42, true", revision, ok)
+	}
+	if got.TotalCount != 1 {
+		t.Errorf("TotalCount = %v, want 1", got.TotalCount)
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package cachesync defines the delta synchronization of the apiserver cache service. The
+// list responses carry the revision of the listed resources, and the ListChangesSince method
+// returns the changes after a revision, so that iam-authz-server catches up with a brief
+// disconnection by reloading the changed users only.
+package cachesync // import "github.com/marmotedu/iam/internal/pkg/cachesync"
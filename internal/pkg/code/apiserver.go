@@ -13,6 +13,15 @@ const (
 
 	// ErrUserAlreadyExist - 400: User already exist.
 	ErrUserAlreadyExist
+
+	// ErrUserSuspended - 403: User is suspended.
+	ErrUserSuspended
+
+	// ErrUserDeactivated - 403: User is deactivated.
+	ErrUserDeactivated
+
+	// ErrUserStateTransition - 409: User can not move to the requested state.
+	ErrUserStateTransition
 )
 
 // iam-apiserver: secret errors.
@@ -28,4 +37,55 @@ const (
 const (
 	// ErrPolicyNotFound - 404: Policy not found.
 	ErrPolicyNotFound int = iota + 110201
+
+	// ErrAttachmentNotFound - 404: Policy attachment not found.
+	ErrAttachmentNotFound
+
+	// ErrAttachmentAlreadyExist - 400: Policy attachment already exist.
+	ErrAttachmentAlreadyExist
+)
+
+// iam-apiserver: group errors.
+const (
+	// ErrGroupNotFound - 404: Group not found.
+	ErrGroupNotFound int = iota + 110301
+
+	// ErrGroupAlreadyExist - 400: Group already exist.
+	ErrGroupAlreadyExist
+)
+
+// iam-apiserver: admission errors.
+const (
+	// ErrAdmissionDenied - 403: Request is denied by an admission hook.
+	ErrAdmissionDenied int = iota + 110401
+)
+
+// iam-apiserver: quota errors.
+const (
+	// ErrQuotaNotFound - 404: Quota not found.
+	ErrQuotaNotFound int = iota + 110501
+
+	// ErrUserQuotaExceeded - 403: Tenant has reached the quota of users.
+	ErrUserQuotaExceeded
+
+	// ErrSecretQuotaExceeded - 403: User has reached the quota of secrets.
+	ErrSecretQuotaExceeded
+
+	// ErrPolicyQuotaExceeded - 403: Tenant has reached the quota of policies.
+	ErrPolicyQuotaExceeded
+
+	// ErrPolicySizeExceeded - 403: Policy exceeds the size quota of the tenant.
+	ErrPolicySizeExceeded
+)
+
+// iam-apiserver: operation errors.
+const (
+	// ErrOperationNotFound - 404: Operation not found.
+	ErrOperationNotFound int = iota + 110601
+)
+
+// iam-apiserver: snapshot errors.
+const (
+	// ErrInvalidSnapshot - 400: Snapshot is invalid, truncated or can not be decrypted.
+	ErrInvalidSnapshot int = iota + 110701
 )
@@ -7,4 +7,7 @@ package code
 //go:generate codegen -type=int
 
 // iam-authz-server: authorize errors.
-const ()
+const (
+	// ErrOutOfScope - 403: Request is out of the secret scope.
+	ErrOutOfScope int = iota + 120001
+)
@@ -27,12 +27,18 @@ const (
 
 	// ErrPageNotFound - 404: Page not found.
 	ErrPageNotFound
+
+	// ErrTooManyRequests - 429: Too many requests.
+	ErrTooManyRequests
 )
 
 // common: database errors.
 const (
 	// ErrDatabase - 500: Database error.
 	ErrDatabase int = iota + 100101
+
+	// ErrConflict - 409: Resource has been modified by another request.
+	ErrConflict
 )
 
 // common: authorization and authentication errors.
@@ -57,6 +63,12 @@ const (
 
 	// PermissionDenied - 403: Permission denied.
 	ErrPermissionDenied
+
+	// ErrTokenRevoked - 401: Token has been revoked.
+	ErrTokenRevoked
+
+	// ErrPasswordExpired - 403: Password has expired and must be changed.
+	ErrPasswordExpired
 )
 
 // common: encode/decode errors.
@@ -6,6 +6,8 @@ package code
 
 import (
 	"net/http"
+	"sort"
+	"sync"
 
 	"github.com/marmotedu/errors"
 	"github.com/novalagung/gubrak"
@@ -14,20 +16,26 @@ import (
 // ErrCode implements `github.com/marmotedu/errors`.Coder interface.
 type ErrCode struct {
 	// C refers to the code of the ErrCode.
-	C int
+	C int `json:"code"`
 
 	// HTTP status that should be used for the associated error code.
-	HTTP int
+	HTTP int `json:"http"`
 
 	// External (user) facing error text.
-	Ext string
+	Ext string `json:"message"`
 
 	// Ref specify the reference document.
-	Ref string
+	Ref string `json:"reference,omitempty"`
 }
 
 var _ errors.Coder = &ErrCode{}
 
+var (
+	// registered holds the registered error codes, `github.com/marmotedu/errors` can not list them.
+	registered   = map[int]ErrCode{}
+	registeredMu sync.RWMutex
+)
+
 // Code returns the integer code of ErrCode.
 func (coder ErrCode) Code() int {
 	return coder.C
@@ -56,9 +64,9 @@ func (coder ErrCode) HTTPStatus() int {
 
 // nolint: unparam
 func register(code int, httpStatus int, message string, refs ...string) {
-	found, _ := gubrak.Includes([]int{200, 400, 401, 403, 404, 500}, httpStatus)
+	found, _ := gubrak.Includes([]int{200, 400, 401, 403, 404, 409, 429, 500}, httpStatus)
 	if !found {
-		panic("http code not in `200, 400, 401, 403, 404, 500`")
+		panic("http code not in `200, 400, 401, 403, 404, 409, 429, 500`")
 	}
 
 	var reference string
@@ -74,4 +82,22 @@ func register(code int, httpStatus int, message string, refs ...string) {
 	}
 
 	errors.MustRegister(coder)
+
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered[code] = *coder
+}
+
+// List returns every registered error code, sorted by code.
+func List() []ErrCode {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	codes := make([]ErrCode, 0, len(registered))
+	for _, coder := range registered {
+		codes = append(codes, coder)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].C < codes[j].C })
+
+	return codes
 }
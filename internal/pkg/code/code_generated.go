@@ -10,16 +10,34 @@ package code
 func init() {
 	register(ErrUserNotFound, 404, "User not found")
 	register(ErrUserAlreadyExist, 400, "User already exist")
+	register(ErrUserSuspended, 403, "User is suspended")
+	register(ErrUserDeactivated, 403, "User is deactivated")
+	register(ErrUserStateTransition, 409, "User can not move to the requested state")
 	register(ErrReachMaxCount, 400, "Secret reach the max count")
 	register(ErrSecretNotFound, 404, "Secret not found")
 	register(ErrPolicyNotFound, 404, "Policy not found")
+	register(ErrAttachmentNotFound, 404, "Policy attachment not found")
+	register(ErrAttachmentAlreadyExist, 400, "Policy attachment already exist")
+	register(ErrGroupNotFound, 404, "Group not found")
+	register(ErrGroupAlreadyExist, 400, "Group already exist")
+	register(ErrAdmissionDenied, 403, "Request is denied by an admission hook")
+	register(ErrQuotaNotFound, 404, "Quota not found")
+	register(ErrUserQuotaExceeded, 403, "Tenant has reached the quota of users")
+	register(ErrSecretQuotaExceeded, 403, "User has reached the quota of secrets")
+	register(ErrPolicyQuotaExceeded, 403, "Tenant has reached the quota of policies")
+	register(ErrPolicySizeExceeded, 403, "Policy exceeds the size quota of the tenant")
+	register(ErrOperationNotFound, 404, "Operation not found")
+	register(ErrInvalidSnapshot, 400, "Snapshot is invalid, truncated or can not be decrypted")
+	register(ErrOutOfScope, 403, "Request is out of the secret scope")
 	register(ErrSuccess, 200, "OK")
 	register(ErrUnknown, 500, "Internal server error")
 	register(ErrBind, 400, "Error occurred while binding the request body to the struct")
 	register(ErrValidation, 400, "Validation failed")
 	register(ErrTokenInvalid, 401, "Token invalid")
 	register(ErrPageNotFound, 404, "Page not found")
+	register(ErrTooManyRequests, 429, "Too many requests")
 	register(ErrDatabase, 500, "Database error")
+	register(ErrConflict, 409, "Resource has been modified by another request")
 	register(ErrEncrypt, 401, "Error occurred while encrypting the user password")
 	register(ErrSignatureInvalid, 401, "Signature is invalid")
 	register(ErrExpired, 401, "Token expired")
@@ -27,6 +45,8 @@ func init() {
 	register(ErrMissingHeader, 401, "The `Authorization` header was empty")
 	register(ErrPasswordIncorrect, 401, "Password was incorrect")
 	register(ErrPermissionDenied, 403, "Permission denied")
+	register(ErrTokenRevoked, 401, "Token has been revoked")
+	register(ErrPasswordExpired, 403, "Password has expired and must be changed")
 	register(ErrEncodingFailed, 500, "Encoding failed due to an error with the data")
 	register(ErrDecodingFailed, 500, "Decoding failed due to an error with the data")
 	register(ErrInvalidJSON, 500, "Data is not valid JSON")
@@ -0,0 +1,148 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage is the language of the external messages the error codes are registered with.
+var DefaultLanguage = language.AmericanEnglish
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// CatalogLoader loads the translated external messages of the error codes, by language
+// and by code.
+type CatalogLoader interface {
+	Load() (map[language.Tag]map[int]string, error)
+}
+
+// FSLoader loads the translated external messages from the <language>.yaml files of a file
+// system, e.g. zh-CN.yaml, each of them mapping the codes to the messages.
+type FSLoader struct {
+	FS fs.FS
+}
+
+var _ CatalogLoader = FSLoader{}
+
+// Load implements CatalogLoader.
+func (l FSLoader) Load() (map[language.Tag]map[int]string, error) {
+	files, err := fs.Glob(l.FS, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	catalogs := make(map[language.Tag]map[int]string, len(files))
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(file), ".yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid language of %s: %w", file, err)
+		}
+
+		data, err := fs.ReadFile(l.FS, file)
+		if err != nil {
+			return nil, err
+		}
+
+		messages := map[int]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("invalid catalog %s: %w", file, err)
+		}
+		catalogs[tag] = messages
+	}
+
+	return catalogs, nil
+}
+
+// catalog holds the translated external messages of the error codes.
+type catalog struct {
+	lock     sync.RWMutex
+	messages map[language.Tag]map[int]string
+	// tags are the supported languages, the default one first, matched by matcher.
+	tags    []language.Tag
+	matcher language.Matcher
+}
+
+var messageCatalog = &catalog{
+	messages: map[language.Tag]map[int]string{},
+	tags:     []language.Tag{DefaultLanguage},
+	matcher:  language.NewMatcher([]language.Tag{DefaultLanguage}),
+}
+
+//nolint: gochecknoinits
+func init() {
+	embedded, _ := fs.Sub(locales, "locales")
+	if err := LoadCatalog(FSLoader{FS: embedded}); err != nil {
+		panic(err)
+	}
+}
+
+// LoadCatalog adds the messages loaded by the loader to the catalog, they replace the ones
+// already loaded for the same language and code.
+func LoadCatalog(loader CatalogLoader) error {
+	catalogs, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	c := messageCatalog
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for tag, messages := range catalogs {
+		if _, ok := c.messages[tag]; !ok {
+			c.messages[tag] = make(map[int]string, len(messages))
+			c.tags = append(c.tags, tag)
+		}
+		for code, message := range messages {
+			c.messages[tag][code] = message
+		}
+	}
+	c.matcher = language.NewMatcher(c.tags)
+
+	return nil
+}
+
+// Translate returns the external message of the error code in the language best matching
+// the Accept-Language header, and the language it is in. Only the registered message of the
+// code is translated, a more specific message is returned as is.
+func Translate(code int, message string, acceptLanguage string) (string, language.Tag) {
+	registeredMu.RLock()
+	coder, ok := registered[code]
+	registeredMu.RUnlock()
+	if !ok || coder.Ext != message {
+		return message, DefaultLanguage
+	}
+
+	accepted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(accepted) == 0 {
+		return message, DefaultLanguage
+	}
+
+	c := messageCatalog
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, index, confidence := c.matcher.Match(accepted...)
+	if confidence == language.No {
+		return message, DefaultLanguage
+	}
+
+	tag := c.tags[index]
+	if translated, ok := c.messages[tag][code]; ok {
+		return translated, tag
+	}
+
+	return message, DefaultLanguage
+}
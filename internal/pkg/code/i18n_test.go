@@ -0,0 +1,54 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/text/language"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name           string
+		message        string
+		acceptLanguage string
+		want           string
+		wantTag        language.Tag
+	}{
+		{"chinese", "User not found", "zh-CN,zh;q=0.9,en;q=0.8", "用户不存在", language.MustParse("zh-CN")},
+		{"chinese without region", "User not found", "zh", "用户不存在", language.MustParse("zh-CN")},
+		{"english", "User not found", "en-GB", "User not found", DefaultLanguage},
+		{"unsupported", "User not found", "fr-FR", "User not found", DefaultLanguage},
+		{"invalid header", "User not found", ";;", "User not found", DefaultLanguage},
+		{"specific message", "User colin not found", "zh-CN", "User colin not found", DefaultLanguage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, tag := Translate(ErrUserNotFound, tt.message, tt.acceptLanguage)
+			if got != tt.want || tag != tt.wantTag {
+				t.Errorf("Translate() = %q, %v, want %q, %v", got, tag, tt.want, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	loader := FSLoader{FS: fstest.MapFS{
+		"zh-TW.yaml": {Data: []byte("110001: 使用者不存在\n")},
+	}}
+	if err := LoadCatalog(loader); err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+
+	if got, _ := Translate(ErrUserNotFound, "User not found", "zh-TW"); got != "使用者不存在" {
+		t.Errorf("Translate() = %q, want %q", got, "使用者不存在")
+	}
+
+	if got, _ := Translate(ErrUserNotFound, "User not found", "zh-CN"); got != "用户不存在" {
+		t.Errorf("Translate() = %q, want %q", got, "用户不存在")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package condition
+
+import (
+	"net"
+	"regexp"
+	"sync"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+)
+
+// StringMatchCondition replaces the ladon condition of the same name, which compiles its
+// pattern every time it is evaluated. The pattern is compiled once, when the policy is
+// decoded, or when the condition is evaluated for the first time.
+type StringMatchCondition struct {
+	ladon.StringMatchCondition
+
+	once sync.Once
+	re   *regexp.Regexp
+}
+
+// UnmarshalJSON decodes the condition options and compiles the pattern.
+func (c *StringMatchCondition) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.StringMatchCondition); err != nil {
+		return err
+	}
+
+	c.regexp()
+
+	return nil
+}
+
+// Fulfills returns true if the given value is a string and matches the pattern.
+func (c *StringMatchCondition) Fulfills(value interface{}, _ *ladon.Request) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	re := c.regexp()
+
+	return re != nil && re.MatchString(s)
+}
+
+// regexp returns the compiled pattern, or nil when it is invalid.
+func (c *StringMatchCondition) regexp() *regexp.Regexp {
+	c.once.Do(func() {
+		c.re, _ = regexp.Compile(c.Matches)
+	})
+
+	return c.re
+}
+
+// CIDRCondition replaces the ladon condition of the same name, which parses its cidr every
+// time it is evaluated. The cidr is parsed once, like the pattern of StringMatchCondition.
+type CIDRCondition struct {
+	ladon.CIDRCondition
+
+	once  sync.Once
+	ipnet *net.IPNet
+}
+
+// UnmarshalJSON decodes the condition options and parses the cidr.
+func (c *CIDRCondition) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.CIDRCondition); err != nil {
+		return err
+	}
+
+	c.ipNet()
+
+	return nil
+}
+
+// Fulfills returns true if the given value is an ip address in the cidr.
+func (c *CIDRCondition) Fulfills(value interface{}, _ *ladon.Request) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	ipNet := c.ipNet()
+	if ipNet == nil {
+		return false
+	}
+
+	ip := net.ParseIP(s)
+
+	return ip != nil && ipNet.Contains(ip)
+}
+
+// ipNet returns the parsed cidr, or nil when it is invalid.
+func (c *CIDRCondition) ipNet() *net.IPNet {
+	c.once.Do(func() {
+		_, c.ipnet, _ = net.ParseCIDR(c.CIDR)
+	})
+
+	return c.ipnet
+}
+
+// nolint: gochecknoinits
+func init() {
+	ladon.ConditionFactories[new(StringMatchCondition).GetName()] = func() ladon.Condition {
+		return new(StringMatchCondition)
+	}
+	ladon.ConditionFactories[new(CIDRCondition).GetName()] = func() ladon.Condition {
+		return new(CIDRCondition)
+	}
+}
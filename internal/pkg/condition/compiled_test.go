@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package condition
+
+import (
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+)
+
+func TestCompiledConditions(t *testing.T) {
+	policy := `{"conditions":{
+		"owner":{"type":"StringMatchCondition","options":{"matches":"^colin-[0-9]+$"}},
+		"remoteIPAddress":{"type":"CIDRCondition","options":{"cidr":"192.168.0.1/16"}},
+		"invalid":{"type":"StringMatchCondition","options":{"matches":"("}}
+	}}`
+
+	var p ladon.DefaultPolicy
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		condition ladon.Condition
+		value     interface{}
+		want      bool
+	}{
+		{name: "match", condition: p.Conditions["owner"], value: "colin-1", want: true},
+		{name: "no match", condition: p.Conditions["owner"], value: "peter-1", want: false},
+		{name: "not a string", condition: p.Conditions["owner"], value: 1, want: false},
+		{name: "invalid pattern", condition: p.Conditions["invalid"], value: "(", want: false},
+		{name: "in cidr", condition: p.Conditions["remoteIPAddress"], value: "192.168.10.1", want: true},
+		{name: "out of cidr", condition: p.Conditions["remoteIPAddress"], value: "10.0.0.1", want: false},
+		{name: "invalid ip", condition: p.Conditions["remoteIPAddress"], value: "localhost", want: false},
+		{
+			name:      "not decoded",
+			condition: &CIDRCondition{CIDRCondition: ladon.CIDRCondition{CIDR: "10.0.0.0/8"}},
+			value:     "10.0.0.1",
+			want:      true,
+		},
+		{name: "invalid cidr", condition: &CIDRCondition{}, value: "10.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Fulfills(tt.value, &ladon.Request{}); got != tt.want {
+				t.Errorf("%s.Fulfills(%v) = %v, want %v", tt.condition.GetName(), tt.value, got, tt.want)
+			}
+		})
+	}
+
+	// the options are encoded as the ladon ones
+	data, err := json.Marshal(ladon.Conditions{"owner": p.Conditions["owner"]})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"owner":{"type":"StringMatchCondition","options":{"matches":"^colin-[0-9]+$"}}}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func BenchmarkStringMatchCondition_Fulfills(b *testing.B) {
+	benchmarks := []struct {
+		name      string
+		condition ladon.Condition
+	}{
+		{name: "ladon", condition: &ladon.StringMatchCondition{Matches: "^colin-[0-9]+$"}},
+		{name: "compiled", condition: &StringMatchCondition{StringMatchCondition: ladon.StringMatchCondition{
+			Matches: "^colin-[0-9]+$",
+		}}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.condition.Fulfills("colin-1", nil)
+			}
+		})
+	}
+}
+
+func BenchmarkCIDRCondition_Fulfills(b *testing.B) {
+	benchmarks := []struct {
+		name      string
+		condition ladon.Condition
+	}{
+		{name: "ladon", condition: &ladon.CIDRCondition{CIDR: "192.168.0.1/16"}},
+		{name: "compiled", condition: &CIDRCondition{CIDRCondition: ladon.CIDRCondition{CIDR: "192.168.0.1/16"}}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.condition.Fulfills("192.168.10.1", nil)
+			}
+		})
+	}
+}
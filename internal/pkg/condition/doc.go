@@ -0,0 +1,10 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package condition registers the iam specific ladon conditions, e.g. the redis
+// backed RateLimitCondition, or the
+// TagEqualsCondition and TagInCondition on the tags of the caller and the resource. Both iam-apiserver and iam-authz-server import it so
+// that policies using these conditions can be decoded. It also replaces the ladon
+// StringMatchCondition and CIDRCondition with ones compiled once per policy.
+package condition // import "github.com/marmotedu/iam/internal/pkg/condition"
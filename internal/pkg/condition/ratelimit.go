@@ -0,0 +1,74 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package condition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// RateLimitKeyPrefix defines the prefix of the redis keys used to count the requests.
+const RateLimitKeyPrefix = "iam.ratelimit."
+
+// Counter increments a redis counter which expires after expire seconds.
+type Counter interface {
+	IncrememntWithExpire(keyName string, expire int64) int64
+}
+
+var counter Counter = &storage.RedisCluster{}
+
+// RateLimitCondition is fulfilled as long as the subject has not performed the
+// action more than Limit times in the current fixed window of Period seconds, e.g.
+// {"type":"RateLimitCondition","options":{"limit":100,"period":3600}} allows at most
+// 100 requests per hour for every subject and action.
+// Requests are counted in redis each time the condition is evaluated. Policies sharing
+// the same Key share the same counters.
+type RateLimitCondition struct {
+	Limit  int64  `json:"limit"`
+	Period int64  `json:"period"`
+	Key    string `json:"key,omitempty"`
+}
+
+// GetName returns the condition's name.
+func (c *RateLimitCondition) GetName() string {
+	return "RateLimitCondition"
+}
+
+// Fulfills returns true if the request is within the limit. The condition is not
+// fulfilled when the counter can not be incremented.
+func (c *RateLimitCondition) Fulfills(_ interface{}, r *ladon.Request) bool {
+	if c.Limit <= 0 || c.Period <= 0 {
+		return false
+	}
+
+	count := counter.IncrememntWithExpire(c.counterKey(r, time.Now()), c.Period)
+
+	return count > 0 && count <= c.Limit
+}
+
+func (c *RateLimitCondition) counterKey(r *ladon.Request, now time.Time) string {
+	window := now.Unix() / c.Period
+
+	return fmt.Sprintf("%s%s:%s:%s:%d", RateLimitKeyPrefix, c.Key, r.Subject, r.Action, window)
+}
+
+// IsStateful reports whether the result of the condition depends on state outside of the
+// request, e.g. a redis counter, so that decisions involving it must not be reused.
+func IsStateful(c ladon.Condition) bool {
+	_, ok := c.(*RateLimitCondition)
+
+	return ok
+}
+
+// nolint: gochecknoinits
+func init() {
+	ladon.ConditionFactories[new(RateLimitCondition).GetName()] = func() ladon.Condition {
+		return new(RateLimitCondition)
+	}
+}
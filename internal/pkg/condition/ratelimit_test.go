@@ -0,0 +1,91 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package condition
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+)
+
+type fakeCounter map[string]int64
+
+func (f fakeCounter) IncrememntWithExpire(keyName string, _ int64) int64 {
+	f[keyName]++
+
+	return f[keyName]
+}
+
+func TestRateLimitCondition_Unmarshal(t *testing.T) {
+	policy := `{"conditions":{"quota":{"type":"RateLimitCondition","options":{"limit":2,"period":3600}}}}`
+
+	var p ladon.DefaultPolicy
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := &RateLimitCondition{Limit: 2, Period: 3600}
+	if got := p.Conditions["quota"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Conditions[quota] = %v, want %v", got, want)
+	}
+}
+
+func TestRateLimitCondition_Fulfills(t *testing.T) {
+	counter = fakeCounter{}
+	defer func() { counter = fakeCounter{} }()
+
+	tests := []struct {
+		name      string
+		condition *RateLimitCondition
+		request   *ladon.Request
+		want      bool
+	}{
+		{
+			name:      "first",
+			condition: &RateLimitCondition{Limit: 2, Period: 3600},
+			request:   &ladon.Request{Subject: "users:peter", Action: "delete"},
+			want:      true,
+		},
+		{
+			name:      "second",
+			condition: &RateLimitCondition{Limit: 2, Period: 3600},
+			request:   &ladon.Request{Subject: "users:peter", Action: "delete"},
+			want:      true,
+		},
+		{
+			name:      "exceeded",
+			condition: &RateLimitCondition{Limit: 2, Period: 3600},
+			request:   &ladon.Request{Subject: "users:peter", Action: "delete"},
+			want:      false,
+		},
+		{
+			name:      "another_subject",
+			condition: &RateLimitCondition{Limit: 2, Period: 3600},
+			request:   &ladon.Request{Subject: "users:ken", Action: "delete"},
+			want:      true,
+		},
+		{
+			name:      "another_key",
+			condition: &RateLimitCondition{Limit: 2, Period: 3600, Key: "printer"},
+			request:   &ladon.Request{Subject: "users:peter", Action: "delete"},
+			want:      true,
+		},
+		{
+			name:      "invalid",
+			condition: &RateLimitCondition{Limit: 0, Period: 3600},
+			request:   &ladon.Request{Subject: "users:peter", Action: "print"},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Fulfills(nil, tt.request); got != tt.want {
+				t.Errorf("RateLimitCondition.Fulfills() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package condition
+
+import (
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/tags"
+)
+
+// TagEqualsCondition is fulfilled when the tags under the condition key, usually
+// resourceTags or callerTags, have the tag Key equal to exactly one of:
+//   - Value, a literal value;
+//   - the tag CallerTag of the caller, e.g. the resources of the caller's team;
+//   - the request subject when Subject is set, e.g.
+//     {"resourceTags":{"type":"TagEqualsCondition","options":{"key":"owner","subject":true}}}
+//     lets the subjects manage the resources tagged with their own name.
+//
+// A missing tag, or an empty value to compare to, never fulfills the condition.
+type TagEqualsCondition struct {
+	Key       string `json:"key"`
+	Value     string `json:"value,omitempty"`
+	CallerTag string `json:"callerTag,omitempty"`
+	Subject   bool   `json:"subject,omitempty"`
+}
+
+// GetName returns the condition's name.
+func (c *TagEqualsCondition) GetName() string {
+	return "TagEqualsCondition"
+}
+
+// Fulfills returns true if the tag of the given tags equals the expected value.
+func (c *TagEqualsCondition) Fulfills(value interface{}, r *ladon.Request) bool {
+	t, ok := tags.FromValue(value)
+	if !ok {
+		return false
+	}
+
+	actual, ok := t[c.Key]
+	if !ok {
+		return false
+	}
+
+	expected, ok := c.expected(r)
+
+	return ok && expected != "" && actual == expected
+}
+
+// expected returns the value the tag is compared to, false when the options do not name
+// exactly one.
+func (c *TagEqualsCondition) expected(r *ladon.Request) (string, bool) {
+	switch {
+	case c.Value != "" && c.CallerTag == "" && !c.Subject:
+		return c.Value, true
+	case c.CallerTag != "" && c.Value == "" && !c.Subject:
+		caller, _ := tags.FromValue(r.Context[tags.CallerKey])
+
+		return caller[c.CallerTag], true
+	case c.Subject && c.Value == "" && c.CallerTag == "":
+		return r.Subject, true
+	default:
+		return "", false
+	}
+}
+
+// TagInCondition is fulfilled when the tags under the condition key have the tag Key equal
+// to one of Values, e.g.
+// {"resourceTags":{"type":"TagInCondition","options":{"key":"env","values":["dev","test"]}}}.
+type TagInCondition struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// GetName returns the condition's name.
+func (c *TagInCondition) GetName() string {
+	return "TagInCondition"
+}
+
+// Fulfills returns true if the tag of the given tags is one of the values.
+func (c *TagInCondition) Fulfills(value interface{}, _ *ladon.Request) bool {
+	t, ok := tags.FromValue(value)
+	if !ok {
+		return false
+	}
+
+	actual, ok := t[c.Key]
+	if !ok {
+		return false
+	}
+
+	for _, v := range c.Values {
+		if v == actual {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nolint: gochecknoinits
+func init() {
+	ladon.ConditionFactories[new(TagEqualsCondition).GetName()] = func() ladon.Condition {
+		return new(TagEqualsCondition)
+	}
+	ladon.ConditionFactories[new(TagInCondition).GetName()] = func() ladon.Condition {
+		return new(TagInCondition)
+	}
+}
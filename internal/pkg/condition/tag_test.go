@@ -0,0 +1,65 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package condition
+
+import (
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+
+	"github.com/marmotedu/iam/internal/pkg/tags"
+)
+
+func TestTagEqualsCondition_Fulfills(t *testing.T) {
+	request := &ladon.Request{
+		Subject: "users:peter",
+		Context: ladon.Context{
+			tags.CallerKey: tags.Tags{"team": "payments"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		condition *TagEqualsCondition
+		value     interface{}
+		want      bool
+	}{
+		{"value", &TagEqualsCondition{Key: "env", Value: "dev"}, tags.Tags{"env": "dev"}, true},
+		{"other value", &TagEqualsCondition{Key: "env", Value: "dev"}, tags.Tags{"env": "prod"}, false},
+		{"missing tag", &TagEqualsCondition{Key: "env", Value: "dev"}, tags.Tags{}, false},
+		{"decoded json", &TagEqualsCondition{Key: "env", Value: "dev"}, map[string]interface{}{"env": "dev"}, true},
+		{"not tags", &TagEqualsCondition{Key: "env", Value: "dev"}, "env=dev", false},
+		{"caller tag", &TagEqualsCondition{Key: "team", CallerTag: "team"}, tags.Tags{"team": "payments"}, true},
+		{"missing caller tag", &TagEqualsCondition{Key: "team", CallerTag: "org"}, tags.Tags{"team": ""}, false},
+		{"subject", &TagEqualsCondition{Key: "owner", Subject: true}, tags.Tags{"owner": "users:peter"}, true},
+		{"other subject", &TagEqualsCondition{Key: "owner", Subject: true}, tags.Tags{"owner": "users:ken"}, false},
+		{"ambiguous", &TagEqualsCondition{Key: "owner", Value: "users:peter", Subject: true}, tags.Tags{"owner": "users:peter"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Fulfills(tt.value, request); got != tt.want {
+				t.Errorf("TagEqualsCondition.Fulfills() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagInCondition(t *testing.T) {
+	policy := `{"conditions":{"resourceTags":{"type":"TagInCondition","options":{"key":"env","values":["dev","test"]}}}}`
+
+	var p ladon.DefaultPolicy
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	condition := p.Conditions["resourceTags"]
+	if !condition.Fulfills(map[string]interface{}{"env": "test"}, &ladon.Request{}) {
+		t.Error("TagInCondition.Fulfills() = false on a listed value")
+	}
+	if condition.Fulfills(map[string]interface{}{"env": "prod"}, &ladon.Request{}) {
+		t.Error("TagInCondition.Fulfills() = true on an unlisted value")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Supported connector types.
+const (
+	TypeGitHub = "github"
+	TypeGoogle = "google"
+	TypeOIDC   = "oidc"
+)
+
+// ErrNotAllowed is returned when an identity is not allowed to log in by the connector rules.
+var ErrNotAllowed = errors.New("identity is not allowed to log in")
+
+// Identity is a user authenticated by an upstream identity provider.
+type Identity struct {
+	// Subject is the immutable id of the user in the identity provider.
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Connector authenticates users with the OAuth 2.0 authorization code flow of an
+// identity provider.
+type Connector interface {
+	// LoginURL returns the authorization url of the identity provider, the user agent is
+	// redirected back with the state and the authorization code.
+	LoginURL(state string) (string, error)
+	// HandleCallback exchanges the authorization code and returns the authenticated user.
+	HandleCallback(ctx context.Context, code, state string) (*Identity, error)
+}
+
+// New creates the connector of the provider, the redirect url is the callback registered
+// in the identity provider.
+func New(opts *ProviderOptions, redirectURL string) (Connector, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch opts.Type {
+	case TypeGitHub:
+		return newGitHubConnector(opts, redirectURL, client), nil
+	case TypeGoogle, TypeOIDC:
+		return newOIDCConnector(opts, redirectURL, client), nil
+	default:
+		return nil, fmt.Errorf("unsupported connector type %s", opts.Type)
+	}
+}
+
+// tokenResponse is the response of the OAuth 2.0 token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchange exchanges the authorization code for tokens, the client is authenticated by the
+// client_secret_post method.
+func exchange(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	if err := do(client, req, &token); err != nil && token.Error == "" {
+		return nil, fmt.Errorf("exchange authorization code: %w", err)
+	}
+
+	// GitHub returns the errors with status 200
+	if token.Error != "" {
+		return nil, fmt.Errorf("exchange authorization code: %s %s", token.Error, token.ErrorDescription)
+	}
+
+	if token.AccessToken == "" {
+		return nil, errors.New("exchange authorization code: no access token is returned")
+	}
+
+	return &token, nil
+}
+
+// do sends the request and decodes the json response body into v.
+func do(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	// decode the error responses too, so that callers can report their details
+	decodeErr := json.Unmarshal(body, v)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returns status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+
+	return decodeErr
+}
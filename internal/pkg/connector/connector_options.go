@@ -0,0 +1,147 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package connector
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/spf13/pflag"
+)
+
+// ConnectorOptions contains configuration items related to the connector login.
+type ConnectorOptions struct {
+	ExternalURL string             `json:"external-url" mapstructure:"external-url"`
+	Providers   []*ProviderOptions `json:"providers"    mapstructure:"providers"`
+}
+
+// ProviderOptions contains configuration items of an upstream identity provider.
+type ProviderOptions struct {
+	// ID is used in the connector urls, e.g. /connectors/<id>/login.
+	ID           string   `json:"id"            mapstructure:"id"`
+	Name         string   `json:"name"          mapstructure:"name"`
+	Type         string   `json:"type"          mapstructure:"type"`
+	ClientID     string   `json:"client-id"     mapstructure:"client-id"`
+	ClientSecret string   `json:"-"             mapstructure:"client-secret"`
+	Issuer       string   `json:"issuer"        mapstructure:"issuer"`
+	Scopes       []string `json:"scopes"        mapstructure:"scopes"`
+	// UsernameClaim is the id token claim used as the user name, the domain is removed from
+	// the email addresses.
+	UsernameClaim  string   `json:"username-claim"  mapstructure:"username-claim"`
+	UsernamePrefix string   `json:"username-prefix" mapstructure:"username-prefix"`
+	AllowedDomains []string `json:"allowed-domains" mapstructure:"allowed-domains"`
+	// JITProvisioning creates the iam users on their first login.
+	JITProvisioning bool `json:"jit-provisioning" mapstructure:"jit-provisioning"`
+	// LinkExisting links the existing iam users to the identity with the same verified email.
+	LinkExisting bool `json:"link-existing" mapstructure:"link-existing"`
+}
+
+// NewConnectorOptions creates a ConnectorOptions object with default parameters.
+func NewConnectorOptions() *ConnectorOptions {
+	return &ConnectorOptions{
+		ExternalURL: "",
+		Providers:   []*ProviderOptions{},
+	}
+}
+
+// Enabled reports whether any connector is configured.
+func (o *ConnectorOptions) Enabled() bool {
+	return len(o.Providers) > 0
+}
+
+// RedirectURL returns the callback url of the provider registered in the identity provider.
+func (o *ConnectorOptions) RedirectURL(p *ProviderOptions) string {
+	return strings.TrimSuffix(o.ExternalURL, "/") + "/connectors/" + p.ID + "/callback"
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *ConnectorOptions) Validate() []error {
+	errs := []error{}
+
+	if !o.Enabled() {
+		return errs
+	}
+
+	if u, err := url.Parse(o.ExternalURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("--connectors.external-url must be an absolute url when connectors are configured"))
+	}
+
+	seen := map[string]bool{}
+	for i, p := range o.Providers {
+		for _, msg := range validation.IsQualifiedName(p.ID) {
+			errs = append(errs, fmt.Errorf("connectors.providers[%d].id %s is invalid: %s", i, p.ID, msg))
+		}
+
+		if seen[p.ID] {
+			errs = append(errs, fmt.Errorf("connectors.providers[%d].id %s is duplicated", i, p.ID))
+		}
+		seen[p.ID] = true
+
+		switch p.Type {
+		case TypeGitHub, TypeGoogle:
+		case TypeOIDC:
+			if u, err := url.Parse(p.Issuer); err != nil || u.Scheme != "https" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("connectors.providers[%d].issuer must be a https url", i))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("connectors.providers[%d].type %s is not supported, must be %s, %s or %s",
+				i, p.Type, TypeGitHub, TypeGoogle, TypeOIDC))
+		}
+
+		if p.ClientID == "" || p.ClientSecret == "" {
+			errs = append(errs, fmt.Errorf("connectors.providers[%d].client-id and client-secret must be specified", i))
+		}
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the connector login for a specific api server to the
+// specified FlagSet. The providers can only be configured by the config file.
+func (o *ConnectorOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringVar(&o.ExternalURL, "connectors.external-url", o.ExternalURL, ""+
+		"External url of iam-apiserver, the callback url of a connector is <external-url>/connectors/<id>/callback.")
+}
+
+// DisplayName returns the name of the provider shown to the users.
+func (p *ProviderOptions) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+
+	return p.ID
+}
+
+// Username returns the iam user name of the identity.
+func (p *ProviderOptions) Username(identity *Identity) string {
+	return p.UsernamePrefix + identity.Username
+}
+
+// Allow checks the identity against the allowed email domains of the provider.
+func (p *ProviderOptions) Allow(identity *Identity) error {
+	if len(p.AllowedDomains) == 0 {
+		return nil
+	}
+
+	if !identity.EmailVerified {
+		return fmt.Errorf("%w: email is not verified", ErrNotAllowed)
+	}
+
+	domain := identity.Email[strings.LastIndex(identity.Email, "@")+1:]
+	for _, allowed := range p.AllowedDomains {
+		if strings.EqualFold(domain, allowed) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: email domain %s is not allowed", ErrNotAllowed, domain)
+}
@@ -0,0 +1,282 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package connector
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type fakeProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "good" || r.PostFormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims)
+		token.Header["kid"] = "k1"
+		idToken, _ := token.SignedString(p.key)
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "id_token": idToken})
+	})
+	p.Server = httptest.NewServer(mux)
+
+	return p
+}
+
+func TestOIDCConnector(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.Close()
+
+	c := newOIDCConnector(&ProviderOptions{
+		Type:         TypeOIDC,
+		Issuer:       p.URL,
+		ClientID:     "iam",
+		ClientSecret: "secret",
+	}, "https://iam.example.com/connectors/sso/callback", p.Client())
+
+	loginURL, err := c.LoginURL("state1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(loginURL)
+	if u.Path != "/authorize" || u.Query().Get("nonce") != "state1" || u.Query().Get("client_id") != "iam" {
+		t.Errorf("LoginURL() = %s", loginURL)
+	}
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                p.URL,
+			"aud":                "iam",
+			"sub":                "1234",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"nonce":              "state1",
+			"email":              "colin@example.com",
+			"email_verified":     true,
+			"name":               "Colin",
+			"preferred_username": "colin",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		modify  func(jwt.MapClaims)
+		want    *Identity
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			code: "good",
+			want: &Identity{
+				Subject:       "1234",
+				Username:      "colin",
+				Email:         "colin@example.com",
+				EmailVerified: true,
+				Name:          "Colin",
+			},
+		},
+		{name: "invalid code", code: "bad", wantErr: true},
+		{name: "other issuer", code: "good", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil" }, wantErr: true},
+		{name: "other audience", code: "good", modify: func(c jwt.MapClaims) { c["aud"] = "other" }, wantErr: true},
+		{name: "expired", code: "good", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: true},
+		{name: "no expiration", code: "good", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: true},
+		{name: "nonce mismatch", code: "good", modify: func(c jwt.MapClaims) { c["nonce"] = "other" }, wantErr: true},
+		{name: "no username", code: "good", modify: func(c jwt.MapClaims) { delete(c, "preferred_username") }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.claims = valid()
+			if tt.modify != nil {
+				tt.modify(p.claims)
+			}
+
+			got, err := c.HandleCallback(context.Background(), tt.code, "state1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HandleCallback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleCallback() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCConnector_otherKey(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.Close()
+
+	c := newOIDCConnector(&ProviderOptions{Type: TypeOIDC, Issuer: p.URL, ClientID: "iam", ClientSecret: "secret"},
+		"https://iam.example.com/connectors/sso/callback", p.Client())
+
+	p.claims = jwt.MapClaims{"iss": p.URL, "aud": "iam", "sub": "1", "exp": time.Now().Add(time.Hour).Unix()}
+	// the id token is signed by a key which is not published by the provider
+	p.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+	if _, err := c.HandleCallback(context.Background(), "good", ""); err == nil {
+		t.Error("HandleCallback() accepts an id token signed by an unknown key")
+	}
+}
+
+func TestGitHubConnector(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "good" {
+			// github returns the errors with status 200
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "login": "colin", "name": "Colin"})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"email": "other@example.com", "primary": false, "verified": true},
+			{"email": "colin@example.com", "primary": true, "verified": true},
+		})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	c := newGitHubConnector(&ProviderOptions{Type: TypeGitHub, ClientID: "iam", ClientSecret: "secret"},
+		"https://iam.example.com/connectors/github/callback", s.Client())
+	c.authURL = s.URL + "/login/oauth/authorize"
+	c.tokenURL = s.URL + "/login/oauth/access_token"
+	c.apiURL = s.URL
+
+	loginURL, _ := c.LoginURL("state1")
+	if !strings.HasPrefix(loginURL, c.authURL+"?") || !strings.Contains(loginURL, "state=state1") {
+		t.Errorf("LoginURL() = %s", loginURL)
+	}
+
+	got, err := c.HandleCallback(context.Background(), "good", "state1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Identity{Subject: "42", Username: "colin", Email: "colin@example.com", EmailVerified: true, Name: "Colin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleCallback() = %v, want %v", got, want)
+	}
+
+	if _, err := c.HandleCallback(context.Background(), "bad", "state1"); err == nil {
+		t.Error("HandleCallback() accepts an invalid code")
+	}
+}
+
+func TestProviderOptions_Allow(t *testing.T) {
+	p := &ProviderOptions{AllowedDomains: []string{"example.com"}}
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		wantErr  bool
+	}{
+		{name: "allowed", identity: &Identity{Email: "colin@Example.com", EmailVerified: true}},
+		{name: "unverified", identity: &Identity{Email: "colin@example.com"}, wantErr: true},
+		{name: "other domain", identity: &Identity{Email: "colin@example.com.evil", EmailVerified: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Allow(tt.identity); (err != nil) != tt.wantErr {
+				t.Errorf("Allow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConnectorOptions_Validate(t *testing.T) {
+	valid := &ProviderOptions{ID: "github", Type: TypeGitHub, ClientID: "id", ClientSecret: "secret"}
+
+	tests := []struct {
+		name    string
+		opts    *ConnectorOptions
+		wantErr bool
+	}{
+		{name: "disabled", opts: NewConnectorOptions()},
+		{name: "valid", opts: &ConnectorOptions{ExternalURL: "https://iam.example.com", Providers: []*ProviderOptions{valid}}},
+		{name: "no external url", opts: &ConnectorOptions{Providers: []*ProviderOptions{valid}}, wantErr: true},
+		{
+			name:    "duplicate id",
+			opts:    &ConnectorOptions{ExternalURL: "https://iam.example.com", Providers: []*ProviderOptions{valid, valid}},
+			wantErr: true,
+		},
+		{
+			name: "oidc without issuer",
+			opts: &ConnectorOptions{ExternalURL: "https://iam.example.com", Providers: []*ProviderOptions{
+				{ID: "sso", Type: TypeOIDC, ClientID: "id", ClientSecret: "secret"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unsupported type",
+			opts: &ConnectorOptions{ExternalURL: "https://iam.example.com", Providers: []*ProviderOptions{
+				{ID: "sso", Type: "ldap", ClientID: "id", ClientSecret: "secret"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.opts.Validate(); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("Validate() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package connector implements the upstream identity providers which iam users can log in
+// with, e.g. GitHub, Google or any OpenID Connect provider. The connectors only authenticate
+// the users, the authorization is still decided by the iam policies.
+package connector // import "github.com/marmotedu/iam/internal/pkg/connector"
@@ -0,0 +1,128 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GitHub OAuth app endpoints.
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com"
+)
+
+type githubConnector struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+
+	authURL  string
+	tokenURL string
+	apiURL   string
+}
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func newGitHubConnector(opts *ProviderOptions, redirectURL string, client *http.Client) *githubConnector {
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+
+	return &githubConnector{
+		clientID:     opts.ClientID,
+		clientSecret: opts.ClientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		client:       client,
+		authURL:      githubAuthURL,
+		tokenURL:     githubTokenURL,
+		apiURL:       githubAPIURL,
+	}
+}
+
+func (g *githubConnector) LoginURL(state string) (string, error) {
+	query := url.Values{
+		"client_id":    {g.clientID},
+		"redirect_uri": {g.redirectURL},
+		"scope":        {strings.Join(g.scopes, " ")},
+		"state":        {state},
+	}
+
+	return g.authURL + "?" + query.Encode(), nil
+}
+
+func (g *githubConnector) HandleCallback(ctx context.Context, code, state string) (*Identity, error) {
+	token, err := exchange(ctx, g.client, g.tokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {g.redirectURL},
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := g.get(ctx, token.AccessToken, "/user", &user); err != nil {
+		return nil, err
+	}
+
+	if user.ID == 0 || user.Login == "" {
+		return nil, errors.New("github returns an invalid user")
+	}
+
+	identity := &Identity{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+		Name:     user.Name,
+	}
+
+	// the public email of the profile may be unverified, use the primary email instead
+	var emails []githubEmail
+	if err := g.get(ctx, token.AccessToken, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (g *githubConnector) get(ctx context.Context, accessToken, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	return do(g.client, req, v)
+}
@@ -0,0 +1,298 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package connector
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// googleIssuer is the issuer of the Google OpenID Connect provider.
+const googleIssuer = "https://accounts.google.com"
+
+// jwksRefreshInterval limits how often the signing keys are fetched for unknown key ids.
+const jwksRefreshInterval = 5 * time.Minute
+
+// oidcConnector is an OpenID Connect relying party, the identity is read from the claims of
+// the id token which must be signed by a key of the provider.
+type oidcConnector struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	redirectURL   string
+	scopes        []string
+	usernameClaim string
+	client        *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// discovery is the subset of the OpenID provider metadata used by the connector.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func newOIDCConnector(opts *ProviderOptions, redirectURL string, client *http.Client) *oidcConnector {
+	c := &oidcConnector{
+		issuer:        strings.TrimSuffix(opts.Issuer, "/"),
+		clientID:      opts.ClientID,
+		clientSecret:  opts.ClientSecret,
+		redirectURL:   redirectURL,
+		scopes:        opts.Scopes,
+		usernameClaim: opts.UsernameClaim,
+		client:        client,
+	}
+
+	if opts.Type == TypeGoogle && c.issuer == "" {
+		c.issuer = googleIssuer
+	}
+
+	if len(c.scopes) == 0 {
+		c.scopes = []string{"openid", "email", "profile"}
+	}
+
+	if c.usernameClaim == "" {
+		c.usernameClaim = "preferred_username"
+		if opts.Type == TypeGoogle {
+			c.usernameClaim = "email"
+		}
+	}
+
+	return c
+}
+
+func (o *oidcConnector) LoginURL(state string) (string, error) {
+	d, err := o.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {o.clientID},
+		"redirect_uri":  {o.redirectURL},
+		"scope":         {strings.Join(o.scopes, " ")},
+		"state":         {state},
+		// the state is used as the nonce too, it is random and consumed once by the callback
+		"nonce": {state},
+	}
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return d.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+func (o *oidcConnector) HandleCallback(ctx context.Context, code, state string) (*Identity, error) {
+	d, err := o.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchange(ctx, o.client, d.TokenEndpoint, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.redirectURL},
+		"client_id":     {o.clientID},
+		"client_secret": {o.clientSecret},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("no id token is returned")
+	}
+
+	claims, err := o.verify(token.IDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if nonce, _ := claims["nonce"].(string); nonce != state {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	identity := &Identity{}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+
+	// some providers return email_verified as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	identity.Username, _ = claims[o.usernameClaim].(string)
+	if i := strings.Index(identity.Username, "@"); i >= 0 {
+		identity.Username = identity.Username[:i]
+	}
+
+	if identity.Subject == "" || identity.Username == "" {
+		return nil, fmt.Errorf("invalid id token: sub or %s claim is missing", o.usernameClaim)
+	}
+
+	return identity, nil
+}
+
+// verify verifies the signature, the issuer, the audience and the expiration of the id token.
+func (o *oidcConnector) verify(idToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}))
+
+	if _, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return o.getKey(kid)
+	}); err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(o.issuer, true) {
+		return nil, errors.New("issuer mismatch")
+	}
+
+	if !claims.VerifyAudience(o.clientID, true) {
+		return nil, errors.New("audience mismatch")
+	}
+
+	// MapClaims.Valid only checks the expiration when it is present
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("exp claim is missing")
+	}
+
+	return claims, nil
+}
+
+func (o *oidcConnector) getDiscovery() (*discovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.discovery != nil {
+		return o.discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, o.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var d discovery
+	if err := do(o.client, req, &d); err != nil {
+		return nil, fmt.Errorf("discover openid provider %s: %w", o.issuer, err)
+	}
+
+	if strings.TrimSuffix(d.Issuer, "/") != o.issuer {
+		return nil, fmt.Errorf("discover openid provider %s: issuer mismatch %s", o.issuer, d.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("discover openid provider %s: endpoints are missing", o.issuer)
+	}
+
+	o.discovery = &d
+
+	return o.discovery, nil
+}
+
+// getKey returns the signing key of the kid, the keys are fetched again when the kid is
+// unknown so that the key rotations of the provider are picked up.
+func (o *oidcConnector) getKey(kid string) (*rsa.PublicKey, error) {
+	d, err := o.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if key, ok := o.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(o.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := do(o.client, req, &jwks); err != nil {
+		return nil, fmt.Errorf("fetch signing keys: %w", err)
+	}
+
+	o.keys = make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	o.fetchedAt = time.Now()
+
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		if key, err := parseRSAKey(k); err == nil {
+			o.keys[k.Kid] = key
+		}
+	}
+
+	if key, ok := o.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %s", kid)
+}
+
+func parseRSAKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package faultinjection injects latency, errors and connection resets into a percentage of the
+// requests, so that the clients of a server can verify their retries and fallbacks against it.
+// It is meant for the resilience tests only and is disabled by default.
+package faultinjection // import "github.com/marmotedu/iam/internal/pkg/faultinjection"
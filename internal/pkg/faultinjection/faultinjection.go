@@ -0,0 +1,94 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package faultinjection
+
+import (
+	"crypto/tls"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Inject returns a middleware injecting the faults of the rules matching the route of the
+// request. The requests of the unknown routes are left alone.
+func Inject(opts *FaultInjectionOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		for _, rule := range opts.Rules {
+			if !rule.match(route) || rand.Float64()*100 >= rule.Percentage { // nolint: gosec
+				continue
+			}
+
+			if !inject(c, rule) {
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// inject injects the faults of the rule into the request, it reports whether the request is
+// still to be served.
+func inject(c *gin.Context, rule *RuleOptions) bool {
+	if rule.Delay > 0 {
+		log.L(c).Infof("Inject %s delay into %s", rule.Delay, c.Request.URL.Path)
+
+		timer := time.NewTimer(rule.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-c.Request.Context().Done():
+			c.Abort()
+
+			return false
+		}
+	}
+
+	switch {
+	case rule.Reset:
+		log.L(c).Infof("Inject connection reset into %s", c.Request.URL.Path)
+		reset(c)
+		c.Abort()
+
+		return false
+	case rule.Code != 0:
+		log.L(c).Infof("Inject error code %d into %s", rule.Code, c.Request.URL.Path)
+		core.WriteResponse(c, errors.WithCode(rule.Code, "fault injected by %s", rule.Path), nil)
+		c.Abort()
+
+		return false
+	}
+
+	return true
+}
+
+// reset closes the connection of the request with a TCP RST, the server aborts the response of
+// the connections which can not be hijacked, e.g. those of HTTP/2.
+func reset(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+
+	raw := conn
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		raw = tlsConn.NetConn()
+	}
+
+	if tcpConn, ok := raw.(*net.TCPConn); ok {
+		_ = tcpConn.SetLinger(0)
+	}
+
+	_ = conn.Close()
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package faultinjection
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/errors"
+	"github.com/spf13/pflag"
+)
+
+// FaultInjectionOptions contains configuration items related to the faults injected into the
+// requests.
+type FaultInjectionOptions struct {
+	Enable bool `json:"enable" mapstructure:"enable"`
+	// Rules are applied in order to the routes under their path, each one to its own
+	// percentage of the requests.
+	Rules []*RuleOptions `json:"rules"  mapstructure:"rules"`
+}
+
+// RuleOptions contains the faults injected into the requests of a route group, e.g. /v1/policies.
+type RuleOptions struct {
+	Path string `json:"path"       mapstructure:"path"`
+	// Percentage of the requests the faults are injected into, in (0, 100].
+	Percentage float64 `json:"percentage" mapstructure:"percentage"`
+	// Delay is added before the request is served, or failed by the other faults.
+	Delay time.Duration `json:"delay"      mapstructure:"delay"`
+	// Code is the error code the request fails with, e.g. 100002 for an internal server error.
+	Code int `json:"code"       mapstructure:"code"`
+	// Reset closes the connection without a response.
+	Reset bool `json:"reset"      mapstructure:"reset"`
+}
+
+// NewFaultInjectionOptions creates a FaultInjectionOptions object with default parameters.
+func NewFaultInjectionOptions() *FaultInjectionOptions {
+	return &FaultInjectionOptions{
+		Enable: false,
+		Rules:  []*RuleOptions{},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *FaultInjectionOptions) Validate() []error {
+	if !o.Enable {
+		return nil
+	}
+
+	errs := []error{}
+
+	for i, r := range o.Rules {
+		if !strings.HasPrefix(r.Path, "/") {
+			errs = append(errs, fmt.Errorf("fault-injection.rules[%d].path %q must start with /", i, r.Path))
+		}
+
+		if r.Percentage <= 0 || r.Percentage > 100 {
+			errs = append(errs, fmt.Errorf("fault-injection.rules[%d].percentage must be in (0, 100], got %v",
+				i, r.Percentage))
+		}
+
+		if r.Delay < 0 {
+			errs = append(errs, fmt.Errorf("fault-injection.rules[%d].delay can not be negative", i))
+		}
+
+		if r.Delay == 0 && r.Code == 0 && !r.Reset {
+			errs = append(errs, fmt.Errorf("fault-injection.rules[%d] must inject a delay, a code or a reset", i))
+		}
+
+		if r.Code != 0 && r.Reset {
+			errs = append(errs, fmt.Errorf("fault-injection.rules[%d] can not inject both a code and a reset", i))
+		}
+
+		if r.Code != 0 && errors.ParseCoder(errors.WithCode(r.Code, "")).Code() != r.Code {
+			errs = append(errs, fmt.Errorf("fault-injection.rules[%d].code %d is not registered", i, r.Code))
+		}
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the fault injection for a specific api server to the specified
+// FlagSet. The rules can only be configured by the config file.
+func (o *FaultInjectionOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "fault-injection.enable", o.Enable, ""+
+		"Inject the faults of the fault-injection.rules in the config file into the requests. "+
+		"Never enable it in production.")
+}
+
+// match reports whether the rule applies to the route.
+func (r *RuleOptions) match(route string) bool {
+	return route == r.Path || strings.HasPrefix(route, strings.TrimSuffix(r.Path, "/")+"/")
+}
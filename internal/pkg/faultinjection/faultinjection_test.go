@@ -0,0 +1,117 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package faultinjection
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func newEngine(opts *FaultInjectionOptions) *gin.Engine {
+	g := gin.New()
+	g.Use(Inject(opts))
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	g.GET("/v1/policies/:name", handler)
+	g.GET("/v1/users/:name", handler)
+
+	return g
+}
+
+func do(g *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	g.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestInject_code(t *testing.T) {
+	g := newEngine(&FaultInjectionOptions{
+		Enable: true,
+		Rules:  []*RuleOptions{{Path: "/v1/policies", Percentage: 100, Code: code.ErrUnknown}},
+	})
+
+	w := do(g, "/v1/policies/p1")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var resp struct {
+		Code int `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrUnknown, resp.Code)
+
+	// the other routes are served
+	assert.Equal(t, http.StatusOK, do(g, "/v1/users/u1").Code)
+}
+
+func TestInject_delay(t *testing.T) {
+	g := newEngine(&FaultInjectionOptions{
+		Enable: true,
+		Rules:  []*RuleOptions{{Path: "/", Percentage: 100, Delay: 50 * time.Millisecond}},
+	})
+
+	start := time.Now()
+	w := do(g, "/v1/users/u1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestInject_reset(t *testing.T) {
+	g := newEngine(&FaultInjectionOptions{
+		Enable: true,
+		Rules:  []*RuleOptions{{Path: "/v1/policies", Percentage: 100, Reset: true}},
+	})
+	server := httptest.NewServer(g)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1/policies/p1")
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.Error(t, err)
+}
+
+func TestInject_percentage(t *testing.T) {
+	g := newEngine(&FaultInjectionOptions{
+		Enable: true,
+		Rules:  []*RuleOptions{{Path: "/v1/policies", Percentage: 0.000001, Code: code.ErrUnknown}},
+	})
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, http.StatusOK, do(g, "/v1/policies/p1").Code)
+	}
+}
+
+func TestFaultInjectionOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *RuleOptions
+		errs int
+	}{
+		{name: "valid", rule: &RuleOptions{Path: "/v1", Percentage: 10, Delay: time.Second, Code: code.ErrUnknown}},
+		{name: "relative path", rule: &RuleOptions{Path: "v1", Percentage: 10, Reset: true}, errs: 1},
+		{name: "percentage out of range", rule: &RuleOptions{Path: "/v1", Percentage: 101, Reset: true}, errs: 1},
+		{name: "no fault", rule: &RuleOptions{Path: "/v1", Percentage: 10}, errs: 1},
+		{name: "code and reset", rule: &RuleOptions{Path: "/v1", Percentage: 10, Code: code.ErrUnknown, Reset: true}, errs: 1},
+		{name: "unknown code", rule: &RuleOptions{Path: "/v1", Percentage: 10, Code: 99}, errs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &FaultInjectionOptions{Enable: true, Rules: []*RuleOptions{tt.rule}}
+			assert.Len(t, opts.Validate(), tt.errs)
+		})
+	}
+}
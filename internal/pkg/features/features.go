@@ -0,0 +1,38 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package features defines the feature gates of the iam components.
+package features
+
+import (
+	"github.com/marmotedu/iam/pkg/featuregate"
+)
+
+const (
+	// DecisionCache reuses the decisions of the identical authorization requests
+	// until the policies change.
+	DecisionCache featuregate.Feature = "DecisionCache"
+
+	// I18nErrorMessages translates the messages of the error responses to the language
+	// of the Accept-Language header.
+	I18nErrorMessages featuregate.Feature = "I18nErrorMessages"
+)
+
+func init() {
+	if err := featuregate.DefaultFeatureGate.Add(defaultFeatureGates); err != nil {
+		panic(err)
+	}
+}
+
+// defaultFeatureGates consists of all known iam feature keys.
+// To add a new feature, define a key for it above and add it here.
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	DecisionCache:     {Default: true, PreRelease: featuregate.Beta},
+	I18nErrorMessages: {Default: true, PreRelease: featuregate.Beta},
+}
+
+// Enabled returns whether the feature is enabled by the default feature gate.
+func Enabled(feature featuregate.Feature) bool {
+	return featuregate.DefaultFeatureGate.Enabled(feature)
+}
@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package grpcerror translates the errors carrying a business error code into grpc status
+// errors, so that the grpc clients get the structured errors the rest clients do: the business
+// code and its reference document travel as an ErrorInfo detail, the reference document as a
+// Help detail too, and the invalid fields of a request as a BadRequest detail.
+package grpcerror // import "github.com/marmotedu/iam/internal/pkg/grpcerror"
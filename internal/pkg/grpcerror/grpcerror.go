@@ -0,0 +1,198 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package grpcerror
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Domain is the domain of the ErrorInfo details.
+const Domain = "iam.api.marmotedu.com"
+
+// The metadata keys of the ErrorInfo details.
+const (
+	MetadataCode      = "code"
+	MetadataReference = "reference"
+)
+
+// businessCodes maps the business codes whose meaning is finer than their http status.
+var businessCodes = map[int]codes.Code{
+	code.ErrUserAlreadyExist:       codes.AlreadyExists,
+	code.ErrGroupAlreadyExist:      codes.AlreadyExists,
+	code.ErrAttachmentAlreadyExist: codes.AlreadyExists,
+	code.ErrReachMaxCount:          codes.ResourceExhausted,
+	code.ErrUserQuotaExceeded:      codes.ResourceExhausted,
+	code.ErrSecretQuotaExceeded:    codes.ResourceExhausted,
+	code.ErrPolicyQuotaExceeded:    codes.ResourceExhausted,
+	code.ErrPolicySizeExceeded:     codes.ResourceExhausted,
+	code.ErrUserStateTransition:    codes.FailedPrecondition,
+	code.ErrPasswordExpired:        codes.FailedPrecondition,
+	code.ErrConflict:               codes.Aborted,
+}
+
+// CodeOf returns the grpc code of the business code, by its http status unless it has a
+// finer one.
+func CodeOf(businessCode, httpStatus int) codes.Code {
+	if c, ok := businessCodes[businessCode]; ok {
+		return c
+	}
+
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		if httpStatus >= http.StatusInternalServerError {
+			return codes.Internal
+		}
+
+		return codes.Unknown
+	}
+}
+
+// New returns the status of the business code, with the external message and the reference
+// document of the code. The invalid fields of a request, if any, are attached as a BadRequest
+// detail.
+func New(businessCode, httpStatus int, message, reference string, fields ...validation.FieldError) *status.Status {
+	s := status.New(CodeOf(businessCode, httpStatus), message)
+
+	info := &errdetails.ErrorInfo{
+		Reason:   strconv.Itoa(businessCode),
+		Domain:   Domain,
+		Metadata: map[string]string{MetadataCode: strconv.Itoa(businessCode)},
+	}
+	if reference != "" {
+		info.Metadata[MetadataReference] = reference
+	}
+
+	// the details are appended one by one, a status keeps the details attached so far
+	withDetails, err := s.WithDetails(info)
+	if err != nil {
+		return s
+	}
+
+	if reference != "" {
+		help := &errdetails.Help{Links: []*errdetails.Help_Link{{Description: message, Url: reference}}}
+		if s, err := withDetails.WithDetails(help); err == nil {
+			withDetails = s
+		}
+	}
+
+	if len(fields) != 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+		for _, f := range fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message})
+		}
+		if s, err := withDetails.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			withDetails = s
+		}
+	}
+
+	return withDetails
+}
+
+// Status returns the status of the error. The grpc status errors are kept, the context
+// errors are translated to their grpc codes, and the other errors to the status of their
+// business code, which hides the internal message like the rest api does.
+func Status(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+
+	var fields []validation.FieldError
+	var verr *validation.Error
+	if errors.As(err, &verr) {
+		fields = verr.Fields
+	}
+
+	coder := errors.ParseCoder(err)
+
+	return New(coder.Code(), coder.HTTPStatus(), coder.String(), coder.Reference(), fields...)
+}
+
+// FromResponse returns the status of a failed response of the rest api, made of the business
+// code, the message and the reference document of its body. The bodies which are not error
+// responses, e.g. of a route not found, are the message of a status with the http status code.
+func FromResponse(httpStatus int, body []byte) *status.Status {
+	var resp validation.ErrResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code == 0 {
+		return status.New(CodeOf(0, httpStatus), string(body))
+	}
+
+	return New(resp.Code, httpStatus, resp.Message, resp.Reference, resp.Details...)
+}
+
+// UnaryServerInterceptor returns a unary server interceptor translating the errors of the
+// handlers into grpc status errors.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			log.L(ctx).Errorf("grpc %s failed: %#+v", info.FullMethod, err)
+
+			return resp, Status(err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor translating the errors of the
+// handlers into grpc status errors.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			log.L(ss.Context()).Errorf("grpc %s failed: %#+v", info.FullMethod, err)
+
+			return Status(err).Err()
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package grpcerror
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/validation"
+)
+
+func errorInfoOf(s *status.Status) *errdetails.ErrorInfo {
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantBiz  string
+	}{
+		{"not found", errors.WithCode(code.ErrUserNotFound, "user colin"), codes.NotFound, "110001"},
+		{"already exist", errors.WithCode(code.ErrUserAlreadyExist, "user colin"), codes.AlreadyExists, "110002"},
+		{"quota", errors.WithCode(code.ErrSecretQuotaExceeded, ""), codes.ResourceExhausted, "110503"},
+		{"validation", errors.WithCode(code.ErrValidation, "name"), codes.InvalidArgument, "100004"},
+		{"unknown", errors.New("boom"), codes.Internal, "1"},
+		{"canceled", context.Canceled, codes.Canceled, ""},
+		{"status", status.Error(codes.Unavailable, "down"), codes.Unavailable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Status(tt.err)
+			assert.Equal(t, tt.wantCode, s.Code())
+
+			info := errorInfoOf(s)
+			if tt.wantBiz == "" {
+				assert.Nil(t, info)
+
+				return
+			}
+			assert.Equal(t, Domain, info.GetDomain())
+			assert.Equal(t, tt.wantBiz, info.GetMetadata()[MetadataCode])
+		})
+	}
+
+	// the internal message is hidden
+	s := Status(errors.WithCode(code.ErrDatabase, "dial tcp 127.0.0.1:3306"))
+	assert.NotContains(t, s.Message(), "3306")
+	assert.Nil(t, Status(nil))
+}
+
+func TestStatus_invalidFields(t *testing.T) {
+	s := Status(validation.NewError(field.ErrorList{field.Required(field.NewPath("metadata", "name"), "")}))
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range s.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "metadata.name", violations[0].GetField())
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	s := FromResponse(http.StatusNotFound,
+		[]byte(`{"code":110001,"message":"User not found","reference":"https://example.com/110001"}`))
+	assert.Equal(t, codes.NotFound, s.Code())
+	assert.Equal(t, "User not found", s.Message())
+	assert.Equal(t, "https://example.com/110001", errorInfoOf(s).GetMetadata()[MetadataReference])
+
+	s = FromResponse(http.StatusNotFound, []byte("404 page not found"))
+	assert.Equal(t, codes.NotFound, s.Code())
+	assert.Equal(t, "404 page not found", s.Message())
+	assert.Nil(t, errorInfoOf(s))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/iam.v1.Secret/GetSecret"}
+
+	_, err := interceptor(context.TODO(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.WithCode(code.ErrSecretNotFound, "secret foo")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err := interceptor(context.TODO(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package instruction defines the instructions sent by iam-apiserver to iam-pump through
+// redis, e.g. to erase the analytics records of a purged user.
+package instruction // import "github.com/marmotedu/iam/internal/pkg/instruction"
@@ -0,0 +1,67 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package instruction
+
+import (
+	"context"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// KeyName is the redis list the instructions are queued in, iam-pump takes them at each purge
+// along with the analytics records.
+const KeyName = "iam-pump-instructions"
+
+// keyPrefix prefixes the keys read by iam-pump, like the analytics records.
+const keyPrefix = "analytics-"
+
+// KindEraseUser is the kind of the instructions erasing the analytics records of a user, both
+// the queued ones and the ones written to the pumps able to erase them.
+const KindEraseUser = "EraseUser"
+
+// Instruction is an instruction to iam-pump.
+type Instruction struct {
+	Kind     string `json:"kind"`
+	Username string `json:"username,omitempty"`
+
+	// Operation is the name of the operation which sent the instruction, if any.
+	Operation string    `json:"operation,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Send queues the instruction, it fails if redis is not available.
+func Send(ctx context.Context, ins *Instruction) error {
+	if !storage.Connected() {
+		return storage.ErrRedisIsDown
+	}
+
+	if ins.CreatedAt.IsZero() {
+		ins.CreatedAt = time.Now()
+	}
+
+	data, err := json.Marshal(ins)
+	if err != nil {
+		return errors.Wrap(err, "marshal instruction failed")
+	}
+
+	store := &storage.RedisCluster{KeyPrefix: keyPrefix}
+	store.WithContext(ctx).AppendToSet(KeyName, string(data))
+
+	return nil
+}
+
+// Parse decodes an instruction taken from the queue.
+func Parse(data string) (*Instruction, error) {
+	var ins Instruction
+	if err := json.Unmarshal([]byte(data), &ins); err != nil {
+		return nil, errors.Wrap(err, "unmarshal instruction failed")
+	}
+
+	return &ins, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// BER tags used by the LDAP messages.
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	classApplication = 0x40
+	classContext     = 0x80
+	constructed      = 0x20
+)
+
+// maxPacketSize limits the size of the messages read from the server.
+const maxPacketSize = 16 << 20
+
+var errMalformed = errors.New("ldap: malformed BER packet")
+
+// packet is a decoded BER element, the children are decoded for the constructed elements.
+type packet struct {
+	tag      byte
+	value    []byte
+	children []*packet
+}
+
+func encode(tag byte, content []byte) []byte {
+	n := len(content)
+	if n < 0x80 {
+		return append([]byte{tag, byte(n)}, content...)
+	}
+
+	var length []byte
+	for ; n > 0; n >>= 8 {
+		length = append([]byte{byte(n)}, length...)
+	}
+
+	b := append([]byte{tag, 0x80 | byte(len(length))}, length...)
+
+	return append(b, content...)
+}
+
+func encodeInt(tag byte, v int64) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(v)}, b...)
+		// stop when the remaining bits are only the sign extension of the current byte
+		if (v < 0x80 && v >= -0x80) || len(b) == 8 {
+			break
+		}
+		v >>= 8
+	}
+
+	return encode(tag, b)
+}
+
+func encodeString(tag byte, s string) []byte {
+	return encode(tag, []byte(s))
+}
+
+func encodeBool(v bool) []byte {
+	if v {
+		return encode(tagBoolean, []byte{0xff})
+	}
+
+	return encode(tagBoolean, []byte{0})
+}
+
+func encodeSeq(tag byte, elements ...[]byte) []byte {
+	var content []byte
+	for _, e := range elements {
+		content = append(content, e...)
+	}
+
+	return encode(tag, content)
+}
+
+// readPacket reads a whole BER element from the reader.
+func readPacket(r *bufio.Reader) ([]byte, error) {
+	header := make([]byte, 2, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	n := int(header[1])
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 4 {
+			return nil, errMalformed
+		}
+
+		length := make([]byte, size)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return nil, err
+		}
+		header = append(header, length...)
+
+		n = 0
+		for _, b := range length {
+			n = n<<8 | int(b)
+		}
+	}
+
+	if n > maxPacketSize {
+		return nil, errMalformed
+	}
+
+	b := make([]byte, len(header)+n)
+	copy(b, header)
+	if _, err := io.ReadFull(r, b[len(header):]); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// decode decodes the first BER element of b.
+func decode(b []byte) (*packet, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, errMalformed
+	}
+
+	tag, n, offset := b[0], int(b[1]), 2
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 4 || len(b) < 2+size {
+			return nil, nil, errMalformed
+		}
+
+		n = 0
+		for _, c := range b[2 : 2+size] {
+			n = n<<8 | int(c)
+		}
+		offset += size
+	}
+
+	if n < 0 || len(b)-offset < n {
+		return nil, nil, errMalformed
+	}
+
+	p := &packet{tag: tag, value: b[offset : offset+n]}
+	if tag&constructed != 0 {
+		for rest := p.value; len(rest) > 0; {
+			child, next, err := decode(rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.children = append(p.children, child)
+			rest = next
+		}
+	}
+
+	return p, b[offset+n:], nil
+}
+
+func (p *packet) int() int64 {
+	var v int64
+	for i, b := range p.value {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int64(b)
+	}
+
+	return v
+}
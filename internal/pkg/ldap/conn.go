@@ -0,0 +1,295 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LDAP protocol operation tags, see RFC 4511 section 4.2.
+const (
+	opBindRequest     = classApplication | constructed | 0
+	opBindResponse    = classApplication | constructed | 1
+	opUnbindRequest   = classApplication | 2
+	opSearchRequest   = classApplication | constructed | 3
+	opSearchEntry     = classApplication | constructed | 4
+	opSearchDone      = classApplication | constructed | 5
+	opSearchReference = classApplication | constructed | 19
+
+	authSimple = classContext | 0
+)
+
+// Search scopes.
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// resultSuccess is the result code of the successful operations.
+const resultSuccess = 0
+
+// Error is an unsuccessful LDAP result.
+type Error struct {
+	ResultCode int64
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+}
+
+// Entry is an object returned by a search.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Values returns the values of the attribute, the attribute names are case-insensitive.
+func (e *Entry) Values(name string) []string {
+	for attr, values := range e.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+// Value returns the first value of the attribute, or an empty string.
+func (e *Entry) Value(name string) string {
+	if values := e.Values(name); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// SearchRequest is the parameters of a search operation.
+type SearchRequest struct {
+	BaseDN     string
+	Scope      int
+	Filter     string
+	Attributes []string
+	SizeLimit  int
+}
+
+// Conn is a connection to a LDAP server, the operations are sent one after another.
+type Conn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	id      int64
+	timeout time.Duration
+}
+
+// Dial connects to the server of the url, e.g. ldap://ldap.example.com or
+// ldaps://ldap.example.com:636.
+func Dial(rawURL string, tlsConfig *tls.Config, timeout time.Duration) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		conn, err = dialer.Dial("tcp", hostPort(u.Host, "389"))
+	case "ldaps":
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = u.Hostname()
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", hostPort(u.Host, "636"), tlsConfig)
+	default:
+		return nil, fmt.Errorf("ldap: unsupported scheme %s", u.Scheme)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConn(conn, timeout), nil
+}
+
+// NewConn creates a LDAP connection over conn.
+func NewConn(conn net.Conn, timeout time.Duration) *Conn {
+	return &Conn{conn: conn, r: bufio.NewReader(conn), timeout: timeout}
+}
+
+func hostPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+// Close sends the unbind request and closes the connection.
+func (c *Conn) Close() error {
+	_ = c.send(encode(opUnbindRequest, nil))
+
+	return c.conn.Close()
+}
+
+// Bind authenticates the connection with the simple bind.
+func (c *Conn) Bind(dn, password string) error {
+	if err := c.send(encodeSeq(opBindRequest,
+		encodeInt(tagInteger, 3),
+		encodeString(tagOctetString, dn),
+		encodeString(authSimple, password),
+	)); err != nil {
+		return err
+	}
+
+	op, err := c.receive()
+	if err != nil {
+		return err
+	}
+
+	if op.tag != opBindResponse {
+		return errMalformed
+	}
+
+	return result(op)
+}
+
+// Search returns the entries matching the request, search result references are ignored.
+func (c *Conn) Search(req *SearchRequest) ([]*Entry, error) {
+	filter, err := compileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make([][]byte, 0, len(req.Attributes))
+	for _, attr := range req.Attributes {
+		attrs = append(attrs, encodeString(tagOctetString, attr))
+	}
+
+	if err := c.send(encodeSeq(opSearchRequest,
+		encodeString(tagOctetString, req.BaseDN),
+		encodeInt(tagEnumerated, int64(req.Scope)),
+		encodeInt(tagEnumerated, 0), // neverDerefAliases
+		encodeInt(tagInteger, int64(req.SizeLimit)),
+		encodeInt(tagInteger, int64(c.timeout/time.Second)),
+		encodeBool(false),
+		filter,
+		encodeSeq(tagSequence, attrs...),
+	)); err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for {
+		op, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.tag {
+		case opSearchEntry:
+			entry, err := parseEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case opSearchReference:
+		case opSearchDone:
+			return entries, result(op)
+		default:
+			return nil, errMalformed
+		}
+	}
+}
+
+func (c *Conn) send(op []byte) error {
+	c.id++
+	msg := encodeSeq(tagSequence, encodeInt(tagInteger, c.id), op)
+
+	if c.timeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+
+	_, err := c.conn.Write(msg)
+
+	return err
+}
+
+// receive returns the protocol operation of the next response to the current message.
+func (c *Conn) receive() (*packet, error) {
+	for {
+		if c.timeout > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+		}
+
+		b, err := readPacket(c.r)
+		if err != nil {
+			return nil, err
+		}
+
+		msg, _, err := decode(b)
+		if err != nil {
+			return nil, err
+		}
+
+		if msg.tag != tagSequence || len(msg.children) < 2 || msg.children[0].tag != tagInteger {
+			return nil, errMalformed
+		}
+
+		// skip the unsolicited notifications, e.g. the notice of disconnection
+		if msg.children[0].int() == c.id {
+			return msg.children[1], nil
+		}
+
+		if id := msg.children[0].int(); id == 0 {
+			if err := result(msg.children[1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// result returns the error of an unsuccessful LDAPResult.
+func result(op *packet) error {
+	if len(op.children) < 3 || op.children[0].tag != tagEnumerated {
+		return errMalformed
+	}
+
+	if code := op.children[0].int(); code != resultSuccess {
+		return &Error{ResultCode: code, Message: string(op.children[2].value)}
+	}
+
+	return nil
+}
+
+func parseEntry(op *packet) (*Entry, error) {
+	if len(op.children) != 2 || op.children[1].tag != tagSequence {
+		return nil, errMalformed
+	}
+
+	entry := &Entry{DN: string(op.children[0].value), Attributes: map[string][]string{}}
+	for _, attr := range op.children[1].children {
+		if len(attr.children) != 2 {
+			return nil, errMalformed
+		}
+
+		name := string(attr.children[0].value)
+		for _, v := range attr.children[1].children {
+			entry.Attributes[name] = append(entry.Attributes[name], string(v.value))
+		}
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// FirstRDN returns the attribute type and the unescaped value of the first relative
+// distinguished name of the dn, e.g. uid and colin for uid=colin,ou=people,dc=example,dc=com.
+// Multi-valued RDNs are not split.
+func FirstRDN(dn string) (string, string, bool) {
+	i := strings.IndexByte(dn, '=')
+	if i <= 0 {
+		return "", "", false
+	}
+
+	attr := strings.TrimSpace(dn[:i])
+
+	var value strings.Builder
+	for j := i + 1; j < len(dn); j++ {
+		switch c := dn[j]; c {
+		case ',', '+':
+			return attr, strings.TrimSpace(value.String()), true
+		case '\\':
+			if j+1 >= len(dn) {
+				return "", "", false
+			}
+
+			// \XX hex pair or an escaped special character
+			if j+2 < len(dn) {
+				if b, err := hex.DecodeString(dn[j+1 : j+3]); err == nil {
+					value.Write(b)
+					j += 2
+
+					continue
+				}
+			}
+			value.WriteByte(dn[j+1])
+			j++
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return attr, strings.TrimSpace(value.String()), true
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package ldap implements a minimal LDAPv3 client which supports the simple bind and the
+// search operations, it is used to synchronize the directory groups into iam.
+package ldap // import "github.com/marmotedu/iam/internal/pkg/ldap"
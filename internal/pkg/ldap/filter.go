@@ -0,0 +1,219 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Filter choice tags, see RFC 4511 section 4.5.1.
+const (
+	filterAnd            = classContext | constructed | 0
+	filterOr             = classContext | constructed | 1
+	filterNot            = classContext | constructed | 2
+	filterEqualityMatch  = classContext | constructed | 3
+	filterSubstrings     = classContext | constructed | 4
+	filterGreaterOrEqual = classContext | constructed | 5
+	filterLessOrEqual    = classContext | constructed | 6
+	filterPresent        = classContext | 7
+	filterApproxMatch    = classContext | constructed | 8
+
+	substringInitial = classContext | 0
+	substringAny     = classContext | 1
+	substringFinal   = classContext | 2
+)
+
+// compileFilter encodes the string representation of a search filter, see RFC 4515.
+// Extensible matches are not supported.
+func compileFilter(filter string) ([]byte, error) {
+	b, rest, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if rest != "" {
+		return nil, fmt.Errorf("ldap: invalid filter %q: unexpected %q", filter, rest)
+	}
+
+	return b, nil
+}
+
+func parseFilter(s string) ([]byte, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", fmt.Errorf("ldap: invalid filter %q: missing (", s)
+	}
+	s = s[1:]
+
+	if s == "" {
+		return nil, "", fmt.Errorf("ldap: invalid filter: unexpected end")
+	}
+
+	switch s[0] {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if s[0] == '|' {
+			tag = filterOr
+		}
+
+		var children [][]byte
+		s = s[1:]
+		for strings.HasPrefix(s, "(") {
+			child, rest, err := parseFilter(s)
+			if err != nil {
+				return nil, "", err
+			}
+			children = append(children, child)
+			s = rest
+		}
+
+		if !strings.HasPrefix(s, ")") {
+			return nil, "", fmt.Errorf("ldap: invalid filter: missing )")
+		}
+
+		return encodeSeq(tag, children...), s[1:], nil
+	case '!':
+		child, rest, err := parseFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+
+		if !strings.HasPrefix(rest, ")") {
+			return nil, "", fmt.Errorf("ldap: invalid filter: missing )")
+		}
+
+		return encodeSeq(filterNot, child), rest[1:], nil
+	}
+
+	end := strings.IndexByte(s, ')')
+	if end < 0 {
+		return nil, "", fmt.Errorf("ldap: invalid filter: missing )")
+	}
+
+	item, err := parseItem(s[:end])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return item, s[end+1:], nil
+}
+
+// parseItem encodes a simple, present or substring filter item, e.g. cn=admin*.
+func parseItem(item string) ([]byte, error) {
+	i := strings.IndexByte(item, '=')
+	if i <= 0 {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", item)
+	}
+
+	attr, value := item[:i], item[i+1:]
+
+	tag := byte(filterEqualityMatch)
+	switch attr[len(attr)-1] {
+	case '~':
+		tag = filterApproxMatch
+	case '>':
+		tag = filterGreaterOrEqual
+	case '<':
+		tag = filterLessOrEqual
+	}
+
+	if tag != filterEqualityMatch {
+		attr = attr[:len(attr)-1]
+		if strings.Contains(value, "*") {
+			return nil, fmt.Errorf("ldap: invalid filter item %q", item)
+		}
+	}
+
+	if attr == "" || strings.ContainsAny(attr, "()*\\:") {
+		return nil, fmt.Errorf("ldap: invalid filter attribute %q", attr)
+	}
+
+	if tag == filterEqualityMatch && value == "*" {
+		return encodeString(filterPresent, attr), nil
+	}
+
+	if tag == filterEqualityMatch && strings.Contains(value, "*") {
+		return parseSubstrings(attr, value)
+	}
+
+	v, err := unescapeValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSeq(tag, encodeString(tagOctetString, attr), encodeString(tagOctetString, v)), nil
+}
+
+func parseSubstrings(attr, value string) ([]byte, error) {
+	parts := strings.Split(value, "*")
+
+	var substrings [][]byte
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		v, err := unescapeValue(part)
+		if err != nil {
+			return nil, err
+		}
+
+		tag := byte(substringAny)
+		switch i {
+		case 0:
+			tag = substringInitial
+		case len(parts) - 1:
+			tag = substringFinal
+		}
+		substrings = append(substrings, encodeString(tag, v))
+	}
+
+	return encodeSeq(filterSubstrings, encodeString(tagOctetString, attr), encodeSeq(tagSequence, substrings...)), nil
+}
+
+// unescapeValue decodes the \XX escapes of a filter value.
+func unescapeValue(value string) (string, error) {
+	if strings.ContainsAny(value, "()") {
+		return "", fmt.Errorf("ldap: invalid filter value %q", value)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+
+			continue
+		}
+
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("ldap: invalid escape in filter value %q", value)
+		}
+
+		c, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("ldap: invalid escape in filter value %q", value)
+		}
+		b.Write(c)
+		i += 2
+	}
+
+	return b.String(), nil
+}
+
+// EscapeFilter escapes the special characters of a filter value.
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_compileFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:   "equality",
+			filter: "(cn=admin)",
+			want:   []byte{0xa3, 0x0b, 0x04, 0x02, 'c', 'n', 0x04, 0x05, 'a', 'd', 'm', 'i', 'n'},
+		},
+		{
+			name:   "present",
+			filter: "(cn=*)",
+			want:   []byte{0x87, 0x02, 'c', 'n'},
+		},
+		{
+			name:   "substrings",
+			filter: "(cn=a*b*c)",
+			want: []byte{
+				0xa4, 0x0f, 0x04, 0x02, 'c', 'n',
+				0x30, 0x09, 0x80, 0x01, 'a', 0x81, 0x01, 'b', 0x82, 0x01, 'c',
+			},
+		},
+		{
+			name:   "and not",
+			filter: "(&(cn=*)(!(cn=*)))",
+			want:   []byte{0xa0, 0x0a, 0x87, 0x02, 'c', 'n', 0xa2, 0x04, 0x87, 0x02, 'c', 'n'},
+		},
+		{
+			name:   "escaped value",
+			filter: `(cn=a\2ab)`,
+			want:   []byte{0xa3, 0x09, 0x04, 0x02, 'c', 'n', 0x04, 0x03, 'a', '*', 'b'},
+		},
+		{name: "missing parenthesis", filter: "cn=admin", wantErr: true},
+		{name: "unbalanced", filter: "(&(cn=admin)", wantErr: true},
+		{name: "trailing data", filter: "(cn=admin))", wantErr: true},
+		{name: "invalid escape", filter: `(cn=a\zz)`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compileFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("compileFilter() = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstRDN(t *testing.T) {
+	tests := []struct {
+		dn        string
+		wantAttr  string
+		wantValue string
+		wantOK    bool
+	}{
+		{dn: "uid=colin,ou=people,dc=example,dc=com", wantAttr: "uid", wantValue: "colin", wantOK: true},
+		{dn: `cn=Kong\, Colin,ou=people`, wantAttr: "cn", wantValue: "Kong, Colin", wantOK: true},
+		{dn: `cn=a\2cb`, wantAttr: "cn", wantValue: "a,b", wantOK: true},
+		{dn: "colin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dn, func(t *testing.T) {
+			attr, value, ok := FirstRDN(tt.dn)
+			if attr != tt.wantAttr || value != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("FirstRDN() = %s, %s, %v", attr, value, ok)
+			}
+		})
+	}
+}
+
+// fakeServer answers the bind with success for the password secret and every search with
+// the entries.
+func fakeServer(t *testing.T, conn net.Conn, entries []*Entry) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		b, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		msg, _, err := decode(b)
+		if err != nil {
+			t.Errorf("decode request: %v", err)
+
+			return
+		}
+
+		id := encodeInt(tagInteger, msg.children[0].int())
+		op := msg.children[1]
+
+		reply := func(ops ...[]byte) {
+			for _, op := range ops {
+				_, _ = conn.Write(encodeSeq(tagSequence, id, op))
+			}
+		}
+		ldapResult := func(tag byte, code int64) []byte {
+			return encodeSeq(tag, encodeInt(tagEnumerated, code), encodeString(tagOctetString, ""),
+				encodeString(tagOctetString, "message"))
+		}
+
+		switch op.tag {
+		case opBindRequest:
+			code := int64(49) // invalidCredentials
+			if string(op.children[2].value) == "secret" {
+				code = resultSuccess
+			}
+			reply(ldapResult(opBindResponse, code))
+		case opSearchRequest:
+			var ops [][]byte
+			for _, e := range entries {
+				var attrs [][]byte
+				for name, values := range e.Attributes {
+					var vals [][]byte
+					for _, v := range values {
+						vals = append(vals, encodeString(tagOctetString, v))
+					}
+					attrs = append(attrs, encodeSeq(tagSequence, encodeString(tagOctetString, name), encodeSeq(tagSet, vals...)))
+				}
+				ops = append(ops, encodeSeq(opSearchEntry, encodeString(tagOctetString, e.DN), encodeSeq(tagSequence, attrs...)))
+			}
+			reply(append(ops, ldapResult(opSearchDone, resultSuccess))...)
+		case opUnbindRequest:
+			return
+		}
+	}
+}
+
+func TestConn(t *testing.T) {
+	entries := []*Entry{
+		{DN: "cn=admins,ou=groups,dc=example,dc=com", Attributes: map[string][]string{
+			"cn":     {"admins"},
+			"member": {"uid=colin,ou=people,dc=example,dc=com", "uid=peter,ou=people,dc=example,dc=com"},
+		}},
+		{DN: "cn=dev,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"cn": {"dev"}}},
+	}
+
+	client, server := net.Pipe()
+	go fakeServer(t, server, entries)
+
+	conn := NewConn(client, time.Second)
+	defer conn.Close()
+
+	err := conn.Bind("cn=admin,dc=example,dc=com", "wrong")
+	if e, ok := err.(*Error); !ok || e.ResultCode != 49 {
+		t.Fatalf("Bind() error = %v, want invalid credentials", err)
+	}
+
+	if err := conn.Bind("cn=admin,dc=example,dc=com", "secret"); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+
+	got, err := conn.Search(&SearchRequest{
+		BaseDN:     "ou=groups,dc=example,dc=com",
+		Scope:      ScopeWholeSubtree,
+		Filter:     "(objectClass=groupOfNames)",
+		Attributes: []string{"cn", "member"},
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, entries) {
+		t.Errorf("Search() = %v, want %v", got, entries)
+	}
+
+	if got[0].Value("CN") != "admins" {
+		t.Errorf("Value() = %s, want admins", got[0].Value("CN"))
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package loginthrottle throttles the login attempts with sliding windows stored in redis,
+// counted per ip and username, per ip and per username. It slows down the password guessing
+// before it reaches the users, independently of the lockout of their accounts.
+package loginthrottle // import "github.com/marmotedu/iam/internal/pkg/loginthrottle"
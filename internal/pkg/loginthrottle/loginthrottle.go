@@ -0,0 +1,157 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package loginthrottle
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the redis keys of the sliding windows of the login attempts.
+const KeyPrefix = "iam-login-throttle-"
+
+// HeaderRetryAfter is the header telling the throttled clients when to retry.
+const HeaderRetryAfter = "Retry-After"
+
+// maxBodySize bounds the login body read to find the username.
+const maxBodySize = 1 << 20
+
+// Window adds a login attempt to the sliding window of a key.
+type Window interface {
+	AddToSlidingWindow(keyName string, limit int64, window, block time.Duration) (*storage.SlidingWindow, error)
+}
+
+// Throttle returns a middleware throttling the login attempts with the sliding windows stored
+// in redis. It must be installed before the login handler, which it leaves the body to.
+func Throttle(opts *LoginThrottleOptions) gin.HandlerFunc {
+	return ThrottleWith(opts, &storage.RedisCluster{})
+}
+
+// ThrottleWith is like Throttle, with the attempts added to window.
+func ThrottleWith(opts *LoginThrottleOptions, window Window) gin.HandlerFunc {
+	// the allowlist is validated with the options
+	allowlist, _ := parseAllowlist(opts.Allowlist)
+
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		if allowed(allowlist, ip) {
+			c.Next()
+
+			return
+		}
+
+		username := loginUsername(c)
+		rules := []struct {
+			name string
+			id   string
+			rule RuleOptions
+		}{
+			{"ip-username", ip + "/" + username, opts.IPUsername},
+			{"ip", ip, opts.IP},
+			{"username", username, opts.Username},
+		}
+
+		for _, r := range rules {
+			// the attempts without username are only counted per ip
+			if r.rule.Limit <= 0 || r.id == "" {
+				continue
+			}
+
+			// the hash tag keeps the window and its block in the same redis cluster slot
+			key := KeyPrefix + r.name + ":{" + r.id + "}"
+			state, err := window.AddToSlidingWindow(key, r.rule.Limit, r.rule.Window, r.rule.Block)
+			if err != nil {
+				// the login attempts are allowed while redis is unavailable
+				log.L(c).Warnf("throttle login attempt failed: %s", err.Error())
+
+				continue
+			}
+
+			if !state.Allowed {
+				log.L(c).Warnw("login attempt throttled", "rule", r.name, "ip", ip, "username", username)
+				c.Header(HeaderRetryAfter, seconds(state.RetryAfter))
+				core.WriteResponse(c, errors.WithCode(code.ErrTooManyRequests,
+					"too many login attempts, retry after %s", state.RetryAfter.Round(time.Second)), nil)
+				c.Abort()
+
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// allowed returns whether the ip is in the allowlist.
+func allowed(allowlist []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, ipNet := range allowlist {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loginUsername returns the username of the login attempt, from the basic authorization
+// header or from the json body, which is restored for the login handler.
+func loginUsername(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		auth := strings.SplitN(header, " ", 2)
+		if len(auth) != 2 || auth[0] != "Basic" {
+			return ""
+		}
+
+		payload, err := base64.StdEncoding.DecodeString(auth[1])
+		if err != nil {
+			return ""
+		}
+
+		return strings.SplitN(string(payload), ":", 2)[0]
+	}
+
+	if c.Request.Body == nil || c.Request.Method != http.MethodPost {
+		return ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	var login struct {
+		Username string `json:"username"`
+	}
+	_ = json.Unmarshal(data, &login)
+
+	return login.Username
+}
+
+// seconds rounds d up to whole seconds, so that the clients do not retry too early.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package loginthrottle
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// LoginThrottleOptions contains configuration items related to the throttling of the login
+// attempts.
+type LoginThrottleOptions struct {
+	// IPUsername limits the attempts from an ip against a user, e.g. a password guessing.
+	IPUsername RuleOptions `json:"ip-username" mapstructure:"ip-username"`
+	// IP limits the attempts from an ip against any user, e.g. a credential stuffing.
+	IP RuleOptions `json:"ip" mapstructure:"ip"`
+	// Username limits the attempts against a user from any ip, e.g. a distributed guessing.
+	Username RuleOptions `json:"username" mapstructure:"username"`
+	// Allowlist lists the ips and cidrs never throttled, e.g. a trusted proxy.
+	Allowlist []string `json:"allowlist" mapstructure:"allowlist"`
+}
+
+// RuleOptions contains the limit of a throttling rule, the rule is disabled when the limit is 0.
+type RuleOptions struct {
+	// Limit is the number of the attempts allowed in the window.
+	Limit int64 `json:"limit" mapstructure:"limit"`
+	// Window is the duration the attempts are counted over.
+	Window time.Duration `json:"window" mapstructure:"window"`
+	// Block is how long the attempts are refused once the limit is reached, 0 refuses them
+	// until an attempt leaves the window.
+	Block time.Duration `json:"block" mapstructure:"block"`
+}
+
+// NewLoginThrottleOptions creates a LoginThrottleOptions object with default parameters.
+// The attempts against a user from any ip are not limited by default, since anyone could
+// lock the user out.
+func NewLoginThrottleOptions() *LoginThrottleOptions {
+	return &LoginThrottleOptions{
+		IPUsername: RuleOptions{Limit: 5, Window: time.Minute, Block: 5 * time.Minute},
+		IP:         RuleOptions{Limit: 30, Window: time.Minute, Block: 15 * time.Minute},
+		Username:   RuleOptions{Limit: 0, Window: time.Minute, Block: 0},
+		Allowlist:  []string{},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *LoginThrottleOptions) Validate() []error {
+	errs := []error{}
+
+	errs = append(errs, o.IPUsername.validate("ip-username")...)
+	errs = append(errs, o.IP.validate("ip")...)
+	errs = append(errs, o.Username.validate("username")...)
+
+	if _, err := parseAllowlist(o.Allowlist); err != nil {
+		errs = append(errs, fmt.Errorf("--login-throttle.allowlist: %w", err))
+	}
+
+	return errs
+}
+
+func (r *RuleOptions) validate(name string) []error {
+	errs := []error{}
+
+	if r.Limit < 0 {
+		errs = append(errs, fmt.Errorf("--login-throttle.%s.limit can not be negative", name))
+	}
+
+	if r.Limit > 0 && r.Window < time.Second {
+		errs = append(errs, fmt.Errorf("--login-throttle.%s.window can not be less than 1s", name))
+	}
+
+	if r.Block < 0 {
+		errs = append(errs, fmt.Errorf("--login-throttle.%s.block can not be negative", name))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the throttling of the login attempts for a specific api
+// server to the specified FlagSet.
+func (o *LoginThrottleOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	o.IPUsername.addFlags(fs, "ip-username", "from an ip against a user")
+	o.IP.addFlags(fs, "ip", "from an ip against any user")
+	o.Username.addFlags(fs, "username", "against a user from any ip")
+
+	fs.StringSliceVar(&o.Allowlist, "login-throttle.allowlist", o.Allowlist, ""+
+		"IPs and CIDRs whose login attempts are never throttled, e.g. 10.0.0.0/8.")
+}
+
+func (r *RuleOptions) addFlags(fs *pflag.FlagSet, name, what string) {
+	fs.Int64Var(&r.Limit, "login-throttle."+name+".limit", r.Limit, ""+
+		"Login attempts "+what+" allowed in the window. Set to zero to disable the limit.")
+	fs.DurationVar(&r.Window, "login-throttle."+name+".window", r.Window, ""+
+		"Duration the login attempts "+what+" are counted over.")
+	fs.DurationVar(&r.Block, "login-throttle."+name+".block", r.Block, ""+
+		"How long the login attempts "+what+" are refused once the limit is reached. "+
+		"Set to zero to refuse them until an attempt leaves the window.")
+}
+
+// parseAllowlist parses the ips and cidrs of the allowlist.
+func parseAllowlist(allowlist []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(allowlist))
+	for _, entry := range allowlist {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %q", entry)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package loginthrottle
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// fakeWindow counts the attempts per key, without expiring them.
+type fakeWindow struct {
+	attempts map[string]int64
+	err      error
+}
+
+func (f *fakeWindow) AddToSlidingWindow(
+	keyName string,
+	limit int64,
+	window, block time.Duration,
+) (*storage.SlidingWindow, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if f.attempts[keyName] >= limit {
+		return &storage.SlidingWindow{RetryAfter: block}, nil
+	}
+	f.attempts[keyName]++
+
+	return &storage.SlidingWindow{Allowed: true, Remaining: limit - f.attempts[keyName]}, nil
+}
+
+func newEngine(opts *LoginThrottleOptions, window Window) *gin.Engine {
+	g := gin.New()
+	g.POST("/login", ThrottleWith(opts, window), func(c *gin.Context) {
+		// the body is left to the login handler
+		data, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(data))
+	})
+
+	return g
+}
+
+func login(g *gin.Engine, ip, username string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	body := `{"username":"` + username + `","password":"Admin@2021"}`
+	req, _ := http.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.RemoteAddr = ip + ":12345"
+	g.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestThrottle(t *testing.T) {
+	opts := NewLoginThrottleOptions()
+	opts.IPUsername.Limit = 2
+	opts.IP.Limit = 3
+	opts.Allowlist = []string{"10.0.0.0/8"}
+
+	window := &fakeWindow{attempts: map[string]int64{}}
+	g := newEngine(opts, window)
+
+	w := login(g, "192.168.0.1", "colin")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"colin"`)
+	assert.Equal(t, http.StatusOK, login(g, "192.168.0.1", "colin").Code)
+
+	w = login(g, "192.168.0.1", "colin")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "300", w.Header().Get(HeaderRetryAfter))
+
+	// another user from the same ip is limited by the ip rule only
+	assert.Equal(t, http.StatusOK, login(g, "192.168.0.1", "peter").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(g, "192.168.0.1", "ken").Code)
+
+	// the attempts from another ip are counted apart
+	assert.Equal(t, http.StatusOK, login(g, "192.168.0.2", "colin").Code)
+
+	// the allowlisted ips are never throttled
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, login(g, "10.1.2.3", "colin").Code)
+	}
+}
+
+func TestThrottle_RedisDown(t *testing.T) {
+	opts := NewLoginThrottleOptions()
+	opts.IPUsername.Limit = 1
+
+	g := newEngine(opts, &fakeWindow{err: errors.New("redis is down")})
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, login(g, "192.168.0.1", "colin").Code)
+	}
+}
+
+func TestLoginThrottleOptions_Validate(t *testing.T) {
+	opts := NewLoginThrottleOptions()
+	assert.Empty(t, opts.Validate())
+
+	opts.IP.Limit = -1
+	opts.Username = RuleOptions{Limit: 5}
+	opts.Allowlist = []string{"10.0.0.1", "fd00::/8", "not-an-ip"}
+	assert.Len(t, opts.Validate(), 3)
+}
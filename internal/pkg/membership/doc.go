@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package membership carries the group and role memberships from the apiserver cache
+// service to iam-authz-server, and indexes them by member.
+package membership // import "github.com/marmotedu/iam/internal/pkg/membership"
@@ -0,0 +1,145 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package membership
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// The pb.ListPoliciesRequest and pb.ListPoliciesResponse messages have no membership
+// fields yet, so the memberships travel as unknown fields. An apiserver which does not
+// know them returns the plain policies, which leaves the membership index empty.
+const (
+	// onlyField asks the apiserver to return the memberships instead of the policies.
+	onlyField protowire.Number = 101
+	// membershipField carries one Membership of the response.
+	membershipField protowire.Number = 100
+)
+
+// Membership is the membership of a member in a group or a role.
+type Membership struct {
+	// Username is the owner of the group.
+	Username string
+	// Group is the ladon subject of the group, e.g. groups:admins or roles:auditor.
+	Group string
+	// Member is the ladon subject of the member, e.g. users:colin.
+	Member string
+}
+
+// SetOnly asks the apiserver to list the memberships instead of the policies.
+func SetOnly(req proto.Message) {
+	m := req.ProtoReflect()
+	raw := protowire.AppendTag(m.GetUnknown(), onlyField, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, 1)
+	m.SetUnknown(raw)
+}
+
+// Only reports whether the list request asks for the memberships only.
+func Only(req proto.Message) bool {
+	only := false
+	walk(req.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, value []byte) {
+		if num == onlyField && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(value)
+			only = n > 0 && v != 0
+		}
+	})
+
+	return only
+}
+
+// Set appends the memberships to the list response.
+func Set(resp proto.Message, memberships []Membership) {
+	m := resp.ProtoReflect()
+	raw := m.GetUnknown()
+	for _, ms := range memberships {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, ms.Username)
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, ms.Group)
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, ms.Member)
+
+		raw = protowire.AppendTag(raw, membershipField, protowire.BytesType)
+		raw = protowire.AppendBytes(raw, b)
+	}
+	m.SetUnknown(raw)
+}
+
+// Get returns the memberships carried by the list response.
+func Get(resp proto.Message) []Membership {
+	var memberships []Membership
+	walk(resp.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, value []byte) {
+		if num != membershipField || typ != protowire.BytesType {
+			return
+		}
+
+		b, n := protowire.ConsumeBytes(value)
+		if n < 0 {
+			return
+		}
+
+		var ms Membership
+		walk(b, func(num protowire.Number, typ protowire.Type, value []byte) {
+			if typ != protowire.BytesType {
+				return
+			}
+
+			v, _ := protowire.ConsumeString(value)
+			switch num {
+			case 1:
+				ms.Username = v
+			case 2:
+				ms.Group = v
+			case 3:
+				ms.Member = v
+			}
+		})
+		memberships = append(memberships, ms)
+	})
+
+	return memberships
+}
+
+// walk calls fn with the number, the type and the raw value of every field in raw.
+// It stops at the first malformed field.
+func walk(raw []byte, fn func(num protowire.Number, typ protowire.Type, value []byte)) {
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return
+		}
+		raw = raw[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return
+		}
+		fn(num, typ, raw[:n])
+		raw = raw[n:]
+	}
+}
+
+// Index maps the members to the subjects of the groups and roles containing them.
+type Index map[string][]string
+
+// NewIndexes indexes the memberships by the username of the group owner.
+func NewIndexes(memberships []Membership) map[string]Index {
+	indexes := make(map[string]Index)
+	for _, ms := range memberships {
+		if indexes[ms.Username] == nil {
+			indexes[ms.Username] = make(Index)
+		}
+		indexes[ms.Username][ms.Member] = append(indexes[ms.Username][ms.Member], ms.Group)
+	}
+
+	return indexes
+}
+
+// Groups returns the subjects of the groups and roles containing the member.
+func (i Index) Groups(member string) []string {
+	return i[member]
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package membership
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOnly(t *testing.T) {
+	req := &pb.ListPoliciesRequest{}
+	if Only(req) {
+		t.Fatalf("Only() on empty request should be false")
+	}
+
+	SetOnly(req)
+
+	raw, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	got := &pb.ListPoliciesRequest{}
+	if err := proto.Unmarshal(raw, got); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	if !Only(got) {
+		t.Errorf("Only() = false, want true")
+	}
+}
+
+func TestSet(t *testing.T) {
+	memberships := []Membership{
+		{Username: "colin", Group: "groups:admins", Member: "users:peter"},
+		{Username: "colin", Group: "roles:auditor", Member: "users:peter"},
+		{Username: "admin", Group: "groups:dev", Member: "users:maria"},
+	}
+
+	resp := &pb.ListPoliciesResponse{TotalCount: 1}
+	Set(resp, memberships)
+
+	// the memberships must survive the wire
+	raw, err := proto.Marshal(resp)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	got := &pb.ListPoliciesResponse{}
+	if err := proto.Unmarshal(raw, got); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(Get(got), memberships) {
+		t.Errorf("Get() = %v, want %v", Get(got), memberships)
+	}
+}
+
+func TestNewIndexes(t *testing.T) {
+	indexes := NewIndexes([]Membership{
+		{Username: "colin", Group: "groups:admins", Member: "users:peter"},
+		{Username: "colin", Group: "roles:auditor", Member: "users:peter"},
+		{Username: "admin", Group: "groups:dev", Member: "users:peter"},
+	})
+
+	want := []string{"groups:admins", "roles:auditor"}
+	if got := indexes["colin"].Groups("users:peter"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Index.Groups() = %v, want %v", got, want)
+	}
+
+	if got := indexes["colin"].Groups("users:maria"); len(got) != 0 {
+		t.Errorf("Index.Groups() = %v, want empty", got)
+	}
+
+	// the index of an unknown user is empty
+	if got := indexes["unknown"].Groups("users:peter"); len(got) != 0 {
+		t.Errorf("Index.Groups() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// auditIgnoredPaths are the routes which are sent with a changing method but do not change
+// any resource.
+var auditIgnoredPaths = map[string]bool{
+	"/v1/policy-validations": true,
+}
+
+// Audit records the requests of the authenticated users which change a resource, whether
+// they succeeded or not, to answer who did what. Failing to save the event never fails the
+// request.
+func Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		verb := v1.AuditVerb(c.Request.Method)
+		username := c.GetString(UsernameKey)
+		// the unmatched routes and the anonymous requests are not audited
+		if verb == "" || username == "" || c.FullPath() == "" || auditIgnoredPaths[c.FullPath()] {
+			return
+		}
+
+		event := &v1.AuditEvent{
+			Username:     username,
+			Verb:         verb,
+			Resource:     auditResource(c.Request.URL.Path),
+			ResourceName: c.Param("name"),
+			Method:       c.Request.Method,
+			Path:         c.Request.URL.Path,
+			StatusCode:   c.Writer.Status(),
+			IP:           c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+			RequestID:    c.Writer.Header().Get(XRequestIDKey),
+		}
+		if id := c.Param("id"); id != "" {
+			event.ResourceName = id
+		}
+
+		if err := store.Client().AuditEvents().Create(c, event, metav1.CreateOptions{}); err != nil {
+			log.L(c).Warnf("save audit event of user %s failed: %s", username, err.Error())
+		}
+	}
+}
+
+// auditResource returns the resource of a path, e.g. policies for /v1/policies/:name, or
+// users for /scim/v2/Users/:id.
+func auditResource(path string) string {
+	pathSplit := strings.Split(path, "/")
+	if len(pathSplit) > 3 && pathSplit[1] == "scim" {
+		return strings.ToLower(pathSplit[3])
+	}
+
+	if len(pathSplit) > 2 {
+		return pathSplit[2]
+	}
+
+	return ""
+}
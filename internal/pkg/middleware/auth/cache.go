@@ -5,6 +5,7 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,12 +17,16 @@ import (
 
 	"github.com/marmotedu/iam/internal/pkg/code"
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/revocation"
+	"github.com/marmotedu/iam/internal/pkg/scope"
+	"github.com/marmotedu/iam/internal/pkg/session"
 )
 
 // Defined errors.
 var (
 	ErrMissingKID    = errors.New("Invalid token format: missing kid field in claims")
 	ErrMissingSecret = errors.New("Can not obtain secret information from cache")
+	ErrEmptySecret   = errors.New("Secret has no key")
 )
 
 // Secret contains the basic information of the secret key.
@@ -30,77 +35,155 @@ type Secret struct {
 	ID       string
 	Key      string
 	Expires  int64
+	// Scope restricts what the secret can authorize, nil means unrestricted.
+	Scope *scope.Scope
+	// Session restricts the temporary credentials to the session policies, nil means the
+	// secret is not temporary.
+	Session *session.Session
+	// Denied is the reason the owner of the secret is not authenticated, e.g. it is suspended,
+	// nil means the owner is active. It is only reported once the signature is verified.
+	Denied error
 }
 
 // CacheStrategy defines jwt bearer authentication strategy which called `cache strategy`.
 // Secrets are obtained through grpc api interface and cached in memory.
 type CacheStrategy struct {
-	get func(kid string) (Secret, error)
+	get  func(kid string) (Secret, error)
+	used func(secretID string)
 }
 
 var _ middleware.AuthStrategy = &CacheStrategy{}
 
 // NewCacheStrategy create cache strategy with function which can list and cache secrets.
 func NewCacheStrategy(get func(kid string) (Secret, error)) CacheStrategy {
-	return CacheStrategy{get}
+	return CacheStrategy{get: get}
+}
+
+// WithUsage returns a copy of the strategy calling used with the ID of the secret which signed
+// each accepted token.
+func (cache CacheStrategy) WithUsage(used func(secretID string)) CacheStrategy {
+	cache.used = used
+
+	return cache
 }
 
 // AuthFunc defines cache strategy as the gin authentication middleware.
 func (cache CacheStrategy) AuthFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		header := c.Request.Header.Get("Authorization")
-		if len(header) == 0 {
-			core.WriteResponse(c, errors.WithCode(code.ErrMissingHeader, "Authorization header cannot be empty."), nil)
+		secret, err := cache.Verify(c.Request.Header.Get("Authorization"))
+		if err != nil {
+			core.WriteResponse(c, err, nil)
 			c.Abort()
 
 			return
 		}
 
-		var rawJWT string
-		// Parse the header to get the token part.
-		fmt.Sscanf(header, "Bearer %s", &rawJWT)
+		c.Set(middleware.UsernameKey, secret.Username)
+		c.Set(middleware.SecretIDKey, secret.ID)
+		if secret.Scope != nil {
+			c.Set(middleware.ScopeKey, secret.Scope)
+		}
+		if secret.Session != nil {
+			c.Set(middleware.SessionKey, secret.Session)
+		}
+		c.Next()
+	}
+}
 
-		// Use own validation logic, see below
-		var secret Secret
+// Verify verifies the bearer token in the given Authorization header and returns the secret
+// which signed it.
+func (cache CacheStrategy) Verify(header string) (Secret, error) {
+	secret, _, err := cache.VerifyAudiences(header)
 
-		claims := &jwt.MapClaims{}
-		// Verify the token
-		parsedT, err := jwt.ParseWithClaims(rawJWT, claims, func(token *jwt.Token) (interface{}, error) {
-			// Validate the alg is HMAC signature
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
+	return secret, err
+}
 
-			kid, ok := token.Header["kid"].(string)
-			if !ok {
-				return nil, ErrMissingKID
-			}
+// VerifyAudiences verifies the bearer token like Verify, and also returns the audiences the
+// token is issued for.
+func (cache CacheStrategy) VerifyAudiences(header string) (Secret, []string, error) {
+	if len(header) == 0 {
+		return Secret{}, nil, errors.WithCode(code.ErrMissingHeader, "Authorization header cannot be empty.")
+	}
 
-			var err error
-			secret, err = cache.get(kid)
-			if err != nil {
-				return nil, ErrMissingSecret
-			}
+	var rawJWT string
+	// Parse the header to get the token part.
+	fmt.Sscanf(header, "Bearer %s", &rawJWT)
 
-			return []byte(secret.Key), nil
-		})
-		if err != nil || !parsedT.Valid {
-			core.WriteResponse(c, errors.WithCode(code.ErrSignatureInvalid, err.Error()), nil)
-			c.Abort()
+	// Use own validation logic, see below
+	var secret Secret
 
-			return
+	claims := &jwt.MapClaims{}
+	// Verify the token
+	parsedT, err := jwt.ParseWithClaims(rawJWT, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate the alg is HMAC signature
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		if KeyExpired(secret.Expires) {
-			tm := time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05")
-			core.WriteResponse(c, errors.WithCode(code.ErrExpired, "expired at: %s", tm), nil)
-			c.Abort()
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrMissingKID
+		}
 
-			return
+		var err error
+		secret, err = cache.get(kid)
+		if err != nil {
+			return nil, ErrMissingSecret
 		}
 
-		c.Set(middleware.UsernameKey, secret.Username)
-		c.Next()
+		// the HMAC signatures made with an empty key can be forged by anyone knowing the kid
+		if secret.Key == "" {
+			return nil, ErrEmptySecret
+		}
+
+		return []byte(secret.Key), nil
+	})
+	if err != nil || !parsedT.Valid {
+		return Secret{}, nil, errors.WithCode(code.ErrSignatureInvalid, err.Error())
+	}
+
+	if KeyExpired(secret.Expires) {
+		tm := time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05")
+
+		return Secret{}, nil, errors.WithCode(code.ErrExpired, "expired at: %s", tm)
+	}
+
+	if secret.Denied != nil {
+		return Secret{}, nil, secret.Denied
+	}
+
+	if revocation.IsRevoked(context.Background(), rawJWT, secret.Username, *claims) {
+		return Secret{}, nil, errors.WithCode(code.ErrTokenRevoked, "token is revoked")
+	}
+
+	aud := audiences(*claims)
+	if !secret.Scope.AllowAudience(aud) {
+		return Secret{}, nil, errors.WithCode(code.ErrOutOfScope, "audience is not allowed by the secret")
+	}
+
+	if cache.used != nil {
+		cache.used(secret.ID)
+	}
+
+	return secret, aud, nil
+}
+
+// audiences returns the values of the `aud` claim which can be either a string or an array.
+func audiences(claims jwt.MapClaims) []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		ret := make([]string, 0, len(aud))
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+
+		return ret
+	default:
+		return nil
 	}
 }
 
@@ -0,0 +1,152 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/pkg/sdk/signer"
+)
+
+// HMACStrategy defines request signing authentication strategy. The requests are signed with
+// the secretKey of a secret instead of carrying a bearer token, see package signer.
+type HMACStrategy struct {
+	get     func(kid string) (Secret, error)
+	maxSkew time.Duration
+	now     func() time.Time
+	used    func(secretID string)
+
+	lock sync.Mutex
+	// seen holds the signatures accepted within the allowed clock skew and when they expire,
+	// the expired ones are dropped every maxSkew.
+	seen      map[string]time.Time
+	nextSweep time.Time
+}
+
+var _ middleware.AuthStrategy = &HMACStrategy{}
+
+// NewHMACStrategy create hmac strategy with function which can get the secrets.
+func NewHMACStrategy(get func(kid string) (Secret, error)) *HMACStrategy {
+	return &HMACStrategy{
+		get:     get,
+		maxSkew: signer.DefaultMaxSkew,
+		now:     time.Now,
+		seen:    map[string]time.Time{},
+	}
+}
+
+// AuthFunc defines hmac strategy as the gin authentication middleware.
+func (h *HMACStrategy) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret, err := h.Verify(c)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(middleware.UsernameKey, secret.Username)
+		c.Set(middleware.SecretIDKey, secret.ID)
+		if secret.Scope != nil {
+			c.Set(middleware.ScopeKey, secret.Scope)
+		}
+		if secret.Session != nil {
+			c.Set(middleware.SessionKey, secret.Session)
+		}
+		c.Next()
+	}
+}
+
+// Verify verifies the signature of the request and returns the secret which signed it.
+// A signature is accepted only once, so that a captured request can not be replayed.
+func (h *HMACStrategy) Verify(c *gin.Context) (Secret, error) {
+	a, err := signer.ParseAuthorization(c.Request.Header.Get("Authorization"))
+	if err != nil {
+		return Secret{}, errors.WithCode(code.ErrInvalidAuthHeader, err.Error())
+	}
+
+	secret, err := h.get(a.SecretID)
+	if err != nil {
+		return Secret{}, errors.WithCode(code.ErrSignatureInvalid, ErrMissingSecret.Error())
+	}
+
+	if secret.Key == "" {
+		return Secret{}, errors.WithCode(code.ErrSignatureInvalid, ErrEmptySecret.Error())
+	}
+
+	now := h.now()
+	if err := signer.Verify(c.Request, a, secret.Key, h.maxSkew, now); err != nil {
+		return Secret{}, errors.WithCode(code.ErrSignatureInvalid, err.Error())
+	}
+
+	if KeyExpired(secret.Expires) {
+		tm := time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05")
+
+		return Secret{}, errors.WithCode(code.ErrExpired, "expired at: %s", tm)
+	}
+
+	if secret.Denied != nil {
+		return Secret{}, secret.Denied
+	}
+
+	var audiences []string
+	if aud := c.Request.Header.Get(signer.AudienceHeader); aud != "" {
+		audiences = []string{aud}
+	}
+
+	if !secret.Scope.AllowAudience(audiences) {
+		return Secret{}, errors.WithCode(code.ErrOutOfScope, "audience is not allowed by the secret")
+	}
+
+	if !h.accept(a.Signature, now) {
+		return Secret{}, errors.WithCode(code.ErrSignatureInvalid, "signature has already been used")
+	}
+
+	if h.used != nil {
+		h.used(secret.ID)
+	}
+
+	return secret, nil
+}
+
+// WithUsage makes the strategy call used with the ID of the secret which signed each accepted
+// request.
+func (h *HMACStrategy) WithUsage(used func(secretID string)) *HMACStrategy {
+	h.used = used
+
+	return h
+}
+
+// accept records the signature and reports whether it was not seen before.
+func (h *HMACStrategy) accept(signature string, now time.Time) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if now.After(h.nextSweep) {
+		for s, expires := range h.seen {
+			if now.After(expires) {
+				delete(h.seen, s)
+			}
+		}
+		h.nextSweep = now.Add(h.maxSkew)
+	}
+
+	if _, ok := h.seen[signature]; ok {
+		return false
+	}
+
+	// a signature is valid at most 2 * maxSkew after its date is within the skew
+	h.seen[signature] = now.Add(2 * h.maxSkew)
+
+	return true
+}
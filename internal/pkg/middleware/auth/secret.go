@@ -0,0 +1,46 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/pkg/sdk/signer"
+)
+
+// SecretStrategy defines authentication strategy which chooses between the bearer tokens and the
+// signed requests, both authenticated by the secrets, according to the Authorization header.
+type SecretStrategy struct {
+	bearer middleware.AuthStrategy
+	hmac   middleware.AuthStrategy
+}
+
+var _ middleware.AuthStrategy = &SecretStrategy{}
+
+// NewSecretStrategy create secret strategy with bearer strategy and hmac strategy.
+func NewSecretStrategy(bearer, hmac middleware.AuthStrategy) SecretStrategy {
+	return SecretStrategy{
+		bearer: bearer,
+		hmac:   hmac,
+	}
+}
+
+// AuthFunc defines secret strategy as the gin authentication middleware.
+func (s SecretStrategy) AuthFunc() gin.HandlerFunc {
+	bearer, hmac := s.bearer.AuthFunc(), s.hmac.AuthFunc()
+
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.Header.Get("Authorization"), signer.Algorithm+" ") {
+			hmac(c)
+
+			return
+		}
+
+		bearer(c)
+	}
+}
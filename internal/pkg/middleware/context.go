@@ -13,6 +13,27 @@ import (
 // UsernameKey defines the key in gin context which represents the owner of the secret.
 const UsernameKey = "username"
 
+// ScopeKey defines the key used to store the scope of the secret which signed the request.
+const ScopeKey = "scope"
+
+// SessionKey defines the key used to store the session of the temporary credentials which
+// signed the request.
+const SessionKey = "session"
+
+// SecretIDKey defines the key used to store the id of the secret which signed the request.
+const SecretIDKey = "secretID"
+
+// PlatformAdminKey defines the key set by Validation when the user is a platform administrator.
+const PlatformAdminKey = "platformAdmin"
+
+// DelegatedAdminKey defines the key set by Validation when a delegated administrator is let
+// through to a user resource, the controllers check that the user is in its scope.
+const DelegatedAdminKey = "delegatedAdmin"
+
+// ChangePasswordOnlyKey defines the key set when the token of the request can only change the
+// password of its user, e.g. the token issued for an expired password.
+const ChangePasswordOnlyKey = "changePasswordOnly"
+
 // Context is a middleware that injects common prefix fields to gin.Context.
 func Context() gin.HandlerFunc {
 	return func(c *gin.Context) {
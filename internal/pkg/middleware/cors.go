@@ -5,6 +5,7 @@
 package middleware
 
 import (
+	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,17 +16,42 @@ const (
 	maxAge = 12
 )
 
+var (
+	corsMu      sync.RWMutex
+	corsOrigins = []string{"*"}
+)
+
+// SetCorsOrigins sets the origins allowed by the cors middleware, * allows any origin. They
+// can be changed while the requests are served.
+func SetCorsOrigins(origins []string) {
+	corsMu.Lock()
+	defer corsMu.Unlock()
+
+	corsOrigins = origins
+}
+
+// allowOrigin reports whether the origin is one of the allowed origins.
+func allowOrigin(origin string) bool {
+	corsMu.RLock()
+	defer corsMu.RUnlock()
+
+	for _, allowed := range corsOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Cors add cors headers.
 func Cors() gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"PUT", "PATCH", "GET", "POST", "OPTIONS", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Accept", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
-		AllowOriginFunc: func(origin string) bool {
-			return origin == "https://github.com"
-		},
-		MaxAge: maxAge * time.Hour,
+		AllowOriginFunc:  allowOrigin,
+		MaxAge:           maxAge * time.Hour,
 	})
 }
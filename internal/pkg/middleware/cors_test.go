@@ -0,0 +1,38 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCors(t *testing.T) {
+	defer SetCorsOrigins([]string{"*"})
+
+	g := gin.New()
+	g.Use(Cors())
+	g.GET("/v1/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/v1/users", nil)
+		req.Header.Set("Origin", origin)
+		g.ServeHTTP(w, req)
+
+		return w
+	}
+
+	assert.Equal(t, "https://example.com", do("https://example.com").Header().Get("Access-Control-Allow-Origin"))
+
+	// the origins changed at runtime apply to the next requests
+	SetCorsOrigins([]string{"https://iam.marmotedu.com"})
+	assert.Equal(t, http.StatusForbidden, do("https://example.com").Code)
+	assert.Equal(t, "https://iam.marmotedu.com", do("https://iam.marmotedu.com").Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	g := gin.New()
+	g.Use(ETag())
+	g.GET("/v1/policies", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"totalCount": 1})
+	})
+	g.GET("/v1/policies/:name", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"code": 110201})
+	})
+
+	do := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		g.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := do("/v1/policies", "")
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"totalCount":1}`, w.Body.String())
+	assert.NotEmpty(t, etag)
+
+	w = do("/v1/policies", `"stale", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = do("/v1/policies", `"stale"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"totalCount":1}`, w.Body.String())
+
+	// the errors have no etag
+	w = do("/v1/policies/policy", etag)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Equal(t, `{"code":110201}`, w.Body.String())
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/json"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/features"
+)
+
+// I18n is a middleware that translates the external message of the error responses to the
+// language requested by the 'Accept-Language' header of the request.
+func I18n() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		acceptLanguage := c.GetHeader("Accept-Language")
+		if acceptLanguage != "" && features.Enabled(features.I18nErrorMessages) {
+			c.Writer = &i18nWriter{ResponseWriter: c.Writer, acceptLanguage: acceptLanguage}
+		}
+
+		c.Next()
+	}
+}
+
+// i18nResponse is an error response, the details of the validation errors are kept as is.
+type i18nResponse struct {
+	core.ErrResponse
+
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+// i18nWriter translates the message of the error response bodies, written at once by
+// core.WriteResponse.
+type i18nWriter struct {
+	gin.ResponseWriter
+	acceptLanguage string
+}
+
+func (w *i18nWriter) Write(data []byte) (int, error) {
+	if w.Status() < http.StatusBadRequest || w.Written() {
+		return w.ResponseWriter.Write(data)
+	}
+
+	var resp i18nResponse
+	if err := json.Unmarshal(data, &resp); err != nil || resp.Code == 0 {
+		return w.ResponseWriter.Write(data)
+	}
+
+	message, tag := code.Translate(resp.Code, resp.Message, w.acceptLanguage)
+	if message == resp.Message {
+		return w.ResponseWriter.Write(data)
+	}
+
+	resp.Message = message
+	translated, err := json.Marshal(resp)
+	if err != nil {
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.Header().Set("Content-Language", tag.String())
+	if _, err := w.ResponseWriter.Write(translated); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
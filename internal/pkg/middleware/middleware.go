@@ -55,7 +55,7 @@ func Secure(c *gin.Context) {
 
 func defaultMiddlewares() map[string]gin.HandlerFunc {
 	return map[string]gin.HandlerFunc{
-		"recovery":  gin.Recovery(),
+		"recovery":  Recovery(""),
 		"secure":    Secure,
 		"options":   Options,
 		"nocache":   NoCache,
@@ -22,7 +22,7 @@ func Publish() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
-		if c.Writer.Status() != http.StatusOK {
+		if c.Writer.Status() != http.StatusOK && c.Writer.Status() != http.StatusCreated {
 			log.L(c).Debugf("request failed with http status code `%d`, ignore publish message", c.Writer.Status())
 
 			return
@@ -34,29 +34,82 @@ func Publish() gin.HandlerFunc {
 		if len(pathSplit) > 2 {
 			resource = pathSplit[2]
 		}
+		// SCIM paths are like /scim/v2/Groups/<id>
+		if len(pathSplit) > 3 && pathSplit[1] == "scim" {
+			resource = strings.ToLower(pathSplit[3])
+		}
 
 		method := c.Request.Method
 
 		switch resource {
-		case "policies":
+		case "policies", "groups":
+			notify(c, method, load.NoticePolicyChanged)
+		case "secrets", "sts":
+			notify(c, method, load.NoticeSecretChanged)
+		case "import":
 			notify(c, method, load.NoticePolicyChanged)
-		case "secrets":
 			notify(c, method, load.NoticeSecretChanged)
+		case "users":
+			// the secrets of a user are authenticated by iam-authz-server depending on its state,
+			// the SCIM users are identified by their name as well
+			username := c.Param("name")
+			if username == "" {
+				username = c.Param("id")
+			}
+			if username != "" && method != http.MethodGet {
+				publish(c, load.NoticeSecretChanged, load.Event{Type: load.EventUpdated, Username: username})
+			}
 		default:
 		}
 	}
 }
 
-func notify(ctx context.Context, method string, command load.NotificationCommand) {
-	switch method {
-	case http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch:
-		redisStore := &storage.RedisCluster{}
-		message, _ := json.Marshal(load.Notification{Command: command})
+func notify(c *gin.Context, method string, command load.NotificationCommand) {
+	var eventType load.EventType
 
-		if err := redisStore.Publish(load.RedisPubSubChannel, string(message)); err != nil {
-			log.L(ctx).Errorw("publish redis message failed", "error", err.Error())
-		}
-		log.L(ctx).Debugw("publish redis message", "method", method, "command", command)
+	switch method {
+	case http.MethodPost:
+		eventType = load.EventCreated
+	case http.MethodPut, http.MethodPatch:
+		eventType = load.EventUpdated
+	case http.MethodDelete:
+		eventType = load.EventDeleted
 	default:
+		return
+	}
+
+	event := load.Event{
+		Type:     eventType,
+		Username: c.GetString(UsernameKey),
+		Names:    c.QueryArray("name"),
+	}
+	if name := c.Param("name"); name != "" {
+		event.Names = append(event.Names, name)
+	}
+	if id := c.Param("id"); id != "" {
+		event.Names = append(event.Names, id)
+	}
+
+	publish(c, command, event)
+}
+
+// PublishUserChanged notifies iam-authz-server that the secrets and the policies of a user
+// changed out of a request, e.g. by an operation.
+func PublishUserChanged(ctx context.Context, username string) {
+	publish(ctx, load.NoticeSecretChanged, load.Event{Type: load.EventUpdated, Username: username})
+	publish(ctx, load.NoticePolicyChanged, load.Event{Type: load.EventUpdated, Username: username})
+}
+
+// publish allocates the sequence of the event and publishes it.
+func publish(ctx context.Context, command load.NotificationCommand, event load.Event) {
+	event.Sequence = load.NextSequence()
+	load.RecordChange(command, event)
+
+	redisStore := &storage.RedisCluster{}
+	message, _ := json.Marshal(load.NewEventNotification(command, event))
+
+	if err := redisStore.Publish(load.RedisPubSubChannel, string(message)); err != nil {
+		log.L(ctx).Errorw("publish redis message failed", "error", err.Error())
 	}
+	log.L(ctx).Debugw("publish redis message", "command", command, "sequence", event.Sequence)
 }
@@ -0,0 +1,165 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"runtime/pprof"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	merrors "github.com/marmotedu/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// XCrashIDKey defines the header returning the id of the crash record of a request which
+// panicked.
+const XCrashIDKey = "X-Crash-ID"
+
+// CrashRecord describes a panic recovered while serving a request.
+type CrashRecord struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	Panic      string    `json:"panic"`
+	Stack      string    `json:"stack"`
+	RequestID  string    `json:"requestID,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Route      string    `json:"route,omitempty"`
+	Query      string    `json:"query,omitempty"`
+	ClientIP   string    `json:"clientIP"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	Goroutines string    `json:"goroutines"`
+}
+
+// crashResponse is the error returned to the client, along with the id of the crash record.
+type crashResponse struct {
+	core.ErrResponse
+	CrashID string `json:"crashID"`
+}
+
+// Recovery is a middleware recovering from the panics of the handlers. The panic is logged as a
+// crash record, which is written to crashDir as well unless it is empty, and the client
+// receives the internal server error along with the id of the record.
+func Recovery(crashDir string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			// the handler aborted the response on purpose, the server closes the connection
+			if err, ok := r.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(r)
+			}
+
+			record := newCrashRecord(c, r)
+			log.L(c).Errorw("Recovered from panic",
+				"crashID", record.ID,
+				"panic", record.Panic,
+				"method", record.Method,
+				"path", record.Path,
+				"clientIP", record.ClientIP,
+				"stack", record.Stack,
+				"goroutines", record.Goroutines,
+			)
+
+			if crashDir != "" {
+				if err := writeCrashRecord(crashDir, record); err != nil {
+					log.L(c).Errorf("Write crash record %s failed: %s", record.ID, err.Error())
+				}
+			}
+
+			// the response can not be written once the client went away
+			if brokenPipe(r) {
+				c.Abort()
+
+				return
+			}
+
+			coder := merrors.ParseCoder(merrors.WithCode(code.ErrUnknown, "%v", r))
+			c.Header(XCrashIDKey, record.ID)
+			c.AbortWithStatusJSON(coder.HTTPStatus(), crashResponse{
+				ErrResponse: core.ErrResponse{
+					Code:      coder.Code(),
+					Message:   coder.String(),
+					Reference: coder.Reference(),
+				},
+				CrashID: record.ID,
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+func newCrashRecord(c *gin.Context, r interface{}) *CrashRecord {
+	var goroutines bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&goroutines, 2)
+
+	return &CrashRecord{
+		ID:         uuid.Must(uuid.NewV4()).String(),
+		Time:       time.Now(),
+		Panic:      fmt.Sprintf("%v", r),
+		Stack:      string(debug.Stack()),
+		RequestID:  c.Writer.Header().Get(XRequestIDKey),
+		Username:   c.GetString(UsernameKey),
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		Route:      c.FullPath(),
+		Query:      c.Request.URL.RawQuery,
+		ClientIP:   c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Goroutines: goroutines.String(),
+	}
+}
+
+// writeCrashRecord writes the record to crash-<time>-<id>.json in dir.
+func writeCrashRecord(dir string, record *CrashRecord) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("crash-%s-%s.json", record.Time.UTC().Format("20060102T150405Z"), record.ID)
+
+	return os.WriteFile(filepath.Join(dir, name), data, 0o600)
+}
+
+// brokenPipe reports whether the panic is caused by a connection closed by the client.
+func brokenPipe(r interface{}) bool {
+	err, ok := r.(error)
+	if !ok {
+		return false
+	}
+
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && strings.Contains(strings.ToLower(opErr.Error()), "broken pipe")
+}
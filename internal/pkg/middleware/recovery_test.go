@@ -0,0 +1,55 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func TestRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	g := gin.New()
+	g.Use(Recovery(dir))
+	g.GET("/v1/users/:name", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/users/colin?x=1", nil)
+	g.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var resp crashResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrUnknown, resp.Code)
+	assert.NotEmpty(t, resp.CrashID)
+	assert.Equal(t, resp.CrashID, w.Header().Get(XCrashIDKey))
+
+	files, err := filepath.Glob(filepath.Join(dir, "crash-*-"+resp.CrashID+".json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+
+	var record CrashRecord
+	assert.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "boom", record.Panic)
+	assert.Equal(t, "/v1/users/:name", record.Route)
+	assert.Equal(t, "x=1", record.Query)
+	assert.Contains(t, record.Stack, "TestRecovery")
+	assert.Contains(t, record.Goroutines, "goroutine")
+}
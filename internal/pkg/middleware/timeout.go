@@ -0,0 +1,57 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout is a middleware setting the deadline of the context of the request, it does
+// nothing if timeout is 0. The request is cancelled as well when the client goes away.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requestContext is the context of a request served by gin, the values set in the gin
+// context are looked up first.
+type requestContext struct {
+	context.Context
+	c *gin.Context
+}
+
+func (r *requestContext) Value(key interface{}) interface{} {
+	if v := r.c.Value(key); v != nil {
+		return v
+	}
+
+	return r.Context.Value(key)
+}
+
+// RequestContext returns a context cancelled along with the request when ctx is the gin
+// context serving it, as the gin context is never cancelled. The other contexts are returned
+// unchanged.
+func RequestContext(ctx context.Context) context.Context {
+	c, ok := ctx.(*gin.Context)
+	if !ok || c.Request == nil {
+		return ctx
+	}
+
+	return &requestContext{Context: c.Request.Context(), c: c}
+}
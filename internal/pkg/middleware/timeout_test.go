@@ -0,0 +1,41 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	g := gin.New()
+	g.Use(Timeout(10 * time.Millisecond))
+	g.GET("/v1/users", func(c *gin.Context) {
+		c.Set(UsernameKey, "colin")
+
+		ctx := RequestContext(c)
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, "colin", ctx.Value(UsernameKey))
+
+		<-ctx.Done()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+		c.Status(http.StatusGatewayTimeout)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/users", nil)
+	g.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	ctx := context.TODO()
+	assert.Equal(t, ctx, RequestContext(ctx))
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+)
+
+// ChangePasswordPath is the route changing the password of a user.
+const ChangePasswordPath = "/v1/users/:name/change-password"
+
+// ActiveUser denies the requests of the authenticated users which are not active, including
+// the users suspended or deactivated after their token was issued.
+// The requests authenticated by an expired password, and the ones whose token can only change
+// the password, can only change the password of their user.
+// It must be installed after the authentication middleware.
+func ActiveUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.GetString(UsernameKey)
+		user, err := store.Client().Users().Get(c, username, metav1.GetOptions{})
+		if err == nil {
+			err = userstate.Check(username, userstate.Of(user))
+		}
+
+		if err == nil && !changingOwnPassword(c, username) {
+			switch {
+			case c.GetBool(ChangePasswordOnlyKey):
+				err = errors.WithCode(code.ErrPasswordExpired, "the token can only change the password of user %s", username)
+			case strings.HasPrefix(c.Request.Header.Get("Authorization"), "Basic "):
+				// the basic authentication sends the password along with every request
+				err = passwordexpiry.Check(c, store.Client().Quotas(), user)
+			}
+		}
+
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// changingOwnPassword reports whether the request changes the password of the given user.
+func changingOwnPassword(c *gin.Context, username string) bool {
+	return c.Request.Method == http.MethodPut && c.FullPath() == ChangePasswordPath && c.Param("name") == username
+}
@@ -6,6 +6,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/core"
@@ -13,7 +14,9 @@ import (
 	"github.com/marmotedu/errors"
 
 	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
 	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
 )
 
 // Validation make sure users have the right resource permission and operation.
@@ -22,23 +25,60 @@ func Validation() gin.HandlerFunc {
 		if err := isAdmin(c); err != nil {
 			switch c.FullPath() {
 			case "/v1/users":
-				if c.Request.Method != http.MethodPost {
+				// the delegated administrators list the users in their scope
+				if c.Request.Method != http.MethodPost && (c.Request.Method != http.MethodGet || !delegate(c)) {
 					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
 					c.Abort()
 
 					return
 				}
-			case "/v1/users/:name", "/v1/users/:name/change_password":
+			case "/v1/users/:name", "/v1/users/:name/change_password", "/v1/users/:name/logins":
 				username := c.GetString("username")
-				if c.Request.Method == http.MethodDelete ||
-					(c.Request.Method != http.MethodDelete && username != c.Param("name")) {
+				if (c.Request.Method == http.MethodDelete ||
+					(c.Request.Method != http.MethodDelete && username != c.Param("name"))) && !delegate(c) {
+					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+					c.Abort()
+
+					return
+				}
+			case "/v1/users/:name/suspend", "/v1/users/:name/activate", "/v1/users/:name/deactivate",
+				"/v1/users/:name/purge":
+				// only the platform or delegated administrators change the state of the users, including
+				// their own, and purge them
+				if !delegate(c) {
+					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+					c.Abort()
+
+					return
+				}
+			case "/v1/tenants/:name/quota":
+				if c.Request.Method != http.MethodGet || tenantOf(c) != c.Param("name") {
+					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+					c.Abort()
+
+					return
+				}
+			case "/v1/operations/:id":
+				if operationOwner(c) != c.GetString(UsernameKey) {
 					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
 					c.Abort()
 
 					return
 				}
 			default:
+				// only administrators provision users and groups through SCIM, read the audit events,
+				// delete the tenants and export or import the snapshots
+				if strings.HasPrefix(c.FullPath(), "/scim/") || c.FullPath() == "/v1/audits" ||
+					c.FullPath() == "/v1/tenants/:name" || c.FullPath() == "/v1/export" ||
+					c.FullPath() == "/v1/import" {
+					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+					c.Abort()
+
+					return
+				}
 			}
+		} else {
+			c.Set(PlatformAdminKey, true)
 		}
 
 		c.Next()
@@ -60,3 +100,36 @@ func isAdmin(c *gin.Context) error {
 
 	return nil
 }
+
+// delegate lets a delegated administrator through to the user resources, the controllers check
+// that the user is in the scope of the administrator.
+func delegate(c *gin.Context) bool {
+	user, err := store.Client().Users().Get(c, c.GetString(UsernameKey), metav1.GetOptions{})
+	if err != nil || user.Extend[adminscope.ExtendKey] == nil {
+		return false
+	}
+
+	c.Set(DelegatedAdminKey, true)
+
+	return true
+}
+
+// tenantOf returns the tenant of the user, empty for the default tenant.
+func tenantOf(c *gin.Context) string {
+	user, err := store.Client().Users().Get(c, c.GetString(UsernameKey), metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+
+	return tenant.FromExtend(user.Extend)
+}
+
+// operationOwner returns the user who requested the operation, empty if it is not found.
+func operationOwner(c *gin.Context) string {
+	op, err := store.Client().Operations().Get(c, c.Param("id"), metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+
+	return op.Username
+}
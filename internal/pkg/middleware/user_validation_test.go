@@ -0,0 +1,53 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/adminscope"
+)
+
+func TestValidation_purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockFactory.EXPECT().Users().AnyTimes().Return(mockUserStore)
+	mockUserStore.EXPECT().Get(gomock.Any(), "james", gomock.Any()).AnyTimes().
+		Return(&v1.User{ObjectMeta: metav1.ObjectMeta{Name: "james"}}, nil)
+	mockUserStore.EXPECT().Get(gomock.Any(), "orgadmin", gomock.Any()).AnyTimes().
+		Return(&v1.User{ObjectMeta: metav1.ObjectMeta{
+			Name:   "orgadmin",
+			Extend: metav1.Extend{adminscope.ExtendKey: map[string]interface{}{"tenant": true}},
+		}}, nil)
+
+	defer store.SetClient(store.Client())
+	store.SetClient(mockFactory)
+
+	for caller, want := range map[string]int{"james": http.StatusForbidden, "orgadmin": http.StatusAccepted} {
+		g := gin.New()
+		g.Use(func(c *gin.Context) { c.Set(UsernameKey, caller) }, Validation())
+		g.POST("/v1/users/:name/purge", func(c *gin.Context) {
+			c.Status(http.StatusAccepted)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v1/users/admin/purge", nil)
+		g.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, "purge by %s", caller)
+	}
+}
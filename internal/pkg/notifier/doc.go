@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package notifier notifies the users of the events of their accounts, e.g. a lockout or a
+// secret about to expire. The notifications are rendered from templates and delivered by the
+// configured providers: mails through a SMTP server, text messages through a SMS gateway and
+// json posted to a webhook.
+package notifier // import "github.com/marmotedu/iam/internal/pkg/notifier"
@@ -0,0 +1,143 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Event is the kind of a notification, it names the template the notification is rendered from.
+type Event string
+
+// Define the events the users are notified of.
+const (
+	// PasswordReset is sent when a password reset is requested, with the resetURL data.
+	PasswordReset Event = "password-reset"
+	// MFAEnrollment is sent when a multi-factor authentication device is enrolled, with the
+	// device data.
+	MFAEnrollment Event = "mfa-enrollment"
+	// SecretExpiry is sent before a secret expires, with the secretID, secretName and expiresAt data.
+	SecretExpiry Event = "secret-expiry"
+	// Lockout is sent when the user is locked out, with the reason data.
+	Lockout Event = "lockout"
+	// PasswordExpiry is sent before the password expires, with the expiresAt data.
+	PasswordExpiry Event = "password-expiry"
+)
+
+// Events lists the known events.
+var Events = []Event{PasswordReset, MFAEnrollment, SecretExpiry, Lockout, PasswordExpiry}
+
+// Recipient is the user a notification is sent to. The providers skip the recipients without
+// the address they deliver to.
+type Recipient struct {
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// RecipientOf returns the recipient of the notifications of the user.
+func RecipientOf(user *v1.User) Recipient {
+	return Recipient{Username: user.Name, Email: user.Email, Phone: user.Phone}
+}
+
+// Notification is a rendered notification.
+type Notification struct {
+	Event     Event                  `json:"event"`
+	Recipient Recipient              `json:"recipient"`
+	Subject   string                 `json:"subject"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// Provider delivers the notifications.
+type Provider interface {
+	// Name returns the name of the provider, used in the logs.
+	Name() string
+	// Send delivers the notification, nothing is done if the recipient has no address the
+	// provider delivers to.
+	Send(ctx context.Context, n *Notification) error
+}
+
+// Notifier renders the notifications and delivers them with every provider.
+type Notifier struct {
+	templates *Templates
+	providers []Provider
+}
+
+// New creates a notifier delivering the notifications rendered from the templates with the
+// given providers.
+func New(templates *Templates, providers ...Provider) *Notifier {
+	return &Notifier{templates: templates, providers: providers}
+}
+
+// Send renders the notification of the event and delivers it with every provider. The
+// notification is only logged if there is no provider.
+func (n *Notifier) Send(ctx context.Context, event Event, to Recipient, data map[string]interface{}) error {
+	notification, err := n.templates.Render(event, to, data)
+	if err != nil {
+		return err
+	}
+
+	if len(n.providers) == 0 {
+		log.L(ctx).Infow("no notification provider configured", "event", event, "username", to.Username)
+
+		return nil
+	}
+
+	var errs []error
+	for _, provider := range n.providers {
+		if err := provider.Send(ctx, notification); err != nil {
+			errs = append(errs, errors.Wrapf(err, "send %s notification with %s failed", event, provider.Name()))
+		}
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+var (
+	lock sync.RWMutex
+	std  = New(DefaultTemplates())
+)
+
+// SetNotifier sets the notifier used by Send and Notify.
+func SetNotifier(n *Notifier) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	std = n
+}
+
+func defaultNotifier() *Notifier {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return std
+}
+
+// Send renders and delivers the notification with the notifier set by SetNotifier.
+func Send(ctx context.Context, event Event, to Recipient, data map[string]interface{}) error {
+	return defaultNotifier().Send(ctx, event, to, data)
+}
+
+// Notify sends the notification in the background, so that a slow or unavailable provider
+// does not delay the request notifying the user. The failures are logged.
+func Notify(ctx context.Context, event Event, to Recipient, data map[string]interface{}) {
+	n := defaultNotifier()
+	logger := log.L(ctx)
+
+	go func() {
+		if err := n.Send(context.Background(), event, to, data); err != nil {
+			logger.Errorf("notify user %s failed: %s", to.Username, err.Error())
+		}
+	}()
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// NotificationOptions contains configuration items related to the notifications of the users.
+// A provider is enabled when its server is set, the notifications are only logged when none is.
+type NotificationOptions struct {
+	TemplateDir string         `json:"template-dir" mapstructure:"template-dir"`
+	Timeout     time.Duration  `json:"timeout"      mapstructure:"timeout"`
+	SMTP        SMTPOptions    `json:"smtp"         mapstructure:"smtp"`
+	Webhook     WebhookOptions `json:"webhook"     mapstructure:"webhook"`
+	SMS         SMSOptions     `json:"sms"          mapstructure:"sms"`
+}
+
+// SMTPOptions contains the SMTP server the notifications are mailed through.
+type SMTPOptions struct {
+	Host     string `json:"host"     mapstructure:"host"`
+	Port     int    `json:"port"     mapstructure:"port"`
+	Username string `json:"username" mapstructure:"username"`
+	Password string `json:"-"        mapstructure:"password"`
+	From     string `json:"from"     mapstructure:"from"`
+}
+
+// WebhookOptions contains the url the notifications are posted to.
+type WebhookOptions struct {
+	URL string `json:"url" mapstructure:"url"`
+}
+
+// SMSOptions contains the gateway the text messages are sent through.
+type SMSOptions struct {
+	URL   string `json:"url" mapstructure:"url"`
+	Token string `json:"-"   mapstructure:"token"`
+}
+
+// NewNotificationOptions creates a NotificationOptions object with default parameters.
+func NewNotificationOptions() *NotificationOptions {
+	return &NotificationOptions{
+		TemplateDir: "",
+		Timeout:     10 * time.Second,
+		SMTP: SMTPOptions{
+			Port: 587,
+		},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *NotificationOptions) Validate() []error {
+	errs := []error{}
+
+	if o.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("--notification.timeout must be greater than 0"))
+	}
+
+	if o.SMTP.Host != "" {
+		if o.SMTP.Port <= 0 || o.SMTP.Port > 65535 {
+			errs = append(errs, fmt.Errorf("--notification.smtp.port %d must be between 1 and 65535", o.SMTP.Port))
+		}
+
+		if _, err := mail.ParseAddress(o.SMTP.From); err != nil {
+			errs = append(errs, fmt.Errorf("--notification.smtp.from must be a mail address: %w", err))
+		}
+	}
+
+	for _, u := range []struct{ flag, value string }{
+		{"--notification.webhook.url", o.Webhook.URL},
+		{"--notification.sms.url", o.SMS.URL},
+	} {
+		if u.value == "" {
+			continue
+		}
+
+		if parsed, err := url.Parse(u.value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") ||
+			parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("%s must be a http:// or https:// url", u.flag))
+		}
+	}
+
+	if _, err := LoadTemplates(o.TemplateDir); err != nil {
+		errs = append(errs, fmt.Errorf("--notification.template-dir: %w", err))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the notifications for a specific server to the specified
+// FlagSet.
+func (o *NotificationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringVar(&o.TemplateDir, "notification.template-dir", o.TemplateDir, ""+
+		"Directory of the <event>.tmpl files overriding the built-in notification templates, "+
+		"the events are password-reset, mfa-enrollment, secret-expiry, lockout and password-expiry.")
+	fs.DurationVar(&o.Timeout, "notification.timeout", o.Timeout, ""+
+		"Timeout of the delivery of a notification by each provider.")
+
+	fs.StringVar(&o.SMTP.Host, "notification.smtp.host", o.SMTP.Host, ""+
+		"Host of the SMTP server the notifications are mailed through. No mail is sent if it is empty.")
+	fs.IntVar(&o.SMTP.Port, "notification.smtp.port", o.SMTP.Port, ""+
+		"Port of the SMTP server.")
+	fs.StringVar(&o.SMTP.Username, "notification.smtp.username", o.SMTP.Username, ""+
+		"Username used to authenticate to the SMTP server, no authentication if it is empty.")
+	fs.StringVar(&o.SMTP.Password, "notification.smtp.password", o.SMTP.Password, ""+
+		"Password used to authenticate to the SMTP server.")
+	fs.StringVar(&o.SMTP.From, "notification.smtp.from", o.SMTP.From, ""+
+		"Sender address of the mails.")
+
+	fs.StringVar(&o.Webhook.URL, "notification.webhook.url", o.Webhook.URL, ""+
+		"URL the notifications are posted to as json. Nothing is posted if it is empty.")
+
+	fs.StringVar(&o.SMS.URL, "notification.sms.url", o.SMS.URL, ""+
+		"URL of the gateway the text messages are posted to as json. No text message is sent if it is empty.")
+	fs.StringVar(&o.SMS.Token, "notification.sms.token", o.SMS.Token, ""+
+		"Bearer token used to authenticate to the SMS gateway.")
+}
+
+// NewNotifier creates the notifier delivering the notifications with the enabled providers.
+func (o *NotificationOptions) NewNotifier() (*Notifier, error) {
+	templates, err := LoadTemplates(o.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: o.Timeout}
+
+	var providers []Provider
+	if o.SMTP.Host != "" {
+		providers = append(providers,
+			NewSMTPProvider(o.SMTP.Host, o.SMTP.Port, o.SMTP.Username, o.SMTP.Password, o.SMTP.From, o.Timeout))
+	}
+	if o.Webhook.URL != "" {
+		providers = append(providers, NewWebhookProvider(o.Webhook.URL, client))
+	}
+	if o.SMS.URL != "" {
+		providers = append(providers, NewSMSProvider(o.SMS.URL, o.SMS.Token, client))
+	}
+
+	return New(templates, providers...), nil
+}
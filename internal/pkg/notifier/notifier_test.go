@@ -0,0 +1,114 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	templates := DefaultTemplates()
+	for _, event := range Events {
+		n, err := templates.Render(event, Recipient{Username: "colin"}, nil)
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", event, err)
+		}
+		if n.Subject == "" || !strings.Contains(n.Body, "colin") {
+			t.Errorf("Render(%s) = %+v", event, n)
+		}
+	}
+
+	n, _ := templates.Render(SecretExpiry, Recipient{Username: "colin"}, map[string]interface{}{
+		"secretID":   "ZuxvXNfG08BdEMqkTaP41L2DLArlE6Jpqoox",
+		"secretName": "secret0",
+		"expiresAt":  "2021-10-05T14:34:07+08:00",
+	})
+	if !strings.Contains(n.Subject, "secret0") || !strings.Contains(n.Body, "2021-10-05T14:34:07+08:00") {
+		t.Errorf("Render(%s) = %+v", SecretExpiry, n)
+	}
+
+	if _, err := templates.Render(Event("unknown"), Recipient{}, nil); err == nil {
+		t.Error("Render() of an unknown event should fail")
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	text := `{{define "subject"}}Locked{{end}}{{define "body"}}{{.Username}} is locked{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "lockout.tmpl"), []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	n, _ := templates.Render(Lockout, Recipient{Username: "colin"}, nil)
+	if n.Subject != "Locked" || n.Body != "colin is locked" {
+		t.Errorf("Render() = %+v", n)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "lockout.tmpl"), []byte(`{{define "subject"}}Locked{{end}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir); err == nil {
+		t.Error("LoadTemplates() of a template without body should fail")
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	var webhook Notification
+	var sms smsMessage
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &webhook)
+	})
+	mux.HandleFunc("/sms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &sms)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	n := New(DefaultTemplates(),
+		NewWebhookProvider(server.URL+"/webhook", server.Client()),
+		NewSMSProvider(server.URL+"/sms", "token", server.Client()),
+	)
+
+	to := Recipient{Username: "colin", Phone: "1812884xxxx"}
+	if err := n.Send(context.TODO(), Lockout, to, map[string]interface{}{"reason": "suspended"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if webhook.Event != Lockout || webhook.Recipient != to || webhook.Data["reason"] != "suspended" {
+		t.Errorf("webhook received %+v", webhook)
+	}
+	if sms.To != to.Phone || !strings.Contains(sms.Text, "suspended") {
+		t.Errorf("sms gateway received %+v", sms)
+	}
+
+	n = New(DefaultTemplates(), NewSMSProvider(server.URL+"/sms", "wrong", server.Client()))
+	if err := n.Send(context.TODO(), Lockout, to, nil); err == nil {
+		t.Error("Send() should fail when a provider fails")
+	}
+	if err := n.Send(context.TODO(), Lockout, Recipient{Username: "colin"}, nil); err != nil {
+		t.Errorf("Send() to a recipient without phone error = %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// SMSProvider sends the notifications as text messages through a http gateway, which relays
+// them to the SMS service of the deployment. The gateway receives the phone number and the
+// text as json, authenticated by a bearer token.
+type SMSProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+var _ Provider = (*SMSProvider)(nil)
+
+// smsMessage is the json posted to the gateway.
+type smsMessage struct {
+	To    string `json:"to"`
+	Text  string `json:"text"`
+	Event Event  `json:"event"`
+}
+
+// NewSMSProvider creates a provider sending the text messages through the gateway at url.
+func NewSMSProvider(url, token string, client *http.Client) *SMSProvider {
+	return &SMSProvider{url: url, token: token, client: client}
+}
+
+// Name returns the name of the provider.
+func (p *SMSProvider) Name() string {
+	return "sms"
+}
+
+// Send sends the body of the notification to the phone of the recipient.
+func (p *SMSProvider) Send(ctx context.Context, n *Notification) error {
+	if n.Recipient.Phone == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(smsMessage{To: n.Recipient.Phone, Text: n.Body, Event: n.Event})
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, p.client, p.url, p.token, body)
+}
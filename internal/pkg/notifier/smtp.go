@@ -0,0 +1,118 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPProvider mails the notifications through a SMTP server. The connection is upgraded with
+// STARTTLS when the server supports it, which is required to authenticate to a remote server.
+type SMTPProvider struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+var _ Provider = (*SMTPProvider)(nil)
+
+// NewSMTPProvider creates a provider mailing the notifications from the from address.
+func NewSMTPProvider(host string, port int, username, password, from string, timeout time.Duration) *SMTPProvider {
+	return &SMTPProvider{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+		timeout:  timeout,
+	}
+}
+
+// Name returns the name of the provider.
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+// Send mails the notification to the email of the recipient.
+func (p *SMTPProvider) Send(ctx context.Context, n *Notification) error {
+	if n.Recipient.Email == "" {
+		return nil
+	}
+
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(p.host, strconv.Itoa(p.port)))
+	if err != nil {
+		return err
+	}
+	// bound the whole conversation, the smtp client has no timeout of its own
+	_ = conn.SetDeadline(time.Now().Add(p.timeout))
+
+	c, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	if p.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", p.username, p.password, p.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(p.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(n.Recipient.Email); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(p.message(n)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message returns the mail of the notification.
+func (p *SMTPProvider) message(n *Notification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", p.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", n.Recipient.Email)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", n.CreatedAt.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.Write(bytes.ReplaceAll([]byte(n.Body), []byte("\n"), []byte("\r\n")))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
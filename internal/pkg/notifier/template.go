@@ -0,0 +1,140 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/marmotedu/errors"
+)
+
+// defaultTemplates are the templates of the events, each defines the subject and the body of
+// the notification. The templates are executed with the recipient fields and the event data
+// under .Data.
+var defaultTemplates = map[Event]string{
+	PasswordReset: `{{define "subject"}}Reset your IAM password{{end}}
+{{define "body"}}Hello {{.Username}},
+
+A password reset was requested for your account.{{with .Data.resetURL}} Reset your password at {{.}}{{end}}
+
+Ignore this message if you did not request it.{{end}}`,
+
+	MFAEnrollment: `{{define "subject"}}Multi-factor authentication enrolled{{end}}
+{{define "body"}}Hello {{.Username}},
+
+A multi-factor authentication device{{with .Data.device}} {{.}}{{end}} was enrolled for your account.
+
+Contact your administrator if you did not enroll it.{{end}}`,
+
+	SecretExpiry: `{{define "subject"}}Your IAM secret {{.Data.secretName}} expires soon{{end}}
+{{define "body"}}Hello {{.Username}},
+
+Your secret {{.Data.secretName}} ({{.Data.secretID}}) expires at {{.Data.expiresAt}}. Create a new secret and rotate your clients before then.{{end}}`,
+
+	Lockout: `{{define "subject"}}Your IAM account is locked{{end}}
+{{define "body"}}Hello {{.Username}},
+
+Your account has been locked{{with .Data.reason}}: {{.}}{{end}}. Contact your administrator to unlock it.{{end}}`,
+
+	PasswordExpiry: `{{define "subject"}}Your IAM password expires soon{{end}}
+{{define "body"}}Hello {{.Username}},
+
+Your password expires at {{.Data.expiresAt}}. Change it before then, otherwise you will have to change it at your next login.{{end}}`,
+}
+
+// Templates are the templates the notifications of the events are rendered from.
+type Templates struct {
+	events map[Event]*template.Template
+}
+
+// DefaultTemplates returns the built-in templates.
+func DefaultTemplates() *Templates {
+	t, err := parseTemplates(defaultTemplates)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// LoadTemplates returns the built-in templates, overridden by the <event>.tmpl files of the
+// given directory. A file must define both the subject and the body templates.
+func LoadTemplates(dir string) (*Templates, error) {
+	texts := make(map[Event]string, len(defaultTemplates))
+	for event, text := range defaultTemplates {
+		texts[event] = text
+	}
+
+	if dir != "" {
+		for _, event := range Events {
+			data, err := os.ReadFile(filepath.Join(dir, string(event)+".tmpl"))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "read %s template failed", event)
+			}
+
+			texts[event] = string(data)
+		}
+	}
+
+	return parseTemplates(texts)
+}
+
+func parseTemplates(texts map[Event]string) (*Templates, error) {
+	t := &Templates{events: make(map[Event]*template.Template, len(texts))}
+	for event, text := range texts {
+		tmpl, err := template.New(string(event)).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse %s template failed", event)
+		}
+
+		for _, name := range []string{"subject", "body"} {
+			if tmpl.Lookup(name) == nil {
+				return nil, errors.Errorf("%s template does not define the %s template", event, name)
+			}
+		}
+
+		t.events[event] = tmpl
+	}
+
+	return t, nil
+}
+
+// Render renders the notification of the event.
+func (t *Templates) Render(event Event, to Recipient, data map[string]interface{}) (*Notification, error) {
+	tmpl, ok := t.events[event]
+	if !ok {
+		return nil, errors.Errorf("unknown notification event %s", event)
+	}
+
+	values := struct {
+		Recipient
+		Data map[string]interface{}
+	}{to, data}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return nil, errors.Wrapf(err, "render %s subject failed", event)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", values); err != nil {
+		return nil, errors.Wrapf(err, "render %s body failed", event)
+	}
+
+	return &Notification{
+		Event:     event,
+		Recipient: to,
+		Subject:   strings.TrimSpace(subject.String()),
+		Body:      strings.TrimSpace(body.String()),
+		Data:      data,
+		CreatedAt: time.Now(),
+	}, nil
+}
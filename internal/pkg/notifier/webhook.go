@@ -0,0 +1,66 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// WebhookProvider posts the notifications as json to a url, e.g. a chat relay.
+type WebhookProvider struct {
+	url    string
+	client *http.Client
+}
+
+var _ Provider = (*WebhookProvider)(nil)
+
+// NewWebhookProvider creates a provider posting the notifications to the url.
+func NewWebhookProvider(url string, client *http.Client) *WebhookProvider {
+	return &WebhookProvider{url: url, client: client}
+}
+
+// Name returns the name of the provider.
+func (p *WebhookProvider) Name() string {
+	return "webhook"
+}
+
+// Send posts the notification, whatever the addresses of the recipient.
+func (p *WebhookProvider) Send(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, p.client, p.url, "", body)
+}
+
+// postJSON posts the json body to the url, with the token as bearer token if any.
+func postJSON(ctx context.Context, client *http.Client, url, token string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package operation executes the long-running operations in the background with a pool of
+// workers. The operations are persisted, so that the ones interrupted by a restart are
+// executed again.
+package operation // import "github.com/marmotedu/iam/internal/pkg/operation"
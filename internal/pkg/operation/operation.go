@@ -0,0 +1,245 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package operation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Handler executes an operation of a kind and returns its result, it reports the progress of
+// the operation in percent. The operations interrupted by a restart are executed again from
+// the beginning, so a handler has to be idempotent, and should return once ctx is done.
+type Handler func(ctx context.Context, st store.Factory, op *v1.Operation, progress func(percent int)) (metav1.Extend, error)
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+)
+
+// Register registers the handler of a kind of operations. It is usually called in the init
+// function of the package submitting the operations.
+func Register(kind string, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+
+	if _, ok := handlers[kind]; ok {
+		panic(fmt.Sprintf("operation handler %s registered twice", kind))
+	}
+
+	handlers[kind] = handler
+}
+
+func handlerOf(kind string) Handler {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+
+	return handlers[kind]
+}
+
+// Pool executes the submitted operations with a fixed number of workers.
+type Pool struct {
+	store   store.Factory
+	workers int
+	queue   chan string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPool creates a pool executing the operations stored in st.
+func NewPool(st store.Factory, opts *OperationOptions) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Pool{
+		store:   st,
+		workers: opts.Workers,
+		queue:   make(chan string),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start starts the workers, and queues the operations which were not done when the server
+// stopped.
+func (p *Pool) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	limit := int64(-1)
+	ops, err := p.store.Operations().List(p.ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("status!=%s,status!=%s", v1.OperationSucceeded, v1.OperationFailed),
+		Limit:         &limit,
+	})
+	if err != nil {
+		log.Warnf("list the unfinished operations failed: %s", err.Error())
+
+		return
+	}
+
+	for _, op := range ops.Items {
+		log.Infof("resume operation %s of kind %s", op.Name, op.Kind)
+		p.enqueue(op.Name)
+	}
+}
+
+// Stop stops the workers and waits for the running operations to return, they stay running
+// in the store and are resumed by the next start.
+func (p *Pool) Stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Submit stores a pending operation of the kind and queues it.
+func (p *Pool) Submit(ctx context.Context, kind, username string, params metav1.Extend) (*v1.Operation, error) {
+	if handlerOf(kind) == nil {
+		return nil, errors.WithCode(code.ErrUnknown, "operation kind %s is not registered", kind)
+	}
+
+	op := &v1.Operation{
+		ObjectMeta: metav1.ObjectMeta{Name: idutil.GetUUID36("op-")},
+		Kind:       kind,
+		Username:   username,
+		Params:     params,
+		Status:     v1.OperationPending,
+	}
+	if err := p.store.Operations().Create(ctx, op, metav1.CreateOptions{}); err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	p.enqueue(op.Name)
+
+	return op, nil
+}
+
+// enqueue queues the operation without blocking the caller until a worker is free.
+func (p *Pool) enqueue(name string) {
+	go func() {
+		select {
+		case p.queue <- name:
+		case <-p.ctx.Done():
+		}
+	}()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case name := <-p.queue:
+			p.run(name)
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Pool) run(name string) {
+	op, err := p.store.Operations().Get(p.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		log.Warnf("get operation %s failed: %s", name, err.Error())
+
+		return
+	}
+
+	if op.Done() {
+		return
+	}
+
+	op.Status = v1.OperationRunning
+	p.update(op)
+
+	result, err := p.execute(op)
+	if p.ctx.Err() != nil {
+		log.Infof("operation %s is interrupted, it is resumed by the next start", op.Name)
+
+		return
+	}
+
+	now := time.Now()
+	op.FinishedAt = &now
+	if err != nil {
+		op.Status = v1.OperationFailed
+		op.Error = err.Error()
+	} else {
+		op.Status = v1.OperationSucceeded
+		op.Progress = 100
+		op.Result = result
+	}
+	p.update(op)
+}
+
+func (p *Pool) execute(op *v1.Operation) (result metav1.Extend, err error) {
+	handler := handlerOf(op.Kind)
+	if handler == nil {
+		return nil, fmt.Errorf("operation kind %s is not registered", op.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("operation panicked: %v", r)
+		}
+	}()
+
+	return handler(p.ctx, p.store, op, func(percent int) {
+		if percent <= op.Progress || percent >= 100 {
+			return
+		}
+
+		op.Progress = percent
+		p.update(op)
+	})
+}
+
+func (p *Pool) update(op *v1.Operation) {
+	if err := p.store.Operations().Update(p.ctx, op, metav1.UpdateOptions{}); err != nil {
+		log.Warnf("update operation %s failed: %s", op.Name, err.Error())
+	}
+}
+
+var (
+	poolMu sync.RWMutex
+	pool   *Pool
+)
+
+// SetPool sets the pool used by Submit.
+func SetPool(p *Pool) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+
+	pool = p
+}
+
+// Submit submits an operation to the pool set by SetPool. The requesting user is taken from
+// the request context.
+func Submit(ctx context.Context, kind string, params metav1.Extend) (*v1.Operation, error) {
+	poolMu.RLock()
+	p := pool
+	poolMu.RUnlock()
+
+	if p == nil {
+		return nil, errors.WithCode(code.ErrUnknown, "operations are not enabled")
+	}
+
+	username, _ := ctx.Value(middleware.UsernameKey).(string)
+
+	return p.Submit(ctx, kind, username, params)
+}
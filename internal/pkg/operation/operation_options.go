@@ -0,0 +1,46 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package operation
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// OperationOptions contains configuration items related to the long-running operations.
+type OperationOptions struct {
+	Workers int `json:"workers" mapstructure:"workers"`
+}
+
+// NewOperationOptions creates a OperationOptions object with default parameters.
+func NewOperationOptions() *OperationOptions {
+	return &OperationOptions{
+		Workers: 4,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *OperationOptions) Validate() []error {
+	errs := []error{}
+
+	if o.Workers <= 0 {
+		errs = append(errs, fmt.Errorf("--operation.workers must be greater than 0, got %d", o.Workers))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the long-running operations for a specific api server to the
+// specified FlagSet.
+func (o *OperationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.IntVar(&o.Workers, "operation.workers", o.Workers, ""+
+		"Number of the long-running operations, e.g. the deletion of a tenant, executed concurrently.")
+}
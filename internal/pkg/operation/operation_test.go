@@ -0,0 +1,82 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package operation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/apiserver/store/fake"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func init() {
+	Register("TestCount", func(ctx context.Context, st store.Factory, op *v1.Operation, progress func(int)) (metav1.Extend, error) {
+		progress(50)
+
+		return metav1.Extend{"count": op.Params["count"]}, nil
+	})
+	Register("TestFail", func(ctx context.Context, st store.Factory, op *v1.Operation, progress func(int)) (metav1.Extend, error) {
+		return nil, fmt.Errorf("tenant not found")
+	})
+}
+
+func waitDone(t *testing.T, st store.Factory, name string) *v1.Operation {
+	t.Helper()
+
+	var op *v1.Operation
+	assert.Eventually(t, func() bool {
+		op, _ = st.Operations().Get(context.Background(), name, metav1.GetOptions{})
+
+		return op != nil && op.Done()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return op
+}
+
+func TestPool(t *testing.T) {
+	st, _ := fake.GetFakeFactoryOr()
+
+	// an operation interrupted by a restart
+	interrupted := &v1.Operation{
+		ObjectMeta: metav1.ObjectMeta{Name: "op-interrupted"},
+		Kind:       "TestCount",
+		Params:     metav1.Extend{"count": 2},
+		Status:     v1.OperationRunning,
+	}
+	assert.NoError(t, st.Operations().Create(context.Background(), interrupted, metav1.CreateOptions{}))
+
+	pool := NewPool(st, &OperationOptions{Workers: 2})
+	pool.Start()
+	defer pool.Stop()
+
+	op := waitDone(t, st, interrupted.Name)
+	assert.Equal(t, v1.OperationSucceeded, op.Status)
+	assert.Equal(t, 2, op.Result["count"])
+
+	op, err := pool.Submit(context.Background(), "TestCount", "colin", metav1.Extend{"count": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, v1.OperationPending, op.Status)
+
+	op = waitDone(t, st, op.Name)
+	assert.Equal(t, v1.OperationSucceeded, op.Status)
+	assert.Equal(t, 100, op.Progress)
+	assert.Equal(t, "colin", op.Username)
+	assert.NotNil(t, op.FinishedAt)
+
+	op, _ = pool.Submit(context.Background(), "TestFail", "colin", nil)
+	op = waitDone(t, st, op.Name)
+	assert.Equal(t, v1.OperationFailed, op.Status)
+	assert.Equal(t, "tenant not found", op.Error)
+
+	_, err = pool.Submit(context.Background(), "NotRegistered", "colin", nil)
+	assert.Error(t, err)
+}
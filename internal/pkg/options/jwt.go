@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
 	"github.com/spf13/pflag"
 
 	"github.com/marmotedu/iam/internal/pkg/server"
@@ -20,6 +21,9 @@ type JwtOptions struct {
 	Key        string        `json:"key"         mapstructure:"key"`
 	Timeout    time.Duration `json:"timeout"     mapstructure:"timeout"`
 	MaxRefresh time.Duration `json:"max-refresh" mapstructure:"max-refresh"`
+
+	// generated is true if the key is not configured but generated by Complete.
+	generated bool
 }
 
 // NewJwtOptions creates a JwtOptions object with default parameters.
@@ -58,6 +62,26 @@ func (s *JwtOptions) Validate() []error {
 	return errs
 }
 
+// Complete generates a random key if none is configured.
+func (s *JwtOptions) Complete() error {
+	if s.Key == "" {
+		s.Key = idutil.NewSecretKey()
+		s.generated = true
+	}
+
+	return nil
+}
+
+// Check verifies the key is configured, it is used to verify the options before the startup.
+func (s *JwtOptions) Check() error {
+	if s.generated {
+		return fmt.Errorf("--jwt.key is not set, the random key generated at startup differs between " +
+			"the instances and restarts, which reject the tokens they did not sign")
+	}
+
+	return nil
+}
+
 // AddFlags adds flags related to features for a specific api server to the
 // specified FlagSet.
 func (s *JwtOptions) AddFlags(fs *pflag.FlagSet) {
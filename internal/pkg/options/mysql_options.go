@@ -5,6 +5,8 @@
 package options
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -15,14 +17,25 @@ import (
 
 // MySQLOptions defines options for mysql database.
 type MySQLOptions struct {
-	Host                  string        `json:"host,omitempty"                     mapstructure:"host"`
-	Username              string        `json:"username,omitempty"                 mapstructure:"username"`
-	Password              string        `json:"-"                                  mapstructure:"password"`
-	Database              string        `json:"database"                           mapstructure:"database"`
-	MaxIdleConnections    int           `json:"max-idle-connections,omitempty"     mapstructure:"max-idle-connections"`
-	MaxOpenConnections    int           `json:"max-open-connections,omitempty"     mapstructure:"max-open-connections"`
-	MaxConnectionLifeTime time.Duration `json:"max-connection-life-time,omitempty" mapstructure:"max-connection-life-time"`
-	LogLevel              int           `json:"log-level"                          mapstructure:"log-level"`
+	Host                  string            `json:"host,omitempty"                     mapstructure:"host"`
+	Username              string            `json:"username,omitempty"                 mapstructure:"username"`
+	Password              string            `json:"-"                                  mapstructure:"password"`
+	Database              string            `json:"database"                           mapstructure:"database"`
+	MaxIdleConnections    int               `json:"max-idle-connections,omitempty"     mapstructure:"max-idle-connections"`
+	MaxOpenConnections    int               `json:"max-open-connections,omitempty"     mapstructure:"max-open-connections"`
+	MaxConnectionLifeTime time.Duration     `json:"max-connection-life-time,omitempty" mapstructure:"max-connection-life-time"`
+	PrepareStatement      bool              `json:"prepare-statement"                  mapstructure:"prepare-statement"`
+	LogLevel              int               `json:"log-level"                          mapstructure:"log-level"`
+	TLSMode               string            `json:"tls-mode"                           mapstructure:"tls-mode"`
+	TLSCAFile             string            `json:"tls-ca-file"                        mapstructure:"tls-ca-file"`
+	Charset               string            `json:"charset"                            mapstructure:"charset"`
+	Loc                   string            `json:"loc"                                mapstructure:"loc"`
+	ConnectTimeout        time.Duration     `json:"connect-timeout"                    mapstructure:"connect-timeout"`
+	ReadTimeout           time.Duration     `json:"read-timeout"                       mapstructure:"read-timeout"`
+	WriteTimeout          time.Duration     `json:"write-timeout"                      mapstructure:"write-timeout"`
+	Params                map[string]string `json:"params,omitempty"                   mapstructure:"params"`
+	Hosts                 []string          `json:"hosts,omitempty"                    mapstructure:"hosts"`
+	ProbeInterval         time.Duration     `json:"probe-interval,omitempty"           mapstructure:"probe-interval"`
 }
 
 // NewMySQLOptions create a `zero` value instance.
@@ -35,7 +48,18 @@ func NewMySQLOptions() *MySQLOptions {
 		MaxIdleConnections:    100,
 		MaxOpenConnections:    100,
 		MaxConnectionLifeTime: time.Duration(10) * time.Second,
+		PrepareStatement:      true,
 		LogLevel:              1, // Silent
+		TLSMode:               db.TLSDisable,
+		TLSCAFile:             "",
+		Charset:               "utf8",
+		Loc:                   "Local",
+		ConnectTimeout:        0,
+		ReadTimeout:           0,
+		WriteTimeout:          0,
+		Params:                map[string]string{},
+		Hosts:                 []string{},
+		ProbeInterval:         5 * time.Second,
 	}
 }
 
@@ -43,6 +67,51 @@ func NewMySQLOptions() *MySQLOptions {
 func (o *MySQLOptions) Validate() []error {
 	errs := []error{}
 
+	valid := false
+	for _, mode := range db.TLSModes {
+		if o.TLSMode == mode {
+			valid = true
+		}
+	}
+
+	if !valid {
+		errs = append(errs, fmt.Errorf("--mysql.tls-mode must be one of %s", strings.Join(db.TLSModes, ", ")))
+	}
+
+	if o.TLSCAFile != "" && o.TLSMode != db.TLSVerifyCA && o.TLSMode != db.TLSVerifyIdentity {
+		errs = append(errs, fmt.Errorf("--mysql.tls-ca-file requires --mysql.tls-mode %s or %s",
+			db.TLSVerifyCA, db.TLSVerifyIdentity))
+	}
+
+	if _, err := time.LoadLocation(o.Loc); err != nil {
+		errs = append(errs, fmt.Errorf("--mysql.loc is invalid: %w", err))
+	}
+
+	if o.ConnectTimeout < 0 || o.ReadTimeout < 0 || o.WriteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("--mysql.connect-timeout, --mysql.read-timeout and --mysql.write-timeout "+
+			"can not be negative"))
+	}
+
+	primary := false
+	for _, h := range o.Hosts {
+		host, err := db.ParseHost(h)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		primary = primary || host.Role == db.RolePrimary
+	}
+
+	if len(o.Hosts) > 0 && !primary {
+		errs = append(errs, fmt.Errorf("--mysql.hosts requires a %s host", db.RolePrimary))
+	}
+
+	if o.ProbeInterval <= 0 {
+		errs = append(errs, fmt.Errorf("--mysql.probe-interval must be positive"))
+	}
+
 	return errs
 }
 
@@ -69,8 +138,45 @@ func (o *MySQLOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&o.MaxConnectionLifeTime, "mysql.max-connection-life-time", o.MaxConnectionLifeTime, ""+
 		"Maximum connection life time allowed to connect to mysql.")
 
+	fs.BoolVar(&o.PrepareStatement, "mysql.prepare-statement", o.PrepareStatement, ""+
+		"Prepare the sql statements and cache them per connection, so that repeated queries are not parsed again.")
+
 	fs.IntVar(&o.LogLevel, "mysql.log-mode", o.LogLevel, ""+
 		"Specify gorm log level.")
+
+	fs.StringVar(&o.TLSMode, "mysql.tls-mode", o.TLSMode, ""+
+		"TLS mode of the connection to mysql, one of "+strings.Join(db.TLSModes, ", ")+". "+
+		"skip-verify does not verify the server certificate, verify-ca verifies it is signed by the "+
+		"certificate authority, and verify-identity also verifies the host name.")
+
+	fs.StringVar(&o.TLSCAFile, "mysql.tls-ca-file", o.TLSCAFile, ""+
+		"Certificate authority verifying the certificate of the mysql server, the system ones are used if empty.")
+
+	fs.StringVar(&o.Charset, "mysql.charset", o.Charset, ""+
+		"Charset of the connection to mysql.")
+
+	fs.StringVar(&o.Loc, "mysql.loc", o.Loc, ""+
+		"Location of the time values read from mysql, such as Local or UTC.")
+
+	fs.DurationVar(&o.ConnectTimeout, "mysql.connect-timeout", o.ConnectTimeout, ""+
+		"Timeout of connecting to mysql, 0 means the system timeout.")
+
+	fs.DurationVar(&o.ReadTimeout, "mysql.read-timeout", o.ReadTimeout, ""+
+		"Timeout of reading from a mysql connection, 0 means no timeout.")
+
+	fs.DurationVar(&o.WriteTimeout, "mysql.write-timeout", o.WriteTimeout, ""+
+		"Timeout of writing to a mysql connection, 0 means no timeout.")
+
+	fs.StringToStringVar(&o.Params, "mysql.params", o.Params, ""+
+		"Additional parameters of the mysql connection, such as the system variables (e.g. sql_mode='ANSI').")
+
+	fs.StringSliceVar(&o.Hosts, "mysql.hosts", o.Hosts, ""+
+		"Hosts of a primary/replica deployment in the format role=host:port, where role is primary or replica, "+
+		"--mysql.host is ignored if set. The writes are sent to the first writable host found by the health "+
+		"prober, so that they fail over to a promoted replica, and the reads are spread over the replicas.")
+
+	fs.DurationVar(&o.ProbeInterval, "mysql.probe-interval", o.ProbeInterval, ""+
+		"Interval the health of the --mysql.hosts is probed at.")
 }
 
 // NewClient create mysql store with the given config.
@@ -83,8 +189,36 @@ func (o *MySQLOptions) NewClient() (*gorm.DB, error) {
 		MaxIdleConnections:    o.MaxIdleConnections,
 		MaxOpenConnections:    o.MaxOpenConnections,
 		MaxConnectionLifeTime: o.MaxConnectionLifeTime,
+		PrepareStatement:      o.PrepareStatement,
 		LogLevel:              o.LogLevel,
+		TLSMode:               o.TLSMode,
+		TLSCAFile:             o.TLSCAFile,
+		Charset:               o.Charset,
+		Loc:                   o.Loc,
+		ConnectTimeout:        o.ConnectTimeout,
+		ReadTimeout:           o.ReadTimeout,
+		WriteTimeout:          o.WriteTimeout,
+		Params:                o.Params,
+		Hosts:                 o.Hosts,
+		ProbeInterval:         o.ProbeInterval,
 	}
 
 	return db.New(opts)
 }
+
+// Check connects to the mysql service, it is used to verify the options before the startup.
+func (o *MySQLOptions) Check() error {
+	client, err := o.NewClient()
+	if err != nil {
+		return err
+	}
+
+	defer db.Close(client)
+
+	sqlDB, err := client.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Ping()
+}
@@ -5,24 +5,32 @@
 package options
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/pflag"
+
+	"github.com/marmotedu/iam/pkg/app"
+	"github.com/marmotedu/iam/pkg/storage"
 )
 
 // RedisOptions defines options for redis cluster.
 type RedisOptions struct {
-	Host                  string   `json:"host"                     mapstructure:"host"                     description:"Redis service host address"`
-	Port                  int      `json:"port"`
-	Addrs                 []string `json:"addrs"                    mapstructure:"addrs"`
-	Username              string   `json:"username"                 mapstructure:"username"`
-	Password              string   `json:"password"                 mapstructure:"password"`
-	Database              int      `json:"database"                 mapstructure:"database"`
-	MasterName            string   `json:"master-name"              mapstructure:"master-name"`
-	MaxIdle               int      `json:"optimisation-max-idle"    mapstructure:"optimisation-max-idle"`
-	MaxActive             int      `json:"optimisation-max-active"  mapstructure:"optimisation-max-active"`
-	Timeout               int      `json:"timeout"                  mapstructure:"timeout"`
-	EnableCluster         bool     `json:"enable-cluster"           mapstructure:"enable-cluster"`
-	UseSSL                bool     `json:"use-ssl"                  mapstructure:"use-ssl"`
-	SSLInsecureSkipVerify bool     `json:"ssl-insecure-skip-verify" mapstructure:"ssl-insecure-skip-verify"`
+	Host                  string        `json:"host"                     mapstructure:"host"                     description:"Redis service host address"`
+	Port                  int           `json:"port"`
+	Addrs                 []string      `json:"addrs"                    mapstructure:"addrs"`
+	Username              string        `json:"username"                 mapstructure:"username"`
+	Password              string        `json:"password"                 mapstructure:"password"`
+	Database              int           `json:"database"                 mapstructure:"database"`
+	MasterName            string        `json:"master-name"              mapstructure:"master-name"`
+	MaxIdle               int           `json:"max-idle"                 mapstructure:"max-idle"`
+	MaxActive             int           `json:"max-active"               mapstructure:"max-active"`
+	Timeout               int           `json:"timeout"                  mapstructure:"timeout"`
+	EnableCluster         bool          `json:"enable-cluster"           mapstructure:"enable-cluster"`
+	UseSSL                bool          `json:"use-ssl"                  mapstructure:"use-ssl"`
+	SSLInsecureSkipVerify bool          `json:"ssl-insecure-skip-verify" mapstructure:"ssl-insecure-skip-verify"`
+	ClientCacheSize       int           `json:"client-cache-size"        mapstructure:"client-cache-size"`
+	ClientCacheTTL        time.Duration `json:"client-cache-ttl"         mapstructure:"client-cache-ttl"`
 }
 
 // NewRedisOptions create a `zero` value instance.
@@ -41,6 +49,8 @@ func NewRedisOptions() *RedisOptions {
 		EnableCluster:         false,
 		UseSSL:                false,
 		SSLInsecureSkipVerify: false,
+		ClientCacheSize:       0,
+		ClientCacheTTL:        time.Minute,
 	}
 }
 
@@ -48,6 +58,14 @@ func NewRedisOptions() *RedisOptions {
 func (o *RedisOptions) Validate() []error {
 	errs := []error{}
 
+	if o.ClientCacheSize < 0 {
+		errs = append(errs, fmt.Errorf("--redis.client-cache-size can not be negative"))
+	}
+
+	if o.ClientCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("--redis.client-cache-ttl can not be negative"))
+	}
+
 	return errs
 }
 
@@ -65,12 +83,12 @@ func (o *RedisOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.StringVar(&o.MasterName, "redis.master-name", o.MasterName, "The name of master redis instance.")
 
-	fs.IntVar(&o.MaxIdle, "redis.optimisation-max-idle", o.MaxIdle, ""+
+	fs.IntVar(&o.MaxIdle, "redis.max-idle", o.MaxIdle, ""+
 		"This setting will configure how many connections are maintained in the pool when idle (no traffic). "+
-		"Set the --redis.optimisation-max-active to something large, we usually leave it at around 2000 for "+
+		"Set the --redis.max-active to something large, we usually leave it at around 2000 for "+
 		"HA deployments.")
 
-	fs.IntVar(&o.MaxActive, "redis.optimisation-max-active", o.MaxActive, ""+
+	fs.IntVar(&o.MaxActive, "redis.max-active", o.MaxActive, ""+
 		"In order to not over commit connections to the Redis server, we may limit the total "+
 		"number of active connections to Redis. We recommend for production use to set this to around 4000.")
 
@@ -85,4 +103,39 @@ func (o *RedisOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&o.SSLInsecureSkipVerify, "redis.ssl-insecure-skip-verify", o.SSLInsecureSkipVerify, ""+
 		"Allows usage of self-signed certificates when connecting to an encrypted Redis database.")
+
+	fs.IntVar(&o.ClientCacheSize, "redis.client-cache-size", o.ClientCacheSize, ""+
+		"Maximum number of the hot keys, such as the revoked tokens and the sessions, cached in process "+
+		"and invalidated by the client tracking of Redis 6 or later. Set to 0 to disable the cache. "+
+		"It is not supported with redis cluster.")
+
+	fs.DurationVar(&o.ClientCacheTTL, "redis.client-cache-ttl", o.ClientCacheTTL, ""+
+		"Maximum time a key is cached in process, it bounds the staleness when an invalidation is lost. "+
+		"Set to 0 to cache the keys until they are invalidated.")
+
+	// the previous names are deprecated, and kept for one release
+	app.RenameFlag(fs, "redis.optimisation-max-idle", "redis.max-idle")
+	app.RenameFlag(fs, "redis.optimisation-max-active", "redis.max-active")
+}
+
+// Check connects to the redis service, it is used to verify the options before the startup.
+func (o *RedisOptions) Check() error {
+	client := storage.NewRedisClusterPool(false, &storage.Config{
+		Host:                  o.Host,
+		Port:                  o.Port,
+		Addrs:                 o.Addrs,
+		MasterName:            o.MasterName,
+		Username:              o.Username,
+		Password:              o.Password,
+		Database:              o.Database,
+		MaxIdle:               o.MaxIdle,
+		MaxActive:             o.MaxActive,
+		Timeout:               o.Timeout,
+		EnableCluster:         o.EnableCluster,
+		UseSSL:                o.UseSSL,
+		SSLInsecureSkipVerify: o.SSLInsecureSkipVerify,
+	})
+	defer client.Close()
+
+	return client.Ping().Err()
 }
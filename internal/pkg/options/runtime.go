@@ -0,0 +1,154 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package options
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/pflag"
+	"go.uber.org/automaxprocs/maxprocs"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// cgroupMemoryLimitFiles are the files holding the memory limit of the container, for the
+// cgroup v2 and v1 hierarchies.
+var cgroupMemoryLimitFiles = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// RuntimeOptions contains configuration items related to the go runtime, tuned to the
+// limits of the container the process runs in.
+type RuntimeOptions struct {
+	MemoryLimit      string  `json:"memory-limit"       mapstructure:"memory-limit"`
+	MemoryLimitRatio float64 `json:"memory-limit-ratio" mapstructure:"memory-limit-ratio"`
+}
+
+// NewRuntimeOptions creates a RuntimeOptions object with default parameters.
+func NewRuntimeOptions() *RuntimeOptions {
+	return &RuntimeOptions{
+		MemoryLimit:      "",
+		MemoryLimitRatio: 0.9,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *RuntimeOptions) Validate() []error {
+	var errs []error
+
+	if o.MemoryLimit != "" {
+		if _, err := humanize.ParseBytes(o.MemoryLimit); err != nil {
+			errs = append(errs, fmt.Errorf("--runtime.memory-limit %q is invalid: %w", o.MemoryLimit, err))
+		}
+	}
+
+	if o.MemoryLimitRatio < 0 || o.MemoryLimitRatio > 1 {
+		errs = append(errs, fmt.Errorf("--runtime.memory-limit-ratio %v must be between 0 and 1, inclusive",
+			o.MemoryLimitRatio))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the go runtime for a specific server to the
+// specified FlagSet.
+func (o *RuntimeOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.MemoryLimit, "runtime.memory-limit", o.MemoryLimit, ""+
+		"Soft memory limit of the go runtime, e.g. 512MiB. The GOMEMLIMIT environment variable "+
+		"takes precedence. If empty, the limit is derived from the memory limit of the cgroup.")
+
+	fs.Float64Var(&o.MemoryLimitRatio, "runtime.memory-limit-ratio", o.MemoryLimitRatio, ""+
+		"Ratio of the cgroup memory limit used as soft memory limit when --runtime.memory-limit "+
+		"is not set. Set to zero to disable.")
+}
+
+// Apply sets GOMAXPROCS to the cpu quota and the soft memory limit to the memory limit of
+// the container. It must be called once the logger is initialized.
+func (o *RuntimeOptions) Apply() {
+	if _, err := maxprocs.Set(maxprocs.Logger(log.Infof)); err != nil {
+		log.Warnf("failed to set GOMAXPROCS: %s", err.Error())
+	}
+
+	// the go runtime reads GOMEMLIMIT itself
+	if os.Getenv("GOMEMLIMIT") != "" {
+		log.Infof("memory limit: honoring GOMEMLIMIT=%s", os.Getenv("GOMEMLIMIT"))
+
+		return
+	}
+
+	limit, err := o.memoryLimit()
+	if err != nil {
+		log.Warnf("failed to compute the memory limit: %s", err.Error())
+
+		return
+	}
+	if limit <= 0 {
+		return
+	}
+
+	if setMemoryLimit(limit) {
+		log.Infof("memory limit: set to %s", humanize.IBytes(uint64(limit)))
+	}
+}
+
+// memoryLimit returns the soft memory limit to set, 0 means no limit.
+func (o *RuntimeOptions) memoryLimit() (int64, error) {
+	if o.MemoryLimit != "" {
+		limit, err := humanize.ParseBytes(o.MemoryLimit)
+		if err != nil {
+			return 0, err
+		}
+
+		return int64(limit), nil
+	}
+
+	if o.MemoryLimitRatio == 0 {
+		return 0, nil
+	}
+
+	limit, err := cgroupMemoryLimit()
+	if err != nil || limit == 0 {
+		return 0, err
+	}
+
+	return int64(float64(limit) * o.MemoryLimitRatio), nil
+}
+
+// cgroupMemoryLimit returns the memory limit of the cgroup of the process, 0 if there is none.
+func cgroupMemoryLimit() (uint64, error) {
+	for _, file := range cgroupMemoryLimitFiles {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, nil
+		}
+
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid memory limit %q in %s: %w", value, file, err)
+		}
+		// cgroup v1 reports a page-aligned max int64 when there is no limit
+		if limit >= 1<<62 {
+			return 0, nil
+		}
+
+		return limit, nil
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.19
+// +build go1.19
+
+package options
+
+import "runtime/debug"
+
+func setMemoryLimit(limit int64) bool {
+	debug.SetMemoryLimit(limit)
+
+	return true
+}
@@ -0,0 +1,17 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.19
+// +build !go1.19
+
+package options
+
+import "github.com/marmotedu/iam/pkg/log"
+
+// setMemoryLimit is a no-op, the soft memory limit requires go1.19.
+func setMemoryLimit(limit int64) bool {
+	log.Warnf("memory limit: not supported before go1.19, ignoring %d bytes", limit)
+
+	return false
+}
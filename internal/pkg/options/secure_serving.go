@@ -5,9 +5,12 @@
 package options
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"path"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -155,6 +158,32 @@ func (s *SecureServingOptions) Complete() error {
 	return nil
 }
 
+// Check loads the TLS certificate and key, and verifies the certificate is currently
+// valid. It is used to verify the options before the startup.
+func (s *SecureServingOptions) Check() error {
+	if s == nil || s.BindPort == 0 {
+		return nil
+	}
+
+	keyCert := s.ServerCert.CertKey
+	cert, err := tls.LoadX509KeyPair(keyCert.CertFile, keyCert.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	if now := time.Now(); now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate %s is only valid from %s to %s", keyCert.CertFile,
+			leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
 // CreateListener create net listener by given address and returns it and port.
 func CreateListener(addr string) (net.Listener, int, error) {
 	network := "tcp"
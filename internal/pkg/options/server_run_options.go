@@ -5,16 +5,24 @@
 package options
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/pflag"
 
+	"github.com/marmotedu/iam/internal/pkg/middleware"
 	"github.com/marmotedu/iam/internal/pkg/server"
 )
 
 // ServerRunOptions contains the options while running a generic api server.
 type ServerRunOptions struct {
-	Mode        string   `json:"mode"        mapstructure:"mode"`
-	Healthz     bool     `json:"healthz"     mapstructure:"healthz"`
-	Middlewares []string `json:"middlewares" mapstructure:"middlewares"`
+	Mode           string        `json:"mode"            mapstructure:"mode"`
+	Healthz        bool          `json:"healthz"         mapstructure:"healthz"`
+	Middlewares    []string      `json:"middlewares"     mapstructure:"middlewares"`
+	RequestTimeout time.Duration `json:"request-timeout" mapstructure:"request-timeout"`
+	CrashDir       string        `json:"crash-dir"       mapstructure:"crash-dir"`
+	CorsOrigins    []string      `json:"cors-origins"    mapstructure:"cors-origins"`
 }
 
 // NewServerRunOptions creates a new ServerRunOptions object with default parameters.
@@ -22,9 +30,12 @@ func NewServerRunOptions() *ServerRunOptions {
 	defaults := server.NewConfig()
 
 	return &ServerRunOptions{
-		Mode:        defaults.Mode,
-		Healthz:     defaults.Healthz,
-		Middlewares: defaults.Middlewares,
+		Mode:           defaults.Mode,
+		Healthz:        defaults.Healthz,
+		Middlewares:    defaults.Middlewares,
+		RequestTimeout: defaults.RequestTimeout,
+		CrashDir:       defaults.CrashDir,
+		CorsOrigins:    defaults.CorsOrigins,
 	}
 }
 
@@ -33,6 +44,9 @@ func (s *ServerRunOptions) ApplyTo(c *server.Config) error {
 	c.Mode = s.Mode
 	c.Healthz = s.Healthz
 	c.Middlewares = s.Middlewares
+	c.RequestTimeout = s.RequestTimeout
+	c.CrashDir = s.CrashDir
+	c.CorsOrigins = s.CorsOrigins
 
 	return nil
 }
@@ -41,6 +55,34 @@ func (s *ServerRunOptions) ApplyTo(c *server.Config) error {
 func (s *ServerRunOptions) Validate() []error {
 	errors := []error{}
 
+	if s.RequestTimeout < 0 {
+		errors = append(errors, fmt.Errorf("--server.request-timeout cannot be negative"))
+	}
+
+	errors = append(errors, validateCorsOrigins(s.CorsOrigins)...)
+
+	return errors
+}
+
+// ReloadCorsOrigins applies the valid cors origins to the running servers.
+func (s *ServerRunOptions) ReloadCorsOrigins() error {
+	if errs := validateCorsOrigins(s.CorsOrigins); len(errs) != 0 {
+		return errs[0]
+	}
+
+	middleware.SetCorsOrigins(s.CorsOrigins)
+
+	return nil
+}
+
+func validateCorsOrigins(origins []string) []error {
+	errors := []error{}
+	for _, origin := range origins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errors = append(errors, fmt.Errorf("--server.cors-origins %s must be * or start with http:// or https://", origin))
+		}
+	}
+
 	return errors
 }
 
@@ -56,4 +98,16 @@ func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.StringSliceVar(&s.Middlewares, "server.middlewares", s.Middlewares, ""+
 		"List of allowed middlewares for server, comma separated. If this list is empty default middlewares will be used.")
+
+	fs.DurationVar(&s.RequestTimeout, "server.request-timeout", s.RequestTimeout, ""+
+		"Timeout of a request, the database and redis calls made for it are cancelled once it expires "+
+		"or the client goes away. Zero means no timeout.")
+
+	fs.StringVar(&s.CrashDir, "server.crash-dir", s.CrashDir, ""+
+		"Directory the recovery middleware writes the crash records of the panicking requests to, "+
+		"they are only logged if it is empty.")
+
+	fs.StringSliceVar(&s.CorsOrigins, "server.cors-origins", s.CorsOrigins, ""+
+		"List of origins allowed by the cors middleware, comma separated, * allows any origin. "+
+		"It is applied at runtime when the config file changes.")
 }
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package pagination applies the page size limits of the list endpoints, makes the total
+// count of the listed records opt-in and carries the sort order of the list requests.
+package pagination // import "github.com/marmotedu/iam/internal/pkg/pagination"
@@ -0,0 +1,73 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pagination
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// CountParam is the query parameter requesting the total count of the listed records.
+const CountParam = "count"
+
+// skipTotalCountKey is a string key so that it can be set on the gin context, which is passed
+// to the stores as context.
+const skipTotalCountKey = "pagination.skipTotalCount"
+
+var (
+	mu      sync.RWMutex
+	options = NewPaginationOptions()
+)
+
+// SetOptions sets the page size limits applied by Complete.
+func SetOptions(opts *PaginationOptions) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	options = opts
+}
+
+// Complete applies the default page size to the list options of a list request without a
+// positive limit, and caps the limit to the maximum page size. The total count is only
+// computed when the request sets count=true.
+func Complete(c *gin.Context, opts *metav1.ListOptions) {
+	mu.RLock()
+	defaultLimit, maxLimit := options.DefaultLimit, options.MaxLimit
+	mu.RUnlock()
+
+	limit := defaultLimit
+	if opts.Limit != nil && *opts.Limit > 0 {
+		limit = *opts.Limit
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	opts.Limit = &limit
+
+	if count, _ := strconv.ParseBool(c.Query(CountParam)); !count {
+		c.Set(skipTotalCountKey, true)
+	}
+}
+
+// TotalCount reports whether the total count should be computed by the stores. It is only
+// skipped for the list requests which did not ask for it, so internal callers keep relying
+// on it.
+func TotalCount(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipTotalCountKey).(bool)
+
+	return !skip
+}
+
+// WithTotalCount returns a copy of ctx in which the total count is computed, for the lists
+// made while serving a list request which rely on the count.
+func WithTotalCount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipTotalCountKey, false)
+}
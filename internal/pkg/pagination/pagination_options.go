@@ -0,0 +1,56 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pagination
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// PaginationOptions contains configuration items related to the page size of the list endpoints.
+type PaginationOptions struct {
+	DefaultLimit int64 `json:"default-limit" mapstructure:"default-limit"`
+	MaxLimit     int64 `json:"max-limit"     mapstructure:"max-limit"`
+}
+
+// NewPaginationOptions creates a PaginationOptions object with default parameters.
+func NewPaginationOptions() *PaginationOptions {
+	return &PaginationOptions{
+		DefaultLimit: 100,
+		MaxLimit:     1000,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *PaginationOptions) Validate() []error {
+	errs := []error{}
+
+	if o.MaxLimit <= 0 {
+		errs = append(errs, fmt.Errorf("--pagination.max-limit must be greater than 0, got %d", o.MaxLimit))
+	}
+
+	if o.DefaultLimit <= 0 || o.DefaultLimit > o.MaxLimit {
+		errs = append(errs, fmt.Errorf("--pagination.default-limit must be between 1 and --pagination.max-limit %d, got %d",
+			o.MaxLimit, o.DefaultLimit))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the page size of the list endpoints for a specific api server
+// to the specified FlagSet.
+func (o *PaginationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.Int64Var(&o.DefaultLimit, "pagination.default-limit", o.DefaultLimit, ""+
+		"Number of records returned by the list endpoints when the limit is omitted.")
+
+	fs.Int64Var(&o.MaxLimit, "pagination.max-limit", o.MaxLimit, ""+
+		"Maximum number of records returned by the list endpoints, larger limits are capped to it.")
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pagination
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	SetOptions(&PaginationOptions{DefaultLimit: 20, MaxLimit: 100})
+	defer SetOptions(NewPaginationOptions())
+
+	tests := []struct {
+		name      string
+		query     string
+		limit     *int64
+		wantLimit int64
+		wantCount bool
+	}{
+		{name: "default limit", wantLimit: 20},
+		{name: "limit", limit: pointer.ToInt64(50), wantLimit: 50},
+		{name: "limit capped", limit: pointer.ToInt64(5000), wantLimit: 100},
+		{name: "negative limit", limit: pointer.ToInt64(-1), wantLimit: 20},
+		{name: "count", query: "?count=true", wantLimit: 20, wantCount: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest("GET", "/v1/users"+tt.query, nil)
+
+			opts := metav1.ListOptions{Limit: tt.limit}
+			Complete(c, &opts)
+
+			assert.Equal(t, tt.wantLimit, *opts.Limit)
+			assert.Equal(t, tt.wantCount, TotalCount(c))
+			assert.True(t, TotalCount(WithTotalCount(c)))
+		})
+	}
+
+	assert.True(t, TotalCount(context.Background()))
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pagination
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// The query parameters sorting the listed records.
+const (
+	SortByParam = "sortBy"
+	OrderParam  = "order"
+)
+
+// The values of the order parameter.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+const sortKey = "pagination.sort"
+
+// Sort is the order in which a list request returns the records.
+type Sort struct {
+	// By is the field the records are sorted by, the records are sorted by their creation
+	// order when it is empty.
+	By string
+
+	// Desc sorts the records in descending order.
+	Desc bool
+}
+
+// CompleteSort validates the sortBy and order parameters of a list request, the records can
+// only be sorted by the given fields. A sortBy without order sorts in ascending order, and
+// the records are listed newest first without parameters.
+func CompleteSort(c *gin.Context, fields ...string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	sort := Sort{By: c.Query(SortByParam), Desc: c.Query(SortByParam) == ""}
+
+	if sort.By != "" && !contains(fields, sort.By) {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath(SortByParam), sort.By, fields))
+	}
+
+	switch order := c.Query(OrderParam); order {
+	case "":
+	case OrderAsc:
+		sort.Desc = false
+	case OrderDesc:
+		sort.Desc = true
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath(OrderParam), order, []string{OrderAsc, OrderDesc}))
+	}
+
+	if len(allErrs) == 0 {
+		c.Set(sortKey, sort)
+	}
+
+	return allErrs
+}
+
+// SortFrom returns the sort order of the list request, newest first when the request did not
+// set one.
+func SortFrom(ctx context.Context) Sort {
+	if sort, ok := ctx.Value(sortKey).(Sort); ok {
+		return sort
+	}
+
+	return Sort{Desc: true}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pagination
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     Sort
+		wantErrs int
+	}{
+		{name: "newest first", want: Sort{Desc: true}},
+		{name: "sort by", query: "?sortBy=name", want: Sort{By: "name"}},
+		{name: "sort by desc", query: "?sortBy=createdAt&order=desc", want: Sort{By: "createdAt", Desc: true}},
+		{name: "oldest first", query: "?order=asc", want: Sort{}},
+		{name: "unknown field", query: "?sortBy=password&order=up", want: Sort{Desc: true}, wantErrs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest("GET", "/v1/users"+tt.query, nil)
+
+			assert.Len(t, CompleteSort(c, "name", "createdAt"), tt.wantErrs)
+			assert.Equal(t, tt.want, SortFrom(c))
+		})
+	}
+
+	assert.Equal(t, Sort{Desc: true}, SortFrom(context.Background()))
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package passwordexpiry expires the passwords of the users once they are older than the
+// maximum password age of their tenant. The users logging in with an expired password get a
+// token which can only change the password.
+package passwordexpiry // import "github.com/marmotedu/iam/internal/pkg/passwordexpiry"
@@ -0,0 +1,102 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package passwordexpiry
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+)
+
+// ExtendKey is the key under which the time the password was last changed is stored in the
+// extend fields of the user, in RFC3339 format.
+const ExtendKey = "passwordChangedAt"
+
+// ScopeClaim is the jwt claim restricting what a token can do, it is set to ChangePasswordScope
+// in the tokens issued for an expired password.
+const ScopeClaim = "scope"
+
+// ChangePasswordScope is the scope of the tokens which can only change the password of their user.
+const ChangePasswordScope = "change-password"
+
+// ChangedAt returns the time the password of the user was last changed, which is the creation
+// time of the users who never changed their password.
+func ChangedAt(user *v1.User) time.Time {
+	if value, ok := user.Extend[ExtendKey].(string); ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+
+	return user.CreatedAt
+}
+
+// SetChangedAt records the time the password of the user was changed.
+func SetChangedAt(user *v1.User, t time.Time) {
+	if user.Extend == nil {
+		user.Extend = metav1.Extend{}
+	}
+
+	user.Extend[ExtendKey] = t.UTC().Format(time.RFC3339)
+}
+
+// ExpiresAt returns when the password of the user expires for the given maximum age. The zero
+// time is returned when the password does not expire.
+func ExpiresAt(user *v1.User, maxAge time.Duration) time.Time {
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+
+	return ChangedAt(user).Add(maxAge)
+}
+
+// Expired reports whether the password of the user has expired for the given maximum age.
+func Expired(user *v1.User, maxAge time.Duration) bool {
+	expiresAt := ExpiresAt(user, maxAge)
+
+	return !expiresAt.IsZero() && !time.Now().Before(expiresAt)
+}
+
+// MaxAge returns the maximum password age of the tenant of the user, zero if the passwords of
+// the tenant do not expire.
+func MaxAge(ctx context.Context, quotas store.QuotaStore, user *v1.User) (time.Duration, error) {
+	name := tenant.FromExtend(user.Extend)
+	if name == "" {
+		return 0, nil
+	}
+
+	quota, err := quotas.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsCode(err, code.ErrQuotaNotFound) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return time.Duration(quota.MaxPasswordAge) * 24 * time.Hour, nil
+}
+
+// Check returns a ErrPasswordExpired error if the password of the user has expired.
+// It returns a `github.com/marmotedu/errors.withCode` error.
+func Check(ctx context.Context, quotas store.QuotaStore, user *v1.User) error {
+	maxAge, err := MaxAge(ctx, quotas, user)
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if Expired(user, maxAge) {
+		return errors.WithCode(code.ErrPasswordExpired, "password of user %s has expired, it must be changed", user.Name)
+	}
+
+	return nil
+}
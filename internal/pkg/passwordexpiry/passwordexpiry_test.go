@@ -0,0 +1,89 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package passwordexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/apiserver/store"
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func TestChangedAt(t *testing.T) {
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "colin", CreatedAt: createdAt}}
+
+	if got := ChangedAt(user); !got.Equal(createdAt) {
+		t.Errorf("ChangedAt() = %v, want the creation time %v", got, createdAt)
+	}
+
+	changedAt := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	SetChangedAt(user, changedAt)
+	if got := ChangedAt(user); !got.Equal(changedAt) {
+		t.Errorf("ChangedAt() = %v, want %v", got, changedAt)
+	}
+
+	if got := ExpiresAt(user, 0); !got.IsZero() {
+		t.Errorf("ExpiresAt() without maximum age = %v, want zero", got)
+	}
+	if got := ExpiresAt(user, 24*time.Hour); !got.Equal(changedAt.Add(24 * time.Hour)) {
+		t.Errorf("ExpiresAt() = %v, want %v", got, changedAt.Add(24*time.Hour))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quotas := store.NewMockQuotaStore(ctrl)
+	quotas.EXPECT().Get(gomock.Any(), gomock.Eq("marmotedu"), gomock.Any()).AnyTimes().
+		Return(&apiv1.Quota{ObjectMeta: metav1.ObjectMeta{Name: "marmotedu"}, MaxPasswordAge: 90}, nil)
+	quotas.EXPECT().Get(gomock.Any(), gomock.Eq("other"), gomock.Any()).AnyTimes().
+		Return(nil, errors.WithCode(code.ErrQuotaNotFound, "quota not found"))
+
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	recent := time.Now().Add(-10 * 24 * time.Hour)
+	tests := []struct {
+		name      string
+		tenant    string
+		changedAt time.Time
+		wantCode  int
+	}{
+		{"expired", "marmotedu", old, code.ErrPasswordExpired},
+		{"not expired", "marmotedu", recent, 0},
+		{"tenant without quota", "other", old, 0},
+		{"default tenant", "", old, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "colin", Extend: metav1.Extend{}}}
+			if tt.tenant != "" {
+				user.Extend[tenant.ExtendKey] = tt.tenant
+			}
+			SetChangedAt(user, tt.changedAt)
+
+			err := Check(context.TODO(), quotas, user)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+
+				return
+			}
+			if !errors.IsCode(err, tt.wantCode) {
+				t.Errorf("Check() error = %v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
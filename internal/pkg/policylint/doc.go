@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package policylint lints the authorization policies: their syntax, their conditions, their
+// templates, and the breadth of their wildcards. iam-apiserver rejects the policies with
+// errors, and serves the linting to iamctl, which can also run it locally.
+package policylint // import "github.com/marmotedu/iam/internal/pkg/policylint"
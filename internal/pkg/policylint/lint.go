@@ -0,0 +1,234 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package policylint
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+	"github.com/ory/ladon/compiler"
+
+	"github.com/marmotedu/iam/internal/pkg/condition"
+	"github.com/marmotedu/iam/internal/pkg/shadow"
+	"github.com/marmotedu/iam/internal/pkg/tags"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError findings make the policy rejected.
+	SeverityError Severity = "error"
+	// SeverityWarning findings are accepted, but likely mistakes.
+	SeverityWarning Severity = "warning"
+)
+
+// wildcardProbe is a value no subject, action or resource is expected to be named after, a
+// template matching it matches everything.
+const wildcardProbe = "\x00policylint\x00probe"
+
+// Finding is a problem of a policy.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Field    string   `json:"field,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Severity, f.detail())
+}
+
+func (f Finding) detail() string {
+	if f.Field == "" {
+		return f.Message
+	}
+
+	return fmt.Sprintf("%s: %s", f.Field, f.Message)
+}
+
+// Result is the result of the linting of a policy.
+type Result struct {
+	// Valid is false if there are error findings.
+	Valid    bool      `json:"valid"`
+	Findings []Finding `json:"findings"`
+}
+
+// Lint decodes the json encoded policy and lints it, the decoding errors, e.g. unknown
+// conditions, are error findings.
+func Lint(data []byte) Result {
+	var policy v1.Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return newResult([]Finding{{Severity: SeverityError, Message: err.Error()}})
+	}
+
+	return LintPolicy(&policy)
+}
+
+// LintPolicy lints the decoded policy.
+func LintPolicy(policy *v1.Policy) Result {
+	var findings []Finding
+
+	errs := append(append(policy.Validate(), shadow.ValidateExtend(policy.Extend)...), tenant.ValidateExtend(policy.Extend)...)
+	errs = append(errs, tags.ValidateExtend(policy.Extend)...)
+	for _, err := range errs {
+		findings = append(findings, Finding{Severity: SeverityError, Field: err.Field, Message: err.ErrorBody()})
+	}
+
+	p := &policy.Policy.DefaultPolicy
+	if p.Effect != ladon.AllowAccess && p.Effect != ladon.DenyAccess {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Field:    "policy.effect",
+			Message:  fmt.Sprintf("must be %q or %q, got %q", ladon.AllowAccess, ladon.DenyAccess, p.Effect),
+		})
+	}
+
+	subjects, subjectFindings := lintTemplates("policy.subjects", p.Subjects, "subject")
+	actions, actionFindings := lintTemplates("policy.actions", p.Actions, "action")
+	resources, resourceFindings := lintTemplates("policy.resources", p.Resources, "resource")
+	findings = append(findings, subjectFindings...)
+	findings = append(findings, actionFindings...)
+	findings = append(findings, resourceFindings...)
+
+	if p.Effect == ladon.AllowAccess && subjects && actions && resources {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Field:    "policy",
+			Message:  "grants every action on every resource to every subject",
+		})
+	}
+
+	names := make([]string, 0, len(p.Conditions))
+	for name := range p.Conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		findings = append(findings, lintCondition("policy.conditions."+name, p.Conditions[name])...)
+	}
+
+	return newResult(findings)
+}
+
+// lintTemplates lints the subject, action or resource templates, and reports whether one of
+// them matches everything.
+func lintTemplates(field string, templates []string, kind string) (bool, []Finding) {
+	var (
+		findings []Finding
+		matchAll bool
+	)
+
+	if len(templates) == 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Field:    field,
+			Message:  fmt.Sprintf("at least one %s is required, the policy matches no request", kind),
+		})
+	}
+
+	for i, template := range templates {
+		path := fmt.Sprintf("%s[%d]", field, i)
+
+		re, err := compiler.CompileRegex(template, '<', '>')
+		if err != nil {
+			findings = append(findings, Finding{Severity: SeverityError, Field: path, Message: err.Error()})
+
+			continue
+		}
+
+		if matched, _ := re.MatchString(wildcardProbe); matched {
+			matchAll = true
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Field:    path,
+				Message:  fmt.Sprintf("%q matches every %s", template, kind),
+			})
+		}
+	}
+
+	return matchAll, findings
+}
+
+// MatchesAll reports whether the template matches every subject, action or resource, e.g. <.*>.
+// An invalid template matches nothing.
+func MatchesAll(template string) bool {
+	re, err := compiler.CompileRegex(template, '<', '>')
+	if err != nil {
+		return false
+	}
+
+	matched, _ := re.MatchString(wildcardProbe)
+
+	return matched
+}
+
+// lintCondition lints the options of the conditions which can not be checked when they
+// are decoded.
+func lintCondition(field string, c ladon.Condition) []Finding {
+	var err error
+	switch c := c.(type) {
+	case *condition.CIDRCondition:
+		_, _, err = net.ParseCIDR(c.CIDR)
+	case *ladon.CIDRCondition:
+		_, _, err = net.ParseCIDR(c.CIDR)
+	case *condition.StringMatchCondition:
+		_, err = regexp.Compile(c.Matches)
+	case *ladon.StringMatchCondition:
+		_, err = regexp.Compile(c.Matches)
+	}
+
+	if err != nil {
+		return []Finding{{Severity: SeverityError, Field: field, Message: err.Error()}}
+	}
+
+	return nil
+}
+
+func newResult(findings []Finding) Result {
+	result := Result{Valid: true, Findings: findings}
+	if result.Findings == nil {
+		result.Findings = []Finding{}
+	}
+
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			result.Valid = false
+		}
+	}
+
+	return result
+}
+
+// Err returns the aggregate of the error findings, or nil if the policy is valid.
+func (r Result) Err() error {
+	var errs []error
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			errs = append(errs, errors.New(finding.detail()))
+		}
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+// FieldErrors returns the error findings as invalid fields, or nil if the policy is valid.
+func (r Result) FieldErrors() field.ErrorList {
+	var errs field.ErrorList
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			errs = append(errs, &field.Error{Type: field.ErrorTypeInvalid, Field: finding.Field, Detail: finding.Message})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package policylint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		valid    bool
+		findings []string
+	}{
+		{
+			name: "valid",
+			policy: `{"metadata":{"name":"policy0"},"policy":{"subjects":["users:maria"],"actions":["get"],` +
+				`"effect":"allow","resources":["resources:articles:<.*>"],` +
+				`"conditions":{"remoteIPAddress":{"type":"CIDRCondition","options":{"cidr":"192.168.0.1/16"}}}}}`,
+			valid:    true,
+			findings: []string{},
+		},
+		{
+			name:     "syntax",
+			policy:   `{"metadata":{"name":"policy0"},"policy":{`,
+			valid:    false,
+			findings: []string{"error: unexpected end of JSON input"},
+		},
+		{
+			name: "unknown condition",
+			policy: `{"metadata":{"name":"policy0"},"policy":{"subjects":["users:maria"],"actions":["get"],` +
+				`"effect":"allow","resources":["resources:articles"],` +
+				`"conditions":{"remoteIPAddress":{"type":"UnknownCondition","options":{}}}}}`,
+			valid: false,
+		},
+		{
+			name: "invalid options",
+			policy: `{"metadata":{"name":"policy0"},"policy":{"subjects":["users:<[>"],"actions":["get"],` +
+				`"effect":"permit","resources":["resources:articles"],` +
+				`"conditions":{"remoteIPAddress":{"type":"CIDRCondition","options":{"cidr":"192.168.0.1"}}}}}`,
+			valid: false,
+			findings: []string{
+				`error: policy.effect: must be "allow" or "deny", got "permit"`,
+				"error: policy.subjects[0]: error parsing regexp: unterminated [] set in `^[$`",
+				"error: policy.conditions.remoteIPAddress: invalid CIDR address: 192.168.0.1",
+			},
+		},
+		{
+			name: "wildcards",
+			policy: `{"metadata":{"name":"policy0"},"policy":{"subjects":["<.*>"],"actions":["<.*>"],` +
+				`"effect":"allow","resources":["<.*>", "resources:<.*>"]}}`,
+			valid: true,
+			findings: []string{
+				`warning: policy.subjects[0]: "<.*>" matches every subject`,
+				`warning: policy.actions[0]: "<.*>" matches every action`,
+				`warning: policy.resources[0]: "<.*>" matches every resource`,
+				"warning: policy: grants every action on every resource to every subject",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Lint([]byte(tt.policy))
+
+			assert.Equal(t, tt.valid, result.Valid)
+			assert.Equal(t, tt.valid, result.Err() == nil)
+			if tt.findings == nil {
+				return
+			}
+
+			findings := []string{}
+			for _, finding := range result.Findings {
+				findings = append(findings, finding.String())
+			}
+			assert.Equal(t, tt.findings, findings)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package ratelimit limits the requests of each client with token buckets stored in redis,
+// so that the limits are shared by all the instances of a server.
+package ratelimit // import "github.com/marmotedu/iam/internal/pkg/ratelimit"
@@ -0,0 +1,99 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the redis keys of the token buckets of the clients.
+const KeyPrefix = "iam-rate-limit-"
+
+// The headers of the rate limits, see
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/.
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Bucket takes a token from the token bucket of a client, refilled with qps tokens per second
+// and holding at most burst tokens.
+type Bucket interface {
+	TakeToken(keyName string, qps float64, burst int64) (*storage.TokenBucket, error)
+}
+
+// Limit returns a middleware limiting the requests of each client with the token buckets
+// stored in redis. It must be installed after the authentication, so that the clients are
+// identified by their secret id or username, the unauthenticated ones by their ip.
+func Limit(opts *RateLimitOptions) gin.HandlerFunc {
+	return LimitWith(opts, &storage.RedisCluster{})
+}
+
+// LimitWith is like Limit, with the token buckets taken from bucket.
+func LimitWith(opts *RateLimitOptions, bucket Bucket) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group := opts.group(c.FullPath())
+		if group.QPS <= 0 {
+			c.Next()
+
+			return
+		}
+
+		// the requests are allowed while redis is unavailable
+		tokens, err := bucket.TakeToken(KeyPrefix+group.Path+":"+client(c), group.QPS, group.Burst)
+		if err != nil {
+			log.L(c).Warnf("take rate limit token failed: %s", err.Error())
+			c.Next()
+
+			return
+		}
+
+		c.Header(HeaderLimit, strconv.FormatInt(group.Burst, 10))
+		c.Header(HeaderRemaining, strconv.FormatInt(tokens.Remaining, 10))
+		c.Header(HeaderReset, seconds(tokens.ResetAfter))
+
+		if !tokens.Allowed {
+			c.Header(HeaderRetryAfter, seconds(tokens.RetryAfter))
+			core.WriteResponse(c, errors.WithCode(code.ErrTooManyRequests,
+				"rate limit of %s exceeded, retry after %s", group.Path, tokens.RetryAfter), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// client returns the identity of the client of the request.
+func client(c *gin.Context) string {
+	if secretID := c.GetString(middleware.SecretIDKey); secretID != "" {
+		return "secret:" + secretID
+	}
+
+	if username := c.GetString(middleware.UsernameKey); username != "" {
+		return "user:" + username
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds d up to whole seconds, so that the clients do not retry too early.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
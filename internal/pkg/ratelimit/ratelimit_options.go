@@ -0,0 +1,125 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/marmotedu/errors"
+	"github.com/spf13/pflag"
+)
+
+// RateLimitOptions contains configuration items related to the rate limits of the clients.
+type RateLimitOptions struct {
+	// QPS and Burst are the limits of the route groups without their own, the requests are not
+	// limited when QPS is 0.
+	QPS   float64 `json:"qps"   mapstructure:"qps"`
+	Burst int64   `json:"burst" mapstructure:"burst"`
+	// Groups override the limits of the routes under their path.
+	Groups []*GroupOptions `json:"groups" mapstructure:"groups"`
+
+	// mu guards the limits replaced by Reload while the requests are limited
+	mu sync.RWMutex
+}
+
+// GroupOptions contains the rate limits of a route group, e.g. /v1/policies.
+type GroupOptions struct {
+	Path  string  `json:"path"  mapstructure:"path"`
+	QPS   float64 `json:"qps"   mapstructure:"qps"`
+	Burst int64   `json:"burst" mapstructure:"burst"`
+}
+
+// NewRateLimitOptions creates a RateLimitOptions object with default parameters.
+func NewRateLimitOptions() *RateLimitOptions {
+	return &RateLimitOptions{
+		QPS:    0,
+		Burst:  20,
+		Groups: []*GroupOptions{},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *RateLimitOptions) Validate() []error {
+	errs := []error{}
+
+	if o.QPS < 0 {
+		errs = append(errs, fmt.Errorf("--rate-limit.qps can not be negative"))
+	}
+
+	if o.QPS > 0 && o.Burst <= 0 {
+		errs = append(errs, fmt.Errorf("--rate-limit.burst must be greater than 0, got %d", o.Burst))
+	}
+
+	seen := map[string]bool{}
+	for i, g := range o.Groups {
+		if !strings.HasPrefix(g.Path, "/") {
+			errs = append(errs, fmt.Errorf("rate-limit.groups[%d].path %q must start with /", i, g.Path))
+		}
+
+		if seen[g.Path] {
+			errs = append(errs, fmt.Errorf("rate-limit.groups[%d].path %s is duplicated", i, g.Path))
+		}
+		seen[g.Path] = true
+
+		if g.QPS < 0 {
+			errs = append(errs, fmt.Errorf("rate-limit.groups[%d].qps can not be negative", i))
+		}
+
+		if g.QPS > 0 && g.Burst <= 0 {
+			errs = append(errs, fmt.Errorf("rate-limit.groups[%d].burst must be greater than 0, got %d", i, g.Burst))
+		}
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the rate limits for a specific api server to the specified
+// FlagSet. The limits of the route groups can only be configured by the config file.
+func (o *RateLimitOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.Float64Var(&o.QPS, "rate-limit.qps", o.QPS, ""+
+		"Requests per second allowed for each client, identified by its secret id, username or ip. "+
+		"Set to zero to disable the rate limits.")
+
+	fs.Int64Var(&o.Burst, "rate-limit.burst", o.Burst, ""+
+		"Requests allowed at once for each client above --rate-limit.qps.")
+}
+
+// Reload replaces the limits with the valid limits of from, they apply to the next requests
+// limited with these options.
+func (o *RateLimitOptions) Reload(from *RateLimitOptions) error {
+	if errs := from.Validate(); len(errs) != 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.QPS, o.Burst, o.Groups = from.QPS, from.Burst, from.Groups
+
+	return nil
+}
+
+// group returns the limits of the route, those of the group with the longest matching path.
+func (o *RateLimitOptions) group(route string) *GroupOptions {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	ret := &GroupOptions{Path: "/", QPS: o.QPS, Burst: o.Burst}
+	matched := -1
+	for _, g := range o.Groups {
+		if len(g.Path) > matched && (route == g.Path || strings.HasPrefix(route, strings.TrimSuffix(g.Path, "/")+"/")) {
+			ret, matched = g, len(g.Path)
+		}
+	}
+
+	return ret
+}
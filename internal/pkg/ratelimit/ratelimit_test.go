@@ -0,0 +1,138 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// fakeBucket allows tokens requests per key.
+type fakeBucket struct {
+	tokens int64
+	taken  map[string]int64
+	err    error
+}
+
+func (f *fakeBucket) TakeToken(keyName string, qps float64, burst int64) (*storage.TokenBucket, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.taken[keyName]++
+	if f.taken[keyName] > f.tokens {
+		return &storage.TokenBucket{RetryAfter: 1500 * time.Millisecond, ResetAfter: 2 * time.Second}, nil
+	}
+
+	return &storage.TokenBucket{Allowed: true, Remaining: f.tokens - f.taken[keyName], ResetAfter: time.Second}, nil
+}
+
+func newEngine(opts *RateLimitOptions, bucket Bucket) *gin.Engine {
+	g := gin.New()
+	identify := func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-User"))
+		c.Set(middleware.SecretIDKey, c.GetHeader("X-Secret"))
+	}
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	g.GET("/v1/policies/:name", identify, LimitWith(opts, bucket), handler)
+	g.GET("/v1/users/:name", identify, LimitWith(opts, bucket), handler)
+	g.GET("/healthz", identify, LimitWith(opts, bucket), handler)
+
+	return g
+}
+
+func do(g *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	g.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestLimitWith(t *testing.T) {
+	opts := &RateLimitOptions{
+		QPS:   10,
+		Burst: 2,
+		Groups: []*GroupOptions{
+			{Path: "/v1/policies", QPS: 1, Burst: 1},
+			{Path: "/healthz"},
+		},
+	}
+	bucket := &fakeBucket{tokens: 1, taken: map[string]int64{}}
+	g := newEngine(opts, bucket)
+
+	w := do(g, "/v1/policies/policy", map[string]string{"X-User": "colin"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(HeaderLimit))
+	assert.Equal(t, "0", w.Header().Get(HeaderRemaining))
+	assert.Equal(t, "1", w.Header().Get(HeaderReset))
+
+	w = do(g, "/v1/policies/policy", map[string]string{"X-User": "colin"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderRetryAfter))
+	assert.Contains(t, w.Body.String(), "100007")
+
+	// the buckets are kept per client and per route group
+	assert.Equal(t, http.StatusOK, do(g, "/v1/policies/policy", map[string]string{"X-User": "john"}).Code)
+	assert.Equal(t, http.StatusOK, do(g, "/v1/policies/policy", map[string]string{"X-User": "colin", "X-Secret": "id"}).Code)
+	assert.Equal(t, http.StatusOK, do(g, "/v1/users/colin", map[string]string{"X-User": "colin"}).Code)
+	assert.Equal(t, "2", do(g, "/v1/users/colin", map[string]string{"X-User": "john"}).Header().Get(HeaderLimit))
+	assert.Contains(t, bucket.taken, KeyPrefix+"/v1/policies:secret:id")
+	assert.Contains(t, bucket.taken, KeyPrefix+"/:user:colin")
+
+	// a group without qps is not limited
+	w = do(g, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderLimit))
+
+	bucket.err = errors.New("redis is down")
+	assert.Equal(t, http.StatusOK, do(g, "/v1/policies/policy", map[string]string{"X-User": "colin"}).Code)
+}
+
+func TestRateLimitOptions_Validate(t *testing.T) {
+	o := &RateLimitOptions{
+		QPS:   -1,
+		Burst: 0,
+		Groups: []*GroupOptions{
+			{Path: "/v1/policies", QPS: 1, Burst: 1},
+			{Path: "/v1/policies", QPS: 1},
+			{Path: "v1/users"},
+		},
+	}
+
+	assert.Len(t, o.Validate(), 4)
+	assert.Empty(t, NewRateLimitOptions().Validate())
+}
+
+func TestRateLimitOptions_Reload(t *testing.T) {
+	opts := NewRateLimitOptions()
+	bucket := &fakeBucket{tokens: 1, taken: map[string]int64{}}
+	g := newEngine(opts, bucket)
+
+	assert.Empty(t, do(g, "/healthz", nil).Header().Get(HeaderLimit))
+
+	// the middlewares installed with the options apply the reloaded limits
+	err := opts.Reload(&RateLimitOptions{QPS: 10, Burst: 5, Groups: []*GroupOptions{{Path: "/v1/policies", QPS: 1, Burst: 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", do(g, "/healthz", nil).Header().Get(HeaderLimit))
+	assert.Equal(t, "1", do(g, "/v1/policies/policy", nil).Header().Get(HeaderLimit))
+
+	// the invalid limits are not applied
+	assert.Error(t, opts.Reload(&RateLimitOptions{QPS: -1}))
+	assert.Equal(t, "5", do(g, "/healthz", nil).Header().Get(HeaderLimit))
+}
@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package resourceversion defines the resource version of the users, secrets and policies.
+// The version is increased by every update of a resource, an update which carries a version
+// is only applied to that version of the resource, so that concurrent updates can not
+// overwrite each other.
+package resourceversion // import "github.com/marmotedu/iam/internal/pkg/resourceversion"
@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package resourceversion
+
+import (
+	"strconv"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+// ExtendKey is the key under which the resource version is stored in the extend fields of the
+// users, secrets and policies.
+const ExtendKey = "resourceVersion"
+
+// FromExtend returns the resource version stored in the given extend fields, 0 if no version
+// is set or it is invalid.
+func FromExtend(ext metav1.Extend) uint64 {
+	value, _ := ext[ExtendKey].(string)
+	version, _ := strconv.ParseUint(value, 10, 64)
+
+	return version
+}
+
+// ValidateExtend validates the resource version stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	value, ok := ext[ExtendKey]
+	if !ok {
+		return nil
+	}
+
+	fldPath := field.NewPath("extend", ExtendKey)
+
+	// the version is a string, like in the responses, so that it is never rounded by the clients
+	version, ok := value.(string)
+	if !ok {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be a string")}
+	}
+
+	if n, err := strconv.ParseUint(version, 10, 64); err != nil || n == 0 {
+		return field.ErrorList{field.Invalid(fldPath, version, "must be a positive integer")}
+	}
+
+	return nil
+}
+
+// Init returns the extend fields of a created resource, with the first resource version.
+func Init(ext metav1.Extend) metav1.Extend {
+	return with(ext, 1)
+}
+
+// Next returns the extend fields of an updated resource, with the version following the one of
+// the current extend fields. The update is rejected with ErrConflict if the updated extend fields
+// carry a version other than the current one, an update without version is always applied.
+func Next(current, updated metav1.Extend) (metav1.Extend, error) {
+	version := FromExtend(current)
+	if _, ok := updated[ExtendKey]; ok && FromExtend(updated) != version {
+		return nil, errors.WithCode(code.ErrConflict,
+			"the resource version is %v, but the current version is %d", updated[ExtendKey], version)
+	}
+
+	return with(updated, version+1), nil
+}
+
+// with returns a copy of the extend fields with the resource version.
+func with(ext metav1.Extend, version uint64) metav1.Extend {
+	ret := make(metav1.Extend, len(ext)+1)
+	for k, v := range ext {
+		ret[k] = v
+	}
+	ret[ExtendKey] = strconv.FormatUint(version, 10)
+
+	return ret
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package resourceversion
+
+import (
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func TestValidateExtend(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     metav1.Extend
+		wantErr bool
+	}{
+		{name: "empty", ext: nil, wantErr: false},
+		{name: "valid", ext: metav1.Extend{"resourceVersion": "3"}, wantErr: false},
+		{name: "not_string", ext: metav1.Extend{"resourceVersion": 3}, wantErr: true},
+		{name: "zero", ext: metav1.Extend{"resourceVersion": "0"}, wantErr: true},
+		{name: "not_integer", ext: metav1.Extend{"resourceVersion": "v3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateExtend(tt.ext); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("ValidateExtend() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      metav1.Extend
+		updated      metav1.Extend
+		want         string
+		wantConflict bool
+	}{
+		{name: "unversioned", current: nil, updated: nil, want: "1"},
+		{name: "without_version", current: metav1.Extend{"resourceVersion": "3"}, updated: nil, want: "4"},
+		{
+			name:    "same_version",
+			current: metav1.Extend{"resourceVersion": "3"},
+			updated: metav1.Extend{"resourceVersion": "3", "tenant": "marmotedu"},
+			want:    "4",
+		},
+		{
+			name:         "stale_version",
+			current:      metav1.Extend{"resourceVersion": "4"},
+			updated:      metav1.Extend{"resourceVersion": "3"},
+			wantConflict: true,
+		},
+		{
+			name:         "invalid_version",
+			current:      metav1.Extend{"resourceVersion": "3"},
+			updated:      metav1.Extend{"resourceVersion": 3},
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Next(tt.current, tt.updated)
+			if tt.wantConflict {
+				if !errors.IsCode(err, code.ErrConflict) {
+					t.Fatalf("Next() error = %v, want ErrConflict", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			if got[ExtendKey] != tt.want {
+				t.Errorf("Next() version = %v, want %s", got[ExtendKey], tt.want)
+			}
+			for k, v := range tt.updated {
+				if k != ExtendKey && got[k] != v {
+					t.Errorf("Next() %s = %v, want the other fields kept", k, got[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package revocation stores the revoked jwt tokens in redis, where they are checked by both
+// iam-apiserver and iam-authz-server, so that a compromised token is rejected at once.
+package revocation // import "github.com/marmotedu/iam/internal/pkg/revocation"
@@ -0,0 +1,122 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package revocation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// The prefixes of the redis keys of the revocations.
+const (
+	tokenKeyPrefix = "iam-revoked-token-"
+	idKeyPrefix    = "iam-revoked-jti-"
+	userKeyPrefix  = "iam-revoked-user-"
+)
+
+// MaxTTL bounds how long a revocation is kept, the tokens living longer are rejected again by
+// deleting the secret which signed them or by changing the jwt key.
+const MaxTTL = 7 * 24 * time.Hour
+
+// revoked stores the revocations until the revoked tokens expire. They are checked by every
+// authenticated request, so that they are cached in process when the client side cache is
+// enabled.
+var revoked = &storage.RedisCluster{ClientSideCache: true}
+
+// tokenKey returns the redis key of a token, the token itself is not stored.
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return tokenKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// RevokeToken revokes the token for the given duration, which is the remaining lifetime of
+// the token.
+func RevokeToken(ctx context.Context, token string, ttl time.Duration) error {
+	return revoked.WithContext(middleware.RequestContext(ctx)).SetKey(tokenKey(token), "1", ttl)
+}
+
+// RevokeID revokes the tokens with the given `jti` claim for the given duration.
+func RevokeID(ctx context.Context, jti string, ttl time.Duration) error {
+	return revoked.WithContext(middleware.RequestContext(ctx)).SetKey(idKeyPrefix+jti, "1", ttl)
+}
+
+// RevokeUser revokes the tokens issued to the user so far for the given duration, the tokens
+// issued afterwards are accepted. The time of the revocation is kept to the microsecond.
+func RevokeUser(ctx context.Context, username string, ttl time.Duration) error {
+	now := strconv.FormatFloat(float64(time.Now().UnixMicro())/1e6, 'f', 6, 64)
+
+	return revoked.WithContext(middleware.RequestContext(ctx)).SetKey(userKeyPrefix+username, now, ttl)
+}
+
+// IsRevoked reports whether the token of the user has been revoked, by itself, by its `jti`
+// claim or along with the other tokens of the user. A token without an `iat` claim is revoked
+// along with the other tokens of the user until the revocation expires. The tokens are
+// accepted when the revocation store is unavailable.
+func IsRevoked(ctx context.Context, token, username string, claims map[string]interface{}) bool {
+	if token == "" {
+		return false
+	}
+
+	store := revoked.WithContext(middleware.RequestContext(ctx))
+	if _, err := store.GetKey(tokenKey(token)); err == nil {
+		return true
+	}
+
+	if jti, ok := claims["jti"].(string); ok && jti != "" {
+		if _, err := store.GetKey(idKeyPrefix + jti); err == nil {
+			return true
+		}
+	}
+
+	if username == "" {
+		return false
+	}
+
+	value, err := store.GetKey(userKeyPrefix + username)
+	if err != nil {
+		return false
+	}
+
+	// the revocations made before the sub-second precision are whole seconds
+	revokedAt, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	return issuedBefore(claims, revokedAt)
+}
+
+// issuedBefore reports whether the token was issued before or at the time of the revocation,
+// in seconds since the epoch. The `iat` claims are usually whole seconds, which can not tell
+// the tokens issued in the second of the revocation before it from those issued after it: they
+// are all revoked, the users get a valid token by logging in again a second later.
+func issuedBefore(claims map[string]interface{}, revokedAt float64) bool {
+	issuedAt, ok := IssuedAt(claims)
+
+	return !ok || issuedAt <= revokedAt
+}
+
+// IssuedAt returns the time the token was issued at, in seconds since the epoch, with the
+// fraction of the second when the claim has one. The tokens of iam-apiserver carry the time
+// of the login or of the last refresh in the `orig_iat` claim.
+func IssuedAt(claims map[string]interface{}) (float64, bool) {
+	for _, key := range []string{"orig_iat", "iat"} {
+		switch v := claims[key].(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		}
+	}
+
+	return 0, false
+}
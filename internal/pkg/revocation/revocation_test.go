@@ -0,0 +1,60 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package revocation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIssuedAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		want   float64
+		wantOk bool
+	}{
+		{"refreshed token", map[string]interface{}{"orig_iat": float64(200), "iat": float64(100)}, 200, true},
+		{"issued at", map[string]interface{}{"iat": float64(100)}, 100, true},
+		{"sub-second issued at", map[string]interface{}{"iat": 100.25}, 100.25, true},
+		{"no issued at", map[string]interface{}{"iat": "100"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := IssuedAt(tt.claims)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("IssuedAt() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_issuedBefore(t *testing.T) {
+	revokedAt := 100.5
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		want   bool
+	}{
+		{"issued before", map[string]interface{}{"iat": 100.25}, true},
+		{"issued after", map[string]interface{}{"iat": 100.75}, false},
+		{"issued in the second of the revocation", map[string]interface{}{"iat": float64(100)}, true},
+		{"issued the next second", map[string]interface{}{"iat": float64(101)}, false},
+		{"no issued at", map[string]interface{}{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issuedBefore(tt.claims, revokedAt); got != tt.want {
+				t.Errorf("issuedBefore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRevoked_StoreUnavailable(t *testing.T) {
+	if IsRevoked(context.TODO(), "token", "colin", map[string]interface{}{"jti": "id"}) {
+		t.Error("IsRevoked() = true, want the tokens accepted when redis is down")
+	}
+}
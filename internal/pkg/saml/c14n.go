@@ -0,0 +1,149 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"strings"
+)
+
+// canonicalize serializes the subtree of e with the exclusive xml canonicalization without
+// comments, see https://www.w3.org/TR/xml-exc-c14n/. The namespaces of the inclusive prefixes
+// are rendered like the inclusive canonicalization, "#default" is the default namespace.
+// The excluded element is left out, it is the signature of the enveloped-signature transform.
+func canonicalize(e *element, inclusive []string, excluded *element) []byte {
+	var b bytes.Buffer
+	writeCanonical(&b, e, map[string]string{}, inclusive, excluded)
+
+	return b.Bytes()
+}
+
+type namespaceDecl struct {
+	prefix string
+	uri    string
+}
+
+// writeCanonical writes e, rendered is the namespaces rendered by the output ancestors of e.
+func writeCanonical(b *bytes.Buffer, e *element, rendered map[string]string, inclusive []string, excluded *element) {
+	// the namespaces visibly utilized by e and its attributes
+	utilized := map[string]struct{}{e.prefix: {}}
+	for _, attr := range e.attrs {
+		if !isNamespaceDecl(attr) && attr.Name.Space != "" {
+			utilized[attr.Name.Space] = struct{}{}
+		}
+	}
+
+	for _, prefix := range inclusive {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		if e.lookupNS(prefix) != "" {
+			utilized[prefix] = struct{}{}
+		}
+	}
+
+	next := make(map[string]string, len(rendered))
+	for prefix, uri := range rendered {
+		next[prefix] = uri
+	}
+
+	var decls []namespaceDecl
+	for prefix := range utilized {
+		if prefix == "xml" {
+			continue
+		}
+
+		uri := e.lookupNS(prefix)
+		if prev, ok := rendered[prefix]; (ok && prev == uri) || (!ok && uri == "") {
+			continue
+		}
+
+		decls = append(decls, namespaceDecl{prefix: prefix, uri: uri})
+		next[prefix] = uri
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+
+	attrs := make([]xml.Attr, 0, len(e.attrs))
+	for _, attr := range e.attrs {
+		if !isNamespaceDecl(attr) {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		nsi, nsj := attrNamespace(e, attrs[i]), attrNamespace(e, attrs[j])
+		if nsi != nsj {
+			return nsi < nsj
+		}
+
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	name := qualifiedName(e.prefix, e.local)
+	b.WriteString("<" + name)
+	for _, decl := range decls {
+		if decl.prefix == "" {
+			b.WriteString(` xmlns="`)
+		} else {
+			b.WriteString(" xmlns:" + decl.prefix + `="`)
+		}
+		b.WriteString(escapeAttr(decl.uri) + `"`)
+	}
+	for _, attr := range attrs {
+		b.WriteString(" " + qualifiedName(attr.Name.Space, attr.Name.Local) + `="` + escapeAttr(attr.Value) + `"`)
+	}
+	b.WriteString(">")
+
+	for _, child := range e.children {
+		switch c := child.(type) {
+		case *element:
+			if c != excluded {
+				writeCanonical(b, c, next, inclusive, excluded)
+			}
+		case xml.CharData:
+			b.WriteString(escapeText(string(c)))
+		case xml.ProcInst:
+			b.WriteString("<?" + c.Target)
+			if inst := strings.TrimLeft(string(c.Inst), " \t\r\n"); inst != "" {
+				b.WriteString(" " + inst)
+			}
+			b.WriteString("?>")
+		}
+	}
+
+	b.WriteString("</" + name + ">")
+}
+
+func attrNamespace(e *element, attr xml.Attr) string {
+	if attr.Name.Space == "" {
+		return ""
+	}
+
+	return e.lookupNS(attr.Name.Space)
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+
+	return prefix + ":" + local
+}
+
+var (
+	textReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrReplacer = strings.NewReplacer(
+		"&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;",
+	)
+)
+
+func escapeText(s string) string {
+	return textReplacer.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return attrReplacer.Replace(s)
+}
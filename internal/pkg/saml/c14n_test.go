@@ -0,0 +1,70 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"testing"
+)
+
+func Test_canonicalize(t *testing.T) {
+	const doc = `<?xml version="1.0"?>
+<root xmlns="urn:default" xmlns:a="urn:a" xmlns:unused="urn:unused"><!-- comment -->` +
+		`<a:child b="2" a:attr="x" xmlns:c="urn:c" c:z="1" a="&quot;1&#10;">text &amp; &lt;more&gt;<empty/></a:child>` +
+		`<s xmlns=""/></root>`
+
+	root, err := parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	child := root.childElements("urn:a", "child")[0]
+
+	tests := []struct {
+		name      string
+		e         *element
+		inclusive []string
+		excluded  *element
+		want      string
+	}{
+		{
+			name: "subtree",
+			e:    child,
+			want: `<a:child xmlns:a="urn:a" xmlns:c="urn:c" a="&quot;1&#xA;" b="2" a:attr="x" c:z="1">` +
+				`text &amp; &lt;more&gt;<empty xmlns="urn:default"></empty></a:child>`,
+		},
+		{
+			name: "document",
+			e:    root,
+			want: `<root xmlns="urn:default">` +
+				`<a:child xmlns:a="urn:a" xmlns:c="urn:c" a="&quot;1&#xA;" b="2" a:attr="x" c:z="1">` +
+				`text &amp; &lt;more&gt;<empty></empty></a:child><s xmlns=""></s></root>`,
+		},
+		{
+			name:      "inclusive prefixes and excluded element",
+			e:         root,
+			inclusive: []string{"unused"},
+			excluded:  child,
+			want:      `<root xmlns="urn:default" xmlns:unused="urn:unused"><s xmlns=""></s></root>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(canonicalize(tt.e, tt.inclusive, tt.excluded)); got != tt.want {
+				t.Errorf("canonicalize() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parse(t *testing.T) {
+	for _, doc := range []string{
+		`<!DOCTYPE r [<!ENTITY x "y">]><r>&x;</r>`,
+		`<r><s></r></s>`,
+		`<r/><s/>`,
+	} {
+		if _, err := parse([]byte(doc)); err == nil {
+			t.Errorf("parse(%s) error = nil, want error", doc)
+		}
+	}
+}
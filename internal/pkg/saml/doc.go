@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package saml implements a SAML 2.0 service provider which supports the SP-initiated login
+// with the HTTP-Redirect binding for the authentication requests and the HTTP-POST binding
+// for the responses. The responses or the assertions must be signed by the configured
+// identity provider certificate, encrypted assertions are not supported.
+package saml // import "github.com/marmotedu/iam/internal/pkg/saml"
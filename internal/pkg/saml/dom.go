@@ -0,0 +1,157 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// nsXML is the namespace bound to the xml prefix.
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+// element is a node of the parsed document. The names keep their original prefixes, which
+// are needed to canonicalize the signed elements.
+type element struct {
+	prefix   string
+	local    string
+	attrs    []xml.Attr
+	children []interface{} // *element, xml.CharData or xml.ProcInst
+	parent   *element
+}
+
+// parse parses an xml document. Document type declarations are rejected, and the comments
+// are dropped because the signatures never cover them.
+func parse(data []byte) (*element, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+
+	var root, current *element
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if current == nil && root != nil {
+				return nil, errors.New("parse xml: multiple root elements")
+			}
+
+			e := &element{
+				prefix: t.Name.Space,
+				local:  t.Name.Local,
+				attrs:  append([]xml.Attr(nil), t.Attr...),
+				parent: current,
+			}
+			if current == nil {
+				root = e
+			} else {
+				current.children = append(current.children, e)
+			}
+			current = e
+		case xml.EndElement:
+			if current == nil || t.Name.Space != current.prefix || t.Name.Local != current.local {
+				return nil, fmt.Errorf("parse xml: unexpected end element %s", t.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, t.Copy())
+			}
+		case xml.ProcInst:
+			if current != nil {
+				current.children = append(current.children, t.Copy())
+			}
+		case xml.Directive:
+			return nil, errors.New("parse xml: document type declarations are not allowed")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, errors.New("parse xml: incomplete document")
+	}
+
+	return root, nil
+}
+
+// lookupNS returns the namespace bound to the prefix in the scope of e.
+func (e *element) lookupNS(prefix string) string {
+	if prefix == "xml" {
+		return nsXML
+	}
+
+	for p := e; p != nil; p = p.parent {
+		for _, attr := range p.attrs {
+			if (prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns") ||
+				(prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix) {
+				return attr.Value
+			}
+		}
+	}
+
+	return ""
+}
+
+// is reports whether e is the element local in the namespace ns.
+func (e *element) is(ns, local string) bool {
+	return e.local == local && e.lookupNS(e.prefix) == ns
+}
+
+// childElements returns the child elements local in the namespace ns.
+func (e *element) childElements(ns, local string) []*element {
+	var elements []*element
+	for _, child := range e.children {
+		if c, ok := child.(*element); ok && c.is(ns, local) {
+			elements = append(elements, c)
+		}
+	}
+
+	return elements
+}
+
+// child returns the first child element local in the namespace ns, or nil.
+func (e *element) child(ns, local string) *element {
+	if elements := e.childElements(ns, local); len(elements) > 0 {
+		return elements[0]
+	}
+
+	return nil
+}
+
+// attr returns the value of the attribute without namespace.
+func (e *element) attr(local string) string {
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// text returns the trimmed character data of e.
+func (e *element) text() string {
+	var b strings.Builder
+	for _, child := range e.children {
+		if data, ok := child.(xml.CharData); ok {
+			b.Write(data)
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// isNamespaceDecl reports whether the attribute declares a namespace.
+func isNamespaceDecl(attr xml.Attr) bool {
+	return attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns")
+}
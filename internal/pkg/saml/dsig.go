@@ -0,0 +1,164 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	// register the hash functions of the supported algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// XML signature namespaces and algorithms.
+const (
+	nsDSig    = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcC14N = "http://www.w3.org/2001/10/xml-exc-c14n#"
+
+	algExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algSHA256    = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algSHA512    = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// errNotSigned is returned when an element has no enveloped signature.
+var errNotSigned = errors.New("element is not signed")
+
+var (
+	signatureAlgorithms = map[string]crypto.Hash{algRSASHA256: crypto.SHA256, algRSASHA512: crypto.SHA512}
+	digestAlgorithms    = map[string]crypto.Hash{algSHA256: crypto.SHA256, algSHA512: crypto.SHA512}
+)
+
+// verifySignature verifies the enveloped signature of e with the certificate. The signature
+// must reference e itself, so that the caller only trusts the content of the verified element.
+func verifySignature(e *element, cert *x509.Certificate) error {
+	signature := e.child(nsDSig, "Signature")
+	if signature == nil {
+		return errNotSigned
+	}
+
+	signedInfo := signature.child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("missing SignedInfo")
+	}
+
+	c14nMethod := signedInfo.child(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != algExcC14N {
+		return errors.New("unsupported canonicalization method")
+	}
+
+	signatureMethod := signedInfo.child(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return errors.New("missing SignatureMethod")
+	}
+	hash, ok := signatureAlgorithms[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %s", signatureMethod.attr("Algorithm"))
+	}
+
+	if err := verifyReference(e, signature, signedInfo); err != nil {
+		return err
+	}
+
+	signatureValue, err := decodeBase64(signature.child(nsDSig, "SignatureValue"))
+	if err != nil {
+		return fmt.Errorf("decode SignatureValue: %w", err)
+	}
+
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("certificate does not have a rsa public key")
+	}
+
+	h := hash.New()
+	h.Write(canonicalize(signedInfo, inclusivePrefixes(c14nMethod), nil))
+
+	if err := rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), signatureValue); err != nil {
+		return errors.New("signature is invalid")
+	}
+
+	return nil
+}
+
+// verifyReference verifies the digest of the only reference of the signed info, which must
+// reference e with the enveloped-signature and the exclusive canonicalization transforms.
+func verifyReference(e, signature, signedInfo *element) error {
+	references := signedInfo.childElements(nsDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("signature must have exactly one reference")
+	}
+	reference := references[0]
+
+	if id := e.attr("ID"); id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("signature does not reference the signed element")
+	}
+
+	var prefixes []string
+	var canonical bool
+	if transforms := reference.child(nsDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.childElements(nsDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case algEnveloped:
+			case algExcC14N:
+				canonical = true
+				prefixes = inclusivePrefixes(transform)
+			default:
+				return fmt.Errorf("unsupported transform %s", transform.attr("Algorithm"))
+			}
+		}
+	}
+	if !canonical {
+		return errors.New("reference must use the exclusive canonicalization")
+	}
+
+	digestMethod := reference.child(nsDSig, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("missing DigestMethod")
+	}
+	hash, ok := digestAlgorithms[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %s", digestMethod.attr("Algorithm"))
+	}
+
+	digestValue, err := decodeBase64(reference.child(nsDSig, "DigestValue"))
+	if err != nil {
+		return fmt.Errorf("decode DigestValue: %w", err)
+	}
+
+	h := hash.New()
+	h.Write(canonicalize(e, prefixes, signature))
+
+	if subtle.ConstantTimeCompare(h.Sum(nil), digestValue) != 1 {
+		return errors.New("digest of the signed element does not match")
+	}
+
+	return nil
+}
+
+// inclusivePrefixes returns the PrefixList of the InclusiveNamespaces of a canonicalization method.
+func inclusivePrefixes(method *element) []string {
+	if namespaces := method.child(nsExcC14N, "InclusiveNamespaces"); namespaces != nil {
+		return strings.Fields(namespaces.attr("PrefixList"))
+	}
+
+	return nil
+}
+
+func decodeBase64(e *element) ([]byte, error) {
+	if e == nil {
+		return nil, errors.New("missing value")
+	}
+
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(e.text()), ""))
+}
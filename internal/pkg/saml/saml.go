@@ -0,0 +1,388 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// SAML 2.0 namespaces and identifiers.
+const (
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+
+	bindingHTTPPost    = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	nameIDUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	confirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// Assertion is the identity asserted by a validated response.
+type Assertion struct {
+	NameID       string
+	SessionIndex string
+	// Attributes are keyed by both the Name and the FriendlyName of the attributes.
+	Attributes map[string][]string
+}
+
+// Attribute returns the first value of the attribute, or an empty string.
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// ServiceProvider is a SAML 2.0 service provider trusting a single identity provider.
+type ServiceProvider struct {
+	EntityID       string
+	ACSURL         string
+	IDPEntityID    string
+	IDPSSOURL      string
+	IDPCertificate *x509.Certificate
+	ClockSkew      time.Duration
+
+	// now returns the current time, it is replaced by the tests.
+	now func() time.Time
+}
+
+// NewServiceProvider creates a service provider from the options, the certificate of the
+// identity provider is loaded from the certificate file.
+func NewServiceProvider(opts *SAMLOptions) (*ServiceProvider, error) {
+	data, err := os.ReadFile(opts.IDPCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("read identity provider certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("identity provider certificate is not a PEM encoded certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse identity provider certificate: %w", err)
+	}
+
+	return &ServiceProvider{
+		EntityID:       opts.EntityID,
+		ACSURL:         opts.ACSURL,
+		IDPEntityID:    opts.IDPEntityID,
+		IDPSSOURL:      opts.IDPSSOURL,
+		IDPCertificate: cert,
+		ClockSkew:      opts.ClockSkew,
+		now:            time.Now,
+	}, nil
+}
+
+type entityDescriptor struct {
+	XMLName  xml.Name `xml:"md:EntityDescriptor"`
+	XMLNS    string   `xml:"xmlns:md,attr"`
+	EntityID string   `xml:"entityID,attr"`
+	SP       struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"md:NameIDFormat"`
+		ACS                        struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+			Index    int    `xml:"index,attr"`
+		} `xml:"md:AssertionConsumerService"`
+	} `xml:"md:SPSSODescriptor"`
+}
+
+// Metadata returns the metadata of the service provider, which is imported by the identity provider.
+func (sp *ServiceProvider) Metadata() []byte {
+	var md entityDescriptor
+	md.XMLNS = nsMetadata
+	md.EntityID = sp.EntityID
+	md.SP.WantAssertionsSigned = true
+	md.SP.ProtocolSupportEnumeration = nsProtocol
+	md.SP.NameIDFormat = nameIDUnspecified
+	md.SP.ACS.Binding = bindingHTTPPost
+	md.SP.ACS.Location = sp.ACSURL
+	md.SP.ACS.Index = 1
+
+	data, _ := xml.MarshalIndent(md, "", "  ")
+
+	return append([]byte(xml.Header), data...)
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"samlp:AuthnRequest"`
+	XMLNSProtocol               string   `xml:"xmlns:samlp,attr"`
+	XMLNSAssertion              string   `xml:"xmlns:saml,attr"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	Issuer                      string   `xml:"saml:Issuer"`
+	NameIDPolicy                struct {
+		Format      string `xml:"Format,attr"`
+		AllowCreate bool   `xml:"AllowCreate,attr"`
+	} `xml:"samlp:NameIDPolicy"`
+}
+
+// AuthnRequestURL returns the url redirecting the user agent to the identity provider with
+// an authentication request, and the ID of the request which must be passed to ParseResponse.
+func (sp *ServiceProvider) AuthnRequestURL(relayState string) (string, string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", "", err
+	}
+
+	req := authnRequest{
+		XMLNSProtocol:               nsProtocol,
+		XMLNSAssertion:              nsAssertion,
+		ID:                          id,
+		Version:                     "2.0",
+		IssueInstant:                sp.now().UTC().Format(time.RFC3339),
+		Destination:                 sp.IDPSSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		ProtocolBinding:             bindingHTTPPost,
+		Issuer:                      sp.EntityID,
+	}
+	req.NameIDPolicy.Format = nameIDUnspecified
+	req.NameIDPolicy.AllowCreate = true
+
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return "", "", err
+	}
+
+	// HTTP-Redirect binding: DEFLATE, base64 and url encoding
+	var b bytes.Buffer
+	w, _ := flate.NewWriter(&b, flate.DefaultCompression)
+	_, _ = w.Write(data)
+	_ = w.Close()
+
+	u, err := url.Parse(sp.IDPSSOURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(b.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), id, nil
+}
+
+// ParseResponse validates a base64 encoded response of the HTTP-POST binding and returns
+// its assertion. consumeRequest is called with the ID of the authentication request the
+// response is in response to, and returns false when the request is unknown or has already
+// been consumed, which rejects the unsolicited and the replayed responses.
+func (sp *ServiceProvider) ParseResponse(encoded string, consumeRequest func(id string) bool) (*Assertion, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	response, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.is(nsProtocol, "Response") {
+		return nil, errors.New("not a SAML response")
+	}
+
+	responseErr := verifySignature(response, sp.IDPCertificate)
+	if responseErr != nil && !errors.Is(responseErr, errNotSigned) {
+		return nil, fmt.Errorf("verify response signature: %w", responseErr)
+	}
+
+	if destination := response.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("response destination %s does not match", destination)
+	}
+
+	if err := sp.verifyIssuer(response); err != nil {
+		return nil, err
+	}
+
+	if status := response.child(nsProtocol, "Status"); status == nil ||
+		status.child(nsProtocol, "StatusCode") == nil ||
+		status.child(nsProtocol, "StatusCode").attr("Value") != statusSuccess {
+		return nil, errors.New("authentication failed on the identity provider")
+	}
+
+	inResponseTo := response.attr("InResponseTo")
+	if inResponseTo == "" || !consumeRequest(inResponseTo) {
+		return nil, errors.New("response is not in response to a pending authentication request")
+	}
+
+	if len(response.childElements(nsAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+
+	assertions := response.childElements(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("response must have exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	// the assertion is trusted if either the response or the assertion itself is signed
+	if err := verifySignature(assertion, sp.IDPCertificate); err != nil &&
+		(!errors.Is(err, errNotSigned) || responseErr != nil) {
+		return nil, fmt.Errorf("verify assertion signature: %w", err)
+	}
+
+	return sp.parseAssertion(assertion, inResponseTo)
+}
+
+func (sp *ServiceProvider) parseAssertion(assertion *element, inResponseTo string) (*Assertion, error) {
+	if err := sp.verifyIssuer(assertion); err != nil {
+		return nil, err
+	}
+
+	now := sp.now()
+
+	subject := assertion.child(nsAssertion, "Subject")
+	if subject == nil || subject.child(nsAssertion, "NameID") == nil {
+		return nil, errors.New("assertion has no subject")
+	}
+
+	if err := sp.verifySubjectConfirmation(subject, inResponseTo, now); err != nil {
+		return nil, err
+	}
+
+	if err := sp.verifyConditions(assertion.child(nsAssertion, "Conditions"), now); err != nil {
+		return nil, err
+	}
+
+	a := &Assertion{
+		NameID:     subject.child(nsAssertion, "NameID").text(),
+		Attributes: map[string][]string{},
+	}
+
+	if statement := assertion.child(nsAssertion, "AuthnStatement"); statement != nil {
+		a.SessionIndex = statement.attr("SessionIndex")
+	}
+
+	for _, statement := range assertion.childElements(nsAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childElements(nsAssertion, "Attribute") {
+			var values []string
+			for _, value := range attribute.childElements(nsAssertion, "AttributeValue") {
+				values = append(values, value.text())
+			}
+
+			for _, name := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if name != "" {
+					a.Attributes[name] = append(a.Attributes[name], values...)
+				}
+			}
+		}
+	}
+
+	return a, nil
+}
+
+func (sp *ServiceProvider) verifyIssuer(e *element) error {
+	if sp.IDPEntityID == "" {
+		return nil
+	}
+
+	if issuer := e.child(nsAssertion, "Issuer"); issuer != nil && issuer.text() != sp.IDPEntityID {
+		return fmt.Errorf("issuer %s does not match", issuer.text())
+	}
+
+	return nil
+}
+
+// verifySubjectConfirmation requires a bearer confirmation for the assertion consumer service
+// and the authentication request, which is not expired.
+func (sp *ServiceProvider) verifySubjectConfirmation(subject *element, inResponseTo string, now time.Time) error {
+	for _, confirmation := range subject.childElements(nsAssertion, "SubjectConfirmation") {
+		data := confirmation.child(nsAssertion, "SubjectConfirmationData")
+		if confirmation.attr("Method") != confirmationBearer || data == nil {
+			continue
+		}
+
+		if data.attr("Recipient") != sp.ACSURL {
+			continue
+		}
+
+		// binds the signed assertion to the authentication request when the response is not signed
+		if data.attr("InResponseTo") != inResponseTo {
+			continue
+		}
+
+		notOnOrAfter, err := time.Parse(time.RFC3339, data.attr("NotOnOrAfter"))
+		if err != nil || !now.Add(-sp.ClockSkew).Before(notOnOrAfter) {
+			continue
+		}
+
+		return nil
+	}
+
+	return errors.New("assertion has no valid bearer subject confirmation")
+}
+
+// verifyConditions checks the validity period and the audience restrictions of the assertion.
+func (sp *ServiceProvider) verifyConditions(conditions *element, now time.Time) error {
+	if conditions == nil {
+		return nil
+	}
+
+	if v := conditions.attr("NotBefore"); v != "" {
+		notBefore, err := time.Parse(time.RFC3339, v)
+		if err != nil || now.Add(sp.ClockSkew).Before(notBefore) {
+			return errors.New("assertion is not yet valid")
+		}
+	}
+
+	if v := conditions.attr("NotOnOrAfter"); v != "" {
+		notOnOrAfter, err := time.Parse(time.RFC3339, v)
+		if err != nil || !now.Add(-sp.ClockSkew).Before(notOnOrAfter) {
+			return errors.New("assertion has expired")
+		}
+	}
+
+	for _, restriction := range conditions.childElements(nsAssertion, "AudienceRestriction") {
+		var ok bool
+		for _, audience := range restriction.childElements(nsAssertion, "Audience") {
+			if audience.text() == sp.EntityID {
+				ok = true
+			}
+		}
+
+		if !ok {
+			return errors.New("assertion is not intended for this service provider")
+		}
+	}
+
+	return nil
+}
+
+// newID returns a random ID, which must not start with a digit.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "id-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// SAMLOptions contains configuration items related to the SAML 2.0 login.
+type SAMLOptions struct {
+	EntityID          string        `json:"entity-id"          mapstructure:"entity-id"`
+	ACSURL            string        `json:"acs-url"            mapstructure:"acs-url"`
+	IDPEntityID       string        `json:"idp-entity-id"      mapstructure:"idp-entity-id"`
+	IDPSSOURL         string        `json:"idp-sso-url"        mapstructure:"idp-sso-url"`
+	IDPCertFile       string        `json:"idp-cert-file"      mapstructure:"idp-cert-file"`
+	UsernameAttribute string        `json:"username-attribute" mapstructure:"username-attribute"`
+	EmailAttribute    string        `json:"email-attribute"    mapstructure:"email-attribute"`
+	NicknameAttribute string        `json:"nickname-attribute" mapstructure:"nickname-attribute"`
+	JITProvisioning   bool          `json:"jit-provisioning"   mapstructure:"jit-provisioning"`
+	ClockSkew         time.Duration `json:"clock-skew"         mapstructure:"clock-skew"`
+}
+
+// NewSAMLOptions creates a SAMLOptions object with default parameters.
+func NewSAMLOptions() *SAMLOptions {
+	return &SAMLOptions{
+		EntityID:          "",
+		UsernameAttribute: "",
+		EmailAttribute:    "email",
+		NicknameAttribute: "displayName",
+		JITProvisioning:   false,
+		ClockSkew:         90 * time.Second,
+	}
+}
+
+// Enabled reports whether the SAML 2.0 login is enabled.
+func (o *SAMLOptions) Enabled() bool {
+	return o.EntityID != ""
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *SAMLOptions) Validate() []error {
+	errs := []error{}
+
+	if !o.Enabled() {
+		return errs
+	}
+
+	for flag, value := range map[string]string{"--saml.acs-url": o.ACSURL, "--saml.idp-sso-url": o.IDPSSOURL} {
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s must be an absolute url when --saml.entity-id is set", flag))
+		}
+	}
+
+	if o.IDPCertFile == "" {
+		errs = append(errs, fmt.Errorf("--saml.idp-cert-file must be specified when --saml.entity-id is set"))
+	}
+
+	if o.ClockSkew < 0 {
+		errs = append(errs, fmt.Errorf("--saml.clock-skew can not be negative"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to the SAML 2.0 login for a specific api server to the
+// specified FlagSet.
+func (o *SAMLOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringVar(&o.EntityID, "saml.entity-id", o.EntityID, ""+
+		"Entity ID of iam-apiserver as a SAML service provider. The SAML login is disabled if it is empty.")
+	fs.StringVar(&o.ACSURL, "saml.acs-url", o.ACSURL, ""+
+		"Absolute url of the assertion consumer service, e.g. https://iam.example.com/saml/acs.")
+	fs.StringVar(&o.IDPEntityID, "saml.idp-entity-id", o.IDPEntityID, ""+
+		"Entity ID of the identity provider. If set, the issuer of the responses must match it.")
+	fs.StringVar(&o.IDPSSOURL, "saml.idp-sso-url", o.IDPSSOURL, ""+
+		"Single sign-on url of the identity provider which supports the HTTP-Redirect binding.")
+	fs.StringVar(&o.IDPCertFile, "saml.idp-cert-file", o.IDPCertFile, ""+
+		"File containing the PEM encoded certificate which signs the responses of the identity provider.")
+	fs.StringVar(&o.UsernameAttribute, "saml.username-attribute", o.UsernameAttribute, ""+
+		"Assertion attribute used as the iam user name. The NameID is used if it is empty.")
+	fs.StringVar(&o.EmailAttribute, "saml.email-attribute", o.EmailAttribute, ""+
+		"Assertion attribute used as the email of the provisioned users.")
+	fs.StringVar(&o.NicknameAttribute, "saml.nickname-attribute", o.NicknameAttribute, ""+
+		"Assertion attribute used as the nickname of the provisioned users.")
+	fs.BoolVar(&o.JITProvisioning, "saml.jit-provisioning", o.JITProvisioning, ""+
+		"Create the users which do not exist on their first SAML login.")
+	fs.DurationVar(&o.ClockSkew, "saml.clock-skew", o.ClockSkew, ""+
+		"Clock skew allowed when validating the time conditions of the assertions.")
+}
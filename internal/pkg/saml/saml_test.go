@@ -0,0 +1,267 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testEntityID = "https://iam.example.com/saml/metadata"
+	testACSURL   = "https://iam.example.com/saml/acs"
+	testIDP      = "https://idp.example.com"
+	testID       = "id-request"
+)
+
+var testNow = time.Date(2020, 9, 23, 11, 45, 16, 0, time.UTC)
+
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    testNow.Add(-time.Hour),
+		NotAfter:     testNow.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+
+	return key, cert
+}
+
+func newTestServiceProvider(cert *x509.Certificate) *ServiceProvider {
+	return &ServiceProvider{
+		EntityID:       testEntityID,
+		ACSURL:         testACSURL,
+		IDPEntityID:    testIDP,
+		IDPSSOURL:      testIDP + "/sso",
+		IDPCertificate: cert,
+		ClockSkew:      90 * time.Second,
+		now:            func() time.Time { return testNow },
+	}
+}
+
+// testAssertion returns an assertion of colin, the {{signature}} placeholder is replaced by the signature.
+func testAssertion(id, audience string) string {
+	return `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="` + id + `" Version="2.0" ` +
+		`IssueInstant="2020-09-23T11:45:16Z"><saml:Issuer>` + testIDP + `</saml:Issuer>{{signature}}` +
+		`<saml:Subject><saml:NameID>colin</saml:NameID><saml:SubjectConfirmation ` +
+		`Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml:SubjectConfirmationData InResponseTo="` + testID +
+		`" NotOnOrAfter="2020-09-23T11:50:16Z" Recipient="` + testACSURL + `"/></saml:SubjectConfirmation>` +
+		`</saml:Subject><saml:Conditions NotBefore="2020-09-23T11:44:16Z" NotOnOrAfter="2020-09-23T11:50:16Z">` +
+		`<saml:AudienceRestriction><saml:Audience>` + audience + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions><saml:AuthnStatement SessionIndex="session-1"/><saml:AttributeStatement>` +
+		`<saml:Attribute Name="urn:oid:0.9.2342.19200300.100.1.3" FriendlyName="email">` +
+		`<saml:AttributeValue>colin@foxmail.com</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement></saml:Assertion>`
+}
+
+func testResponse(assertions string) string {
+	return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="id-response" ` +
+		`InResponseTo="` + testID + `" Version="2.0" IssueInstant="2020-09-23T11:45:16Z" Destination="` +
+		testACSURL + `"><saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">` + testIDP +
+		`</saml:Issuer><samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/>` +
+		`</samlp:Status>` + assertions + `</samlp:Response>`
+}
+
+// sign signs the element with the ID in doc, and replaces its {{signature}} placeholder.
+func sign(t *testing.T, key *rsa.PrivateKey, doc, id string) string {
+	t.Helper()
+
+	find := func(doc string) *element {
+		root, err := parse([]byte(strings.Replace(doc, "{{signature}}", "", 1)))
+		if err != nil {
+			t.Fatalf("parse() error = %v", err)
+		}
+
+		var found *element
+		var walk func(e *element)
+		walk = func(e *element) {
+			if e.attr("ID") == id {
+				found = e
+			}
+			for _, child := range e.children {
+				if c, ok := child.(*element); ok {
+					walk(c)
+				}
+			}
+		}
+		walk(root)
+
+		return found
+	}
+
+	digest := sha256.Sum256(canonicalize(find(doc), nil, nil))
+	signature := `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/></ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo><ds:SignatureValue>{{value}}</ds:SignatureValue></ds:Signature>`
+	doc = strings.Replace(doc, "{{signature}}", signature, 1)
+
+	signedInfo := find(doc).child(nsDSig, "Signature").child(nsDSig, "SignedInfo")
+	hashed := sha256.Sum256(canonicalize(signedInfo, nil, nil))
+	value, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	return strings.Replace(doc, "{{value}}", base64.StdEncoding.EncodeToString(value), 1)
+}
+
+func TestServiceProvider_ParseResponse(t *testing.T) {
+	key, cert := newTestCertificate(t)
+	_, otherCert := newTestCertificate(t)
+
+	signedAssertion := sign(t, key, testAssertion("id-assertion", testEntityID), "id-assertion")
+
+	tests := []struct {
+		name     string
+		response string
+		cert     *x509.Certificate
+		requests map[string]bool
+		wantErr  bool
+	}{
+		{
+			name:     "signed assertion",
+			response: testResponse(signedAssertion),
+		},
+		{
+			name: "signed response",
+			response: sign(t, key, strings.Replace(
+				testResponse(strings.Replace(testAssertion("id-assertion", testEntityID), "{{signature}}", "", 1)),
+				"</saml:Issuer><samlp:Status>", "</saml:Issuer>{{signature}}<samlp:Status>", 1), "id-response"),
+		},
+		{
+			name:     "unsigned",
+			response: testResponse(strings.Replace(testAssertion("id-assertion", testEntityID), "{{signature}}", "", 1)),
+			wantErr:  true,
+		},
+		{
+			name:     "tampered assertion",
+			response: testResponse(strings.Replace(signedAssertion, ">colin<", ">admin<", 1)),
+			wantErr:  true,
+		},
+		{
+			name:     "signed by another certificate",
+			response: testResponse(signedAssertion),
+			cert:     otherCert,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown request",
+			response: testResponse(signedAssertion),
+			requests: map[string]bool{},
+			wantErr:  true,
+		},
+		{
+			name: "wrong audience",
+			response: testResponse(sign(t, key, testAssertion("id-assertion", "https://other.example.com"),
+				"id-assertion")),
+			wantErr: true,
+		},
+		{
+			name: "wrapped assertion",
+			response: testResponse(strings.Replace(strings.Replace(signedAssertion, ">colin<", ">admin<", 1),
+				`<saml:Subject>`, `<saml:Advice>`+signedAssertion+`</saml:Advice><saml:Subject>`, 1)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := newTestServiceProvider(cert)
+			if tt.cert != nil {
+				sp.IDPCertificate = tt.cert
+			}
+
+			requests := tt.requests
+			if requests == nil {
+				requests = map[string]bool{testID: true}
+			}
+			consume := func(id string) bool {
+				ok := requests[id]
+				delete(requests, id)
+
+				return ok
+			}
+
+			encoded := base64.StdEncoding.EncodeToString([]byte(tt.response))
+			got, err := sp.ParseResponse(encoded, consume)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got.NameID != "colin" || got.SessionIndex != "session-1" || got.Attribute("email") != "colin@foxmail.com" {
+				t.Errorf("ParseResponse() = %+v", got)
+			}
+
+			// the request is consumed, the response can not be replayed
+			if _, err := sp.ParseResponse(encoded, consume); err == nil {
+				t.Errorf("ParseResponse() replayed response error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestServiceProvider_AuthnRequestURL(t *testing.T) {
+	sp := newTestServiceProvider(nil)
+
+	redirect, id, err := sp.AuthnRequestURL("/dashboard")
+	if err != nil {
+		t.Fatalf("AuthnRequestURL() error = %v", err)
+	}
+
+	u, _ := url.Parse(redirect)
+	if u.Host != "idp.example.com" || u.Query().Get("RelayState") != "/dashboard" {
+		t.Errorf("AuthnRequestURL() = %s", redirect)
+	}
+
+	deflated, _ := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	data, _ := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+
+	req, err := parse(data)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if !req.is(nsProtocol, "AuthnRequest") || req.attr("ID") != id ||
+		req.attr("AssertionConsumerServiceURL") != testACSURL ||
+		req.child(nsAssertion, "Issuer").text() != testEntityID {
+		t.Errorf("AuthnRequestURL() request = %s", data)
+	}
+}
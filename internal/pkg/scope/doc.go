@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package scope defines the secret scope which limits the audiences, actions and
+// resources a secret is allowed to authorize.
+package scope // import "github.com/marmotedu/iam/internal/pkg/scope"
@@ -0,0 +1,155 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scope
+
+import (
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+)
+
+// ExtendKey is the key under which the scope is stored in the secret extend fields.
+const ExtendKey = "scope"
+
+// Wildcard matches any audience or action.
+const Wildcard = "*"
+
+// Scope restricts what a secret can be used for. An empty list means no restriction
+// on that dimension.
+type Scope struct {
+	// Audiences lists the allowed values of the jwt `aud` claim.
+	Audiences []string `json:"audiences,omitempty"`
+
+	// Actions lists the ladon actions the secret can authorize.
+	Actions []string `json:"actions,omitempty"`
+
+	// Resources lists the resource prefixes the secret can authorize.
+	Resources []string `json:"resources,omitempty"`
+}
+
+// FromExtend returns the scope stored in the given extend fields.
+// Nil is returned if no scope is set.
+func FromExtend(ext metav1.Extend) (*Scope, error) {
+	value, ok := ext[ExtendKey]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal scope failed")
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes a scope from its json form.
+func Parse(data []byte) (*Scope, error) {
+	var s Scope
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, errors.Wrap(err, "unmarshal scope failed")
+	}
+
+	return &s, nil
+}
+
+// String returns the json format of the scope.
+func (s *Scope) String() string {
+	data, _ := json.Marshal(s)
+
+	return string(data)
+}
+
+// Validate validates that a scope is valid.
+func (s *Scope) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, aud := range s.Audiences {
+		if strings.TrimSpace(aud) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("audiences").Index(i), "must not be empty"))
+		}
+	}
+
+	for i, action := range s.Actions {
+		if strings.TrimSpace(action) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("actions").Index(i), "must not be empty"))
+		}
+	}
+
+	for i, resource := range s.Resources {
+		if strings.TrimSpace(resource) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("resources").Index(i), "must not be empty"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateExtend validates the scope stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	fldPath := field.NewPath("extend", ExtendKey)
+
+	s, err := FromExtend(ext)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, ext[ExtendKey], err.Error())}
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	return s.Validate(fldPath)
+}
+
+// AllowAudience reports whether one of the given jwt audiences is in scope.
+func (s *Scope) AllowAudience(audiences []string) bool {
+	if s == nil || len(s.Audiences) == 0 {
+		return true
+	}
+
+	for _, aud := range audiences {
+		if contains(s.Audiences, aud) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allow reports whether the secret can authorize the given action on the given resource.
+func (s *Scope) Allow(action, resource string) bool {
+	if s == nil {
+		return true
+	}
+
+	if len(s.Actions) > 0 && !contains(s.Actions, action) {
+		return false
+	}
+
+	if len(s.Resources) == 0 {
+		return true
+	}
+
+	for _, prefix := range s.Resources {
+		if strings.HasPrefix(resource, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == Wildcard || item == value {
+			return true
+		}
+	}
+
+	return false
+}
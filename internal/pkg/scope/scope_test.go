@@ -0,0 +1,142 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scope
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFromExtend(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     metav1.Extend
+		want    *Scope
+		wantErr bool
+	}{
+		{
+			name: "no scope",
+			ext:  metav1.Extend{"foo": "bar"},
+			want: nil,
+		},
+		{
+			name: "scope",
+			ext: metav1.Extend{ExtendKey: map[string]interface{}{
+				"actions":   []interface{}{"get"},
+				"resources": []interface{}{"resources:articles:"},
+			}},
+			want: &Scope{Actions: []string{"get"}, Resources: []string{"resources:articles:"}},
+		},
+		{
+			name:    "invalid scope",
+			ext:     metav1.Extend{ExtendKey: "get"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromExtend(tt.ext)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromExtend() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromExtend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_Allow(t *testing.T) {
+	s := &Scope{
+		Actions:   []string{"get", "list"},
+		Resources: []string{"resources:articles:"},
+	}
+
+	type args struct {
+		action   string
+		resource string
+	}
+	tests := []struct {
+		name  string
+		scope *Scope
+		args  args
+		want  bool
+	}{
+		{name: "nil scope", scope: nil, args: args{"delete", "resources:printer"}, want: true},
+		{name: "in scope", scope: s, args: args{"get", "resources:articles:ladon"}, want: true},
+		{name: "action out of scope", scope: s, args: args{"delete", "resources:articles:ladon"}, want: false},
+		{name: "resource out of scope", scope: s, args: args{"get", "resources:printer"}, want: false},
+		{name: "wildcard action", scope: &Scope{Actions: []string{Wildcard}}, args: args{"delete", "x"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Allow(tt.args.action, tt.args.resource); got != tt.want {
+				t.Errorf("Scope.Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_AllowAudience(t *testing.T) {
+	s := &Scope{Audiences: []string{"iam.authz.marmotedu.com"}}
+
+	tests := []struct {
+		name      string
+		scope     *Scope
+		audiences []string
+		want      bool
+	}{
+		{name: "unrestricted", scope: &Scope{}, audiences: nil, want: true},
+		{name: "allowed", scope: s, audiences: []string{"iam.authz.marmotedu.com"}, want: true},
+		{name: "denied", scope: s, audiences: []string{"iam.api.marmotedu.com"}, want: false},
+		{name: "missing", scope: s, audiences: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.AllowAudience(tt.audiences); got != tt.want {
+				t.Errorf("Scope.AllowAudience() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretInfo(t *testing.T) {
+	want := &Scope{Audiences: []string{"iam.authz.marmotedu.com"}, Actions: []string{"get"}}
+
+	info := &pb.SecretInfo{SecretId: "id", Username: "colin"}
+	SetSecretInfo(info, want)
+
+	// make sure the scope survives a round trip over the wire
+	data, err := proto.Marshal(info)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	var got pb.SecretInfo
+	if err := proto.Unmarshal(data, &got); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	if got.SecretId != info.SecretId || got.Username != info.Username {
+		t.Errorf("proto.Unmarshal() = %v, want %v", &got, info)
+	}
+
+	sc, err := FromSecretInfo(&got)
+	if err != nil {
+		t.Fatalf("FromSecretInfo() error = %v", err)
+	}
+	if !reflect.DeepEqual(sc, want) {
+		t.Errorf("FromSecretInfo() = %v, want %v", sc, want)
+	}
+
+	if sc, _ := FromSecretInfo(&pb.SecretInfo{}); sc != nil {
+		t.Errorf("FromSecretInfo() = %v, want nil", sc)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scope
+
+import (
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// secretInfoField is the field number used to carry the scope in pb.SecretInfo.
+// SecretInfo has no scope field yet, so the scope travels as an unknown field which
+// older iam-authz-server instances simply ignore.
+const secretInfoField protowire.Number = 100
+
+// SetSecretInfo attaches the scope to the given SecretInfo message.
+func SetSecretInfo(info *pb.SecretInfo, s *Scope) {
+	if s == nil {
+		return
+	}
+
+	m := info.ProtoReflect()
+	raw := protowire.AppendTag(m.GetUnknown(), secretInfoField, protowire.BytesType)
+	raw = protowire.AppendString(raw, s.String())
+	m.SetUnknown(raw)
+}
+
+// FromSecretInfo returns the scope attached to the given SecretInfo message.
+// Nil is returned if no scope is attached.
+func FromSecretInfo(info *pb.SecretInfo) (*Scope, error) {
+	raw := info.ProtoReflect().GetUnknown()
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return nil, errors.Wrap(protowire.ParseError(n), "parse unknown fields failed")
+		}
+		raw = raw[n:]
+
+		if num == secretInfoField && typ == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(raw)
+			if n < 0 {
+				return nil, errors.Wrap(protowire.ParseError(n), "parse scope field failed")
+			}
+
+			return Parse(value)
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return nil, errors.Wrap(protowire.ParseError(n), "parse unknown fields failed")
+		}
+		raw = raw[n:]
+	}
+
+	return nil, nil
+}
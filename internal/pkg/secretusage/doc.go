@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package secretusage tracks the last time each secret was used. iam-authz-server records the
+// secrets of the accepted requests in memory and flushes them to redis in batches, from where
+// iam-watcher moves them to mysql, so that the unused secrets can be identified and removed.
+package secretusage // import "github.com/marmotedu/iam/internal/pkg/secretusage"
@@ -0,0 +1,147 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretusage
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+// redisKey is the sorted set holding the secretIDs not moved to mysql yet, scored by the unix
+// time they were last used.
+const redisKey = "iam-secret-last-used"
+
+// FlushInterval is how often the recorded usages are flushed to redis.
+const FlushInterval = 10 * time.Second
+
+// Recorder batches the usages of the secrets in memory, a secret used many times between two
+// flushes is written once.
+type Recorder struct {
+	store *storage.RedisCluster
+
+	lock    sync.Mutex
+	pending map[string]int64
+}
+
+// defaultRecorder is the recorder used by Record and Start.
+var defaultRecorder = NewRecorder(&storage.RedisCluster{})
+
+// NewRecorder creates a recorder flushing the usages to the given store.
+func NewRecorder(store *storage.RedisCluster) *Recorder {
+	return &Recorder{store: store, pending: map[string]int64{}}
+}
+
+// Record records that the secret has just been used.
+func (r *Recorder) Record(secretID string) {
+	if secretID == "" {
+		return
+	}
+
+	now := time.Now().Unix()
+
+	r.lock.Lock()
+	r.pending[secretID] = now
+	r.lock.Unlock()
+}
+
+// Flush writes the recorded usages to redis. The usages are dropped when redis is unavailable,
+// the secrets in use being recorded again soon.
+func (r *Recorder) Flush() {
+	r.lock.Lock()
+	pending := r.pending
+	r.pending = make(map[string]int64, len(pending))
+	r.lock.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if !storage.Connected() {
+		log.Warnf("redis is down, dropped the usages of %d secrets", len(pending))
+
+		return
+	}
+
+	for secretID, usedAt := range pending {
+		r.store.AddToSortedSet(redisKey, secretID, float64(usedAt))
+	}
+}
+
+// Start flushes the recorded usages every FlushInterval until the context is done, when they
+// are flushed a last time.
+func (r *Recorder) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.Flush()
+			case <-ctx.Done():
+				r.Flush()
+
+				return
+			}
+		}
+	}()
+}
+
+// Record records that the secret has just been used with the default recorder.
+func Record(secretID string) {
+	defaultRecorder.Record(secretID)
+}
+
+// Start starts flushing the default recorder until the context is done.
+func Start(ctx context.Context) {
+	defaultRecorder.Start(ctx)
+}
+
+// Drain removes the usages flushed to redis and returns when each secret was last used.
+// The usages flushed meanwhile with a later time are kept for the next drain.
+func Drain(ctx context.Context) (map[string]time.Time, error) {
+	if !storage.Connected() {
+		return nil, storage.ErrRedisIsDown
+	}
+
+	store := (&storage.RedisCluster{}).WithContext(ctx)
+
+	secretIDs, scores, err := store.GetSortedSetRange(redisKey, "-inf", "+inf")
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[string]time.Time, len(secretIDs))
+	var last float64
+	for i, secretID := range secretIDs {
+		usages[secretID] = time.Unix(int64(scores[i]), 0)
+		if scores[i] > last {
+			last = scores[i]
+		}
+	}
+
+	if len(usages) == 0 {
+		return usages, nil
+	}
+
+	if err := store.RemoveSortedSetRange(redisKey, "-inf", strconv.FormatFloat(last, 'f', -1, 64)); err != nil {
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+// Requeue puts back the drained usages which could not be moved to mysql, for the next drain.
+func Requeue(ctx context.Context, usages map[string]time.Time) {
+	store := (&storage.RedisCluster{}).WithContext(ctx)
+	for secretID, at := range usages {
+		store.AddToSortedSet(redisKey, secretID, float64(at.Unix()))
+	}
+}
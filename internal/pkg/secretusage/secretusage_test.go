@@ -0,0 +1,37 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretusage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/marmotedu/iam/pkg/storage"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(&storage.RedisCluster{})
+
+	r.Record("")
+	r.Record("id-1")
+	r.Record("id-1")
+	r.Record("id-2")
+
+	if len(r.pending) != 2 {
+		t.Fatalf("pending usages = %v, want 2 secrets", r.pending)
+	}
+
+	// redis is not connected, the usages are dropped rather than piling up
+	r.Flush()
+	if len(r.pending) != 0 {
+		t.Errorf("pending usages after flush = %v, want none", r.pending)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	if _, err := Drain(context.Background()); err != storage.ErrRedisIsDown {
+		t.Errorf("Drain() error = %v, want %v", err, storage.ErrRedisIsDown)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/util/secretref"
 )
 
 const (
@@ -35,6 +36,9 @@ type Config struct {
 	Mode            string
 	Middlewares     []string
 	Healthz         bool
+	RequestTimeout  time.Duration
+	CrashDir        string
+	CorsOrigins     []string
 	EnableProfiling bool
 	EnableMetrics   bool
 }
@@ -82,6 +86,7 @@ func NewConfig() *Config {
 		Healthz:         true,
 		Mode:            gin.ReleaseMode,
 		Middlewares:     []string{},
+		CorsOrigins:     []string{"*"},
 		EnableProfiling: true,
 		EnableMetrics:   true,
 		Jwt: &JwtInfo{
@@ -115,6 +120,9 @@ func (c CompletedConfig) New() (*GenericAPIServer, error) {
 		enableMetrics:       c.EnableMetrics,
 		enableProfiling:     c.EnableProfiling,
 		middlewares:         c.Middlewares,
+		requestTimeout:      c.RequestTimeout,
+		crashDir:            c.CrashDir,
+		corsOrigins:         c.CorsOrigins,
 		Engine:              gin.New(),
 	}
 
@@ -143,5 +151,11 @@ func LoadConfig(cfg string, defaultName string) {
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err != nil {
 		log.Warnf("WARNING: viper failed to discover and load the configuration file: %s", err.Error())
+
+		return
+	}
+
+	if err := secretref.ResolveConfig(viper.GetViper()); err != nil {
+		log.Warnf("WARNING: failed to resolve the secrets of the configuration file: %s", err.Error())
 	}
 }
@@ -18,8 +18,11 @@ import (
 	"github.com/marmotedu/component-base/pkg/version"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
 	"golang.org/x/sync/errgroup"
+	"net"
 
 	"github.com/marmotedu/iam/internal/pkg/middleware"
+	"github.com/marmotedu/iam/internal/pkg/upgrade"
+	"github.com/marmotedu/iam/pkg/featuregate"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -41,6 +44,12 @@ type GenericAPIServer struct {
 	healthz         bool
 	enableMetrics   bool
 	enableProfiling bool
+	// requestTimeout bounds the work done for a request, including its database calls
+	requestTimeout time.Duration
+	// crashDir is the directory the recovery middleware writes the crash records to
+	crashDir string
+	// corsOrigins are the origins allowed by the cors middleware
+	corsOrigins []string
 	// wrapper for gin.Engine
 
 	insecureServer, secureServer *http.Server
@@ -78,6 +87,10 @@ func (s *GenericAPIServer) InstallAPIs() {
 	s.GET("/version", func(c *gin.Context) {
 		core.WriteResponse(c, nil, version.Get())
 	})
+
+	s.GET("/featuregates", func(c *gin.Context) {
+		core.WriteResponse(c, nil, featuregate.DefaultFeatureGate.Status())
+	})
 }
 
 // Setup do some setup work for gin engine.
@@ -92,6 +105,8 @@ func (s *GenericAPIServer) InstallMiddlewares() {
 	// necessary middlewares
 	s.Use(middleware.RequestID())
 	s.Use(middleware.Context())
+	s.Use(middleware.Timeout(s.requestTimeout))
+	s.Use(middleware.I18n())
 
 	// install custom middlewares
 	for _, m := range s.middlewares {
@@ -102,6 +117,14 @@ func (s *GenericAPIServer) InstallMiddlewares() {
 			continue
 		}
 
+		if m == "recovery" && s.crashDir != "" {
+			mw = middleware.Recovery(s.crashDir)
+		}
+
+		if m == "cors" {
+			middleware.SetCorsOrigins(s.corsOrigins)
+		}
+
 		log.Infof("install middleware: %s", m)
 		s.Use(mw)
 	}
@@ -139,6 +162,22 @@ func (s *GenericAPIServer) Run() error {
 		// MaxHeaderBytes: 1 << 20,
 	}
 
+	// the listeners are inherited from the previous process on upgrade
+	insecureListener, err := upgrade.Listen(s.InsecureServingInfo.Address)
+	if err != nil {
+		return err
+	}
+
+	key, cert := s.SecureServingInfo.CertKey.KeyFile, s.SecureServingInfo.CertKey.CertFile
+	secure := cert != "" && key != "" && s.SecureServingInfo.BindPort != 0
+
+	var secureListener net.Listener
+	if secure {
+		if secureListener, err = upgrade.Listen(s.SecureServingInfo.Address()); err != nil {
+			return err
+		}
+	}
+
 	var eg errgroup.Group
 
 	// Initializing the server in a goroutine so that
@@ -146,7 +185,7 @@ func (s *GenericAPIServer) Run() error {
 	eg.Go(func() error {
 		log.Infof("Start to listening the incoming requests on http address: %s", s.InsecureServingInfo.Address)
 
-		if err := s.insecureServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := s.insecureServer.Serve(insecureListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err.Error())
 
 			return err
@@ -158,14 +197,13 @@ func (s *GenericAPIServer) Run() error {
 	})
 
 	eg.Go(func() error {
-		key, cert := s.SecureServingInfo.CertKey.KeyFile, s.SecureServingInfo.CertKey.CertFile
-		if cert == "" || key == "" || s.SecureServingInfo.BindPort == 0 {
+		if !secure {
 			return nil
 		}
 
 		log.Infof("Start to listening the incoming requests on https address: %s", s.SecureServingInfo.Address())
 
-		if err := s.secureServer.ListenAndServeTLS(cert, key); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := s.secureServer.ServeTLS(secureListener, cert, key); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err.Error())
 
 			return err
@@ -185,6 +223,9 @@ func (s *GenericAPIServer) Run() error {
 		}
 	}
 
+	// the previous process drains once the server is ready
+	upgrade.Ready()
+
 	if err := eg.Wait(); err != nil {
 		log.Fatal(err.Error())
 	}
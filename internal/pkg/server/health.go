@@ -7,17 +7,24 @@ package server
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/marmotedu/iam/pkg/log"
 )
 
+// healthPaths are the paths the health check is handled at by the default serve mux, which is
+// shared by the components run in one process, e.g. by the all-in-one iam command.
+var healthPaths sync.Map
+
 // ServeHealthCheck runs a http server used to provide a api to check pump health status.
 func ServeHealthCheck(healthPath string, healthAddress string) {
-	http.HandleFunc("/"+healthPath, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status": "ok"}`))
-	})
+	if _, loaded := healthPaths.LoadOrStore(healthPath, true); !loaded {
+		http.HandleFunc("/"+healthPath, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status": "ok"}`))
+		})
+	}
 
 	if err := http.ListenAndServe(healthAddress, nil); err != nil {
 		log.Fatalf("Error serving health check endpoint: %s", err.Error())
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package session defines the sessions of the temporary credentials issued by assume-role,
+// whose policies restrict what the credentials can authorize beyond the policies of their owner.
+package session // import "github.com/marmotedu/iam/internal/pkg/session"
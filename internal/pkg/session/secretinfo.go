@@ -0,0 +1,57 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// secretInfoField is the field number used to carry the session in pb.SecretInfo, as an
+// unknown field like the scope of the secret.
+const secretInfoField protowire.Number = 104
+
+// SetSecretInfo attaches the session to the given SecretInfo message.
+func SetSecretInfo(info *pb.SecretInfo, s *Session) {
+	if s == nil {
+		return
+	}
+
+	m := info.ProtoReflect()
+	raw := protowire.AppendTag(m.GetUnknown(), secretInfoField, protowire.BytesType)
+	raw = protowire.AppendString(raw, s.String())
+	m.SetUnknown(raw)
+}
+
+// FromSecretInfo returns the session attached to the given SecretInfo message.
+// Nil is returned if no session is attached.
+func FromSecretInfo(info *pb.SecretInfo) (*Session, error) {
+	raw := info.ProtoReflect().GetUnknown()
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return nil, errors.Wrap(protowire.ParseError(n), "parse unknown fields failed")
+		}
+		raw = raw[n:]
+
+		if num == secretInfoField && typ == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(raw)
+			if n < 0 {
+				return nil, errors.Wrap(protowire.ParseError(n), "parse session field failed")
+			}
+
+			return Parse(value)
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return nil, errors.Wrap(protowire.ParseError(n), "parse unknown fields failed")
+		}
+		raw = raw[n:]
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"fmt"
+	"time"
+
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	// register the iam specific conditions the session policies may use.
+	_ "github.com/marmotedu/iam/internal/pkg/condition"
+)
+
+// ExtendKey is the key under which the session is stored in the secret extend fields.
+const ExtendKey = "session"
+
+// The bounds of the lifetime of the temporary credentials.
+const (
+	MinDuration     = 15 * time.Minute
+	MaxDuration     = 12 * time.Hour
+	DefaultDuration = time.Hour
+
+	// MaxPolicies is the maximum number of policies of a session.
+	MaxPolicies = 10
+)
+
+// anySubject matches every subject, the session policies apply to the requests whatever
+// their subject is.
+const anySubject = "<.*>"
+
+// Session restricts the temporary credentials to the requests allowed by its policies. The
+// policies never grant anything by themselves: a request must be allowed by the policies of
+// the owner and by the session policies.
+type Session struct {
+	// Name identifies the session, e.g. the CI job, in the audit trail.
+	Name string `json:"name"`
+
+	// Policies are evaluated like the policies of the owner, a request is allowed if one of
+	// them allows it and none denies it. The subjects default to any subject.
+	Policies []*ladon.DefaultPolicy `json:"policies"`
+}
+
+// FromExtend returns the session stored in the given extend fields.
+// Nil is returned if no session is set.
+func FromExtend(ext metav1.Extend) (*Session, error) {
+	value, ok := ext[ExtendKey]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal session failed")
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes a session from its json form.
+func Parse(data []byte) (*Session, error) {
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, errors.Wrap(err, "unmarshal session failed")
+	}
+
+	for _, policy := range s.Policies {
+		if policy != nil && len(policy.Subjects) == 0 {
+			policy.Subjects = []string{anySubject}
+		}
+	}
+
+	return &s, nil
+}
+
+// String returns the json format of the session.
+func (s *Session) String() string {
+	data, _ := json.Marshal(s)
+
+	return string(data)
+}
+
+// Validate validates that a session is valid.
+func (s *Session) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, msg := range validation.IsQualifiedName(s.Name) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), s.Name, msg))
+	}
+
+	if len(s.Policies) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("policies"), "must restrict the session"))
+	}
+	if len(s.Policies) > MaxPolicies {
+		allErrs = append(allErrs, field.TooMany(fldPath.Child("policies"), len(s.Policies), MaxPolicies))
+	}
+
+	for i, policy := range s.Policies {
+		idxPath := fldPath.Child("policies").Index(i)
+		if policy == nil {
+			allErrs = append(allErrs, field.Required(idxPath, "must not be null"))
+
+			continue
+		}
+
+		if policy.Effect != ladon.AllowAccess && policy.Effect != ladon.DenyAccess {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("effect"), policy.Effect,
+				[]string{ladon.AllowAccess, ladon.DenyAccess}))
+		}
+		if len(policy.Actions) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("actions"), "must match some actions"))
+		}
+		if len(policy.Resources) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("resources"), "must match some resources"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateExtend validates the session stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	fldPath := field.NewPath("extend", ExtendKey)
+
+	s, err := FromExtend(ext)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, ext[ExtendKey], err.Error())}
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	return s.Validate(fldPath)
+}
+
+// Allow returns nil if the session policies allow the request, an error otherwise.
+func (s *Session) Allow(request *ladon.Request) error {
+	if s == nil {
+		return nil
+	}
+
+	policies := make(ladon.Policies, 0, len(s.Policies))
+	for _, policy := range s.Policies {
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+
+	// the audit trail is kept for the policies of the owner only
+	return (&ladon.Ladon{}).DoPoliciesAllow(request, policies)
+}
+
+// Restrict denies the response of an allowed request which the session policies do not allow,
+// so that the temporary credentials authorize the intersection of both.
+func (s *Session) Restrict(request *ladon.Request, rsp *authzv1.Response) *authzv1.Response {
+	if s == nil || !rsp.Allowed {
+		return rsp
+	}
+
+	if err := s.Allow(request); err != nil {
+		return &authzv1.Response{
+			Denied: true,
+			Reason: fmt.Sprintf("Request is not allowed by session %s: %s", s.Name, err.Error()),
+		}
+	}
+
+	return rsp
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"testing"
+
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+)
+
+func testSession(t *testing.T) *Session {
+	t.Helper()
+
+	s, err := FromExtend(metav1.Extend{ExtendKey: map[string]interface{}{
+		"name": "ci-build",
+		"policies": []interface{}{
+			map[string]interface{}{
+				"effect":    "allow",
+				"actions":   []interface{}{"<get|list>"},
+				"resources": []interface{}{"resources:articles:<.*>"},
+			},
+			map[string]interface{}{
+				"effect":    "deny",
+				"actions":   []interface{}{"get"},
+				"resources": []interface{}{"resources:articles:secret"},
+			},
+		},
+	}})
+	if err != nil || s == nil {
+		t.Fatalf("FromExtend() = %v, %v", s, err)
+	}
+
+	return s
+}
+
+func TestSession_Restrict(t *testing.T) {
+	s := testSession(t)
+
+	tests := []struct {
+		name    string
+		session *Session
+		request *ladon.Request
+		allowed bool
+		want    bool
+	}{
+		{"no session", nil, &ladon.Request{Action: "delete", Resource: "resources:printer"}, true, true},
+		{"allowed by both", s, &ladon.Request{Subject: "users:colin", Action: "get", Resource: "resources:articles:ladon"}, true, true},
+		{"denied by the owner policies", s, &ladon.Request{Action: "get", Resource: "resources:articles:ladon"}, false, false},
+		{"not allowed by the session", s, &ladon.Request{Action: "delete", Resource: "resources:articles:ladon"}, true, false},
+		{"denied by the session", s, &ladon.Request{Action: "get", Resource: "resources:articles:secret"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsp := tt.session.Restrict(tt.request, &authzv1.Response{Allowed: tt.allowed, Denied: !tt.allowed})
+			if rsp.Allowed != tt.want {
+				t.Errorf("Restrict() = %+v, want allowed %v", rsp, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateExtend(t *testing.T) {
+	if errs := ValidateExtend(metav1.Extend{ExtendKey: map[string]interface{}{
+		"name":     "ci-build",
+		"policies": []interface{}{map[string]interface{}{"effect": "allow", "actions": []interface{}{"get"}}},
+	}}); len(errs) != 1 {
+		t.Errorf("ValidateExtend() = %v, want a missing resources error", errs)
+	}
+
+	if errs := ValidateExtend(metav1.Extend{ExtendKey: map[string]interface{}{"name": "ci build"}}); len(errs) != 2 {
+		t.Errorf("ValidateExtend() = %v, want an invalid name and a missing policies error", errs)
+	}
+}
+
+func TestSecretInfo(t *testing.T) {
+	s := testSession(t)
+	info := &pb.SecretInfo{SecretId: "id"}
+	SetSecretInfo(info, s)
+
+	got, err := FromSecretInfo(info)
+	if err != nil || got == nil || got.Name != s.Name || len(got.Policies) != len(s.Policies) {
+		t.Errorf("FromSecretInfo() = %v, %v, want %v", got, err, s)
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package shadow defines the shadow mode of an authorization policy. A shadow policy
+// is evaluated by iam-authz-server but never changes the actual decision, its
+// would-be effect is only logged and metered.
+package shadow // import "github.com/marmotedu/iam/internal/pkg/shadow"
@@ -0,0 +1,65 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadow
+
+import (
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/ory/ladon"
+)
+
+// ExtendKey is the key under which the shadow mode is stored in the policy extend fields,
+// and in the metadata of the ladon policy sent to iam-authz-server.
+const ExtendKey = "shadow"
+
+// FromExtend reports whether the given extend fields mark the policy as shadow.
+func FromExtend(ext metav1.Extend) bool {
+	enabled, ok := ext[ExtendKey].(bool)
+
+	return ok && enabled
+}
+
+// ValidateExtend validates the shadow mode stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	value, ok := ext[ExtendKey]
+	if !ok {
+		return nil
+	}
+
+	if _, ok := value.(bool); !ok {
+		return field.ErrorList{field.Invalid(field.NewPath("extend", ExtendKey), value, "must be a boolean")}
+	}
+
+	return nil
+}
+
+// Mark marks the ladon policy as shadow in its metadata, keeping the other metadata fields.
+func Mark(policy *ladon.DefaultPolicy) {
+	meta := map[string]interface{}{}
+	// metadata which is not a json object can not be kept
+	_ = json.Unmarshal(policy.Meta, &meta)
+	meta[ExtendKey] = true
+
+	policy.Meta, _ = json.Marshal(meta)
+}
+
+// IsShadow reports whether the ladon policy is marked as shadow in its metadata.
+func IsShadow(policy ladon.Policy) bool {
+	// it is called for every candidate policy of every request, most of them have no metadata
+	if len(policy.GetMeta()) == 0 {
+		return false
+	}
+
+	var meta struct {
+		Shadow bool `json:"shadow"`
+	}
+
+	if err := json.Unmarshal(policy.GetMeta(), &meta); err != nil {
+		return false
+	}
+
+	return meta.Shadow
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shadow
+
+import (
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+)
+
+func TestValidateExtend(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     metav1.Extend
+		wantErr bool
+	}{
+		{name: "empty", ext: nil, wantErr: false},
+		{name: "enabled", ext: metav1.Extend{"shadow": true}, wantErr: false},
+		{name: "not_boolean", ext: metav1.Extend{"shadow": "yes"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateExtend(tt.ext); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("ValidateExtend() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *ladon.DefaultPolicy
+	}{
+		{name: "no_meta", policy: &ladon.DefaultPolicy{}},
+		{name: "keep_meta", policy: &ladon.DefaultPolicy{Meta: []byte(`{"owner":"colin"}`)}},
+		{name: "invalid_meta", policy: &ladon.DefaultPolicy{Meta: []byte(`owner`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if IsShadow(tt.policy) {
+				t.Fatalf("IsShadow() = true before Mark()")
+			}
+
+			Mark(tt.policy)
+
+			if !IsShadow(tt.policy) {
+				t.Errorf("IsShadow() = false after Mark(), meta = %s", tt.policy.Meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/marmotedu/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// An encrypted snapshot starts with encryptMagic and the salt of the key derived from the
+// passphrase, followed by the chunks sealed with AES-256-GCM. Each chunk is prefixed with its
+// size, whose high bit marks the last chunk. The nonce of a chunk is its sequence number and
+// the last chunk flag, so that the chunks can not be reordered, removed or truncated.
+var encryptMagic = []byte("IAMSNAP1")
+
+const (
+	saltSize  = 16
+	chunkSize = 64 << 10
+	lastChunk = 1 << 31
+)
+
+var (
+	// ErrPassphraseRequired is returned when reading an encrypted snapshot without passphrase.
+	ErrPassphraseRequired = errors.New("snapshot is encrypted, a passphrase is required")
+
+	// ErrDecrypt is returned when an encrypted snapshot can not be decrypted.
+	ErrDecrypt = errors.New("snapshot can not be decrypted, the passphrase is wrong or the snapshot is corrupted")
+)
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func nonce(size int, seq uint64, last bool) []byte {
+	ret := make([]byte, size)
+	binary.BigEndian.PutUint64(ret, seq)
+	if last {
+		ret[size-1] = 1
+	}
+
+	return ret
+}
+
+type encryptWriter struct {
+	w    io.Writer
+	aead cipher.AEAD
+	// header is written along with the first chunk, nothing is written before
+	header []byte
+	buf    []byte
+	seq    uint64
+}
+
+func newEncryptWriter(w io.Writer, passphrase string) (*encryptWriter, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		header: append(append([]byte{}, encryptMagic...), salt...),
+		buf:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := chunkSize - len(e.buf)
+		if size > len(p) {
+			size = len(p)
+		}
+
+		e.buf = append(e.buf, p[:size]...)
+		p = p[size:]
+
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Close seals the last chunk, it does not close the underlying writer.
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	sealed := e.aead.Seal(nil, nonce(e.aead.NonceSize(), e.seq, last), e.buf, nil)
+
+	if e.header != nil {
+		if _, err := e.w.Write(e.header); err != nil {
+			return err
+		}
+
+		e.header = nil
+	}
+
+	size := uint32(len(sealed))
+	if last {
+		size |= lastChunk
+	}
+
+	if err := binary.Write(e.w, binary.BigEndian, size); err != nil {
+		return err
+	}
+
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+
+	e.buf = e.buf[:0]
+	e.seq++
+
+	return nil
+}
+
+type decryptReader struct {
+	r    io.Reader
+	aead cipher.AEAD
+	buf  []byte
+	seq  uint64
+	last bool
+	err  error
+}
+
+func newDecryptReader(r io.Reader, passphrase string) (*decryptReader, error) {
+	header := make([]byte, len(encryptMagic)+saltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	aead, err := newAEAD(passphrase, header[len(encryptMagic):])
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{r: r, aead: aead}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.last {
+			return 0, io.EOF
+		}
+
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		d.err = d.open()
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	var header uint32
+	if err := binary.Read(d.r, binary.BigEndian, &header); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+
+	last := header&lastChunk != 0
+	size := header &^ lastChunk
+	if size > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrDecrypt
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+
+	plain, err := d.aead.Open(sealed[:0], nonce(d.aead.NonceSize(), d.seq, last), sealed, nil)
+	if err != nil {
+		return ErrDecrypt
+	}
+
+	d.buf = plain
+	d.seq++
+	d.last = last
+
+	return nil
+}
@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package snapshot reads and writes the snapshots of the iam resources, used to restore them
+// after a disaster or to copy them to another environment. A snapshot is a stream of json
+// lines, one resource per line and terminated by an end record, optionally compressed with
+// gzip and encrypted with a passphrase.
+package snapshot // import "github.com/marmotedu/iam/internal/pkg/snapshot"
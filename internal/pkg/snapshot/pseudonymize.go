@@ -0,0 +1,122 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/marmotedu/errors"
+)
+
+// MinPseudonymizationKeyLength is the minimum length of a pseudonymization key, the
+// pseudonyms of the short values, e.g. the phone numbers, could be guessed with short keys.
+const MinPseudonymizationKeyLength = 16
+
+// pseudonymDomain is the domain of the pseudonymized emails, reserved so that the emails
+// sent by the environments the snapshot is copied to are never delivered.
+const pseudonymDomain = "example.com"
+
+// ErrPseudonymizationKeyTooShort is returned when the pseudonymization key is too short.
+var ErrPseudonymizationKeyTooShort = errors.Errorf("pseudonymization key must have at least %d characters",
+	MinPseudonymizationKeyLength)
+
+// Pseudonymizer replaces the personal data by pseudonyms derived from a key: a value has the
+// same pseudonym in all the snapshots exported with the same key, so that they can be joined,
+// and the values can not be recovered without the key.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// NewPseudonymizer creates a Pseudonymizer with the key.
+func NewPseudonymizer(key string) (*Pseudonymizer, error) {
+	if len(key) < MinPseudonymizationKeyLength {
+		return nil, ErrPseudonymizationKeyTooShort
+	}
+
+	return &Pseudonymizer{key: []byte(key)}, nil
+}
+
+// Name returns the pseudonym of the name of a person.
+func (p *Pseudonymizer) Name(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return "user-" + hex.EncodeToString(p.sum("name", name))[:10]
+}
+
+// Email returns the pseudonym of an email, the emails of a domain keep sharing their domain.
+func (p *Pseudonymizer) Email(email string) string {
+	if email == "" {
+		return ""
+	}
+
+	email = strings.ToLower(email)
+	domain := ""
+	if i := strings.LastIndex(email, "@"); i >= 0 {
+		domain = email[i+1:]
+	}
+
+	return hex.EncodeToString(p.sum("email", email))[:16] + "@" +
+		hex.EncodeToString(p.sum("domain", domain))[:8] + "." + pseudonymDomain
+}
+
+// Phone returns the pseudonym of a phone number, the digits are replaced and the other
+// characters, e.g. the leading +, are kept.
+func (p *Pseudonymizer) Phone(phone string) string {
+	sum := p.sum("phone", phone)
+
+	ret := []byte(phone)
+	for i, c := range ret {
+		if c >= '0' && c <= '9' {
+			ret[i] = '0' + sum[i%len(sum)]%10
+		}
+	}
+
+	return string(ret)
+}
+
+func (p *Pseudonymizer) sum(field, value string) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+
+	return mac.Sum(nil)
+}
+
+// user replaces the nickname, the email and the phone of the json format of a user, and drops
+// its password hash.
+func (p *Pseudonymizer) user(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	delete(fields, "password")
+
+	for name, pseudonym := range map[string]func(string) string{
+		"nickname": p.Name,
+		"email":    p.Email,
+		"phone":    p.Phone,
+	} {
+		var value string
+		if raw, ok := fields[name]; !ok || json.Unmarshal(raw, &value) != nil {
+			continue
+		}
+
+		replaced, err := json.Marshal(pseudonym(value))
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = replaced
+	}
+
+	return json.Marshal(fields)
+}
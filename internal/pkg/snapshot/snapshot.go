@@ -0,0 +1,198 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+
+	"github.com/marmotedu/errors"
+)
+
+// Kinds of the resources of a snapshot, in the order they are written, so that a resource is
+// restored after the resources it refers to.
+const (
+	KindUser             = "User"
+	KindSecret           = "Secret"
+	KindPolicy           = "Policy"
+	KindGroup            = "Group"
+	KindPolicyAttachment = "PolicyAttachment"
+	KindQuota            = "Quota"
+)
+
+// kindEnd is the kind of the last record, a snapshot without it has been truncated.
+const kindEnd = "End"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Record is a line of a snapshot.
+type Record struct {
+	Kind string `json:"kind"`
+
+	// Object is the json format of the resource.
+	Object json.RawMessage `json:"object,omitempty"`
+
+	// Count is the number of the resources of the snapshot, only set on the end record.
+	Count int64 `json:"count,omitempty"`
+}
+
+// Writer writes a snapshot.
+type Writer struct {
+	enc           *json.Encoder
+	closers       []io.Closer
+	count         int64
+	pseudonymizer *Pseudonymizer
+}
+
+// NewWriter creates a writer of a snapshot to w, compressed with gzip if compress is true and
+// encrypted with the passphrase if it is not empty. The snapshot is complete once the writer
+// is closed.
+func NewWriter(w io.Writer, passphrase string, compress bool) (*Writer, error) {
+	ret := &Writer{}
+
+	if passphrase != "" {
+		ew, err := newEncryptWriter(w, passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.closers = append(ret.closers, ew)
+		w = ew
+	}
+
+	if compress {
+		gw := gzip.NewWriter(w)
+		ret.closers = append(ret.closers, gw)
+		w = gw
+	}
+
+	ret.enc = json.NewEncoder(w)
+
+	return ret, nil
+}
+
+// Pseudonymize replaces the personal data of the users, their nickname, email and phone, by
+// the pseudonyms of p, and drops the credentials: the password hashes of the users and the
+// secrets, which are left out of the snapshot. The usernames are kept, the other resources
+// and the policies refer to them.
+func (w *Writer) Pseudonymize(p *Pseudonymizer) {
+	w.pseudonymizer = p
+}
+
+// Write writes a resource of the kind.
+func (w *Writer) Write(kind string, obj interface{}) error {
+	// a secret restored without its key would verify the tokens signed with an empty key
+	if w.pseudonymizer != nil && kind == KindSecret {
+		return nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "marshal %s failed", kind)
+	}
+
+	if w.pseudonymizer != nil && kind == KindUser {
+		if data, err = w.pseudonymizer.user(data); err != nil {
+			return errors.Wrapf(err, "pseudonymize %s failed", kind)
+		}
+	}
+
+	w.count++
+
+	return w.enc.Encode(&Record{Kind: kind, Object: data})
+}
+
+// Close writes the end record and flushes the compressed and encrypted data.
+func (w *Writer) Close() error {
+	if err := w.enc.Encode(&Record{Kind: kindEnd, Count: w.count}); err != nil {
+		return err
+	}
+
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if err := w.closers[i].Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reader reads a snapshot.
+type Reader struct {
+	src   io.Reader
+	dec   *json.Decoder
+	count int64
+	done  bool
+}
+
+// NewReader creates a reader of the snapshot read from r, the compressed and the encrypted
+// snapshots are detected by their header. The passphrase is only required by the encrypted
+// snapshots.
+func NewReader(r io.Reader, passphrase string) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	if magic, _ := br.Peek(len(encryptMagic)); bytes.Equal(magic, encryptMagic) {
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+
+		dr, err := newDecryptReader(br, passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		br = bufio.NewReader(dr)
+	}
+
+	if magic, _ := br.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		br = bufio.NewReader(gr)
+	}
+
+	return &Reader{src: br, dec: json.NewDecoder(br)}, nil
+}
+
+// Next returns the next resource of the snapshot, and io.EOF after the last one. It returns
+// io.ErrUnexpectedEOF if the snapshot has been truncated.
+func (r *Reader) Next() (*Record, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	var rec Record
+	if err := r.dec.Decode(&rec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	if rec.Kind == kindEnd {
+		if rec.Count != r.count {
+			return nil, errors.Errorf("snapshot has %d resources, but %d were read", rec.Count, r.count)
+		}
+
+		// the compressed and the encrypted snapshots are verified once read to the end
+		if _, err := io.Copy(io.Discard, r.src); err != nil {
+			return nil, err
+		}
+
+		r.done = true
+
+		return nil, io.EOF
+	}
+
+	r.count++
+
+	return &rec, nil
+}
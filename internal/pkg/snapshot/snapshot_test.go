@@ -0,0 +1,190 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type object struct {
+	Name string `json:"name"`
+}
+
+func writeSnapshot(t *testing.T, passphrase string, compress bool, count int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, passphrase, compress)
+	assert.NoError(t, err)
+
+	for i := 0; i < count; i++ {
+		assert.NoError(t, w.Write(KindUser, &object{Name: strings.Repeat("x", i%100)}))
+	}
+	assert.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func readSnapshot(data []byte, passphrase string) (int, error) {
+	r, err := NewReader(bytes.NewReader(data), passphrase)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+
+		if err != nil {
+			return count, err
+		}
+
+		if rec.Kind != KindUser {
+			return count, io.ErrUnexpectedEOF
+		}
+		count++
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		compress   bool
+	}{
+		{name: "plain"},
+		{name: "compressed", compress: true},
+		{name: "encrypted", passphrase: "secret"},
+		{name: "compressed and encrypted", passphrase: "secret", compress: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// more than a chunk of encrypted data
+			data := writeSnapshot(t, tt.passphrase, tt.compress, 2000)
+
+			count, err := readSnapshot(data, tt.passphrase)
+			assert.NoError(t, err)
+			assert.Equal(t, 2000, count)
+
+			_, err = readSnapshot(data[:len(data)-10], tt.passphrase)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSnapshotPassphrase(t *testing.T) {
+	data := writeSnapshot(t, "secret", false, 10)
+
+	_, err := readSnapshot(data, "")
+	assert.Equal(t, ErrPassphraseRequired, err)
+
+	_, err = readSnapshot(data, "wrong")
+	assert.Equal(t, ErrDecrypt, err)
+
+	data[len(data)/2] ^= 0xff
+	_, err = readSnapshot(data, "secret")
+	assert.Equal(t, ErrDecrypt, err)
+}
+
+func TestSnapshotPseudonymize(t *testing.T) {
+	type user struct {
+		Name     string `json:"name"`
+		Nickname string `json:"nickname"`
+		Email    string `json:"email"`
+		Phone    string `json:"phone"`
+	}
+
+	export := func(key string) user {
+		p, err := NewPseudonymizer(key)
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, "", false)
+		assert.NoError(t, err)
+		w.Pseudonymize(p)
+		assert.NoError(t, w.Write(KindUser, &user{
+			Name:     "colin",
+			Nickname: "Colin Kong",
+			Email:    "Colin@Foxmail.com",
+			Phone:    "+86 181-2884-0000",
+		}))
+		assert.NoError(t, w.Close())
+
+		r, err := NewReader(&buf, "")
+		assert.NoError(t, err)
+		rec, err := r.Next()
+		assert.NoError(t, err)
+
+		var ret user
+		assert.NoError(t, json.Unmarshal(rec.Object, &ret))
+
+		return ret
+	}
+
+	got := export("0123456789abcdef")
+	assert.Equal(t, "colin", got.Name, "the usernames are kept")
+	assert.NotContains(t, got.Nickname, "Colin")
+	assert.True(t, strings.HasSuffix(got.Email, ".example.com"), "got %s", got.Email)
+	assert.NotContains(t, got.Email, "colin")
+	assert.Len(t, got.Phone, len("+86 181-2884-0000"))
+	assert.True(t, strings.HasPrefix(got.Phone, "+"))
+	assert.NotEqual(t, "+86 181-2884-0000", got.Phone)
+
+	assert.Equal(t, got, export("0123456789abcdef"), "the pseudonyms are deterministic per key")
+	assert.NotEqual(t, got, export("fedcba9876543210"))
+
+	_, err := NewPseudonymizer("short")
+	assert.Equal(t, ErrPseudonymizationKeyTooShort, err)
+}
+
+func TestSnapshotPseudonymize_credentials(t *testing.T) {
+	p, err := NewPseudonymizer("0123456789abcdef")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "", false)
+	assert.NoError(t, err)
+	w.Pseudonymize(p)
+	assert.NoError(t, w.Write(KindUser, &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin"},
+		Password:   "$2a$10$ZVKI6ySNnQb0/XmSBs1fmuKKqYBwcjgFDEYeVdTbjkcHfU6cm7wHK",
+	}))
+	assert.NoError(t, w.Write(KindSecret, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret0"},
+		Username:   "colin",
+		SecretID:   "ZuxvXNfG08BdEMqkTaP41L2DLArlE6Jpqoox",
+		SecretKey:  "7Sfa5EfAPIwcTLGCfSvqLf0zZGCjF3l8",
+	}))
+	assert.NoError(t, w.Close())
+
+	export := buf.String()
+	assert.NotContains(t, export, "$2a$10$", "the password hashes are dropped")
+	assert.NotContains(t, export, "7Sfa5EfAPIwcTLGCfSvqLf0zZGCjF3l8", "the secrets are left out")
+	assert.NotContains(t, export, "ZuxvXNfG08BdEMqkTaP41L2DLArlE6Jpqoox", "the secrets are left out")
+
+	r, err := NewReader(&buf, "")
+	assert.NoError(t, err)
+	rec, err := r.Next()
+	assert.NoError(t, err)
+	var user v1.User
+	assert.NoError(t, json.Unmarshal(rec.Object, &user))
+	assert.Equal(t, "colin", user.Name)
+	assert.Empty(t, user.Password)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err, "the end record counts the written resources only")
+}
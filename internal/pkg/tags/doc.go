@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package tags defines the key/value tags of the users, secrets and policies, which are
+// added to the context of the authorization requests so that the policy conditions can
+// refer to them.
+package tags // import "github.com/marmotedu/iam/internal/pkg/tags"
@@ -0,0 +1,80 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tags
+
+import (
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/json"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers used to carry the tags in pb.SecretInfo, as unknown fields like the
+// scope of the secret. The tags of the owner travel with every secret of the owner, which is
+// how iam-authz-server learns the tags of the users.
+const (
+	secretTagsField protowire.Number = 102
+	ownerTagsField  protowire.Number = 103
+)
+
+// SetSecretInfo attaches the tags of the secret and of its owner to the given SecretInfo
+// message. Empty tags are not attached.
+func SetSecretInfo(info *pb.SecretInfo, secret, owner Tags) {
+	if len(secret) == 0 && len(owner) == 0 {
+		return
+	}
+
+	m := info.ProtoReflect()
+	raw := m.GetUnknown()
+	if len(secret) > 0 {
+		raw = protowire.AppendTag(raw, secretTagsField, protowire.BytesType)
+		raw = protowire.AppendString(raw, secret.String())
+	}
+	if len(owner) > 0 {
+		raw = protowire.AppendTag(raw, ownerTagsField, protowire.BytesType)
+		raw = protowire.AppendString(raw, owner.String())
+	}
+	m.SetUnknown(raw)
+}
+
+// FromSecretInfo returns the tags of the secret and of its owner attached to the given
+// SecretInfo message. Nil is returned for the tags which are not attached.
+func FromSecretInfo(info *pb.SecretInfo) (secret Tags, owner Tags) {
+	raw := info.ProtoReflect().GetUnknown()
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return secret, owner
+		}
+		raw = raw[n:]
+
+		if (num == secretTagsField || num == ownerTagsField) && typ == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(raw)
+			if n < 0 {
+				return secret, owner
+			}
+			raw = raw[n:]
+
+			var t Tags
+			if json.Unmarshal(value, &t) != nil {
+				continue
+			}
+			if num == secretTagsField {
+				secret = t
+			} else {
+				owner = t
+			}
+
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return secret, owner
+		}
+		raw = raw[n:]
+	}
+
+	return secret, owner
+}
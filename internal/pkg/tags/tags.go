@@ -0,0 +1,141 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tags
+
+import (
+	"sort"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/ory/ladon"
+)
+
+// ExtendKey is the key under which the tags are stored in the extend fields of the users,
+// secrets and policies, and in the metadata of the ladon policy sent to iam-authz-server.
+const ExtendKey = "tags"
+
+const (
+	// CallerKey is the ladon request context key which carries the tags of the request subject.
+	CallerKey = "callerTags"
+	// ResourceKey is the ladon request context key which carries the tags of the requested resource.
+	ResourceKey = "resourceTags"
+)
+
+const (
+	// MaxTags is the maximum number of the tags of a user, a secret or a policy.
+	MaxTags = 50
+	// MaxValueLength is the maximum length of a tag value.
+	MaxValueLength = 256
+)
+
+// Tags are the key/value pairs attached to a user, a secret or a policy.
+type Tags map[string]string
+
+// FromExtend returns the tags stored in the given extend fields.
+// Nil is returned if no valid tags are set.
+func FromExtend(ext metav1.Extend) Tags {
+	t, _ := FromValue(ext[ExtendKey])
+
+	return t
+}
+
+// FromValue converts a decoded json object, or the tags themselves, to tags. The second
+// return value reports whether value holds tags, only string values are allowed.
+func FromValue(value interface{}) (Tags, bool) {
+	switch v := value.(type) {
+	case Tags:
+		return v, true
+	case map[string]string:
+		return v, true
+	case map[string]interface{}:
+		t := make(Tags, len(v))
+		for key, val := range v {
+			s, ok := val.(string)
+			if !ok {
+				return nil, false
+			}
+			t[key] = s
+		}
+
+		return t, true
+	default:
+		return nil, false
+	}
+}
+
+// Validate validates that the tags are valid.
+func (t Tags) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(t) > MaxTags {
+		allErrs = append(allErrs, field.TooMany(fldPath, len(t), MaxTags))
+	}
+
+	// sort the keys so that the errors are reported in a stable order
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath, key, msg))
+		}
+		if len(t[key]) > MaxValueLength {
+			allErrs = append(allErrs, field.TooLong(fldPath.Key(key), t[key], MaxValueLength))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateExtend validates the tags stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	value, ok := ext[ExtendKey]
+	if !ok || value == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("extend", ExtendKey)
+
+	t, ok := FromValue(value)
+	if !ok {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be an object of string values")}
+	}
+
+	return t.Validate(fldPath)
+}
+
+// String returns the json format of the tags.
+func (t Tags) String() string {
+	data, _ := json.Marshal(t)
+
+	return string(data)
+}
+
+// Mark stores the tags in the metadata of the ladon policy, keeping the other metadata fields.
+func Mark(policy *ladon.DefaultPolicy, t Tags) {
+	meta := map[string]interface{}{}
+	// metadata which is not a json object can not be kept
+	_ = json.Unmarshal(policy.Meta, &meta)
+	meta[ExtendKey] = t
+
+	policy.Meta, _ = json.Marshal(meta)
+}
+
+// FromPolicy returns the tags stored in the metadata of the ladon policy.
+func FromPolicy(policy ladon.Policy) Tags {
+	var meta struct {
+		Tags Tags `json:"tags"`
+	}
+
+	if err := json.Unmarshal(policy.GetMeta(), &meta); err != nil {
+		return nil
+	}
+
+	return meta.Tags
+}
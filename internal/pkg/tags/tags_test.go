@@ -0,0 +1,74 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidateExtend(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      metav1.Extend
+		wantErrs int
+	}{
+		{"no tags", metav1.Extend{}, 0},
+		{"valid", metav1.Extend{ExtendKey: map[string]interface{}{"team": "payments", "owner": "users:colin"}}, 0},
+		{"not an object", metav1.Extend{ExtendKey: "team=payments"}, 1},
+		{"not a string", metav1.Extend{ExtendKey: map[string]interface{}{"team": 1}}, 1},
+		{"invalid key", metav1.Extend{ExtendKey: map[string]interface{}{"-team": "payments"}}, 1},
+		{"long value", metav1.Extend{ExtendKey: map[string]interface{}{"team": strings.Repeat("a", MaxValueLength+1)}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateExtend(tt.ext); len(got) != tt.wantErrs {
+				t.Errorf("ValidateExtend() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policy := &ladon.DefaultPolicy{Meta: []byte(`{"tenant":"acme"}`)}
+	Mark(policy, Tags{"team": "payments"})
+
+	if got := FromPolicy(policy); !reflect.DeepEqual(got, Tags{"team": "payments"}) {
+		t.Errorf("FromPolicy() = %v", got)
+	}
+	if !strings.Contains(string(policy.Meta), `"tenant":"acme"`) {
+		t.Errorf("Mark() dropped the other metadata: %s", policy.Meta)
+	}
+}
+
+func TestSecretInfo(t *testing.T) {
+	info := &pb.SecretInfo{SecretId: "id0"}
+	SetSecretInfo(info, Tags{"env": "dev"}, Tags{"team": "payments"})
+
+	// the tags must survive the wire
+	data, err := proto.Marshal(info)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+	decoded := &pb.SecretInfo{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	secret, owner := FromSecretInfo(decoded)
+	if !reflect.DeepEqual(secret, Tags{"env": "dev"}) || !reflect.DeepEqual(owner, Tags{"team": "payments"}) {
+		t.Errorf("FromSecretInfo() = %v, %v", secret, owner)
+	}
+
+	if secret, owner := FromSecretInfo(&pb.SecretInfo{}); secret != nil || owner != nil {
+		t.Errorf("FromSecretInfo() without tags = %v, %v", secret, owner)
+	}
+}
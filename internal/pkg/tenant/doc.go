@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package tenant defines the tenant of an authorization policy. iam-authz-server partitions
+// the cached policies by tenant, and only evaluates the policies of the request tenant.
+package tenant // import "github.com/marmotedu/iam/internal/pkg/tenant"
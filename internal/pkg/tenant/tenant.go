@@ -0,0 +1,95 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tenant
+
+import (
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/ory/ladon"
+)
+
+// ExtendKey is the key under which the tenant is stored in the policy extend fields,
+// and in the metadata of the ladon policy sent to iam-authz-server.
+const ExtendKey = "tenant"
+
+// ContextKey is the ladon request context key which carries the tenant of the request.
+const ContextKey = "tenant"
+
+// FromExtend returns the tenant stored in the given extend fields, empty for the default tenant.
+func FromExtend(ext metav1.Extend) string {
+	name, _ := ext[ExtendKey].(string)
+
+	return name
+}
+
+// ValidateExtend validates the tenant stored in the given extend fields, if any.
+func ValidateExtend(ext metav1.Extend) field.ErrorList {
+	value, ok := ext[ExtendKey]
+	if !ok {
+		return nil
+	}
+
+	fldPath := field.NewPath("extend", ExtendKey)
+
+	name, ok := value.(string)
+	if !ok {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be a string")}
+	}
+
+	allErrs := field.ErrorList{}
+	for _, msg := range validation.IsDNS1123Label(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+
+	return allErrs
+}
+
+// Mark stores the tenant in the metadata of the ladon policy, keeping the other metadata fields.
+func Mark(policy *ladon.DefaultPolicy, name string) {
+	meta := map[string]interface{}{}
+	// metadata which is not a json object can not be kept
+	_ = json.Unmarshal(policy.Meta, &meta)
+	meta[ExtendKey] = name
+
+	policy.Meta, _ = json.Marshal(meta)
+}
+
+// FromPolicy returns the tenant stored in the metadata of the ladon policy.
+func FromPolicy(policy ladon.Policy) string {
+	var meta struct {
+		Tenant string `json:"tenant"`
+	}
+
+	if err := json.Unmarshal(policy.GetMeta(), &meta); err != nil {
+		return ""
+	}
+
+	return meta.Tenant
+}
+
+// FromRequest returns the tenant of the ladon request. The second return value reports
+// whether the tenant is valid, a tenant which is not a string is not.
+func FromRequest(r *ladon.Request) (string, bool) {
+	value, ok := r.Context[ContextKey]
+	if !ok {
+		return "", true
+	}
+
+	name, ok := value.(string)
+
+	return name, ok
+}
+
+// Key returns the key of the policy partition of the tenant, the default tenant uses the
+// username itself.
+func Key(username, name string) string {
+	if name == "" {
+		return username
+	}
+
+	return username + "/" + name
+}
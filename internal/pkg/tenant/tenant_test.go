@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tenant
+
+import (
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+)
+
+func TestValidateExtend(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     metav1.Extend
+		wantErr bool
+	}{
+		{name: "empty", ext: nil, wantErr: false},
+		{name: "valid", ext: metav1.Extend{"tenant": "marmotedu"}, wantErr: false},
+		{name: "not_string", ext: metav1.Extend{"tenant": 1}, wantErr: true},
+		{name: "invalid_name", ext: metav1.Extend{"tenant": "Marmot/Edu"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateExtend(tt.ext); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("ValidateExtend() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	policy := &ladon.DefaultPolicy{Meta: []byte(`{"shadow":true}`)}
+	if got := FromPolicy(policy); got != "" {
+		t.Fatalf("FromPolicy() = %s before Mark(), want empty", got)
+	}
+
+	Mark(policy, "marmotedu")
+
+	if got := FromPolicy(policy); got != "marmotedu" {
+		t.Errorf("FromPolicy() = %s, want marmotedu", got)
+	}
+	if string(policy.Meta) != `{"shadow":true,"tenant":"marmotedu"}` {
+		t.Errorf("Mark() meta = %s, want the other fields kept", policy.Meta)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		context   ladon.Context
+		want      string
+		wantValid bool
+	}{
+		{name: "default", context: ladon.Context{}, want: "", wantValid: true},
+		{name: "tenant", context: ladon.Context{"tenant": "marmotedu"}, want: "marmotedu", wantValid: true},
+		{name: "invalid", context: ladon.Context{"tenant": 1}, want: "", wantValid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, valid := FromRequest(&ladon.Request{Context: tt.context})
+			if got != tt.want || valid != tt.wantValid {
+				t.Errorf("FromRequest() = %s, %v, want %s, %v", got, valid, tt.want, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got := Key("colin", ""); got != "colin" {
+		t.Errorf("Key() = %s, want colin", got)
+	}
+
+	if got := Key("colin", "marmotedu"); got != "colin/marmotedu" {
+		t.Errorf("Key() = %s, want colin/marmotedu", got)
+	}
+}
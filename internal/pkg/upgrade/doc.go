@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package upgrade upgrades the binary of a server in place without downtime. On SIGUSR2 the
+// server starts the binary again, which inherits its listeners, and drains once the new
+// process is ready to serve.
+package upgrade // import "github.com/marmotedu/iam/internal/pkg/upgrade"
@@ -0,0 +1,107 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package upgrade
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+const (
+	// listenersEnv lists the addresses of the inherited listeners, the listener of the i-th
+	// address is the file descriptor 3+i.
+	listenersEnv = "IAM_UPGRADE_LISTENERS"
+
+	// readyEnv is the file descriptor the new process closes once it is ready to serve.
+	readyEnv = "IAM_UPGRADE_READY_FD"
+)
+
+var (
+	mu sync.Mutex
+	// inherited are the listeners inherited from the previous process, by address
+	inherited = map[string]net.Listener{}
+	// listeners are the listeners of the process, by address
+	listeners = map[string]*net.TCPListener{}
+)
+
+// nolint: gochecknoinits
+func init() {
+	addrs := os.Getenv(listenersEnv)
+	if addrs == "" {
+		return
+	}
+	_ = os.Unsetenv(listenersEnv)
+
+	for i, addr := range strings.Split(addrs, ",") {
+		f := os.NewFile(uintptr(3+i), addr)
+		ln, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Inherit the listener of %s failed: %s\n", addr, err.Error())
+
+			continue
+		}
+
+		inherited[addr] = ln
+	}
+}
+
+// Listen announces on the tcp address, the listener is inherited from the previous process
+// if it listened on the same address.
+func Listen(addr string) (net.Listener, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	ln, ok := inherited[addr]
+	if ok {
+		delete(inherited, addr)
+		log.Infof("Inherit the listener of %s from the previous process", addr)
+	} else {
+		var err error
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return nil, err
+		}
+	}
+
+	if tcp, ok := ln.(*net.TCPListener); ok {
+		listeners[addr] = tcp
+	}
+
+	return ln, nil
+}
+
+// listenerFiles returns the addresses of the listeners along with a duplicate of their file
+// descriptors.
+func listenerFiles() ([]string, []*os.File, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	addrs := make([]string, 0, len(listeners))
+	files := make([]*os.File, 0, len(listeners))
+	for addr, ln := range listeners {
+		f, err := ln.File()
+		if err != nil {
+			closeFiles(files)
+
+			return nil, nil, err
+		}
+
+		addrs = append(addrs, addr)
+		files = append(files, f)
+	}
+
+	return addrs, files, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package upgrade
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListen(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	addrs, files, err := listenerFiles()
+	assert.NoError(t, err)
+	defer closeFiles(files)
+	assert.Equal(t, []string{"127.0.0.1:0"}, addrs)
+
+	// the duplicated file accepts the connections of the listener, as the new process does
+	dup, err := net.FileListener(files[0])
+	assert.NoError(t, err)
+	inherited["127.0.0.1:0"] = dup
+
+	ln2, err := Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln2.Close()
+	assert.Equal(t, ln.Addr().String(), ln2.Addr().String())
+
+	// the inherited listeners which are not used are closed once ready
+	unused, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	inherited["127.0.0.1:1"] = unused
+	Ready()
+	assert.Empty(t, inherited)
+
+	_, err = unused.Accept()
+	assert.Error(t, err)
+}
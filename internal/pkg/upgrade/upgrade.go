@@ -0,0 +1,179 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/marmotedu/errors"
+	"net"
+
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/shutdown"
+)
+
+// Name defines shutdown manager name.
+const Name = "UpgradeManager"
+
+// readyTimeout is the time the new process has to be ready, it is killed after.
+const readyTimeout = time.Minute
+
+// Manager implements ShutdownManager interface, it upgrades the binary on SIGUSR2 and shuts
+// the old process down once the new one is ready.
+type Manager struct {
+	upgrading int32
+}
+
+// NewManager initializes the Manager.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// GetName returns name of this ShutdownManager.
+func (m *Manager) GetName() string {
+	return Name
+}
+
+// Start starts listening for SIGUSR2.
+func (m *Manager) Start(gs shutdown.GSInterface) error {
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGUSR2)
+
+		for range c {
+			if !atomic.CompareAndSwapInt32(&m.upgrading, 0, 1) {
+				log.Warn("An upgrade is already in progress")
+
+				continue
+			}
+
+			if err := upgrade(); err != nil {
+				log.Errorf("Upgrade failed, the current process keeps serving: %s", err.Error())
+				atomic.StoreInt32(&m.upgrading, 0)
+
+				continue
+			}
+
+			log.Info("The new process is ready, drain the current process")
+			gs.StartShutdown(m)
+
+			return
+		}
+	}()
+
+	return nil
+}
+
+// ShutdownStart does nothing.
+func (m *Manager) ShutdownStart() error {
+	return nil
+}
+
+// ShutdownFinish exits the app with os.Exit(0).
+func (m *Manager) ShutdownFinish() error {
+	os.Exit(0)
+
+	return nil
+}
+
+// upgrade starts the binary again with the listeners of the process, and waits until it is
+// ready to serve.
+func upgrade() error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	addrs, files, err := listenerFiles()
+	if err != nil {
+		return errors.Wrap(err, "duplicate the listeners")
+	}
+	defer closeFiles(files)
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, readyW)
+	cmd.Env = append(os.Environ(),
+		listenersEnv+"="+strings.Join(addrs, ","),
+		readyEnv+"="+strconv.Itoa(3+len(files)),
+	)
+
+	log.Infof("Upgrade the process with %s, handing the listeners of %v over", path, addrs)
+	err = cmd.Start()
+	_ = readyW.Close()
+	if err != nil {
+		return err
+	}
+
+	// the new process writes to the pipe once it is ready, the pipe is closed if it exits before
+	ready := make(chan error, 1)
+	go func() {
+		var b [1]byte
+		_, err := readyR.Read(b[:])
+		ready <- err
+	}()
+
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			log.Warnf("The new process exited: %s", err.Error())
+		}
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			return fmt.Errorf("the new process exited before it is ready")
+		}
+	case <-time.After(readyTimeout):
+		_ = cmd.Process.Kill()
+
+		return fmt.Errorf("the new process is not ready within %s", readyTimeout)
+	}
+
+	return nil
+}
+
+// Ready notifies the previous process the new one serves, so that it drains. The inherited
+// listeners which are not used are closed.
+func Ready() {
+	mu.Lock()
+	for addr, ln := range inherited {
+		log.Warnf("The inherited listener of %s is not used, close it", addr)
+		_ = ln.Close()
+	}
+	inherited = map[string]net.Listener{}
+	mu.Unlock()
+
+	fd := os.Getenv(readyEnv)
+	if fd == "" {
+		return
+	}
+	_ = os.Unsetenv(readyEnv)
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return
+	}
+
+	f := os.NewFile(uintptr(n), "ready")
+	_, _ = f.Write([]byte{1})
+	_ = f.Close()
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package userstate defines the lifecycle states of the users, stored in their status, and
+// the transitions between them. Only the active users are authenticated.
+package userstate // import "github.com/marmotedu/iam/internal/pkg/userstate"
@@ -0,0 +1,57 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package userstate
+
+import (
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// secretInfoField is the field number used to carry the state of the secret owner in
+// pb.SecretInfo, as an unknown field like the scope of the secret.
+const secretInfoField protowire.Number = 101
+
+// SetSecretInfo attaches the state of the secret owner to the given SecretInfo message.
+// Nothing is attached for an active owner, which is also what a missing state means.
+func SetSecretInfo(info *pb.SecretInfo, state State) {
+	if state == Active {
+		return
+	}
+
+	m := info.ProtoReflect()
+	raw := protowire.AppendTag(m.GetUnknown(), secretInfoField, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, uint64(state))
+	m.SetUnknown(raw)
+}
+
+// FromSecretInfo returns the state of the secret owner attached to the given SecretInfo
+// message, Active if none is attached.
+func FromSecretInfo(info *pb.SecretInfo) State {
+	raw := info.ProtoReflect().GetUnknown()
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return Active
+		}
+		raw = raw[n:]
+
+		if num == secretInfoField && typ == protowire.VarintType {
+			value, n := protowire.ConsumeVarint(raw)
+			if n < 0 {
+				return Active
+			}
+
+			return State(value)
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, raw)
+		if n < 0 {
+			return Active
+		}
+		raw = raw[n:]
+	}
+
+	return Active
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package userstate
+
+import (
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+// State is the lifecycle state of a user, stored as the status of the user.
+type State int
+
+// Define the user states. Deactivated and Active keep the values of the former disabled and
+// enabled status.
+const (
+	// Deactivated users are turned off for good, e.g. when they leave, until an administrator
+	// activates them again.
+	Deactivated State = 0
+	// Active users are authenticated.
+	Active State = 1
+	// Suspended users are turned off for a while, e.g. during an investigation.
+	Suspended State = 2
+)
+
+// Action moves a user to another state.
+type Action string
+
+// Define the user state actions.
+const (
+	Suspend    Action = "suspend"
+	Activate   Action = "activate"
+	Deactivate Action = "deactivate"
+)
+
+// transitions lists the states each action moves the users from.
+var transitions = map[Action]struct {
+	from []State
+	to   State
+}{
+	Suspend:    {from: []State{Active}, to: Suspended},
+	Activate:   {from: []State{Suspended, Deactivated}, to: Active},
+	Deactivate: {from: []State{Active, Suspended}, to: Deactivated},
+}
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Active:
+		return "active"
+	case Suspended:
+		return "suspended"
+	case Deactivated:
+		return "deactivated"
+	default:
+		return "unknown"
+	}
+}
+
+// Of returns the state of the user.
+func Of(user *v1.User) State {
+	return State(user.Status)
+}
+
+// Transition returns the state the action moves a user in the from state to. Applying an
+// action to a user already in its target state is allowed and changes nothing.
+// It returns a `github.com/marmotedu/errors.withCode` error.
+func Transition(from State, action Action) (State, error) {
+	t, ok := transitions[action]
+	if !ok {
+		return from, errors.WithCode(code.ErrValidation, "unknown user action %s", action)
+	}
+
+	if from == t.to {
+		return from, nil
+	}
+
+	for _, state := range t.from {
+		if state == from {
+			return t.to, nil
+		}
+	}
+
+	return from, errors.WithCode(code.ErrUserStateTransition, "can not %s a %s user", action, from)
+}
+
+// Check returns an error if the user in the given state can not be authenticated.
+// It returns a `github.com/marmotedu/errors.withCode` error.
+func Check(username string, state State) error {
+	switch state {
+	case Active:
+		return nil
+	case Suspended:
+		return errors.WithCode(code.ErrUserSuspended, "user %s is suspended", username)
+	default:
+		return errors.WithCode(code.ErrUserDeactivated, "user %s is deactivated", username)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package userstate
+
+import (
+	"testing"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     State
+		action   Action
+		want     State
+		wantCode int
+	}{
+		{"suspend active", Active, Suspend, Suspended, 0},
+		{"activate suspended", Suspended, Activate, Active, 0},
+		{"activate deactivated", Deactivated, Activate, Active, 0},
+		{"deactivate suspended", Suspended, Deactivate, Deactivated, 0},
+		{"suspend suspended", Suspended, Suspend, Suspended, 0},
+		{"suspend deactivated", Deactivated, Suspend, Deactivated, code.ErrUserStateTransition},
+		{"unknown action", Active, Action("delete"), Active, code.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Transition(tt.from, tt.action)
+			if got != tt.want {
+				t.Errorf("Transition() = %v, want %v", got, tt.want)
+			}
+
+			if tt.wantCode == 0 && err != nil {
+				t.Errorf("Transition() error = %v", err)
+			}
+			if tt.wantCode != 0 && errors.ParseCoder(err).Code() != tt.wantCode {
+				t.Errorf("Transition() error = %v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check("colin", Active); err != nil {
+		t.Errorf("Check() on active user error = %v", err)
+	}
+	if got := errors.ParseCoder(Check("colin", Suspended)).Code(); got != code.ErrUserSuspended {
+		t.Errorf("Check() on suspended user code = %d, want %d", got, code.ErrUserSuspended)
+	}
+	if got := errors.ParseCoder(Check("colin", Deactivated)).Code(); got != code.ErrUserDeactivated {
+		t.Errorf("Check() on deactivated user code = %d, want %d", got, code.ErrUserDeactivated)
+	}
+}
+
+func TestSecretInfo(t *testing.T) {
+	info := &pb.SecretInfo{SecretId: "id0"}
+	if got := FromSecretInfo(info); got != Active {
+		t.Fatalf("FromSecretInfo() on empty secret = %v, want active", got)
+	}
+
+	SetSecretInfo(info, Suspended)
+
+	// the state must survive the wire
+	raw, err := proto.Marshal(info)
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	got := &pb.SecretInfo{}
+	if err := proto.Unmarshal(raw, got); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	if state := FromSecretInfo(got); state != Suspended {
+		t.Errorf("FromSecretInfo() = %v, want suspended", state)
+	}
+}
@@ -2,9 +2,16 @@
 // Use of this source code is governed by a MIT style
 // license that can be found in the LICENSE file.
 
-// Package gormutil is a util to convert offset and limit to default values.
+// Package gormutil is a util to convert offset and limit to default values, to list
+// records along with their total count, and to filter them by field selectors in SQL.
 package gormutil
 
+import (
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/selection"
+	"gorm.io/gorm"
+)
+
 // DefaultLimit define the default number of records to be retrieved.
 const DefaultLimit = 1000
 
@@ -32,3 +39,88 @@ func Unpointer(offset *int64, limit *int64) *LimitAndOffset {
 		Limit:  l,
 	}
 }
+
+// TotalCountColumn is the column which carries the number of all the records matching a
+// query selected by WithTotalCount.
+const TotalCountColumn = "total_count"
+
+// WithTotalCount selects the number of all the records matching the query along with each
+// record of the page, in the TotalCountColumn column. A list then takes a single windowed
+// query, instead of a count query after the select one. The records are scanned into a type
+// embedding the model, so that its hooks still run, e.g.
+//
+//	type userRow struct {
+//		v1.User
+//		TotalCount int64 `gorm:"column:total_count"`
+//	}
+//
+// An empty page has no total count, the records have to be counted when it is not the first one.
+func WithTotalCount(db *gorm.DB) *gorm.DB {
+	return db.Select("*, COUNT(*) OVER() AS " + TotalCountColumn)
+}
+
+// WithTotalCountIf selects the total count like WithTotalCount only if count is true, the
+// count is skipped for the list requests which did not ask for it.
+func WithTotalCountIf(db *gorm.DB, count bool) *gorm.DB {
+	if !count {
+		return db
+	}
+
+	return WithTotalCount(db)
+}
+
+// WhereFields filters the query by the requirements of the selector on the fields found in
+// columns, which maps a field to its column. The requirements on the other fields are left to
+// the caller, they usually need more than an equality.
+func WhereFields(db *gorm.DB, selector fields.Selector, columns map[string]string) *gorm.DB {
+	if selector == nil {
+		return db
+	}
+
+	for _, r := range selector.Requirements() {
+		column, ok := columns[r.Field]
+		if !ok {
+			continue
+		}
+
+		switch r.Operator {
+		case selection.Equals, selection.DoubleEquals:
+			db = db.Where(column+" = ?", r.Value)
+		case selection.NotEquals:
+			db = db.Where(column+" <> ?", r.Value)
+		}
+	}
+
+	return db
+}
+
+// HasField reports whether the selector has a requirement on the field.
+func HasField(selector fields.Selector, field string) bool {
+	if selector == nil {
+		return false
+	}
+
+	for _, r := range selector.Requirements() {
+		if r.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderBy sorts the query by the column of the field found in columns, which maps a field to
+// its column, and then by id so that the pages stay stable. The records are sorted by id only
+// when the field is not found.
+func OrderBy(db *gorm.DB, field string, desc bool, columns map[string]string) *gorm.DB {
+	direction := " asc"
+	if desc {
+		direction = " desc"
+	}
+
+	if column, ok := columns[field]; ok {
+		db = db.Order(column + direction)
+	}
+
+	return db.Order("id" + direction)
+}
@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/AlekSi/pointer"
+	"github.com/marmotedu/component-base/pkg/fields"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
 )
 
 func TestUnpointer(t *testing.T) {
@@ -86,3 +89,136 @@ func FuzzUnpointer(f *testing.F) {
 		}
 	})
 }
+
+type user struct {
+	ID   uint64
+	Name string
+}
+
+func (u *user) TableName() string {
+	return "user"
+}
+
+type userRow struct {
+	user
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// dryRun returns a db whose statements are built, but never run.
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	// the dry run mode never connects to the database
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "iam@tcp(127.0.0.1:3306)/iam", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	return db
+}
+
+func TestWithTotalCount(t *testing.T) {
+	db := dryRun(t)
+
+	var rows []*userRow
+	stmt := WithTotalCount(db.Where("status = ?", 1)).Offset(10).Limit(5).Order("id desc").Find(&rows).Statement
+
+	want := "SELECT *, COUNT(*) OVER() AS total_count FROM `user` WHERE status = ? ORDER BY id desc LIMIT 5 OFFSET 10"
+	if got := stmt.SQL.String(); got != want {
+		t.Errorf("WithTotalCount() sql = %s, want %s", got, want)
+	}
+
+	stmt = WithTotalCountIf(dryRun(t).Where("status = ?", 1), false).Offset(10).Limit(5).Order("id desc").Find(&rows).Statement
+
+	want = "SELECT * FROM `user` WHERE status = ? ORDER BY id desc LIMIT 5 OFFSET 10"
+	if got := stmt.SQL.String(); got != want {
+		t.Errorf("WithTotalCountIf() sql = %s, want %s", got, want)
+	}
+}
+
+func TestWhereFields(t *testing.T) {
+	columns := map[string]string{"status": "status", "isAdmin": "isAdmin"}
+
+	tests := []struct {
+		name      string
+		selector  string
+		want      string
+		wantVars  []interface{}
+		wantField bool
+	}{
+		{
+			name:     "no selector",
+			want:     "SELECT * FROM `user`",
+			wantVars: []interface{}{},
+		},
+		{
+			name:      "equal and not equal",
+			selector:  "status=0,isAdmin!=1",
+			want:      "SELECT * FROM `user` WHERE isAdmin <> ? AND status = ?",
+			wantVars:  []interface{}{"1", "0"},
+			wantField: true,
+		},
+		{
+			name:     "unknown fields are left out",
+			selector: "name=colin,password=secret",
+			want:     "SELECT * FROM `user`",
+			wantVars: []interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := fields.ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseSelector() error = %v", err)
+			}
+
+			var users []*user
+			stmt := WhereFields(dryRun(t), selector, columns).Find(&users).Statement
+			if got := stmt.SQL.String(); got != tt.want || !reflect.DeepEqual(stmt.Vars, tt.wantVars) {
+				t.Errorf("WhereFields() sql = %s %v, want %s %v", got, stmt.Vars, tt.want, tt.wantVars)
+			}
+
+			if got := HasField(selector, "status"); got != tt.wantField {
+				t.Errorf("HasField() = %v, want %v", got, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	columns := map[string]string{"name": "name", "createdAt": "createdAt"}
+
+	tests := []struct {
+		name  string
+		field string
+		desc  bool
+		want  string
+	}{
+		{
+			name: "newest first",
+			desc: true,
+			want: "SELECT * FROM `user` ORDER BY id desc",
+		},
+		{
+			name:  "field",
+			field: "name",
+			want:  "SELECT * FROM `user` ORDER BY name asc,id asc",
+		},
+		{
+			name:  "unknown fields are left out",
+			field: "password",
+			desc:  true,
+			want:  "SELECT * FROM `user` ORDER BY id desc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []*user
+			stmt := OrderBy(dryRun(t), tt.field, tt.desc, columns).Find(&users).Statement
+			if got := stmt.SQL.String(); got != tt.want {
+				t.Errorf("OrderBy() sql = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
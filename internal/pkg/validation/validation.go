@@ -4,3 +4,126 @@
 
 // Package validation defines validate functions internal used by iam.
 package validation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// supportedValuesPrefix prefixes the supported values in the detail of the field.NotSupported errors.
+const supportedValuesPrefix = "supported values: "
+
+// FieldError is an invalid field of a request.
+type FieldError struct {
+	// Field is the path of the field, e.g. metadata.name or policy.subjects[0].
+	Field string `json:"field"`
+
+	// Reason is the type of the error, e.g. FieldValueRequired or FieldValueNotSupported.
+	Reason string `json:"reason"`
+
+	// Message describes why the field is invalid. The value of the field is left out of the
+	// field errors, it may be a secret, e.g. a password.
+	Message string `json:"message"`
+
+	// AllowedValues are the values the field can be set to, for the unsupported values.
+	AllowedValues []string `json:"allowedValues,omitempty"`
+}
+
+// ErrResponse is the response of a request with invalid fields, core.ErrResponse along with the
+// invalid fields.
+// swagger:model
+type ErrResponse struct {
+	core.ErrResponse
+
+	// Details lists the invalid fields of the request.
+	Details []FieldError `json:"details"`
+}
+
+// Error is the error of a request with invalid fields.
+type Error struct {
+	Fields []FieldError
+}
+
+// Error returns the aggregate of the messages of the invalid fields.
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+
+	return strings.Join(msgs, ", ")
+}
+
+// NewError returns an ErrValidation error of the field errors, which WriteResponse writes along
+// with the invalid fields.
+func NewError(errs field.ErrorList) error {
+	return NewErrorWithCode(code.ErrValidation, errs)
+}
+
+// NewErrorWithCode is like NewError, but returns an error of the code, e.g. a request denied by
+// an admission hook.
+func NewErrorWithCode(c int, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, newFieldError(err))
+	}
+
+	return errors.WrapC(&Error{Fields: fields}, c, "%s", errs.ToAggregate().Error())
+}
+
+// WriteResponse writes the response of an error or the data, like core.WriteResponse, but the
+// response of an error returned by NewError lists the invalid fields.
+func WriteResponse(c *gin.Context, err error, data interface{}) {
+	var verr *Error
+	if !errors.As(err, &verr) {
+		core.WriteResponse(c, err, data)
+
+		return
+	}
+
+	log.L(c).Errorf("%#+v", err)
+	coder := errors.ParseCoder(err)
+	c.JSON(coder.HTTPStatus(), ErrResponse{
+		ErrResponse: core.ErrResponse{
+			Code:      coder.Code(),
+			Message:   coder.String(),
+			Reference: coder.Reference(),
+		},
+		Details: verr.Fields,
+	})
+}
+
+func newFieldError(err *field.Error) FieldError {
+	f := FieldError{
+		Field:   err.Field,
+		Reason:  string(err.Type),
+		Message: err.Detail,
+	}
+
+	if err.Type == field.ErrorTypeNotSupported && strings.HasPrefix(err.Detail, supportedValuesPrefix) {
+		f.Message = err.Type.String()
+		for _, quoted := range strings.Split(strings.TrimPrefix(err.Detail, supportedValuesPrefix), ", ") {
+			if value, uerr := strconv.Unquote(quoted); uerr == nil {
+				f.AllowedValues = append(f.AllowedValues, value)
+			}
+		}
+	}
+
+	if f.Message == "" {
+		f.Message = err.Type.String()
+	}
+
+	return f
+}
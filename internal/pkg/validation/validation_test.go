@@ -0,0 +1,61 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/marmotedu/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/pkg/code"
+)
+
+func TestWriteResponse(t *testing.T) {
+	errs := field.ErrorList{
+		field.Required(field.NewPath("metadata", "name"), ""),
+		field.Invalid(field.NewPath("password"), "Admin@2020", "must contain an uppercase letter"),
+		field.NotSupported(field.NewPath("kind"), "role", []string{"user", "policy"}),
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/v1/users", nil)
+
+	WriteResponse(c, NewError(errs), nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, w.Body.String(), "Admin@2020")
+
+	var resp ErrResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrValidation, resp.Code)
+	assert.Equal(t, []FieldError{
+		{Field: "metadata.name", Reason: "FieldValueRequired", Message: "Required value"},
+		{Field: "password", Reason: "FieldValueInvalid", Message: "must contain an uppercase letter"},
+		{
+			Field:         "kind",
+			Reason:        "FieldValueNotSupported",
+			Message:       "Unsupported value",
+			AllowedValues: []string{"user", "policy"},
+		},
+	}, resp.Details)
+}
+
+func TestWriteResponse_otherErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/v1/users/colin", nil)
+
+	WriteResponse(c, errors.WithCode(code.ErrUserNotFound, "record not found"), nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "details")
+}
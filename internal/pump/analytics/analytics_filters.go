@@ -8,6 +8,7 @@ package analytics
 type AnalyticsFilters struct {
 	Usernames        []string `json:"usernames"`
 	SkippedUsernames []string `json:"skip_usernames"`
+	Effects          []string `json:"effects"`
 }
 
 // ShouldFilter determine whether a record should to be filtered out.
@@ -17,6 +18,8 @@ func (filters AnalyticsFilters) ShouldFilter(record AnalyticsRecord) bool {
 		return true
 	case len(filters.Usernames) > 0 && !stringInSlice(record.Username, filters.Usernames):
 		return true
+	case len(filters.Effects) > 0 && !stringInSlice(record.Effect, filters.Effects):
+		return true
 	}
 
 	return false
@@ -24,7 +27,7 @@ func (filters AnalyticsFilters) ShouldFilter(record AnalyticsRecord) bool {
 
 // HasFilter determine whether a record has a filter.
 func (filters AnalyticsFilters) HasFilter() bool {
-	if len(filters.SkippedUsernames) == 0 && len(filters.Usernames) == 0 {
+	if len(filters.SkippedUsernames) == 0 && len(filters.Usernames) == 0 && len(filters.Effects) == 0 {
 		return false
 	}
 
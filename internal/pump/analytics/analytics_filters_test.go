@@ -29,6 +29,15 @@ func TestShouldFilter(t *testing.T) {
 		t.Fatal("filter should be filtering the record")
 	}
 
+	// test effects
+	filter = AnalyticsFilters{
+		Effects: []string{"deny"},
+	}
+	shouldFilter = filter.ShouldFilter(record)
+	if shouldFilter == false {
+		t.Fatal("filter should be filtering the record")
+	}
+
 	// test no filter
 	filter = AnalyticsFilters{}
 	shouldFilter = filter.ShouldFilter(record)
@@ -37,6 +37,8 @@ func run(opts *options.Options) app.RunFunc {
 		log.Init(opts.Log)
 		defer log.Flush()
 
+		opts.RuntimeOptions.Apply()
+
 		cfg, err := config.CreateConfigFromOptions(opts)
 		if err != nil {
 			return err
@@ -0,0 +1,56 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pump
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// pipelineRecords counts the analytics records processed by the pipelines, partitioned by
+	// result, kept or dropped by the filters and the sampling of the pipeline.
+	pipelineRecords = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_pump_pipeline_records_total",
+			Help: "Number of analytics records processed by the pipelines, partitioned by pipeline and result.",
+		},
+		[]string{"pipeline", "result"},
+	)
+
+	// pipelineWrites counts the writes to the pumps of the pipelines, partitioned by result,
+	// success, failure or timeout.
+	pipelineWrites = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_pump_pipeline_writes_total",
+			Help: "Number of writes to the pumps of the pipelines, partitioned by pipeline, pump and result.",
+		},
+		[]string{"pipeline", "pump", "result"},
+	)
+
+	// pipelineWriteDuration observes how long the writes to the pumps of the pipelines take.
+	pipelineWriteDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "iam_pump_pipeline_write_duration_seconds",
+			Help:    "Duration of the writes to the pumps of the pipelines, partitioned by pipeline and pump.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"pipeline", "pump"},
+	)
+
+	// pipelineErasures counts the erasures of the records of the purged users by the pumps of
+	// the pipelines, partitioned by result, success or failure.
+	pipelineErasures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_pump_pipeline_erasures_total",
+			Help: "Number of erasures of the records of the purged users, partitioned by pipeline, pump and result.",
+		},
+		[]string{"pipeline", "pump", "result"},
+	)
+)
+
+// nolint: gochecknoinits
+func init() {
+	prometheus.MustRegister(pipelineRecords, pipelineWrites, pipelineWriteDuration, pipelineErasures)
+}
@@ -23,15 +23,30 @@ type PumpConfig struct {
 	Meta                  map[string]interface{}     `json:"meta"                    mapstructure:"meta"`
 }
 
+// DefaultPipeline is the name of the pipeline of the top-level pumps, which write all the
+// analytics records.
+const DefaultPipeline = "default"
+
+// PipelineConfig defines a pipeline, which writes the analytics records kept by its filters,
+// once transformed, to its own pumps. The pipelines process the same records independently.
+type PipelineConfig struct {
+	Filters               analytics.AnalyticsFilters `json:"filters"                 mapstructure:"filters"`
+	SampleRate            float64                    `json:"sample-rate"             mapstructure:"sample-rate"`
+	OmitDetailedRecording bool                       `json:"omit-detailed-recording" mapstructure:"omit-detailed-recording"`
+	Pumps                 map[string]PumpConfig      `json:"pumps"                   mapstructure:"pumps"`
+}
+
 // Options runs a pumpserver.
 type Options struct {
-	PurgeDelay            int                          `json:"purge-delay"             mapstructure:"purge-delay"`
-	Pumps                 map[string]PumpConfig        `json:"pumps"                   mapstructure:"pumps"`
-	HealthCheckPath       string                       `json:"health-check-path"       mapstructure:"health-check-path"`
-	HealthCheckAddress    string                       `json:"health-check-address"    mapstructure:"health-check-address"`
-	OmitDetailedRecording bool                         `json:"omit-detailed-recording" mapstructure:"omit-detailed-recording"`
-	RedisOptions          *genericoptions.RedisOptions `json:"redis"                   mapstructure:"redis"`
-	Log                   *log.Options                 `json:"log"                     mapstructure:"log"`
+	PurgeDelay            int                            `json:"purge-delay"             mapstructure:"purge-delay"`
+	Pumps                 map[string]PumpConfig          `json:"pumps"                   mapstructure:"pumps"`
+	Pipelines             map[string]PipelineConfig      `json:"pipelines"               mapstructure:"pipelines"`
+	HealthCheckPath       string                         `json:"health-check-path"       mapstructure:"health-check-path"`
+	HealthCheckAddress    string                         `json:"health-check-address"    mapstructure:"health-check-address"`
+	OmitDetailedRecording bool                           `json:"omit-detailed-recording" mapstructure:"omit-detailed-recording"`
+	RedisOptions          *genericoptions.RedisOptions   `json:"redis"                   mapstructure:"redis"`
+	Log                   *log.Options                   `json:"log"                     mapstructure:"log"`
+	RuntimeOptions        *genericoptions.RuntimeOptions `json:"runtime"                 mapstructure:"runtime"`
 }
 
 // NewOptions creates a new Options object with default parameters.
@@ -50,6 +65,7 @@ func NewOptions() *Options {
 		HealthCheckAddress: "0.0.0.0:7070",
 		RedisOptions:       genericoptions.NewRedisOptions(),
 		Log:                log.NewOptions(),
+		RuntimeOptions:     genericoptions.NewRuntimeOptions(),
 	}
 
 	return &s
@@ -59,6 +75,7 @@ func NewOptions() *Options {
 func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
+	o.RuntimeOptions.AddFlags(fss.FlagSet("runtime"))
 
 	// Note: the weird ""+ in below lines seems to be the only way to get gofmt to
 	// arrange these text blocks sensibly. Grrr.
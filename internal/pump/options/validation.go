@@ -4,12 +4,45 @@
 
 package options
 
+import (
+	"fmt"
+
+	"github.com/ory/ladon"
+)
+
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() []error {
 	var errs []error
 
+	errs = append(errs, o.validatePipelines()...)
+
 	errs = append(errs, o.RedisOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
+	errs = append(errs, o.RuntimeOptions.Validate()...)
+
+	return errs
+}
+
+func (o *Options) validatePipelines() []error {
+	var errs []error
+
+	for name, pipeline := range o.Pipelines {
+		if name == DefaultPipeline {
+			errs = append(errs, fmt.Errorf("pipeline name %s is reserved for the top-level pumps", DefaultPipeline))
+		}
+		if len(pipeline.Pumps) == 0 {
+			errs = append(errs, fmt.Errorf("pipeline %s must have at least one pump", name))
+		}
+		if pipeline.SampleRate < 0 || pipeline.SampleRate > 1 {
+			errs = append(errs, fmt.Errorf("pipelines.%s.sample-rate must be between 0 and 1, got %v", name, pipeline.SampleRate))
+		}
+		for _, effect := range pipeline.Filters.Effects {
+			if effect != ladon.AllowAccess && effect != ladon.DenyAccess {
+				errs = append(errs, fmt.Errorf("pipeline %s filters unsupported effect %s, must be %s or %s",
+					name, effect, ladon.AllowAccess, ladon.DenyAccess))
+			}
+		}
+	}
 
 	return errs
 }
@@ -0,0 +1,192 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pump
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/iam/internal/pump/analytics"
+	"github.com/marmotedu/iam/internal/pump/options"
+	"github.com/marmotedu/iam/internal/pump/pumps"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// pipeline writes the analytics records kept by its filters and its sampling, once
+// transformed, to its pumps.
+type pipeline struct {
+	name        string
+	filters     analytics.AnalyticsFilters
+	sampleRate  float64
+	omitDetails bool
+	pumps       []namedPump
+
+	// random returns a number in [0.0,1.0), it decides which records are sampled.
+	random func() float64
+}
+
+// namedPump is a pump along with the name it is configured by.
+type namedPump struct {
+	name string
+	pumps.Pump
+}
+
+// newPipelines creates the pipelines, the top-level pumps make the default pipeline.
+func newPipelines(pmps map[string]options.PumpConfig, configs map[string]options.PipelineConfig) []*pipeline {
+	var pipelines []*pipeline
+	if len(pmps) > 0 {
+		pipelines = append(pipelines, newPipeline(options.DefaultPipeline, options.PipelineConfig{Pumps: pmps}))
+	}
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pipelines = append(pipelines, newPipeline(name, configs[name]))
+	}
+
+	return pipelines
+}
+
+func newPipeline(name string, cfg options.PipelineConfig) *pipeline {
+	p := &pipeline{
+		name:        name,
+		filters:     cfg.Filters,
+		sampleRate:  cfg.SampleRate,
+		omitDetails: cfg.OmitDetailedRecording,
+		random:      rand.Float64, //nolint: gosec // the sampling needs no secure random
+	}
+
+	for key, pmp := range cfg.Pumps {
+		pumpTypeName := pmp.Type
+		if pumpTypeName == "" {
+			pumpTypeName = key
+		}
+
+		pmpType, err := pumps.GetPumpByName(pumpTypeName)
+		if err != nil {
+			log.Errorf("Pump load error of pipeline %s (skipping): %s", name, err.Error())
+
+			continue
+		}
+
+		pmpIns := pmpType.New()
+		if err := pmpIns.Init(pmp.Meta); err != nil {
+			log.Errorf("Pump init error of pipeline %s (skipping): %s", name, err.Error())
+
+			continue
+		}
+
+		log.Infof("Init Pump of pipeline %s: %s", name, pmpIns.GetName())
+		pmpIns.SetFilters(pmp.Filters)
+		pmpIns.SetTimeout(pmp.Timeout)
+		pmpIns.SetOmitDetailedRecording(pmp.OmitDetailedRecording)
+		p.pumps = append(p.pumps, namedPump{name: key, Pump: pmpIns})
+	}
+
+	return p
+}
+
+// process writes the records the pipeline keeps to its pumps, concurrently.
+func (p *pipeline) process(keys []interface{}, purgeDelay int) {
+	records := p.keep(keys)
+	pipelineRecords.WithLabelValues(p.name, "kept").Add(float64(len(records)))
+	pipelineRecords.WithLabelValues(p.name, "dropped").Add(float64(len(keys) - len(records)))
+
+	if len(records) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(p.pumps))
+	for _, pmp := range p.pumps {
+		go execPumpWriting(&wg, p.name, pmp, records, purgeDelay)
+	}
+	wg.Wait()
+}
+
+// keep returns the records kept by the filters and the sampling of the pipeline, transformed.
+// The records are copied, the pipelines share the records they process.
+func (p *pipeline) keep(keys []interface{}) []interface{} {
+	records := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		record, ok := key.(analytics.AnalyticsRecord)
+		if !ok || p.filters.ShouldFilter(record) {
+			continue
+		}
+
+		// a zero sample rate keeps all the records, like a sample rate of 1
+		if p.sampleRate > 0 && p.random() >= p.sampleRate {
+			continue
+		}
+
+		if p.omitDetails {
+			record.Policies = ""
+			record.Deciders = ""
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// erase erases the records of the users written to the pumps of the pipeline which can erase
+// them, the other pumps are skipped.
+func (p *pipeline) erase(usernames []string) {
+	for _, pmp := range p.pumps {
+		eraser, ok := pmp.Pump.(pumps.Eraser)
+		if !ok {
+			log.Warnf("Pump %s of pipeline %s can not erase the records of the purged users", pmp.GetName(), p.name)
+
+			continue
+		}
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if tm := pmp.GetTimeout(); tm > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), time.Duration(tm)*time.Second)
+		} else {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+
+		count, err := eraser.Erase(ctx, usernames)
+		cancel()
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+			log.Errorf("Error Erasing from: %s of pipeline %s - Error: %s", pmp.GetName(), p.name, err.Error())
+		} else {
+			log.Infof("Erased %d records of the purged users from %s of pipeline %s", count, pmp.GetName(), p.name)
+		}
+		pipelineErasures.WithLabelValues(p.name, pmp.name, result).Inc()
+	}
+}
+
+// processPipelines processes the records by all the pipelines, concurrently.
+func processPipelines(pipelines []*pipeline, keys []interface{}, purgeDelay int) {
+	if len(pipelines) == 0 {
+		log.Warn("No pumps defined!")
+
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(pipelines))
+	for _, p := range pipelines {
+		go func(p *pipeline) {
+			defer wg.Done()
+
+			p.process(keys, purgeDelay)
+		}(p)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pump
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/iam/internal/pkg/instruction"
+	"github.com/marmotedu/iam/internal/pump/analytics"
+	"github.com/marmotedu/iam/internal/pump/options"
+	"github.com/marmotedu/iam/internal/pump/pumps"
+)
+
+// fakePump records the analytics records written to it.
+type fakePump struct {
+	pumps.CommonPumpConfig
+
+	mu      sync.Mutex
+	records []interface{}
+}
+
+func (f *fakePump) GetName() string        { return "Fake Pump" }
+func (f *fakePump) New() pumps.Pump        { return &fakePump{} }
+func (f *fakePump) Init(interface{}) error { return nil }
+
+func (f *fakePump) WriteData(_ context.Context, data []interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records = append(f.records, data...)
+
+	return nil
+}
+
+func (f *fakePump) Erase(_ context.Context, usernames []string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kept := f.records[:0]
+	for _, r := range f.records {
+		if record, _ := r.(analytics.AnalyticsRecord); !contains(usernames, record.Username) {
+			kept = append(kept, r)
+		}
+	}
+
+	count := len(f.records) - len(kept)
+	f.records = kept
+
+	return count, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fakePump) written() []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.records
+}
+
+func TestProcessPipelines(t *testing.T) {
+	keys := []interface{}{
+		analytics.AnalyticsRecord{Username: "colin", Effect: ladon.AllowAccess, Policies: "p1"},
+		analytics.AnalyticsRecord{Username: "colin", Effect: ladon.DenyAccess, Policies: "p2"},
+		nil, // a record which could not be decoded
+		analytics.AnalyticsRecord{Username: "james", Effect: ladon.DenyAccess, Policies: "p3"},
+	}
+
+	all, denies, sampled := &fakePump{}, &fakePump{}, &fakePump{}
+	samples := []float64{0.1, 0.9, 0.2}
+	pipelines := []*pipeline{
+		{name: options.DefaultPipeline, pumps: []namedPump{{"all", all}}},
+		{
+			name:        "denies",
+			filters:     analytics.AnalyticsFilters{Effects: []string{ladon.DenyAccess}},
+			omitDetails: true,
+			pumps:       []namedPump{{"denies", denies}},
+		},
+		{
+			name:       "sampled",
+			sampleRate: 0.5,
+			random: func() float64 {
+				sample := samples[0]
+				samples = samples[1:]
+
+				return sample
+			},
+			pumps: []namedPump{{"sampled", sampled}},
+		},
+	}
+
+	processPipelines(pipelines, keys, 10)
+
+	assert.Len(t, all.written(), 3)
+	assert.Equal(t, []interface{}{
+		analytics.AnalyticsRecord{Username: "colin", Effect: ladon.DenyAccess},
+		analytics.AnalyticsRecord{Username: "james", Effect: ladon.DenyAccess},
+	}, denies.written())
+	assert.Equal(t, []interface{}{keys[0], keys[3]}, sampled.written())
+
+	// the pipelines do not change the records they share
+	assert.Equal(t, "p2", keys[1].(analytics.AnalyticsRecord).Policies)
+}
+
+func TestNewPipelines(t *testing.T) {
+	pipelines := newPipelines(
+		map[string]options.PumpConfig{"dummy": {}},
+		map[string]options.PipelineConfig{
+			"sampled": {SampleRate: 0.1, Pumps: map[string]options.PumpConfig{"dummy": {}}},
+			"denies":  {Pumps: map[string]options.PumpConfig{"unknown": {}}},
+		},
+	)
+
+	names := make([]string, 0, len(pipelines))
+	for _, p := range pipelines {
+		names = append(names, p.name)
+	}
+	assert.Equal(t, []string{options.DefaultPipeline, "denies", "sampled"}, names)
+	assert.Len(t, pipelines[0].pumps, 1)
+	// the pumps which can not be loaded are skipped
+	assert.Empty(t, pipelines[1].pumps)
+}
+
+// fakeStore returns the values queued in its lists.
+type fakeStore struct {
+	lists map[string][]interface{}
+}
+
+func (f *fakeStore) Init(interface{}) error { return nil }
+func (f *fakeStore) GetName() string        { return "fake" }
+func (f *fakeStore) Connect() bool          { return true }
+
+func (f *fakeStore) GetAndDeleteSet(key string) []interface{} {
+	values := f.lists[key]
+	delete(f.lists, key)
+
+	return values
+}
+
+func TestPumpServer_executeInstructions(t *testing.T) {
+	erase, _ := json.Marshal(instruction.Instruction{
+		Kind:      instruction.KindEraseUser,
+		Username:  "colin",
+		Operation: "op-purge",
+		CreatedAt: time.Now(),
+	})
+
+	pmp := &fakePump{records: []interface{}{
+		analytics.AnalyticsRecord{Username: "colin"},
+		analytics.AnalyticsRecord{Username: "james"},
+	}}
+	s := &pumpServer{
+		analyticsStore: &fakeStore{lists: map[string][]interface{}{
+			instruction.KeyName: {string(erase), `{"kind":"Unknown"}`, "invalid"},
+		}},
+		pipelines: []*pipeline{{name: options.DefaultPipeline, pumps: []namedPump{{"fake", pmp}}}},
+	}
+
+	assert.Equal(t, map[string]bool{"colin": true}, s.executeInstructions())
+	assert.Equal(t, []interface{}{analytics.AnalyticsRecord{Username: "james"}}, pmp.written())
+
+	// the instructions are executed once
+	assert.Empty(t, s.executeInstructions())
+}
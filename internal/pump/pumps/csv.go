@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"time"
 
 	"github.com/marmotedu/errors"
@@ -115,3 +116,99 @@ func (c *CSVPump) WriteData(ctx context.Context, data []interface{}) error {
 
 	return nil
 }
+
+// Erase rewrites the csv files without the records of the users.
+func (c *CSVPump) Erase(ctx context.Context, usernames []string) (int, error) {
+	files, err := filepath.Glob(path.Join(c.csvConf.CSVDir, "*.csv"))
+	if err != nil {
+		return 0, errors.Wrap(err, "list csv files failed")
+	}
+
+	erased := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		erased[username] = true
+	}
+
+	var total int
+	for _, fname := range files {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		count, err := eraseCSV(fname, erased)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
+// eraseCSV rewrites a csv file without the records of the users, and returns their number.
+// The file is left untouched if it has none.
+func eraseCSV(fname string, usernames map[string]bool) (int, error) {
+	infile, err := os.Open(fname)
+	if err != nil {
+		return 0, errors.Wrap(err, "open csv file failed")
+	}
+
+	rows, err := csv.NewReader(infile).ReadAll()
+	_ = infile.Close()
+	if err != nil {
+		return 0, errors.Wrapf(err, "read csv file %s failed", fname)
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	column := -1
+	for i, name := range rows[0] {
+		if name == "Username" {
+			column = i
+		}
+	}
+	if column < 0 {
+		return 0, nil
+	}
+
+	kept := rows[:1]
+	for _, row := range rows[1:] {
+		if column < len(row) && usernames[row[column]] {
+			continue
+		}
+		kept = append(kept, row)
+	}
+
+	count := len(rows) - len(kept)
+	if count == 0 {
+		return 0, nil
+	}
+
+	// the file is replaced at once, so that the records are not lost by a failed rewrite
+	tmpname := fname + ".erasing"
+	outfile, err := os.OpenFile(tmpname, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, errors.Wrap(err, "create csv file failed")
+	}
+
+	writer := csv.NewWriter(outfile)
+	_ = writer.WriteAll(kept)
+	if err := writer.Error(); err != nil {
+		_ = outfile.Close()
+		_ = os.Remove(tmpname)
+
+		return 0, errors.Wrapf(err, "write csv file %s failed", tmpname)
+	}
+
+	if err := outfile.Close(); err != nil {
+		return 0, errors.Wrapf(err, "close csv file %s failed", tmpname)
+	}
+
+	if err := os.Rename(tmpname, fname); err != nil {
+		return 0, errors.Wrap(err, "replace csv file failed")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pumps
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marmotedu/iam/internal/pump/analytics"
+)
+
+func TestCSVPump_Erase(t *testing.T) {
+	dir := t.TempDir()
+
+	c := &CSVPump{}
+	require.NoError(t, c.Init(map[string]interface{}{"csv_dir": dir}))
+	require.NoError(t, c.WriteData(context.Background(), []interface{}{
+		analytics.AnalyticsRecord{Username: "colin", Effect: "allow"},
+		analytics.AnalyticsRecord{Username: "james", Effect: "deny"},
+		analytics.AnalyticsRecord{Username: "colin", Effect: "deny"},
+	}))
+
+	count, err := c.Erase(context.Background(), []string{"colin"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "Username", rows[0][1])
+		assert.Equal(t, "james", rows[1][1])
+	}
+
+	// the files without the records of the users are left untouched
+	count, err = c.Erase(context.Background(), []string{"colin"})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/marmotedu/errors"
 	"github.com/mitchellh/mapstructure"
 	"github.com/vinllen/mgo"
+	"github.com/vinllen/mgo/bson"
 
 	"github.com/marmotedu/iam/internal/pump/analytics"
 	"github.com/marmotedu/iam/pkg/log"
@@ -454,6 +455,25 @@ func (m *MongoPump) WriteData(ctx context.Context, data []interface{}) error {
 	return nil
 }
 
+// Erase removes the records of the users from the collection. The capped collections only
+// allow it from MongoDB 5.0.
+func (m *MongoPump) Erase(ctx context.Context, usernames []string) (int, error) {
+	for m.dbSession == nil {
+		log.Debug("Connecting to analytics store")
+		m.connect()
+	}
+
+	sess := m.dbSession.Copy()
+	defer sess.Close()
+
+	info, err := sess.DB("").C(m.dbConf.CollectionName).RemoveAll(bson.M{"username": bson.M{"$in": usernames}})
+	if err != nil {
+		return 0, errors.Wrap(err, "remove the records from mongo failed")
+	}
+
+	return info.Removed, nil
+}
+
 // AccumulateSet accumulate data.
 func (m *MongoPump) AccumulateSet(data []interface{}) [][]interface{} {
 	accumulatorTotal := 0
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/ory/ladon"
@@ -44,7 +45,14 @@ func (p *PrometheusPump) New() Pump {
 		[]string{"code", "username"},
 	)
 
-	prometheus.MustRegister(newPump.TotalStatusMetrics)
+	// the prometheus pumps of several pipelines share the metrics
+	if err := prometheus.Register(newPump.TotalStatusMetrics); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+		newPump.TotalStatusMetrics, _ = are.ExistingCollector.(*prometheus.CounterVec)
+	}
 
 	return &newPump
 }
@@ -70,9 +78,13 @@ func (p *PrometheusPump) Init(conf interface{}) error {
 		return errors.New("prometheus listen_addr not set")
 	}
 
-	log.Infof("Starting prometheus listener on: %s", p.conf.Addr)
+	HandleMetrics(p.conf.Path)
 
-	http.Handle(p.conf.Path, promhttp.Handler())
+	if _, loaded := listeners.LoadOrStore(p.conf.Addr, true); loaded {
+		return nil
+	}
+
+	log.Infof("Starting prometheus listener on: %s", p.conf.Addr)
 
 	go func() {
 		log.Fatal(http.ListenAndServe(p.conf.Addr, nil).Error())
@@ -81,6 +93,21 @@ func (p *PrometheusPump) Init(conf interface{}) error {
 	return nil
 }
 
+// listeners and metricsPaths are the addresses and the paths the metrics are served on, by
+// the default serve mux, which the prometheus pumps of several pipelines share.
+var (
+	listeners    sync.Map
+	metricsPaths sync.Map
+)
+
+// HandleMetrics serves the metrics at the path of the default serve mux, unless they are
+// already served there.
+func HandleMetrics(path string) {
+	if _, loaded := metricsPaths.LoadOrStore(path, true); !loaded {
+		http.Handle(path, promhttp.Handler())
+	}
+}
+
 // WriteData write analyzed data to prometheus persistent back-end storage.
 func (p *PrometheusPump) WriteData(ctx context.Context, data []interface{}) error {
 	log.Debugf("Writing %d records", len(data))
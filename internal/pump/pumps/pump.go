@@ -25,6 +25,13 @@ type Pump interface {
 	GetOmitDetailedRecording() bool
 }
 
+// Eraser is implemented by the pumps which can erase the records they wrote, e.g. those of the
+// purged users.
+type Eraser interface {
+	// Erase erases the records of the users and returns their number.
+	Erase(ctx context.Context, usernames []string) (int, error)
+}
+
 // GetPumpByName returns the pump instance by given name.
 func GetPumpByName(name string) (Pump, error) {
 	if pump, ok := availablePumps[name]; ok && pump != nil {
@@ -7,10 +7,13 @@ package pump
 import (
 	genericapiserver "github.com/marmotedu/iam/internal/pkg/server"
 	"github.com/marmotedu/iam/internal/pump/config"
+	"github.com/marmotedu/iam/internal/pump/pumps"
 )
 
 // Run runs the specified pump server. This should never exit.
 func Run(cfg *config.Config, stopCh <-chan struct{}) error {
+	// the metrics of the pipelines are served along with the health check
+	pumps.HandleMetrics("/metrics")
 	go genericapiserver.ServeHealthCheck(cfg.HealthCheckPath, cfg.HealthCheckAddress)
 
 	server, err := createPumpServer(cfg)
@@ -7,6 +7,7 @@ package pump
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/go-redsync/redsync/v4/redis/goredis/v8"
 	"github.com/vmihailenco/msgpack/v5"
 
+	"github.com/marmotedu/iam/internal/pkg/instruction"
 	"github.com/marmotedu/iam/internal/pump/analytics"
 	"github.com/marmotedu/iam/internal/pump/config"
 	"github.com/marmotedu/iam/internal/pump/options"
@@ -24,14 +26,14 @@ import (
 	"github.com/marmotedu/iam/pkg/log"
 )
 
-var pmps []pumps.Pump
-
 type pumpServer struct {
 	secInterval    int
 	omitDetails    bool
 	mutex          *redsync.Mutex
 	analyticsStore storage.AnalyticsStorage
 	pumps          map[string]options.PumpConfig
+	pipelineConfig map[string]options.PipelineConfig
+	pipelines      []*pipeline
 }
 
 // preparedGenericAPIServer is a private wrapper that enforces a call of PrepareRun() before Run can be invoked.
@@ -55,6 +57,7 @@ func createPumpServer(cfg *config.Config) (*pumpServer, error) {
 		mutex:          rs.NewMutex("iam-pump", redsync.WithExpiry(10*time.Minute)),
 		analyticsStore: &redis.RedisClusterStorageManager{},
 		pumps:          cfg.Pumps,
+		pipelineConfig: cfg.Pipelines,
 	}
 
 	if err := server.analyticsStore.Init(cfg.RedisOptions); err != nil {
@@ -101,6 +104,9 @@ func (s *pumpServer) pump() {
 		}
 	}()
 
+	// the records of the purged users are erased before the queued ones are written
+	erased := s.executeInstructions()
+
 	analyticsValues := s.analyticsStore.GetAndDeleteSet(storage.AnalyticsKeyName)
 	if len(analyticsValues) == 0 {
 		return
@@ -116,6 +122,9 @@ func (s *pumpServer) pump() {
 		if err != nil {
 			log.Errorf("Couldn't unmarshal analytics data: %s", err.Error())
 		} else {
+			if erased[decoded.Username] {
+				continue
+			}
 			if s.omitDetails {
 				decoded.Policies = ""
 				decoded.Deciders = ""
@@ -124,51 +133,52 @@ func (s *pumpServer) pump() {
 		}
 	}
 
-	// Send to pumps
-	writeToPumps(keys, s.secInterval)
+	// Send to the pumps of all the pipelines
+	processPipelines(s.pipelines, keys, s.secInterval)
 }
 
-func (s *pumpServer) initialize() {
-	pmps = make([]pumps.Pump, len(s.pumps))
-	i := 0
-	for key, pmp := range s.pumps {
-		pumpTypeName := pmp.Type
-		if pumpTypeName == "" {
-			pumpTypeName = key
+// executeInstructions executes the instructions sent by iam-apiserver, and returns the names of
+// the users whose records are erased, so that their queued records are dropped as well.
+func (s *pumpServer) executeInstructions() map[string]bool {
+	erased := map[string]bool{}
+	for _, v := range s.analyticsStore.GetAndDeleteSet(instruction.KeyName) {
+		data, _ := v.(string)
+		ins, err := instruction.Parse(data)
+		if err != nil {
+			log.Errorf("Couldn't unmarshal instruction: %s", err.Error())
+
+			continue
 		}
 
-		pmpType, err := pumps.GetPumpByName(pumpTypeName)
-		if err != nil {
-			log.Errorf("Pump load error (skipping): %s", err.Error())
-		} else {
-			pmpIns := pmpType.New()
-			initErr := pmpIns.Init(pmp.Meta)
-			if initErr != nil {
-				log.Errorf("Pump init error (skipping): %s", initErr.Error())
-			} else {
-				log.Infof("Init Pump: %s", pmpIns.GetName())
-				pmpIns.SetFilters(pmp.Filters)
-				pmpIns.SetTimeout(pmp.Timeout)
-				pmpIns.SetOmitDetailedRecording(pmp.OmitDetailedRecording)
-				pmps[i] = pmpIns
-			}
+		if ins.Kind != instruction.KindEraseUser {
+			log.Warnf("Unknown instruction kind %s, skipping", ins.Kind)
+
+			continue
 		}
-		i++
+
+		log.Infof("Erase the records of a purged user, requested by operation %s", ins.Operation)
+		erased[ins.Username] = true
 	}
-}
 
-func writeToPumps(keys []interface{}, purgeDelay int) {
-	// Send to pumps
-	if pmps != nil {
-		var wg sync.WaitGroup
-		wg.Add(len(pmps))
-		for _, pmp := range pmps {
-			go execPumpWriting(&wg, pmp, &keys, purgeDelay)
-		}
-		wg.Wait()
-	} else {
-		log.Warn("No pumps defined!")
+	if len(erased) == 0 {
+		return erased
+	}
+
+	usernames := make([]string, 0, len(erased))
+	for username := range erased {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	for _, p := range s.pipelines {
+		p.erase(usernames)
 	}
+
+	return erased
+}
+
+func (s *pumpServer) initialize() {
+	s.pipelines = newPipelines(s.pumps, s.pipelineConfig)
 }
 
 func filterData(pump pumps.Pump, keys []interface{}) []interface{} {
@@ -176,10 +186,11 @@ func filterData(pump pumps.Pump, keys []interface{}) []interface{} {
 	if !filters.HasFilter() && !pump.GetOmitDetailedRecording() {
 		return keys
 	}
-	filteredKeys := keys[:] // nolint: gocritic
+	// the pumps share the keys, which are filtered into a copy
+	filteredKeys := make([]interface{}, len(keys))
 	newLenght := 0
 
-	for _, key := range filteredKeys {
+	for _, key := range keys {
 		decoded, _ := key.(analytics.AnalyticsRecord)
 		if pump.GetOmitDetailedRecording() {
 			decoded.Policies = ""
@@ -196,7 +207,7 @@ func filterData(pump pumps.Pump, keys []interface{}) []interface{} {
 	return filteredKeys
 }
 
-func execPumpWriting(wg *sync.WaitGroup, pmp pumps.Pump, keys *[]interface{}, purgeDelay int) {
+func execPumpWriting(wg *sync.WaitGroup, pipelineName string, pmp namedPump, keys []interface{}, purgeDelay int) {
 	timer := time.AfterFunc(time.Duration(purgeDelay)*time.Second, func() {
 		if pmp.GetTimeout() == 0 {
 			log.Warnf(
@@ -224,24 +235,31 @@ func execPumpWriting(wg *sync.WaitGroup, pmp pumps.Pump, keys *[]interface{}, pu
 
 	defer cancel()
 
-	go func(ch chan error, ctx context.Context, pmp pumps.Pump, keys *[]interface{}) {
-		filteredKeys := filterData(pmp, *keys)
+	start := time.Now()
+	go func(ch chan error, ctx context.Context, pmp pumps.Pump, keys []interface{}) {
+		filteredKeys := filterData(pmp, keys)
 
 		ch <- pmp.WriteData(ctx, filteredKeys)
-	}(ch, ctx, pmp, keys)
+	}(ch, ctx, pmp.Pump, keys)
 
+	result := "success"
 	select {
 	case err := <-ch:
 		if err != nil {
-			log.Warnf("Error Writing to: %s - Error: %s", pmp.GetName(), err.Error())
+			result = "failure"
+			log.Warnf("Error Writing to: %s of pipeline %s - Error: %s", pmp.GetName(), pipelineName, err.Error())
 		}
 	case <-ctx.Done():
+		result = "timeout"
 		//nolint: errorlint
 		switch ctx.Err() {
 		case context.Canceled:
-			log.Warnf("The writing to %s have got canceled.", pmp.GetName())
+			log.Warnf("The writing to %s of pipeline %s have got canceled.", pmp.GetName(), pipelineName)
 		case context.DeadlineExceeded:
-			log.Warnf("Timeout Writing to: %s", pmp.GetName())
+			log.Warnf("Timeout Writing to: %s of pipeline %s", pmp.GetName(), pipelineName)
 		}
 	}
+
+	pipelineWrites.WithLabelValues(pipelineName, pmp.name, result).Inc()
+	pipelineWriteDuration.WithLabelValues(pipelineName, pmp.name).Observe(time.Since(start).Seconds())
 }
@@ -6,9 +6,15 @@
 package options
 
 import (
+	"fmt"
+	"net/url"
+	"time"
+
 	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
 	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/spf13/pflag"
 
+	"github.com/marmotedu/iam/internal/pkg/notifier"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
 	"github.com/marmotedu/iam/pkg/log"
 )
@@ -23,20 +29,55 @@ type TaskOptions struct {
 	MaxInactiveDays int `json:"max-inactive-days" mapstructure:"max-inactive-days"`
 }
 
+// LDAPOptions defines options for ldap watcher, which mirrors the ldap groups into iam groups.
+type LDAPOptions struct {
+	URL                string        `json:"url"                  mapstructure:"url"`
+	BindDN             string        `json:"bind-dn"              mapstructure:"bind-dn"`
+	BindPassword       string        `json:"-"                    mapstructure:"bind-password"`
+	InsecureSkipVerify bool          `json:"insecure-skip-verify" mapstructure:"insecure-skip-verify"`
+	Timeout            time.Duration `json:"timeout"              mapstructure:"timeout"`
+	GroupBaseDN        string        `json:"group-base-dn"        mapstructure:"group-base-dn"`
+	GroupFilter        string        `json:"group-filter"         mapstructure:"group-filter"`
+	GroupNameAttribute string        `json:"group-name-attribute" mapstructure:"group-name-attribute"`
+	MemberAttribute    string        `json:"member-attribute"     mapstructure:"member-attribute"`
+	UserNameAttribute  string        `json:"user-name-attribute"  mapstructure:"user-name-attribute"`
+	GroupPrefix        string        `json:"group-prefix"         mapstructure:"group-prefix"`
+	Owner              string        `json:"owner"                mapstructure:"owner"`
+	Prune              bool          `json:"prune"                mapstructure:"prune"`
+	Interval           time.Duration `json:"interval"             mapstructure:"interval"`
+}
+
+// PasswordExpiryOptions defines options for password expiry watcher, which warns the users
+// before their password expires.
+type PasswordExpiryOptions struct {
+	WarnDays int `json:"warn-days" mapstructure:"warn-days"`
+}
+
+// SecretExpiryOptions defines options for secret expiry watcher, which warns the users before
+// their secrets expire.
+type SecretExpiryOptions struct {
+	WarnDays int `json:"warn-days" mapstructure:"warn-days"`
+}
+
 // WatcherOptions defines options for watchers.
 type WatcherOptions struct {
-	Clean CleanOptions `json:"clean" mapstructure:"clean"`
-	Task  TaskOptions  `json:"task"  mapstructure:"task"`
+	Clean          CleanOptions          `json:"clean"           mapstructure:"clean"`
+	Task           TaskOptions           `json:"task"            mapstructure:"task"`
+	LDAP           LDAPOptions           `json:"ldap"            mapstructure:"ldap"`
+	PasswordExpiry PasswordExpiryOptions `json:"password-expiry" mapstructure:"password-expiry"`
+	SecretExpiry   SecretExpiryOptions   `json:"secret-expiry"   mapstructure:"secret-expiry"`
 }
 
 // Options runs a pumpserver.
 type Options struct {
-	HealthCheckPath    string                       `json:"health-check-path"    mapstructure:"health-check-path"`
-	HealthCheckAddress string                       `json:"health-check-address" mapstructure:"health-check-address"`
-	MySQLOptions       *genericoptions.MySQLOptions `json:"mysql"                mapstructure:"mysql"`
-	RedisOptions       *genericoptions.RedisOptions `json:"redis"                mapstructure:"redis"`
-	WatcherOptions     *WatcherOptions              `json:"watcher"              mapstructure:"watcher"`
-	Log                *log.Options                 `json:"log"                  mapstructure:"log"`
+	HealthCheckPath     string                         `json:"health-check-path"    mapstructure:"health-check-path"`
+	HealthCheckAddress  string                         `json:"health-check-address" mapstructure:"health-check-address"`
+	MySQLOptions        *genericoptions.MySQLOptions   `json:"mysql"                mapstructure:"mysql"`
+	RedisOptions        *genericoptions.RedisOptions   `json:"redis"                mapstructure:"redis"`
+	WatcherOptions      *WatcherOptions                `json:"watcher"              mapstructure:"watcher"`
+	NotificationOptions *notifier.NotificationOptions  `json:"notification"         mapstructure:"notification"`
+	Log                 *log.Options                   `json:"log"                  mapstructure:"log"`
+	RuntimeOptions      *genericoptions.RuntimeOptions `json:"runtime"             mapstructure:"runtime"`
 }
 
 // NewOptions creates a new Options object with default parameters.
@@ -53,8 +94,28 @@ func NewOptions() *Options {
 			Task: TaskOptions{
 				MaxInactiveDays: 0, // not expire by default
 			},
+			LDAP: LDAPOptions{
+				URL:                "", // not synchronize by default
+				Timeout:            10 * time.Second,
+				GroupFilter:        "(objectClass=groupOfNames)",
+				GroupNameAttribute: "cn",
+				MemberAttribute:    "member",
+				UserNameAttribute:  "uid",
+				GroupPrefix:        "ldap-",
+				Owner:              "admin",
+				Prune:              false,
+				Interval:           time.Hour,
+			},
+			PasswordExpiry: PasswordExpiryOptions{
+				WarnDays: 7,
+			},
+			SecretExpiry: SecretExpiryOptions{
+				WarnDays: 7,
+			},
 		},
-		Log: log.NewOptions(),
+		NotificationOptions: notifier.NewNotificationOptions(),
+		Log:                 log.NewOptions(),
+		RuntimeOptions:      genericoptions.NewRuntimeOptions(),
 	}
 
 	return &s
@@ -64,7 +125,9 @@ func NewOptions() *Options {
 func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
+	o.NotificationOptions.AddFlags(fss.FlagSet("notification"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
+	o.RuntimeOptions.AddFlags(fss.FlagSet("runtime"))
 
 	// Note: the weird ""+ in below lines seems to be the only way to get gofmt to
 	// arrange these text blocks sensibly. Grrr.
@@ -87,9 +150,110 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 		"Maximum user inactivity time. Otherwise the account will be disabled.",
 	)
 
+	o.addLDAPFlags(fs)
+	o.addExpiryFlags(fs)
+
 	return fss
 }
 
+func (o *Options) addExpiryFlags(fs *pflag.FlagSet) {
+	p := &o.WatcherOptions.PasswordExpiry
+	fs.IntVar(&p.WarnDays, "watcher.password-expiry.warn-days", p.WarnDays, ""+
+		"Days before the password of a user expires to warn the user every day. No warning is sent if it is 0.")
+
+	s := &o.WatcherOptions.SecretExpiry
+	fs.IntVar(&s.WarnDays, "watcher.secret-expiry.warn-days", s.WarnDays, ""+
+		"Days before a secret expires to warn its owner every day. No warning is sent if it is 0.")
+}
+
+func (o *Options) addLDAPFlags(fs *pflag.FlagSet) {
+	l := &o.WatcherOptions.LDAP
+
+	fs.StringVar(&l.URL, "watcher.ldap.url", l.URL, ""+
+		"URL of the ldap server, e.g. ldaps://ldap.example.com. The ldap groups are not synchronized if it is empty.")
+	fs.StringVar(&l.BindDN, "watcher.ldap.bind-dn", l.BindDN, ""+
+		"DN used to bind to the ldap server.")
+	fs.StringVar(&l.BindPassword, "watcher.ldap.bind-password", l.BindPassword, ""+
+		"Password used to bind to the ldap server.")
+	fs.BoolVar(&l.InsecureSkipVerify, "watcher.ldap.insecure-skip-verify", l.InsecureSkipVerify, ""+
+		"Skip the verification of the ldap server certificate.")
+	fs.DurationVar(&l.Timeout, "watcher.ldap.timeout", l.Timeout, ""+
+		"Timeout of the ldap operations.")
+	fs.StringVar(&l.GroupBaseDN, "watcher.ldap.group-base-dn", l.GroupBaseDN, ""+
+		"Base DN of the ldap groups search.")
+	fs.StringVar(&l.GroupFilter, "watcher.ldap.group-filter", l.GroupFilter, ""+
+		"Filter of the ldap groups search.")
+	fs.StringVar(&l.GroupNameAttribute, "watcher.ldap.group-name-attribute", l.GroupNameAttribute, ""+
+		"Attribute of the ldap groups used as the iam group name.")
+	fs.StringVar(&l.MemberAttribute, "watcher.ldap.member-attribute", l.MemberAttribute, ""+
+		"Attribute of the ldap groups which lists the member DNs or user names.")
+	fs.StringVar(&l.UserNameAttribute, "watcher.ldap.user-name-attribute", l.UserNameAttribute, ""+
+		"Attribute of the ldap users used as the iam user name.")
+	fs.StringVar(&l.GroupPrefix, "watcher.ldap.group-prefix", l.GroupPrefix, ""+
+		"Prefix of the iam groups mirrored from ldap.")
+	fs.StringVar(&l.Owner, "watcher.ldap.owner", l.Owner, ""+
+		"Owner of the iam groups mirrored from ldap.")
+	fs.BoolVar(&l.Prune, "watcher.ldap.prune", l.Prune, ""+
+		"Delete the mirrored groups and members which no longer exist in ldap. "+
+		"Otherwise they are only added.")
+	fs.DurationVar(&l.Interval, "watcher.ldap.interval", l.Interval, ""+
+		"Interval of the ldap groups synchronization.")
+}
+
+// Validate checks the ldap options when the synchronization is enabled.
+func (l *LDAPOptions) Validate() []error {
+	var errs []error
+
+	if l.URL == "" {
+		return errs
+	}
+
+	if u, err := url.Parse(l.URL); err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("--watcher.ldap.url must be a ldap:// or ldaps:// url"))
+	}
+
+	if l.GroupBaseDN == "" {
+		errs = append(errs, fmt.Errorf("--watcher.ldap.group-base-dn must be specified when --watcher.ldap.url is set"))
+	}
+
+	if l.GroupNameAttribute == "" || l.MemberAttribute == "" || l.UserNameAttribute == "" {
+		errs = append(errs, fmt.Errorf("--watcher.ldap.group-name-attribute, member-attribute and "+
+			"user-name-attribute can not be empty"))
+	}
+
+	if l.Owner == "" {
+		errs = append(errs, fmt.Errorf("--watcher.ldap.owner must be specified when --watcher.ldap.url is set"))
+	}
+
+	if l.Interval < time.Minute {
+		errs = append(errs, fmt.Errorf("--watcher.ldap.interval can not be less than 1m"))
+	}
+
+	return errs
+}
+
+// Validate checks the password expiry options.
+func (p *PasswordExpiryOptions) Validate() []error {
+	var errs []error
+
+	if p.WarnDays < 0 {
+		errs = append(errs, fmt.Errorf("--watcher.password-expiry.warn-days can not be negative"))
+	}
+
+	return errs
+}
+
+// Validate checks the secret expiry options.
+func (s *SecretExpiryOptions) Validate() []error {
+	var errs []error
+
+	if s.WarnDays < 0 {
+		errs = append(errs, fmt.Errorf("--watcher.secret-expiry.warn-days can not be negative"))
+	}
+
+	return errs
+}
+
 func (o *Options) String() string {
 	data, _ := json.Marshal(o)
 
@@ -11,6 +11,11 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.RedisOptions.Validate()...)
 	errs = append(errs, o.MySQLOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
+	errs = append(errs, o.RuntimeOptions.Validate()...)
+	errs = append(errs, o.WatcherOptions.LDAP.Validate()...)
+	errs = append(errs, o.WatcherOptions.PasswordExpiry.Validate()...)
+	errs = append(errs, o.WatcherOptions.SecretExpiry.Validate()...)
+	errs = append(errs, o.NotificationOptions.Validate()...)
 
 	return errs
 }
@@ -5,23 +5,27 @@
 package watcher
 
 import (
+	"context"
 	"time"
 
 	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
 	genericoptions "github.com/marmotedu/iam/internal/pkg/options"
 	"github.com/marmotedu/iam/internal/watcher/config"
 	"github.com/marmotedu/iam/internal/watcher/options"
 	"github.com/marmotedu/iam/pkg/log"
 	"github.com/marmotedu/iam/pkg/shutdown"
 	"github.com/marmotedu/iam/pkg/shutdown/shutdownmanagers/posixsignal"
+	"github.com/marmotedu/iam/pkg/storage"
 )
 
 type watcherServer struct {
-	gs             *shutdown.GracefulShutdown
-	cron           *watchJob
-	redisOptions   *genericoptions.RedisOptions
-	mysqlOptions   *genericoptions.MySQLOptions
-	watcherOptions *options.WatcherOptions
+	gs                  *shutdown.GracefulShutdown
+	cron                *watchJob
+	redisOptions        *genericoptions.RedisOptions
+	mysqlOptions        *genericoptions.MySQLOptions
+	watcherOptions      *options.WatcherOptions
+	notificationOptions *notifier.NotificationOptions
 }
 
 // preparedGenericAPIServer is a private wrapper that enforces a call of PrepareRun() before Run can be invoked.
@@ -34,10 +38,11 @@ func createWatcherServer(cfg *config.Config) *watcherServer {
 	gs.AddShutdownManager(posixsignal.NewPosixSignalManager())
 
 	server := &watcherServer{
-		gs:             gs,
-		redisOptions:   cfg.RedisOptions,
-		mysqlOptions:   cfg.MySQLOptions,
-		watcherOptions: cfg.WatcherOptions,
+		gs:                  gs,
+		redisOptions:        cfg.RedisOptions,
+		mysqlOptions:        cfg.MySQLOptions,
+		watcherOptions:      cfg.WatcherOptions,
+		notificationOptions: cfg.NotificationOptions,
 	}
 
 	return server
@@ -54,11 +59,46 @@ func (s *watcherServer) PrepareRun() preparedWatcherServer {
 		return mysqlStore.Close()
 	}))
 
+	// keep redis connected for the watchers reading the data of the other components
+	ctx, cancel := context.WithCancel(context.Background())
+	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		cancel()
+
+		return nil
+	}))
+	go storage.ConnectToRedis(ctx, s.buildStorageConfig())
+
+	userNotifier, err := s.notificationOptions.NewNotifier()
+	if err != nil {
+		panic(err)
+	}
+	notifier.SetNotifier(userNotifier)
+
 	s.cron = newWatchJob(s.redisOptions, s.watcherOptions).addWatchers()
 
 	return preparedWatcherServer{s}
 }
 
+func (s *watcherServer) buildStorageConfig() *storage.Config {
+	return &storage.Config{
+		Host:                  s.redisOptions.Host,
+		Port:                  s.redisOptions.Port,
+		Addrs:                 s.redisOptions.Addrs,
+		MasterName:            s.redisOptions.MasterName,
+		Username:              s.redisOptions.Username,
+		Password:              s.redisOptions.Password,
+		Database:              s.redisOptions.Database,
+		MaxIdle:               s.redisOptions.MaxIdle,
+		MaxActive:             s.redisOptions.MaxActive,
+		Timeout:               s.redisOptions.Timeout,
+		EnableCluster:         s.redisOptions.EnableCluster,
+		UseSSL:                s.redisOptions.UseSSL,
+		SSLInsecureSkipVerify: s.redisOptions.SSLInsecureSkipVerify,
+		ClientCacheSize:       s.redisOptions.ClientCacheSize,
+		ClientCacheTTL:        s.redisOptions.ClientCacheTTL,
+	}
+}
+
 func (s preparedWatcherServer) Run() error {
 	stopCh := make(chan struct{})
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
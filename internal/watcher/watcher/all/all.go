@@ -7,5 +7,9 @@ package all
 // nolint: golint
 import (
 	_ "github.com/marmotedu/iam/internal/watcher/watcher/clean"
+	_ "github.com/marmotedu/iam/internal/watcher/watcher/ldapsync"
+	_ "github.com/marmotedu/iam/internal/watcher/watcher/passwordexpiry"
+	_ "github.com/marmotedu/iam/internal/watcher/watcher/secretexpiry"
+	_ "github.com/marmotedu/iam/internal/watcher/watcher/secretusage"
 	_ "github.com/marmotedu/iam/internal/watcher/watcher/task"
 )
@@ -0,0 +1,206 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldapsync
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation"
+
+	"github.com/marmotedu/iam/internal/pkg/ldap"
+	"github.com/marmotedu/iam/internal/watcher/options"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// extendDN is the group extend field which marks the groups mirrored from ldap, only these
+// groups are updated or pruned by the watcher.
+const extendDN = "ldap.dn"
+
+// mirroredGroup is an ldap group mapped onto an iam group.
+type mirroredGroup struct {
+	DN      string
+	Name    string
+	Members []string
+}
+
+type mapper struct {
+	opts options.LDAPOptions
+	// lookup returns the user name attribute of the dn.
+	lookup func(dn string) (string, error)
+	cache  map[string]string
+}
+
+// groups maps the ldap group entries onto iam groups keyed by name, the groups and the
+// members which are not valid iam names are skipped.
+func (m *mapper) groups(ctx context.Context, entries []*ldap.Entry) map[string]*mirroredGroup {
+	groups := make(map[string]*mirroredGroup, len(entries))
+
+	for _, entry := range entries {
+		name := groupName(entry.Value(m.opts.GroupNameAttribute))
+		if name != "" {
+			name = m.opts.GroupPrefix + name
+		}
+
+		if msgs := validation.IsQualifiedName(name); len(msgs) != 0 {
+			log.L(ctx).Warnf("skip ldap group %s: invalid name %s", entry.DN, name)
+
+			continue
+		}
+
+		if g, ok := groups[name]; ok {
+			log.L(ctx).Warnf("skip ldap group %s: name %s is used by %s", entry.DN, name, g.DN)
+
+			continue
+		}
+
+		seen := map[string]bool{}
+		members := []string{}
+		for _, value := range entry.Values(m.opts.MemberAttribute) {
+			username := m.username(value)
+			if username == "" || seen[username] || len(validation.IsQualifiedName(username)) != 0 {
+				continue
+			}
+			seen[username] = true
+			members = append(members, v1.SubjectKindUser+":"+username)
+		}
+		sort.Strings(members)
+
+		groups[name] = &mirroredGroup{DN: entry.DN, Name: name, Members: members}
+	}
+
+	return groups
+}
+
+// username returns the iam user name of a member value, which is either a user dn or a user
+// name, e.g. the memberUid of posixGroup.
+func (m *mapper) username(member string) string {
+	attr, value, ok := ldap.FirstRDN(member)
+	if !ok {
+		return member
+	}
+
+	if strings.EqualFold(attr, m.opts.UserNameAttribute) {
+		return value
+	}
+
+	if m.cache == nil {
+		m.cache = map[string]string{}
+	}
+
+	if username, ok := m.cache[member]; ok {
+		return username
+	}
+
+	username, _ := m.lookup(member)
+	m.cache[member] = username
+
+	return username
+}
+
+// groupName converts the ldap group name to an iam group name, e.g. "Domain Admins" to
+// domain-admins.
+func groupName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, name)
+
+	return strings.Trim(name, "-_.")
+}
+
+// reconcile returns the iam groups to create, update and delete so that the mirrored groups
+// match the ldap groups. The groups not created by the watcher are never changed. Without
+// prune, the groups and members removed from ldap are kept.
+func reconcile(
+	desired map[string]*mirroredGroup,
+	existing []*v1.Group,
+	owner string,
+	prune bool,
+) (creates, updates, deletes []*v1.Group) {
+	current := make(map[string]*v1.Group, len(existing))
+	for _, group := range existing {
+		current[group.Name] = group
+	}
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		want := desired[name]
+
+		group, ok := current[name]
+		if !ok {
+			creates = append(creates, &v1.Group{
+				ObjectMeta:  metav1.ObjectMeta{Name: name, Extend: metav1.Extend{extendDN: want.DN}},
+				Username:    owner,
+				Kind:        v1.SubjectKindGroup,
+				Members:     want.Members,
+				Description: "Mirrored from ldap group " + want.DN,
+			})
+
+			continue
+		}
+
+		if _, mirrored := group.Extend[extendDN]; !mirrored {
+			continue
+		}
+
+		members := want.Members
+		if !prune {
+			members = union(group.Members, want.Members)
+		}
+
+		if !reflect.DeepEqual(normalize(group.Members), members) || group.Extend[extendDN] != want.DN {
+			group.Members = members
+			group.Extend[extendDN] = want.DN
+			updates = append(updates, group)
+		}
+	}
+
+	if !prune {
+		return creates, updates, nil
+	}
+
+	for _, group := range existing {
+		if _, mirrored := group.Extend[extendDN]; mirrored && desired[group.Name] == nil {
+			deletes = append(deletes, group)
+		}
+	}
+
+	return creates, updates, deletes
+}
+
+func union(a, b []string) []string {
+	return normalize(append(append([]string{}, a...), b...))
+}
+
+// normalize returns the sorted members without duplicates.
+func normalize(members []string) []string {
+	seen := make(map[string]bool, len(members))
+	ret := []string{}
+	for _, member := range members {
+		if !seen[member] {
+			seen[member] = true
+			ret = append(ret, member)
+		}
+	}
+	sort.Strings(ret)
+
+	return ret
+}
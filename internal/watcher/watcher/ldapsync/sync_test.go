@@ -0,0 +1,138 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldapsync
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/ldap"
+	"github.com/marmotedu/iam/internal/watcher/options"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_mapper_groups(t *testing.T) {
+	m := &mapper{
+		opts: options.NewOptions().WatcherOptions.LDAP,
+		lookup: func(dn string) (string, error) {
+			if dn == "cn=Peter Lee,ou=people,dc=example,dc=com" {
+				return "peter", nil
+			}
+
+			return "", nil
+		},
+	}
+
+	entries := []*ldap.Entry{
+		{DN: "cn=Domain Admins,ou=groups,dc=example,dc=com", Attributes: map[string][]string{
+			"cn": {"Domain Admins"},
+			"member": {
+				"uid=colin,ou=people,dc=example,dc=com",
+				"cn=Peter Lee,ou=people,dc=example,dc=com",
+				"cn=unknown,ou=people,dc=example,dc=com",
+				"uid=colin,ou=people,dc=example,dc=com",
+			},
+		}},
+		{DN: "cn=dev,ou=groups,dc=example,dc=com", Attributes: map[string][]string{
+			"cn":     {"dev"},
+			"member": {"tom"},
+		}},
+		{DN: "cn=invalid,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"cn": {"!!!"}}},
+	}
+
+	want := map[string]*mirroredGroup{
+		"ldap-domain-admins": {
+			DN:      "cn=Domain Admins,ou=groups,dc=example,dc=com",
+			Name:    "ldap-domain-admins",
+			Members: []string{"users:colin", "users:peter"},
+		},
+		"ldap-dev": {
+			DN:      "cn=dev,ou=groups,dc=example,dc=com",
+			Name:    "ldap-dev",
+			Members: []string{"users:tom"},
+		},
+	}
+
+	if got := m.groups(context.Background(), entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("groups() = %v, want %v", got, want)
+	}
+}
+
+func Test_reconcile(t *testing.T) {
+	mirrored := func(name string, members ...string) *v1.Group {
+		return &v1.Group{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Extend: metav1.Extend{extendDN: "cn=" + name}},
+			Username:   "admin",
+			Members:    members,
+		}
+	}
+
+	desired := map[string]*mirroredGroup{
+		"ldap-admins": {DN: "cn=ldap-admins", Name: "ldap-admins", Members: []string{"users:colin"}},
+		"ldap-dev":    {DN: "cn=ldap-dev", Name: "ldap-dev", Members: []string{"users:tom"}},
+		"manual":      {DN: "cn=manual", Name: "manual", Members: []string{"users:tom"}},
+	}
+
+	tests := []struct {
+		name        string
+		prune       bool
+		wantCreates []string
+		wantUpdates map[string][]string
+		wantDeletes []string
+	}{
+		{
+			name:        "without prune",
+			wantCreates: []string{"ldap-dev"},
+			wantUpdates: map[string][]string{"ldap-admins": {"users:colin", "users:peter"}},
+		},
+		{
+			name:        "with prune",
+			prune:       true,
+			wantCreates: []string{"ldap-dev"},
+			wantUpdates: map[string][]string{"ldap-admins": {"users:colin"}},
+			wantDeletes: []string{"ldap-old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := []*v1.Group{
+				mirrored("ldap-admins", "users:peter"),
+				mirrored("ldap-old", "users:colin"),
+				// groups not created by the watcher are never changed
+				{ObjectMeta: metav1.ObjectMeta{Name: "manual"}, Username: "admin"},
+			}
+
+			creates, updates, deletes := reconcile(desired, existing, "admin", tt.prune)
+
+			var gotCreates, gotDeletes []string
+			for _, g := range creates {
+				gotCreates = append(gotCreates, g.Name)
+				if g.Username != "admin" || g.Extend[extendDN] != desired[g.Name].DN {
+					t.Errorf("reconcile() creates %+v", g)
+				}
+			}
+			for _, g := range deletes {
+				gotDeletes = append(gotDeletes, g.Name)
+			}
+			gotUpdates := map[string][]string{}
+			for _, g := range updates {
+				gotUpdates[g.Name] = g.Members
+			}
+
+			if !reflect.DeepEqual(gotCreates, tt.wantCreates) {
+				t.Errorf("reconcile() creates = %v, want %v", gotCreates, tt.wantCreates)
+			}
+			if !reflect.DeepEqual(gotUpdates, tt.wantUpdates) {
+				t.Errorf("reconcile() updates = %v, want %v", gotUpdates, tt.wantUpdates)
+			}
+			if !reflect.DeepEqual(gotDeletes, tt.wantDeletes) {
+				t.Errorf("reconcile() deletes = %v, want %v", gotDeletes, tt.wantDeletes)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ldapsync
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/go-redsync/redsync/v4"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/ldap"
+	"github.com/marmotedu/iam/internal/watcher/options"
+	"github.com/marmotedu/iam/internal/watcher/watcher"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+type ldapWatcher struct {
+	ctx   context.Context
+	mutex *redsync.Mutex
+	opts  options.LDAPOptions
+}
+
+// Run runs the watcher job.
+func (lw *ldapWatcher) Run() {
+	// if url is empty, means never synchronize
+	if lw.opts.URL == "" {
+		return
+	}
+
+	if err := lw.mutex.Lock(); err != nil {
+		log.L(lw.ctx).Info("ldapWatcher already run.")
+
+		return
+	}
+	defer func() {
+		if _, err := lw.mutex.Unlock(); err != nil {
+			log.L(lw.ctx).Errorf("could not release ldapWatcher lock. err: %v", err)
+
+			return
+		}
+	}()
+
+	desired, err := lw.fetchGroups()
+	if err != nil {
+		log.L(lw.ctx).Errorf("fetch ldap groups failed: %s", err.Error())
+
+		return
+	}
+
+	db, _ := mysql.GetMySQLFactoryOr(nil)
+
+	existing, err := db.Groups().List(lw.ctx, lw.opts.Owner, metav1.ListOptions{})
+	if err != nil {
+		log.L(lw.ctx).Errorf("list groups failed: %s", err.Error())
+
+		return
+	}
+
+	creates, updates, deletes := reconcile(desired, existing.Items, lw.opts.Owner, lw.opts.Prune)
+
+	for _, group := range creates {
+		if err := db.Groups().Create(lw.ctx, group, metav1.CreateOptions{}); err != nil {
+			log.L(lw.ctx).Errorf("create group %s failed: %s", group.Name, err.Error())
+		}
+	}
+
+	for _, group := range updates {
+		if err := db.Groups().Update(lw.ctx, group, metav1.UpdateOptions{}); err != nil {
+			log.L(lw.ctx).Errorf("update group %s failed: %s", group.Name, err.Error())
+		}
+	}
+
+	for _, group := range deletes {
+		if err := db.Groups().Delete(lw.ctx, group.Username, group.Name, metav1.DeleteOptions{}); err != nil {
+			log.L(lw.ctx).Errorf("delete group %s failed: %s", group.Name, err.Error())
+		}
+	}
+
+	log.L(lw.ctx).Infof("synchronized %d ldap groups: %d created, %d updated, %d deleted",
+		len(desired), len(creates), len(updates), len(deletes))
+}
+
+// fetchGroups searches the ldap groups and maps them onto iam groups.
+func (lw *ldapWatcher) fetchGroups() (map[string]*mirroredGroup, error) {
+	conn, err := ldap.Dial(lw.opts.URL, &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		//nolint: gosec
+		InsecureSkipVerify: lw.opts.InsecureSkipVerify,
+	}, lw.opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if lw.opts.BindDN != "" {
+		if err := conn.Bind(lw.opts.BindDN, lw.opts.BindPassword); err != nil {
+			return nil, fmt.Errorf("bind %s: %w", lw.opts.BindDN, err)
+		}
+	}
+
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     lw.opts.GroupBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     lw.opts.GroupFilter,
+		Attributes: []string{lw.opts.GroupNameAttribute, lw.opts.MemberAttribute},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mapper{opts: lw.opts, lookup: func(dn string) (string, error) {
+		users, err := conn.Search(&ldap.SearchRequest{
+			BaseDN:     dn,
+			Scope:      ldap.ScopeBaseObject,
+			Filter:     "(objectClass=*)",
+			Attributes: []string{lw.opts.UserNameAttribute},
+		})
+		if err != nil || len(users) == 0 {
+			return "", err
+		}
+
+		return users[0].Value(lw.opts.UserNameAttribute), nil
+	}}
+
+	return m.groups(lw.ctx, entries), nil
+}
+
+// Spec is parsed using the time zone of ldap Cron instance as the default.
+func (lw *ldapWatcher) Spec() string {
+	return "@every " + lw.opts.Interval.String()
+}
+
+// Init initializes the watcher for later execution.
+func (lw *ldapWatcher) Init(ctx context.Context, rs *redsync.Mutex, config interface{}) error {
+	cfg, ok := config.(*options.WatcherOptions)
+	if !ok {
+		return watcher.ErrConfigUnavailable
+	}
+
+	*lw = ldapWatcher{
+		ctx:   ctx,
+		mutex: rs,
+		opts:  cfg.LDAP,
+	}
+
+	return nil
+}
+
+func init() {
+	watcher.Register("ldap", &ldapWatcher{})
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package passwordexpiry
+
+import (
+	"sort"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+// Warning is sent to each user whose password expires soon.
+type Warning struct {
+	Recipient notifier.Recipient
+	Tenant    string
+	ExpiresAt time.Time
+}
+
+// warnings returns the warnings of the active users whose password expires within the given
+// duration from now, by the maximum password ages of the quotas of their tenant.
+func warnings(users []*v1.User, quotas []*apiv1.Quota, now time.Time, within time.Duration) []Warning {
+	maxAges := make(map[string]time.Duration, len(quotas))
+	for _, quota := range quotas {
+		maxAges[quota.Name] = time.Duration(quota.MaxPasswordAge) * 24 * time.Hour
+	}
+
+	ret := make([]Warning, 0)
+	for _, user := range users {
+		if userstate.Of(user) != userstate.Active {
+			continue
+		}
+
+		name := tenant.FromExtend(user.Extend)
+		expiresAt := passwordexpiry.ExpiresAt(user, maxAges[name])
+		if expiresAt.IsZero() || !expiresAt.After(now) || expiresAt.After(now.Add(within)) {
+			continue
+		}
+
+		ret = append(ret, Warning{Recipient: notifier.RecipientOf(user), Tenant: name, ExpiresAt: expiresAt})
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ExpiresAt.Before(ret[j].ExpiresAt) })
+
+	return ret
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package passwordexpiry
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/passwordexpiry"
+	"github.com/marmotedu/iam/internal/pkg/tenant"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+	apiv1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+)
+
+func Test_warnings(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	newUser := func(name, tenantName string, changedAt time.Time, state userstate.State) *v1.User {
+		user := &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Extend: metav1.Extend{tenant.ExtendKey: tenantName}},
+			Email:      name + "@foxmail.com",
+			Status:     int(state),
+		}
+		passwordexpiry.SetChangedAt(user, changedAt)
+
+		return user
+	}
+
+	users := []*v1.User{
+		newUser("later", "marmotedu", now.Add(-80*day), userstate.Active),
+		newUser("soon", "marmotedu", now.Add(-88*day), userstate.Active),
+		newUser("sooner", "marmotedu", now.Add(-89*day), userstate.Active),
+		newUser("expired", "marmotedu", now.Add(-91*day), userstate.Active),
+		newUser("suspended", "marmotedu", now.Add(-88*day), userstate.Suspended),
+		newUser("unlimited", "other", now.Add(-88*day), userstate.Active),
+	}
+	quotas := []*apiv1.Quota{
+		{ObjectMeta: metav1.ObjectMeta{Name: "marmotedu"}, MaxPasswordAge: 90},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+	}
+
+	got := warnings(users, quotas, now, 7*day)
+	if len(got) != 2 || got[0].Recipient.Username != "sooner" || got[1].Recipient.Username != "soon" {
+		t.Fatalf("warnings() = %+v, want sooner and soon", got)
+	}
+	if got[1].Recipient.Email != "soon@foxmail.com" || got[1].Tenant != "marmotedu" || !got[1].ExpiresAt.Equal(now.Add(2*day)) {
+		t.Errorf("warnings() = %+v", got[1])
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package passwordexpiry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/watcher/options"
+	"github.com/marmotedu/iam/internal/watcher/watcher"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+type passwordExpiryWatcher struct {
+	ctx   context.Context
+	mutex *redsync.Mutex
+	opts  options.PasswordExpiryOptions
+}
+
+// Run runs the watcher job.
+func (pw *passwordExpiryWatcher) Run() {
+	// if warnDays equal to 0, means never warn
+	if pw.opts.WarnDays == 0 {
+		return
+	}
+
+	if err := pw.mutex.Lock(); err != nil {
+		log.L(pw.ctx).Info("passwordExpiryWatcher already run.")
+
+		return
+	}
+	defer func() {
+		if _, err := pw.mutex.Unlock(); err != nil {
+			log.L(pw.ctx).Errorf("could not release passwordExpiryWatcher lock. err: %v", err)
+
+			return
+		}
+	}()
+
+	db, _ := mysql.GetMySQLFactoryOr(nil)
+
+	all := int64(-1)
+	quotas, err := db.Quotas().List(pw.ctx, metav1.ListOptions{Limit: &all})
+	if err != nil {
+		log.L(pw.ctx).Errorf("list quotas failed: %s", err.Error())
+
+		return
+	}
+
+	users, err := db.Users().List(pw.ctx, metav1.ListOptions{Limit: &all})
+	if err != nil {
+		log.L(pw.ctx).Errorf("list users failed: %s", err.Error())
+
+		return
+	}
+
+	within := time.Duration(pw.opts.WarnDays) * 24 * time.Hour
+	for _, warning := range warnings(users.Items, quotas.Items, time.Now(), within) {
+		log.L(pw.ctx).Infof("password of user %s expires at %s",
+			warning.Recipient.Username, warning.ExpiresAt.Format(time.RFC3339))
+
+		err := notifier.Send(pw.ctx, notifier.PasswordExpiry, warning.Recipient, map[string]interface{}{
+			"expiresAt": warning.ExpiresAt.Format(time.RFC3339),
+			"tenant":    warning.Tenant,
+		})
+		if err != nil {
+			log.L(pw.ctx).Errorf("send password expiry warning to user %s failed: %s",
+				warning.Recipient.Username, err.Error())
+		}
+	}
+}
+
+// Spec is parsed using the time zone of passwordexpiry Cron instance as the default.
+func (pw *passwordExpiryWatcher) Spec() string {
+	return "@every 24h"
+}
+
+// Init initializes the watcher for later execution.
+func (pw *passwordExpiryWatcher) Init(ctx context.Context, rs *redsync.Mutex, config interface{}) error {
+	cfg, ok := config.(*options.WatcherOptions)
+	if !ok {
+		return watcher.ErrConfigUnavailable
+	}
+
+	*pw = passwordExpiryWatcher{
+		ctx:   ctx,
+		mutex: rs,
+		opts:  cfg.PasswordExpiry,
+	}
+
+	return nil
+}
+
+func init() {
+	watcher.Register("passwordexpiry", &passwordExpiryWatcher{})
+}
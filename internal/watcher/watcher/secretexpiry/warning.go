@@ -0,0 +1,56 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretexpiry
+
+import (
+	"sort"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+)
+
+// Warning is sent to the owner of each secret expiring soon.
+type Warning struct {
+	Recipient  notifier.Recipient
+	SecretID   string
+	SecretName string
+	ExpiresAt  time.Time
+}
+
+// warnings returns the warnings of the secrets of the active users expiring within the given
+// duration from now. The secrets which never expire are skipped.
+func warnings(secrets []*v1.Secret, users []*v1.User, now time.Time, within time.Duration) []Warning {
+	owners := make(map[string]*v1.User, len(users))
+	for _, user := range users {
+		owners[user.Name] = user
+	}
+
+	ret := make([]Warning, 0)
+	for _, secret := range secrets {
+		owner, ok := owners[secret.Username]
+		if !ok || userstate.Of(owner) != userstate.Active || secret.Expires <= 0 {
+			continue
+		}
+
+		expiresAt := time.Unix(secret.Expires, 0)
+		if !expiresAt.After(now) || expiresAt.After(now.Add(within)) {
+			continue
+		}
+
+		ret = append(ret, Warning{
+			Recipient:  notifier.RecipientOf(owner),
+			SecretID:   secret.SecretID,
+			SecretName: secret.Name,
+			ExpiresAt:  expiresAt,
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ExpiresAt.Before(ret[j].ExpiresAt) })
+
+	return ret
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretexpiry
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/pkg/userstate"
+)
+
+func Test_warnings(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	newSecret := func(name, username string, expires int64) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Username:   username,
+			SecretID:   name + "-id",
+			Expires:    expires,
+		}
+	}
+
+	users := []*v1.User{
+		{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Email: "colin@foxmail.com", Status: int(userstate.Active)},
+		{ObjectMeta: metav1.ObjectMeta{Name: "peter"}, Status: int(userstate.Suspended)},
+	}
+	secrets := []*v1.Secret{
+		newSecret("later", "colin", now.Add(10*day).Unix()),
+		newSecret("soon", "colin", now.Add(3*day).Unix()),
+		newSecret("sooner", "colin", now.Add(day).Unix()),
+		newSecret("expired", "colin", now.Add(-day).Unix()),
+		newSecret("never", "colin", 0),
+		newSecret("suspended", "peter", now.Add(day).Unix()),
+		newSecret("orphan", "ken", now.Add(day).Unix()),
+	}
+
+	got := warnings(secrets, users, now, 7*day)
+	if len(got) != 2 || got[0].SecretName != "sooner" || got[1].SecretName != "soon" {
+		t.Fatalf("warnings() = %+v, want sooner and soon", got)
+	}
+	if got[1].Recipient.Email != "colin@foxmail.com" || got[1].SecretID != "soon-id" ||
+		!got[1].ExpiresAt.Equal(now.Add(3*day)) {
+		t.Errorf("warnings() = %+v", got[1])
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretexpiry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/watcher/options"
+	"github.com/marmotedu/iam/internal/watcher/watcher"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+type secretExpiryWatcher struct {
+	ctx   context.Context
+	mutex *redsync.Mutex
+	opts  options.SecretExpiryOptions
+}
+
+// Run runs the watcher job.
+func (sw *secretExpiryWatcher) Run() {
+	// if warnDays equal to 0, means never warn
+	if sw.opts.WarnDays == 0 {
+		return
+	}
+
+	if err := sw.mutex.Lock(); err != nil {
+		log.L(sw.ctx).Info("secretExpiryWatcher already run.")
+
+		return
+	}
+	defer func() {
+		if _, err := sw.mutex.Unlock(); err != nil {
+			log.L(sw.ctx).Errorf("could not release secretExpiryWatcher lock. err: %v", err)
+
+			return
+		}
+	}()
+
+	db, _ := mysql.GetMySQLFactoryOr(nil)
+
+	all := int64(-1)
+	secrets, err := db.Secrets().List(sw.ctx, "", metav1.ListOptions{Limit: &all})
+	if err != nil {
+		log.L(sw.ctx).Errorf("list secrets failed: %s", err.Error())
+
+		return
+	}
+
+	users, err := db.Users().List(sw.ctx, metav1.ListOptions{Limit: &all})
+	if err != nil {
+		log.L(sw.ctx).Errorf("list users failed: %s", err.Error())
+
+		return
+	}
+
+	within := time.Duration(sw.opts.WarnDays) * 24 * time.Hour
+	for _, warning := range warnings(secrets.Items, users.Items, time.Now(), within) {
+		log.L(sw.ctx).Infof("secret %s of user %s expires at %s",
+			warning.SecretID, warning.Recipient.Username, warning.ExpiresAt.Format(time.RFC3339))
+
+		err := notifier.Send(sw.ctx, notifier.SecretExpiry, warning.Recipient, map[string]interface{}{
+			"secretID":   warning.SecretID,
+			"secretName": warning.SecretName,
+			"expiresAt":  warning.ExpiresAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			log.L(sw.ctx).Errorf("send secret expiry warning to user %s failed: %s",
+				warning.Recipient.Username, err.Error())
+		}
+	}
+}
+
+// Spec is parsed using the time zone of secretexpiry Cron instance as the default.
+func (sw *secretExpiryWatcher) Spec() string {
+	return "@every 24h"
+}
+
+// Init initializes the watcher for later execution.
+func (sw *secretExpiryWatcher) Init(ctx context.Context, rs *redsync.Mutex, config interface{}) error {
+	cfg, ok := config.(*options.WatcherOptions)
+	if !ok {
+		return watcher.ErrConfigUnavailable
+	}
+
+	*sw = secretExpiryWatcher{
+		ctx:   ctx,
+		mutex: rs,
+		opts:  cfg.SecretExpiry,
+	}
+
+	return nil
+}
+
+func init() {
+	watcher.Register("secretexpiry", &secretExpiryWatcher{})
+}
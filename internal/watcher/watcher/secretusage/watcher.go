@@ -0,0 +1,90 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretusage
+
+import (
+	"context"
+
+	"github.com/go-redsync/redsync/v4"
+
+	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/secretusage"
+	"github.com/marmotedu/iam/internal/watcher/watcher"
+	v1 "github.com/marmotedu/iam/pkg/api/apiserver/v1"
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// secretUsageWatcher moves the usages of the secrets flushed to redis by iam-authz-server to
+// mysql, and deletes the usages of the deleted secrets.
+type secretUsageWatcher struct {
+	ctx   context.Context
+	mutex *redsync.Mutex
+}
+
+// Run runs the watcher job.
+func (sw *secretUsageWatcher) Run() {
+	if err := sw.mutex.Lock(); err != nil {
+		log.L(sw.ctx).Info("secretUsageWatcher already run.")
+
+		return
+	}
+
+	defer func() {
+		if _, err := sw.mutex.Unlock(); err != nil {
+			log.L(sw.ctx).Errorf("could not release secretUsageWatcher lock. err: %v", err)
+
+			return
+		}
+	}()
+
+	db, _ := mysql.GetMySQLFactoryOr(nil)
+
+	lastUsed, err := secretusage.Drain(sw.ctx)
+	if err != nil {
+		log.L(sw.ctx).Errorw("drain secret usages from redis failed", "error", err)
+
+		return
+	}
+
+	usages := make([]*v1.SecretUsage, 0, len(lastUsed))
+	for secretID, at := range lastUsed {
+		usages = append(usages, &v1.SecretUsage{SecretID: secretID, LastUsedAt: at})
+	}
+
+	if err := db.SecretUsages().Record(sw.ctx, usages); err != nil {
+		log.L(sw.ctx).Errorw("record secret usages failed", "error", err)
+		secretusage.Requeue(sw.ctx, lastUsed)
+
+		return
+	}
+
+	rowsAffected, err := db.SecretUsages().Prune(sw.ctx)
+	if err != nil {
+		log.L(sw.ctx).Errorw("prune secret usages failed", "error", err)
+
+		return
+	}
+
+	log.L(sw.ctx).Debugf("recorded the usages of %d secrets, pruned %d", len(usages), rowsAffected)
+}
+
+// Spec is parsed using the time zone of secretusage Cron instance as the default.
+func (sw *secretUsageWatcher) Spec() string {
+	return "@every 1m"
+}
+
+// Init initializes the watcher for later execution.
+func (sw *secretUsageWatcher) Init(ctx context.Context, rs *redsync.Mutex, config interface{}) error {
+	*sw = secretUsageWatcher{
+		ctx:   ctx,
+		mutex: rs,
+	}
+
+	return nil
+}
+
+func init() {
+	watcher.Register("secretusage", &secretUsageWatcher{})
+}
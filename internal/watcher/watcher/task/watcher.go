@@ -6,12 +6,15 @@ package task
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-redsync/redsync/v4"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 
 	"github.com/marmotedu/iam/internal/apiserver/store/mysql"
+	"github.com/marmotedu/iam/internal/pkg/notifier"
+	"github.com/marmotedu/iam/internal/pkg/userstate"
 	"github.com/marmotedu/iam/internal/watcher/options"
 	"github.com/marmotedu/iam/internal/watcher/watcher"
 	"github.com/marmotedu/iam/pkg/log"
@@ -56,8 +59,18 @@ func (tw *taskWatcher) Run() {
 		if time.Since(user.LoginedAt) > time.Duration(tw.maxInactiveDays)*(24*time.Hour) {
 			log.L(tw.ctx).Infof("user %s not active for %d days, disable his account", user.Name, tw.maxInactiveDays)
 
-			user.Status = 0
-			_ = db.Users().Update(tw.ctx, user, metav1.UpdateOptions{})
+			wasActive := userstate.Of(user) == userstate.Active
+			user.Status = int(userstate.Deactivated)
+			if err := db.Users().Update(tw.ctx, user, metav1.UpdateOptions{}); err != nil || !wasActive {
+				continue
+			}
+
+			err := notifier.Send(tw.ctx, notifier.Lockout, notifier.RecipientOf(user), map[string]interface{}{
+				"reason": fmt.Sprintf("the account is not used for %d days", tw.maxInactiveDays),
+			})
+			if err != nil {
+				log.L(tw.ctx).Errorf("notify user %s of the lockout failed: %s", user.Name, err.Error())
+			}
 		}
 	}
 }
@@ -0,0 +1,82 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"strings"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// Subject kinds that a managed policy can be attached to.
+const (
+	SubjectKindUser  = "users"
+	SubjectKindGroup = "groups"
+	SubjectKindRole  = "roles"
+)
+
+// PolicyAttachment represents the attachment of a managed policy to a subject.
+// It is also used as gorm model.
+type PolicyAttachment struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata, the name of an attachment is generated.
+	metav1.ObjectMeta `json:"metadata,omitempty" validate:"-"`
+
+	// The owner of the attached policy.
+	Username string `json:"username" gorm:"column:username" validate:"omitempty"`
+
+	// The name of the attached policy.
+	PolicyName string `json:"policyName" gorm:"column:policyName" validate:"omitempty"`
+
+	// Subject is the principal the policy is attached to, in the ladon `<kind>:<name>`
+	// format, e.g. users:colin, groups:admins or roles:auditor.
+	Subject string `json:"subject" gorm:"column:subject" validate:"required,subject=users groups roles"`
+}
+
+// PolicyAttachmentList is the whole list of all policy attachments which have been stored in stroage.
+type PolicyAttachmentList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of policy attachments.
+	Items []*PolicyAttachment `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (a *PolicyAttachment) TableName() string {
+	return "policy_attachment"
+}
+
+// AfterCreate run after create database record.
+func (a *PolicyAttachment) AfterCreate(tx *gorm.DB) error {
+	a.InstanceID = idutil.GetInstanceID(a.ID, "attachment-")
+
+	return tx.Save(a).Error
+}
+
+// Validate validates that a policy attachment object is valid.
+func (a *PolicyAttachment) Validate() field.ErrorList {
+	return validator.Struct(a)
+}
+
+// SplitSubject splits a ladon subject into its kind and name.
+func SplitSubject(subject string) (kind, name string, ok bool) {
+	parts := strings.SplitN(subject, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "testing"
+
+func TestPolicyAttachment_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		wantErr bool
+	}{
+		{name: "user", subject: "users:colin", wantErr: false},
+		{name: "group", subject: "groups:admins", wantErr: false},
+		{name: "role", subject: "roles:auditor", wantErr: false},
+		{name: "missing kind", subject: "colin", wantErr: true},
+		{name: "empty name", subject: "users:", wantErr: true},
+		{name: "unsupported kind", subject: "teams:dev", wantErr: true},
+		{name: "invalid name", subject: "users:<.*>", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &PolicyAttachment{Subject: tt.subject}
+			if errs := a.Validate(); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("PolicyAttachment.Validate() error = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"gorm.io/gorm"
+)
+
+// Verbs of the audited requests.
+const (
+	AuditVerbCreate = "create"
+	AuditVerbUpdate = "update"
+	AuditVerbDelete = "delete"
+)
+
+// AuditVerb returns the audit verb of a http method, or an empty string if the requests of
+// the method do not change any resource.
+func AuditVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return AuditVerbCreate
+	case http.MethodPut, http.MethodPatch:
+		return AuditVerbUpdate
+	case http.MethodDelete:
+		return AuditVerbDelete
+	default:
+		return ""
+	}
+}
+
+// AuditEvent represents a request of a user which changed, or tried to change, a resource.
+// It is also used as gorm model.
+type AuditEvent struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The user who sent the request.
+	Username string `json:"username" gorm:"column:username"`
+
+	// The verb of the request, create, update or delete.
+	Verb string `json:"verb" gorm:"column:verb"`
+
+	// The type of the resource, e.g. policies or secrets.
+	Resource string `json:"resource" gorm:"column:resource"`
+
+	// The name of the resource, empty when the request creates it or deletes a collection.
+	ResourceName string `json:"resourceName,omitempty" gorm:"column:resourceName"`
+
+	// The http method and path of the request.
+	Method string `json:"method" gorm:"column:method"`
+	Path   string `json:"path" gorm:"column:path"`
+
+	// The http status code of the response.
+	StatusCode int `json:"statusCode" gorm:"column:statusCode"`
+
+	// The client ip and the user agent of the request.
+	IP        string `json:"ip" gorm:"column:ip"`
+	UserAgent string `json:"userAgent" gorm:"column:userAgent"`
+
+	// The request id, which can be found in the logs of the apiserver.
+	RequestID string `json:"requestID,omitempty" gorm:"column:requestID"`
+}
+
+// AuditEventList is the whole list of all audit events which have been stored in stroage.
+type AuditEventList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of audit events.
+	Items []*AuditEvent `json:"items"`
+}
+
+// AuditEventListOptions is the query options to list the audit events. The events can be
+// filtered by the `username`, `verb`, `resource` and `resourceName` field selectors.
+type AuditEventListOptions struct {
+	metav1.ListOptions `json:",inline"`
+
+	// Since only lists the events created at or after the time.
+	Since *time.Time `json:"since,omitempty" form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	// AfterID only lists the events whose id is greater than it, it is used to follow the
+	// new events.
+	AfterID uint64 `json:"afterID,omitempty" form:"afterID"`
+}
+
+// Successful reports whether the request succeeded.
+func (a *AuditEvent) Successful() bool {
+	return a.StatusCode >= http.StatusOK && a.StatusCode < http.StatusMultipleChoices
+}
+
+// TableName maps to mysql table name.
+func (a *AuditEvent) TableName() string {
+	return "audit_event"
+}
+
+// AfterCreate run after create database record.
+func (a *AuditEvent) AfterCreate(tx *gorm.DB) error {
+	a.InstanceID = idutil.GetInstanceID(a.ID, "audit-")
+
+	return tx.Save(a).Error
+}
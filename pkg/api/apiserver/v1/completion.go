@@ -0,0 +1,37 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+// Kinds of the resources whose names can be completed.
+const (
+	CompletionKindUser   = "user"
+	CompletionKindSecret = "secret"
+	CompletionKindPolicy = "policy"
+	CompletionKindGroup  = "group"
+)
+
+// DefaultCompletionLimit is the default number of the names returned by a completion.
+const DefaultCompletionLimit = 100
+
+// CompletionKinds are the kinds of the resources whose names can be completed.
+var CompletionKinds = []string{CompletionKindUser, CompletionKindSecret, CompletionKindPolicy, CompletionKindGroup}
+
+// CompletionOptions is the query options to complete the names of the resources.
+type CompletionOptions struct {
+	// Kind is the kind of the resources, one of user, secret, policy or group.
+	Kind string `json:"kind" form:"kind"`
+
+	// Prefix is the prefix of the names to complete.
+	Prefix string `json:"prefix,omitempty" form:"prefix"`
+
+	// Limit specify the number of names to be returned, defaults to DefaultCompletionLimit.
+	Limit int `json:"limit,omitempty" form:"limit"`
+}
+
+// CompletionList is the names of the resources starting with a prefix, in alphabetical order.
+type CompletionList struct {
+	Kind  string   `json:"kind"`
+	Items []string `json:"items"`
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package v1 defines the iam-apiserver v1 resources which are not yet part of
+// github.com/marmotedu/api.
+package v1 // import "github.com/marmotedu/iam/pkg/api/apiserver/v1"
@@ -0,0 +1,137 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// Group represents a group or a role of users. The policies whose subjects match the
+// group subject, e.g. groups:admins, apply to all of its members.
+// It is also used as gorm model.
+type Group struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The owner of the group.
+	Username string `json:"username" gorm:"column:username" validate:"omitempty"`
+
+	// Kind is either groups or roles, defaults to groups.
+	Kind string `json:"kind,omitempty" gorm:"column:kind" validate:"omitempty,oneof=groups roles"`
+
+	// Members are the users of the group, in the ladon `users:<name>` format.
+	Members []string `json:"members" gorm:"-" validate:"unique,dive,subject=users"`
+
+	// MembersShadow is the json format of Members stored in the database.
+	MembersShadow string `json:"-" gorm:"column:membersShadow" validate:"omitempty"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+}
+
+// GroupList is the whole list of all groups which have been stored in stroage.
+type GroupList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of groups.
+	Items []*Group `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (g *Group) TableName() string {
+	return "user_group"
+}
+
+// Subject returns the ladon subject of the group, e.g. groups:admins or roles:auditor.
+func (g *Group) Subject() string {
+	kind := g.Kind
+	if kind == "" {
+		kind = SubjectKindGroup
+	}
+
+	return kind + ":" + g.Name
+}
+
+// HasMember reports whether the member belongs to the group.
+func (g *Group) HasMember(member string) bool {
+	for _, m := range g.Members {
+		if m == member {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BeforeCreate run before create database record.
+func (g *Group) BeforeCreate(tx *gorm.DB) error {
+	if err := g.ObjectMeta.BeforeCreate(tx); err != nil {
+		return fmt.Errorf("failed to run `BeforeCreate` hook: %w", err)
+	}
+
+	return g.shadowMembers()
+}
+
+// AfterCreate run after create database record.
+func (g *Group) AfterCreate(tx *gorm.DB) error {
+	g.InstanceID = idutil.GetInstanceID(g.ID, "group-")
+
+	return tx.Save(g).Error
+}
+
+// BeforeUpdate run before update database record.
+func (g *Group) BeforeUpdate(tx *gorm.DB) error {
+	if err := g.ObjectMeta.BeforeUpdate(tx); err != nil {
+		return fmt.Errorf("failed to run `BeforeUpdate` hook: %w", err)
+	}
+
+	return g.shadowMembers()
+}
+
+// AfterFind run after find to unmarshal the members.
+func (g *Group) AfterFind(tx *gorm.DB) error {
+	if err := g.ObjectMeta.AfterFind(tx); err != nil {
+		return fmt.Errorf("failed to run `AfterFind` hook: %w", err)
+	}
+
+	if g.MembersShadow == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(g.MembersShadow), &g.Members); err != nil {
+		return fmt.Errorf("failed to unmarshal membersShadow: %w", err)
+	}
+
+	return nil
+}
+
+func (g *Group) shadowMembers() error {
+	data, err := json.Marshal(g.Members)
+	if err != nil {
+		return fmt.Errorf("failed to marshal members: %w", err)
+	}
+	g.MembersShadow = string(data)
+
+	return nil
+}
+
+// Validate validates that a group object is valid.
+func (g *Group) Validate() field.ErrorList {
+	return validator.Struct(g)
+}
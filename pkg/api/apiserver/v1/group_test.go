@@ -0,0 +1,70 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+func TestGroup_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   *Group
+		wantErr bool
+	}{
+		{
+			name:    "group",
+			group:   &Group{ObjectMeta: metav1.ObjectMeta{Name: "admins"}, Members: []string{"users:colin"}},
+			wantErr: false,
+		},
+		{
+			name:    "role",
+			group:   &Group{ObjectMeta: metav1.ObjectMeta{Name: "auditor"}, Kind: "roles"},
+			wantErr: false,
+		},
+		{
+			name:    "unsupported kind",
+			group:   &Group{ObjectMeta: metav1.ObjectMeta{Name: "dev"}, Kind: "teams"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			group:   &Group{ObjectMeta: metav1.ObjectMeta{Name: "<.*>"}},
+			wantErr: true,
+		},
+		{
+			name:    "member is not a user",
+			group:   &Group{ObjectMeta: metav1.ObjectMeta{Name: "admins"}, Members: []string{"groups:dev"}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate member",
+			group: &Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "admins"},
+				Members:    []string{"users:colin", "users:colin"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.group.Validate(); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("Group.Validate() error = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroup_Subject(t *testing.T) {
+	if got := (&Group{ObjectMeta: metav1.ObjectMeta{Name: "admins"}}).Subject(); got != "groups:admins" {
+		t.Errorf("Group.Subject() = %s, want groups:admins", got)
+	}
+
+	if got := (&Group{ObjectMeta: metav1.ObjectMeta{Name: "auditor"}, Kind: "roles"}).Subject(); got != "roles:auditor" {
+		t.Errorf("Group.Subject() = %s, want roles:auditor", got)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "time"
+
+// Authentication methods of the requests.
+const (
+	// AuthMethodBasic means the request carries the username and password of the user.
+	AuthMethodBasic = "basic"
+
+	// AuthMethodToken means the request carries a bearer token.
+	AuthMethodToken = "token"
+)
+
+// Identity is the authenticated identity of a request, as seen by the apiserver.
+type Identity struct {
+	// The authenticated user.
+	Username string `json:"username"`
+	Nickname string `json:"nickname,omitempty"`
+	Email    string `json:"email,omitempty"`
+
+	// The authentication method of the request, basic or token.
+	AuthMethod string `json:"authMethod"`
+
+	// The expiry time of the token, only set when the request carries a token.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// The tenant of the user, set by the `tenant` key of its extend field.
+	Tenant string `json:"tenant,omitempty"`
+
+	// The groups and roles the user is a member of, in the ladon `<kind>:<name>` format,
+	// e.g. groups:admins or roles:auditor.
+	Groups []string `json:"groups"`
+
+	// Whether the user is an administrator, who can call the admin apis.
+	IsAdmin bool `json:"isAdmin"`
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"gorm.io/gorm"
+)
+
+// Authentication methods used by a login attempt.
+const (
+	// LoginMethodBasic means the credentials are passed by the Basic Authorization header.
+	LoginMethodBasic = "basic"
+
+	// LoginMethodPassword means the credentials are passed by the request body.
+	LoginMethodPassword = "password"
+
+	// LoginMethodSAML means the user is authenticated by a SAML identity provider.
+	LoginMethodSAML = "saml"
+
+	// LoginMethodConnector means the user is authenticated by an upstream identity provider
+	// connector, e.g. GitHub or Google.
+	LoginMethodConnector = "connector"
+)
+
+// LoginRecord represents a single login attempt of a user.
+// It is also used as gorm model.
+type LoginRecord struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The username used by the login attempt, the user may not exist.
+	Username string `json:"username" gorm:"column:username"`
+
+	// The client ip of the login attempt.
+	IP string `json:"ip" gorm:"column:ip"`
+
+	// The user agent of the login attempt.
+	UserAgent string `json:"userAgent" gorm:"column:userAgent"`
+
+	// The authentication method used by the login attempt, e.g. basic, password or saml.
+	Method string `json:"method" gorm:"column:method"`
+
+	// Whether the login attempt succeeded.
+	Success bool `json:"success" gorm:"column:success"`
+
+	// The reason why the login attempt failed.
+	Reason string `json:"reason,omitempty" gorm:"column:reason"`
+}
+
+// LoginRecordList is the whole list of all login records which have been stored in stroage.
+type LoginRecordList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of login records.
+	Items []*LoginRecord `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (l *LoginRecord) TableName() string {
+	return "login_record"
+}
+
+// AfterCreate run after create database record.
+func (l *LoginRecord) AfterCreate(tx *gorm.DB) error {
+	l.InstanceID = idutil.GetInstanceID(l.ID, "login-")
+
+	return tx.Save(l).Error
+}
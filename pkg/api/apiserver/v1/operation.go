@@ -0,0 +1,142 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"gorm.io/gorm"
+)
+
+// Statuses of the operations.
+const (
+	OperationPending   = "Pending"
+	OperationRunning   = "Running"
+	OperationSucceeded = "Succeeded"
+	OperationFailed    = "Failed"
+)
+
+// Operation is a long-running request executed in the background, e.g. the deletion of a
+// tenant. The request returns it at once, and the client polls it by its name until it is
+// done.
+// It is also used as gorm model.
+type Operation struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Kind is the type of the operation, e.g. DeleteTenant.
+	Kind string `json:"kind" gorm:"column:kind"`
+
+	// The user who requested the operation.
+	Username string `json:"username" gorm:"column:username"`
+
+	// Params are the parameters of the operation, e.g. the name of the deleted tenant.
+	Params metav1.Extend `json:"params,omitempty" gorm:"-"`
+
+	// ParamsShadow is the json format of Params stored in the database.
+	ParamsShadow string `json:"-" gorm:"column:paramsShadow"`
+
+	// Status is one of Pending, Running, Succeeded and Failed.
+	Status string `json:"status" gorm:"column:status"`
+
+	// Progress is the percentage of the operation which is done.
+	Progress int `json:"progress" gorm:"column:progress"`
+
+	// Result is the result of a succeeded operation, e.g. the number of deleted users.
+	Result metav1.Extend `json:"result,omitempty" gorm:"-"`
+
+	// ResultShadow is the json format of Result stored in the database.
+	ResultShadow string `json:"-" gorm:"column:resultShadow"`
+
+	// Error is the reason of a failed operation.
+	Error string `json:"error,omitempty" gorm:"column:error"`
+
+	// FinishedAt is the time the operation succeeded or failed.
+	FinishedAt *time.Time `json:"finishedAt,omitempty" gorm:"column:finishedAt"`
+}
+
+// OperationList is the whole list of all operations which have been stored in stroage.
+type OperationList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of operations.
+	Items []*Operation `json:"items"`
+}
+
+// Done reports whether the operation succeeded or failed.
+func (o *Operation) Done() bool {
+	return o.Status == OperationSucceeded || o.Status == OperationFailed
+}
+
+// TableName maps to mysql table name.
+func (o *Operation) TableName() string {
+	return "operation"
+}
+
+// BeforeCreate run before create database record.
+func (o *Operation) BeforeCreate(tx *gorm.DB) error {
+	if err := o.ObjectMeta.BeforeCreate(tx); err != nil {
+		return fmt.Errorf("failed to run `BeforeCreate` hook: %w", err)
+	}
+
+	o.shadow()
+
+	return nil
+}
+
+// AfterCreate run after create database record.
+func (o *Operation) AfterCreate(tx *gorm.DB) error {
+	o.InstanceID = idutil.GetInstanceID(o.ID, "operation-")
+
+	return tx.Save(o).Error
+}
+
+// BeforeUpdate run before update database record.
+func (o *Operation) BeforeUpdate(tx *gorm.DB) error {
+	if err := o.ObjectMeta.BeforeUpdate(tx); err != nil {
+		return fmt.Errorf("failed to run `BeforeUpdate` hook: %w", err)
+	}
+
+	o.shadow()
+
+	return nil
+}
+
+// AfterFind run after find to unmarshal the params and the result.
+func (o *Operation) AfterFind(tx *gorm.DB) error {
+	if err := o.ObjectMeta.AfterFind(tx); err != nil {
+		return fmt.Errorf("failed to run `AfterFind` hook: %w", err)
+	}
+
+	if o.ParamsShadow != "" {
+		if err := json.Unmarshal([]byte(o.ParamsShadow), &o.Params); err != nil {
+			return fmt.Errorf("failed to unmarshal paramsShadow: %w", err)
+		}
+	}
+
+	if o.ResultShadow != "" {
+		if err := json.Unmarshal([]byte(o.ResultShadow), &o.Result); err != nil {
+			return fmt.Errorf("failed to unmarshal resultShadow: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Operation) shadow() {
+	o.ParamsShadow = o.Params.String()
+	o.ResultShadow = o.Result.String()
+}
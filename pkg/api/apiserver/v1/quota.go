@@ -0,0 +1,89 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/pkg/validator"
+)
+
+// Quota limits the resources of a tenant, the name of a quota is the name of the tenant.
+// A zero limit is unlimited.
+// It is also used as gorm model.
+type Quota struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// MaxUsers limits the available users of the tenant.
+	MaxUsers int64 `json:"maxUsers" gorm:"column:maxUsers" validate:"min=0"`
+
+	// MaxSecretsPerUser limits the secrets of each user of the tenant.
+	MaxSecretsPerUser int64 `json:"maxSecretsPerUser" gorm:"column:maxSecretsPerUser" validate:"min=0"`
+
+	// MaxPolicies limits the policies of the tenant.
+	MaxPolicies int64 `json:"maxPolicies" gorm:"column:maxPolicies" validate:"min=0"`
+
+	// MaxPolicySize limits the size of each policy of the tenant, in bytes of its json format.
+	MaxPolicySize int64 `json:"maxPolicySize" gorm:"column:maxPolicySize" validate:"min=0"`
+
+	// MaxPasswordAge limits how long the passwords of the users of the tenant are valid, in days
+	// since they were last changed.
+	MaxPasswordAge int64 `json:"maxPasswordAge" gorm:"column:maxPasswordAge" validate:"min=0"`
+
+	// Usage is the resources used by the tenant, it is only returned along with the quota.
+	Usage *QuotaUsage `json:"usage,omitempty" gorm:"-" validate:"omitempty"`
+}
+
+// QuotaUsage is the resources used by a tenant.
+type QuotaUsage struct {
+	// Users is the number of the available users of the tenant.
+	Users int64 `json:"users"`
+
+	// SecretsPerUser is the number of the secrets of the user of the tenant who has the most.
+	SecretsPerUser int64 `json:"secretsPerUser"`
+
+	// Policies is the number of the policies of the tenant.
+	Policies int64 `json:"policies"`
+
+	// PolicySize is the size of the largest policy of the tenant.
+	PolicySize int64 `json:"policySize"`
+}
+
+// QuotaList is the whole list of all quotas which have been stored in stroage.
+type QuotaList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of quotas.
+	Items []*Quota `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (q *Quota) TableName() string {
+	return "tenant_quota"
+}
+
+// AfterCreate run after create database record.
+func (q *Quota) AfterCreate(tx *gorm.DB) error {
+	q.InstanceID = idutil.GetInstanceID(q.ID, "quota-")
+
+	return tx.Save(q).Error
+}
+
+// Validate validates that a quota object is valid.
+func (q *Quota) Validate() field.ErrorList {
+	// the quota is named after the tenant
+	return append(validator.Struct(q), validator.Var(field.NewPath("metadata", "name"), q.Name, "dnslabel")...)
+}
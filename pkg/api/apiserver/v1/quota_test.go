@@ -0,0 +1,47 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+func TestQuota_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quota   *Quota
+		wantErr bool
+	}{
+		{
+			name:    "quota",
+			quota:   &Quota{ObjectMeta: metav1.ObjectMeta{Name: "marmotedu"}, MaxUsers: 100, MaxPolicySize: 4096},
+			wantErr: false,
+		},
+		{
+			name:    "unlimited",
+			quota:   &Quota{ObjectMeta: metav1.ObjectMeta{Name: "marmotedu"}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid tenant",
+			quota:   &Quota{ObjectMeta: metav1.ObjectMeta{Name: "Marmotedu"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			quota:   &Quota{ObjectMeta: metav1.ObjectMeta{Name: "marmotedu"}, MaxSecretsPerUser: -1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.quota.Validate(); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("Quota.Validate() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// Secret is a secret along with the last time it was used, as returned by the secret api.
+type Secret struct {
+	*v1.Secret `json:",inline"`
+
+	// LastUsedAt is the last time the secret was used to sign an accepted request, it is unset
+	// for the secrets never used. It lags behind by up to a few minutes.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// SecretList is the whole list of all secrets along with the last time they were used.
+type SecretList struct {
+	// May add TypeMeta in the future.
+	// metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of secrets.
+	Items []*Secret `json:"items"`
+}
+
+// SecretUsage records the last time a secret was used.
+// It is also used as gorm model.
+type SecretUsage struct {
+	// SecretID is the identifier of the secret.
+	SecretID string `json:"secretID" gorm:"column:secretID;primaryKey"`
+
+	// LastUsedAt is the last time the secret was used.
+	LastUsedAt time.Time `json:"lastUsedAt" gorm:"column:lastUsedAt"`
+}
+
+// TableName maps to mysql table name.
+func (u *SecretUsage) TableName() string {
+	return "secret_usage"
+}
@@ -0,0 +1,12 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+// ImportResult is the result of the import of a snapshot, the numbers of the created resources
+// and of the skipped ones, which already existed, by kind.
+type ImportResult struct {
+	Created map[string]int64 `json:"created"`
+	Skipped map[string]int64 `json:"skipped"`
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "github.com/ory/ladon"
+
+// AssumeRoleRequest is the request to exchange the credentials of a user for temporary ones.
+type AssumeRoleRequest struct {
+	// SessionName identifies the session, e.g. the CI job, in the audit trail.
+	SessionName string `json:"sessionName" validate:"name"`
+
+	// DurationSeconds is the lifetime of the temporary credentials, from 15 minutes to 12
+	// hours, defaults to 1 hour.
+	DurationSeconds int64 `json:"durationSeconds,omitempty" validate:"omitempty,min=900,max=43200"`
+
+	// Policies restrict the temporary credentials, which only authorize the requests allowed
+	// by both the policies of the user and these ones.
+	Policies []*ladon.DefaultPolicy `json:"policies" validate:"required,max=10"`
+}
+
+// Credentials are the temporary credentials issued by assume-role.
+type Credentials struct {
+	SecretID    string `json:"secretID"`
+	SecretKey   string `json:"secretKey"`
+	Expires     int64  `json:"expires"`
+	SessionName string `json:"sessionName" validate:"name"`
+}
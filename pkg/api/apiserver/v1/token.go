@@ -0,0 +1,19 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+// RevokeTokenRequest is the request to revoke jwt tokens, exactly one of its fields is set.
+type RevokeTokenRequest struct {
+	// Token revokes the token itself, any authenticated user holding it can revoke it.
+	Token string `json:"token,omitempty"`
+
+	// JTI revokes the tokens with this `jti` claim, including the refreshed ones. Only the
+	// platform administrators revoke the tokens by id.
+	JTI string `json:"jti,omitempty"`
+
+	// Username revokes all the tokens issued to the user so far. The users revoke their own
+	// tokens, the administrators the tokens of the users they administer.
+	Username string `json:"username,omitempty" validate:"omitempty,username"`
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/marmotedu/iam/pkg/featuregate"
 	"github.com/marmotedu/iam/pkg/log"
 )
 
@@ -75,7 +76,12 @@ type App struct {
 	noConfig    bool
 	commands    []*Command
 	args        cobra.PositionalArgs
+	reloaders   map[string]Reloader
+	checks      []Check
+	featureGate *featuregate.FeatureGate
 	cmd         *cobra.Command
+	// flagSets are the flags of the command, by section.
+	flagSets cliflag.NamedFlagSets
 }
 
 // Option defines optional parameters for initializing the application
@@ -130,6 +136,20 @@ func WithNoConfig() Option {
 	}
 }
 
+// WithCommands adds the subcommands of the application.
+func WithCommands(cmds ...*Command) Option {
+	return func(a *App) {
+		a.commands = append(a.commands, cmds...)
+	}
+}
+
+// WithFeatureGate adds the --feature-gates flag setting the features of the feature gate.
+func WithFeatureGate(gate *featuregate.FeatureGate) Option {
+	return func(a *App) {
+		a.featureGate = gate
+	}
+}
+
 // WithValidArgs set the validation function to valid non-flag arguments.
 func WithValidArgs(args cobra.PositionalArgs) Option {
 	return func(a *App) {
@@ -210,12 +230,26 @@ func (a *App) buildCommand() {
 	if !a.noConfig {
 		addConfigFlag(a.basename, namedFlagSets.FlagSet("global"))
 	}
+	if a.featureGate != nil {
+		a.featureGate.AddFlag(namedFlagSets.FlagSet("global"))
+	}
+	if len(a.checks) > 0 {
+		addDryRunFlag(namedFlagSets.FlagSet("global"))
+	}
 	globalflag.AddGlobalFlags(namedFlagSets.FlagSet("global"), cmd.Name())
 	// add new global flagset to cmd FlagSet
 	cmd.Flags().AddFlagSet(namedFlagSets.FlagSet("global"))
 
+	if !a.noConfig && a.options != nil {
+		cmd.AddCommand(a.configCommand(namedFlagSets))
+	}
+	if len(a.checks) > 0 {
+		cmd.AddCommand(a.checkCommand(namedFlagSets))
+	}
+
 	addCmdTemplate(&cmd, namedFlagSets)
 	a.cmd = &cmd
+	a.flagSets = namedFlagSets
 }
 
 // Run is used to launch the application.
@@ -232,6 +266,12 @@ func (a *App) Command() *cobra.Command {
 }
 
 func (a *App) runCommand(cmd *cobra.Command, args []string) error {
+	if helpEnv {
+		printEnv(a.basename, a.flagSets)
+
+		return nil
+	}
+
 	printWorkingDir()
 	cliflag.PrintFlags(cmd.Flags())
 	if !a.noVersion {
@@ -244,9 +284,17 @@ func (a *App) runCommand(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		if err := migrateDeprecatedFlags(cmd.Flags()); err != nil {
+			return err
+		}
+
 		if err := viper.Unmarshal(a.options); err != nil {
 			return err
 		}
+
+		if err := a.applyFeatureGates(cmd); err != nil {
+			return err
+		}
 	}
 
 	if !a.silence {
@@ -258,11 +306,22 @@ func (a *App) runCommand(cmd *cobra.Command, args []string) error {
 			log.Infof("%v Config file used: `%s`", progressMessage, viper.ConfigFileUsed())
 		}
 	}
+	if dryRun {
+		var err error
+		if a.options != nil {
+			err = a.applyOptionRules()
+		}
+
+		return a.runChecks(err)
+	}
 	if a.options != nil {
 		if err := a.applyOptionRules(); err != nil {
 			return err
 		}
 	}
+	if !a.noConfig && a.options != nil {
+		a.watchConfig()
+	}
 	// run application
 	if a.runFunc != nil {
 		return a.runFunc(a.basename)
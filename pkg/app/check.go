@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/gosuri/uitable"
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const dryRunFlagName = "dry-run"
+
+var dryRun bool
+
+// Check is a verification of the environment the application starts in, e.g. the
+// connectivity to a dependency. The checks run instead of the application in dry-run mode.
+type Check struct {
+	Name string
+	Run  func() error
+}
+
+// WithChecks sets the checks run in dry-run mode, after the options are validated.
+func WithChecks(checks ...Check) Option {
+	return func(a *App) {
+		a.checks = checks
+	}
+}
+
+// addDryRunFlag adds the flag enabling the dry-run mode to the specified FlagSet.
+func addDryRunFlag(fs *pflag.FlagSet) {
+	fs.BoolVar(&dryRun, dryRunFlagName, false, ""+
+		"Validate the configuration, run the startup checks, e.g. the connectivity to the "+
+		"dependencies, print a report and exit. The exit code is non-zero if any check fails.")
+}
+
+// runChecks runs every check, whatever the result of the previous ones, prints the report
+// and returns an error if any check failed. optionsErr is the result of the validation of
+// the options.
+func (a *App) runChecks(optionsErr error) error {
+	table := uitable.New()
+	table.Separator = "  "
+	table.MaxColWidth = 100
+	table.Wrap = true
+	table.AddRow("CHECK", "RESULT", "DETAIL")
+
+	failed := 0
+	report := func(name string, err error) {
+		if err != nil {
+			failed++
+			table.AddRow(name, color.RedString("FAIL"), err.Error())
+
+			return
+		}
+		table.AddRow(name, color.GreenString("OK"), "")
+	}
+
+	report("config", optionsErr)
+	for _, check := range a.checks {
+		report(check.Name, check.Run())
+	}
+
+	fmt.Printf("%v Startup checks of %s:\n%v\n", progressMessage, a.name, table)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d startup checks failed", failed, len(a.checks)+1)
+	}
+
+	return nil
+}
+
+// checkCommand returns the check command of the application, which shares the flags of the
+// application to run the startup checks, like the dry-run mode, e.g. in an init container.
+func (a *App) checkCommand(namedFlagSets cliflag.NamedFlagSets) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Run the startup checks, print a pass/fail report and exit non-zero on failure.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !a.noConfig {
+				if err := viper.BindPFlags(cmd.Flags()); err != nil {
+					return err
+				}
+
+				if err := migrateDeprecatedFlags(cmd.Flags()); err != nil {
+					return err
+				}
+
+				if err := viper.Unmarshal(a.options); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			if a.options != nil {
+				err = a.applyOptionRules()
+			}
+
+			return a.runChecks(err)
+		},
+	}
+	for _, name := range namedFlagSets.Order {
+		cmd.Flags().AddFlagSet(namedFlagSets.FlagSets[name])
+	}
+
+	return cmd
+}
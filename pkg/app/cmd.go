@@ -77,6 +77,7 @@ func (c *Command) cobraCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   c.usage,
 		Short: c.desc,
+		Long:  c.desc,
 	}
 	cmd.SetOutput(os.Stdout)
 	cmd.Flags().SortFlags = false
@@ -88,13 +89,15 @@ func (c *Command) cobraCommand() *cobra.Command {
 	if c.runFunc != nil {
 		cmd.Run = c.runCommand
 	}
+	addHelpCommandFlag(c.usage, cmd.Flags())
 	if c.options != nil {
-		for _, f := range c.options.Flags().FlagSets {
+		namedFlagSets := c.options.Flags()
+		for _, f := range namedFlagSets.FlagSets {
 			cmd.Flags().AddFlagSet(f)
 		}
-		// c.options.AddFlags(cmd.Flags())
+		// print the flags of the command by section, instead of the flags of the application
+		addCmdTemplate(cmd, namedFlagSets)
 	}
-	addHelpCommandFlag(c.usage, cmd.Flags())
 
 	return cmd
 }
@@ -5,21 +5,35 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/gosuri/uitable"
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
 	"github.com/marmotedu/component-base/pkg/util/homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/marmotedu/iam/pkg/util/secretref"
+)
+
+const (
+	configFlagName  = "config"
+	helpEnvFlagName = "help-env"
 )
 
-const configFlagName = "config"
+var (
+	cfgFile string
+	helpEnv bool
 
-var cfgFile string
+	// envKeyReplacer maps the configuration keys, e.g. mysql.max-idle-connections, to the
+	// suffix of the environment variables they are bound to, e.g. MYSQL_MAX_IDLE_CONNECTIONS.
+	envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+)
 
 //nolint: gochecknoinits
 func init() {
@@ -27,14 +41,27 @@ func init() {
 		"support JSON, TOML, YAML, HCL, or Java properties formats.")
 }
 
+// envPrefix returns the prefix of the environment variables of the application, e.g.
+// IAM_APISERVER for iam-apiserver.
+func envPrefix(basename string) string {
+	return strings.Replace(strings.ToUpper(basename), "-", "_", -1)
+}
+
+// envName returns the environment variable the configuration key is bound to.
+func envName(basename string, key string) string {
+	return envPrefix(basename) + "_" + envKeyReplacer.Replace(strings.ToUpper(key))
+}
+
 // addConfigFlag adds flags for a specific server to the specified FlagSet
 // object.
 func addConfigFlag(basename string, fs *pflag.FlagSet) {
 	fs.AddFlag(pflag.Lookup(configFlagName))
+	fs.BoolVar(&helpEnv, helpEnvFlagName, false, ""+
+		"Print the environment variables every flag can be set with, and exit.")
 
 	viper.AutomaticEnv()
-	viper.SetEnvPrefix(strings.Replace(strings.ToUpper(basename), "-", "_", -1))
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.SetEnvPrefix(envPrefix(basename))
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 
 	cobra.OnInitialize(func() {
 		if cfgFile != "" {
@@ -51,12 +78,49 @@ func addConfigFlag(basename string, fs *pflag.FlagSet) {
 		}
 
 		if err := viper.ReadInConfig(); err != nil {
+			// without a configuration file, e.g. in containers, run from the flags and the
+			// environment variables
+			var notFound viper.ConfigFileNotFoundError
+			if cfgFile == "" && errors.As(err, &notFound) {
+				return
+			}
+
 			_, _ = fmt.Fprintf(os.Stderr, "Error: failed to read configuration file(%s): %v\n", cfgFile, err)
 			os.Exit(1)
 		}
+
+		if err := secretref.ResolveConfig(viper.GetViper()); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: failed to resolve the secrets of configuration file(%s): %v\n",
+				viper.ConfigFileUsed(), err)
+			os.Exit(1)
+		}
 	})
 }
 
+// printEnv prints the environment variable bound to every flag of the application, by
+// section.
+func printEnv(basename string, namedFlagSets cliflag.NamedFlagSets) {
+	for _, name := range namedFlagSets.Order {
+		// the global flags are not configuration items
+		if name == "global" {
+			continue
+		}
+
+		table := uitable.New()
+		table.Separator = "  "
+		table.MaxColWidth = 80
+		table.Wrap = true
+		namedFlagSets.FlagSets[name].VisitAll(func(flag *pflag.Flag) {
+			if flag.Deprecated != "" {
+				return
+			}
+			table.AddRow(envName(basename, flag.Name), flag.DefValue, flag.Usage)
+		})
+
+		fmt.Printf("%s%s environment variables:\n%v\n\n", strings.ToUpper(name[:1]), name[1:], table)
+	}
+}
+
 func printConfig() {
 	if keys := viper.AllKeys(); len(keys) > 0 {
 		fmt.Printf("%v Configuration items:\n", progressMessage)
@@ -0,0 +1,121 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const maskedValue = "******"
+
+// secretKeySuffixes are the suffixes of the last segment of the keys of the configuration
+// items holding secrets, e.g. mysql.password or jwt.key.
+var secretKeySuffixes = []string{"password", "secret", "token", "key"}
+
+// configCommand returns the config command of the application, which shares the flags of
+// the application to view the configuration they result in.
+func (a *App) configCommand(namedFlagSets cliflag.NamedFlagSets) *cobra.Command {
+	view := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective configuration and where every value comes from.",
+		Long: `Print the effective configuration, after merging the flags, the environment variables
+and the configuration file, along with the source of every value. Secrets are masked.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := viper.BindPFlags(cmd.Flags()); err != nil {
+				return err
+			}
+
+			if err := migrateDeprecatedFlags(cmd.Flags()); err != nil {
+				return err
+			}
+
+			printEffectiveConfig(a.basename, cmd.Flags(), namedFlagSets.FlagSet("global"))
+
+			return nil
+		},
+	}
+	for _, name := range namedFlagSets.Order {
+		view.Flags().AddFlagSet(namedFlagSets.FlagSets[name])
+	}
+
+	config := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration of the application.",
+		Args:  cobra.NoArgs,
+	}
+	config.AddCommand(view)
+
+	return config
+}
+
+// printEffectiveConfig prints the value of every configuration item and its source: flag,
+// env, file or default, by order of precedence.
+func printEffectiveConfig(basename string, fs *pflag.FlagSet, global *pflag.FlagSet) {
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+
+	table := uitable.New()
+	table.Separator = "  "
+	table.MaxColWidth = 80
+	table.Wrap = true
+	table.AddRow("KEY", "VALUE", "SOURCE")
+	for _, key := range keys {
+		// the global flags, e.g. --config or --version, are not configuration items
+		if global.Lookup(key) != nil {
+			continue
+		}
+		// the deprecated items are shown as the source of their replacement
+		if flag := fs.Lookup(key); flag != nil && flag.Deprecated != "" {
+			continue
+		}
+
+		value := fmt.Sprint(viper.Get(key))
+		if isSecretKey(key) && value != "" {
+			value = maskedValue
+		}
+		table.AddRow(key, value, configSource(basename, fs, key))
+	}
+
+	fmt.Printf("Config file used: %s\n%v\n", viper.ConfigFileUsed(), table)
+}
+
+// configSource returns where the value of the configuration item comes from.
+func configSource(basename string, fs *pflag.FlagSet, key string) string {
+	if deprecated, ok := migratedFrom[key]; ok {
+		return fmt.Sprintf("%s (deprecated %s)", configSource(basename, fs, deprecated), deprecated)
+	}
+	if flag := fs.Lookup(key); flag != nil && flag.Changed {
+		return "flag"
+	}
+	if _, ok := os.LookupEnv(envName(basename, key)); ok {
+		return "env"
+	}
+	if viper.InConfig(key) {
+		return "file"
+	}
+
+	return "default"
+}
+
+func isSecretKey(key string) bool {
+	name := key[strings.LastIndex(key, ".")+1:]
+	for _, suffix := range secretKeySuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// replacementAnnotation annotates the deprecated flags with the name of their replacement.
+const replacementAnnotation = "iam.marmotedu.com/replacement"
+
+// migratedFrom maps the flags set from the configuration items of their deprecated flag,
+// to the name of the deprecated flag.
+var migratedFrom = map[string]string{}
+
+// ConvertFunc converts the value of a deprecated flag to the value of its replacement.
+type ConvertFunc func(value string) (string, error)
+
+// RenameFlag declares the flag oldName, and the configuration item of the same name, as
+// a deprecated alias of the flag newName, which must be added to fs before.
+func RenameFlag(fs *pflag.FlagSet, oldName, newName string) {
+	MigrateFlag(fs, oldName, newName, nil)
+}
+
+// MigrateFlag declares the flag oldName, and the configuration item of the same name, as
+// deprecated in favor of the flag newName, which must be added to fs before. The values of
+// oldName are converted by convert, if not nil, and set to newName with a warning. The
+// deprecated flag is hidden from the help, and is ignored when newName is set too.
+func MigrateFlag(fs *pflag.FlagSet, oldName, newName string, convert ConvertFunc) {
+	target := fs.Lookup(newName)
+	if target == nil {
+		panic(fmt.Sprintf("replacement flag %q of the deprecated flag %q is not defined", newName, oldName))
+	}
+
+	flag := fs.VarPF(&migratedValue{target: target, convert: convert}, oldName, "", target.Usage)
+	flag.DefValue = target.DefValue
+	flag.NoOptDefVal = target.NoOptDefVal
+
+	_ = fs.MarkDeprecated(oldName, fmt.Sprintf("use --%s instead", newName))
+	_ = fs.SetAnnotation(oldName, replacementAnnotation, []string{newName})
+}
+
+// migratedValue is the value of a deprecated flag, which sets its replacement.
+type migratedValue struct {
+	target  *pflag.Flag
+	convert ConvertFunc
+	value   string
+}
+
+var _ pflag.Value = &migratedValue{}
+
+func (v *migratedValue) String() string {
+	if v.value == "" {
+		return v.target.DefValue
+	}
+
+	return v.value
+}
+
+func (v *migratedValue) Set(value string) error {
+	converted := value
+	if v.convert != nil {
+		var err error
+		if converted, err = v.convert(value); err != nil {
+			return err
+		}
+	}
+
+	if err := v.target.Value.Set(converted); err != nil {
+		return err
+	}
+	v.target.Changed = true
+	v.value = value
+
+	return nil
+}
+
+func (v *migratedValue) Type() string {
+	return v.target.Value.Type()
+}
+
+// migrateDeprecatedFlags maps the deprecated configuration items, set in the configuration
+// file or the environment variables, forward to their replacement. The deprecated flags set
+// on the command line are mapped by their parsing.
+func migrateDeprecatedFlags(fs *pflag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(flag *pflag.Flag) {
+		replacement, ok := flag.Annotations[replacementAnnotation]
+		if !ok || flag.Changed || err != nil || !viper.IsSet(flag.Name) {
+			return
+		}
+
+		newName := replacement[0]
+		if viper.IsSet(newName) {
+			log.Warnf("Configuration item %s is deprecated and ignored, as %s is set", flag.Name, newName)
+
+			return
+		}
+
+		log.Warnf("Configuration item %s is deprecated, use %s instead", flag.Name, newName)
+		if err = flag.Value.Set(configString(viper.Get(flag.Name))); err != nil {
+			err = fmt.Errorf("invalid value of the deprecated configuration item %s: %w", flag.Name, err)
+
+			return
+		}
+		migratedFrom[newName] = flag.Name
+	})
+
+	return err
+}
+
+// configString formats a configuration value the way it is set on the command line.
+func configString(value interface{}) string {
+	if values, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(values))
+		for _, v := range values {
+			items = append(items, fmt.Sprint(v))
+		}
+
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(value)
+}
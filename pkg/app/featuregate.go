@@ -0,0 +1,54 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const flagFeatureGates = "feature-gates"
+
+// applyFeatureGates sets the feature gate from the configuration file or the environment
+// variable, the --feature-gates flag is already set by the command line parsing.
+func (a *App) applyFeatureGates(cmd *cobra.Command) error {
+	if a.featureGate == nil || cmd.Flags().Changed(flagFeatureGates) {
+		return nil
+	}
+
+	return a.setFeatureGates()
+}
+
+// reloadFeatureGates sets the feature gate again when the configuration file changes, unless
+// the --feature-gates flag overrides it. The features removed from the configuration file
+// keep their value until the next restart.
+func (a *App) reloadFeatureGates(opts CliOptions) error {
+	if a.cmd.Flags().Changed(flagFeatureGates) {
+		return nil
+	}
+
+	return a.setFeatureGates()
+}
+
+// setFeatureGates sets the feature gate from the configuration file or the environment variable.
+func (a *App) setFeatureGates() error {
+	switch value := viper.Get(flagFeatureGates).(type) {
+	case nil:
+		return nil
+	case string:
+		return a.featureGate.Set(value)
+	case map[string]interface{}:
+		settings := make(map[string]string, len(value))
+		for name, enabled := range value {
+			settings[name] = fmt.Sprint(enabled)
+		}
+
+		return a.featureGate.SetFromMap(settings)
+	default:
+		return fmt.Errorf("invalid %s value %v, needs a map of feature names to bools", flagFeatureGates, value)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/marmotedu/iam/pkg/log"
+	"github.com/marmotedu/iam/pkg/util/secretref"
+)
+
+// Reloader applies a configuration item changed at runtime, read from the reloaded options.
+type Reloader func(opts CliOptions) error
+
+// WithReloaders sets the configuration items, by key, which are applied at runtime when the
+// configuration file changes. A key can also be a section, e.g. rate-limit, whose reloader
+// applies the changes of all its items. The log level and the feature gates are always
+// applied at runtime, the changes of the other configuration items take effect on the next
+// restart.
+func WithReloaders(reloaders map[string]Reloader) Option {
+	return func(a *App) {
+		a.reloaders = reloaders
+	}
+}
+
+const flagLogLevel = "log.level"
+
+// reloadLogLevel applies the log level of the configuration file.
+func reloadLogLevel(opts CliOptions) error {
+	return log.SetLevel(viper.GetString(flagLogLevel))
+}
+
+// defaultReloaders returns the reloaders of the configuration items shared by the
+// applications, they are overridden by the reloaders of the application.
+func (a *App) defaultReloaders() map[string]Reloader {
+	reloaders := map[string]Reloader{
+		flagLogLevel: reloadLogLevel,
+	}
+	if a.featureGate != nil {
+		reloaders[flagFeatureGates] = a.reloadFeatureGates
+	}
+
+	for key, reload := range a.reloaders {
+		reloaders[key] = reload
+	}
+
+	return reloaders
+}
+
+// watchConfig watches the configuration file and applies the changed configuration items
+// which have a reloader.
+func (a *App) watchConfig() {
+	reloaders := a.defaultReloaders()
+	settings := configSettings()
+
+	// viper calls back from a single goroutine, settings needs no lock
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		if err := secretref.ResolveConfig(viper.GetViper()); err != nil {
+			log.Errorf("%v Failed to resolve the secrets of config file `%s`: %s", progressMessage, e.Name, err.Error())
+
+			return
+		}
+
+		current := configSettings()
+		changed := changedSettings(settings, current)
+		settings = current
+		if len(changed) == 0 {
+			return
+		}
+
+		// unmarshal into fresh options, the running ones are read concurrently
+		opts, _ := reflect.New(reflect.TypeOf(a.options).Elem()).Interface().(CliOptions)
+		if err := viper.Unmarshal(opts); err != nil {
+			log.Errorf("%v Failed to reload config file `%s`: %s", progressMessage, e.Name, err.Error())
+
+			return
+		}
+
+		var applied, restart []string
+		results := map[string]error{}
+		for _, key := range changed {
+			name, ok := reloaderKey(reloaders, key)
+			if !ok {
+				restart = append(restart, key)
+
+				continue
+			}
+
+			// a section is reloaded once for all its changed items
+			err, done := results[name]
+			if !done {
+				err = reloaders[name](opts)
+				results[name] = err
+				if err != nil {
+					log.Errorf("%v Failed to apply config item `%s`: %s", progressMessage, name, err.Error())
+				}
+			}
+			if err != nil {
+				continue
+			}
+			applied = append(applied, key)
+		}
+
+		log.Infof("%v Config file `%s` changed, applied: %v", progressMessage, e.Name, applied)
+		if len(restart) > 0 {
+			log.Warnf("%v Config items changed but require a restart: %v", progressMessage, restart)
+		}
+	})
+	viper.WatchConfig()
+}
+
+// reloaderKey returns the key of the reloader of the configuration item, the item itself or
+// the closest section containing it.
+func reloaderKey(reloaders map[string]Reloader, key string) (string, bool) {
+	for name := key; ; {
+		if _, ok := reloaders[name]; ok {
+			return name, true
+		}
+
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return "", false
+		}
+		name = name[:i]
+	}
+}
+
+// configSettings returns the value of every configuration item, by key.
+func configSettings() map[string]string {
+	settings := make(map[string]string)
+	for _, key := range viper.AllKeys() {
+		settings[key] = fmt.Sprint(viper.Get(key))
+	}
+
+	return settings
+}
+
+// changedSettings returns the sorted keys of the configuration items added, removed or
+// changed from before to after.
+func changedSettings(before, after map[string]string) []string {
+	var changed []string
+	for key, value := range after {
+		if old, ok := before[key]; !ok || old != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
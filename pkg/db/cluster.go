@@ -0,0 +1,273 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// Roles of the hosts of a primary/replica deployment.
+const (
+	// RolePrimary is a host the writes are sent to, the first writable one is used.
+	RolePrimary = "primary"
+
+	// RoleReplica is a host the reads are spread over, which may be promoted to primary.
+	RoleReplica = "replica"
+)
+
+// Host is a host of a primary/replica deployment.
+type Host struct {
+	Role string
+	Addr string
+}
+
+// ParseHost parses a host in the format role=host:port.
+func ParseHost(s string) (Host, error) {
+	role, addr, ok := strings.Cut(s, "=")
+	if !ok || addr == "" {
+		return Host{}, fmt.Errorf("mysql host %q is not in the format role=host:port", s)
+	}
+
+	if role != RolePrimary && role != RoleReplica {
+		return Host{}, fmt.Errorf("mysql host %q has an unknown role, it must be %s or %s", s, RolePrimary, RoleReplica)
+	}
+
+	return Host{Role: role, Addr: addr}, nil
+}
+
+type node struct {
+	Host
+	db *sql.DB
+	// pool runs the statements, it caches the prepared statements if required
+	pool    gorm.ConnPool
+	healthy int32
+}
+
+// cluster is the connection pool of a primary/replica deployment. The writes and the
+// transactions are sent to the primary, which is the first writable host found by the prober,
+// so that the writes fail over to a replica promoted by a planned failover without restart.
+// The reads are spread over the healthy replicas, they may lag behind the primary.
+type cluster struct {
+	nodes   []*node
+	primary int32
+	next    uint32
+	stop    chan struct{}
+	once    sync.Once
+}
+
+var _ gorm.ConnPool = &cluster{}
+
+// newCluster creates a gorm db instance of the hosts of the options.
+func newCluster(opts *Options) (*gorm.DB, error) {
+	c := &cluster{primary: -1, stop: make(chan struct{})}
+
+	for _, h := range opts.Hosts {
+		host, err := ParseHost(h)
+		if err != nil {
+			_ = c.Close()
+
+			return nil, err
+		}
+
+		o := *opts
+		o.Host = host.Addr
+		dsn, err := o.DSN()
+		if err != nil {
+			_ = c.Close()
+
+			return nil, err
+		}
+
+		sqlDB, err := sql.Open("mysql", dsn)
+		if err != nil {
+			_ = c.Close()
+
+			return nil, err
+		}
+
+		setPool(sqlDB, opts)
+
+		n := &node{Host: host, db: sqlDB, pool: sqlDB}
+		if opts.PrepareStatement {
+			n.pool = &gorm.PreparedStmtDB{
+				ConnPool:    sqlDB,
+				Stmts:       map[string]gorm.Stmt{},
+				Mux:         &sync.RWMutex{},
+				PreparedSQL: make([]string, 0, 100),
+			}
+		}
+
+		c.nodes = append(c.nodes, n)
+		if c.primary < 0 && host.Role == RolePrimary {
+			c.primary = int32(len(c.nodes) - 1)
+		}
+	}
+
+	if c.primary < 0 {
+		_ = c.Close()
+
+		return nil, fmt.Errorf("no %s mysql host", RolePrimary)
+	}
+
+	interval := opts.ProbeInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	// the primary may have been failed over before the startup
+	c.probe(interval)
+	go c.run(interval)
+
+	// the statements are prepared by the hosts, as they are not shared
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: c}), &gorm.Config{Logger: opts.Logger})
+	if err != nil {
+		_ = c.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (c *cluster) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.probe(interval)
+		}
+	}
+}
+
+// probe checks the health of the hosts, and fails the primary over to the first writable host
+// if it is no longer writable.
+func (c *cluster) probe(timeout time.Duration) {
+	writable := make([]bool, len(c.nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range c.nodes {
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			var readOnly bool
+			if err := n.db.QueryRowContext(ctx, "SELECT @@global.read_only").Scan(&readOnly); err != nil {
+				if atomic.SwapInt32(&n.healthy, 0) == 1 {
+					log.Warnf("Mysql host %s is unhealthy: %s", n.Addr, err.Error())
+				}
+
+				return
+			}
+
+			if atomic.SwapInt32(&n.healthy, 1) == 0 {
+				log.Infof("Mysql host %s is healthy", n.Addr)
+			}
+
+			writable[i] = !readOnly
+		}(i, n)
+	}
+	wg.Wait()
+
+	current := atomic.LoadInt32(&c.primary)
+	if writable[current] {
+		return
+	}
+
+	// the configured primaries are preferred to the promoted replicas
+	for _, role := range []string{RolePrimary, RoleReplica} {
+		for i, n := range c.nodes {
+			if n.Role == role && writable[i] {
+				log.Warnf("Mysql primary fails over from %s to %s", c.nodes[current].Addr, n.Addr)
+				atomic.StoreInt32(&c.primary, int32(i))
+
+				return
+			}
+		}
+	}
+
+	log.Warnf("No writable mysql host is found, the writes are still sent to %s", c.nodes[current].Addr)
+}
+
+func (c *cluster) writer() *node {
+	return c.nodes[atomic.LoadInt32(&c.primary)]
+}
+
+// reader returns the next healthy replica, or the primary if there is none.
+func (c *cluster) reader() *node {
+	primary := int(atomic.LoadInt32(&c.primary))
+	next := int(atomic.AddUint32(&c.next, 1))
+
+	for i := range c.nodes {
+		j := (next + i) % len(c.nodes)
+		n := c.nodes[j]
+		if j != primary && n.Role == RoleReplica && atomic.LoadInt32(&n.healthy) == 1 {
+			return n
+		}
+	}
+
+	return c.nodes[primary]
+}
+
+func (c *cluster) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.writer().pool.PrepareContext(ctx, query)
+}
+
+func (c *cluster) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.writer().pool.ExecContext(ctx, query, args...)
+}
+
+func (c *cluster) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.reader().pool.QueryContext(ctx, query, args...)
+}
+
+func (c *cluster) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.reader().pool.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx begins a transaction on the primary.
+func (c *cluster) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.writer().db.BeginTx(ctx, opts)
+}
+
+// GetDBConn returns the connection pool of the primary.
+func (c *cluster) GetDBConn() (*sql.DB, error) {
+	return c.writer().db, nil
+}
+
+func (c *cluster) Ping() error {
+	return c.writer().db.Ping()
+}
+
+// Close stops the prober and closes the connections of all the hosts.
+func (c *cluster) Close() error {
+	c.once.Do(func() { close(c.stop) })
+
+	var lastErr error
+	for _, n := range c.nodes {
+		if err := n.db.Close(); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
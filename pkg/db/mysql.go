@@ -5,9 +5,10 @@
 package db
 
 import (
-	"fmt"
+	"database/sql"
 	"time"
 
+	driver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -22,22 +23,51 @@ type Options struct {
 	MaxIdleConnections    int
 	MaxOpenConnections    int
 	MaxConnectionLifeTime time.Duration
+	PrepareStatement      bool
 	LogLevel              int
 	Logger                logger.Interface
+
+	// TLSMode is one of TLSDisable, TLSSkipVerify, TLSVerifyCA and TLSVerifyIdentity, TLSCAFile
+	// is the certificate authority verifying the server, the system ones are used if empty.
+	TLSMode   string
+	TLSCAFile string
+
+	// Charset and Loc are the charset of the connection and the location of the time values,
+	// utf8 and Local if empty.
+	Charset string
+	Loc     string
+
+	// ConnectTimeout, ReadTimeout and WriteTimeout are the timeouts of the connection, there is
+	// none if they are 0.
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+
+	// Params are the additional parameters of the connection, such as the system variables.
+	Params map[string]string
+
+	// Hosts are the hosts of a primary/replica deployment in the format role=host:port, Host is
+	// ignored if they are set. See ParseHost.
+	Hosts []string
+
+	// ProbeInterval is the interval the hosts are probed at, to find the writable primary.
+	ProbeInterval time.Duration
 }
 
 // New create a new gorm db instance with the given options.
 func New(opts *Options) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(`%s:%s@tcp(%s)/%s?charset=utf8&parseTime=%t&loc=%s`,
-		opts.Username,
-		opts.Password,
-		opts.Host,
-		opts.Database,
-		true,
-		"Local")
+	if len(opts.Hosts) > 0 {
+		return newCluster(opts)
+	}
+
+	dsn, err := opts.DSN()
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: opts.Logger,
+		Logger:      opts.Logger,
+		PrepareStmt: opts.PrepareStatement,
 	})
 	if err != nil {
 		return nil, err
@@ -48,6 +78,26 @@ func New(opts *Options) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	setPool(sqlDB, opts)
+
+	return db, nil
+}
+
+// Close closes the connections of the db instance created by New.
+func Close(db *gorm.DB) error {
+	if c, ok := db.ConnPool.(*cluster); ok {
+		return c.Close()
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+func setPool(sqlDB *sql.DB, opts *Options) {
 	// SetMaxOpenConns sets the maximum number of open connections to the database.
 	sqlDB.SetMaxOpenConns(opts.MaxOpenConnections)
 
@@ -56,6 +106,44 @@ func New(opts *Options) (*gorm.DB, error) {
 
 	// SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
 	sqlDB.SetMaxIdleConns(opts.MaxIdleConnections)
+}
 
-	return db, nil
+// DSN returns the data source name of the database.
+func (opts *Options) DSN() (string, error) {
+	cfg := driver.NewConfig()
+	cfg.User = opts.Username
+	cfg.Passwd = opts.Password
+	cfg.Net = "tcp"
+	cfg.Addr = opts.Host
+	cfg.DBName = opts.Database
+	cfg.ParseTime = true
+	cfg.Timeout = opts.ConnectTimeout
+	cfg.ReadTimeout = opts.ReadTimeout
+	cfg.WriteTimeout = opts.WriteTimeout
+
+	loc := opts.Loc
+	if loc == "" {
+		loc = "Local"
+	}
+
+	var err error
+	if cfg.Loc, err = time.LoadLocation(loc); err != nil {
+		return "", err
+	}
+
+	charset := opts.Charset
+	if charset == "" {
+		charset = "utf8"
+	}
+
+	cfg.Params = map[string]string{"charset": charset}
+	for k, v := range opts.Params {
+		cfg.Params[k] = v
+	}
+
+	if cfg.TLSConfig, err = registerTLSConfig(opts.Host, opts.TLSMode, opts.TLSCAFile); err != nil {
+		return "", err
+	}
+
+	return cfg.FormatDSN(), nil
 }
@@ -0,0 +1,45 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	// register the sqlite driver written in pure go, which needs no cgo.
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLite create a new gorm db instance of the SQLite database in the given file, or in
+// memory if the file is empty. It is meant for the development and the tests, e.g. by the
+// all-in-one iam command.
+func NewSQLite(file string, logger logger.Interface) (*gorm.DB, error) {
+	dsn := "file::memory:"
+	if file != "" {
+		dsn = "file:" + file
+	}
+
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{
+		Logger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// the statements are run one after another on a single connection which is never closed,
+	// so that the database in memory lives as long as the db instance, and that the writes
+	// never fail for a database locked by another connection.
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+
+	return db, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// TLS modes of the connection to the database.
+const (
+	// TLSDisable connects without TLS.
+	TLSDisable = "disable"
+
+	// TLSSkipVerify encrypts the connection without verifying the server certificate.
+	TLSSkipVerify = "skip-verify"
+
+	// TLSVerifyCA verifies the server certificate is signed by the certificate authority.
+	TLSVerifyCA = "verify-ca"
+
+	// TLSVerifyIdentity verifies the server certificate along with the host name of the server.
+	TLSVerifyIdentity = "verify-identity"
+)
+
+// TLSModes are the supported TLS modes.
+var TLSModes = []string{TLSDisable, TLSSkipVerify, TLSVerifyCA, TLSVerifyIdentity}
+
+// registerTLSConfig registers the TLS configuration of the mode to the mysql driver, and returns
+// its name in the DSN.
+func registerTLSConfig(host, mode, caFile string) (string, error) {
+	switch mode {
+	case "", TLSDisable:
+		return "", nil
+	case TLSSkipVerify:
+		return TLSSkipVerify, nil
+	case TLSVerifyCA, TLSVerifyIdentity:
+	default:
+		return "", fmt.Errorf("unknown mysql tls mode %q", mode)
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return "", err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return "", fmt.Errorf("no certificate found in %s", caFile)
+		}
+	}
+
+	if mode == TLSVerifyCA {
+		// the chain is verified without the host name
+		config.InsecureSkipVerify = true // nolint: gosec
+		config.VerifyPeerCertificate = verifyChain(config.RootCAs)
+	}
+
+	// the configurations of the different servers are registered separately
+	name := fmt.Sprintf("iam-%s-%s", mode, host)
+	if err := driver.RegisterTLSConfig(name, config); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// verifyChain returns a function verifying the certificate chain of the server against roots.
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+
+			certs = append(certs, cert)
+		}
+
+		if len(certs) == 0 {
+			return fmt.Errorf("no certificate presented by the mysql server")
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+
+		return err
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package featuregate implements feature gates, which turn the features on and off by
+// name, e.g. --feature-gates=DecisionCache=false, so that they can ship disabled until
+// they are ready.
+package featuregate // import "github.com/marmotedu/iam/pkg/featuregate"
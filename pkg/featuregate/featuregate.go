@@ -0,0 +1,234 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+	// Alpha features are disabled by default, and may change or go away.
+	Alpha = Stage("ALPHA")
+	// Beta features are usually enabled by default, and are well tested.
+	Beta = Stage("BETA")
+	// GA features are always enabled, the gate is kept for one release.
+	GA = Stage("GA")
+	// Deprecated features are going away.
+	Deprecated = Stage("DEPRECATED")
+)
+
+// FeatureSpec describes a feature gate.
+type FeatureSpec struct {
+	Default    bool
+	PreRelease Stage
+	// LockToDefault forbids to change the default, e.g. for the GA features.
+	LockToDefault bool
+}
+
+// FeatureStatus describes the state of a feature gate.
+type FeatureStatus struct {
+	Name    Feature `json:"name"`
+	Stage   Stage   `json:"stage"`
+	Default bool    `json:"default"`
+	Enabled bool    `json:"enabled"`
+}
+
+// FeatureGate holds the known features and which of them are enabled. It implements
+// pflag.Value, the value being a comma separated list of Feature=bool.
+type FeatureGate struct {
+	lock    sync.RWMutex
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+var _ pflag.Value = &FeatureGate{}
+
+// DefaultFeatureGate is the feature gate shared by the components, the features are added
+// to it by the packages defining them.
+var DefaultFeatureGate = NewFeatureGate()
+
+// NewFeatureGate creates a feature gate without known features.
+func NewFeatureGate() *FeatureGate {
+	return &FeatureGate{
+		known:   map[Feature]FeatureSpec{},
+		enabled: map[Feature]bool{},
+	}
+}
+
+// Add adds the features to the known features, a feature can not be redefined.
+func (f *FeatureGate) Add(features map[Feature]FeatureSpec) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for name, spec := range features {
+		if existing, ok := f.known[name]; ok && existing != spec {
+			return fmt.Errorf("feature gate %s is already defined with a different spec", name)
+		}
+		f.known[name] = spec
+	}
+
+	return nil
+}
+
+// Enabled returns whether the feature is enabled, it panics if the feature is unknown.
+func (f *FeatureGate) Enabled(name Feature) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if enabled, ok := f.enabled[name]; ok {
+		return enabled
+	}
+
+	spec, ok := f.known[name]
+	if !ok {
+		panic(fmt.Sprintf("feature gate %s is unknown", name))
+	}
+
+	return spec.Default
+}
+
+// Set parses a comma separated list of Feature=bool and sets the features, e.g.
+// DecisionCache=false,I18nErrorMessages=true.
+func (f *FeatureGate) Set(value string) error {
+	settings := map[string]string{}
+	for _, setting := range strings.Split(value, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+
+		kv := strings.SplitN(setting, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("missing bool value for feature gate %s", setting)
+		}
+		settings[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return f.SetFromMap(settings)
+}
+
+// SetFromMap sets the features from a map of feature names to bool values. The names are
+// matched case insensitively, as the keys of the configuration files are lowercased.
+func (f *FeatureGate) SetFromMap(settings map[string]string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	enabled := make(map[Feature]bool, len(f.enabled))
+	for name, value := range f.enabled {
+		enabled[name] = value
+	}
+
+	for key, value := range settings {
+		name, ok := f.lookup(key)
+		if !ok {
+			return fmt.Errorf("unrecognized feature gate: %s", key)
+		}
+
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %s=%s, needs a bool", key, value)
+		}
+
+		if spec := f.known[name]; spec.LockToDefault && spec.Default != boolValue {
+			return fmt.Errorf("cannot set feature gate %s to %v, feature is locked to %v", name, boolValue, spec.Default)
+		}
+		enabled[name] = boolValue
+	}
+	f.enabled = enabled
+
+	return nil
+}
+
+// lookup returns the known feature matching the key, case insensitively.
+func (f *FeatureGate) lookup(key string) (Feature, bool) {
+	if _, ok := f.known[Feature(key)]; ok {
+		return Feature(key), true
+	}
+
+	for name := range f.known {
+		if strings.EqualFold(string(name), key) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// String returns the features explicitly set, in the format parsed by Set.
+func (f *FeatureGate) String() string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	settings := make([]string, 0, len(f.enabled))
+	for name, enabled := range f.enabled {
+		settings = append(settings, fmt.Sprintf("%s=%t", name, enabled))
+	}
+	sort.Strings(settings)
+
+	return strings.Join(settings, ",")
+}
+
+// Type implements pflag.Value.
+func (f *FeatureGate) Type() string {
+	return "mapStringBool"
+}
+
+// KnownFeatures returns the description of every known feature, sorted by name, for the
+// usage of the flag.
+func (f *FeatureGate) KnownFeatures() []string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	known := make([]string, 0, len(f.known))
+	for name, spec := range f.known {
+		known = append(known, fmt.Sprintf("%s=true|false (%s - default=%t)", name, spec.PreRelease, spec.Default))
+	}
+	sort.Strings(known)
+
+	return known
+}
+
+// Status returns the state of every known feature, sorted by name.
+func (f *FeatureGate) Status() []FeatureStatus {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	status := make([]FeatureStatus, 0, len(f.known))
+	for name, spec := range f.known {
+		enabled, ok := f.enabled[name]
+		if !ok {
+			enabled = spec.Default
+		}
+
+		status = append(status, FeatureStatus{
+			Name:    name,
+			Stage:   spec.PreRelease,
+			Default: spec.Default,
+			Enabled: enabled,
+		})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Name < status[j].Name })
+
+	return status
+}
+
+// AddFlag adds the --feature-gates flag to the specified FlagSet.
+func (f *FeatureGate) AddFlag(fs *pflag.FlagSet) {
+	fs.Var(f, "feature-gates", ""+
+		"A set of key=value pairs that describe feature gates for alpha/experimental features. "+
+		"Options are:\n"+strings.Join(f.KnownFeatures(), "\n"))
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package featuregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	alphaFeature Feature = "AlphaFeature"
+	betaFeature  Feature = "BetaFeature"
+	gaFeature    Feature = "GAFeature"
+)
+
+func newTestFeatureGate(t *testing.T) *FeatureGate {
+	f := NewFeatureGate()
+	assert.Nil(t, f.Add(map[Feature]FeatureSpec{
+		alphaFeature: {Default: false, PreRelease: Alpha},
+		betaFeature:  {Default: true, PreRelease: Beta},
+		gaFeature:    {Default: true, PreRelease: GA, LockToDefault: true},
+	}))
+
+	return f
+}
+
+func TestFeatureGate_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[Feature]bool
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			value: "",
+			want:  map[Feature]bool{alphaFeature: false, betaFeature: true, gaFeature: true},
+		},
+		{
+			name:  "set",
+			value: "AlphaFeature=true, BetaFeature=false",
+			want:  map[Feature]bool{alphaFeature: true, betaFeature: false, gaFeature: true},
+		},
+		{
+			name:  "case insensitive",
+			value: "alphafeature=true",
+			want:  map[Feature]bool{alphaFeature: true, betaFeature: true, gaFeature: true},
+		},
+		{name: "unknown", value: "UnknownFeature=true", wantErr: true},
+		{name: "invalid value", value: "AlphaFeature=maybe", wantErr: true},
+		{name: "missing value", value: "AlphaFeature", wantErr: true},
+		{name: "locked", value: "GAFeature=false", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFeatureGate(t)
+
+			err := f.Set(tt.value)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			for name, want := range tt.want {
+				assert.Equal(t, want, f.Enabled(name), name)
+			}
+		})
+	}
+}
+
+func TestFeatureGate_Status(t *testing.T) {
+	f := newTestFeatureGate(t)
+	assert.Nil(t, f.Set("AlphaFeature=true"))
+
+	assert.Equal(t, []FeatureStatus{
+		{Name: alphaFeature, Stage: Alpha, Default: false, Enabled: true},
+		{Name: betaFeature, Stage: Beta, Default: true, Enabled: true},
+		{Name: gaFeature, Stage: GA, Default: true, Enabled: true},
+	}, f.Status())
+	assert.Equal(t, "AlphaFeature=true", f.String())
+}
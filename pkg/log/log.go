@@ -146,6 +146,8 @@ type zapLogger struct {
 	// deals with our desire to have multiple verbosity levels.
 	zapLogger *zap.Logger
 	infoLogger
+	// level is the minimum enabled level, it can be changed at runtime.
+	level zap.AtomicLevel
 }
 
 // handleFields converts a bunch of arbitrary key-value pairs into Zap fields.  It takes
@@ -211,6 +213,21 @@ func Init(opts *Options) {
 	std = New(opts)
 }
 
+// SetLevel changes the minimum enabled level of the global logger, e.g. when the
+// configuration is reloaded.
+func SetLevel(text string) error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	std.level.SetLevel(level)
+
+	return nil
+}
+
 // New create logger by opts which can custmoized by command arguments.
 func New(opts *Options) *zapLogger {
 	if opts == nil {
@@ -241,8 +258,9 @@ func New(opts *Options) *zapLogger {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
+	level := zap.NewAtomicLevelAt(zapLevel)
 	loggerConfig := &zap.Config{
-		Level:             zap.NewAtomicLevelAt(zapLevel),
+		Level:             level,
 		Development:       opts.Development,
 		DisableCaller:     opts.DisableCaller,
 		DisableStacktrace: opts.DisableStacktrace,
@@ -267,6 +285,7 @@ func New(opts *Options) *zapLogger {
 			log:   l,
 			level: zap.InfoLevel,
 		},
+		level: level,
 	}
 	klog.InitLogger(l)
 	zap.RedirectStdLog(l)
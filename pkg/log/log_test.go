@@ -60,3 +60,15 @@ func Test_Option(t *testing.T) {
 
 	assert.Equal(t, "debug", opt.Level)
 }
+
+func Test_SetLevel(t *testing.T) {
+	defer log.Flush() // used for record logger printer
+
+	assert.Nil(t, log.SetLevel("error"))
+	assert.Nil(t, log.ZapLogger().Check(log.InfoLevel, "Hello world!"))
+
+	assert.Nil(t, log.SetLevel("debug"))
+	assert.NotNil(t, log.ZapLogger().Check(log.InfoLevel, "Hello world!"))
+
+	assert.NotNil(t, log.SetLevel("verbose"))
+}
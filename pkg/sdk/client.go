@@ -0,0 +1,127 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+	authzv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/authz/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/marmotedu/iam/pkg/sdk/rpc"
+)
+
+// Transports of the iam-apiserver clients.
+const (
+	TransportREST = "rest"
+	TransportGRPC = "grpc"
+)
+
+// Config is the configuration of an iam client.
+type Config struct {
+	*rest.Config
+
+	// Transport of the iam-apiserver clients, rest or grpc, defaults to rest. The
+	// iam-authz-server client always uses rest.
+	Transport string
+	// GRPCAddress is the address of the grpc server of iam-apiserver, e.g. 127.0.0.1:8081.
+	GRPCAddress string
+
+	// QPS and Burst limit the requests of all the clients together, e.g. for the batch jobs
+	// which must not trip the rate limit of the servers. A zero QPS does not limit them. The
+	// rest clients are wrapped, see Wrap.
+	QPS   float64
+	Burst int
+
+	// SystemRoots trusts the system roots in addition to the CA bundle of the config.
+	SystemRoots bool
+	// ProxyURL is the proxy of the rest clients, e.g. http://proxy.example.com:3128, the proxy of
+	// the environment variables is used when it is empty. The grpc transport always uses the
+	// proxy of the environment variables.
+	ProxyURL string
+}
+
+var _ iam.IamInterface = &IAMClient{}
+
+// IAMClient is the iam client with the iam-apiserver clients of the configured transport.
+type IAMClient struct {
+	apiV1   apiv1.APIV1Interface
+	authzV1 authzv1.AuthzV1Interface
+	conn    *grpc.ClientConn
+}
+
+// NewIAMClient creates an iam client for the config.
+func NewIAMClient(c *Config) (*IAMClient, error) {
+	rc, err := iam.NewForConfig(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ConfigureTransport(rc.APIV1().RESTClient(), c); err != nil {
+		return nil, err
+	}
+	if err := ConfigureTransport(rc.AuthzV1().RESTClient(), c); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := TLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialOpts []grpc.DialOption
+	if tlsConfig != nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+	if c.QPS > 0 {
+		limiter := NewRateLimiter(c.QPS, c.Burst)
+		if err := WrapIAMClient(rc, RateLimit(limiter)); err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithUnaryInterceptor(RateLimitInterceptor(limiter)))
+	}
+
+	client := &IAMClient{apiV1: rc.APIV1(), authzV1: rc.AuthzV1()}
+
+	switch c.Transport {
+	case "", TransportREST:
+	case TransportGRPC:
+		if c.GRPCAddress == "" {
+			return nil, fmt.Errorf("grpc address is required by the grpc transport")
+		}
+
+		client.apiV1, client.conn, err = rpc.NewForConfig(c.GRPCAddress, c.Config, dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transport %q, must be %s or %s", c.Transport, TransportREST, TransportGRPC)
+	}
+
+	return client, nil
+}
+
+// APIV1 returns the iam-apiserver clients.
+func (c *IAMClient) APIV1() apiv1.APIV1Interface {
+	return c.apiV1
+}
+
+// AuthzV1 returns the iam-authz-server client.
+func (c *IAMClient) AuthzV1() authzv1.AuthzV1Interface {
+	return c.authzV1
+}
+
+// Close closes the grpc connection of the client.
+func (c *IAMClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
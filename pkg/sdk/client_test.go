@@ -0,0 +1,56 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"testing"
+
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+
+	"github.com/marmotedu/iam/pkg/sdk/rpc"
+)
+
+func TestNewIAMClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		wantGRPC bool
+		wantErr  bool
+	}{
+		{name: "rest", config: &Config{Config: &rest.Config{Host: "127.0.0.1:8080"}}, wantGRPC: false},
+		{
+			name:     "grpc",
+			config:   &Config{Config: &rest.Config{Host: "127.0.0.1:8080"}, Transport: TransportGRPC, GRPCAddress: "127.0.0.1:8081"},
+			wantGRPC: true,
+		},
+		{
+			name:    "grpc without address",
+			config:  &Config{Config: &rest.Config{Host: "127.0.0.1:8080"}, Transport: TransportGRPC},
+			wantErr: true,
+		},
+		{
+			name:     "rate limited",
+			config:   &Config{Config: &rest.Config{Host: "127.0.0.1:8080"}, Transport: TransportGRPC, GRPCAddress: "127.0.0.1:8081", QPS: 10},
+			wantGRPC: true,
+		},
+		{name: "unsupported", config: &Config{Config: &rest.Config{Host: "127.0.0.1:8080"}, Transport: "soap"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewIAMClient(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIAMClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer client.Close()
+
+			if _, ok := client.APIV1().(*rpc.APIV1Client); ok != tt.wantGRPC {
+				t.Errorf("NewIAMClient() grpc transport = %v, want %v", ok, tt.wantGRPC)
+			}
+		})
+	}
+}
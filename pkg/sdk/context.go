@@ -0,0 +1,93 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/marmotedu/marmotedu-sdk-go/third_party/forked/gorequest"
+)
+
+// agentContext returns the context of the last rest.Request.Do call of the agent. The forked
+// gorequest drops the request returned by http.Request.WithContext, so the requests are sent
+// with the background context and the cancellation of the callers is ignored.
+func agentContext(agent *gorequest.SuperAgent) context.Context {
+	field := reflect.ValueOf(agent).Elem().FieldByName("ctx")
+	if !field.IsValid() || field.Kind() != reflect.Interface {
+		return nil
+	}
+
+	// nolint: gosec
+	ctx, _ := reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface().(context.Context)
+
+	return ctx
+}
+
+// contextTransport sends the requests of the agent with the context of the caller.
+type contextTransport struct {
+	agent *gorequest.SuperAgent
+	base  http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ctx := agentContext(t.agent); ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// Timeout returns a middleware which cancels the requests after d, unless the context of the
+// call has an earlier deadline. It bounds each call, while the timeout of rest.Config bounds
+// all the attempts of a call together.
+func Timeout(d time.Duration) Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &timeoutTransport{timeout: d, base: base}
+	}
+}
+
+type timeoutTransport struct {
+	timeout time.Duration
+	base    http.RoundTripper
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) <= t.timeout {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	// the body is read after RoundTrip returns, the context is released when it is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
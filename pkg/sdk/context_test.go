@@ -0,0 +1,117 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+)
+
+func TestWrap_Context(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" || r.URL.Path != "/v1/users/colin" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"name":"colin"}}`))
+	}))
+	defer server.Close()
+
+	client, err := apiv1.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WrapInterface(client.RESTClient(), Timeout(500*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		user    string
+		wantErr bool
+		// maxElapsed tells the cancellation of the caller from the default timeout
+		maxElapsed time.Duration
+	}{
+		{
+			name:       "fast call",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			user:       "colin",
+			wantErr:    false,
+			maxElapsed: 400 * time.Millisecond,
+		},
+		{
+			name: "canceled by the caller",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			user:       "slow",
+			wantErr:    true,
+			maxElapsed: 400 * time.Millisecond,
+		},
+		{
+			name:       "default timeout",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			user:       "slow",
+			wantErr:    true,
+			maxElapsed: 2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			start := time.Now()
+			_, err := client.Users().Get(ctx, tt.user, metav1.GetOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxElapsed {
+				t.Errorf("Get() returns after %s, want at most %s", elapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
+
+func TestTimeout_DeadlineOfCaller(t *testing.T) {
+	var got time.Duration
+	rt := Timeout(time.Minute)(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		deadline, _ := req.Context().Deadline()
+		got = time.Until(deadline)
+
+		return nil, errors.New("sent")
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1", nil)
+	_, _ = rt.RoundTrip(req)
+	if got > time.Second {
+		t.Errorf("Timeout() overrides the earlier deadline of the caller, got %s", got)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
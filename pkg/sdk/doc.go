@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package sdk contains the extensions of the marmotedu-sdk-go clients, such as request
+// signing, which are maintained together with the iam servers. The extensions are middlewares
+// of the client transport, installed by Wrap, and the hooks of Instrument let the callers
+// observe the iam calls without writing one.
+package sdk // import "github.com/marmotedu/iam/pkg/sdk"
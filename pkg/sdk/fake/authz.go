@@ -0,0 +1,39 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+)
+
+// DeniedReason is the reason of the authorization when no reactor handles it.
+const DeniedReason = "Request is denied by the fake authorizer"
+
+// authz implements authzv1.AuthzInterface, the decisions are made by the reactors of the
+// authorize verb, the requests are denied otherwise.
+type authz struct {
+	*Clientset
+}
+
+func (c *authz) Authorize(ctx context.Context, request *ladon.Request,
+	opts metav1.AuthorizeOptions) (*authzv1.Response, error) {
+	action := Action{Verb: VerbAuthorize, Resource: ResourceAuthz, Object: request}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*authzv1.Response)
+
+		return result, err
+	}
+
+	return &authzv1.Response{Denied: true, Reason: DeniedReason}, nil
+}
+
+// AllowAll is a reactor of the authorize verb which allows all the requests.
+func AllowAll(action Action) (bool, interface{}, error) {
+	return true, &authzv1.Response{Allowed: true}, nil
+}
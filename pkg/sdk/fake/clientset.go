@@ -0,0 +1,116 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"fmt"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/marmotedu"
+	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+	authzv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/authz/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+)
+
+var (
+	_ marmotedu.Interface      = &Clientset{}
+	_ iam.IamInterface         = &Clientset{}
+	_ apiv1.APIV1Interface     = &apiV1{}
+	_ authzv1.AuthzV1Interface = &authzV1{}
+)
+
+// Clientset implements the sdk clientset with an ObjectTracker. It is both the marmotedu and
+// the iam clientset, so it can replace either of them.
+type Clientset struct {
+	Fake
+
+	tracker *ObjectTracker
+}
+
+// NewSimpleClientset returns a clientset tracking the objects, which are *v1.User, *v1.Secret
+// or *v1.Policy.
+func NewSimpleClientset(objects ...interface{}) *Clientset {
+	tracker := NewObjectTracker()
+	for _, obj := range objects {
+		resource, name := resourceOf(obj)
+		if err := tracker.Create(resource, name, obj); err != nil {
+			panic(fmt.Sprintf("fake: add %s: %v", resource, err))
+		}
+	}
+
+	return &Clientset{tracker: tracker}
+}
+
+// Tracker returns the ObjectTracker of the clientset, which may be used by the reactors.
+func (c *Clientset) Tracker() *ObjectTracker {
+	return c.tracker
+}
+
+// Iam returns the iam clientset.
+func (c *Clientset) Iam() iam.IamInterface {
+	return c
+}
+
+// APIV1 returns the fake iam-apiserver clients.
+func (c *Clientset) APIV1() apiv1.APIV1Interface {
+	return &apiV1{c}
+}
+
+// AuthzV1 returns the fake iam-authz-server client.
+func (c *Clientset) AuthzV1() authzv1.AuthzV1Interface {
+	return &authzV1{c}
+}
+
+type apiV1 struct {
+	*Clientset
+}
+
+func (a *apiV1) Users() apiv1.UserInterface {
+	return &users{a.Clientset}
+}
+
+func (a *apiV1) Secrets() apiv1.SecretInterface {
+	return &secrets{a.Clientset}
+}
+
+func (a *apiV1) Policies() apiv1.PolicyInterface {
+	return &policies{a.Clientset}
+}
+
+// RESTClient returns nil, the fake clients do not send any request.
+func (a *apiV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+
+	return ret
+}
+
+type authzV1 struct {
+	*Clientset
+}
+
+func (a *authzV1) Authz() authzv1.AuthzInterface {
+	return &authz{a.Clientset}
+}
+
+// RESTClient returns nil, the fake clients do not send any request.
+func (a *authzV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+
+	return ret
+}
+
+func resourceOf(obj interface{}) (resource, name string) {
+	switch o := obj.(type) {
+	case *v1.User:
+		return ResourceUsers, o.Name
+	case *v1.Secret:
+		return ResourceSecrets, o.Name
+	case *v1.Policy:
+		return ResourcePolicies, o.Name
+	default:
+		panic(fmt.Sprintf("fake: unsupported object %T", obj))
+	}
+}
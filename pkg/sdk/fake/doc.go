@@ -0,0 +1,17 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+/*
+Package fake implements the marmotedu-sdk-go client interfaces in memory, so that the
+applications using the iam sdk can be unit tested without a live server.
+
+The objects are kept by an ObjectTracker, every call is recorded as an Action and handled by
+the reactors, the tracker being the last one:
+
+	client := fake.NewSimpleClientset(&v1.User{ObjectMeta: metav1.ObjectMeta{Name: "colin"}})
+	client.PrependReactor("delete", "users", func(action fake.Action) (bool, interface{}, error) {
+		return true, nil, errors.New("forbidden")
+	})
+*/
+package fake // import "github.com/marmotedu/iam/pkg/sdk/fake"
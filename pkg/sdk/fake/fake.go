@@ -0,0 +1,126 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"sync"
+)
+
+// Verbs of the actions.
+const (
+	VerbCreate           = "create"
+	VerbUpdate           = "update"
+	VerbDelete           = "delete"
+	VerbDeleteCollection = "delete-collection"
+	VerbGet              = "get"
+	VerbList             = "list"
+	VerbAuthorize        = "authorize"
+)
+
+// Resources of the actions.
+const (
+	ResourceUsers    = "users"
+	ResourceSecrets  = "secrets"
+	ResourcePolicies = "policies"
+	ResourceAuthz    = "authz"
+)
+
+// Action is a call of the fake clients.
+type Action struct {
+	Verb     string
+	Resource string
+	// Name is set for get and delete.
+	Name string
+	// Object is the object of create and update, the ladon request of authorize, and the
+	// list options of list and delete-collection.
+	Object interface{}
+}
+
+// ReactionFunc handles an action. When handled is false the next reactor is called, ret must be
+// of the type the client method returns.
+type ReactionFunc func(action Action) (handled bool, ret interface{}, err error)
+
+type reactor struct {
+	verb     string
+	resource string
+	fn       ReactionFunc
+}
+
+func (r reactor) matches(action Action) bool {
+	return (r.verb == "*" || r.verb == action.Verb) && (r.resource == "*" || r.resource == action.Resource)
+}
+
+// Fake records the actions and dispatches them to the reactors.
+type Fake struct {
+	lock     sync.RWMutex
+	actions  []Action
+	reactors []reactor
+}
+
+// AddReactor appends a reactor for the verb and resource, "*" matches all.
+func (f *Fake) AddReactor(verb, resource string, fn ReactionFunc) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.reactors = append(f.reactors, reactor{verb: verb, resource: resource, fn: fn})
+}
+
+// PrependReactor adds a reactor called before all the others.
+func (f *Fake) PrependReactor(verb, resource string, fn ReactionFunc) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.reactors = append([]reactor{{verb: verb, resource: resource, fn: fn}}, f.reactors...)
+}
+
+// Actions returns the recorded actions in order.
+func (f *Fake) Actions() []Action {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	actions := make([]Action, len(f.actions))
+	copy(actions, f.actions)
+
+	return actions
+}
+
+// ClearActions drops the recorded actions.
+func (f *Fake) ClearActions() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.actions = nil
+}
+
+// Invokes records the action and returns the result of the first reactor which handles it,
+// or defaultReturn when none does.
+func (f *Fake) Invokes(action Action, defaultReturn interface{}) (interface{}, error) {
+	if handled, ret, err := f.invokes(action); handled {
+		return ret, err
+	}
+
+	return defaultReturn, nil
+}
+
+// invokes records the action and calls the reactors until one handles it.
+func (f *Fake) invokes(action Action) (bool, interface{}, error) {
+	f.lock.Lock()
+	f.actions = append(f.actions, action)
+	reactors := make([]reactor, len(f.reactors))
+	copy(reactors, f.reactors)
+	f.lock.Unlock()
+
+	for _, r := range reactors {
+		if !r.matches(action) {
+			continue
+		}
+
+		if handled, ret, err := r.fn(action); handled {
+			return true, ret, err
+		}
+	}
+
+	return false, nil, nil
+}
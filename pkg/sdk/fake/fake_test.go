@@ -0,0 +1,155 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/ory/ladon"
+)
+
+func newUser(name string) *v1.User {
+	return &v1.User{ObjectMeta: metav1.ObjectMeta{Name: name}, Nickname: name}
+}
+
+func TestClientset_Users(t *testing.T) {
+	ctx := context.Background()
+	client := NewSimpleClientset(newUser("colin"), newUser("alice"), newUser("bob"))
+	users := client.Iam().APIV1().Users()
+
+	if _, err := users.Create(ctx, newUser("colin"), metav1.CreateOptions{}); !IsAlreadyExists(err) {
+		t.Errorf("Create() error = %v, want already exists", err)
+	}
+
+	user, err := users.Get(ctx, "colin", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	user.Nickname = "updated"
+	if got, _ := users.Get(ctx, "colin", metav1.GetOptions{}); got.Nickname != "colin" {
+		t.Errorf("Get() returned an object shared with the tracker")
+	}
+
+	if _, err := users.Update(ctx, user, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := users.Get(ctx, "colin", metav1.GetOptions{}); got.Nickname != "updated" {
+		t.Errorf("Get() nickname = %s, want updated", got.Nickname)
+	}
+
+	tests := []struct {
+		name      string
+		opts      metav1.ListOptions
+		wantNames []string
+	}{
+		{name: "all", opts: metav1.ListOptions{}, wantNames: []string{"alice", "bob", "colin"}},
+		{name: "limit", opts: metav1.ListOptions{Limit: pointer.ToInt64(2)}, wantNames: []string{"alice", "bob"}},
+		{
+			name:      "offset",
+			opts:      metav1.ListOptions{Offset: pointer.ToInt64(1), Limit: pointer.ToInt64(1)},
+			wantNames: []string{"bob"},
+		},
+		{name: "past the end", opts: metav1.ListOptions{Offset: pointer.ToInt64(3)}, wantNames: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := users.List(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			var names []string
+			for _, item := range list.Items {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) || list.TotalCount != 3 {
+				t.Errorf("List() = %v (total %d), want %v (total 3)", names, list.TotalCount, tt.wantNames)
+			}
+		})
+	}
+
+	if err := users.Delete(ctx, "colin", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := users.Get(ctx, "colin", metav1.GetOptions{}); !IsNotFound(err) {
+		t.Errorf("Get() error = %v, want not found", err)
+	}
+
+	if err := users.DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil {
+		t.Fatalf("DeleteCollection() error = %v", err)
+	}
+	if list, _ := users.List(ctx, metav1.ListOptions{}); list.TotalCount != 0 {
+		t.Errorf("List() total = %d after DeleteCollection(), want 0", list.TotalCount)
+	}
+}
+
+func TestClientset_Reactors(t *testing.T) {
+	ctx := context.Background()
+	client := NewSimpleClientset(&v1.Policy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}})
+	forbidden := errors.New("forbidden")
+
+	client.AddReactor("*", "*", func(action Action) (bool, interface{}, error) {
+		if action.Verb == VerbDelete {
+			t.Errorf("reactor added after the forbidding one is called")
+		}
+
+		return false, nil, nil
+	})
+	client.PrependReactor(VerbDelete, ResourcePolicies, func(action Action) (bool, interface{}, error) {
+		return true, nil, forbidden
+	})
+	client.PrependReactor(VerbGet, "*", func(action Action) (bool, interface{}, error) {
+		// not handled, falls through to the tracker
+		return false, nil, nil
+	})
+
+	policies := client.Iam().APIV1().Policies()
+	if err := policies.Delete(ctx, "policy", metav1.DeleteOptions{}); !errors.Is(err, forbidden) {
+		t.Errorf("Delete() error = %v, want %v", err, forbidden)
+	}
+	if _, err := policies.Get(ctx, "policy", metav1.GetOptions{}); err != nil {
+		t.Errorf("Get() error = %v, the policy must not be deleted", err)
+	}
+
+	want := []Action{
+		{Verb: VerbDelete, Resource: ResourcePolicies, Name: "policy"},
+		{Verb: VerbGet, Resource: ResourcePolicies, Name: "policy"},
+	}
+	if got := client.Actions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions() = %v, want %v", got, want)
+	}
+
+	client.ClearActions()
+	if got := client.Actions(); len(got) != 0 {
+		t.Errorf("Actions() = %v after ClearActions(), want none", got)
+	}
+}
+
+func TestClientset_Authorize(t *testing.T) {
+	ctx := context.Background()
+	client := NewSimpleClientset()
+	request := &ladon.Request{Subject: "users:colin", Action: "delete", Resource: "resources:articles:ladon"}
+
+	rsp, err := client.Iam().AuthzV1().Authz().Authorize(ctx, request, metav1.AuthorizeOptions{})
+	if err != nil || rsp.Allowed || rsp.Reason != DeniedReason {
+		t.Errorf("Authorize() = %v, %v, want denied by default", rsp, err)
+	}
+
+	client.PrependReactor(VerbAuthorize, ResourceAuthz, AllowAll)
+	rsp, err = client.Iam().AuthzV1().Authz().Authorize(ctx, request, metav1.AuthorizeOptions{})
+	if err != nil || !rsp.Allowed {
+		t.Errorf("Authorize() = %v, %v, want allowed", rsp, err)
+	}
+
+	if got := client.Actions(); len(got) != 2 || got[0].Object != request {
+		t.Errorf("Actions() = %v, want the authorize actions with the request", got)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// policies implements apiv1.PolicyInterface with the tracker of the clientset.
+type policies struct {
+	*Clientset
+}
+
+func (c *policies) Create(ctx context.Context, obj *v1.Policy, opts metav1.CreateOptions) (*v1.Policy, error) {
+	action := Action{Verb: VerbCreate, Resource: ResourcePolicies, Name: obj.Name, Object: obj}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.Policy)
+
+		return result, err
+	}
+
+	if err := c.tracker.Create(ResourcePolicies, obj.Name, obj); err != nil {
+		return nil, err
+	}
+
+	return c.get(obj.Name)
+}
+
+func (c *policies) Update(ctx context.Context, obj *v1.Policy, opts metav1.UpdateOptions) (*v1.Policy, error) {
+	action := Action{Verb: VerbUpdate, Resource: ResourcePolicies, Name: obj.Name, Object: obj}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.Policy)
+
+		return result, err
+	}
+
+	if err := c.tracker.Update(ResourcePolicies, obj.Name, obj); err != nil {
+		return nil, err
+	}
+
+	return c.get(obj.Name)
+}
+
+func (c *policies) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	action := Action{Verb: VerbDelete, Resource: ResourcePolicies, Name: name}
+	if handled, _, err := c.invokes(action); handled {
+		return err
+	}
+
+	return c.tracker.Delete(ResourcePolicies, name)
+}
+
+func (c *policies) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	action := Action{Verb: VerbDeleteCollection, Resource: ResourcePolicies, Object: listOpts}
+	if handled, _, err := c.invokes(action); handled {
+		return err
+	}
+
+	for _, obj := range c.tracker.List(ResourcePolicies) {
+		if err := c.tracker.Delete(ResourcePolicies, obj.(*v1.Policy).Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *policies) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Policy, error) {
+	action := Action{Verb: VerbGet, Resource: ResourcePolicies, Name: name}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.Policy)
+
+		return result, err
+	}
+
+	return c.get(name)
+}
+
+func (c *policies) List(ctx context.Context, opts metav1.ListOptions) (*v1.PolicyList, error) {
+	action := Action{Verb: VerbList, Resource: ResourcePolicies, Object: opts}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.PolicyList)
+
+		return result, err
+	}
+
+	objs := c.tracker.List(ResourcePolicies)
+	list := &v1.PolicyList{ListMeta: metav1.ListMeta{TotalCount: int64(len(objs))}}
+	for _, obj := range paginate(objs, opts) {
+		list.Items = append(list.Items, obj.(*v1.Policy))
+	}
+
+	return list, nil
+}
+
+func (c *policies) get(name string) (*v1.Policy, error) {
+	obj, err := c.tracker.Get(ResourcePolicies, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*v1.Policy), nil
+}
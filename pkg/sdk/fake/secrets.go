@@ -0,0 +1,108 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// secrets implements apiv1.SecretInterface with the tracker of the clientset.
+type secrets struct {
+	*Clientset
+}
+
+func (c *secrets) Create(ctx context.Context, obj *v1.Secret, opts metav1.CreateOptions) (*v1.Secret, error) {
+	action := Action{Verb: VerbCreate, Resource: ResourceSecrets, Name: obj.Name, Object: obj}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.Secret)
+
+		return result, err
+	}
+
+	if err := c.tracker.Create(ResourceSecrets, obj.Name, obj); err != nil {
+		return nil, err
+	}
+
+	return c.get(obj.Name)
+}
+
+func (c *secrets) Update(ctx context.Context, obj *v1.Secret, opts metav1.UpdateOptions) (*v1.Secret, error) {
+	action := Action{Verb: VerbUpdate, Resource: ResourceSecrets, Name: obj.Name, Object: obj}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.Secret)
+
+		return result, err
+	}
+
+	if err := c.tracker.Update(ResourceSecrets, obj.Name, obj); err != nil {
+		return nil, err
+	}
+
+	return c.get(obj.Name)
+}
+
+func (c *secrets) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	action := Action{Verb: VerbDelete, Resource: ResourceSecrets, Name: name}
+	if handled, _, err := c.invokes(action); handled {
+		return err
+	}
+
+	return c.tracker.Delete(ResourceSecrets, name)
+}
+
+func (c *secrets) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	action := Action{Verb: VerbDeleteCollection, Resource: ResourceSecrets, Object: listOpts}
+	if handled, _, err := c.invokes(action); handled {
+		return err
+	}
+
+	for _, obj := range c.tracker.List(ResourceSecrets) {
+		if err := c.tracker.Delete(ResourceSecrets, obj.(*v1.Secret).Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *secrets) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Secret, error) {
+	action := Action{Verb: VerbGet, Resource: ResourceSecrets, Name: name}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.Secret)
+
+		return result, err
+	}
+
+	return c.get(name)
+}
+
+func (c *secrets) List(ctx context.Context, opts metav1.ListOptions) (*v1.SecretList, error) {
+	action := Action{Verb: VerbList, Resource: ResourceSecrets, Object: opts}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.SecretList)
+
+		return result, err
+	}
+
+	objs := c.tracker.List(ResourceSecrets)
+	list := &v1.SecretList{ListMeta: metav1.ListMeta{TotalCount: int64(len(objs))}}
+	for _, obj := range paginate(objs, opts) {
+		list.Items = append(list.Items, obj.(*v1.Secret))
+	}
+
+	return list, nil
+}
+
+func (c *secrets) get(name string) (*v1.Secret, error) {
+	obj, err := c.tracker.Get(ResourceSecrets, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*v1.Secret), nil
+}
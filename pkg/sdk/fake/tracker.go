@@ -0,0 +1,150 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// Errors returned by the tracker, wrapped with the resource and the name.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+// IsNotFound reports whether err is returned for a missing object.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsAlreadyExists reports whether err is returned for an existing object.
+func IsAlreadyExists(err error) bool {
+	return errors.Is(err, ErrAlreadyExists)
+}
+
+// ObjectTracker keeps the objects by resource and name. The objects are copied in and out, so
+// the callers never share them with the tracker.
+type ObjectTracker struct {
+	lock    sync.RWMutex
+	objects map[string]map[string]interface{}
+}
+
+// NewObjectTracker creates an empty tracker.
+func NewObjectTracker() *ObjectTracker {
+	return &ObjectTracker{objects: map[string]map[string]interface{}{}}
+}
+
+// Get returns a copy of the object.
+func (t *ObjectTracker) Get(resource, name string) (interface{}, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	obj, ok := t.objects[resource][name]
+	if !ok {
+		return nil, fmt.Errorf("%s %q %w", resource, name, ErrNotFound)
+	}
+
+	return deepCopy(obj), nil
+}
+
+// List returns copies of the objects of the resource sorted by name.
+func (t *ObjectTracker) List(resource string) []interface{} {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	names := make([]string, 0, len(t.objects[resource]))
+	for name := range t.objects[resource] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	objs := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		objs = append(objs, deepCopy(t.objects[resource][name]))
+	}
+
+	return objs
+}
+
+// Create adds a copy of the object, which must not exist.
+func (t *ObjectTracker) Create(resource, name string, obj interface{}) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.objects[resource][name]; ok {
+		return fmt.Errorf("%s %q %w", resource, name, ErrAlreadyExists)
+	}
+
+	if t.objects[resource] == nil {
+		t.objects[resource] = map[string]interface{}{}
+	}
+	t.objects[resource][name] = deepCopy(obj)
+
+	return nil
+}
+
+// Update replaces the object with a copy of obj, the object must exist.
+func (t *ObjectTracker) Update(resource, name string, obj interface{}) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.objects[resource][name]; !ok {
+		return fmt.Errorf("%s %q %w", resource, name, ErrNotFound)
+	}
+	t.objects[resource][name] = deepCopy(obj)
+
+	return nil
+}
+
+// Delete removes the object, which must exist.
+func (t *ObjectTracker) Delete(resource, name string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.objects[resource][name]; !ok {
+		return fmt.Errorf("%s %q %w", resource, name, ErrNotFound)
+	}
+	delete(t.objects[resource], name)
+
+	return nil
+}
+
+// deepCopy copies the object through its json encoding, as the client would receive it.
+func deepCopy(obj interface{}) interface{} {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		panic(fmt.Sprintf("fake: marshal %T: %v", obj, err))
+	}
+
+	copied := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+	if err := json.Unmarshal(data, copied); err != nil {
+		panic(fmt.Sprintf("fake: unmarshal %T: %v", obj, err))
+	}
+
+	return copied
+}
+
+// paginate returns the objects selected by the offset and limit of the list options.
+func paginate(objs []interface{}, opts metav1.ListOptions) []interface{} {
+	if opts.Offset != nil && *opts.Offset > 0 {
+		if *opts.Offset >= int64(len(objs)) {
+			return nil
+		}
+		objs = objs[*opts.Offset:]
+	}
+
+	if opts.Limit != nil && *opts.Limit >= 0 && *opts.Limit < int64(len(objs)) {
+		objs = objs[:*opts.Limit]
+	}
+
+	return objs
+}
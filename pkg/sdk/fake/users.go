@@ -0,0 +1,108 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fake
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// users implements apiv1.UserInterface with the tracker of the clientset.
+type users struct {
+	*Clientset
+}
+
+func (c *users) Create(ctx context.Context, obj *v1.User, opts metav1.CreateOptions) (*v1.User, error) {
+	action := Action{Verb: VerbCreate, Resource: ResourceUsers, Name: obj.Name, Object: obj}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.User)
+
+		return result, err
+	}
+
+	if err := c.tracker.Create(ResourceUsers, obj.Name, obj); err != nil {
+		return nil, err
+	}
+
+	return c.get(obj.Name)
+}
+
+func (c *users) Update(ctx context.Context, obj *v1.User, opts metav1.UpdateOptions) (*v1.User, error) {
+	action := Action{Verb: VerbUpdate, Resource: ResourceUsers, Name: obj.Name, Object: obj}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.User)
+
+		return result, err
+	}
+
+	if err := c.tracker.Update(ResourceUsers, obj.Name, obj); err != nil {
+		return nil, err
+	}
+
+	return c.get(obj.Name)
+}
+
+func (c *users) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	action := Action{Verb: VerbDelete, Resource: ResourceUsers, Name: name}
+	if handled, _, err := c.invokes(action); handled {
+		return err
+	}
+
+	return c.tracker.Delete(ResourceUsers, name)
+}
+
+func (c *users) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	action := Action{Verb: VerbDeleteCollection, Resource: ResourceUsers, Object: listOpts}
+	if handled, _, err := c.invokes(action); handled {
+		return err
+	}
+
+	for _, obj := range c.tracker.List(ResourceUsers) {
+		if err := c.tracker.Delete(ResourceUsers, obj.(*v1.User).Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *users) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.User, error) {
+	action := Action{Verb: VerbGet, Resource: ResourceUsers, Name: name}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.User)
+
+		return result, err
+	}
+
+	return c.get(name)
+}
+
+func (c *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+	action := Action{Verb: VerbList, Resource: ResourceUsers, Object: opts}
+	if handled, ret, err := c.invokes(action); handled {
+		result, _ := ret.(*v1.UserList)
+
+		return result, err
+	}
+
+	objs := c.tracker.List(ResourceUsers)
+	list := &v1.UserList{ListMeta: metav1.ListMeta{TotalCount: int64(len(objs))}}
+	for _, obj := range paginate(objs, opts) {
+		list.Items = append(list.Items, obj.(*v1.User))
+	}
+
+	return list, nil
+}
+
+func (c *users) get(name string) (*v1.User, error) {
+	obj, err := c.tracker.Get(ResourceUsers, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*v1.User), nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks are called around the requests of a client, e.g. to record the latency of the iam calls
+// or to propagate the trace of the caller. The hooks are called by the goroutine making the
+// call, and must not read or close the bodies.
+type Hooks struct {
+	// OnRequest is called before the request is sent, it may set the headers of the request.
+	OnRequest func(req *http.Request)
+	// OnResponse is called with the response of the request, or the error when none is
+	// received, and the time elapsed since the request was sent.
+	OnResponse func(req *http.Request, resp *http.Response, err error, latency time.Duration)
+}
+
+// Instrument returns a middleware which calls the hooks. When it is placed before the retry
+// middleware the hooks see each call once, after it they see each attempt.
+func Instrument(hooks Hooks) Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &hooksTransport{hooks: hooks, base: base}
+	}
+}
+
+// Headers returns a middleware which sets the headers on all the requests.
+func Headers(header http.Header) Middleware {
+	return Instrument(Hooks{OnRequest: func(req *http.Request) {
+		for key, values := range header {
+			req.Header[http.CanonicalHeaderKey(key)] = values
+		}
+	}})
+}
+
+type hooksTransport struct {
+	hooks Hooks
+	base  http.RoundTripper
+}
+
+func (t *hooksTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hooks.OnRequest != nil {
+		// the request of the caller must not be modified
+		req = req.Clone(req.Context())
+		t.hooks.OnRequest(req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	if t.hooks.OnResponse != nil {
+		t.hooks.OnResponse(req, resp, err, time.Since(start))
+	}
+
+	return resp, err
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInstrument(t *testing.T) {
+	sendErr := errors.New("connection refused")
+	tests := []struct {
+		name       string
+		status     int
+		err        error
+		wantStatus int
+	}{
+		{name: "response", status: http.StatusOK, wantStatus: http.StatusOK},
+		{name: "error", err: sendErr, wantStatus: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentHeader string
+			base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				sentHeader = req.Header.Get("Traceparent")
+				time.Sleep(10 * time.Millisecond)
+				if tt.err != nil {
+					return nil, tt.err
+				}
+
+				return &http.Response{StatusCode: tt.status, Request: req}, nil
+			})
+
+			var (
+				gotStatus  int
+				gotErr     error
+				gotLatency time.Duration
+			)
+			rt := Instrument(Hooks{
+				OnRequest: func(req *http.Request) {
+					req.Header.Set("Traceparent", "00-trace-span-01")
+				},
+				OnResponse: func(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+					if resp != nil {
+						gotStatus = resp.StatusCode
+					}
+					gotErr, gotLatency = err, latency
+				},
+			})(base)
+
+			req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/v1/users", nil)
+			_, _ = rt.RoundTrip(req)
+
+			if sentHeader != "00-trace-span-01" || req.Header.Get("Traceparent") != "" {
+				t.Errorf("OnRequest() header sent %q, caller header %q", sentHeader, req.Header.Get("Traceparent"))
+			}
+			if gotStatus != tt.wantStatus || !errors.Is(gotErr, tt.err) || gotLatency < 10*time.Millisecond {
+				t.Errorf("OnResponse() = %d, %v, %s, want %d, %v", gotStatus, gotErr, gotLatency, tt.wantStatus, tt.err)
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	rt := Headers(http.Header{"x-request-id": {"42"}})(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/v1/users", nil)
+	_, _ = rt.RoundTrip(req)
+	if got.Get("X-Request-Id") != "42" {
+		t.Errorf("Headers() sent %v, want X-Request-Id: 42", got)
+	}
+}
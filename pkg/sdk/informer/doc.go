@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package informer keeps local caches of the policies and the secrets which are periodically
+// re-listed from iam-apiserver, so that the reads are served locally and the changes are
+// notified to event handlers.
+package informer // import "github.com/marmotedu/iam/pkg/sdk/informer"
@@ -0,0 +1,226 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package informer
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// DefaultResyncPeriod is how often the objects are re-listed by default.
+const DefaultResyncPeriod = time.Minute
+
+// listPageSize is the limit of a list request.
+const listPageSize = 500
+
+// EventHandler is notified of the changes of the objects. The objects must not be modified.
+type EventHandler interface {
+	OnAdd(obj interface{})
+	OnUpdate(oldObj, newObj interface{})
+	OnDelete(obj interface{})
+}
+
+// EventHandlerFuncs adapts the functions to an EventHandler, the nil functions are ignored.
+type EventHandlerFuncs struct {
+	AddFunc    func(obj interface{})
+	UpdateFunc func(oldObj, newObj interface{})
+	DeleteFunc func(obj interface{})
+}
+
+// OnAdd calls AddFunc if it's not nil.
+func (f EventHandlerFuncs) OnAdd(obj interface{}) {
+	if f.AddFunc != nil {
+		f.AddFunc(obj)
+	}
+}
+
+// OnUpdate calls UpdateFunc if it's not nil.
+func (f EventHandlerFuncs) OnUpdate(oldObj, newObj interface{}) {
+	if f.UpdateFunc != nil {
+		f.UpdateFunc(oldObj, newObj)
+	}
+}
+
+// OnDelete calls DeleteFunc if it's not nil.
+func (f EventHandlerFuncs) OnDelete(obj interface{}) {
+	if f.DeleteFunc != nil {
+		f.DeleteFunc(obj)
+	}
+}
+
+// listFunc lists all the objects keyed by name.
+type listFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// informer is the cache shared by the typed informers.
+type informer struct {
+	list   listFunc
+	period time.Duration
+
+	// handlerLock serializes the resyncs and the notifications, the handlers are notified
+	// without holding lock so that they can read the cache.
+	handlerLock sync.Mutex
+	handlers    []EventHandler
+
+	lock   sync.RWMutex
+	items  map[string]interface{}
+	synced bool
+}
+
+func newInformer(list listFunc, period time.Duration) *informer {
+	if period <= 0 {
+		period = DefaultResyncPeriod
+	}
+
+	return &informer{
+		list:   list,
+		period: period,
+		items:  map[string]interface{}{},
+	}
+}
+
+// AddEventHandler adds a handler which is notified of the later changes, and of the objects
+// already in the cache as added.
+func (i *informer) AddEventHandler(handler EventHandler) {
+	i.handlerLock.Lock()
+	defer i.handlerLock.Unlock()
+
+	i.handlers = append(i.handlers, handler)
+	for _, obj := range i.all() {
+		obj := obj
+		notify([]EventHandler{handler}, func(h EventHandler) { h.OnAdd(obj) })
+	}
+}
+
+// Run lists the objects every period until the context is done.
+func (i *informer) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.period)
+	defer ticker.Stop()
+
+	for {
+		if err := i.Resync(ctx); err != nil {
+			log.Warnf("resync the informer cache failed: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Resync lists the objects and notifies the handlers of the changes. The cache is kept as is
+// when the list fails.
+func (i *informer) Resync(ctx context.Context) error {
+	i.handlerLock.Lock()
+	defer i.handlerLock.Unlock()
+
+	items, err := i.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.lock.Lock()
+	old := i.items
+	i.items = items
+	i.synced = true
+	i.lock.Unlock()
+
+	for _, name := range sortedNames(items) {
+		obj := items[name]
+		oldObj, ok := old[name]
+
+		switch {
+		case !ok:
+			notify(i.handlers, func(h EventHandler) { h.OnAdd(obj) })
+		case !reflect.DeepEqual(oldObj, obj):
+			notify(i.handlers, func(h EventHandler) { h.OnUpdate(oldObj, obj) })
+		}
+	}
+
+	for _, name := range sortedNames(old) {
+		if _, ok := items[name]; !ok {
+			obj := old[name]
+			notify(i.handlers, func(h EventHandler) { h.OnDelete(obj) })
+		}
+	}
+
+	return nil
+}
+
+// HasSynced reports whether the objects have been listed at least once.
+func (i *informer) HasSynced() bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	return i.synced
+}
+
+// WaitForCacheSync waits until the objects have been listed or the context is done.
+func (i *informer) WaitForCacheSync(ctx context.Context) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !i.HasSynced() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
+
+func (i *informer) get(name string) (interface{}, bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	obj, ok := i.items[name]
+
+	return obj, ok
+}
+
+func (i *informer) all() []interface{} {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	objs := make([]interface{}, 0, len(i.items))
+	for _, name := range sortedNames(i.items) {
+		objs = append(objs, i.items[name])
+	}
+
+	return objs
+}
+
+// notify calls the handlers, a panicking handler does not stop the informer.
+func notify(handlers []EventHandler, fn func(h EventHandler)) {
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("informer event handler panicked: %v", r)
+				}
+			}()
+
+			fn(h)
+		}()
+	}
+}
+
+func sortedNames(items map[string]interface{}) []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package informer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+)
+
+// fakePolicies serves the pages of items, the other methods are not implemented.
+type fakePolicies struct {
+	apiv1.PolicyInterface
+	items []*v1.Policy
+	err   error
+}
+
+func (f *fakePolicies) Policies() apiv1.PolicyInterface {
+	return f
+}
+
+func (f *fakePolicies) List(ctx context.Context, opts metav1.ListOptions) (*v1.PolicyList, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	start, end := *opts.Offset, *opts.Offset+*opts.Limit
+	if start > int64(len(f.items)) {
+		start = int64(len(f.items))
+	}
+	if end > int64(len(f.items)) {
+		end = int64(len(f.items))
+	}
+
+	return &v1.PolicyList{Items: f.items[start:end]}, nil
+}
+
+func policy(name, description string) *v1.Policy {
+	return &v1.Policy{ObjectMeta: metav1.ObjectMeta{Name: name, Extend: metav1.Extend{"description": description}}}
+}
+
+func TestPolicyInformer(t *testing.T) {
+	client := &fakePolicies{}
+	for i := 0; i < listPageSize+1; i++ {
+		client.items = append(client.items, policy(fmt.Sprintf("policy-%04d", i), ""))
+	}
+
+	i := NewPolicyInformer(client, 0)
+
+	var events []string
+	i.AddEventHandler(EventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// the handlers can read the cache
+			if _, ok := i.Get(obj.(*v1.Policy).Name); !ok {
+				t.Errorf("Get(%s) in the handler = false, want true", obj.(*v1.Policy).Name)
+			}
+			events = append(events, "add "+obj.(*v1.Policy).Name)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			events = append(events, "update "+newObj.(*v1.Policy).Name)
+		},
+		DeleteFunc: func(obj interface{}) { events = append(events, "delete "+obj.(*v1.Policy).Name) },
+	})
+
+	if err := i.Resync(context.TODO()); err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+
+	// all the pages are listed
+	if got := len(i.List()); got != listPageSize+1 || !i.HasSynced() {
+		t.Fatalf("List() returns %d policies, want %d", got, listPageSize+1)
+	}
+
+	client.items = []*v1.Policy{policy("policy-0000", "changed"), policy("policy-0001", ""), policy("new", "")}
+	events = nil
+
+	if err := i.Resync(context.TODO()); err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+
+	if len(events) != listPageSize+1 || events[0] != "add new" || events[1] != "update policy-0000" ||
+		events[2] != "delete policy-0002" {
+		t.Errorf("events = %v... (%d events)", events[:3], len(events))
+	}
+
+	if p, ok := i.Get("policy-0000"); !ok || !reflect.DeepEqual(p, client.items[0]) {
+		t.Errorf("Get() = %v, %v, want %v", p, ok, client.items[0])
+	}
+
+	// a failed list keeps the cache
+	client.err = fmt.Errorf("connection refused")
+	if err := i.Resync(context.TODO()); err == nil {
+		t.Error("Resync() error = nil, want error")
+	}
+	if got := len(i.List()); got != 3 {
+		t.Errorf("List() after a failed resync returns %d policies, want 3", got)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package informer
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+
+	"github.com/marmotedu/iam/pkg/sdk/pager"
+)
+
+// listOptions lists all the items by pages of listPageSize.
+var listOptions = metav1.ListOptions{Limit: pointer.ToInt64(listPageSize)}
+
+// PolicyInformer caches the policies of the authenticated user.
+type PolicyInformer struct {
+	*informer
+}
+
+// NewPolicyInformer creates an informer which re-lists the policies every period.
+func NewPolicyInformer(client apiv1.PoliciesGetter, period time.Duration) *PolicyInformer {
+	return &PolicyInformer{newInformer(func(ctx context.Context) (map[string]interface{}, error) {
+		items := map[string]interface{}{}
+		err := pager.Policies(client).EachListItem(ctx, listOptions, func(obj interface{}) error {
+			items[obj.(*v1.Policy).Name] = obj
+
+			return nil
+		})
+
+		return items, err
+	}, period)}
+}
+
+// Get returns the cached policy of the name.
+func (i *PolicyInformer) Get(name string) (*v1.Policy, bool) {
+	obj, ok := i.get(name)
+	if !ok {
+		return nil, false
+	}
+
+	return obj.(*v1.Policy), true
+}
+
+// List returns the cached policies sorted by name.
+func (i *PolicyInformer) List() []*v1.Policy {
+	objs := i.all()
+	policies := make([]*v1.Policy, 0, len(objs))
+	for _, obj := range objs {
+		policies = append(policies, obj.(*v1.Policy))
+	}
+
+	return policies
+}
+
+// SecretInformer caches the secrets of the authenticated user.
+type SecretInformer struct {
+	*informer
+}
+
+// NewSecretInformer creates an informer which re-lists the secrets every period.
+func NewSecretInformer(client apiv1.SecretsGetter, period time.Duration) *SecretInformer {
+	return &SecretInformer{newInformer(func(ctx context.Context) (map[string]interface{}, error) {
+		items := map[string]interface{}{}
+		err := pager.Secrets(client).EachListItem(ctx, listOptions, func(obj interface{}) error {
+			items[obj.(*v1.Secret).Name] = obj
+
+			return nil
+		})
+
+		return items, err
+	}, period)}
+}
+
+// Get returns the cached secret of the name.
+func (i *SecretInformer) Get(name string) (*v1.Secret, bool) {
+	obj, ok := i.get(name)
+	if !ok {
+		return nil, false
+	}
+
+	return obj.(*v1.Secret), true
+}
+
+// List returns the cached secrets sorted by name.
+func (i *SecretInformer) List() []*v1.Secret {
+	objs := i.all()
+	secrets := make([]*v1.Secret, 0, len(objs))
+	for _, obj := range objs {
+		secrets = append(secrets, obj.(*v1.Secret))
+	}
+
+	return secrets
+}
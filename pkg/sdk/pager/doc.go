@@ -0,0 +1,18 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+/*
+Package pager follows the offset pagination of the iam-apiserver list apis, so that the callers
+do not have to write the pagination loops:
+
+	err := pager.Users(client.APIV1()).EachListItem(ctx, metav1.ListOptions{}, func(obj interface{}) error {
+		fmt.Println(obj.(*v1.User).Name)
+
+		return nil
+	})
+
+The lists are requested page by page with increasing offsets, until a page is shorter than the
+page size or the total count of the list is reached.
+*/
+package pager // import "github.com/marmotedu/iam/pkg/sdk/pager"
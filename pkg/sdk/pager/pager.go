@@ -0,0 +1,111 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AlekSi/pointer"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// DefaultPageSize is the number of items requested per page when neither ListPager.PageSize nor
+// the limit of the list options is set.
+const DefaultPageSize = 500
+
+// ErrStop may be returned by the callbacks of Pages and EachListItem to stop the iteration, it is
+// not returned to the caller.
+var ErrStop = errors.New("stop iteration")
+
+// ListPageFunc lists a page of items selected by the offset and limit of opts, and returns the
+// items and the total count of the list.
+type ListPageFunc func(ctx context.Context, opts metav1.ListOptions) (items []interface{}, totalCount int64, err error)
+
+// ListPager lists all the items by pages.
+type ListPager struct {
+	// PageSize overrides the limit of the list options when set.
+	PageSize int64
+	PageFn   ListPageFunc
+}
+
+// New creates a pager for the list function.
+func New(fn ListPageFunc) *ListPager {
+	return &ListPager{PageFn: fn}
+}
+
+// Pages calls fn with every page of the list, starting at the offset of opts. The limit of opts
+// is the page size when PageSize is not set.
+func (p *ListPager) Pages(ctx context.Context, opts metav1.ListOptions, fn func(items []interface{}) error) error {
+	offset := pointer.GetInt64(opts.Offset)
+	pageSize := p.pageSize(opts)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		opts.Offset = pointer.ToInt64(offset)
+		opts.Limit = pointer.ToInt64(pageSize)
+
+		items, totalCount, err := p.PageFn(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		if len(items) > 0 {
+			if err := fn(items); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+
+				return err
+			}
+		}
+
+		offset += int64(len(items))
+		// a zero total count is not trusted, some list apis do not set it
+		if int64(len(items)) < pageSize || (totalCount > 0 && offset >= totalCount) {
+			return nil
+		}
+	}
+}
+
+// EachListItem calls fn with every item of the list.
+func (p *ListPager) EachListItem(ctx context.Context, opts metav1.ListOptions, fn func(obj interface{}) error) error {
+	return p.Pages(ctx, opts, func(items []interface{}) error {
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// List returns all the items of the list.
+func (p *ListPager) List(ctx context.Context, opts metav1.ListOptions) ([]interface{}, error) {
+	var all []interface{}
+	err := p.Pages(ctx, opts, func(items []interface{}) error {
+		all = append(all, items...)
+
+		return nil
+	})
+
+	return all, err
+}
+
+func (p *ListPager) pageSize(opts metav1.ListOptions) int64 {
+	if p.PageSize > 0 {
+		return p.PageSize
+	}
+
+	if limit := pointer.GetInt64(opts.Limit); limit > 0 {
+		return limit
+	}
+
+	return DefaultPageSize
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/marmotedu/iam/pkg/sdk/fake"
+)
+
+func newClientset(n int) *fake.Clientset {
+	objects := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		objects = append(objects, &v1.User{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("user-%02d", i)}})
+	}
+
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestListPager_EachListItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		users     int
+		pageSize  int64
+		opts      metav1.ListOptions
+		wantItems int
+		wantPages int
+	}{
+		{name: "empty", users: 0, pageSize: 2, wantItems: 0, wantPages: 1},
+		{name: "one page", users: 2, pageSize: 5, wantItems: 2, wantPages: 1},
+		{name: "full pages", users: 4, pageSize: 2, wantItems: 4, wantPages: 2},
+		{name: "last page", users: 5, pageSize: 2, wantItems: 5, wantPages: 3},
+		{name: "limit as page size", users: 5, opts: metav1.ListOptions{Limit: pointer.ToInt64(3)}, wantItems: 5, wantPages: 2},
+		{name: "offset", users: 5, pageSize: 2, opts: metav1.ListOptions{Offset: pointer.ToInt64(3)}, wantItems: 2, wantPages: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClientset(tt.users)
+			p := Users(client.Iam().APIV1())
+			p.PageSize = tt.pageSize
+
+			var names []string
+			err := p.EachListItem(context.Background(), tt.opts, func(obj interface{}) error {
+				names = append(names, obj.(*v1.User).Name)
+
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("EachListItem() error = %v", err)
+			}
+
+			if len(names) != tt.wantItems {
+				t.Errorf("EachListItem() visits %d items, want %d", len(names), tt.wantItems)
+			}
+			if got := len(client.Actions()); got != tt.wantPages {
+				t.Errorf("EachListItem() lists %d pages, want %d", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestListPager_Stop(t *testing.T) {
+	client := newClientset(5)
+	p := Users(client.Iam().APIV1())
+	p.PageSize = 2
+
+	var names []string
+	err := p.EachListItem(context.Background(), metav1.ListOptions{}, func(obj interface{}) error {
+		names = append(names, obj.(*v1.User).Name)
+		if len(names) == 3 {
+			return ErrStop
+		}
+
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(names, []string{"user-00", "user-01", "user-02"}) {
+		t.Errorf("EachListItem() = %v, %v, want stopped at the third user", names, err)
+	}
+	if got := len(client.Actions()); got != 2 {
+		t.Errorf("EachListItem() lists %d pages, want 2", got)
+	}
+}
+
+func TestListPager_Error(t *testing.T) {
+	client := newClientset(5)
+	want := errors.New("internal error")
+	client.PrependReactor(fake.VerbList, fake.ResourceUsers, func(action fake.Action) (bool, interface{}, error) {
+		if pointer.GetInt64(action.Object.(metav1.ListOptions).Offset) > 0 {
+			return true, nil, want
+		}
+
+		return false, nil, nil
+	})
+
+	p := Users(client.Iam().APIV1())
+	p.PageSize = 2
+	if items, err := p.List(context.Background(), metav1.ListOptions{}); !errors.Is(err, want) || len(items) != 2 {
+		t.Errorf("List() = %d items, %v, want the first page and %v", len(items), err, want)
+	}
+}
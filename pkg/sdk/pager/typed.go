@@ -0,0 +1,63 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pager
+
+import (
+	"context"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+)
+
+// Users returns a pager of the users, the items are *v1.User.
+func Users(client apiv1.UsersGetter) *ListPager {
+	return New(func(ctx context.Context, opts metav1.ListOptions) ([]interface{}, int64, error) {
+		list, err := client.Users().List(ctx, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		items := make([]interface{}, 0, len(list.Items))
+		for _, user := range list.Items {
+			items = append(items, user)
+		}
+
+		return items, list.TotalCount, nil
+	})
+}
+
+// Secrets returns a pager of the secrets, the items are *v1.Secret.
+func Secrets(client apiv1.SecretsGetter) *ListPager {
+	return New(func(ctx context.Context, opts metav1.ListOptions) ([]interface{}, int64, error) {
+		list, err := client.Secrets().List(ctx, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		items := make([]interface{}, 0, len(list.Items))
+		for _, secret := range list.Items {
+			items = append(items, secret)
+		}
+
+		return items, list.TotalCount, nil
+	})
+}
+
+// Policies returns a pager of the policies, the items are *v1.Policy.
+func Policies(client apiv1.PoliciesGetter) *ListPager {
+	return New(func(ctx context.Context, opts metav1.ListOptions) ([]interface{}, int64, error) {
+		list, err := client.Policies().List(ctx, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		items := make([]interface{}, 0, len(list.Items))
+		for _, policy := range list.Items {
+			items = append(items, policy)
+		}
+
+		return items, list.TotalCount, nil
+	})
+}
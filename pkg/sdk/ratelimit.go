@@ -0,0 +1,58 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+// NewRateLimiter returns a token bucket limiter allowing qps requests per second with bursts
+// of burst requests, a burst lower than 1 is set to 1.
+func NewRateLimiter(qps float64, burst int) *rate.Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(qps), burst)
+}
+
+// RateLimit returns a middleware which delays the requests to the rate of the limiter. A request
+// fails with the error of its context when it is canceled while waiting. The limiter may be
+// shared by the clients, e.g. with RateLimitInterceptor, to limit them together.
+func RateLimit(limiter *rate.Limiter) Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &rateLimitTransport{limiter: limiter, base: base}
+	}
+}
+
+type rateLimitTransport struct {
+	limiter *rate.Limiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// RateLimitInterceptor returns a grpc client interceptor which delays the calls to the rate of
+// the limiter.
+func RateLimitInterceptor(limiter *rate.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	sent := 0
+	rt := RateLimit(NewRateLimiter(20, 2))(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent++
+
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/v1/users", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip() error = %v", err)
+		}
+	}
+
+	// the burst is sent at once, the 2 others wait 50ms each
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond || sent != 4 {
+		t.Errorf("RateLimit() sends %d requests in %s, want 4 in about 100ms", sent, elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1/v1/users", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() error = %v, want canceled", err)
+	}
+	if sent != 4 {
+		t.Errorf("RoundTrip() sends the canceled request")
+	}
+}
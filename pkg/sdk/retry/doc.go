@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package retry retries the failed requests of the sdk clients with exponential backoff. Only
+// the idempotent requests are retried, unless the caller supplies an idempotency key.
+package retry // import "github.com/marmotedu/iam/pkg/sdk/retry"
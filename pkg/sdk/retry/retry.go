@@ -0,0 +1,210 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/marmotedu/iam/pkg/sdk"
+)
+
+// IdempotencyKeyHeader is the request header which makes a non idempotent request retryable.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Policy defines when and how often a failed request is retried.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the backoff before the first retry, it grows by Multiplier up to MaxBackoff.
+	// A random jitter of up to half the backoff is subtracted.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// RetryableStatus are the response status codes to retry, the transport errors are always retried.
+	RetryableStatus []int
+}
+
+// DefaultPolicy returns the policy which makes 3 attempts, retrying the 429 and 5xx responses
+// except 501.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type policyKey struct{}
+
+type idempotencyKey struct{}
+
+// WithPolicy overrides the policy of the requests made with the returned context.
+func WithPolicy(ctx context.Context, p Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// WithoutRetry disables the retries of the requests made with the returned context.
+func WithoutRetry(ctx context.Context) context.Context {
+	return WithPolicy(ctx, Policy{MaxAttempts: 1})
+}
+
+// WithIdempotencyKey sets the IdempotencyKeyHeader of the requests made with the returned
+// context, so that they are retried whatever their method.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// Transport is a http.RoundTripper which retries the failed requests according to Policy,
+// or to the policy set on the request context.
+type Transport struct {
+	Policy Policy
+	// Base defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewTransport creates a transport which retries the requests according to p.
+func NewTransport(base http.RoundTripper, p Policy) *Transport {
+	return &Transport{Policy: p, Base: base}
+}
+
+// Middleware returns the sdk middleware which retries the requests according to p. The
+// retries of the rest clients themselves, the MaxRetries of their config, should be disabled.
+func Middleware(p Policy) sdk.Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(base, p)
+	}
+}
+
+// RoundTrip sends the request, and sends it again while it fails and may be retried.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	p := t.Policy
+	if override, ok := ctx.Value(policyKey{}).(Policy); ok {
+		p = override
+	}
+
+	if key, ok := ctx.Value(idempotencyKey{}).(string); ok && key != "" {
+		req = req.Clone(ctx)
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if !retryable || attempt >= p.MaxAttempts || !p.shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := jitter(backoff)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			resp.Body.Close()
+		}
+
+		if err := t.wait(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+func (t *Transport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (p Policy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	for _, status := range p.RetryableStatus {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isIdempotent reports whether the request can be sent more than once, see RFC 7231 4.2.2.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// retryAfter returns the delay of the Retry-After header in seconds, capped to a minute.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	if d := time.Duration(seconds) * time.Second; d < time.Minute {
+		return d, true
+	}
+
+	return time.Minute, true
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	// nolint: gosec
+	return d - time.Duration(rand.Int63n(int64(d)/2+1))
+}
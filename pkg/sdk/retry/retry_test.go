@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package retry
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		ctx          func(ctx context.Context) context.Context
+		failures     int32
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{name: "get_recovers", method: http.MethodGet, failures: 2, wantStatus: http.StatusOK, wantAttempts: 3},
+		{
+			name:         "get_gives_up",
+			method:       http.MethodGet,
+			failures:     5,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 3,
+		},
+		{
+			name:         "post_not_retried",
+			method:       http.MethodPost,
+			failures:     1,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:   "post_with_idempotency_key",
+			method: http.MethodPost,
+			ctx: func(ctx context.Context) context.Context {
+				return WithIdempotencyKey(ctx, "create-user-colin")
+			},
+			failures:     1,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "per_call_override",
+			method:       http.MethodGet,
+			ctx:          WithoutRetry,
+			failures:     1,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+
+				// the body is sent again on every attempt
+				if body, _ := ioutil.ReadAll(r.Body); tt.method == http.MethodPost && string(body) != `{"name":"colin"}` {
+					w.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				if n <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			rt := NewTransport(nil, DefaultPolicy())
+			rt.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+			ctx := context.TODO()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+
+			req, _ := http.NewRequestWithContext(ctx, tt.method, srv.URL, strings.NewReader(`{"name":"colin"}`))
+			resp, err := (&http.Client{Transport: rt}).Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || attempts != tt.wantAttempts {
+				t.Errorf("Do() = %d after %d attempts, want %d after %d attempts",
+					resp.StatusCode, attempts, tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": {"2"}}}
+	if d, ok := retryAfter(resp); !ok || d != 2*time.Second {
+		t.Errorf("retryAfter() = %v, %v, want 2s, true", d, ok)
+	}
+
+	resp.Header.Set("Retry-After", "Wed, 21 Oct 2015 07:28:00 GMT")
+	if _, ok := retryAfter(resp); ok {
+		t.Error("retryAfter() of a http date = true, want false")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// ServiceName is the name of the grpc service of iam-apiserver.
+const ServiceName = "iam.apiserver.v1.API"
+
+// Request is the message of all the methods.
+type Request struct {
+	// Name is the name of the object got, updated or deleted.
+	Name string `json:"name,omitempty"`
+	// Body is the object created or updated, or the delete options.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Query holds the list options of the list and delete collection methods.
+	Query url.Values `json:"query,omitempty"`
+}
+
+// Response is the result of all the methods, Body is the object or the list returned.
+type Response struct {
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Method is a method of the service and the rest api it maps to.
+type Method struct {
+	Name string
+	// HTTPMethod and Resource are the verb and the resource of the rest api, a named method
+	// is served at /v1/<resource>/<name>.
+	HTTPMethod string
+	Resource   string
+	Named      bool
+}
+
+// FullName returns the full grpc method name, e.g. /iam.apiserver.v1.API/GetUser.
+func (m Method) FullName() string {
+	return "/" + ServiceName + "/" + m.Name
+}
+
+// Path returns the path of the rest api serving the request.
+func (m Method) Path(name string) string {
+	if !m.Named {
+		return "/v1/" + m.Resource
+	}
+
+	return "/v1/" + m.Resource + "/" + url.PathEscape(name)
+}
+
+// Methods of the service.
+var (
+	CreateUser  = Method{Name: "CreateUser", HTTPMethod: http.MethodPost, Resource: "users"}
+	UpdateUser  = Method{Name: "UpdateUser", HTTPMethod: http.MethodPut, Resource: "users", Named: true}
+	DeleteUser  = Method{Name: "DeleteUser", HTTPMethod: http.MethodDelete, Resource: "users", Named: true}
+	DeleteUsers = Method{Name: "DeleteUsers", HTTPMethod: http.MethodDelete, Resource: "users"}
+	GetUser     = Method{Name: "GetUser", HTTPMethod: http.MethodGet, Resource: "users", Named: true}
+	ListUsers   = Method{Name: "ListUsers", HTTPMethod: http.MethodGet, Resource: "users"}
+
+	CreateSecret  = Method{Name: "CreateSecret", HTTPMethod: http.MethodPost, Resource: "secrets"}
+	UpdateSecret  = Method{Name: "UpdateSecret", HTTPMethod: http.MethodPut, Resource: "secrets", Named: true}
+	DeleteSecret  = Method{Name: "DeleteSecret", HTTPMethod: http.MethodDelete, Resource: "secrets", Named: true}
+	DeleteSecrets = Method{Name: "DeleteSecrets", HTTPMethod: http.MethodDelete, Resource: "secrets"}
+	GetSecret     = Method{Name: "GetSecret", HTTPMethod: http.MethodGet, Resource: "secrets", Named: true}
+	ListSecrets   = Method{Name: "ListSecrets", HTTPMethod: http.MethodGet, Resource: "secrets"}
+
+	CreatePolicy   = Method{Name: "CreatePolicy", HTTPMethod: http.MethodPost, Resource: "policies"}
+	UpdatePolicy   = Method{Name: "UpdatePolicy", HTTPMethod: http.MethodPut, Resource: "policies", Named: true}
+	DeletePolicy   = Method{Name: "DeletePolicy", HTTPMethod: http.MethodDelete, Resource: "policies", Named: true}
+	DeletePolicies = Method{Name: "DeletePolicies", HTTPMethod: http.MethodDelete, Resource: "policies"}
+	GetPolicy      = Method{Name: "GetPolicy", HTTPMethod: http.MethodGet, Resource: "policies", Named: true}
+	ListPolicies   = Method{Name: "ListPolicies", HTTPMethod: http.MethodGet, Resource: "policies"}
+)
+
+// Methods returns all the methods of the service.
+func Methods() []Method {
+	return []Method{
+		CreateUser, UpdateUser, DeleteUser, DeleteUsers, GetUser, ListUsers,
+		CreateSecret, UpdateSecret, DeleteSecret, DeleteSecrets, GetSecret, ListSecrets,
+		CreatePolicy, UpdatePolicy, DeletePolicy, DeletePolicies, GetPolicy, ListPolicies,
+	}
+}
+
+// Codec encodes the messages as json, it is registered as the json content subtype.
+type Codec struct{}
+
+// Marshal returns the json encoding of v.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal parses the json encoded data into v.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name returns the content subtype of the codec.
+func (Codec) Name() string {
+	return "json"
+}
+
+// nolint: gochecknoinits
+func init() {
+	encoding.RegisterCodec(Codec{})
+}
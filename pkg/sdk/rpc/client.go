@@ -0,0 +1,210 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	apiv1 "github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam/apiserver/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+var _ apiv1.APIV1Interface = &APIV1Client{}
+
+// APIV1Client implements the iam-apiserver clients of marmotedu-sdk-go over the grpc api.
+type APIV1Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewAPIV1Client creates the clients calling the methods on the connection.
+func NewAPIV1Client(conn grpc.ClientConnInterface) *APIV1Client {
+	return &APIV1Client{conn: conn}
+}
+
+// NewForConfig dials the grpc server of iam-apiserver at address, with the credentials and the
+// tls configuration of the rest config. The extra dial options are applied last, so they may
+// override the transport credentials.
+func NewForConfig(address string, config *rest.Config, dialOpts ...grpc.DialOption) (*APIV1Client, *grpc.ClientConn, error) {
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the grpc server of iam-apiserver always serves tls
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	if creds := newCredentials(config); creds != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(creds))
+	}
+
+	// the connection is established lazily and re-established by grpc when it is lost
+	conn, err := grpc.Dial(address, append(opts, dialOpts...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewAPIV1Client(conn), conn, nil
+}
+
+// Users returns the users client.
+func (c *APIV1Client) Users() apiv1.UserInterface {
+	return &users{c}
+}
+
+// Secrets returns the secrets client.
+func (c *APIV1Client) Secrets() apiv1.SecretInterface {
+	return &secrets{c}
+}
+
+// Policies returns the policies client.
+func (c *APIV1Client) Policies() apiv1.PolicyInterface {
+	return &policies{c}
+}
+
+// RESTClient returns nil, the clients do not use a rest client.
+func (c *APIV1Client) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+
+	return ret
+}
+
+// invoke calls the method, body is encoded as the body of the request and the body of the
+// response is decoded into out when it is not nil.
+func (c *APIV1Client) invoke(ctx context.Context, m Method, req *Request, body interface{}, out interface{}) error {
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		req.Body = data
+	}
+
+	var rsp Response
+	if err := c.conn.Invoke(ctx, m.FullName(), req, &rsp, grpc.CallContentSubtype(Codec{}.Name())); err != nil {
+		return errorOf(err)
+	}
+
+	if out == nil || len(rsp.Body) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(rsp.Body, out)
+}
+
+// errorOf returns the error body the rest clients return as error for the status error, built
+// from the business code and the reference of its ErrorInfo details, and the invalid fields of
+// its BadRequest details.
+func errorOf(err error) error {
+	s, ok := status.FromError(err)
+	if !ok || s.Message() == "" {
+		return err
+	}
+
+	body := map[string]interface{}{"message": s.Message()}
+	for _, detail := range s.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if businessCode, err := strconv.Atoi(d.GetMetadata()["code"]); err == nil {
+				body["code"] = businessCode
+				body["reference"] = d.GetMetadata()["reference"]
+			}
+		case *errdetails.BadRequest:
+			fields := make([]map[string]string, 0, len(d.GetFieldViolations()))
+			for _, v := range d.GetFieldViolations() {
+				fields = append(fields, map[string]string{"field": v.GetField(), "message": v.GetDescription()})
+			}
+			body["details"] = fields
+		}
+	}
+
+	if _, ok := body["code"]; !ok {
+		return errors.New(s.Message())
+	}
+
+	data, _ := json.Marshal(body)
+
+	return errors.New(string(data))
+}
+
+// withTimeout applies the timeout of the list options, like the rest clients do.
+func withTimeout(ctx context.Context, timeoutSeconds *int64) (context.Context, context.CancelFunc) {
+	if timeoutSeconds == nil || *timeoutSeconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(*timeoutSeconds)*time.Second)
+}
+
+// encodeQuery encodes the options as the query string, with the json names of the fields like
+// the rest clients do.
+func encodeQuery(opts interface{}) (url.Values, error) {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	for key, value := range fields {
+		switch v := value.(type) {
+		case nil:
+		case map[string]interface{}, []interface{}:
+			data, _ := json.Marshal(v)
+			query.Set(key, string(data))
+		default:
+			query.Set(key, fmt.Sprint(v))
+		}
+	}
+
+	return query, nil
+}
+
+// authCredentials sends the credentials of the rest config as the authorization metadata.
+type authCredentials struct {
+	authorization string
+}
+
+func newCredentials(config *rest.Config) credentials.PerRPCCredentials {
+	switch {
+	case config.BearerToken != "":
+		return &authCredentials{authorization: "Bearer " + config.BearerToken}
+	case config.Username != "":
+		userpass := base64.StdEncoding.EncodeToString([]byte(config.Username + ":" + config.Password))
+
+		return &authCredentials{authorization: "Basic " + userpass}
+	default:
+		return nil
+	}
+}
+
+func (a *authCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": a.authorization}, nil
+}
+
+func (a *authCredentials) RequireTransportSecurity() bool {
+	return true
+}
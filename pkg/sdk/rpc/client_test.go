@@ -0,0 +1,52 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+func TestEncodeQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		opts metav1.ListOptions
+		want url.Values
+	}{
+		{name: "empty", opts: metav1.ListOptions{}, want: url.Values{}},
+		{
+			name: "pagination",
+			opts: metav1.ListOptions{Offset: pointer.ToInt64(0), Limit: pointer.ToInt64(1000000)},
+			want: url.Values{"offset": {"0"}, "limit": {"1000000"}},
+		},
+		{
+			name: "selector",
+			opts: metav1.ListOptions{FieldSelector: "name=colin"},
+			want: url.Values{"fieldSelector": {"name=colin"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeQuery(tt.opts)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encodeQuery() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMethod_Path(t *testing.T) {
+	if got := GetUser.Path("colin"); got != "/v1/users/colin" {
+		t.Errorf("Path() = %s, want /v1/users/colin", got)
+	}
+
+	if got := ListPolicies.Path(""); got != "/v1/policies" {
+		t.Errorf("Path() = %s, want /v1/policies", got)
+	}
+}
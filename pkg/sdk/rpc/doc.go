@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+/*
+Package rpc defines the grpc api of iam-apiserver and implements the typed clients of
+marmotedu-sdk-go over it, for the internal services which keep a multiplexed connection to
+iam-apiserver instead of sending a http request per call.
+
+The service iam.apiserver.v1.API has a method per verb and resource of the rest api, e.g.
+GetUser or ListPolicies. The messages are encoded as json with the application/grpc+json
+content subtype:
+
+	Request:  {"name": "colin", "body": {...}, "query": {"offset": ["0"], "limit": ["20"]}}
+	Response: {"body": {...}}
+
+The calls are served by the rest handlers of iam-apiserver, so they are authenticated, validated
+and published the same way. The authorization metadata carries the basic or bearer credentials,
+and the failures are returned with the grpc code of the http status and the rest error body
+as message.
+*/
+package rpc // import "github.com/marmotedu/iam/pkg/sdk/rpc"
@@ -0,0 +1,69 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// policies implements apiv1.PolicyInterface over the grpc api.
+type policies struct {
+	client *APIV1Client
+}
+
+func (c *policies) Create(ctx context.Context, obj *v1.Policy, opts metav1.CreateOptions) (*v1.Policy, error) {
+	result := &v1.Policy{}
+	err := c.client.invoke(ctx, CreatePolicy, &Request{}, obj, result)
+
+	return result, err
+}
+
+func (c *policies) Update(ctx context.Context, obj *v1.Policy, opts metav1.UpdateOptions) (*v1.Policy, error) {
+	result := &v1.Policy{}
+	err := c.client.invoke(ctx, UpdatePolicy, &Request{Name: obj.Name}, obj, result)
+
+	return result, err
+}
+
+func (c *policies) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.invoke(ctx, DeletePolicy, &Request{Name: name}, &opts, nil)
+}
+
+func (c *policies) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	query, err := encodeQuery(listOpts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, listOpts.TimeoutSeconds)
+	defer cancel()
+
+	return c.client.invoke(ctx, DeletePolicies, &Request{Query: query}, &opts, nil)
+}
+
+func (c *policies) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Policy, error) {
+	result := &v1.Policy{}
+	err := c.client.invoke(ctx, GetPolicy, &Request{Name: name}, nil, result)
+
+	return result, err
+}
+
+func (c *policies) List(ctx context.Context, opts metav1.ListOptions) (*v1.PolicyList, error) {
+	query, err := encodeQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, opts.TimeoutSeconds)
+	defer cancel()
+
+	result := &v1.PolicyList{}
+	err = c.client.invoke(ctx, ListPolicies, &Request{Query: query}, nil, result)
+
+	return result, err
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// secrets implements apiv1.SecretInterface over the grpc api.
+type secrets struct {
+	client *APIV1Client
+}
+
+func (c *secrets) Create(ctx context.Context, obj *v1.Secret, opts metav1.CreateOptions) (*v1.Secret, error) {
+	result := &v1.Secret{}
+	err := c.client.invoke(ctx, CreateSecret, &Request{}, obj, result)
+
+	return result, err
+}
+
+func (c *secrets) Update(ctx context.Context, obj *v1.Secret, opts metav1.UpdateOptions) (*v1.Secret, error) {
+	result := &v1.Secret{}
+	err := c.client.invoke(ctx, UpdateSecret, &Request{Name: obj.Name}, obj, result)
+
+	return result, err
+}
+
+func (c *secrets) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.invoke(ctx, DeleteSecret, &Request{Name: name}, &opts, nil)
+}
+
+func (c *secrets) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	query, err := encodeQuery(listOpts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, listOpts.TimeoutSeconds)
+	defer cancel()
+
+	return c.client.invoke(ctx, DeleteSecrets, &Request{Query: query}, &opts, nil)
+}
+
+func (c *secrets) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Secret, error) {
+	result := &v1.Secret{}
+	err := c.client.invoke(ctx, GetSecret, &Request{Name: name}, nil, result)
+
+	return result, err
+}
+
+func (c *secrets) List(ctx context.Context, opts metav1.ListOptions) (*v1.SecretList, error) {
+	query, err := encodeQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, opts.TimeoutSeconds)
+	defer cancel()
+
+	result := &v1.SecretList{}
+	err = c.client.invoke(ctx, ListSecrets, &Request{Query: query}, nil, result)
+
+	return result, err
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// users implements apiv1.UserInterface over the grpc api.
+type users struct {
+	client *APIV1Client
+}
+
+func (c *users) Create(ctx context.Context, obj *v1.User, opts metav1.CreateOptions) (*v1.User, error) {
+	result := &v1.User{}
+	err := c.client.invoke(ctx, CreateUser, &Request{}, obj, result)
+
+	return result, err
+}
+
+func (c *users) Update(ctx context.Context, obj *v1.User, opts metav1.UpdateOptions) (*v1.User, error) {
+	result := &v1.User{}
+	err := c.client.invoke(ctx, UpdateUser, &Request{Name: obj.Name}, obj, result)
+
+	return result, err
+}
+
+func (c *users) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.invoke(ctx, DeleteUser, &Request{Name: name}, &opts, nil)
+}
+
+func (c *users) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	query, err := encodeQuery(listOpts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, listOpts.TimeoutSeconds)
+	defer cancel()
+
+	return c.client.invoke(ctx, DeleteUsers, &Request{Query: query}, &opts, nil)
+}
+
+func (c *users) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.User, error) {
+	result := &v1.User{}
+	err := c.client.invoke(ctx, GetUser, &Request{Name: name}, nil, result)
+
+	return result, err
+}
+
+func (c *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+	query, err := encodeQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, opts.TimeoutSeconds)
+	defer cancel()
+
+	result := &v1.UserList{}
+	err = c.client.invoke(ctx, ListUsers, &Request{Query: query}, nil, result)
+
+	return result, err
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+/*
+Package signer signs the http requests with the secretKey of a secret, so the requests can be
+authenticated by iam-authz-server without sending a bearer token.
+
+A signed request carries the following headers:
+
+	X-Iam-Date: 20201102T150405Z
+	X-Iam-Content-Sha256: <hex encoded sha256 of the body>
+	Authorization: IAM-HMAC-SHA256 Credential=<secretID>, SignedHeaders=host;x-iam-content-sha256;x-iam-date, Signature=<hex>
+
+The signature is the HMAC-SHA256 of the string to sign, keyed by the HMAC-SHA256 of the date
+keyed by "IAM" and the secretKey:
+
+	IAM-HMAC-SHA256
+	<X-Iam-Date>
+	<hex encoded sha256 of the canonical request>
+
+The canonical request is, each item on its own line, the method, the escaped path, the sorted
+query string, the lower-cased signed headers as name:value, the signed header names and the
+body hash. A signature is valid for a few minutes around its date and is accepted only once.
+*/
+package signer // import "github.com/marmotedu/iam/pkg/sdk/signer"
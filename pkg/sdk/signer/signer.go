@@ -0,0 +1,268 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package signer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Algorithm is the scheme of the Authorization header of the signed requests.
+const Algorithm = "IAM-HMAC-SHA256"
+
+// Headers of the signed requests.
+const (
+	// DateHeader is the time the request is signed at, in the TimeFormat.
+	DateHeader = "X-Iam-Date"
+	// ContentSHA256Header is the hex encoded sha256 of the request body.
+	ContentSHA256Header = "X-Iam-Content-Sha256"
+	// AudienceHeader is the optional audience of the request, checked against the audiences of
+	// the secret like the aud claim of a bearer token.
+	AudienceHeader = "X-Iam-Audience"
+)
+
+// TimeFormat is the format of the DateHeader.
+const TimeFormat = "20060102T150405Z"
+
+// DefaultMaxSkew is how far the date of a signed request may be from the server time.
+const DefaultMaxSkew = 5 * time.Minute
+
+var errInvalidAuthorization = errors.New("invalid signature authorization header")
+
+// Signer signs the http requests with a secret.
+type Signer struct {
+	SecretID  string
+	SecretKey string
+	// Audience is set to the AudienceHeader when not empty.
+	Audience string
+
+	now func() time.Time
+}
+
+// New creates a signer for the secret.
+func New(secretID, secretKey string) *Signer {
+	return &Signer{
+		SecretID:  secretID,
+		SecretKey: secretKey,
+		now:       time.Now,
+	}
+}
+
+// Sign sets the signature headers of the request, the body is read and restored.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set(DateHeader, now().UTC().Format(TimeFormat))
+	req.Header.Set(ContentSHA256Header, hashHex(body))
+
+	signedHeaders := []string{"host", strings.ToLower(ContentSHA256Header), strings.ToLower(DateHeader)}
+	if s.Audience != "" {
+		req.Header.Set(AudienceHeader, s.Audience)
+		signedHeaders = append(signedHeaders, strings.ToLower(AudienceHeader))
+	}
+	sort.Strings(signedHeaders)
+
+	signature, err := Signature(req, signedHeaders, s.SecretKey)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s, SignedHeaders=%s, Signature=%s",
+		Algorithm, s.SecretID, strings.Join(signedHeaders, ";"), signature))
+
+	return nil
+}
+
+// Authorization is the parsed Authorization header of a signed request.
+type Authorization struct {
+	SecretID      string
+	SignedHeaders []string
+	Signature     string
+}
+
+// ParseAuthorization parses the Authorization header of a signed request.
+func ParseAuthorization(header string) (*Authorization, error) {
+	params := strings.TrimPrefix(header, Algorithm+" ")
+	if params == header {
+		return nil, errInvalidAuthorization
+	}
+
+	a := &Authorization{}
+	for _, param := range strings.Split(params, ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 {
+			return nil, errInvalidAuthorization
+		}
+
+		switch kv[0] {
+		case "Credential":
+			a.SecretID = kv[1]
+		case "SignedHeaders":
+			a.SignedHeaders = strings.Split(kv[1], ";")
+		case "Signature":
+			a.Signature = kv[1]
+		}
+	}
+
+	if a.SecretID == "" || a.Signature == "" || !signs(a.SignedHeaders, "host", DateHeader, ContentSHA256Header) {
+		return nil, errInvalidAuthorization
+	}
+
+	return a, nil
+}
+
+// Verify verifies the signature of the request with the secretKey of the secret in the
+// Authorization header. The request body must match the ContentSHA256Header and the
+// DateHeader must be within maxSkew of now.
+func Verify(req *http.Request, a *Authorization, secretKey string, maxSkew time.Duration, now time.Time) error {
+	date, err := time.Parse(TimeFormat, req.Header.Get(DateHeader))
+	if err != nil {
+		return fmt.Errorf("invalid %s header", DateHeader)
+	}
+
+	if date.Before(now.Add(-maxSkew)) || date.After(now.Add(maxSkew)) {
+		return fmt.Errorf("%s is out of the allowed clock skew", DateHeader)
+	}
+
+	if req.Header.Get(AudienceHeader) != "" && !signs(a.SignedHeaders, AudienceHeader) {
+		return fmt.Errorf("%s is not signed", AudienceHeader)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(hashHex(body)), []byte(req.Header.Get(ContentSHA256Header))) {
+		return errors.New("request body does not match the signed content hash")
+	}
+
+	signature, err := Signature(req, a.SignedHeaders, secretKey)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(a.Signature)) {
+		return errors.New("signature does not match")
+	}
+
+	return nil
+}
+
+// Signature computes the hex encoded signature of the request over the signed headers.
+func Signature(req *http.Request, signedHeaders []string, secretKey string) (string, error) {
+	date := req.Header.Get(DateHeader)
+	if len(date) < len("20060102") {
+		return "", fmt.Errorf("invalid %s header", DateHeader)
+	}
+
+	canonical, err := canonicalRequest(req, signedHeaders)
+	if err != nil {
+		return "", err
+	}
+
+	stringToSign := strings.Join([]string{Algorithm, date, hashHex([]byte(canonical))}, "\n")
+	key := hmacSHA256([]byte("IAM"+secretKey), []byte(date[:len("20060102")]))
+
+	return hex.EncodeToString(hmacSHA256(key, []byte(stringToSign))), nil
+}
+
+func canonicalRequest(req *http.Request, signedHeaders []string) (string, error) {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	lines := []string{req.Method, path, req.URL.Query().Encode()}
+	for _, name := range signedHeaders {
+		var value string
+		if name == "host" {
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		} else {
+			values, ok := req.Header[http.CanonicalHeaderKey(name)]
+			if !ok {
+				return "", fmt.Errorf("signed header %s is missing", name)
+			}
+			value = strings.Join(values, ",")
+		}
+
+		lines = append(lines, name+":"+strings.TrimSpace(value))
+	}
+
+	lines = append(lines, strings.Join(signedHeaders, ";"), req.Header.Get(ContentSHA256Header))
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// readBody reads the request body and restores it so that it can be sent or read again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func signs(signedHeaders []string, names ...string) bool {
+	for _, name := range names {
+		found := false
+		for _, h := range signedHeaders {
+			if h == strings.ToLower(name) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package signer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2020, 11, 2, 15, 4, 5, 0, time.UTC)
+	body := `{"subject":"users:peter","action":"delete","resource":"resources:printer"}`
+
+	tests := []struct {
+		name    string
+		tamper  func(r *http.Request)
+		now     time.Time
+		key     string
+		wantErr bool
+	}{
+		{name: "valid", now: now, key: "secret"},
+		{name: "wrong_key", now: now, key: "other", wantErr: true},
+		{name: "expired", now: now.Add(DefaultMaxSkew + time.Second), key: "secret", wantErr: true},
+		{
+			name:    "tampered_body",
+			tamper:  func(r *http.Request) { r.Body = ioutil.NopCloser(strings.NewReader(`{}`)) },
+			now:     now,
+			key:     "secret",
+			wantErr: true,
+		},
+		{
+			name:    "tampered_path",
+			tamper:  func(r *http.Request) { r.URL.Path = "/v1/subjectaccessreviews" },
+			now:     now,
+			key:     "secret",
+			wantErr: true,
+		},
+		{
+			name:    "unsigned_audience",
+			tamper:  func(r *http.Request) { r.Header.Set(AudienceHeader, "iam.authz.marmotedu.com") },
+			now:     now,
+			key:     "secret",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New("id", "secret")
+			s.now = func() time.Time { return now }
+
+			r := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:9090/v1/authz?b=2&a=1", strings.NewReader(body))
+			if err := s.Sign(r); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			if tt.tamper != nil {
+				tt.tamper(r)
+			}
+
+			a, err := ParseAuthorization(r.Header.Get("Authorization"))
+			if err != nil {
+				t.Fatalf("ParseAuthorization() error = %v", err)
+			}
+			if a.SecretID != "id" {
+				t.Errorf("ParseAuthorization() secretID = %s, want id", a.SecretID)
+			}
+
+			if err := Verify(r, a, tt.key, DefaultMaxSkew, tt.now); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			header: Algorithm + " Credential=id, SignedHeaders=host;x-iam-content-sha256;x-iam-date, Signature=abc",
+		},
+		{name: "bearer", header: "Bearer abc", wantErr: true},
+		{
+			name:    "missing_signed_header",
+			header:  Algorithm + " Credential=id, SignedHeaders=host, Signature=abc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAuthorization(tt.header); (err != nil) != tt.wantErr {
+				t.Errorf("ParseAuthorization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a, err := ParseAuthorization(r.Header.Get("Authorization"))
+		if err == nil {
+			err = Verify(r, a, "secret", DefaultMaxSkew, time.Now())
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, New("id", "secret"))}
+	resp, err := client.Post(srv.URL+"/v1/authz", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Post() status = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package signer
+
+import (
+	"net/http"
+
+	"github.com/marmotedu/iam/pkg/sdk"
+)
+
+// Transport is a http.RoundTripper which signs the requests before sending them with Base.
+type Transport struct {
+	Signer *Signer
+	// Base defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport which signs the requests with s.
+func NewTransport(base http.RoundTripper, s *Signer) *Transport {
+	return &Transport{Signer: s, Base: base}
+}
+
+// RoundTrip signs a clone of the request and sends it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := t.Signer.Sign(req); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return base.RoundTrip(req)
+}
+
+// Middleware returns the sdk middleware which signs the requests with s.
+func Middleware(s *Signer) sdk.Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(base, s)
+	}
+}
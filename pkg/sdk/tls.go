@@ -0,0 +1,94 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+)
+
+// TLSConfig returns the tls configuration of the clients: the CA bundle, the client certificate
+// and the server name of the rest config. The CA bundle replaces the system roots unless
+// SystemRoots is set, e.g. to add the CA of a tls inspecting proxy. It returns nil when the
+// default configuration is used.
+func TLSConfig(c *Config) (*tls.Config, error) {
+	// rest.TLSConfigFor loads the files into the config
+	config := rest.CopyConfig(c.Config)
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil || tlsConfig == nil {
+		return tlsConfig, err
+	}
+
+	if !config.HasCA() {
+		return tlsConfig, nil
+	}
+
+	pool := x509.NewCertPool()
+	if c.SystemRoots {
+		if pool, err = x509.SystemCertPool(); err != nil {
+			return nil, fmt.Errorf("load system roots: %w", err)
+		}
+	}
+
+	// a bundle without any certificate would fail all the handshakes
+	if !pool.AppendCertsFromPEM(config.CAData) {
+		return nil, fmt.Errorf("no certificate found in the CA bundle %s", config.CAFile)
+	}
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
+
+// ProxyFunc returns the proxy of the requests: the proxy url when it is set, or the proxy of the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+func ProxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q", proxyURL)
+	}
+
+	return http.ProxyURL(u), nil
+}
+
+// ConfigureTransport applies the tls and the proxy configuration to the transport of the rest
+// client, whose transport ignores the proxy environment variables by default.
+func ConfigureTransport(c rest.Interface, config *Config) error {
+	client, ok := c.(*rest.RESTClient)
+	if !ok {
+		return fmt.Errorf("unexpected rest client type %T", c)
+	}
+
+	tlsConfig, err := TLSConfig(config)
+	if err != nil {
+		return err
+	}
+
+	proxy, err := ProxyFunc(config.ProxyURL)
+	if err != nil {
+		return err
+	}
+
+	if tlsConfig != nil {
+		client.Client.Transport.TLSClientConfig = tlsConfig
+	}
+	client.Client.Transport.Proxy = proxy
+
+	// the transport is not swapped in when the swap is disabled by Wrap
+	if client.Client.Client.Transport == nil {
+		client.Client.Client.Transport = client.Client.Transport
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+)
+
+func userHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"metadata":{"name":"colin"}}`))
+}
+
+func TestNewIAMClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(userHandler))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty.pem")
+	if err := ioutil.WriteFile(emptyFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		tls         rest.TLSClientConfig
+		systemRoots bool
+		wantErr     bool
+		wantGetErr  bool
+	}{
+		{name: "unknown authority", tls: rest.TLSClientConfig{}, wantGetErr: true},
+		{name: "CA bundle", tls: rest.TLSClientConfig{CAFile: caFile}},
+		{name: "CA bundle and system roots", tls: rest.TLSClientConfig{CAFile: caFile}, systemRoots: true},
+		{name: "SNI override", tls: rest.TLSClientConfig{CAFile: caFile, ServerName: "iam.invalid"}, wantGetErr: true},
+		{name: "invalid bundle", tls: rest.TLSClientConfig{CAFile: emptyFile}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewIAMClient(&Config{
+				Config:      &rest.Config{Host: server.URL, TLSClientConfig: tt.tls},
+				SystemRoots: tt.systemRoots,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIAMClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			_, err = client.APIV1().Users().Get(context.Background(), "colin", metav1.GetOptions{})
+			if (err != nil) != tt.wantGetErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantGetErr)
+			}
+		})
+	}
+}
+
+func TestNewIAMClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a forward proxy receives the absolute url
+		proxied = r.URL.String()
+		userHandler(w, r)
+	}))
+	defer proxy.Close()
+
+	client, err := NewIAMClient(&Config{
+		Config:   &rest.Config{Host: "http://iam.example.com:8080"},
+		ProxyURL: proxy.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.APIV1().Users().Get(context.Background(), "colin", metav1.GetOptions{}); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if proxied != "http://iam.example.com:8080/v1/users/colin" {
+		t.Errorf("Get() is sent to the proxy as %q", proxied)
+	}
+
+	if _, err := NewIAMClient(&Config{Config: &rest.Config{Host: "http://127.0.0.1"}, ProxyURL: "://proxy"}); err == nil {
+		t.Errorf("NewIAMClient() accepts an invalid proxy url")
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package token obtains the access tokens of a secret from the OAuth 2.0 token endpoint of
+// iam-authz-server, caches them on disk and refreshes them before they expire.
+package token // import "github.com/marmotedu/iam/pkg/sdk/token"
@@ -0,0 +1,61 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockTimeout is how long to wait for the lock held by another process.
+	lockTimeout = 10 * time.Second
+	// lockStale is the age after which a lock is considered left by a crashed process.
+	lockStale = 30 * time.Second
+	lockRetry = 50 * time.Millisecond
+)
+
+// withLock runs fn while holding the lock of the file. The lock is a <file>.lock file created
+// exclusively, which works the same on every platform.
+func withLock(file string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+
+	lock := file + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+
+			break
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(lock)
+
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the lock %s", lock)
+		}
+
+		time.Sleep(lockRetry)
+	}
+
+	defer os.Remove(lock)
+
+	return fn()
+}
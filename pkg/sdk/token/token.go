@@ -0,0 +1,232 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// DefaultRefreshBefore is how long before its expiry a token is refreshed.
+const DefaultRefreshBefore = 5 * time.Minute
+
+// Token is an access token and when it expires.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// valid reports whether the token can still be used for refreshBefore.
+func (t *Token) valid(now time.Time, refreshBefore time.Duration) bool {
+	return t != nil && t.AccessToken != "" && now.Add(refreshBefore).Before(t.Expiry)
+}
+
+// Source obtains the access tokens of a secret with the client_credentials grant.
+type Source struct {
+	// TokenURL is the token endpoint, e.g. http://127.0.0.1:9090/oauth/token.
+	TokenURL  string
+	SecretID  string
+	SecretKey string
+	// Scopes are the audiences of the tokens, the server defaults to iam-authz-server.
+	Scopes []string
+	// CacheDir is the directory the tokens are cached in, no token is cached on disk when empty.
+	CacheDir      string
+	RefreshBefore time.Duration
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	lock  sync.Mutex
+	token *Token
+	now   func() time.Time
+}
+
+// NewSource creates a token source of the secret which caches the tokens in $HOME/.iam/cache/tokens.
+func NewSource(tokenURL, secretID, secretKey string) *Source {
+	s := &Source{
+		TokenURL:      tokenURL,
+		SecretID:      secretID,
+		SecretKey:     secretKey,
+		RefreshBefore: DefaultRefreshBefore,
+		now:           time.Now,
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		s.CacheDir = filepath.Join(home, ".iam", "cache", "tokens")
+	}
+
+	return s
+}
+
+// Token returns a token which is valid for at least RefreshBefore, the token is read from
+// memory, then from the cache file, and finally obtained from the token endpoint.
+func (s *Source) Token(ctx context.Context) (*Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.clock()
+	if s.token.valid(now, s.RefreshBefore) {
+		return s.token, nil
+	}
+
+	if s.CacheDir == "" {
+		token, err := s.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.token = token
+
+		return token, nil
+	}
+
+	// the cache file is locked while the token is refreshed, so that the processes sharing the
+	// cache obtain a single token
+	var token *Token
+	err := withLock(s.cacheFile(), func() error {
+		token = readToken(s.cacheFile())
+		if token.valid(now, s.RefreshBefore) {
+			return nil
+		}
+
+		var err error
+		if token, err = s.fetch(ctx); err != nil {
+			return err
+		}
+
+		return writeToken(s.cacheFile(), token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+
+	return token, nil
+}
+
+// Invalidate drops the token when it is rejected by the server, so that the next call of
+// Token obtains a new one.
+func (s *Source) Invalidate(token *Token) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token != nil && token != nil && s.token.AccessToken != token.AccessToken {
+		return
+	}
+	s.token = nil
+
+	if s.CacheDir != "" {
+		_ = withLock(s.cacheFile(), func() error {
+			if cached := readToken(s.cacheFile()); cached != nil && token != nil &&
+				cached.AccessToken != token.AccessToken {
+				return nil
+			}
+
+			return os.Remove(s.cacheFile())
+		})
+	}
+}
+
+// cacheFile is named after the token endpoint, the secret and the scopes, so the secretKey
+// never appears in the file name.
+func (s *Source) cacheFile() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{s.TokenURL, s.SecretID, s.SecretKey,
+		strings.Join(s.Scopes, " ")}, "\n")))
+
+	return filepath.Join(s.CacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (s *Source) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+
+	return s.now()
+}
+
+func (s *Source) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(s.SecretID), url.QueryEscape(s.SecretKey))
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	now := s.clock()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode token response failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("obtain token failed: %d %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+
+	return &Token{
+		AccessToken: body.AccessToken,
+		Expiry:      now.Add(time.Duration(body.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func readToken(file string) *Token {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil
+	}
+
+	return &token
+}
+
+// writeToken writes the token to a temporary file which is renamed, so that a reader never
+// sees a partially written token.
+func writeToken(file string, token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
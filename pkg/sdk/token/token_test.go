@@ -0,0 +1,108 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer issues the tokens token-1, token-2, ... which expire in expiresIn seconds.
+func newTokenServer(t *testing.T, expiresIn int64) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var issued int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, key, ok := r.BasicAuth()
+		if !ok || id != "id" || key != "key" || r.PostFormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+
+			return
+		}
+
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &issued
+}
+
+func TestSource_Token(t *testing.T) {
+	srv, issued := newTokenServer(t, 3600)
+	dir := t.TempDir()
+
+	s := NewSource(srv.URL, "id", "key")
+	s.CacheDir = dir
+
+	token, err := s.Token(context.TODO())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "token-1" {
+		t.Errorf("Token() = %s, want token-1", token.AccessToken)
+	}
+
+	// another process shares the cached token
+	other := NewSource(srv.URL, "id", "key")
+	other.CacheDir = dir
+	if token, _ := other.Token(context.TODO()); token == nil || token.AccessToken != "token-1" {
+		t.Errorf("Token() of another source = %v, want token-1", token)
+	}
+
+	// the token is refreshed before it expires
+	s.now = func() time.Time { return time.Now().Add(time.Hour - time.Minute) }
+	if token, _ := s.Token(context.TODO()); token == nil || token.AccessToken != "token-2" {
+		t.Errorf("Token() near expiry = %v, want token-2", token)
+	}
+
+	if got := atomic.LoadInt32(issued); got != 2 {
+		t.Errorf("issued tokens = %d, want 2", got)
+	}
+
+	wrong := NewSource(srv.URL, "id", "wrong")
+	wrong.CacheDir = dir
+	if _, err := wrong.Token(context.TODO()); err == nil {
+		t.Error("Token() with a wrong secretKey error = nil, want error")
+	}
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tokenSrv, _ := newTokenServer(t, 3600)
+
+	// the api server rejects the first token, as if it was revoked
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set("X-Length", strconv.FormatInt(r.ContentLength, 10))
+	}))
+	defer srv.Close()
+
+	s := NewSource(tokenSrv.URL, "id", "key")
+	s.CacheDir = t.TempDir()
+
+	client := &http.Client{Transport: NewTransport(nil, s)}
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"subject":"users:peter"}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Length") != "25" {
+		t.Errorf("Post() = %d with length %s, want 200 with length 25", resp.StatusCode, resp.Header.Get("X-Length"))
+	}
+}
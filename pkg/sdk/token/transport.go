@@ -0,0 +1,79 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"net/http"
+
+	"github.com/marmotedu/iam/pkg/sdk"
+)
+
+// Transport is a http.RoundTripper which sets the bearer token of the requests from Source. A
+// request rejected with 401 is sent once more with a new token when its body can be replayed.
+type Transport struct {
+	Source *Source
+	// Base defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport which authenticates the requests with the tokens of s.
+func NewTransport(base http.RoundTripper, s *Source) *Transport {
+	return &Transport{Source: s, Base: base}
+}
+
+// Middleware returns the sdk middleware which authenticates the requests with the tokens of s.
+func Middleware(s *Source) sdk.Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(base, s)
+	}
+}
+
+// RoundTrip sets the bearer token of a clone of the request and sends it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, token, err := t.send(base, req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	t.Source.Invalidate(token)
+
+	retry := req
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry = req.Clone(req.Context())
+		retry.Body = body
+	}
+	resp.Body.Close()
+
+	resp, _, err = t.send(base, retry)
+
+	return resp, err
+}
+
+func (t *Transport) send(base http.RoundTripper, req *http.Request) (*http.Response, *Token, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := base.RoundTrip(req)
+
+	return resp, token, err
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/marmotedu/marmotedu-sdk-go/marmotedu/service/iam"
+	"github.com/marmotedu/marmotedu-sdk-go/rest"
+	"github.com/marmotedu/marmotedu-sdk-go/third_party/forked/gorequest"
+)
+
+// Middleware wraps the transport of a client, e.g. to sign or to authenticate its requests.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Wrap wraps the transport of the marmotedu-sdk-go rest client with the middlewares, the
+// first middleware sees the requests first.
+//
+// The rest client swaps the transport of its http client before each request, so the swap is
+// disabled process wide and every other rest client of the process must be wrapped too, or it
+// falls back to http.DefaultTransport.
+//
+// The wrapped client sends its requests with the context passed to the sdk methods, so that
+// the calls are canceled with it and the middlewares may read the values of the context.
+func Wrap(c *rest.RESTClient, middlewares ...Middleware) {
+	gorequest.DisableTransportSwap = true
+
+	var rt http.RoundTripper = c.Client.Transport
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+
+	c.Client.Client.Transport = &contextTransport{agent: c.Client, base: rt}
+}
+
+// WrapInterface is like Wrap for the rest clients of the typed clients, which are returned
+// as rest.Interface.
+func WrapInterface(c rest.Interface, middlewares ...Middleware) error {
+	client, ok := c.(*rest.RESTClient)
+	if !ok {
+		return fmt.Errorf("unexpected rest client type %T", c)
+	}
+
+	Wrap(client, middlewares...)
+
+	return nil
+}
+
+// WrapIAMClient wraps the rest clients of all the services of the iam client.
+func WrapIAMClient(c *iam.IamClient, middlewares ...Middleware) error {
+	if err := WrapInterface(c.APIV1().RESTClient(), middlewares...); err != nil {
+		return err
+	}
+
+	return WrapInterface(c.AuthzV1().RESTClient(), middlewares...)
+}
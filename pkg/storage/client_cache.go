@@ -0,0 +1,264 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"context"
+	"crypto/tls"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	goredislib "github.com/go-redis/redis/v8"
+	"github.com/marmotedu/errors"
+
+	"github.com/marmotedu/iam/pkg/log"
+)
+
+// invalidateChannel is the channel Redis publishes the invalidated keys to, for the clients
+// redirecting their tracking to a connection subscribed to it.
+const invalidateChannel = "__redis__:invalidate"
+
+// clientCachePoolSize is the number of the connections reading the cached keys.
+const clientCachePoolSize = 50
+
+// clientCache caches the keys read by the RedisClusters with ClientSideCache in process. The
+// connections reading them enable the client tracking of Redis 6, which sends the keys to a
+// connection subscribed to the invalidation channel whenever they change, so that their cached
+// values are dropped. Everything is dropped when the subscription is lost, as the invalidations
+// may have been missed.
+// It uses go-redis v8, as v7 can not parse the invalidation messages.
+type clientCache struct {
+	cache *ristretto.Cache
+	ttl   time.Duration
+
+	// seq is increased by every invalidation, a value read while it changed is not cached
+	seq uint64
+}
+
+// cachedValue is the value of a key, or the absence of the key.
+type cachedValue struct {
+	value string
+	found bool
+}
+
+var (
+	activeClientCache atomic.Value
+	activeCacheReader atomic.Value
+)
+
+// readClientCache returns the client cache along with the client reading the missing keys, it
+// returns nil while the cache is disabled or the invalidations are not received.
+func readClientCache() (*clientCache, *goredislib.Client) {
+	cache, _ := activeClientCache.Load().(*clientCache)
+	reader, _ := activeCacheReader.Load().(*goredislib.Client)
+	if cache == nil || reader == nil {
+		return nil, nil
+	}
+
+	return cache, reader
+}
+
+// runClientCache serves the cached reads until ctx is done, it subscribes to the invalidations
+// again once the subscription is lost.
+func runClientCache(ctx context.Context, config *Config) {
+	if config.EnableCluster {
+		log.Warn("The redis client side cache does not support redis cluster, it is disabled")
+
+		return
+	}
+
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: int64(config.ClientCacheSize) * 10,
+		MaxCost:     int64(config.ClientCacheSize),
+		BufferItems: 64,
+	})
+	if err != nil {
+		log.Errorf("Create redis client side cache failed: %s", err.Error())
+
+		return
+	}
+
+	c := &clientCache{cache: cache, ttl: config.ClientCacheTTL}
+	activeClientCache.Store(c)
+
+	for {
+		if err := c.track(ctx, config); err != nil {
+			log.Warnf("Redis client side cache is disabled until the invalidations are received again: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// track subscribes to the invalidations and serves the cached reads, until the subscription is
+// lost.
+func (c *clientCache) track(ctx context.Context, config *Config) error {
+	var redirect int64
+	sub := newCacheClient(config, 1, func(ctx context.Context, cn *goredislib.Conn) error {
+		id, err := cn.ClientID(ctx).Result()
+		atomic.StoreInt64(&redirect, id)
+
+		return err
+	})
+	defer sub.Close()
+
+	pubsub := sub.Subscribe(ctx, invalidateChannel)
+	defer pubsub.Close()
+
+	// the subscription blocks until a message is received, it is closed to stop
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = pubsub.Close()
+		case <-done:
+		}
+	}()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	// the connections reading the keys redirect their invalidations to the subscribed one, it
+	// requires Redis 6 or later
+	id := atomic.LoadInt64(&redirect)
+	reader := newCacheClient(config, clientCachePoolSize, func(ctx context.Context, cn *goredislib.Conn) error {
+		return cn.Process(ctx, goredislib.NewStatusCmd(ctx, "client", "tracking", "on", "redirect", id))
+	})
+	defer reader.Close()
+
+	if err := reader.Ping(ctx).Err(); err != nil {
+		return errors.Wrap(err, "enable client tracking")
+	}
+
+	activeCacheReader.Store(reader)
+	defer c.reset()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			// a flush of the database is received as an invalidation without keys, which go-redis
+			// reports as an error, either way the cache is reset
+			return err
+		}
+
+		if m, ok := msg.(*goredislib.Message); ok {
+			c.invalidate(m.PayloadSlice)
+		}
+	}
+}
+
+// get returns the value of the key, read through reader when it is not cached.
+func (c *clientCache) get(ctx context.Context, reader *goredislib.Client, key string) (string, error) {
+	if v, ok := c.cache.Get(key); ok {
+		clientCacheRequests.WithLabelValues("hit").Inc()
+
+		return v.(*cachedValue).result()
+	}
+
+	clientCacheRequests.WithLabelValues("miss").Inc()
+
+	seq := atomic.LoadUint64(&c.seq)
+	value, err := reader.Get(ctx, key).Result()
+	if err != nil && !errors.Is(err, goredislib.Nil) {
+		log.Debugf("Error trying to get value: %s", err.Error())
+
+		return "", ErrKeyNotFound
+	}
+
+	v := &cachedValue{value: value, found: err == nil}
+	// the key may have been invalidated after it was read
+	if atomic.LoadUint64(&c.seq) == seq {
+		c.cache.SetWithTTL(key, v, 1, c.ttl)
+	}
+
+	return v.result()
+}
+
+func (c *clientCache) invalidate(keys []string) {
+	atomic.AddUint64(&c.seq, 1)
+	clientCacheInvalidations.Add(float64(len(keys)))
+
+	for _, key := range keys {
+		c.cache.Del(key)
+	}
+}
+
+// reset disables the cache and drops all the cached keys.
+func (c *clientCache) reset() {
+	activeCacheReader.Store((*goredislib.Client)(nil))
+	atomic.AddUint64(&c.seq, 1)
+	clientCacheResets.Inc()
+	c.cache.Clear()
+}
+
+func (v *cachedValue) result() (string, error) {
+	if !v.found {
+		return "", ErrKeyNotFound
+	}
+
+	return v.value, nil
+}
+
+// newCacheClient creates a client of the single redis node or of the master known by the
+// sentinels, which calls onConnect for every new connection.
+func newCacheClient(
+	config *Config,
+	poolSize int,
+	onConnect func(ctx context.Context, cn *goredislib.Conn) error,
+) *goredislib.Client {
+	timeout := 5 * time.Second
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	var tlsConfig *tls.Config
+	if config.UseSSL {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: config.SSLInsecureSkipVerify,
+		}
+	}
+
+	addrs := getRedisAddrs(config)
+	if config.MasterName != "" {
+		return goredislib.NewFailoverClient(&goredislib.FailoverOptions{
+			MasterName:    config.MasterName,
+			SentinelAddrs: addrs,
+			OnConnect:     onConnect,
+			Username:      config.Username,
+			Password:      config.Password,
+			DB:            config.Database,
+			DialTimeout:   timeout,
+			ReadTimeout:   timeout,
+			WriteTimeout:  timeout,
+			PoolSize:      poolSize,
+			TLSConfig:     tlsConfig,
+		})
+	}
+
+	addr := "127.0.0.1:6379"
+	if len(addrs) > 0 {
+		addr = addrs[0]
+	}
+
+	return goredislib.NewClient(&goredislib.Options{
+		Addr:         addr,
+		OnConnect:    onConnect,
+		Username:     config.Username,
+		Password:     config.Password,
+		DB:           config.Database,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		PoolSize:     poolSize,
+		TLSConfig:    tlsConfig,
+	})
+}
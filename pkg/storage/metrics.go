@@ -0,0 +1,42 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// clientCacheRequests counts the reads of the client side cache by result, hit or miss.
+	clientCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iam_redis_client_cache_requests_total",
+			Help: "Number of redis client side cache lookups, partitioned by result.",
+		},
+		[]string{"result"},
+	)
+
+	// clientCacheInvalidations counts the keys invalidated by redis.
+	clientCacheInvalidations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "iam_redis_client_cache_invalidations_total",
+			Help: "Number of keys invalidated by redis in the client side cache.",
+		},
+	)
+
+	// clientCacheResets counts the times the whole cache was dropped, because the invalidations
+	// were lost or the database was flushed.
+	clientCacheResets = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "iam_redis_client_cache_resets_total",
+			Help: "Number of times the redis client side cache was dropped as a whole.",
+		},
+	)
+)
+
+// nolint: gochecknoinits
+func init() {
+	prometheus.MustRegister(clientCacheRequests, clientCacheInvalidations, clientCacheResets)
+}
@@ -36,6 +36,11 @@ type Config struct {
 	EnableCluster         bool
 	UseSSL                bool
 	SSLInsecureSkipVerify bool
+	// ClientCacheSize is the maximum number of the keys cached in process by the client side
+	// cache, which is disabled when it is 0.
+	ClientCacheSize int
+	// ClientCacheTTL bounds how long a key is cached, in case an invalidation is lost.
+	ClientCacheTTL time.Duration
 }
 
 // ErrRedisIsDown is returned when we can't communicate with redis.
@@ -116,6 +121,11 @@ type RedisCluster struct {
 	KeyPrefix string
 	HashKeys  bool
 	IsCache   bool
+	// ClientSideCache caches the keys read by GetKey in process when the client side cache is
+	// enabled, they are dropped as soon as redis reports their change.
+	ClientSideCache bool
+
+	ctx context.Context
 }
 
 func clusterConnectionIsOpen(cluster RedisCluster) bool {
@@ -142,6 +152,10 @@ func ConnectToRedis(ctx context.Context, config *Config) {
 	c := []RedisCluster{
 		{}, {IsCache: true},
 	}
+	if config.ClientCacheSize > 0 {
+		go runClientCache(ctx, config)
+	}
+
 	var ok bool
 	for _, v := range c {
 		if !connectSingleton(v.IsCache, config) {
@@ -358,8 +372,37 @@ func (r *RedisCluster) Connect() bool {
 	return true
 }
 
+// WithContext returns a copy of r running the commands with ctx, so that they observe its
+// deadline.
+func (r *RedisCluster) WithContext(ctx context.Context) *RedisCluster {
+	ret := *r
+	ret.ctx = ctx
+
+	return &ret
+}
+
+func (r *RedisCluster) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+
+	return r.ctx
+}
+
 func (r *RedisCluster) singleton() redis.UniversalClient {
-	return singleton(r.IsCache)
+	client := singleton(r.IsCache)
+	if r.ctx == nil {
+		return client
+	}
+
+	switch c := client.(type) {
+	case *redis.Client:
+		return c.WithContext(r.ctx)
+	case *redis.ClusterClient:
+		return c.WithContext(r.ctx)
+	default:
+		return client
+	}
 }
 
 func (r *RedisCluster) hashKey(in string) string {
@@ -393,6 +436,12 @@ func (r *RedisCluster) GetKey(keyName string) (string, error) {
 		return "", err
 	}
 
+	if r.ClientSideCache {
+		if cache, reader := readClientCache(); cache != nil {
+			return cache.get(r.context(), reader, r.fixKey(keyName))
+		}
+	}
+
 	cluster := r.singleton()
 
 	value, err := cluster.Get(r.fixKey(keyName)).Result()
@@ -1055,7 +1104,7 @@ func (r *RedisCluster) GetListRange(keyName string, from, to int64) ([]string, e
 	return elements, nil
 }
 
-// AppendToSetPipelined append values to redis pipeline.
+// AppendToSetPipelined appends the values to the list of the given key with a single RPUSH.
 func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) {
 	if len(values) == 0 {
 		return
@@ -1069,12 +1118,12 @@ func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) {
 	}
 	client := r.singleton()
 
-	pipe := client.Pipeline()
+	args := make([]interface{}, 0, len(values))
 	for _, val := range values {
-		pipe.RPush(fixedKey, val)
+		args = append(args, val)
 	}
 
-	if _, err := pipe.Exec(); err != nil {
+	if err := client.RPush(fixedKey, args...).Err(); err != nil {
 		log.Errorf("Error trying to append to set keys: %s", err.Error())
 	}
 
@@ -1385,3 +1434,160 @@ func (r *RedisCluster) RemoveSortedSetRange(keyName, scoreFrom, scoreTo string)
 
 	return nil
 }
+
+// TokenBucket is the state of a token bucket after a token is taken by TakeToken.
+type TokenBucket struct {
+	// Allowed reports whether a token was available.
+	Allowed bool
+	// Remaining is the number of the tokens left in the bucket.
+	Remaining int64
+	// RetryAfter is the time until a token is available, when none was.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// takeTokenScript implements the generic cell rate algorithm: the key stores the theoretical
+// arrival time of the next request, in seconds, a request is allowed while it is at most
+// burst emission intervals ahead of now.
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local interval = 1 / rate
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local diff = now - (new_tat - interval * burst)
+if diff < 0 then
+  return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+
+redis.call("SET", KEYS[1], tostring(new_tat), "PX", math.ceil((new_tat - now) * 1000))
+
+return {1, math.floor(diff / interval), "0", tostring(new_tat - now)}
+`)
+
+// TakeToken takes a token from the token bucket stored in redis under keyName, which is
+// refilled with qps tokens per second and holds at most burst tokens.
+func (r *RedisCluster) TakeToken(keyName string, qps float64, burst int64) (*TokenBucket, error) {
+	if err := r.up(); err != nil {
+		return nil, err
+	}
+
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+	// This function uses a raw key, so we shouldn't call fixKey
+	res, err := takeTokenScript.Run(r.singleton(), []string{keyName}, qps, burst, now).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 4 {
+		return nil, fmt.Errorf("unexpected token bucket result: %v", res)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfter, _ := values[2].(string)
+	resetAfter, _ := values[3].(string)
+
+	return &TokenBucket{
+		Allowed:    allowed == 1,
+		Remaining:  remaining,
+		RetryAfter: parseSeconds(retryAfter),
+		ResetAfter: parseSeconds(resetAfter),
+	}, nil
+}
+
+// SlidingWindow is the state of a sliding window after an event is added by AddToSlidingWindow.
+type SlidingWindow struct {
+	// Allowed reports whether the event was within the limit, and so added to the window.
+	Allowed bool
+	// Remaining is the number of the events still allowed in the window.
+	Remaining int64
+	// RetryAfter is the time until an event is allowed again, when it was not.
+	RetryAfter time.Duration
+}
+
+// slidingWindowScript counts the events of the last window in a sorted set scored by their
+// time in milliseconds. Once the limit is reached, the key is blocked for the block duration
+// if any, otherwise until the oldest event leaves the window. The blocked events are not added,
+// so that they do not extend the block.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local block = tonumber(ARGV[4])
+
+local blocked = redis.call("PTTL", KEYS[2])
+if blocked > 0 then
+  return {0, 0, blocked}
+end
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count >= limit then
+  if block > 0 then
+    redis.call("SET", KEYS[2], "1", "PX", block)
+    return {0, 0, block}
+  end
+
+  local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+  return {0, 0, math.max(tonumber(oldest[2]) + window - now, 1)}
+end
+
+redis.call("ZADD", KEYS[1], now, ARGV[5])
+redis.call("PEXPIRE", KEYS[1], window)
+
+return {1, limit - count - 1, 0}
+`)
+
+// AddToSlidingWindow adds an event to the sliding window stored in redis under keyName, which
+// allows limit events per window. Once the limit is reached the events are refused for block,
+// or until an event leaves the window if block is 0. The block is stored under keyName with
+// the ":blocked" suffix, keyName must hold a hash tag to use it with redis cluster.
+func (r *RedisCluster) AddToSlidingWindow(
+	keyName string,
+	limit int64,
+	window, block time.Duration,
+) (*SlidingWindow, error) {
+	if err := r.up(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// the events of the same millisecond are told apart
+	member := uuid.Must(uuid.NewV4()).String()
+	// This function uses raw keys, so we shouldn't call fixKey
+	res, err := slidingWindowScript.Run(r.singleton(), []string{keyName, keyName + ":blocked"},
+		now.UnixNano()/int64(time.Millisecond), window.Milliseconds(), limit, block.Milliseconds(), member).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("unexpected sliding window result: %v", res)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfter, _ := values[2].(int64)
+
+	return &SlidingWindow{
+		Allowed:    allowed == 1,
+		Remaining:  remaining,
+		RetryAfter: time.Duration(retryAfter) * time.Millisecond,
+	}, nil
+}
+
+func parseSeconds(s string) time.Duration {
+	seconds, _ := strconv.ParseFloat(s, 64)
+
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package secretref resolves the references to the secrets in the configuration files, so
+// that the passwords and the keys are not written literally into them:
+//
+//	${env:VAR}                the value of the environment variable VAR
+//	${file:/path}             the content of the file, without the trailing newline
+//	${vault:secret/path#key}  the key of the vault secret read from the API path
+//
+// The vault path is the path of the API, e.g. secret/data/iam for a KV version 2 engine. The
+// vault server is set by VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE and VAULT_CACERT.
+package secretref // import "github.com/marmotedu/iam/pkg/util/secretref"
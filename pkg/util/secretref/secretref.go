@@ -0,0 +1,175 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretref
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var refPattern = regexp.MustCompile(`\$\{(env|file|vault):([^}]*)\}`)
+
+// Resolver resolves the references of the values, it caches the vault secrets it reads.
+type Resolver struct {
+	vault   *vaultClient
+	secrets map[string]map[string]interface{}
+}
+
+// NewResolver creates a resolver.
+func NewResolver() *Resolver {
+	return &Resolver{secrets: map[string]map[string]interface{}{}}
+}
+
+// Resolve replaces the references of the value with the secrets they refer to.
+func (r *Resolver) Resolve(value string) (string, error) {
+	var lastErr error
+	ret := refPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		m := refPattern.FindStringSubmatch(ref)
+		secret, err := r.resolve(m[1], m[2])
+		if err != nil {
+			lastErr = fmt.Errorf("resolve %s failed: %w", ref, err)
+		}
+
+		return secret
+	})
+
+	return ret, lastErr
+}
+
+func (r *Resolver) resolve(kind, ref string) (string, error) {
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+
+		return value, nil
+	case "file":
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return r.resolveVault(ref)
+	}
+}
+
+func (r *Resolver) resolveVault(ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("vault reference is not in the format path#key")
+	}
+
+	secret, ok := r.secrets[path]
+	if !ok {
+		if r.vault == nil {
+			client, err := newVaultClient()
+			if err != nil {
+				return "", err
+			}
+
+			r.vault = client
+		}
+
+		var err error
+		if secret, err = r.vault.read(path); err != nil {
+			return "", err
+		}
+
+		r.secrets[path] = secret
+	}
+
+	value, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %s", path, key)
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+// ResolveSettings replaces the references of the string values of the settings in place, it
+// reports whether any was found.
+func (r *Resolver) ResolveSettings(settings map[string]interface{}) (bool, error) {
+	found := false
+	for k, v := range settings {
+		resolved, ok, err := r.resolveValue(v)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", k, err)
+		}
+
+		if ok {
+			settings[k] = resolved
+			found = true
+		}
+	}
+
+	return found, nil
+}
+
+func (r *Resolver) resolveValue(v interface{}) (interface{}, bool, error) {
+	switch value := v.(type) {
+	case string:
+		if !refPattern.MatchString(value) {
+			return value, false, nil
+		}
+
+		resolved, err := r.Resolve(value)
+
+		return resolved, true, err
+	case map[string]interface{}:
+		found, err := r.ResolveSettings(value)
+
+		return value, found, err
+	case []interface{}:
+		found := false
+		for i, item := range value {
+			resolved, ok, err := r.resolveValue(item)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if ok {
+				value[i] = resolved
+				found = true
+			}
+		}
+
+		return value, found, nil
+	default:
+		return v, false, nil
+	}
+}
+
+// ResolveConfig resolves the references of the configuration file read by v, the resolved
+// values keep the priority of the configuration file, below the flags and the environment
+// variables.
+func ResolveConfig(v *viper.Viper) error {
+	file := v.ConfigFileUsed()
+	if file == "" {
+		return nil
+	}
+
+	// the configuration file is read again, v merges the flags and the environment variables
+	raw := viper.New()
+	raw.SetConfigFile(file)
+	if err := raw.ReadInConfig(); err != nil {
+		return err
+	}
+
+	settings := raw.AllSettings()
+	found, err := NewResolver().ResolveSettings(settings)
+	if err != nil || !found {
+		return err
+	}
+
+	return v.MergeConfigMap(settings)
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package secretref
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSettings(t *testing.T) {
+	t.Setenv("IAM_TEST_PASSWORD", "env-secret")
+
+	file := filepath.Join(t.TempDir(), "key")
+	assert.NoError(t, os.WriteFile(file, []byte("file-secret\n"), 0o600))
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/iam", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"jwt-key":"vault-secret"},"metadata":{"version":1}}}`))
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	settings := map[string]interface{}{
+		"mysql": map[string]interface{}{
+			"host":     "${MARIADB_HOST}",
+			"password": "${env:IAM_TEST_PASSWORD}",
+		},
+		"jwt": map[string]interface{}{
+			"key": "${vault:secret/data/iam#jwt-key}",
+		},
+		"redis": map[string]interface{}{
+			"addrs": []interface{}{"127.0.0.1:6379"},
+			"url":   "redis://:${file:" + file + "}@127.0.0.1",
+		},
+	}
+
+	found, err := NewResolver().ResolveSettings(settings)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]interface{}{
+		"mysql": map[string]interface{}{
+			"host":     "${MARIADB_HOST}",
+			"password": "env-secret",
+		},
+		"jwt": map[string]interface{}{
+			"key": "vault-secret",
+		},
+		"redis": map[string]interface{}{
+			"addrs": []interface{}{"127.0.0.1:6379"},
+			"url":   "redis://:file-secret@127.0.0.1",
+		},
+	}, settings)
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "")
+
+	tests := []string{
+		"${env:IAM_TEST_NOT_SET}",
+		"${file:/not/exist}",
+		"${vault:secret/data/iam}",
+		"${vault:secret/data/iam#key}",
+	}
+
+	for _, tt := range tests {
+		_, err := NewResolver().Resolve(tt)
+		assert.Error(t, err, tt)
+	}
+}
@@ -2,15 +2,184 @@
 // Use of this source code is governed by a MIT style
 // license that can be found in the LICENSE file.
 
-// Package validator defines iam custom binding validators used by gin.
+// Package validator defines the iam validator of the request structs, driven by their
+// `validate` tags, and the iam custom binding validators used by gin.
 package validator
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
 )
 
+// maxDescriptionLength is the maximum length of the descriptions, like the description
+// validator of component-base.
+const maxDescriptionLength = 255
+
+// validate is the validator shared by all the request structs, so that the rules of a field are
+// the same whatever the api, rest or grpc, the request comes from.
+var validate = newValidate()
+
+// Struct validates the fields of the struct by their `validate` tags. Besides the tags of
+// go-playground/validator, e.g. required, max=30 or oneof=groups roles, the fields are
+// validated by these tags:
+//
+//   - name: a qualified name, e.g. the name of an object.
+//   - username: a qualified name, like name.
+//   - password: a password, mixing upper and lower case letters, numbers and special characters.
+//   - description: at most 255 characters.
+//   - dnslabel: a DNS-1123 label, e.g. the name of a tenant.
+//   - regexp=<pattern>: matches the regular expression, its commas escaped as 0x2C and its
+//     pipes as 0x7C.
+//   - subject=<kind>...: a ladon subject `<kind>:<name>` of one of the kinds.
+//
+// The errors are reported by the json names of the fields, e.g. metadata.name.
+func Struct(obj interface{}) field.ErrorList {
+	return errorList(nil, validate.Struct(obj))
+}
+
+// Var validates a single value by the tag, the errors are reported at fldPath. It validates the
+// values whose struct can not be tagged.
+func Var(fldPath *field.Path, value interface{}, tag string) field.ErrorList {
+	return errorList(fldPath, validate.Var(value, tag))
+}
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return fld.Name
+		default:
+			return name
+		}
+	})
+
+	_ = v.RegisterValidation("name", validateUsername)
+	_ = v.RegisterValidation("username", validateUsername)
+	_ = v.RegisterValidation("password", validatePassword)
+	_ = v.RegisterValidation("description", validateDescription)
+	_ = v.RegisterValidation("dnslabel", validateDNSLabel)
+	_ = v.RegisterValidation("regexp", validateRegexp)
+	_ = v.RegisterValidation("subject", validateSubject)
+
+	return v
+}
+
+// errorList translates the errors of the validator into field errors.
+func errorList(fldPath *field.Path, err error) field.ErrorList {
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+
+	allErrs := field.ErrorList{}
+	for _, fe := range verrs {
+		path := fldPath
+		// the namespace is prefixed with the name of the struct
+		if ns := fe.Namespace(); ns != "" {
+			if i := strings.Index(ns, "."); i >= 0 {
+				ns = ns[i+1:]
+			}
+			path = field.NewPath(ns)
+		}
+
+		allErrs = append(allErrs, fieldErrors(path, fe)...)
+	}
+
+	return allErrs
+}
+
+// fieldErrors returns the field errors of a failed tag, with the reason of the field.Error types.
+func fieldErrors(path *field.Path, fe validator.FieldError) field.ErrorList {
+	value := fe.Value()
+	kind := fe.Kind()
+
+	switch fe.Tag() {
+	case "required":
+		return field.ErrorList{field.Required(path, "")}
+	case "oneof":
+		return field.ErrorList{field.NotSupported(path, value, strings.Fields(fe.Param()))}
+	case "unique":
+		return field.ErrorList{field.Duplicate(path, value)}
+	case "max":
+		return field.ErrorList{maxError(path, value, kind, fe.Param())}
+	case "min":
+		return field.ErrorList{minError(path, value, kind, fe.Param())}
+	case "email":
+		return field.ErrorList{field.Invalid(path, value, "must be a valid email address")}
+	case "name", "username":
+		return invalid(path, value, validation.IsQualifiedName(fmt.Sprint(value)))
+	case "password":
+		// the password is left out of the error
+		err := validation.IsValidPassword(fmt.Sprint(value))
+
+		return field.ErrorList{field.Invalid(path, "", err.Error())}
+	case "description":
+		return field.ErrorList{field.TooLong(path, value, maxDescriptionLength)}
+	case "dnslabel":
+		return invalid(path, value, validation.IsDNS1123Label(fmt.Sprint(value)))
+	case "regexp":
+		return field.ErrorList{field.Invalid(path, value, "must match the regular expression "+fe.Param())}
+	case "subject":
+		return subjectErrors(path, fmt.Sprint(value), strings.Fields(fe.Param()))
+	}
+
+	return field.ErrorList{field.Invalid(path, value, fmt.Sprintf("must satisfy the %s rule", fe.Tag()))}
+}
+
+func invalid(path *field.Path, value interface{}, msgs []string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, msg := range msgs {
+		allErrs = append(allErrs, field.Invalid(path, value, msg))
+	}
+
+	return allErrs
+}
+
+func maxError(path *field.Path, value interface{}, kind reflect.Kind, param string) *field.Error {
+	switch kind {
+	case reflect.String:
+		var n int
+		_, _ = fmt.Sscan(param, &n)
+
+		return field.TooLong(path, value, n)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		var n int
+		_, _ = fmt.Sscan(param, &n)
+
+		return field.TooMany(path, reflect.ValueOf(value).Len(), n)
+	default:
+		return field.Invalid(path, value, "must be less than or equal to "+param)
+	}
+}
+
+func minError(path *field.Path, value interface{}, kind reflect.Kind, param string) *field.Error {
+	switch kind {
+	case reflect.String:
+		return field.Invalid(path, value, "must be at least "+param+" characters")
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return field.Invalid(path, value, "must have at least "+param+" items")
+	default:
+		return field.Invalid(path, value, "must be greater than or equal to "+param)
+	}
+}
+
 // validateUsername checks if a given username is illegal.
 func validateUsername(fl validator.FieldLevel) bool {
 	username := fl.Field().String()
@@ -31,6 +200,57 @@ func validatePassword(fl validator.FieldLevel) bool {
 	return true
 }
 
+// validateDescription checks if a given description is too long.
+func validateDescription(fl validator.FieldLevel) bool {
+	return len(fl.Field().String()) <= maxDescriptionLength
+}
+
+// validateDNSLabel checks if a given value is a DNS-1123 label.
+func validateDNSLabel(fl validator.FieldLevel) bool {
+	return len(validation.IsDNS1123Label(fl.Field().String())) == 0
+}
+
+// regexps caches the compiled patterns of the regexp tags.
+var regexps sync.Map
+
+// validateRegexp checks if a given value matches the pattern of the tag.
+func validateRegexp(fl validator.FieldLevel) bool {
+	pattern := fl.Param()
+
+	re, ok := regexps.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return false
+		}
+		re, _ = regexps.LoadOrStore(pattern, compiled)
+	}
+
+	return re.(*regexp.Regexp).MatchString(fl.Field().String())
+}
+
+// validateSubject checks if a given value is a ladon subject of the kinds of the tag.
+func validateSubject(fl validator.FieldLevel) bool {
+	return len(subjectErrors(nil, fl.Field().String(), strings.Fields(fl.Param()))) == 0
+}
+
+func subjectErrors(path *field.Path, subject string, kinds []string) field.ErrorList {
+	parts := strings.SplitN(subject, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return field.ErrorList{field.Invalid(path, subject, "must be in the format of <kind>:<name>")}
+	}
+
+	supported := len(kinds) == 0
+	for _, kind := range kinds {
+		supported = supported || kind == parts[0]
+	}
+	if !supported {
+		return field.ErrorList{field.NotSupported(path, parts[0], kinds)}
+	}
+
+	return invalid(path, parts[1], validation.IsQualifiedName(parts[1]))
+}
+
 func init() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		_ = v.RegisterValidation("username", validateUsername)
@@ -0,0 +1,71 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	Name     string   `json:"name" validate:"required,name"`
+	Password string   `json:"password,omitempty" validate:"omitempty,password"`
+	Kind     string   `json:"kind" validate:"omitempty,oneof=groups roles"`
+	Nickname string   `json:"nickname" validate:"max=5"`
+	Code     string   `json:"code" validate:"omitempty,regexp=^[a-z]{2}0x2C[0-9]+$"`
+	Members  []string `json:"members" validate:"unique,dive,subject=users"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := request{Name: "colin", Kind: "roles", Code: "ab,12", Members: []string{"users:colin"}}
+	assert.Empty(t, Struct(&valid))
+
+	tests := []struct {
+		name      string
+		modify    func(r *request)
+		wantField string
+		wantType  field.ErrorType
+	}{
+		{"required", func(r *request) { r.Name = "" }, "name", field.ErrorTypeRequired},
+		{"name", func(r *request) { r.Name = "<.*>" }, "name", field.ErrorTypeInvalid},
+		{"password", func(r *request) { r.Password = "admin" }, "password", field.ErrorTypeInvalid},
+		{"enum", func(r *request) { r.Kind = "teams" }, "kind", field.ErrorTypeNotSupported},
+		{"max length", func(r *request) { r.Nickname = "marmotedu" }, "nickname", field.ErrorTypeTooLong},
+		{"regexp", func(r *request) { r.Code = "ab12" }, "code", field.ErrorTypeInvalid},
+		{"duplicate", func(r *request) { r.Members = []string{"users:colin", "users:colin"} }, "members", field.ErrorTypeDuplicate},
+		{"subject kind", func(r *request) { r.Members = []string{"groups:dev"} }, "members[0]", field.ErrorTypeNotSupported},
+		{"subject format", func(r *request) { r.Members = []string{"colin"} }, "members[0]", field.ErrorTypeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.modify(&r)
+
+			errs := Struct(&r)
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tt.wantField, errs[0].Field)
+				assert.Equal(t, tt.wantType, errs[0].Type)
+			}
+		})
+	}
+}
+
+func TestStruct_passwordLeftOut(t *testing.T) {
+	errs := Struct(&request{Name: "colin", Password: "secret"})
+	if assert.Len(t, errs, 1) {
+		assert.NotContains(t, errs[0].Error(), "secret")
+	}
+}
+
+func TestVar(t *testing.T) {
+	assert.Empty(t, Var(field.NewPath("metadata", "name"), "marmotedu", "dnslabel"))
+
+	errs := Var(field.NewPath("metadata", "name"), "Marmotedu", "dnslabel")
+	if assert.NotEmpty(t, errs) {
+		assert.Equal(t, "metadata.name", errs[0].Field)
+	}
+}